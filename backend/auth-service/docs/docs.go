@@ -442,6 +442,15 @@ const docTemplate = `{
             "description": "Response after token verification",
             "type": "object",
             "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "moderator",
+                        "admin"
+                    ],
+                    "example": "user"
+                },
                 "user_id": {
                     "type": "integer",
                     "example": 1
@@ -90,6 +90,7 @@ type VerifyTokenResponse struct {
 	Valid    bool   `json:"valid" example:"true"`
 	UserID   int64  `json:"user_id,omitempty" example:"1"`
 	Username string `json:"username,omitempty" example:"johndoe"`
+	Role     string `json:"role,omitempty" example:"user" enums:"user,moderator,admin"`
 }
 
 // VerifyTokenRequest represents the request body for token verification
@@ -270,6 +271,7 @@ func (h *AuthHandler) VerifyToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"user_id":  user.ID,
 		"username": user.Username,
+		"role":     user.Role,
 	})
 }
 
@@ -315,6 +315,7 @@ func TestAuthHandler_VerifyToken(t *testing.T) {
 			if tt.expectedValid {
 				assert.Equal(t, "1", response["user_id"])
 				assert.Equal(t, "testuser", response["username"])
+				assert.Equal(t, "user", response["role"])
 			} else {
 				assert.Contains(t, response, "error", "response: %v", response)
 			}
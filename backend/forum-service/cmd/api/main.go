@@ -1,23 +1,35 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/sout1235/forum2/backend/forum-service/api/proto"
+	"github.com/sout1235/forum2/backend/forum-service/internal/avatar"
 	"github.com/sout1235/forum2/backend/forum-service/internal/config"
 	grpcDelivery "github.com/sout1235/forum2/backend/forum-service/internal/delivery/grpc"
 	httpDelivery "github.com/sout1235/forum2/backend/forum-service/internal/delivery/http"
 	"github.com/sout1235/forum2/backend/forum-service/internal/delivery/http/middleware"
 	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/sout1235/forum2/backend/forum-service/internal/storage"
 	"github.com/sout1235/forum2/backend/forum-service/internal/usecase"
 	"google.golang.org/grpc"
 )
 
+// shutdownTimeout is how long in-flight HTTP requests may take to finish
+// when the service is stopped
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	// Загружаем конфигурацию
 	cfg := config.NewConfig()
@@ -39,15 +51,124 @@ func main() {
 		log.Printf("Warning: Failed to run migrations: %v", err)
 	}
 
+	// Отрисовываем HTML для записей, созданных до его появления
+	go func() {
+		rendered, err := repository.RenderMissingHTML(context.Background(), db)
+		if err != nil {
+			log.Printf("Warning: Failed to render content html: %v", err)
+		}
+		if rendered > 0 {
+			log.Printf("Rendered content html for %d records", rendered)
+		}
+	}()
+
 	// Инициализация репозиториев
 	topicRepo := repository.NewTopicRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
 	userRepo := repository.NewUserRepository(db, cfg.AuthServiceURL)
 	chatRepo := repository.NewChatRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	revisionRepo := repository.NewRevisionRepository(db)
+	mentionRepo := repository.NewMentionRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	pollRepo := repository.NewPollRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	avatarRepo := repository.NewAvatarRepository(db)
+	profileRepo := repository.NewProfileRepository(db)
+	draftRepo := repository.NewDraftRepository(db)
+
+	// Хранилище загруженных файлов
+	var fileStorage storage.Storage
+	switch cfg.StorageBackend {
+	case "s3":
+		fileStorage = storage.NewS3Storage(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	default:
+		fileStorage, err = storage.NewLocalStorage(cfg.StorageDir)
+		if err != nil {
+			log.Fatalf("Failed to initialize file storage: %v", err)
+		}
+	}
 
 	// Инициализация use cases
-	commentUseCase := usecase.NewCommentUseCase(commentRepo, userRepo)
-	topicService := service.NewTopicService(topicRepo, userRepo)
+	notificationService := service.NewNotificationService(notificationRepo, commentRepo, subscriptionRepo)
+	commentUseCase := usecase.NewCommentUseCase(commentRepo, userRepo, cfg.CommentEditWindow, notificationService)
+	topicService := service.NewTopicService(topicRepo, userRepo, categoryRepo, tagRepo, notificationService)
+	categoryService := service.NewCategoryService(categoryRepo)
+	tagService := service.NewTagService(tagRepo)
+	searchService := service.NewSearchService(searchRepo, userRepo)
+	reportService := service.NewReportService(reportRepo, cfg.ReportAutoHideThreshold, notificationService)
+	revisionService := service.NewRevisionService(revisionRepo, topicRepo, commentRepo, notificationService)
+	mentionService := service.NewMentionService(mentionRepo)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo)
+	pollService := service.NewPollService(pollRepo, topicRepo, userRepo)
+	attachmentService, err := service.NewAttachmentService(attachmentRepo, topicRepo, commentRepo, userRepo, fileStorage, service.AttachmentConfig{
+		MaxSize:      int64(cfg.AttachmentMaxSize),
+		AllowedTypes: cfg.AttachmentTypes,
+		SigningKey:   []byte(cfg.AttachmentSigningKey),
+		URLTTL:       cfg.AttachmentURLTTL,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment service: %v", err)
+	}
+	avatarService := service.NewAvatarService(avatarRepo, userRepo, fileStorage, service.AvatarConfig{
+		MaxSize: int64(cfg.AvatarMaxSize),
+		MinSide: cfg.AvatarMinSide,
+		MaxSide: cfg.AvatarMaxSide,
+		Style:   avatar.Style(cfg.AvatarStyle),
+	})
+	profileService := service.NewProfileService(profileRepo, userRepo)
+	draftService := service.NewDraftService(draftRepo, topicRepo, commentRepo, categoryRepo, userRepo, notificationService, cfg.DraftTTL)
+
+	// Фоновые задачи работают до остановки серверов, а затем доделывают
+	// начатое: счетчик просмотров, например, сохраняет накопленное
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+
+	// Просмотры тем копятся в памяти и сохраняются пачками
+	viewCounter := service.NewViewCounter(topicRepo, cfg.ViewWindow)
+	runWorker(func(ctx context.Context) { viewCounter.Run(ctx, cfg.ViewFlushInterval) })
+
+	// Удаленный контент окончательно стирается после срока хранения
+	if cfg.DeletedRetention > 0 {
+		purger := service.NewDeletedContentPurger(topicRepo, commentRepo, cfg.DeletedRetention)
+		runWorker(func(ctx context.Context) { purger.Run(ctx, cfg.DeletedPurgeInterval) })
+	}
+
+	// Напоминания по закладкам приходят уведомлениями
+	bookmarkReminder := service.NewBookmarkReminder(bookmarkRepo, notificationService)
+	runWorker(func(ctx context.Context) { bookmarkReminder.Run(ctx, cfg.BookmarkReminderInterval) })
+
+	// Истекшие черновики удаляются
+	draftPurger := service.NewDraftPurger(draftRepo)
+	runWorker(func(ctx context.Context) { draftPurger.Run(ctx, cfg.DraftPurgeInterval) })
+
+	// Отложенные темы публикуются, когда наступает их время
+	topicPublisher := service.NewTopicPublisher(topicRepo, notificationService)
+	runWorker(func(ctx context.Context) { topicPublisher.Run(ctx, cfg.TopicPublishInterval) })
+
+	// Непривязанные загрузки и вложения стертых записей удаляются
+	attachmentCollector := service.NewAttachmentCollector(attachmentRepo, fileStorage, cfg.AttachmentOrphanTTL)
+	runWorker(func(ctx context.Context) { attachmentCollector.Run(ctx, cfg.AttachmentGCInterval) })
 
 	// Инициализация HTTP сервера
 	authConfig := &middleware.AuthConfig{
@@ -61,12 +182,28 @@ func main() {
 		chatRepo,
 		cfg.AuthServiceURL,
 		authConfig,
+		httpDelivery.WithSearchService(searchService),
+		httpDelivery.WithCategoryService(categoryService),
+		httpDelivery.WithTagService(tagService),
+		httpDelivery.WithViewCounter(viewCounter),
+		httpDelivery.WithReportService(reportService),
+		httpDelivery.WithRevisionService(revisionService),
+		httpDelivery.WithMentionService(mentionService),
+		httpDelivery.WithNotificationService(notificationService),
+		httpDelivery.WithSubscriptionService(subscriptionService),
+		httpDelivery.WithBookmarkService(bookmarkService),
+		httpDelivery.WithPollService(pollService),
+		httpDelivery.WithAttachmentService(attachmentService),
+		httpDelivery.WithAvatarService(avatarService),
+		httpDelivery.WithProfileService(profileService),
+		httpDelivery.WithDraftService(draftService),
 	)
 
 	// Запуск HTTP сервера
+	httpServer := &http.Server{Addr: ":" + cfg.HTTPPort, Handler: router.Engine()}
 	go func() {
 		log.Printf("Starting HTTP server on port %s", cfg.HTTPPort)
-		if err := router.Run(":" + cfg.HTTPPort); err != nil && err != http.ErrServerClosed {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start HTTP server: %v", err)
 		}
 	}()
@@ -77,13 +214,33 @@ func main() {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	// Вызовы gRPC аутентифицируются тем же токеном, что и HTTP
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(grpcDelivery.AuthInterceptor(authConfig)))
 	commentServer := grpcDelivery.NewCommentServer(commentUseCase)
 	proto.RegisterCommentServiceServer(grpcServer, commentServer)
 
 	// Запуск gRPC сервера
-	log.Printf("Starting gRPC server on :%s", cfg.GRPCPort)
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve gRPC: %v", err)
+	go func() {
+		log.Printf("Starting gRPC server on :%s", cfg.GRPCPort)
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("Failed to serve gRPC: %v", err)
+		}
+	}()
+
+	// Ждем сигнала остановки
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-signalCtx.Done()
+	log.Printf("Shutting down")
+
+	// Сначала перестаем принимать запросы, потом останавливаем фоновые
+	// задачи, чтобы они сохранили все, что успели накопить запросы
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down HTTP server: %v", err)
 	}
+	grpcServer.GracefulStop()
+	stopWorkers()
+	workers.Wait()
 }
@@ -0,0 +1,78 @@
+// Package avatar turns uploaded pictures into square user avatars and draws
+// the avatars of users who have not uploaded one
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+
+	"golang.org/x/image/draw"
+
+	// Поддерживаемые форматы регистрируются в image.Decode
+	_ "golang.org/x/image/webp"
+	_ "image/jpeg"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("image must be a PNG, JPEG or WebP")
+	ErrTooSmall          = errors.New("image is too small")
+	ErrTooLarge          = errors.New("image is too large")
+)
+
+// Limits bound the sides of pictures accepted as avatars
+type Limits struct {
+	MinSide int
+	MaxSide int
+}
+
+// Resize decodes a PNG, JPEG or WebP picture, crops the middle square out
+// of it and returns that square as a PNG of every requested size
+func Resize(data []byte, limits Limits, sizes []int) (map[int][]byte, error) {
+	// Размеры проверяются до декодирования, чтобы маленький файл не
+	// распаковался в огромную картинку
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	switch format {
+	case "png", "jpeg", "webp":
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width < limits.MinSide || cfg.Height < limits.MinSide {
+		return nil, ErrTooSmall
+	}
+	if cfg.Width > limits.MaxSide || cfg.Height > limits.MaxSide {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	square := cropSquare(src.Bounds())
+
+	images := make(map[int][]byte, len(sizes))
+	for _, size := range sizes {
+		dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Src, nil)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, fmt.Errorf("failed to encode avatar: %w", err)
+		}
+		images[size] = buf.Bytes()
+	}
+	return images, nil
+}
+
+// cropSquare returns the largest square centered in the bounds
+func cropSquare(b image.Rectangle) image.Rectangle {
+	side := min(b.Dx(), b.Dy())
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
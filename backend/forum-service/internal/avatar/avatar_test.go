@@ -0,0 +1,112 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLimits = Limits{MinSide: 64, MaxSide: 1024}
+
+// stripes makes a picture whose left and right thirds are red and the
+// middle is blue
+func stripes(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= w/3 && x < 2*w/3 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestResize(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, stripes(300, 100)))
+
+	images, err := Resize(buf.Bytes(), testLimits, []int{32, 128})
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+
+	for _, size := range []int{32, 128} {
+		img, err := png.Decode(bytes.NewReader(images[size]))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, size, size), img.Bounds())
+		// Из широкой картинки вырезается середина, то есть синяя полоса
+		r, _, b, _ := img.At(size/2, size/2).RGBA()
+		assert.Zero(t, r>>8)
+		assert.Equal(t, uint32(255), b>>8)
+	}
+
+	buf.Reset()
+	require.NoError(t, jpeg.Encode(&buf, stripes(100, 200), nil))
+	images, err = Resize(buf.Bytes(), testLimits, []int{64})
+	require.NoError(t, err)
+	assert.Len(t, images, 1)
+}
+
+func TestResize_Rejected(t *testing.T) {
+	encode := func(img image.Image) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+		return buf.Bytes()
+	}
+	var gifBuf bytes.Buffer
+	require.NoError(t, gif.Encode(&gifBuf, stripes(100, 100), nil))
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"not an image", []byte("hello"), ErrUnsupportedFormat},
+		{"gif", gifBuf.Bytes(), ErrUnsupportedFormat},
+		{"too small", encode(stripes(200, 40)), ErrTooSmall},
+		{"too large", encode(stripes(2000, 100)), ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Resize(tt.data, testLimits, []int{64})
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	a := Generate(StyleIdenticon, "5", "alice")
+	assert.Equal(t, a, Generate(StyleIdenticon, "5", "bob"))
+	assert.NotEqual(t, a, Generate(StyleIdenticon, "6", "alice"))
+	assert.True(t, bytes.HasPrefix(a, []byte("<svg ")))
+
+	initialsSVG := string(Generate(StyleInitials, "5", "john_doe"))
+	assert.Contains(t, initialsSVG, ">JD</text>")
+	assert.Contains(t, string(Generate(StyleInitials, "5", "<b>ob")), ">BO</text>")
+	// Без букв и цифр рисуется identicon
+	assert.Equal(t, string(a), string(Generate(StyleInitials, "5", "__")))
+	assert.False(t, strings.Contains(string(Generate(StyleInitials, "5", "<&>")), "<&>"))
+}
+
+func TestInitials(t *testing.T) {
+	tests := map[string]string{
+		"alice":          "A",
+		"john doe smith": "JD",
+		"иван.петров":    "ИП",
+		"x_42":           "X4",
+		"":               "",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, initials(name), name)
+	}
+}
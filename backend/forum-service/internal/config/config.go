@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -17,6 +20,59 @@ type Config struct {
 	AuthURL        string
 	AuthGRPCURL    string
 	AuthServiceURL string
+	// CommentEditWindow limits how long after posting an author may edit a
+	// comment; zero allows editing at any time
+	CommentEditWindow time.Duration
+	// ViewWindow is how long repeated views of a topic by the same reader
+	// are ignored; ViewFlushInterval is how often counted views are saved
+	ViewWindow        time.Duration
+	ViewFlushInterval time.Duration
+	// ReportAutoHideThreshold is how many users have to report the same
+	// content before it is hidden pending review; zero disables auto-hiding
+	ReportAutoHideThreshold int
+	// DeletedRetention is how long deleted topics and comments can still be
+	// restored before they are purged; zero keeps them forever.
+	// DeletedPurgeInterval is how often the purge runs.
+	DeletedRetention     time.Duration
+	DeletedPurgeInterval time.Duration
+	// BookmarkReminderInterval is how often due bookmark reminders are sent
+	BookmarkReminderInterval time.Duration
+	// DraftTTL is how long a draft is kept after it was last saved;
+	// DraftPurgeInterval is how often expired drafts are deleted
+	DraftTTL           time.Duration
+	DraftPurgeInterval time.Duration
+	// TopicPublishInterval is how often scheduled topics that came due are
+	// published
+	TopicPublishInterval time.Duration
+	// StorageBackend selects where uploaded files are kept: "local" keeps
+	// them in StorageDir, "s3" in a bucket of an S3-compatible service
+	StorageBackend string
+	StorageDir     string
+	S3Endpoint     string
+	S3Bucket       string
+	S3Region       string
+	S3AccessKey    string
+	S3SecretKey    string
+	// AttachmentMaxSize is the largest accepted upload in bytes;
+	// AttachmentTypes lists the accepted content types, empty for the defaults
+	AttachmentMaxSize int
+	AttachmentTypes   []string
+	// AttachmentSigningKey signs download links, which expire after
+	// AttachmentURLTTL. It is required and must be shared by all instances.
+	AttachmentSigningKey string
+	AttachmentURLTTL     time.Duration
+	// AttachmentOrphanTTL is how long an upload may stay unattached before
+	// it is removed; AttachmentGCInterval is how often that is checked
+	AttachmentOrphanTTL  time.Duration
+	AttachmentGCInterval time.Duration
+
+	// AvatarMaxSize is the largest accepted avatar in bytes; pictures with
+	// a side outside AvatarMinSide..AvatarMaxSide pixels are rejected
+	AvatarMaxSize int
+	AvatarMinSide int
+	AvatarMaxSide int
+	// AvatarStyle is the look of generated avatars: identicon or initials
+	AvatarStyle string
 }
 
 func NewConfig() *Config {
@@ -30,6 +86,42 @@ func NewConfig() *Config {
 		HTTPPort:       getEnv("FORUM_HTTP_PORT", "8081"),
 		GRPCPort:       getEnv("FORUM_GRPC_PORT", "50052"),
 		AuthServiceURL: getEnv("AUTH_SERVICE_URL", "http://localhost:8080"),
+
+		CommentEditWindow: getDurationEnv("FORUM_COMMENT_EDIT_WINDOW", 15*time.Minute),
+		ViewWindow:        getDurationEnv("FORUM_VIEW_WINDOW", 30*time.Minute),
+		ViewFlushInterval: getDurationEnv("FORUM_VIEW_FLUSH_INTERVAL", 10*time.Second),
+
+		ReportAutoHideThreshold: getIntEnv("FORUM_REPORT_AUTO_HIDE_THRESHOLD", 5),
+
+		DeletedRetention:     getDurationEnv("FORUM_DELETED_RETENTION", 30*24*time.Hour),
+		DeletedPurgeInterval: getDurationEnv("FORUM_DELETED_PURGE_INTERVAL", time.Hour),
+
+		BookmarkReminderInterval: getDurationEnv("FORUM_BOOKMARK_REMINDER_INTERVAL", time.Minute),
+
+		DraftTTL:           getDurationEnv("FORUM_DRAFT_TTL", 30*24*time.Hour),
+		DraftPurgeInterval: getDurationEnv("FORUM_DRAFT_PURGE_INTERVAL", time.Hour),
+
+		TopicPublishInterval: getDurationEnv("FORUM_TOPIC_PUBLISH_INTERVAL", 15*time.Second),
+
+		StorageBackend: getEnv("FORUM_STORAGE_BACKEND", "local"),
+		StorageDir:     getEnv("FORUM_STORAGE_DIR", "./uploads"),
+		S3Endpoint:     getEnv("FORUM_S3_ENDPOINT", ""),
+		S3Bucket:       getEnv("FORUM_S3_BUCKET", ""),
+		S3Region:       getEnv("FORUM_S3_REGION", "us-east-1"),
+		S3AccessKey:    getEnv("FORUM_S3_ACCESS_KEY", ""),
+		S3SecretKey:    getEnv("FORUM_S3_SECRET_KEY", ""),
+
+		AttachmentMaxSize:    getIntEnv("FORUM_ATTACHMENT_MAX_SIZE", 10<<20),
+		AttachmentTypes:      getListEnv("FORUM_ATTACHMENT_TYPES"),
+		AttachmentSigningKey: getEnv("FORUM_ATTACHMENT_SIGNING_KEY", ""),
+		AttachmentURLTTL:     getDurationEnv("FORUM_ATTACHMENT_URL_TTL", time.Hour),
+		AttachmentOrphanTTL:  getDurationEnv("FORUM_ATTACHMENT_ORPHAN_TTL", 24*time.Hour),
+		AttachmentGCInterval: getDurationEnv("FORUM_ATTACHMENT_GC_INTERVAL", time.Hour),
+
+		AvatarMaxSize: getIntEnv("FORUM_AVATAR_MAX_SIZE", 5<<20),
+		AvatarMinSide: getIntEnv("FORUM_AVATAR_MIN_SIDE", 64),
+		AvatarMaxSide: getIntEnv("FORUM_AVATAR_MAX_SIDE", 4096),
+		AvatarStyle:   getEnv("FORUM_AVATAR_STYLE", "identicon"),
 	}
 
 	// Если DATABASE_URL не указан, формируем его из отдельных параметров
@@ -48,3 +140,42 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getListEnv reads a comma-separated list, skipping empty items
+func getListEnv(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getDurationEnv reads a duration such as "15m" or "2h", falling back to the
+// default when the variable is unset or malformed.
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return defaultValue
+	}
+	return d
+}
+
+// getIntEnv reads a non-negative integer, falling back to the default when
+// the variable is unset or malformed.
+func getIntEnv(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return defaultValue
+	}
+	return n
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -47,3 +48,27 @@ func TestGetEnv(t *testing.T) {
 	os.Setenv("TEST_KEY", "custom_value")
 	assert.Equal(t, "custom_value", getEnv("TEST_KEY", "default"))
 }
+
+func TestGetDurationEnv(t *testing.T) {
+	os.Unsetenv("TEST_DURATION")
+	assert.Equal(t, 15*time.Minute, getDurationEnv("TEST_DURATION", 15*time.Minute))
+
+	os.Setenv("TEST_DURATION", "1h")
+	assert.Equal(t, time.Hour, getDurationEnv("TEST_DURATION", 15*time.Minute))
+
+	os.Setenv("TEST_DURATION", "0")
+	assert.Equal(t, time.Duration(0), getDurationEnv("TEST_DURATION", 15*time.Minute))
+
+	os.Setenv("TEST_DURATION", "soon")
+	assert.Equal(t, 15*time.Minute, getDurationEnv("TEST_DURATION", 15*time.Minute))
+	os.Unsetenv("TEST_DURATION")
+}
+
+func TestGetListEnv(t *testing.T) {
+	os.Unsetenv("TEST_LIST")
+	assert.Empty(t, getListEnv("TEST_LIST"))
+
+	os.Setenv("TEST_LIST", " image/png, ,text/plain ")
+	assert.Equal(t, []string{"image/png", "text/plain"}, getListEnv("TEST_LIST"))
+	os.Unsetenv("TEST_LIST")
+}
@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TokenVerifier checks a "Bearer <token>" header and returns the user it
+// belongs to
+type TokenVerifier interface {
+	VerifyToken(authHeader string) (policy.Actor, error)
+}
+
+type userIDKey struct{}
+
+// ContextWithUserID returns a copy of ctx carrying the authenticated user
+func ContextWithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the user AuthInterceptor authenticated, if any
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(int64)
+	return userID, ok && userID > 0
+}
+
+// AuthInterceptor identifies the caller by the "authorization" metadata,
+// which carries the same bearer token as the HTTP Authorization header.
+// Calls without a token go through anonymously; RPCs that act on behalf of
+// a user reject them. A token that doesn't verify fails the call.
+func AuthInterceptor(verifier TokenVerifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+		actor, err := verifier.VerifyToken(values[0])
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		ctx = ContextWithUserID(ctx, actor.UserID)
+		if actor.Role != "" {
+			ctx = policy.ContextWithRole(ctx, actor.Role)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// callerID returns the authenticated user a call acts on behalf of. The
+// user_id field of older requests is no longer trusted: if it is sent, it
+// must name the caller.
+func callerID(ctx context.Context, requested int64) (int64, error) {
+	userID, ok := UserIDFromContext(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if requested != 0 && requested != userID {
+		return 0, status.Error(codes.PermissionDenied, "user_id does not match the authenticated user")
+	}
+	return userID, nil
+}
+
+// viewerID returns the authenticated caller of a read, or 0 for anonymous
+// readers. Reads only use it to mark what the viewer has liked.
+func viewerID(ctx context.Context) int64 {
+	userID, _ := UserIDFromContext(ctx)
+	return userID
+}
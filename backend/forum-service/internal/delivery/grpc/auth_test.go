@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeVerifier map[string]policy.Actor
+
+func (v fakeVerifier) VerifyToken(authHeader string) (policy.Actor, error) {
+	actor, ok := v[authHeader]
+	if !ok {
+		return policy.Actor{}, errors.New("invalid token")
+	}
+	return actor, nil
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	interceptor := AuthInterceptor(fakeVerifier{"Bearer good": {UserID: 7, Role: "moderator"}})
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.CommentService/UpdateComment"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		userID, _ := UserIDFromContext(ctx)
+		role, _ := policy.RoleFromContext(ctx)
+		return policy.Actor{UserID: userID, Role: role}, nil
+	}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		wantUser policy.Actor
+		wantCode codes.Code
+	}{
+		{name: "valid token", md: metadata.Pairs("authorization", "Bearer good"), wantUser: policy.Actor{UserID: 7, Role: "moderator"}},
+		{name: "anonymous", md: metadata.MD{}},
+		{name: "invalid token", md: metadata.Pairs("authorization", "Bearer bad"), wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			resp, err := interceptor(ctx, nil, info, handler)
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantUser, resp)
+		})
+	}
+}
@@ -2,11 +2,16 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"strconv"
 
 	"github.com/sout1235/forum2/backend/forum-service/api/proto"
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 	"github.com/sout1235/forum2/backend/forum-service/internal/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -27,21 +32,14 @@ func (s *CommentServer) GetCommentsByTopic(ctx context.Context, req *proto.GetCo
 		return nil, err
 	}
 
-	comments, err := s.commentUseCase.GetCommentsByTopicID(ctx, topicID)
+	comments, err := s.commentUseCase.GetCommentsByTopicID(ctx, topicID, viewerID(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	var protoComments []*proto.Comment
 	for _, comment := range comments {
-		protoComments = append(protoComments, &proto.Comment{
-			Id:        comment.ID,
-			Content:   comment.Content,
-			AuthorId:  comment.AuthorID,
-			TopicId:   comment.TopicID,
-			CreatedAt: timestamppb.New(comment.CreatedAt),
-			UpdatedAt: timestamppb.New(comment.UpdatedAt),
-		})
+		protoComments = append(protoComments, toProtoComment(comment))
 	}
 
 	return &proto.GetCommentsByTopicResponse{
@@ -55,20 +53,13 @@ func (s *CommentServer) GetCommentByID(ctx context.Context, req *proto.GetCommen
 		return nil, err
 	}
 
-	comment, err := s.commentUseCase.GetCommentByID(ctx, commentID)
+	comment, err := s.commentUseCase.GetCommentByID(ctx, commentID, viewerID(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	return &proto.GetCommentByIDResponse{
-		Comment: &proto.Comment{
-			Id:        comment.ID,
-			Content:   comment.Content,
-			AuthorId:  comment.AuthorID,
-			TopicId:   comment.TopicID,
-			CreatedAt: timestamppb.New(comment.CreatedAt),
-			UpdatedAt: timestamppb.New(comment.UpdatedAt),
-		},
+		Comment: toProtoComment(comment),
 	}, nil
 }
 
@@ -78,26 +69,45 @@ func (s *CommentServer) CreateComment(ctx context.Context, req *proto.CreateComm
 		return nil, err
 	}
 
+	authorID, err := callerID(ctx, req.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+
 	comment := &entity.Comment{
 		Content:  req.Content,
-		AuthorID: req.AuthorId,
+		AuthorID: authorID,
 		TopicID:  topicID,
 	}
 
 	err = s.commentUseCase.CreateComment(ctx, comment)
 	if err != nil {
-		return nil, err
+		return nil, commentError(err)
 	}
 
 	return &proto.CreateCommentResponse{
-		Comment: &proto.Comment{
-			Id:        comment.ID,
-			Content:   comment.Content,
-			AuthorId:  comment.AuthorID,
-			TopicId:   comment.TopicID,
-			CreatedAt: timestamppb.New(comment.CreatedAt),
-			UpdatedAt: timestamppb.New(comment.UpdatedAt),
-		},
+		Comment: toProtoComment(comment),
+	}, nil
+}
+
+func (s *CommentServer) UpdateComment(ctx context.Context, req *proto.UpdateCommentRequest) (*proto.UpdateCommentResponse, error) {
+	commentID, err := strconv.ParseInt(req.CommentId, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := callerID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentUseCase.UpdateComment(ctx, commentID, userID, req.Content)
+	if err != nil {
+		return nil, commentError(err)
+	}
+
+	return &proto.UpdateCommentResponse{
+		Comment: toProtoComment(comment),
 	}, nil
 }
 
@@ -107,11 +117,16 @@ func (s *CommentServer) DeleteComment(ctx context.Context, req *proto.DeleteComm
 		return nil, err
 	}
 
-	err = s.commentUseCase.DeleteComment(ctx, commentID)
+	userID, err := callerID(ctx, req.UserId)
 	if err != nil {
 		return nil, err
 	}
 
+	err = s.commentUseCase.DeleteComment(ctx, commentID, userID)
+	if err != nil {
+		return nil, commentError(err)
+	}
+
 	return &proto.DeleteCommentResponse{}, nil
 }
 
@@ -120,11 +135,69 @@ func (s *CommentServer) LikeComment(ctx context.Context, req *proto.LikeCommentR
 	if err != nil {
 		return nil, err
 	}
+	userID, err := callerID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	likes, err := s.commentUseCase.LikeComment(ctx, commentID, userID)
+	if err != nil {
+		return nil, commentError(err)
+	}
 
-	err = s.commentUseCase.LikeComment(ctx, commentID)
+	return &proto.LikeCommentResponse{Likes: int32(likes)}, nil
+}
+
+func (s *CommentServer) UnlikeComment(ctx context.Context, req *proto.UnlikeCommentRequest) (*proto.UnlikeCommentResponse, error) {
+	commentID, err := strconv.ParseInt(req.CommentId, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := callerID(ctx, req.UserId)
 	if err != nil {
 		return nil, err
 	}
 
-	return &proto.LikeCommentResponse{}, nil
+	likes, err := s.commentUseCase.UnlikeComment(ctx, commentID, userID)
+	if err != nil {
+		return nil, commentError(err)
+	}
+
+	return &proto.UnlikeCommentResponse{Likes: int32(likes)}, nil
+}
+
+func toProtoComment(comment *entity.Comment) *proto.Comment {
+	pc := &proto.Comment{
+		Id:        comment.ID,
+		Content:   comment.Content,
+		AuthorId:  comment.AuthorID,
+		TopicId:   comment.TopicID,
+		CreatedAt: timestamppb.New(comment.CreatedAt),
+		UpdatedAt: timestamppb.New(comment.UpdatedAt),
+		Edited:    comment.Edited,
+		Likes:     int32(comment.Likes),
+		LikedByMe: comment.LikedByMe,
+	}
+	if comment.EditedAt != nil {
+		pc.EditedAt = timestamppb.New(*comment.EditedAt)
+	}
+	return pc
+}
+
+// commentError converts use case errors into gRPC status errors
+func commentError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrEmptyComment), errors.Is(err, usecase.ErrInvalidParentComment):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, policy.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, policy.ErrForbidden), errors.Is(err, usecase.ErrEditWindowExpired):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, repository.ErrCommentNotFound), errors.Is(err, repository.ErrTopicNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrTopicLocked):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return err
+	}
 }
@@ -8,21 +8,25 @@ import (
 
 	"github.com/sout1235/forum2/backend/forum-service/api/proto"
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type MockCommentUseCase struct {
 	mock.Mock
 }
 
-func (m *MockCommentUseCase) GetCommentsByTopicID(ctx context.Context, topicID int64) ([]*entity.Comment, error) {
-	args := m.Called(ctx, topicID)
+func (m *MockCommentUseCase) GetCommentsByTopicID(ctx context.Context, topicID, viewerID int64) ([]*entity.Comment, error) {
+	args := m.Called(ctx, topicID, viewerID)
 	return args.Get(0).([]*entity.Comment), args.Error(1)
 }
 
-func (m *MockCommentUseCase) GetCommentByID(ctx context.Context, commentID int64) (*entity.Comment, error) {
-	args := m.Called(ctx, commentID)
+func (m *MockCommentUseCase) GetCommentByID(ctx context.Context, commentID, viewerID int64) (*entity.Comment, error) {
+	args := m.Called(ctx, commentID, viewerID)
 	return args.Get(0).(*entity.Comment), args.Error(1)
 }
 
@@ -31,23 +35,57 @@ func (m *MockCommentUseCase) CreateComment(ctx context.Context, comment *entity.
 	return args.Error(0)
 }
 
-func (m *MockCommentUseCase) DeleteComment(ctx context.Context, commentID int64) error {
-	args := m.Called(ctx, commentID)
+func (m *MockCommentUseCase) UpdateComment(ctx context.Context, id, userID int64, content string) (*entity.Comment, error) {
+	args := m.Called(ctx, id, userID, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Comment), args.Error(1)
+}
+
+func (m *MockCommentUseCase) DeleteComment(ctx context.Context, commentID, userID int64) error {
+	args := m.Called(ctx, commentID, userID)
 	return args.Error(0)
 }
 
-func (m *MockCommentUseCase) LikeComment(ctx context.Context, commentID int64) error {
+func (m *MockCommentUseCase) RestoreComment(ctx context.Context, commentID int64) error {
 	args := m.Called(ctx, commentID)
 	return args.Error(0)
 }
 
+func (m *MockCommentUseCase) LikeComment(ctx context.Context, commentID, userID int64) (int, error) {
+	args := m.Called(ctx, commentID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCommentUseCase) UnlikeComment(ctx context.Context, commentID, userID int64) (int, error) {
+	args := m.Called(ctx, commentID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCommentUseCase) GetCommentLikes(ctx context.Context, commentID int64, cursor string, limit int) (*entity.CommentLikePage, error) {
+	args := m.Called(ctx, commentID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CommentLikePage), args.Error(1)
+}
+
+func (m *MockCommentUseCase) GetCommentTree(ctx context.Context, query entity.CommentTreeQuery) (*entity.CommentTree, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CommentTree), args.Error(1)
+}
+
 func TestCommentServer_GetCommentsByTopic(t *testing.T) {
 	muc := new(MockCommentUseCase)
 	server := NewCommentServer(muc)
 	ctx := context.Background()
 	timeNow := time.Now()
 	comments := []*entity.Comment{{ID: 1, Content: "c1", AuthorID: 2, TopicID: 3, CreatedAt: timeNow, UpdatedAt: timeNow}}
-	muc.On("GetCommentsByTopicID", ctx, int64(3)).Return(comments, nil)
+	muc.On("GetCommentsByTopicID", ctx, int64(3), int64(0)).Return(comments, nil)
 
 	resp, err := server.GetCommentsByTopic(ctx, &proto.GetCommentsByTopicRequest{TopicId: "3"})
 	assert.NoError(t, err)
@@ -55,7 +93,7 @@ func TestCommentServer_GetCommentsByTopic(t *testing.T) {
 	assert.Equal(t, int64(1), resp.Comments[0].Id)
 
 	// error case
-	muc.On("GetCommentsByTopicID", ctx, int64(99)).Return([]*entity.Comment{}, errors.New("fail"))
+	muc.On("GetCommentsByTopicID", ctx, int64(99), int64(0)).Return([]*entity.Comment{}, errors.New("fail"))
 	_, err = server.GetCommentsByTopic(ctx, &proto.GetCommentsByTopicRequest{TopicId: "99"})
 	assert.Error(t, err)
 
@@ -70,14 +108,14 @@ func TestCommentServer_GetCommentByID(t *testing.T) {
 	ctx := context.Background()
 	timeNow := time.Now()
 	comment := &entity.Comment{ID: 1, Content: "c1", AuthorID: 2, TopicID: 3, CreatedAt: timeNow, UpdatedAt: timeNow}
-	muc.On("GetCommentByID", ctx, int64(1)).Return(comment, nil)
+	muc.On("GetCommentByID", ctx, int64(1), int64(0)).Return(comment, nil)
 
 	resp, err := server.GetCommentByID(ctx, &proto.GetCommentByIDRequest{CommentId: "1"})
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), resp.Comment.Id)
 
 	// error case
-	muc.On("GetCommentByID", ctx, int64(99)).Return(&entity.Comment{}, errors.New("fail"))
+	muc.On("GetCommentByID", ctx, int64(99), int64(0)).Return(&entity.Comment{}, errors.New("fail"))
 	_, err = server.GetCommentByID(ctx, &proto.GetCommentByIDRequest{CommentId: "99"})
 	assert.Error(t, err)
 
@@ -89,13 +127,14 @@ func TestCommentServer_GetCommentByID(t *testing.T) {
 func TestCommentServer_CreateComment(t *testing.T) {
 	muc := new(MockCommentUseCase)
 	server := NewCommentServer(muc)
-	ctx := context.Background()
-	muc.On("CreateComment", ctx, mock.AnythingOfType("*entity.Comment")).Return(nil)
+	ctx := ContextWithUserID(context.Background(), 2)
+	muc.On("CreateComment", ctx, mock.MatchedBy(func(c *entity.Comment) bool { return c.AuthorID == 2 })).Return(nil)
 
-	req := &proto.CreateCommentRequest{Content: "c1", AuthorId: 2, TopicId: "3"}
+	req := &proto.CreateCommentRequest{Content: "c1", TopicId: "3"}
 	resp, err := server.CreateComment(ctx, req)
 	assert.NoError(t, err)
 	assert.Equal(t, "c1", resp.Comment.Content)
+	assert.Equal(t, int64(2), resp.Comment.AuthorId)
 
 	// error case
 	muc.ExpectedCalls = nil
@@ -103,48 +142,152 @@ func TestCommentServer_CreateComment(t *testing.T) {
 	_, err = server.CreateComment(ctx, req)
 	assert.Error(t, err)
 
+	// locked topic
+	muc.ExpectedCalls = nil
+	muc.On("CreateComment", ctx, mock.AnythingOfType("*entity.Comment")).Return(repository.ErrTopicLocked)
+	_, err = server.CreateComment(ctx, req)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// anonymous caller
+	_, err = server.CreateComment(context.Background(), &proto.CreateCommentRequest{Content: "c1", AuthorId: 2, TopicId: "3"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// posting as someone else
+	_, err = server.CreateComment(ctx, &proto.CreateCommentRequest{Content: "c1", AuthorId: 5, TopicId: "3"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
 	// invalid id
-	badReq := &proto.CreateCommentRequest{Content: "c1", AuthorId: 2, TopicId: "bad"}
+	badReq := &proto.CreateCommentRequest{Content: "c1", TopicId: "bad"}
 	_, err = server.CreateComment(ctx, badReq)
 	assert.Error(t, err)
 }
 
+func TestCommentServer_UpdateComment(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	server := NewCommentServer(muc)
+	ctx := ContextWithUserID(context.Background(), 2)
+	editedAt := time.Now()
+	muc.On("UpdateComment", ctx, int64(1), int64(2), "c2").
+		Return(&entity.Comment{ID: 1, Content: "c2", AuthorID: 2, TopicID: 3, Edited: true, EditedAt: &editedAt}, nil)
+
+	resp, err := server.UpdateComment(ctx, &proto.UpdateCommentRequest{CommentId: "1", Content: "c2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "c2", resp.Comment.Content)
+	assert.True(t, resp.Comment.Edited)
+	assert.NotNil(t, resp.Comment.EditedAt)
+
+	// user_id of the caller is still accepted
+	_, err = server.UpdateComment(ctx, &proto.UpdateCommentRequest{CommentId: "1", UserId: 2, Content: "c2"})
+	assert.NoError(t, err)
+
+	// not the author
+	otherCtx := ContextWithUserID(context.Background(), 5)
+	muc.On("UpdateComment", otherCtx, int64(1), int64(5), "c2").Return(nil, policy.ErrForbidden)
+	_, err = server.UpdateComment(otherCtx, &proto.UpdateCommentRequest{CommentId: "1", Content: "c2"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// claiming to be the author doesn't help
+	_, err = server.UpdateComment(otherCtx, &proto.UpdateCommentRequest{CommentId: "1", UserId: 2, Content: "c2"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	muc.AssertNotCalled(t, "UpdateComment", otherCtx, int64(1), int64(2), "c2")
+
+	// anonymous caller
+	_, err = server.UpdateComment(context.Background(), &proto.UpdateCommentRequest{CommentId: "1", UserId: 2, Content: "c2"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// invalid id
+	_, err = server.UpdateComment(ctx, &proto.UpdateCommentRequest{CommentId: "bad", Content: "c2"})
+	assert.Error(t, err)
+}
+
 func TestCommentServer_DeleteComment(t *testing.T) {
 	muc := new(MockCommentUseCase)
 	server := NewCommentServer(muc)
-	ctx := context.Background()
-	muc.On("DeleteComment", ctx, int64(1)).Return(nil)
+	ctx := ContextWithUserID(context.Background(), 2)
+	muc.On("DeleteComment", ctx, int64(1), int64(2)).Return(nil)
 
 	resp, err := server.DeleteComment(ctx, &proto.DeleteCommentRequest{CommentId: "1"})
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 
+	// not the author
+	otherCtx := ContextWithUserID(context.Background(), 5)
+	muc.On("DeleteComment", otherCtx, int64(1), int64(5)).Return(policy.ErrForbidden)
+	_, err = server.DeleteComment(otherCtx, &proto.DeleteCommentRequest{CommentId: "1"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
 	// error case
-	muc.On("DeleteComment", ctx, int64(99)).Return(errors.New("fail"))
-	_, err = server.DeleteComment(ctx, &proto.DeleteCommentRequest{CommentId: "99"})
+	muc.On("DeleteComment", ctx, int64(99), int64(2)).Return(errors.New("fail"))
+	_, err = server.DeleteComment(ctx, &proto.DeleteCommentRequest{CommentId: "99", UserId: 2})
 	assert.Error(t, err)
 
+	// anonymous caller
+	_, err = server.DeleteComment(context.Background(), &proto.DeleteCommentRequest{CommentId: "1", UserId: 2})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
 	// invalid id
 	_, err = server.DeleteComment(ctx, &proto.DeleteCommentRequest{CommentId: "bad"})
 	assert.Error(t, err)
 }
 
+func TestCommentServer_GetCommentsByTopic_Viewer(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	server := NewCommentServer(muc)
+	ctx := ContextWithUserID(context.Background(), 2)
+	muc.On("GetCommentsByTopicID", ctx, int64(3), int64(2)).Return([]*entity.Comment{{ID: 1, LikedByMe: true}}, nil)
+
+	// Лайки отмечаются для аутентифицированного вызывающего
+	resp, err := server.GetCommentsByTopic(ctx, &proto.GetCommentsByTopicRequest{TopicId: "3"})
+	assert.NoError(t, err)
+	assert.True(t, resp.Comments[0].LikedByMe)
+	muc.AssertExpectations(t)
+}
+
 func TestCommentServer_LikeComment(t *testing.T) {
 	muc := new(MockCommentUseCase)
 	server := NewCommentServer(muc)
-	ctx := context.Background()
-	muc.On("LikeComment", ctx, int64(1)).Return(nil)
+	ctx := ContextWithUserID(context.Background(), 2)
+	muc.On("LikeComment", ctx, int64(1), int64(2)).Return(4, nil)
 
 	resp, err := server.LikeComment(ctx, &proto.LikeCommentRequest{CommentId: "1"})
 	assert.NoError(t, err)
-	assert.NotNil(t, resp)
+	assert.Equal(t, int32(4), resp.Likes)
 
 	// error case
-	muc.On("LikeComment", ctx, int64(99)).Return(errors.New("fail"))
-	_, err = server.LikeComment(ctx, &proto.LikeCommentRequest{CommentId: "99"})
+	muc.On("LikeComment", ctx, int64(99), int64(2)).Return(0, errors.New("fail"))
+	_, err = server.LikeComment(ctx, &proto.LikeCommentRequest{CommentId: "99", UserId: 2})
 	assert.Error(t, err)
 
+	// anonymous caller
+	_, err = server.LikeComment(context.Background(), &proto.LikeCommentRequest{CommentId: "1", UserId: 2})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// liking on behalf of someone else
+	_, err = server.LikeComment(ctx, &proto.LikeCommentRequest{CommentId: "1", UserId: 5})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	muc.AssertNotCalled(t, "LikeComment", ctx, int64(1), int64(5))
+
 	// invalid id
 	_, err = server.LikeComment(ctx, &proto.LikeCommentRequest{CommentId: "bad"})
 	assert.Error(t, err)
 }
+
+func TestCommentServer_UnlikeComment(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	server := NewCommentServer(muc)
+	ctx := ContextWithUserID(context.Background(), 2)
+	muc.On("UnlikeComment", ctx, int64(1), int64(2)).Return(3, nil)
+
+	resp, err := server.UnlikeComment(ctx, &proto.UnlikeCommentRequest{CommentId: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), resp.Likes)
+
+	// comment not found
+	muc.On("UnlikeComment", ctx, int64(99), int64(2)).Return(0, repository.ErrCommentNotFound)
+	_, err = server.UnlikeComment(ctx, &proto.UnlikeCommentRequest{CommentId: "99"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// anonymous caller
+	_, err = server.UnlikeComment(context.Background(), &proto.UnlikeCommentRequest{CommentId: "1", UserId: 2})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,340 @@
+package httpDelivery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// multipartOverhead is the room left in an upload request for the
+// multipart headers around the file
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler handles HTTP requests for uploaded files
+type AttachmentHandler struct {
+	attachmentService service.AttachmentService
+}
+
+// LinkAttachmentsRequest represents attaching uploads to a post
+// @Description Uploads of the current user that aren't attached to a post yet
+type LinkAttachmentsRequest struct {
+	AttachmentIDs []int64 `json:"attachment_ids" binding:"required" example:"7,8"`
+}
+
+// AttachmentResponse represents an uploaded file
+// @Description Uploaded file with download links that expire at url_expires_at; an upload without post_type isn't attached to a post yet
+type AttachmentResponse struct {
+	ID           int64  `json:"id" example:"7"`
+	UploaderID   int64  `json:"uploader_id" example:"3"`
+	PostType     string `json:"post_type,omitempty" example:"comment" enums:"topic,comment"`
+	PostID       int64  `json:"post_id,omitempty" example:"10"`
+	Filename     string `json:"filename" example:"screenshot.png"`
+	ContentType  string `json:"content_type" example:"image/png"`
+	Size         int64  `json:"size" example:"48213"`
+	CreatedAt    string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	URL          string `json:"url" example:"/api/v1/attachments/7/download?expires=1710500400&signature=9f2c"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty" example:"/api/v1/attachments/7/thumbnail?expires=1710500400&signature=41ab"`
+	URLExpiresAt string `json:"url_expires_at" example:"2024-03-15T11:00:00Z"`
+}
+
+func NewAttachmentHandler(attachmentService service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+	}
+}
+
+// @Summary Upload a file
+// @Description Upload a file to attach to a topic or comment later. The type is detected from the contents and must be on the allow-list. Uploads not attached within a grace period are removed
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "File"
+// @Success 201 {object} AttachmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.attachmentService.MaxUploadSize()+multipartOverhead)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.handleError(c, service.ErrAttachmentTooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(c.Request.Context(), userID.(int64), header.Filename, file)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// @Summary Get an attachment
+// @Description Get a file with fresh download links. An upload not attached to a post yet is seen only by the uploader
+// @Tags attachments
+// @Produce json
+// @Param id path int true "Attachment ID"
+// @Success 200 {object} AttachmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /attachments/{id} [get]
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	attachment, err := h.attachmentService.GetAttachment(c.Request.Context(), id, viewerID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// @Summary Delete an attachment
+// @Description Delete a file. The uploader may delete their files; moderators and admins may also delete files attached to posts
+// @Tags attachments
+// @Security BearerAuth
+// @Param id path int true "Attachment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /attachments/{id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.attachmentService.DeleteAttachment(c.Request.Context(), id, userID.(int64)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Download an attachment
+// @Description Download a file through a signed link from an attachment's url
+// @Tags attachments
+// @Produce octet-stream
+// @Param id path int true "Attachment ID"
+// @Param expires query int true "Link expiry as a Unix time"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /attachments/{id}/download [get]
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	h.serve(c, service.AttachmentOriginal)
+}
+
+// @Summary Download an attachment preview
+// @Description Download the JPEG preview of an image through a signed link from an attachment's thumbnail_url
+// @Tags attachments
+// @Produce jpeg
+// @Param id path int true "Attachment ID"
+// @Param expires query int true "Link expiry as a Unix time"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /attachments/{id}/thumbnail [get]
+func (h *AttachmentHandler) Thumbnail(c *gin.Context) {
+	h.serve(c, service.AttachmentThumbnail)
+}
+
+func (h *AttachmentHandler) serve(c *gin.Context, variant service.AttachmentVariant) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		h.handleError(c, service.ErrInvalidAttachmentSignature)
+		return
+	}
+
+	attachment, file, err := h.attachmentService.OpenAttachment(c.Request.Context(), id, variant, expires, c.Query("signature"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer file.Close()
+
+	// Браузер не должен угадывать тип, а показывать сам только картинки
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+	size := attachment.Size
+	if variant == service.AttachmentThumbnail {
+		size = -1
+	}
+	c.DataFromReader(http.StatusOK, size, attachment.ContentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          fmt.Sprintf("private, max-age=%d", max(int64(time.Until(time.Unix(expires, 0)).Seconds()), 0)),
+	})
+}
+
+// @Summary List the attachments of a topic
+// @Description Get the files attached to a topic and to its comments, with fresh download links
+// @Tags attachments
+// @Produce json
+// @Param id path int true "Topic ID"
+// @Success 200 {array} AttachmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/attachments [get]
+func (h *AttachmentHandler) ListTopicAttachments(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	attachments, err := h.attachmentService.GetTopicAttachments(c.Request.Context(), topicID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// @Summary Attach files to a topic
+// @Description Attach uploads of the current user to a topic they may edit
+// @Tags attachments
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param attachments body LinkAttachmentsRequest true "Uploads to attach"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/attachments [post]
+func (h *AttachmentHandler) LinkTopicAttachments(c *gin.Context) {
+	h.link(c, entity.AttachmentPostTopic)
+}
+
+// @Summary Attach files to a comment
+// @Description Attach uploads of the current user to a comment they may edit
+// @Tags attachments
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "Comment ID"
+// @Param attachments body LinkAttachmentsRequest true "Uploads to attach"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /comments/{id}/attachments [post]
+func (h *AttachmentHandler) LinkCommentAttachments(c *gin.Context) {
+	h.link(c, entity.AttachmentPostComment)
+}
+
+func (h *AttachmentHandler) link(c *gin.Context, postType entity.AttachmentPostType) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s ID", postType)})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req LinkAttachmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.attachmentService.LinkAttachments(c.Request.Context(), userID.(int64), postType, postID, req.AttachmentIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AttachmentHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrEmptyAttachment),
+		errors.Is(err, service.ErrInvalidAttachmentPost),
+		errors.Is(err, service.ErrNoAttachments),
+		errors.Is(err, service.ErrTooManyAttachments):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAttachmentTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrForbidden),
+		errors.Is(err, service.ErrInvalidAttachmentSignature):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrAttachmentNotFound),
+		errors.Is(err, repository.ErrTopicNotFound),
+		errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling attachment request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,239 @@
+package httpDelivery
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAttachmentService struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentService) MaxUploadSize() int64 {
+	return int64(m.Called().Int(0))
+}
+
+func (m *MockAttachmentService) Upload(ctx context.Context, userID int64, filename string, r io.Reader) (*entity.Attachment, error) {
+	data, _ := io.ReadAll(r)
+	args := m.Called(ctx, userID, filename, string(data))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) GetAttachment(ctx context.Context, id, viewerID int64) (*entity.Attachment, error) {
+	args := m.Called(ctx, id, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) GetTopicAttachments(ctx context.Context, topicID int64) ([]*entity.Attachment, error) {
+	args := m.Called(ctx, topicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) LinkAttachments(ctx context.Context, userID int64, postType entity.AttachmentPostType, postID int64, ids []int64) error {
+	args := m.Called(ctx, userID, postType, postID, ids)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentService) DeleteAttachment(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentService) OpenAttachment(ctx context.Context, id int64, variant service.AttachmentVariant, expires int64, signature string) (*entity.Attachment, io.ReadCloser, error) {
+	args := m.Called(ctx, id, variant, expires, signature)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.Attachment), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
+func setupAttachmentRouter(attachmentService service.AttachmentService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewAttachmentHandler(attachmentService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.POST("/attachments", withUser, h.UploadAttachment)
+	r.GET("/attachments/:id", h.GetAttachment)
+	r.DELETE("/attachments/:id", withUser, h.DeleteAttachment)
+	r.GET("/attachments/:id/download", h.Download)
+	r.GET("/attachments/:id/thumbnail", h.Thumbnail)
+	r.GET("/topics/:id/attachments", h.ListTopicAttachments)
+	r.POST("/topics/:id/attachments", withUser, h.LinkTopicAttachments)
+	r.POST("/comments/:id/attachments", withUser, h.LinkCommentAttachments)
+	return r
+}
+
+func uploadRequest(field, filename, content string) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreateFormFile(field, filename)
+	part.Write([]byte(content))
+	w.Close()
+	req, _ := http.NewRequest("POST", "/attachments", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestAttachmentHandler_UploadAttachment(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      string
+		mock       bool
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", field: "file", mock: true, wantStatus: http.StatusCreated},
+		{name: "too large", field: "file", mock: true, mockErr: service.ErrAttachmentTooLarge, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "type not allowed", field: "file", mock: true, mockErr: service.ErrAttachmentTypeNotAllowed, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "missing file", field: "upload", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttachmentService)
+			mockService.On("MaxUploadSize").Return(1 << 20)
+			if tt.mock {
+				if tt.mockErr != nil {
+					mockService.On("Upload", mock.Anything, int64(5), "build.log", "panic: oops").Return(nil, tt.mockErr)
+				} else {
+					mockService.On("Upload", mock.Anything, int64(5), "build.log", "panic: oops").
+						Return(&entity.Attachment{ID: 7, UploaderID: 5, Filename: "build.log", ContentType: "text/plain"}, nil)
+				}
+			}
+			r := setupAttachmentRouter(mockService)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, uploadRequest(tt.field, "build.log", "panic: oops"))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttachmentHandler_UploadAttachment_BodyTooLarge(t *testing.T) {
+	mockService := new(MockAttachmentService)
+	mockService.On("MaxUploadSize").Return(10)
+	r := setupAttachmentRouter(mockService)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, uploadRequest("file", "big.log", strings.Repeat("a", multipartOverhead+100)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	mockService.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAttachmentHandler_Download(t *testing.T) {
+	mockService := new(MockAttachmentService)
+	r := setupAttachmentRouter(mockService)
+	expires := time.Now().Add(time.Hour).Unix()
+
+	mockService.On("OpenAttachment", mock.Anything, int64(7), service.AttachmentOriginal, expires, "good").Return(
+		&entity.Attachment{ID: 7, Filename: "build.log", ContentType: "text/plain", Size: 11},
+		io.NopCloser(strings.NewReader("panic: oops")), nil)
+	mockService.On("OpenAttachment", mock.Anything, int64(7), service.AttachmentThumbnail, expires, "bad").
+		Return(nil, nil, service.ErrInvalidAttachmentSignature)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/attachments/7/download?expires="+strconv.FormatInt(expires, 10)+"&signature=good", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "panic: oops", w.Body.String())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=build.log`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/attachments/7/thumbnail?expires="+strconv.FormatInt(expires, 10)+"&signature=bad", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Без срока ссылка недействительна
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/attachments/7/download?signature=good", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAttachmentHandler_LinkAttachments(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		postType   entity.AttachmentPostType
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "topic", path: "/topics/4/attachments", postType: entity.AttachmentPostTopic, wantStatus: http.StatusNoContent},
+		{name: "comment", path: "/comments/4/attachments", postType: entity.AttachmentPostComment, wantStatus: http.StatusNoContent},
+		{name: "not the author", path: "/topics/4/attachments", postType: entity.AttachmentPostTopic, mockErr: policy.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "foreign upload", path: "/comments/4/attachments", postType: entity.AttachmentPostComment, mockErr: repository.ErrAttachmentNotFound, wantStatus: http.StatusNotFound},
+		{name: "too many", path: "/topics/4/attachments", postType: entity.AttachmentPostTopic, mockErr: service.ErrTooManyAttachments, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttachmentService)
+			mockService.On("LinkAttachments", mock.Anything, int64(5), tt.postType, int64(4), []int64{7, 8}).Return(tt.mockErr)
+			r := setupAttachmentRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(`{"attachment_ids":[7,8]}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttachmentHandler_GetAndDelete(t *testing.T) {
+	mockService := new(MockAttachmentService)
+	r := setupAttachmentRouter(mockService)
+
+	mockService.On("GetAttachment", mock.Anything, int64(7), int64(0)).Return(nil, repository.ErrAttachmentNotFound)
+	mockService.On("GetTopicAttachments", mock.Anything, int64(4)).Return([]*entity.Attachment{{ID: 7}}, nil)
+	mockService.On("DeleteAttachment", mock.Anything, int64(7), int64(5)).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/attachments/7", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/topics/4/attachments", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/attachments/7", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
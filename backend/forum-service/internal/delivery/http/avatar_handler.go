@@ -0,0 +1,165 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// AvatarHandler handles HTTP requests for user avatars
+type AvatarHandler struct {
+	avatarService service.AvatarService
+}
+
+// UserResponse represents a user with their avatar
+// @Description User information; avatar is the URL of the uploaded or generated avatar
+type UserResponse struct {
+	ID       int64  `json:"id" example:"5"`
+	Username string `json:"username" example:"johndoe"`
+	Avatar   string `json:"avatar" example:"/api/v1/users/5/avatar?v=9f2c41ab07d3e6f5"`
+}
+
+func NewAvatarHandler(avatarService service.AvatarService) *AvatarHandler {
+	return &AvatarHandler{
+		avatarService: avatarService,
+	}
+}
+
+// @Summary Upload an avatar
+// @Description Replace the avatar of the current user with a PNG, JPEG or WebP picture. The middle square of the picture is kept and stored in several sizes
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Picture"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/me/avatar [put]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.avatarService.MaxUploadSize()+multipartOverhead)
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.handleError(c, service.ErrAvatarTooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	defer file.Close()
+
+	user, err := h.avatarService.UploadAvatar(c.Request.Context(), userID.(int64), file)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UserResponse{ID: user.ID, Username: user.Username, Avatar: user.Avatar})
+}
+
+// @Summary Remove the avatar
+// @Description Remove the uploaded avatar of the current user; a generated avatar is shown instead
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UserResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/me/avatar [delete]
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	user, err := h.avatarService.DeleteAvatar(c.Request.Context(), userID.(int64))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UserResponse{ID: user.ID, Username: user.Username, Avatar: user.Avatar})
+}
+
+// @Summary Get an avatar
+// @Description Get the avatar picture of a user: the smallest stored size of at least size pixels as PNG, or a generated SVG for users without an upload. Links with the v parameter from an author's avatar never change and may be cached for long
+// @Tags users
+// @Produce image/png
+// @Produce image/svg+xml
+// @Param id path int true "User ID"
+// @Param size query int false "Wanted size in pixels; the largest stored size if omitted"
+// @Param v query string false "Avatar version"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/avatar [get]
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	size := 0
+	if s := c.Query("size"); s != "" {
+		size, err = strconv.Atoi(s)
+		if err != nil || size < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid size"})
+			return
+		}
+	}
+
+	file, err := h.avatarService.OpenAvatar(c.Request.Context(), id, size)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer file.Body.Close()
+
+	// Ссылка с версией указывает на конкретный загруженный файл, а ссылка
+	// без нее меняется при загрузке нового аватара
+	cacheControl := "public, max-age=300"
+	if c.Query("v") != "" && !file.Generated {
+		cacheControl = "public, max-age=31536000, immutable"
+	}
+	c.DataFromReader(http.StatusOK, -1, file.ContentType, file.Body, map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'; style-src 'unsafe-inline'",
+		"Cache-Control":           cacheControl,
+	})
+}
+
+func (h *AvatarHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrEmptyAvatar),
+		errors.Is(err, service.ErrInvalidAvatarDimensions):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAvatarTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAvatarTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling avatar request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,179 @@
+package httpDelivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAvatarService struct {
+	mock.Mock
+}
+
+func (m *MockAvatarService) MaxUploadSize() int64 {
+	return int64(m.Called().Int(0))
+}
+
+func (m *MockAvatarService) UploadAvatar(ctx context.Context, userID int64, r io.Reader) (*entity.User, error) {
+	data, _ := io.ReadAll(r)
+	args := m.Called(ctx, userID, string(data))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockAvatarService) DeleteAvatar(ctx context.Context, userID int64) (*entity.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockAvatarService) OpenAvatar(ctx context.Context, userID int64, size int) (*service.AvatarFile, error) {
+	args := m.Called(ctx, userID, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.AvatarFile), args.Error(1)
+}
+
+func setupAvatarRouter(avatarService service.AvatarService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewAvatarHandler(avatarService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.GET("/users/suggest", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	r.PUT("/users/me/avatar", withUser, h.UploadAvatar)
+	r.DELETE("/users/me/avatar", withUser, h.DeleteAvatar)
+	r.GET("/users/:id/avatar", h.GetAvatar)
+	return r
+}
+
+func TestAvatarHandler_UploadAvatar(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      string
+		mock       bool
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", field: "file", mock: true, wantStatus: http.StatusOK},
+		{name: "bad dimensions", field: "file", mock: true, mockErr: service.ErrInvalidAvatarDimensions, wantStatus: http.StatusBadRequest},
+		{name: "too large", field: "file", mock: true, mockErr: service.ErrAvatarTooLarge, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "type not allowed", field: "file", mock: true, mockErr: service.ErrAvatarTypeNotAllowed, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "unknown user", field: "file", mock: true, mockErr: repository.ErrUserNotFound, wantStatus: http.StatusNotFound},
+		{name: "missing file", field: "avatar", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAvatarService)
+			mockService.On("MaxUploadSize").Return(1 << 20)
+			if tt.mock {
+				user := &entity.User{ID: 5, Username: "alice", Avatar: "/api/v1/users/5/avatar?v=abc"}
+				if tt.mockErr != nil {
+					user = nil
+				}
+				mockService.On("UploadAvatar", mock.Anything, int64(5), "picture").Return(user, tt.mockErr)
+			}
+
+			req := uploadRequest(tt.field, "me.png", "picture")
+			req.Method = http.MethodPut
+			req.URL, _ = url.Parse("/users/me/avatar")
+			w := httptest.NewRecorder()
+			setupAvatarRouter(mockService).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var resp UserResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "/api/v1/users/5/avatar?v=abc", resp.Avatar)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAvatarHandler_DeleteAvatar(t *testing.T) {
+	mockService := new(MockAvatarService)
+	mockService.On("DeleteAvatar", mock.Anything, int64(5)).
+		Return(&entity.User{ID: 5, Username: "alice", Avatar: "/api/v1/users/5/avatar"}, nil)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/users/me/avatar", nil)
+	w := httptest.NewRecorder()
+	setupAvatarRouter(mockService).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"avatar":"/api/v1/users/5/avatar"`)
+}
+
+func TestAvatarHandler_GetAvatar(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		size        int
+		file        *service.AvatarFile
+		err         error
+		wantStatus  int
+		wantCaching string
+	}{
+		{
+			name:        "uploaded",
+			url:         "/users/5/avatar?size=64&v=abc",
+			size:        64,
+			file:        &service.AvatarFile{ContentType: "image/png", Body: io.NopCloser(strings.NewReader("png"))},
+			wantStatus:  http.StatusOK,
+			wantCaching: "public, max-age=31536000, immutable",
+		},
+		{
+			name:        "generated",
+			url:         "/users/5/avatar?v=abc",
+			file:        &service.AvatarFile{ContentType: "image/svg+xml", Body: io.NopCloser(strings.NewReader("<svg/>")), Generated: true},
+			wantStatus:  http.StatusOK,
+			wantCaching: "public, max-age=300",
+		},
+		{name: "invalid size", url: "/users/5/avatar?size=big", wantStatus: http.StatusBadRequest},
+		{name: "invalid id", url: "/users/abc/avatar", wantStatus: http.StatusBadRequest},
+		{name: "storage error", url: "/users/5/avatar", err: errors.New("storage down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAvatarService)
+			if tt.file != nil || tt.err != nil {
+				var file any
+				if tt.file != nil {
+					file = tt.file
+				}
+				mockService.On("OpenAvatar", mock.Anything, int64(5), tt.size).Return(file, tt.err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			setupAvatarRouter(mockService).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.file != nil {
+				assert.Equal(t, tt.file.ContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+				assert.Equal(t, tt.wantCaching, w.Header().Get("Cache-Control"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
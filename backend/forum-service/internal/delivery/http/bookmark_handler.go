@@ -0,0 +1,277 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// BookmarkHandler handles HTTP requests for the current user's bookmarks
+type BookmarkHandler struct {
+	bookmarkService service.BookmarkService
+}
+
+// CreateBookmarkRequest represents a new bookmark
+// @Description Topic or comment to bookmark, with an optional note and reminder
+type CreateBookmarkRequest struct {
+	TargetType string     `json:"target_type" binding:"required" example:"comment" enums:"topic,comment"`
+	TargetID   int64      `json:"target_id" binding:"required" example:"10"`
+	Note       string     `json:"note" example:"Explains context cancellation"`
+	RemindAt   *time.Time `json:"remind_at" example:"2024-03-20T09:00:00Z"`
+}
+
+// UpdateBookmarkRequest represents a change of a bookmark
+// @Description New note and reminder; a missing reminder removes it
+type UpdateBookmarkRequest struct {
+	Note     string     `json:"note" example:"Explains context cancellation"`
+	RemindAt *time.Time `json:"remind_at" example:"2024-03-20T09:00:00Z"`
+}
+
+// BookmarkResponse represents a bookmark
+// @Description Bookmarked topic or comment; a deleted or hidden target is not available and has no title or excerpt
+type BookmarkResponse struct {
+	ID         int64  `json:"id" example:"1"`
+	UserID     int64  `json:"user_id" example:"3"`
+	TargetType string `json:"target_type" example:"comment" enums:"topic,comment"`
+	TargetID   int64  `json:"target_id" example:"10"`
+	TopicID    int64  `json:"topic_id,omitempty" example:"4"`
+	Title      string `json:"title,omitempty" example:"Go generics"`
+	Excerpt    string `json:"excerpt,omitempty" example:"Cancel the context before..."`
+	Available  bool   `json:"available" example:"true"`
+	Note       string `json:"note,omitempty" example:"Explains context cancellation"`
+	RemindAt   string `json:"remind_at,omitempty" example:"2024-03-20T09:00:00Z"`
+	RemindedAt string `json:"reminded_at,omitempty" example:"2024-03-20T09:00:30Z"`
+	CreatedAt  string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	UpdatedAt  string `json:"updated_at" example:"2024-03-15T10:05:00Z"`
+}
+
+// BookmarkListResponse represents a page of bookmarks
+// @Description Page of bookmarks, newest first
+type BookmarkListResponse struct {
+	Bookmarks  []BookmarkResponse `json:"bookmarks"`
+	NextCursor string             `json:"next_cursor,omitempty" example:"eyJrIjoiYm9va21hcmtzIn0"`
+}
+
+func NewBookmarkHandler(bookmarkService service.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkService: bookmarkService,
+	}
+}
+
+// @Summary Bookmark a topic or comment
+// @Description Save a topic or comment for later with an optional note and a reminder, which arrives as a notification
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bookmark body CreateBookmarkRequest true "Bookmark"
+// @Success 201 {object} BookmarkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bookmarks [post]
+func (h *BookmarkHandler) CreateBookmark(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmark := &entity.Bookmark{
+		UserID:     userID.(int64),
+		TargetType: entity.BookmarkTargetType(req.TargetType),
+		TargetID:   req.TargetID,
+		Note:       req.Note,
+		RemindAt:   req.RemindAt,
+	}
+	if err := h.bookmarkService.CreateBookmark(c.Request.Context(), bookmark); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, bookmark)
+}
+
+// @Summary List my bookmarks
+// @Description Get the current user's bookmarks, newest first
+// @Tags bookmarks
+// @Produce json
+// @Security BearerAuth
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} BookmarkListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bookmarks [get]
+func (h *BookmarkHandler) ListBookmarks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	filter := entity.BookmarkFilter{
+		UserID: userID.(int64),
+		Cursor: c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	page, err := h.bookmarkService.ListBookmarks(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Get a bookmark
+// @Description Get one of the current user's bookmarks
+// @Tags bookmarks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bookmark ID"
+// @Success 200 {object} BookmarkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bookmarks/{id} [get]
+func (h *BookmarkHandler) GetBookmark(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bookmark ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	bookmark, err := h.bookmarkService.GetBookmark(c.Request.Context(), userID.(int64), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// @Summary Update a bookmark
+// @Description Replace the note and the reminder of one of the current user's bookmarks. A changed reminder fires again
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bookmark ID"
+// @Param bookmark body UpdateBookmarkRequest true "Note and reminder"
+// @Success 200 {object} BookmarkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bookmarks/{id} [put]
+func (h *BookmarkHandler) UpdateBookmark(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bookmark ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req UpdateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmark := &entity.Bookmark{
+		ID:       id,
+		UserID:   userID.(int64),
+		Note:     req.Note,
+		RemindAt: req.RemindAt,
+	}
+	if err := h.bookmarkService.UpdateBookmark(c.Request.Context(), bookmark); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// @Summary Delete a bookmark
+// @Description Delete one of the current user's bookmarks
+// @Tags bookmarks
+// @Security BearerAuth
+// @Param id path int true "Bookmark ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bookmarks/{id} [delete]
+func (h *BookmarkHandler) DeleteBookmark(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bookmark ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.bookmarkService.DeleteBookmark(c.Request.Context(), userID.(int64), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *BookmarkHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookmarkTarget),
+		errors.Is(err, service.ErrBookmarkNoteTooLong),
+		errors.Is(err, service.ErrReminderInPast),
+		errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrBookmarkNotFound),
+		errors.Is(err, repository.ErrTopicNotFound),
+		errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrBookmarkExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling bookmark request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,156 @@
+package httpDelivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBookmarkService struct {
+	mock.Mock
+}
+
+func (m *MockBookmarkService) CreateBookmark(ctx context.Context, bookmark *entity.Bookmark) error {
+	args := m.Called(ctx, bookmark)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkService) GetBookmark(ctx context.Context, userID, id int64) (*entity.Bookmark, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Bookmark), args.Error(1)
+}
+
+func (m *MockBookmarkService) ListBookmarks(ctx context.Context, filter entity.BookmarkFilter) (*entity.BookmarkPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BookmarkPage), args.Error(1)
+}
+
+func (m *MockBookmarkService) UpdateBookmark(ctx context.Context, bookmark *entity.Bookmark) error {
+	args := m.Called(ctx, bookmark)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkService) DeleteBookmark(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func setupBookmarkRouter(bookmarkService service.BookmarkService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewBookmarkHandler(bookmarkService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.GET("/bookmarks", withUser, h.ListBookmarks)
+	r.POST("/bookmarks", withUser, h.CreateBookmark)
+	r.GET("/bookmarks/:id", withUser, h.GetBookmark)
+	r.PUT("/bookmarks/:id", withUser, h.UpdateBookmark)
+	r.DELETE("/bookmarks/:id", withUser, h.DeleteBookmark)
+	return r
+}
+
+func TestBookmarkHandler_CreateBookmark(t *testing.T) {
+	remindAt := time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		body       string
+		mock       bool
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", body: `{"target_type":"comment","target_id":10,"note":"ctx","remind_at":"2024-03-20T09:00:00Z"}`, mock: true, wantStatus: http.StatusCreated},
+		{name: "already bookmarked", body: `{"target_type":"comment","target_id":10,"note":"ctx","remind_at":"2024-03-20T09:00:00Z"}`, mock: true, mockErr: repository.ErrBookmarkExists, wantStatus: http.StatusConflict},
+		{name: "target deleted", body: `{"target_type":"comment","target_id":10,"note":"ctx","remind_at":"2024-03-20T09:00:00Z"}`, mock: true, mockErr: repository.ErrCommentNotFound, wantStatus: http.StatusNotFound},
+		{name: "past reminder", body: `{"target_type":"comment","target_id":10,"note":"ctx","remind_at":"2024-03-20T09:00:00Z"}`, mock: true, mockErr: service.ErrReminderInPast, wantStatus: http.StatusBadRequest},
+		{name: "missing target", body: `{"target_type":"comment"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBookmarkService)
+			if tt.mock {
+				mockService.On("CreateBookmark", mock.Anything, mock.MatchedBy(func(b *entity.Bookmark) bool {
+					return b.UserID == 5 && b.TargetType == entity.BookmarkTargetComment && b.TargetID == 10 &&
+						b.Note == "ctx" && b.RemindAt != nil && b.RemindAt.Equal(remindAt)
+				})).Return(tt.mockErr)
+			}
+			r := setupBookmarkRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/bookmarks", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBookmarkHandler_ListBookmarks(t *testing.T) {
+	mockService := new(MockBookmarkService)
+	r := setupBookmarkRouter(mockService)
+
+	mockService.On("ListBookmarks", mock.Anything, entity.BookmarkFilter{UserID: 5, Limit: 10}).Return(&entity.BookmarkPage{
+		Bookmarks: []*entity.Bookmark{
+			{ID: 2, UserID: 5, TargetType: entity.BookmarkTargetTopic, TargetID: 7},
+			{ID: 1, UserID: 5, TargetType: entity.BookmarkTargetComment, TargetID: 10, TopicID: 4, Title: "Go generics", Available: true},
+		},
+		NextCursor: "next",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/bookmarks?limit=10", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp BookmarkListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Bookmarks, 2)
+	assert.False(t, resp.Bookmarks[0].Available)
+	assert.Equal(t, "Go generics", resp.Bookmarks[1].Title)
+	assert.Equal(t, "next", resp.NextCursor)
+	mockService.AssertExpectations(t)
+}
+
+func TestBookmarkHandler_UpdateAndDelete(t *testing.T) {
+	mockService := new(MockBookmarkService)
+	r := setupBookmarkRouter(mockService)
+
+	// Без remind_at напоминание снимается
+	mockService.On("UpdateBookmark", mock.Anything, &entity.Bookmark{ID: 1, UserID: 5, Note: "new"}).Return(nil)
+	mockService.On("DeleteBookmark", mock.Anything, int64(5), int64(1)).Return(repository.ErrBookmarkNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/bookmarks/1", bytes.NewBufferString(`{"note":"new"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/bookmarks/1", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/bookmarks/abc", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
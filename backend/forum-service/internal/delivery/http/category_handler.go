@@ -0,0 +1,218 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// CategoryHandler handles HTTP requests for categories
+type CategoryHandler struct {
+	categoryService service.CategoryService
+}
+
+// CategoryResponse represents a category with its statistics
+// @Description Category information with topic count and latest activity
+type CategoryResponse struct {
+	ID             int64                   `json:"id" example:"1"`
+	Name           string                  `json:"name" example:"General"`
+	Description    string                  `json:"description" example:"General discussion"`
+	Position       int                     `json:"position" example:"1"`
+	TopicCount     int                     `json:"topic_count" example:"42"`
+	LatestActivity *CategoryActivityResult `json:"latest_activity,omitempty"`
+	CreatedAt      string                  `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	UpdatedAt      string                  `json:"updated_at" example:"2024-03-15T10:00:00Z"`
+}
+
+// CategoryActivityResult represents the most recently active topic of a category
+// @Description Most recently active topic of a category
+type CategoryActivityResult struct {
+	TopicID    int64  `json:"topic_id" example:"7"`
+	TopicTitle string `json:"topic_title" example:"How to use Go"`
+	At         string `json:"at" example:"2024-03-15T10:00:00Z"`
+}
+
+// CategoryRequest represents the editable fields of a category
+// @Description Category name and description
+type CategoryRequest struct {
+	Name        string `json:"name" binding:"required" example:"General"`
+	Description string `json:"description" example:"General discussion"`
+}
+
+// CategoryOrderRequest lists category IDs in their new display order
+// @Description Category IDs in display order
+type CategoryOrderRequest struct {
+	IDs []int64 `json:"ids" binding:"required" example:"3,1,2"`
+}
+
+func NewCategoryHandler(categoryService service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// @Summary Get all categories
+// @Description Get categories in display order with topic counts and the latest activity
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Success 200 {array} CategoryResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /categories [get]
+func (h *CategoryHandler) GetAllCategories(c *gin.Context) {
+	categories, err := h.categoryService.GetAllCategories(c.Request.Context())
+	if err != nil {
+		log.Printf("Error getting categories: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// @Summary Create a category
+// @Description Create a new category at the end of the list. Admin only
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param category body CategoryRequest true "Category information"
+// @Success 201 {object} CategoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := &entity.Category{Name: req.Name, Description: req.Description}
+	if err := h.categoryService.CreateCategory(c.Request.Context(), category); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// @Summary Update a category
+// @Description Update the name and description of a category. Admin only
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category ID"
+// @Param category body CategoryRequest true "Category information"
+// @Success 200 {object} CategoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := &entity.Category{ID: categoryID, Name: req.Name, Description: req.Description}
+	if err := h.categoryService.UpdateCategory(c.Request.Context(), category); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// @Summary Reorder categories
+// @Description Set the display order of categories. Categories not listed keep their position. Admin only
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param order body CategoryOrderRequest true "Category IDs in display order"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /categories/order [put]
+func (h *CategoryHandler) ReorderCategories(c *gin.Context) {
+	var req CategoryOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.categoryService.ReorderCategories(c.Request.Context(), req.IDs); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Categories reordered"})
+}
+
+// @Summary Delete a category
+// @Description Delete a category that has no topics. Admin only
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(c.Request.Context(), categoryID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CategoryHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCategoryName), errors.Is(err, service.ErrInvalidCategoryOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrCategoryExists), errors.Is(err, repository.ErrCategoryNotEmpty):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling category request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
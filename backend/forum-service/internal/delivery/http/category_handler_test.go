@@ -0,0 +1,144 @@
+package httpDelivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCategoryService struct {
+	mock.Mock
+}
+
+func (m *MockCategoryService) CreateCategory(ctx context.Context, category *entity.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryService) GetAllCategories(ctx context.Context) ([]*entity.Category, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryService) ReorderCategories(ctx context.Context, ids []int64) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockCategoryService) DeleteCategory(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func setupCategoryRouter(categoryService service.CategoryService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewCategoryHandler(categoryService)
+	r.GET("/categories", h.GetAllCategories)
+	r.POST("/categories", h.CreateCategory)
+	r.PUT("/categories/order", h.ReorderCategories)
+	r.PUT("/categories/:id", h.UpdateCategory)
+	r.DELETE("/categories/:id", h.DeleteCategory)
+	return r
+}
+
+func TestCategoryHandler_GetAllCategories(t *testing.T) {
+	mockService := new(MockCategoryService)
+	r := setupCategoryRouter(mockService)
+
+	mockService.On("GetAllCategories", mock.Anything).Return([]*entity.Category{
+		{ID: 1, Name: "Общие вопросы", TopicCount: 3, LatestActivity: &entity.CategoryActivity{TopicID: 5}},
+		{ID: 2, Name: "Обсуждения"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/categories", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []CategoryResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 2)
+	assert.Equal(t, 3, resp[0].TopicCount)
+	assert.Equal(t, int64(5), resp[0].LatestActivity.TopicID)
+	assert.Nil(t, resp[1].LatestActivity)
+}
+
+func TestCategoryHandler_CreateCategory(t *testing.T) {
+	mockService := new(MockCategoryService)
+	r := setupCategoryRouter(mockService)
+
+	mockService.On("CreateCategory", mock.Anything, mock.MatchedBy(func(c *entity.Category) bool {
+		return c.Name == "Новости"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Category).ID = 4
+	}).Return(nil)
+
+	body, _ := json.Marshal(CategoryRequest{Name: "Новости"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/categories", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp CategoryResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(4), resp.ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestCategoryHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		mockMethod string
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "create without name", method: "POST", url: "/categories", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "create invalid name", method: "POST", url: "/categories", body: `{"name":" "}`, mockMethod: "CreateCategory", mockErr: service.ErrInvalidCategoryName, wantStatus: http.StatusBadRequest},
+		{name: "create duplicate", method: "POST", url: "/categories", body: `{"name":"Обсуждения"}`, mockMethod: "CreateCategory", mockErr: repository.ErrCategoryExists, wantStatus: http.StatusConflict},
+		{name: "update invalid id", method: "PUT", url: "/categories/abc", body: `{"name":"x"}`, wantStatus: http.StatusBadRequest},
+		{name: "update not found", method: "PUT", url: "/categories/9", body: `{"name":"x"}`, mockMethod: "UpdateCategory", mockErr: repository.ErrCategoryNotFound, wantStatus: http.StatusNotFound},
+		{name: "reorder duplicate", method: "PUT", url: "/categories/order", body: `{"ids":[1,1]}`, mockMethod: "ReorderCategories", mockErr: service.ErrInvalidCategoryOrder, wantStatus: http.StatusBadRequest},
+		{name: "delete not empty", method: "DELETE", url: "/categories/1", mockMethod: "DeleteCategory", mockErr: repository.ErrCategoryNotEmpty, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCategoryService)
+			if tt.mockMethod != "" {
+				mockService.On(tt.mockMethod, mock.Anything, mock.Anything).Return(tt.mockErr)
+			}
+			r := setupCategoryRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package httpDelivery
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 	"github.com/sout1235/forum2/backend/forum-service/internal/usecase"
 )
@@ -19,7 +22,55 @@ type CommentHandler struct {
 // CommentRequest represents a request to create a comment
 // @Description Request body for creating a comment
 type CommentRequest struct {
-	Content string `json:"content" binding:"required" example:"This is a great post!"`
+	Content  string `json:"content" binding:"required" example:"This is a great post!"`
+	ParentID *int64 `json:"parent_id,omitempty" example:"5"`
+}
+
+// CommentUpdateRequest represents a request to edit a comment
+// @Description Request body for editing a comment
+type CommentUpdateRequest struct {
+	Content string `json:"content" binding:"required" example:"This is a great post! (edit: fixed a typo)"`
+}
+
+// CommentLikeResponse represents the like state of a comment after a like or unlike
+// @Description Number of likes and whether the current user likes the comment
+type CommentLikeResponse struct {
+	Likes     int  `json:"likes" example:"12"`
+	LikedByMe bool `json:"liked_by_me" example:"true"`
+}
+
+// CommentLikeUser represents a user who liked a comment
+// @Description User who liked a comment
+type CommentLikeUser struct {
+	UserID    int64  `json:"user_id" example:"1"`
+	Username  string `json:"username" example:"johndoe"`
+	Avatar    string `json:"avatar" example:"https://example.com/avatar.jpg"`
+	CreatedAt string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+}
+
+// CommentLikesResponse represents a page of users who liked a comment
+// @Description Users who liked a comment with a cursor to the next page
+type CommentLikesResponse struct {
+	Likes      []CommentLikeUser `json:"likes"`
+	NextCursor string            `json:"next_cursor,omitempty" example:"eyJrIjoibGlrZXMiLCJ2IjoiMjAyNC0wMy0xNVQxMDowMDowMFoiLCJpZCI6N30"`
+}
+
+// CommentNode represents a comment together with its loaded replies
+// @Description Comment with nested replies
+type CommentNode struct {
+	Comment
+	ParentID      *int64        `json:"parent_id,omitempty" example:"5"`
+	Depth         int           `json:"depth" example:"1"`
+	ReplyCount    int           `json:"reply_count,omitempty" example:"12"`
+	Replies       []CommentNode `json:"replies,omitempty"`
+	RepliesCursor string        `json:"replies_cursor,omitempty" example:"eyJrIjoicmVwbGllcyIsInYiOiIyMDI0LTAzLTE1VDEwOjAwOjAwWiIsImlkIjo3fQ"`
+}
+
+// CommentTreeResponse represents a page of a comment tree
+// @Description Top-level comments with nested replies and a cursor to the next page
+type CommentTreeResponse struct {
+	Comments   []CommentNode `json:"comments"`
+	NextCursor string        `json:"next_cursor,omitempty" example:"eyJrIjoicmVwbGllcyIsInYiOiIyMDI0LTAzLTE1VDEwOjAwOjAwWiIsImlkIjo0Mn0"`
 }
 
 func NewCommentHandler(commentUseCase usecase.CommentUseCase, userRepo repository.UserRepository) *CommentHandler {
@@ -45,7 +96,7 @@ func (h *CommentHandler) GetAllCommentsByTopic(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
 		return
 	}
-	comments, err := h.commentUseCase.GetCommentsByTopicID(c.Request.Context(), topicID)
+	comments, err := h.commentUseCase.GetCommentsByTopicID(c.Request.Context(), topicID, viewerID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -53,6 +104,77 @@ func (h *CommentHandler) GetAllCommentsByTopic(c *gin.Context) {
 	c.JSON(http.StatusOK, comments)
 }
 
+// @Summary Get the comment tree of a topic
+// @Description Get top-level comments of a topic with nested replies. Each comment carries reply_count; comments whose replies didn't all fit carry replies_cursor for /comments/{id}/replies
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Topic ID"
+// @Param depth query int false "Levels of replies to load" default(3) maximum(10)
+// @Param limit query int false "Comments per level" default(20) maximum(100)
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} CommentTreeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/comments/tree [get]
+func (h *CommentHandler) GetCommentTree(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	query, ok := parseCommentTreeQuery(c)
+	if !ok {
+		return
+	}
+	query.TopicID = topicID
+	query.ViewerID = viewerID(c)
+
+	tree, err := h.commentUseCase.GetCommentTree(c.Request.Context(), query)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// @Summary Load more replies to a comment
+// @Description Get replies to a comment with their own nested replies. Pass the comment's replies_cursor to continue after the replies already shown
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param depth query int false "Levels of replies to load" default(3) maximum(10)
+// @Param limit query int false "Comments per level" default(20) maximum(100)
+// @Param cursor query string false "replies_cursor of the comment or next_cursor of the previous page"
+// @Success 200 {object} CommentTreeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /comments/{id}/replies [get]
+func (h *CommentHandler) GetReplies(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	query, ok := parseCommentTreeQuery(c)
+	if !ok {
+		return
+	}
+	query.ParentID = &commentID
+	query.ViewerID = viewerID(c)
+
+	tree, err := h.commentUseCase.GetCommentTree(c.Request.Context(), query)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
 // @Summary Get a specific comment
 // @Description Get detailed information about a specific comment
 // @Tags comments
@@ -70,7 +192,7 @@ func (h *CommentHandler) GetComment(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
 		return
 	}
-	comment, err := h.commentUseCase.GetCommentByID(c.Request.Context(), commentID)
+	comment, err := h.commentUseCase.GetCommentByID(c.Request.Context(), commentID, viewerID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -79,7 +201,7 @@ func (h *CommentHandler) GetComment(c *gin.Context) {
 }
 
 // @Summary Create a new comment
-// @Description Create a new comment on a topic
+// @Description Create a new comment on a topic. Locked topics take no new comments
 // @Tags comments
 // @Accept json
 // @Produce json
@@ -89,6 +211,8 @@ func (h *CommentHandler) GetComment(c *gin.Context) {
 // @Success 201 {object} Comment
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /topics/{id}/comments [post]
 func (h *CommentHandler) CreateComment(c *gin.Context) {
@@ -97,18 +221,66 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Тема берется из пути, чтобы ответ нельзя было прикрепить к чужой теме
+	if topicID, err := strconv.ParseInt(c.Param("id"), 10, 64); err == nil {
+		comment.TopicID = topicID
+	}
 
 	err := h.commentUseCase.CreateComment(c.Request.Context(), &comment)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.handleError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, comment)
 }
 
+// @Summary Edit a comment
+// @Description Replace the content of a comment. Authors may edit within the configured edit window after posting; moderators and admins may edit at any time. Edited comments carry edited=true and edited_at
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param commentId path int true "Comment ID"
+// @Param comment body CommentUpdateRequest true "New comment content"
+// @Success 200 {object} Comment
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/comments/{commentId} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var req CommentUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	comment, err := h.commentUseCase.UpdateComment(c.Request.Context(), commentID, userID.(int64), req.Content)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
 // @Summary Delete a comment
-// @Description Delete a specific comment
+// @Description Delete a specific comment. Only the author, moderators and admins may do this. The comment stays in its thread as a "[deleted]" placeholder
 // @Tags comments
 // @Accept json
 // @Produce json
@@ -123,44 +295,202 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse
 // @Router /topics/{id}/comments/{commentId} [delete]
 func (h *CommentHandler) DeleteComment(c *gin.Context) {
-	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
 		return
 	}
 
-	err = h.commentUseCase.DeleteComment(c.Request.Context(), commentID)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	err = h.commentUseCase.DeleteComment(c.Request.Context(), commentID, userID.(int64))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.handleError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// @Summary Restore a comment
+// @Description Bring back a deleted comment. Moderators only
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Comment ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /comments/{id}/restore [post]
+func (h *CommentHandler) RestoreComment(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	if err := h.commentUseCase.RestoreComment(c.Request.Context(), commentID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment restored"})
+}
+
 // @Summary Like a comment
-// @Description Add a like to a specific comment
+// @Description Like a comment on behalf of the current user. Liking a comment again has no effect
 // @Tags comments
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path int true "Comment ID"
-// @Success 200 "OK"
+// @Success 200 {object} CommentLikeResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /comments/{id}/like [post]
 func (h *CommentHandler) LikeComment(c *gin.Context) {
+	h.changeLike(c, true)
+}
+
+// @Summary Unlike a comment
+// @Description Remove the current user's like from a comment
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Comment ID"
+// @Success 200 {object} CommentLikeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /comments/{id}/like [delete]
+func (h *CommentHandler) UnlikeComment(c *gin.Context) {
+	h.changeLike(c, false)
+}
+
+func (h *CommentHandler) changeLike(c *gin.Context, like bool) {
 	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
 		return
 	}
 
-	err = h.commentUseCase.LikeComment(c.Request.Context(), commentID)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var likes int
+	if like {
+		likes, err = h.commentUseCase.LikeComment(c.Request.Context(), commentID, userID.(int64))
+	} else {
+		likes, err = h.commentUseCase.UnlikeComment(c.Request.Context(), commentID, userID.(int64))
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.handleError(c, err)
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, CommentLikeResponse{Likes: likes, LikedByMe: like})
+}
+
+// @Summary List who liked a comment
+// @Description Get the users who liked a comment, most recent first
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param limit query int false "Items per page" default(20) maximum(100)
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} CommentLikesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /comments/{id}/likes [get]
+func (h *CommentHandler) GetCommentLikes(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+	}
+
+	page, err := h.commentUseCase.GetCommentLikes(c.Request.Context(), commentID, c.Query("cursor"), limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// viewerID returns the ID of the authenticated caller, or 0 for anonymous requests
+func viewerID(c *gin.Context) int64 {
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(int64); ok {
+			return id
+		}
+	}
+	return 0
+}
+
+// parseCommentTreeQuery reads depth, limit and cursor of a comment tree
+// request, answering 400 itself when one of them is malformed.
+func parseCommentTreeQuery(c *gin.Context) (entity.CommentTreeQuery, bool) {
+	query := entity.CommentTreeQuery{Cursor: c.Query("cursor")}
+	if depth := c.Query("depth"); depth != "" {
+		n, err := strconv.Atoi(depth)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid depth"})
+			return query, false
+		}
+		query.Depth = n
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return query, false
+		}
+		query.Limit = n
+	}
+	return query, true
+}
+
+func (h *CommentHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidParentComment), errors.Is(err, usecase.ErrEmptyComment),
+		errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrForbidden), errors.Is(err, usecase.ErrEditWindowExpired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrCommentNotFound), errors.Is(err, repository.ErrTopicNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrTopicLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling comment request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
@@ -2,13 +2,19 @@ package httpDelivery
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,13 +23,13 @@ type MockCommentUseCase struct {
 	mock.Mock
 }
 
-func (m *MockCommentUseCase) GetCommentsByTopicID(ctx context.Context, topicID int64) ([]*entity.Comment, error) {
-	args := m.Called(ctx, topicID)
+func (m *MockCommentUseCase) GetCommentsByTopicID(ctx context.Context, topicID, viewerID int64) ([]*entity.Comment, error) {
+	args := m.Called(ctx, topicID, viewerID)
 	return args.Get(0).([]*entity.Comment), args.Error(1)
 }
 
-func (m *MockCommentUseCase) GetCommentByID(ctx context.Context, commentID int64) (*entity.Comment, error) {
-	args := m.Called(ctx, commentID)
+func (m *MockCommentUseCase) GetCommentByID(ctx context.Context, commentID, viewerID int64) (*entity.Comment, error) {
+	args := m.Called(ctx, commentID, viewerID)
 	return args.Get(0).(*entity.Comment), args.Error(1)
 }
 
@@ -32,16 +38,50 @@ func (m *MockCommentUseCase) CreateComment(ctx context.Context, comment *entity.
 	return args.Error(0)
 }
 
-func (m *MockCommentUseCase) DeleteComment(ctx context.Context, commentID int64) error {
-	args := m.Called(ctx, commentID)
+func (m *MockCommentUseCase) UpdateComment(ctx context.Context, id, userID int64, content string) (*entity.Comment, error) {
+	args := m.Called(ctx, id, userID, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Comment), args.Error(1)
+}
+
+func (m *MockCommentUseCase) DeleteComment(ctx context.Context, commentID, userID int64) error {
+	args := m.Called(ctx, commentID, userID)
 	return args.Error(0)
 }
 
-func (m *MockCommentUseCase) LikeComment(ctx context.Context, commentID int64) error {
+func (m *MockCommentUseCase) RestoreComment(ctx context.Context, commentID int64) error {
 	args := m.Called(ctx, commentID)
 	return args.Error(0)
 }
 
+func (m *MockCommentUseCase) LikeComment(ctx context.Context, commentID, userID int64) (int, error) {
+	args := m.Called(ctx, commentID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCommentUseCase) UnlikeComment(ctx context.Context, commentID, userID int64) (int, error) {
+	args := m.Called(ctx, commentID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCommentUseCase) GetCommentLikes(ctx context.Context, commentID int64, cursor string, limit int) (*entity.CommentLikePage, error) {
+	args := m.Called(ctx, commentID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CommentLikePage), args.Error(1)
+}
+
+func (m *MockCommentUseCase) GetCommentTree(ctx context.Context, query entity.CommentTreeQuery) (*entity.CommentTree, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CommentTree), args.Error(1)
+}
+
 func TestCommentHandler_GetAllCommentsByTopic(t *testing.T) {
 	muc := new(MockCommentUseCase)
 	h := NewCommentHandler(muc, nil)
@@ -49,7 +89,7 @@ func TestCommentHandler_GetAllCommentsByTopic(t *testing.T) {
 	r.GET("/topics/:id/comments", h.GetAllCommentsByTopic)
 	timeNow := time.Now()
 	comments := []*entity.Comment{{ID: 1, Content: "c1", AuthorID: 2, TopicID: 3, CreatedAt: timeNow, UpdatedAt: timeNow}}
-	muc.On("GetCommentsByTopicID", mock.Anything, int64(3)).Return(comments, nil)
+	muc.On("GetCommentsByTopicID", mock.Anything, int64(3), int64(0)).Return(comments, nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/topics/3/comments", nil)
@@ -57,7 +97,7 @@ func TestCommentHandler_GetAllCommentsByTopic(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	// error case
-	muc.On("GetCommentsByTopicID", mock.Anything, int64(99)).Return([]*entity.Comment{}, errors.New("fail"))
+	muc.On("GetCommentsByTopicID", mock.Anything, int64(99), int64(0)).Return([]*entity.Comment{}, errors.New("fail"))
 	w = httptest.NewRecorder()
 	req, _ = http.NewRequest("GET", "/topics/99/comments", nil)
 	r.ServeHTTP(w, req)
@@ -77,7 +117,7 @@ func TestCommentHandler_GetComment(t *testing.T) {
 	r.GET("/comments/:id", h.GetComment)
 	timeNow := time.Now()
 	comment := &entity.Comment{ID: 1, Content: "c1", AuthorID: 2, TopicID: 3, CreatedAt: timeNow, UpdatedAt: timeNow}
-	muc.On("GetCommentByID", mock.Anything, int64(1)).Return(comment, nil)
+	muc.On("GetCommentByID", mock.Anything, int64(1), int64(0)).Return(comment, nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/comments/1", nil)
@@ -85,7 +125,7 @@ func TestCommentHandler_GetComment(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	// error case
-	muc.On("GetCommentByID", mock.Anything, int64(99)).Return(&entity.Comment{}, errors.New("fail"))
+	muc.On("GetCommentByID", mock.Anything, int64(99), int64(0)).Return(&entity.Comment{}, errors.New("fail"))
 	w = httptest.NewRecorder()
 	req, _ = http.NewRequest("GET", "/comments/99", nil)
 	r.ServeHTTP(w, req)
@@ -118,20 +158,89 @@ func TestCommentHandler_CreateComment(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestCommentHandler_UpdateComment(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	h := NewCommentHandler(muc, nil)
+	r, _ := setupTestRouter()
+	r.PUT("/topics/:id/comments/:commentId", func(c *gin.Context) {
+		c.Set("user_id", int64(2))
+		h.UpdateComment(c)
+	})
+
+	editedAt := time.Now()
+	muc.On("UpdateComment", mock.Anything, int64(1), int64(2), "edited").
+		Return(&entity.Comment{ID: 1, Content: "edited", AuthorID: 2, TopicID: 3, Edited: true, EditedAt: &editedAt}, nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/topics/3/comments/1", strings.NewReader(`{"content":"edited"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, true, body["edited"])
+	assert.NotEmpty(t, body["edited_at"])
+
+	// not the author
+	muc.On("UpdateComment", mock.Anything, int64(4), int64(2), "edited").Return(nil, policy.ErrForbidden)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/topics/3/comments/4", strings.NewReader(`{"content":"edited"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// edit window expired
+	muc.On("UpdateComment", mock.Anything, int64(5), int64(2), "edited").Return(nil, usecase.ErrEditWindowExpired)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/topics/3/comments/5", strings.NewReader(`{"content":"edited"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// missing content
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/topics/3/comments/1", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// invalid id
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/topics/3/comments/bad", strings.NewReader(`{"content":"edited"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestCommentHandler_DeleteComment(t *testing.T) {
 	muc := new(MockCommentUseCase)
 	h := NewCommentHandler(muc, nil)
 	r, _ := setupTestRouter()
-	r.DELETE("/topics/:topic_id/comments/:id", h.DeleteComment)
-	muc.On("DeleteComment", mock.Anything, int64(1)).Return(nil)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(2)) }
+	r.DELETE("/topics/:id/comments/:commentId", withUser, h.DeleteComment)
+	muc.On("DeleteComment", mock.Anything, int64(1), int64(2)).Return(nil)
 
+	// Идентификатор комментария берется из :commentId, а не из ID темы
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("DELETE", "/topics/3/comments/1", nil)
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNoContent, w.Code)
 
+	// not the author
+	muc.On("DeleteComment", mock.Anything, int64(4), int64(2)).Return(policy.ErrForbidden)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/topics/3/comments/4", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// not found
+	muc.On("DeleteComment", mock.Anything, int64(5), int64(2)).Return(repository.ErrCommentNotFound)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/topics/3/comments/5", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
 	// error case
-	muc.On("DeleteComment", mock.Anything, int64(99)).Return(errors.New("fail"))
+	muc.On("DeleteComment", mock.Anything, int64(99), int64(2)).Return(errors.New("fail"))
 	w = httptest.NewRecorder()
 	req, _ = http.NewRequest("DELETE", "/topics/3/comments/99", nil)
 	r.ServeHTTP(w, req)
@@ -144,28 +253,240 @@ func TestCommentHandler_DeleteComment(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestCommentHandler_RestoreComment(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	h := NewCommentHandler(muc, nil)
+	r, _ := setupTestRouter()
+	r.POST("/comments/:id/restore", h.RestoreComment)
+	muc.On("RestoreComment", mock.Anything, int64(1)).Return(nil)
+	muc.On("RestoreComment", mock.Anything, int64(5)).Return(repository.ErrCommentNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/comments/1/restore", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// комментарий не удален или не существует
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/comments/5/restore", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// invalid id
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/comments/bad/restore", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	muc.AssertExpectations(t)
+}
+
 func TestCommentHandler_LikeComment(t *testing.T) {
 	muc := new(MockCommentUseCase)
 	h := NewCommentHandler(muc, nil)
 	r, _ := setupTestRouter()
-	r.POST("/comments/:id/like", h.LikeComment)
-	muc.On("LikeComment", mock.Anything, int64(1)).Return(nil)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(2)) }
+	r.POST("/comments/:id/like", withUser, h.LikeComment)
+	r.DELETE("/comments/:id/like", withUser, h.UnlikeComment)
+	r.POST("/anonymous/:id/like", h.LikeComment)
+	muc.On("LikeComment", mock.Anything, int64(1), int64(2)).Return(5, nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/comments/1/like", nil)
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"likes":5,"liked_by_me":true}`, w.Body.String())
+
+	// unlike
+	muc.On("UnlikeComment", mock.Anything, int64(1), int64(2)).Return(4, nil)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/comments/1/like", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"likes":4,"liked_by_me":false}`, w.Body.String())
+
+	// comment not found
+	muc.On("LikeComment", mock.Anything, int64(98), int64(2)).Return(0, repository.ErrCommentNotFound)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/comments/98/like", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	// error case
-	muc.On("LikeComment", mock.Anything, int64(99)).Return(errors.New("fail"))
+	muc.On("LikeComment", mock.Anything, int64(99), int64(2)).Return(0, errors.New("fail"))
 	w = httptest.NewRecorder()
 	req, _ = http.NewRequest("POST", "/comments/99/like", nil)
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
+	// not authenticated
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/anonymous/1/like", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
 	// invalid id
 	w = httptest.NewRecorder()
 	req, _ = http.NewRequest("POST", "/comments/bad/like", nil)
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func TestCommentHandler_GetCommentLikes(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	h := NewCommentHandler(muc, nil)
+	r, _ := setupTestRouter()
+	r.GET("/comments/:id/likes", h.GetCommentLikes)
+
+	page := &entity.CommentLikePage{
+		Likes:      []*entity.CommentLike{{UserID: 2, Username: "bob", CreatedAt: time.Now()}},
+		NextCursor: "next",
+	}
+	muc.On("GetCommentLikes", mock.Anything, int64(1), "c", 10).Return(page, nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/comments/1/likes?cursor=c&limit=10", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Likes []struct {
+			Username string `json:"username"`
+		} `json:"likes"`
+		NextCursor string `json:"next_cursor"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "bob", body.Likes[0].Username)
+	assert.Equal(t, "next", body.NextCursor)
+
+	// comment not found
+	muc.On("GetCommentLikes", mock.Anything, int64(99), "", 0).Return(nil, repository.ErrCommentNotFound)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/comments/99/likes", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// invalid limit
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/comments/1/likes?limit=-1", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCommentHandler_GetComment_LikedByMe(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	h := NewCommentHandler(muc, nil)
+	r, _ := setupTestRouter()
+	r.GET("/comments/:id", func(c *gin.Context) { c.Set("user_id", int64(2)) }, h.GetComment)
+
+	muc.On("GetCommentByID", mock.Anything, int64(1), int64(2)).
+		Return(&entity.Comment{ID: 1, Likes: 1, LikedByMe: true}, nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/comments/1", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"liked_by_me":true`)
+}
+
+func TestCommentHandler_GetCommentTree(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	h := NewCommentHandler(muc, nil)
+	r, _ := setupTestRouter()
+	r.GET("/topics/:id/comments/tree", h.GetCommentTree)
+
+	tree := &entity.CommentTree{
+		Comments: []*entity.Comment{{
+			ID: 1, TopicID: 3, Depth: 1, ReplyCount: 1,
+			Replies: []*entity.Comment{{ID: 2, TopicID: 3, Depth: 2}},
+		}},
+		NextCursor: "next",
+	}
+	muc.On("GetCommentTree", mock.Anything, entity.CommentTreeQuery{TopicID: 3, Depth: 2, Limit: 10}).Return(tree, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/topics/3/comments/tree?depth=2&limit=10", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Comments []struct {
+			ID      int64 `json:"id"`
+			Replies []struct {
+				ID int64 `json:"id"`
+			} `json:"replies"`
+		} `json:"comments"`
+		NextCursor string `json:"next_cursor"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "next", body.NextCursor)
+	if assert.Len(t, body.Comments, 1) {
+		assert.Len(t, body.Comments[0].Replies, 1)
+	}
+
+	// invalid cursor
+	muc.On("GetCommentTree", mock.Anything, entity.CommentTreeQuery{TopicID: 3, Cursor: "bad"}).Return(nil, repository.ErrInvalidCursor)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/topics/3/comments/tree?cursor=bad", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// invalid depth
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/topics/3/comments/tree?depth=0", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCommentHandler_GetReplies(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	h := NewCommentHandler(muc, nil)
+	r, _ := setupTestRouter()
+	r.GET("/comments/:id/replies", h.GetReplies)
+
+	parentID := int64(5)
+	muc.On("GetCommentTree", mock.Anything, entity.CommentTreeQuery{ParentID: &parentID, Cursor: "c"}).
+		Return(&entity.CommentTree{Comments: []*entity.Comment{}}, nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/comments/5/replies?cursor=c", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	missingID := int64(99)
+	muc.On("GetCommentTree", mock.Anything, entity.CommentTreeQuery{ParentID: &missingID}).Return(nil, repository.ErrCommentNotFound)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/comments/99/replies", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCommentHandler_CreateComment_InvalidParent(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	h := NewCommentHandler(muc, nil)
+	r, _ := setupTestRouter()
+	r.POST("/topics/:id/comments", h.CreateComment)
+
+	// Тема берется из пути, а не из тела запроса
+	muc.On("CreateComment", mock.Anything, mock.MatchedBy(func(c *entity.Comment) bool {
+		return c.TopicID == 3 && c.ParentID != nil && *c.ParentID == 7
+	})).Return(usecase.ErrInvalidParentComment)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/topics/3/comments", strings.NewReader(`{"content":"reply","topic_id":4,"parent_id":7}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	muc.AssertExpectations(t)
+}
+
+func TestCommentHandler_CreateComment_LockedTopic(t *testing.T) {
+	muc := new(MockCommentUseCase)
+	h := NewCommentHandler(muc, nil)
+	r, _ := setupTestRouter()
+	r.POST("/topics/:id/comments", h.CreateComment)
+
+	muc.On("CreateComment", mock.Anything, mock.AnythingOfType("*entity.Comment")).Return(repository.ErrTopicLocked)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/topics/3/comments", strings.NewReader(`{"content":"late reply"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	muc.AssertExpectations(t)
+}
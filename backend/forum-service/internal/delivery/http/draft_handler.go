@@ -0,0 +1,266 @@
+package httpDelivery
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// DraftHandler handles HTTP requests for the current user's drafts
+type DraftHandler struct {
+	draftService service.DraftService
+}
+
+// SaveDraftRequest represents an autosave of a draft
+// @Description Text of a draft for a context. version is the version the text is based on: 0 for a new draft, otherwise the version returned by the previous save
+type SaveDraftRequest struct {
+	Context    string   `json:"context" binding:"required" example:"topic" enums:"topic,topic_reply,comment_reply"`
+	TargetID   int64    `json:"target_id" example:"0"`
+	Title      string   `json:"title" example:"Graceful shutdown in Go"`
+	Content    string   `json:"content" example:"Start with signal.NotifyContext..."`
+	CategoryID int64    `json:"category_id" example:"1"`
+	TagNames   []string `json:"tag_names" example:"go,concurrency"`
+	Version    int      `json:"version" example:"3"`
+}
+
+// PublishDraftRequest represents publishing of a draft
+// @Description Optional version the user saw last; publishing fails if the draft was saved elsewhere since
+type PublishDraftRequest struct {
+	Version int `json:"version" example:"4"`
+}
+
+// DraftResponse represents a draft
+// @Description Unpublished topic or reply of the current user
+type DraftResponse struct {
+	ID         int64    `json:"id" example:"7"`
+	UserID     int64    `json:"user_id" example:"3"`
+	Context    string   `json:"context" example:"topic" enums:"topic,topic_reply,comment_reply"`
+	TargetID   int64    `json:"target_id,omitempty" example:"0"`
+	Title      string   `json:"title,omitempty" example:"Graceful shutdown in Go"`
+	Content    string   `json:"content" example:"Start with signal.NotifyContext..."`
+	CategoryID int64    `json:"category_id,omitempty" example:"1"`
+	TagNames   []string `json:"tag_names" example:"go,concurrency"`
+	Version    int      `json:"version" example:"4"`
+	CreatedAt  string   `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	UpdatedAt  string   `json:"updated_at" example:"2024-03-15T10:05:00Z"`
+	ExpiresAt  string   `json:"expires_at" example:"2024-04-14T10:05:00Z"`
+}
+
+// DraftListResponse represents a page of drafts
+// @Description Page of open drafts, most recently saved first
+type DraftListResponse struct {
+	Drafts     []DraftResponse `json:"drafts"`
+	NextCursor string          `json:"next_cursor,omitempty" example:"eyJrIjoiZHJhZnRzIn0"`
+}
+
+// PublishedDraftResponse represents a published draft
+// @Description The topic or the comment the draft became
+type PublishedDraftResponse struct {
+	Topic   *TopicResponse `json:"topic,omitempty"`
+	Comment *Comment       `json:"comment,omitempty"`
+}
+
+func NewDraftHandler(draftService service.DraftService) *DraftHandler {
+	return &DraftHandler{
+		draftService: draftService,
+	}
+}
+
+// @Summary List my drafts
+// @Description Get the current user's open drafts, most recently saved first. With context (and target_id for replies) only the draft for that context is returned
+// @Tags drafts
+// @Produce json
+// @Security BearerAuth
+// @Param context query string false "Draft context" Enums(topic, topic_reply, comment_reply)
+// @Param target_id query int false "Topic or comment replied to"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} DraftListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /drafts [get]
+func (h *DraftHandler) ListDrafts(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	filter := entity.DraftFilter{
+		UserID:  userID.(int64),
+		Context: entity.DraftContext(c.Query("context")),
+		Cursor:  c.Query("cursor"),
+	}
+	if target := c.Query("target_id"); target != "" {
+		id, err := strconv.ParseInt(target, 10, 64)
+		if err != nil || id < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ID"})
+			return
+		}
+		filter.TargetID = id
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	page, err := h.draftService.ListDrafts(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Autosave a draft
+// @Description Create or replace the current user's draft for a context. Each save returns the new version, which the next save has to send; a save based on an older version is rejected with 409 so that two tabs don't overwrite each other
+// @Tags drafts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param draft body SaveDraftRequest true "Draft"
+// @Success 200 {object} DraftResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /drafts [put]
+func (h *DraftHandler) SaveDraft(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	draft := &entity.Draft{
+		UserID:     userID.(int64),
+		Context:    entity.DraftContext(req.Context),
+		TargetID:   req.TargetID,
+		Title:      req.Title,
+		Content:    req.Content,
+		CategoryID: req.CategoryID,
+		TagNames:   req.TagNames,
+		Version:    req.Version,
+	}
+	if err := h.draftService.SaveDraft(c.Request.Context(), draft); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// @Summary Discard a draft
+// @Description Delete one of the current user's drafts
+// @Tags drafts
+// @Security BearerAuth
+// @Param id path int true "Draft ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /drafts/{id} [delete]
+func (h *DraftHandler) DeleteDraft(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.draftService.DeleteDraft(c.Request.Context(), userID.(int64), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Publish a draft
+// @Description Create the topic or comment written in a draft and delete the draft, both at once
+// @Tags drafts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Draft ID"
+// @Param request body PublishDraftRequest false "Version the user saw last"
+// @Success 201 {object} PublishedDraftResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /drafts/{id}/publish [post]
+func (h *DraftHandler) PublishDraft(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// Тело запроса необязательно
+	var req PublishDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	published, err := h.draftService.PublishDraft(c.Request.Context(), userID.(int64), id, req.Version)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, published)
+}
+
+func (h *DraftHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDraft),
+		errors.Is(err, service.ErrDraftIncomplete),
+		errors.Is(err, service.ErrUnknownCategory),
+		errors.Is(err, service.ErrInvalidTag),
+		errors.Is(err, service.ErrTooManyTags),
+		errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrDraftNotFound),
+		errors.Is(err, repository.ErrTopicNotFound),
+		errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrDraftConflict),
+		errors.Is(err, repository.ErrTopicLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling draft request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
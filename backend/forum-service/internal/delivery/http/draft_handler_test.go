@@ -0,0 +1,160 @@
+package httpDelivery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDraftService struct {
+	mock.Mock
+}
+
+func (m *MockDraftService) SaveDraft(ctx context.Context, draft *entity.Draft) error {
+	args := m.Called(ctx, draft)
+	return args.Error(0)
+}
+
+func (m *MockDraftService) ListDrafts(ctx context.Context, filter entity.DraftFilter) (*entity.DraftPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.DraftPage), args.Error(1)
+}
+
+func (m *MockDraftService) DeleteDraft(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockDraftService) PublishDraft(ctx context.Context, userID, id int64, version int) (*entity.PublishedDraft, error) {
+	args := m.Called(ctx, userID, id, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PublishedDraft), args.Error(1)
+}
+
+func setupDraftRouter(draftService service.DraftService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewDraftHandler(draftService)
+	r.Use(func(c *gin.Context) { c.Set("user_id", int64(2)) })
+	r.GET("/drafts", h.ListDrafts)
+	r.PUT("/drafts", h.SaveDraft)
+	r.DELETE("/drafts/:id", h.DeleteDraft)
+	r.POST("/drafts/:id/publish", h.PublishDraft)
+	return r
+}
+
+func TestDraftHandler_SaveDraft(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"saved", `{"context":"topic","title":"Go","content":"draft","version":1}`, nil, http.StatusOK},
+		{"stale version", `{"context":"topic","content":"draft","version":1}`, repository.ErrDraftConflict, http.StatusConflict},
+		{"invalid context", `{"context":"page","content":"draft"}`, service.ErrInvalidDraft, http.StatusBadRequest},
+		{"missing context", `{"content":"draft"}`, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockDraftService)
+			mockService.On("SaveDraft", mock.Anything, mock.MatchedBy(func(d *entity.Draft) bool { return d.UserID == 2 })).
+				Run(func(args mock.Arguments) { args.Get(1).(*entity.Draft).Version++ }).
+				Return(tt.err)
+
+			req, _ := http.NewRequest(http.MethodPut, "/drafts", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			setupDraftRouter(mockService).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"version":2`)
+			}
+		})
+	}
+}
+
+func TestDraftHandler_ListDrafts(t *testing.T) {
+	mockService := new(MockDraftService)
+	mockService.On("ListDrafts", mock.Anything, entity.DraftFilter{UserID: 2, Context: entity.DraftContextTopicReply, TargetID: 4}).
+		Return(&entity.DraftPage{Drafts: []*entity.Draft{{ID: 8, Context: entity.DraftContextTopicReply, TargetID: 4}}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/drafts?context=topic_reply&target_id=4", nil)
+	w := httptest.NewRecorder()
+	setupDraftRouter(mockService).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"target_id":4`)
+
+	req, _ = http.NewRequest(http.MethodGet, "/drafts?target_id=x", nil)
+	w = httptest.NewRecorder()
+	setupDraftRouter(mockService).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDraftHandler_PublishDraft(t *testing.T) {
+	mockService := new(MockDraftService)
+	mockService.On("PublishDraft", mock.Anything, int64(2), int64(7), 0).
+		Return(&entity.PublishedDraft{Topic: &entity.Topic{ID: 15, Title: "Go"}}, nil)
+	mockService.On("PublishDraft", mock.Anything, int64(2), int64(7), 3).
+		Return(nil, repository.ErrDraftConflict)
+	mockService.On("PublishDraft", mock.Anything, int64(2), int64(9), 0).
+		Return(nil, service.ErrDraftIncomplete)
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{"without body", "/drafts/7/publish", "", http.StatusCreated},
+		{"stale version", "/drafts/7/publish", `{"version":3}`, http.StatusConflict},
+		{"incomplete", "/drafts/9/publish", "", http.StatusBadRequest},
+		{"invalid id", "/drafts/x/publish", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			setupDraftRouter(mockService).ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusCreated {
+				assert.Contains(t, w.Body.String(), `"topic":{"id":15`)
+			}
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestDraftHandler_DeleteDraft(t *testing.T) {
+	mockService := new(MockDraftService)
+	mockService.On("DeleteDraft", mock.Anything, int64(2), int64(7)).Return(nil)
+	mockService.On("DeleteDraft", mock.Anything, int64(2), int64(8)).Return(repository.ErrDraftNotFound)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/drafts/7", nil)
+	w := httptest.NewRecorder()
+	setupDraftRouter(mockService).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/drafts/8", nil)
+	w = httptest.NewRecorder()
+	setupDraftRouter(mockService).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,132 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// MentionHandler handles HTTP requests for @mentions
+type MentionHandler struct {
+	mentionService service.MentionService
+}
+
+// UserSuggestionResponse represents a user offered by the mention picker
+// @Description User whose name starts with the typed prefix
+type UserSuggestionResponse struct {
+	ID       int64  `json:"id" example:"3"`
+	Username string `json:"username" example:"alice"`
+	Avatar   string `json:"avatar,omitempty" example:"https://example.com/avatar.png"`
+}
+
+// MentionResponse represents a mention of the current user
+// @Description Topic, comment or chat message that mentioned the user
+type MentionResponse struct {
+	ID             int64  `json:"id" example:"1"`
+	TargetType     string `json:"target_type" example:"comment" enums:"topic,comment,chat_message"`
+	TargetID       int64  `json:"target_id" example:"10"`
+	TopicID        int64  `json:"topic_id,omitempty" example:"4"`
+	UserID         int64  `json:"user_id" example:"3"`
+	AuthorID       int64  `json:"author_id,omitempty" example:"2"`
+	AuthorUsername string `json:"author_username,omitempty" example:"bob"`
+	CreatedAt      string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+}
+
+// MentionListResponse represents a page of mentions
+// @Description Page of mentions, newest first
+type MentionListResponse struct {
+	Mentions   []MentionResponse `json:"mentions"`
+	NextCursor string            `json:"next_cursor,omitempty" example:"eyJrIjoibWVudGlvbnMifQ"`
+}
+
+func NewMentionHandler(mentionService service.MentionService) *MentionHandler {
+	return &MentionHandler{
+		mentionService: mentionService,
+	}
+}
+
+// @Summary Suggest users to mention
+// @Description Get users whose name starts with the given prefix, shortest names first
+// @Tags mentions
+// @Produce json
+// @Param q query string true "Username prefix, with or without a leading @"
+// @Param limit query int false "Number of users (default 10, max 25)"
+// @Success 200 {array} UserSuggestionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/suggest [get]
+func (h *MentionHandler) SuggestUsers(c *gin.Context) {
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+
+	users, err := h.mentionService.SuggestUsers(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	suggestions := make([]UserSuggestionResponse, len(users))
+	for i, user := range users {
+		suggestions[i] = UserSuggestionResponse{ID: user.ID, Username: user.Username, Avatar: user.Avatar}
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// @Summary List my mentions
+// @Description Get the topics, comments and chat messages that mentioned the current user, newest first
+// @Tags mentions
+// @Produce json
+// @Security BearerAuth
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} MentionListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /mentions [get]
+func (h *MentionHandler) GetMentions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+
+	page, err := h.mentionService.GetMentions(c.Request.Context(), userID.(int64), c.Query("cursor"), limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *MentionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMentionPrefix), errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling mention request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,111 @@
+package httpDelivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMentionService struct {
+	mock.Mock
+}
+
+func (m *MockMentionService) SuggestUsers(ctx context.Context, prefix string, limit int) ([]*entity.User, error) {
+	args := m.Called(ctx, prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.User), args.Error(1)
+}
+
+func (m *MockMentionService) GetMentions(ctx context.Context, userID int64, cursor string, limit int) (*entity.MentionPage, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.MentionPage), args.Error(1)
+}
+
+func setupMentionRouter(mentionService service.MentionService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewMentionHandler(mentionService)
+	r.GET("/users/suggest", h.SuggestUsers)
+	r.GET("/mentions", func(c *gin.Context) { c.Set("user_id", int64(5)) }, h.GetMentions)
+	return r
+}
+
+func TestMentionHandler_SuggestUsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		mock       bool
+		mockUsers  []*entity.User
+		mockErr    error
+		wantStatus int
+		wantLen    int
+	}{
+		{name: "success", url: "/users/suggest?q=al&limit=5", mock: true, mockUsers: []*entity.User{{ID: 1, Username: "alice"}, {ID: 2, Username: "alex"}}, wantStatus: http.StatusOK, wantLen: 2},
+		{name: "empty prefix", url: "/users/suggest?q=", mock: true, mockErr: service.ErrInvalidMentionPrefix, wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", url: "/users/suggest?q=al&limit=x", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockMentionService)
+			if tt.mock {
+				mockService.On("SuggestUsers", mock.Anything, mock.Anything, mock.Anything).Return(tt.mockUsers, tt.mockErr)
+			}
+			r := setupMentionRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var resp []UserSuggestionResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Len(t, resp, tt.wantLen)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMentionHandler_GetMentions(t *testing.T) {
+	mockService := new(MockMentionService)
+	r := setupMentionRouter(mockService)
+
+	mockService.On("GetMentions", mock.Anything, int64(5), "", 10).Return(&entity.MentionPage{
+		Mentions:   []*entity.Mention{{ID: 1, TargetType: entity.MentionTargetComment, TargetID: 10, TopicID: 4, UserID: 5}},
+		NextCursor: "next",
+	}, nil)
+	mockService.On("GetMentions", mock.Anything, int64(5), "bad", 0).Return(nil, repository.ErrInvalidCursor)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/mentions?limit=10", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp MentionListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Mentions, 1)
+	assert.Equal(t, int64(4), resp.Mentions[0].TopicID)
+	assert.Equal(t, "next", resp.NextCursor)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/mentions?cursor=bad", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
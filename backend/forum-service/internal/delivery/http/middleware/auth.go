@@ -3,6 +3,7 @@ package middleware
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 )
 
 type AuthConfig struct {
@@ -29,95 +31,140 @@ func (c *AuthConfig) AuthMiddleware() gin.HandlerFunc {
 		log.Printf("Auth header: %s", authHeader)
 		log.Printf("All request headers: %v", ctx.Request.Header)
 
-		if authHeader == "" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header is required"})
+		user, err := c.verifyToken(authHeader)
+		if err != nil {
+			ctx.JSON(err.status, gin.H{"error": err.message})
 			ctx.Abort()
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
-			ctx.Abort()
-			return
-		}
+		// Set user data in context
+		user.setOn(ctx)
+		ctx.Next()
+	}
+}
 
-		token := parts[1]
-		if token == "" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "token is required"})
-			ctx.Abort()
-			return
+// OptionalAuthMiddleware identifies the caller when a valid token is sent
+// but lets anonymous requests through, for public endpoints whose response
+// depends on who is asking.
+func (c *AuthConfig) OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authHeader := ctx.GetHeader("Authorization")
+		if authHeader != "" {
+			if user, err := c.verifyToken(authHeader); err == nil {
+				user.setOn(ctx)
+			}
 		}
+		ctx.Next()
+	}
+}
 
-		log.Printf("Verifying token with auth service: %s", c.AuthServiceURL)
-		// Verify token with auth service
-		tokenReq := struct {
-			Token string `json:"token"`
-		}{
-			Token: token,
-		}
-		reqBody, err := json.Marshal(tokenReq)
-		if err != nil {
-			log.Printf("Failed to marshal token request: %v", err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create verification request"})
-			ctx.Abort()
-			return
-		}
+// VerifyToken checks a "Bearer <token>" header with the auth service and
+// returns the user it belongs to. It lets other transports, such as the
+// gRPC server, authenticate callers the same way the HTTP API does.
+func (c *AuthConfig) VerifyToken(authHeader string) (policy.Actor, error) {
+	user, err := c.verifyToken(authHeader)
+	if err != nil {
+		return policy.Actor{}, errors.New(err.message)
+	}
+	return policy.Actor{UserID: user.userID, Role: user.role}, nil
+}
 
-		log.Printf("Sending verification request to auth service with body: %s", string(reqBody))
-		req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/verify", c.AuthServiceURL), bytes.NewBuffer(reqBody))
-		if err != nil {
-			log.Printf("Failed to create verification request: %v", err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create verification request"})
-			ctx.Abort()
-			return
-		}
-		req.Header.Set("Content-Type", "application/json")
+// verifiedUser is the user a token belongs to, as the auth service reports it
+type verifiedUser struct {
+	userID   int64
+	username string
+	role     string
+}
 
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("Failed to verify token: %v", err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
-			ctx.Abort()
-			return
-		}
-		defer resp.Body.Close()
+// setOn stores the user in the gin context and the role in the request
+// context, where the policy layer looks for it
+func (u verifiedUser) setOn(ctx *gin.Context) {
+	ctx.Set("user_id", u.userID)
+	ctx.Set("username", u.username)
+	if u.role != "" {
+		ctx.Set("user_role", u.role)
+		ctx.Request = ctx.Request.WithContext(policy.ContextWithRole(ctx.Request.Context(), u.role))
+	}
+}
 
-		log.Printf("Auth service response status: %d", resp.StatusCode)
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("Auth service response body: %s", string(body))
+// authError is a failed token check with the response it should produce
+type authError struct {
+	status  int
+	message string
+}
 
-		if resp.StatusCode != http.StatusOK {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			ctx.Abort()
-			return
-		}
+// verifyToken checks a "Bearer <token>" header with the auth service and
+// returns the user it belongs to.
+func (c *AuthConfig) verifyToken(authHeader string) (verifiedUser, *authError) {
+	if authHeader == "" {
+		return verifiedUser{}, &authError{http.StatusUnauthorized, "authorization header is required"}
+	}
 
-		var userData struct {
-			UserID   string `json:"user_id"`
-			Username string `json:"username"`
-		}
-		if err := json.Unmarshal(body, &userData); err != nil {
-			log.Printf("Failed to decode user data: %v", err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decode user data"})
-			ctx.Abort()
-			return
-		}
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return verifiedUser{}, &authError{http.StatusUnauthorized, "invalid authorization header format"}
+	}
 
-		// Convert user ID from string to int64
-		userID, err := strconv.ParseInt(userData.UserID, 10, 64)
-		if err != nil {
-			log.Printf("Failed to parse user ID: %v", err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID format"})
-			ctx.Abort()
-			return
-		}
+	token := parts[1]
+	if token == "" {
+		return verifiedUser{}, &authError{http.StatusUnauthorized, "token is required"}
+	}
 
-		log.Printf("Token verified successfully for user: %s", userData.Username)
-		// Set user data in context
-		ctx.Set("user_id", userID)
-		ctx.Set("username", userData.Username)
-		ctx.Next()
+	log.Printf("Verifying token with auth service: %s", c.AuthServiceURL)
+	// Verify token with auth service
+	tokenReq := struct {
+		Token string `json:"token"`
+	}{
+		Token: token,
+	}
+	reqBody, err := json.Marshal(tokenReq)
+	if err != nil {
+		log.Printf("Failed to marshal token request: %v", err)
+		return verifiedUser{}, &authError{http.StatusInternalServerError, "failed to create verification request"}
+	}
+
+	log.Printf("Sending verification request to auth service with body: %s", string(reqBody))
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/verify", c.AuthServiceURL), bytes.NewBuffer(reqBody))
+	if err != nil {
+		log.Printf("Failed to create verification request: %v", err)
+		return verifiedUser{}, &authError{http.StatusInternalServerError, "failed to create verification request"}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("Failed to verify token: %v", err)
+		return verifiedUser{}, &authError{http.StatusInternalServerError, "failed to verify token"}
+	}
+	defer resp.Body.Close()
+
+	log.Printf("Auth service response status: %d", resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	log.Printf("Auth service response body: %s", string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return verifiedUser{}, &authError{http.StatusUnauthorized, "invalid token"}
+	}
+
+	var userData struct {
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+		Role     string `json:"role"`
 	}
+	if err := json.Unmarshal(body, &userData); err != nil {
+		log.Printf("Failed to decode user data: %v", err)
+		return verifiedUser{}, &authError{http.StatusInternalServerError, "failed to decode user data"}
+	}
+
+	// Convert user ID from string to int64
+	userID, err := strconv.ParseInt(userData.UserID, 10, 64)
+	if err != nil {
+		log.Printf("Failed to parse user ID: %v", err)
+		return verifiedUser{}, &authError{http.StatusInternalServerError, "invalid user ID format"}
+	}
+
+	log.Printf("Token verified successfully for user: %s", userData.Username)
+	return verifiedUser{userID: userID, username: userData.Username, role: userData.Role}, nil
 }
@@ -2,11 +2,13 @@ package middleware
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -134,3 +136,38 @@ func TestAuthMiddleware_Success(t *testing.T) {
 	assert.Equal(t, "123", c.GetString("user_id"))
 	assert.Equal(t, "testuser", c.GetString("username"))
 }
+
+func TestAuthMiddleware_Role(t *testing.T) {
+	// auth-service сообщает роль вместе с пользователем
+	respBody, _ := json.Marshal(map[string]string{"user_id": "123", "username": "moder", "role": "moderator"})
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(respBody)
+	}))
+	defer authServer.Close()
+
+	gin.SetMode(gin.TestMode)
+	cfg := &AuthConfig{AuthServiceURL: authServer.URL}
+	// Локальная таблица users недоступна: роль должна прийти из auth-service
+	lookup := stubRoleLookup{err: errors.New("db error")}
+
+	r := gin.New()
+	r.DELETE("/moderation", cfg.AuthMiddleware(), RequireRole(lookup, "moderator", "admin"), func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		assert.Equal(t, int64(123), userID)
+		role, ok := policy.RoleFromContext(c.Request.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "moderator", role)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/moderation", nil)
+	req.Header.Set("Authorization", "Bearer validtoken")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	actor, err := cfg.VerifyToken("Bearer validtoken")
+	assert.NoError(t, err)
+	assert.Equal(t, policy.Actor{UserID: 123, Role: "moderator"}, actor)
+}
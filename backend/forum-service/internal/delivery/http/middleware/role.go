@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleLookup resolves the forum role of a user
+type RoleLookup interface {
+	GetUserRole(ctx context.Context, id int64) (string, error)
+}
+
+// RequireRole lets the request through only if the authenticated user has one
+// of the given roles. It must run after AuthMiddleware, which supplies the
+// role the auth service reported.
+func RequireRole(lookup RoleLookup, roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, exists := ctx.Get("user_id")
+		if !exists {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			ctx.Abort()
+			return
+		}
+
+		// Роль из ответа auth-service; без нее смотрим users.role
+		role := ctx.GetString("user_role")
+		if role == "" {
+			var err error
+			role, err = lookup.GetUserRole(ctx.Request.Context(), userID.(int64))
+			if err != nil {
+				log.Printf("Failed to get role for user %d: %v", userID, err)
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+				ctx.Abort()
+				return
+			}
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				ctx.Set("user_role", role)
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		ctx.Abort()
+	}
+}
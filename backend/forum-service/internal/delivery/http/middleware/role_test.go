@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubRoleLookup struct {
+	role string
+	err  error
+}
+
+func (s stubRoleLookup) GetUserRole(ctx context.Context, id int64) (string, error) {
+	return s.role, s.err
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		setUser    bool
+		lookup     stubRoleLookup
+		wantStatus int
+	}{
+		{name: "not authenticated", wantStatus: http.StatusUnauthorized},
+		{name: "allowed role", setUser: true, lookup: stubRoleLookup{role: "admin"}, wantStatus: http.StatusOK},
+		{name: "other role", setUser: true, lookup: stubRoleLookup{role: "user"}, wantStatus: http.StatusForbidden},
+		{name: "lookup error", setUser: true, lookup: stubRoleLookup{err: errors.New("db error")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/admin", func(c *gin.Context) {
+				if tt.setUser {
+					c.Set("user_id", int64(1))
+				}
+				c.Next()
+			}, RequireRole(tt.lookup, "admin", "moderator"), func(c *gin.Context) {
+				assert.Equal(t, "admin", c.GetString("user_role"))
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/admin", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -33,18 +33,42 @@ func (m *MockCommentRepository) CreateComment(_ context.Context, c *entity.Comme
 	return nil
 }
 
-func (m *MockCommentRepository) UpdateComment(_ context.Context, c *entity.Comment) error {
+func (m *MockCommentRepository) UpdateComment(_ context.Context, c *entity.Comment, editorID int64) error {
 	return nil
 }
 
-func (m *MockCommentRepository) DeleteComment(_ context.Context, id int64) error {
+func (m *MockCommentRepository) DeleteComment(_ context.Context, id, deletedBy int64) error {
 	return nil
 }
 
-func (m *MockCommentRepository) LikeComment(_ context.Context, id int64) error {
+func (m *MockCommentRepository) RestoreComment(_ context.Context, id int64) error {
 	return nil
 }
 
+func (m *MockCommentRepository) PurgeDeletedComments(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockCommentRepository) LikeComment(_ context.Context, _, _ int64) (int, bool, error) {
+	return 1, true, nil
+}
+
+func (m *MockCommentRepository) UnlikeComment(_ context.Context, _, _ int64) (int, error) {
+	return 0, nil
+}
+
+func (m *MockCommentRepository) GetCommentLikes(_ context.Context, _ int64, _ string, _ int) (*entity.CommentLikePage, error) {
+	return &entity.CommentLikePage{Likes: []*entity.CommentLike{}}, nil
+}
+
+func (m *MockCommentRepository) GetLikedCommentIDs(_ context.Context, _ int64, _ []int64) (map[int64]bool, error) {
+	return map[int64]bool{}, nil
+}
+
+func (m *MockCommentRepository) GetCommentTree(_ context.Context, _ entity.CommentTreeQuery) (*entity.CommentTree, error) {
+	return &entity.CommentTree{Comments: []*entity.Comment{}}, nil
+}
+
 // MockTopicRepository представляет мок для TopicRepository
 type MockTopicRepository struct{}
 
@@ -67,8 +91,8 @@ func (m *MockTopicRepository) GetTopicByID(_ context.Context, id int64) (*entity
 	return nil, nil
 }
 
-func (m *MockTopicRepository) GetAllTopics(_ context.Context) ([]*entity.Topic, error) {
-	return []*entity.Topic{
+func (m *MockTopicRepository) GetAllTopics(_ context.Context, _ entity.TopicFilter) (*entity.TopicPage, error) {
+	return &entity.TopicPage{Topics: []*entity.Topic{
 		{
 			ID:        1,
 			Title:     "Test Topic 1",
@@ -85,21 +109,61 @@ func (m *MockTopicRepository) GetAllTopics(_ context.Context) ([]*entity.Topic,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
-	}, nil
+	}}, nil
+}
+
+func (m *MockTopicRepository) UpdateTopic(_ context.Context, topic *entity.Topic, editorID int64) error {
+	return nil
+}
+
+func (m *MockTopicRepository) DeleteTopic(_ context.Context, id, deletedBy int64) error {
+	return nil
+}
+
+func (m *MockTopicRepository) RestoreTopic(_ context.Context, id int64) error {
+	return nil
 }
 
-func (m *MockTopicRepository) UpdateTopic(_ context.Context, topic *entity.Topic) error {
+func (m *MockTopicRepository) SetPin(_ context.Context, id int64, scope entity.PinScope, until *time.Time) error {
 	return nil
 }
 
-func (m *MockTopicRepository) DeleteTopic(_ context.Context, id int64) error {
+func (m *MockTopicRepository) SetLock(_ context.Context, id int64, locked bool, until *time.Time) error {
 	return nil
 }
 
+func (m *MockTopicRepository) SetAnnouncement(_ context.Context, id int64, announcement bool, until *time.Time) error {
+	return nil
+}
+
+func (m *MockTopicRepository) GetScheduledTopic(_ context.Context, id int64) (*entity.Topic, error) {
+	return nil, nil
+}
+
+func (m *MockTopicRepository) GetScheduledTopics(_ context.Context, authorID int64) ([]*entity.Topic, error) {
+	return []*entity.Topic{}, nil
+}
+
+func (m *MockTopicRepository) RescheduleTopic(_ context.Context, id int64, publishAt time.Time) error {
+	return nil
+}
+
+func (m *MockTopicRepository) PublishDueTopics(_ context.Context, now time.Time, limit int) ([]*entity.Topic, error) {
+	return []*entity.Topic{}, nil
+}
+
+func (m *MockTopicRepository) PurgeDeletedTopics(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
 func (m *MockTopicRepository) UpdateCommentCount(_ context.Context, topicID int64) error {
 	return nil
 }
 
+func (m *MockTopicRepository) AddViews(_ context.Context, views map[int64]int) error {
+	return nil
+}
+
 // MockUserRepository представляет мок для UserRepository
 type MockUserRepository struct {
 	mock.Mock
@@ -110,6 +174,11 @@ func (m *MockUserRepository) GetUsernameByID(ctx context.Context, id int64) (str
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockUserRepository) GetUserRole(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockUserRepository) GetUserByID(ctx context.Context, id int64) (*entity.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
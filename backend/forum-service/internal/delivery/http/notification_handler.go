@@ -0,0 +1,194 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// NotificationHandler handles HTTP requests for the current user's notifications
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+// NotificationResponse represents a notification
+// @Description Event on the user's content; repeated replies and likes are aggregated
+type NotificationResponse struct {
+	ID            int64  `json:"id" example:"1"`
+	UserID        int64  `json:"user_id" example:"3"`
+	Type          string `json:"type" example:"like" enums:"topic_reply,comment_reply,mention,like,moderation"`
+	TargetType    string `json:"target_type" example:"comment" enums:"topic,comment,chat_message"`
+	TargetID      int64  `json:"target_id" example:"10"`
+	TopicID       int64  `json:"topic_id,omitempty" example:"4"`
+	ActorID       int64  `json:"actor_id,omitempty" example:"7"`
+	ActorUsername string `json:"actor_username,omitempty" example:"bob"`
+	ActorCount    int    `json:"actor_count" example:"5"`
+	Action        string `json:"action,omitempty" example:"hide" enums:"hide,delete,warn,rollback"`
+	Note          string `json:"note,omitempty" example:"Advertising"`
+	Read          bool   `json:"read" example:"false"`
+	CreatedAt     string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	UpdatedAt     string `json:"updated_at" example:"2024-03-15T10:05:00Z"`
+}
+
+// NotificationListResponse represents a page of notifications
+// @Description Page of notifications, most recently updated first
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Unread        int                    `json:"unread" example:"3"`
+	NextCursor    string                 `json:"next_cursor,omitempty" example:"eyJrIjoibm90aWZpY2F0aW9ucyJ9"`
+}
+
+// UnreadCountResponse represents the number of unread notifications
+// @Description Number of unread notifications
+type UnreadCountResponse struct {
+	Unread int `json:"unread" example:"3"`
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// @Summary List my notifications
+// @Description Get the current user's notifications, most recently updated first, with the unread count
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} NotificationListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	filter := entity.NotificationFilter{
+		UserID:     userID.(int64),
+		UnreadOnly: c.Query("unread") == "true",
+		Cursor:     c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	page, err := h.notificationService.ListNotifications(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Count my unread notifications
+// @Description Get the number of unread notifications of the current user
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UnreadCountResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	unread, err := h.notificationService.UnreadCount(c.Request.Context(), userID.(int64))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UnreadCountResponse{Unread: unread})
+}
+
+// @Summary Mark a notification read
+// @Description Mark one of the current user's notifications as read. Returns the remaining unread count
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} UnreadCountResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	unread, err := h.notificationService.MarkRead(c.Request.Context(), userID.(int64), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UnreadCountResponse{Unread: unread})
+}
+
+// @Summary Mark all notifications read
+// @Description Mark every notification of the current user as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UnreadCountResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	unread, err := h.notificationService.MarkAllRead(c.Request.Context(), userID.(int64))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UnreadCountResponse{Unread: unread})
+}
+
+func (h *NotificationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrNotificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling notification request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
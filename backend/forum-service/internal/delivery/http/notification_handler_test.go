@@ -0,0 +1,174 @@
+package httpDelivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) CommentCreated(ctx context.Context, comment *entity.Comment) {
+	m.Called(ctx, comment)
+}
+
+func (m *MockNotificationService) CommentLiked(ctx context.Context, commentID, userID int64) {
+	m.Called(ctx, commentID, userID)
+}
+
+func (m *MockNotificationService) Mentioned(ctx context.Context, target entity.NotificationTarget, authorID int64, userIDs []int64) {
+	m.Called(ctx, target, authorID, userIDs)
+}
+
+func (m *MockNotificationService) Moderated(ctx context.Context, target entity.NotificationTarget, authorID, moderatorID int64, action, note string) {
+	m.Called(ctx, target, authorID, moderatorID, action, note)
+}
+
+func (m *MockNotificationService) BookmarkReminder(ctx context.Context, bookmark *entity.Bookmark) error {
+	args := m.Called(ctx, bookmark)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) ListNotifications(ctx context.Context, filter entity.NotificationFilter) (*entity.NotificationPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationPage), args.Error(1)
+}
+
+func (m *MockNotificationService) UnreadCount(ctx context.Context, userID int64) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationService) MarkRead(ctx context.Context, userID, id int64) (int, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationService) MarkAllRead(ctx context.Context, userID int64) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationService) SetPublisher(publisher service.NotificationPublisher) {
+	m.Called(publisher)
+}
+
+func setupNotificationRouter(notificationService service.NotificationService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewNotificationHandler(notificationService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.GET("/notifications", withUser, h.ListNotifications)
+	r.GET("/notifications/unread-count", withUser, h.UnreadCount)
+	r.POST("/notifications/read-all", withUser, h.MarkAllRead)
+	r.POST("/notifications/:id/read", withUser, h.MarkRead)
+	return r
+}
+
+func TestNotificationHandler_ListNotifications(t *testing.T) {
+	mockService := new(MockNotificationService)
+	r := setupNotificationRouter(mockService)
+
+	filter := entity.NotificationFilter{UserID: 5, UnreadOnly: true, Limit: 10}
+	mockService.On("ListNotifications", mock.Anything, filter).Return(&entity.NotificationPage{
+		Notifications: []*entity.Notification{{
+			ID:                 1,
+			UserID:             5,
+			Type:               entity.NotificationLike,
+			NotificationTarget: entity.NotificationTarget{TargetType: entity.NotificationTargetComment, TargetID: 10, TopicID: 4},
+			ActorCount:         5,
+		}},
+		Unread:     3,
+		NextCursor: "next",
+	}, nil)
+	mockService.On("ListNotifications", mock.Anything, entity.NotificationFilter{UserID: 5, Cursor: "bad"}).
+		Return(nil, repository.ErrInvalidCursor)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/notifications?unread=true&limit=10", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp NotificationListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Notifications, 1)
+	assert.Equal(t, int64(4), resp.Notifications[0].TopicID)
+	assert.Equal(t, 5, resp.Notifications[0].ActorCount)
+	assert.Equal(t, 3, resp.Unread)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/notifications?cursor=bad", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationHandler_MarkRead(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		mock       bool
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", url: "/notifications/1/read", mock: true, wantStatus: http.StatusOK},
+		{name: "not found", url: "/notifications/1/read", mock: true, mockErr: repository.ErrNotificationNotFound, wantStatus: http.StatusNotFound},
+		{name: "invalid id", url: "/notifications/abc/read", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			if tt.mock {
+				mockService.On("MarkRead", mock.Anything, int64(5), int64(1)).Return(2, tt.mockErr)
+			}
+			r := setupNotificationRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.url, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var resp UnreadCountResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, 2, resp.Unread)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_MarkAllRead(t *testing.T) {
+	mockService := new(MockNotificationService)
+	r := setupNotificationRouter(mockService)
+
+	mockService.On("MarkAllRead", mock.Anything, int64(5)).Return(0, nil)
+	mockService.On("UnreadCount", mock.Anything, int64(5)).Return(0, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/notifications/read-all", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/notifications/unread-count", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"unread":0}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,218 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// PollHandler handles HTTP requests for topic polls
+type PollHandler struct {
+	pollService service.PollService
+}
+
+// CreatePollRequest represents a new poll
+// @Description Poll to attach to a topic
+type CreatePollRequest struct {
+	Question    string     `json:"question" binding:"required" example:"Which day suits the retro?"`
+	Options     []string   `json:"options" binding:"required" example:"Monday,Friday"`
+	Multiple    bool       `json:"multiple" example:"false"`
+	Anonymous   bool       `json:"anonymous" example:"false"`
+	HideResults bool       `json:"hide_results" example:"true"`
+	ClosesAt    *time.Time `json:"closes_at" example:"2024-03-20T18:00:00Z"`
+}
+
+// VoteRequest represents a vote in a poll
+// @Description Chosen options; a single-choice poll takes exactly one
+type VoteRequest struct {
+	OptionIDs []int64 `json:"option_ids" binding:"required" example:"2"`
+}
+
+// PollOptionResponse represents an answer of a poll
+// @Description Poll option with its votes; voters are listed only in public polls
+type PollOptionResponse struct {
+	ID       int64    `json:"id" example:"2"`
+	Text     string   `json:"text" example:"Friday"`
+	Position int      `json:"position" example:"2"`
+	Votes    int      `json:"votes" example:"5"`
+	Voters   []Author `json:"voters,omitempty"`
+}
+
+// PollResponse represents a poll
+// @Description Poll as the current user sees it. While results are hidden, vote counts are zero
+type PollResponse struct {
+	ID             int64                `json:"id" example:"1"`
+	TopicID        int64                `json:"topic_id" example:"4"`
+	Question       string               `json:"question" example:"Which day suits the retro?"`
+	Multiple       bool                 `json:"multiple" example:"false"`
+	Anonymous      bool                 `json:"anonymous" example:"false"`
+	HideResults    bool                 `json:"hide_results" example:"true"`
+	ClosesAt       string               `json:"closes_at,omitempty" example:"2024-03-20T18:00:00Z"`
+	CreatedAt      string               `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	Options        []PollOptionResponse `json:"options"`
+	Closed         bool                 `json:"closed" example:"false"`
+	ResultsVisible bool                 `json:"results_visible" example:"true"`
+	TotalVoters    int                  `json:"total_voters" example:"8"`
+	MyVotes        []int64              `json:"my_votes" example:"2"`
+}
+
+func NewPollHandler(pollService service.PollService) *PollHandler {
+	return &PollHandler{
+		pollService: pollService,
+	}
+}
+
+// @Summary Add a poll to a topic
+// @Description Attach a single- or multiple-choice poll to a topic. Only the topic author, moderators and admins may do this; a topic has at most one poll
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param poll body CreatePollRequest true "Poll"
+// @Success 201 {object} PollResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/poll [post]
+func (h *PollHandler) CreatePoll(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreatePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	poll := &entity.Poll{
+		TopicID:     topicID,
+		Question:    req.Question,
+		Multiple:    req.Multiple,
+		Anonymous:   req.Anonymous,
+		HideResults: req.HideResults,
+		ClosesAt:    req.ClosesAt,
+	}
+	for _, text := range req.Options {
+		poll.Options = append(poll.Options, &entity.PollOption{Text: text})
+	}
+	if err := h.pollService.CreatePoll(c.Request.Context(), poll, userID.(int64)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, poll)
+}
+
+// @Summary Get the poll of a topic
+// @Description Get a topic's poll with the current user's votes. If the author hid the results, they are shown only after the user votes or the poll closes
+// @Tags polls
+// @Produce json
+// @Param id path int true "Topic ID"
+// @Success 200 {object} PollResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/poll [get]
+func (h *PollHandler) GetPoll(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+	var viewerID int64
+	if userID, exists := c.Get("user_id"); exists {
+		viewerID = userID.(int64)
+	}
+
+	poll, err := h.pollService.GetPoll(c.Request.Context(), topicID, viewerID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+// @Summary Vote in a poll
+// @Description Vote in a topic's poll, or change the current user's vote, until the poll closes
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param vote body VoteRequest true "Chosen options"
+// @Success 200 {object} PollResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/poll/vote [put]
+func (h *PollHandler) Vote(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req VoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	poll, err := h.pollService.Vote(c.Request.Context(), topicID, userID.(int64), req.OptionIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+func (h *PollHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPollQuestion),
+		errors.Is(err, service.ErrInvalidPollOptions),
+		errors.Is(err, service.ErrPollCloseInPast),
+		errors.Is(err, service.ErrInvalidPollVote),
+		errors.Is(err, repository.ErrInvalidPollOption):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPollNotFound), errors.Is(err, repository.ErrTopicNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPollExists), errors.Is(err, service.ErrPollClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling poll request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
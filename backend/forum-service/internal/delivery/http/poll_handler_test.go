@@ -0,0 +1,165 @@
+package httpDelivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPollService struct {
+	mock.Mock
+}
+
+func (m *MockPollService) CreatePoll(ctx context.Context, poll *entity.Poll, userID int64) error {
+	args := m.Called(ctx, poll, userID)
+	return args.Error(0)
+}
+
+func (m *MockPollService) GetPoll(ctx context.Context, topicID, viewerID int64) (*entity.Poll, error) {
+	args := m.Called(ctx, topicID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Poll), args.Error(1)
+}
+
+func (m *MockPollService) Vote(ctx context.Context, topicID, userID int64, optionIDs []int64) (*entity.Poll, error) {
+	args := m.Called(ctx, topicID, userID, optionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Poll), args.Error(1)
+}
+
+func setupPollRouter(pollService service.PollService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewPollHandler(pollService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.GET("/topics/:id/poll", h.GetPoll)
+	r.POST("/topics/:id/poll", withUser, h.CreatePoll)
+	r.PUT("/topics/:id/poll/vote", withUser, h.Vote)
+	return r
+}
+
+func TestPollHandler_CreatePoll(t *testing.T) {
+	body := `{"question":"Which day?","options":["Monday","Friday"],"multiple":true,"hide_results":true}`
+	tests := []struct {
+		name       string
+		body       string
+		mock       bool
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", body: body, mock: true, wantStatus: http.StatusCreated},
+		{name: "not the author", body: body, mock: true, mockErr: policy.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "already has a poll", body: body, mock: true, mockErr: repository.ErrPollExists, wantStatus: http.StatusConflict},
+		{name: "invalid options", body: body, mock: true, mockErr: service.ErrInvalidPollOptions, wantStatus: http.StatusBadRequest},
+		{name: "missing options", body: `{"question":"Which day?"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPollService)
+			if tt.mock {
+				mockService.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *entity.Poll) bool {
+					return p.TopicID == 4 && p.Question == "Which day?" && p.Multiple && p.HideResults &&
+						len(p.Options) == 2 && p.Options[1].Text == "Friday"
+				}), int64(5)).Return(tt.mockErr)
+			}
+			r := setupPollRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/topics/4/poll", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollHandler_GetPoll(t *testing.T) {
+	mockService := new(MockPollService)
+	r := setupPollRouter(mockService)
+
+	// Гость видит опрос без своих голосов
+	mockService.On("GetPoll", mock.Anything, int64(4), int64(0)).Return(&entity.Poll{
+		ID:          1,
+		TopicID:     4,
+		Question:    "Which day?",
+		HideResults: true,
+		Options: []*entity.PollOption{
+			{ID: 10, Text: "Monday", Position: 1},
+			{ID: 11, Text: "Friday", Position: 2},
+		},
+	}, nil)
+	mockService.On("GetPoll", mock.Anything, int64(7), int64(0)).Return(nil, repository.ErrPollNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/topics/4/poll", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp PollResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.ResultsVisible)
+	assert.Len(t, resp.Options, 2)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/topics/7/poll", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPollHandler_Vote(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", wantStatus: http.StatusOK},
+		{name: "closed", mockErr: service.ErrPollClosed, wantStatus: http.StatusConflict},
+		{name: "foreign option", mockErr: repository.ErrInvalidPollOption, wantStatus: http.StatusBadRequest},
+		{name: "too many options", mockErr: service.ErrInvalidPollVote, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPollService)
+			if tt.mockErr != nil {
+				mockService.On("Vote", mock.Anything, int64(4), int64(5), []int64{11}).Return(nil, tt.mockErr)
+			} else {
+				mockService.On("Vote", mock.Anything, int64(4), int64(5), []int64{11}).Return(&entity.Poll{
+					ID:             1,
+					TopicID:        4,
+					ResultsVisible: true,
+					TotalVoters:    1,
+					MyVotes:        []int64{11},
+				}, nil)
+			}
+			r := setupPollRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/topics/4/poll/vote", bytes.NewBufferString(`{"option_ids":[11]}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// ProfileHandler handles HTTP requests for public user profiles
+type ProfileHandler struct {
+	profileService service.ProfileService
+}
+
+// UpdateProfileRequest represents a change of the current user's profile
+// @Description Omitted fields are kept; an empty string clears a field
+type UpdateProfileRequest struct {
+	Bio       *string `json:"bio" example:"Backend developer, mostly Go"`
+	Signature *string `json:"signature" example:"-- sent from my terminal"`
+	Location  *string `json:"location" example:"Berlin"`
+}
+
+// ProfileResponse represents a public user profile
+// @Description Public profile of a user; post counts include only posts visible to everyone
+type ProfileResponse struct {
+	ID           int64  `json:"id" example:"5"`
+	Username     string `json:"username" example:"johndoe"`
+	Avatar       string `json:"avatar" example:"/api/v1/users/5/avatar"`
+	Role         string `json:"role" example:"moderator" enums:"user,moderator,admin"`
+	Bio          string `json:"bio" example:"Backend developer, mostly Go"`
+	Signature    string `json:"signature" example:"-- sent from my terminal"`
+	Location     string `json:"location" example:"Berlin"`
+	JoinedAt     string `json:"joined_at,omitempty" example:"2023-05-01T12:00:00Z"`
+	TopicCount   int    `json:"topic_count" example:"12"`
+	CommentCount int    `json:"comment_count" example:"148"`
+}
+
+// UserCommentResponse represents a comment in a user's activity
+// @Description Comment of the user with the title of its topic
+type UserCommentResponse struct {
+	ID          int64  `json:"id" example:"11"`
+	TopicID     int64  `json:"topic_id" example:"4"`
+	TopicTitle  string `json:"topic_title" example:"How to use Go"`
+	ParentID    int64  `json:"parent_id,omitempty" example:"3"`
+	Content     string `json:"content" example:"Great post!"`
+	ContentHTML string `json:"content_html" example:"<p>Great post!</p>"`
+	Likes       int    `json:"likes" example:"2"`
+	CreatedAt   string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	EditedAt    string `json:"edited_at,omitempty" example:"2024-03-15T10:05:00Z"`
+}
+
+// UserCommentListResponse represents a page of a user's comments
+// @Description Page of comments, newest first
+type UserCommentListResponse struct {
+	Comments   []UserCommentResponse `json:"comments"`
+	NextCursor string                `json:"next_cursor,omitempty" example:"eyJrIjoidXNlcl9jb21tZW50cyJ9"`
+}
+
+func NewProfileHandler(profileService service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// @Summary Get a user profile
+// @Description Get the public profile of a user with their role, bio, signature and post counts
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} ProfileResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id} [get]
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	profile, err := h.profileService.GetProfile(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Update my profile
+// @Description Change the bio, signature or location of the current user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body UpdateProfileRequest true "Profile fields"
+// @Success 200 {object} ProfileResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/me/profile [patch]
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.profileService.UpdateProfile(c.Request.Context(), userID.(int64), entity.ProfileUpdate{
+		Bio:       req.Bio,
+		Signature: req.Signature,
+		Location:  req.Location,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary List the topics of a user
+// @Description Get the visible topics a user started, newest first
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} TopicListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/topics [get]
+func (h *ProfileHandler) GetUserTopics(c *gin.Context) {
+	filter, ok := activityFilter(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.profileService.GetUserTopics(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary List the comments of a user
+// @Description Get the visible comments a user posted in visible topics, newest first
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} UserCommentListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/comments [get]
+func (h *ProfileHandler) GetUserComments(c *gin.Context) {
+	filter, ok := activityFilter(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.profileService.GetUserComments(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// activityFilter reads the user and the page of an activity listing,
+// answering 400 if they are invalid
+func activityFilter(c *gin.Context) (entity.ActivityFilter, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return entity.ActivityFilter{}, false
+	}
+	filter := entity.ActivityFilter{
+		UserID: id,
+		Cursor: c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return entity.ActivityFilter{}, false
+		}
+		filter.Limit = n
+	}
+	return filter, true
+}
+
+func (h *ProfileHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidProfile),
+		errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling profile request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,158 @@
+package httpDelivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProfileService struct {
+	mock.Mock
+}
+
+func (m *MockProfileService) GetProfile(ctx context.Context, userID int64) (*entity.UserProfile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserProfile), args.Error(1)
+}
+
+func (m *MockProfileService) UpdateProfile(ctx context.Context, userID int64, update entity.ProfileUpdate) (*entity.UserProfile, error) {
+	args := m.Called(ctx, userID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserProfile), args.Error(1)
+}
+
+func (m *MockProfileService) GetUserTopics(ctx context.Context, filter entity.ActivityFilter) (*entity.TopicPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TopicPage), args.Error(1)
+}
+
+func (m *MockProfileService) GetUserComments(ctx context.Context, filter entity.ActivityFilter) (*entity.UserCommentPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserCommentPage), args.Error(1)
+}
+
+func setupProfileRouter(profileService service.ProfileService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewProfileHandler(profileService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.GET("/users/:id", h.GetProfile)
+	r.GET("/users/:id/topics", h.GetUserTopics)
+	r.GET("/users/:id/comments", h.GetUserComments)
+	r.PATCH("/users/me/profile", withUser, h.UpdateProfile)
+	return r
+}
+
+func TestProfileHandler_GetProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		mock       bool
+		err        error
+		wantStatus int
+	}{
+		{name: "success", url: "/users/5", mock: true, wantStatus: http.StatusOK},
+		{name: "not found", url: "/users/5", mock: true, err: repository.ErrUserNotFound, wantStatus: http.StatusNotFound},
+		{name: "invalid id", url: "/users/abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProfileService)
+			if tt.mock {
+				var profile *entity.UserProfile
+				if tt.err == nil {
+					profile = &entity.UserProfile{ID: 5, Username: "alice", Role: entity.RoleModerator, TopicCount: 2}
+				}
+				mockService.On("GetProfile", mock.Anything, int64(5)).Return(profile, tt.err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			setupProfileRouter(mockService).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var resp ProfileResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "moderator", resp.Role)
+				assert.Equal(t, 2, resp.TopicCount)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProfileHandler_UpdateProfile(t *testing.T) {
+	mockService := new(MockProfileService)
+	bio := "Gopher"
+	mockService.On("UpdateProfile", mock.Anything, int64(5), entity.ProfileUpdate{Bio: &bio}).
+		Return(&entity.UserProfile{ID: 5, Username: "alice", Bio: bio}, nil)
+	long := strings.Repeat("a", 400)
+	mockService.On("UpdateProfile", mock.Anything, int64(5), entity.ProfileUpdate{Signature: &long}).
+		Return(nil, service.ErrInvalidProfile)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/users/me/profile", strings.NewReader(`{"bio":"Gopher"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	setupProfileRouter(mockService).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"bio":"Gopher"`)
+
+	req, _ = http.NewRequest(http.MethodPatch, "/users/me/profile", strings.NewReader(`{"signature":"`+long+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	setupProfileRouter(mockService).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProfileHandler_Activity(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockService.On("GetUserTopics", mock.Anything, entity.ActivityFilter{UserID: 5, Cursor: "abc", Limit: 10}).
+		Return(&entity.TopicPage{Topics: []*entity.Topic{{ID: 1, Title: "Go tips"}}, NextCursor: "next"}, nil)
+	mockService.On("GetUserComments", mock.Anything, entity.ActivityFilter{UserID: 5}).
+		Return(nil, repository.ErrInvalidCursor)
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+	}{
+		{"topics", "/users/5/topics?cursor=abc&limit=10", http.StatusOK},
+		{"invalid limit", "/users/5/topics?limit=-1", http.StatusBadRequest},
+		{"invalid id", "/users/x/comments", http.StatusBadRequest},
+		{"invalid cursor", "/users/5/comments", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			setupProfileRouter(mockService).ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	mockService.AssertExpectations(t)
+}
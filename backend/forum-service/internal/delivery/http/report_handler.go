@@ -0,0 +1,303 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// ReportHandler handles HTTP requests for content reports and the moderation queue
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+// CreateReportRequest represents a user's report on a piece of content
+// @Description Report on a topic, comment or chat message
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" binding:"required" example:"comment" enums:"topic,comment,chat_message"`
+	TargetID   int64  `json:"target_id" binding:"required" example:"10"`
+	Reason     string `json:"reason" binding:"required" example:"spam" enums:"spam,abuse,off_topic,other"`
+	Details    string `json:"details" example:"Links to a shady shop"`
+}
+
+// ResolveReportRequest represents a moderator's decision on a report
+// @Description Action to take on the reported content
+type ResolveReportRequest struct {
+	Action string `json:"action" binding:"required" example:"hide" enums:"hide,delete,warn"`
+	Note   string `json:"note" example:"Advertising"`
+}
+
+// DismissReportRequest represents a moderator closing a report without action
+// @Description Optional note on why the report was dismissed
+type DismissReportRequest struct {
+	Note string `json:"note" example:"Not a violation"`
+}
+
+// ReportResponse represents a report
+// @Description Report with its moderation state
+type ReportResponse struct {
+	ID              int64  `json:"id" example:"1"`
+	TargetType      string `json:"target_type" example:"comment" enums:"topic,comment,chat_message"`
+	TargetID        int64  `json:"target_id" example:"10"`
+	TargetAuthorID  int64  `json:"target_author_id,omitempty" example:"3"`
+	ContentSnapshot string `json:"content_snapshot" example:"Buy cheap watches at ..."`
+	ReporterID      int64  `json:"reporter_id" example:"2"`
+	Reason          string `json:"reason" example:"spam" enums:"spam,abuse,off_topic,other"`
+	Details         string `json:"details" example:"Links to a shady shop"`
+	Status          string `json:"status" example:"claimed" enums:"open,claimed,resolved,dismissed"`
+	ClaimedBy       int64  `json:"claimed_by,omitempty" example:"5"`
+	ClaimedAt       string `json:"claimed_at,omitempty" example:"2024-03-15T10:05:00Z"`
+	ResolvedBy      int64  `json:"resolved_by,omitempty" example:"5"`
+	ResolvedAt      string `json:"resolved_at,omitempty" example:"2024-03-15T10:10:00Z"`
+	Action          string `json:"action,omitempty" example:"hide" enums:"hide,delete,warn"`
+	Note            string `json:"note" example:"Advertising"`
+	CreatedAt       string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	TargetReports   int    `json:"target_reports,omitempty" example:"3"`
+}
+
+// ReportListResponse represents a page of the moderation queue
+// @Description Page of reports, oldest first
+type ReportListResponse struct {
+	Reports    []ReportResponse `json:"reports"`
+	NextCursor string           `json:"next_cursor,omitempty" example:"eyJrIjoicmVwb3J0cyJ9"`
+}
+
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// @Summary Report content
+// @Description Report a topic, comment or chat message to the moderators. Reporting the same content again updates the earlier report while it is still open
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param report body CreateReportRequest true "Report"
+// @Success 201 {object} ReportResponse "Report created"
+// @Success 200 {object} ReportResponse "Earlier report updated"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports [post]
+func (h *ReportHandler) CreateReport(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report := &entity.Report{
+		TargetType: entity.ReportTargetType(req.TargetType),
+		TargetID:   req.TargetID,
+		ReporterID: userID.(int64),
+		Reason:     entity.ReportReason(req.Reason),
+		Details:    req.Details,
+	}
+	created, err := h.reportService.CreateReport(c.Request.Context(), report)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if created {
+		c.JSON(http.StatusCreated, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary List reports
+// @Description Get the moderation queue, oldest report first. Without a status only reports that still need a decision are returned. Moderators only
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Report status" Enums(open, claimed, resolved, dismissed)
+// @Param target_type query string false "Reported content type" Enums(topic, comment, chat_message)
+// @Param limit query int false "Items per page" default(20) maximum(100)
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} ReportListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /moderation/reports [get]
+func (h *ReportHandler) ListReports(c *gin.Context) {
+	filter := entity.ReportFilter{
+		Status:     entity.ReportStatus(c.Query("status")),
+		TargetType: entity.ReportTargetType(c.Query("target_type")),
+		Cursor:     c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	page, err := h.reportService.ListReports(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Claim a report
+// @Description Take a report from the queue so other moderators know it is being handled. Moderators only
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report ID"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /moderation/reports/{id}/claim [post]
+func (h *ReportHandler) ClaimReport(c *gin.Context) {
+	reportID, moderatorID, ok := h.reportParams(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.ClaimReport(c.Request.Context(), reportID, moderatorID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Resolve a report
+// @Description Hide or delete the reported content, or warn its author, and close every open report on it. Moderators only
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report ID"
+// @Param decision body ResolveReportRequest true "Action to take"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /moderation/reports/{id}/resolve [post]
+func (h *ReportHandler) ResolveReport(c *gin.Context) {
+	reportID, moderatorID, ok := h.reportParams(c)
+	if !ok {
+		return
+	}
+
+	var req ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.reportService.ResolveReport(c.Request.Context(), reportID, moderatorID, entity.ReportAction(req.Action), req.Note)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Dismiss a report
+// @Description Close every open report on the content without action. Content hidden automatically is shown again. Moderators only
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report ID"
+// @Param decision body DismissReportRequest false "Note"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /moderation/reports/{id}/dismiss [post]
+func (h *ReportHandler) DismissReport(c *gin.Context) {
+	reportID, moderatorID, ok := h.reportParams(c)
+	if !ok {
+		return
+	}
+
+	var req DismissReportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	report, err := h.reportService.DismissReport(c.Request.Context(), reportID, moderatorID, req.Note)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// reportParams reads the report ID from the path and the moderator from the context
+func (h *ReportHandler) reportParams(c *gin.Context) (int64, int64, bool) {
+	reportID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return 0, 0, false
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, 0, false
+	}
+	return reportID, userID.(int64), true
+}
+
+func (h *ReportHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidReportTarget),
+		errors.Is(err, service.ErrInvalidReportReason),
+		errors.Is(err, service.ErrInvalidReportAction),
+		errors.Is(err, service.ErrInvalidReportStatus),
+		errors.Is(err, service.ErrReportDetailsTooLong),
+		errors.Is(err, service.ErrReportOwnContent),
+		errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrReportNotFound), errors.Is(err, repository.ErrReportTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrReportClaimed), errors.Is(err, repository.ErrReportClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling report request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
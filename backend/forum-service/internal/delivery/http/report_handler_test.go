@@ -0,0 +1,212 @@
+package httpDelivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReportService struct {
+	mock.Mock
+}
+
+func (m *MockReportService) CreateReport(ctx context.Context, report *entity.Report) (bool, error) {
+	args := m.Called(ctx, report)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReportService) ListReports(ctx context.Context, filter entity.ReportFilter) (*entity.ReportPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ReportPage), args.Error(1)
+}
+
+func (m *MockReportService) ClaimReport(ctx context.Context, id, moderatorID int64) (*entity.Report, error) {
+	args := m.Called(ctx, id, moderatorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Report), args.Error(1)
+}
+
+func (m *MockReportService) ResolveReport(ctx context.Context, id, moderatorID int64, action entity.ReportAction, note string) (*entity.Report, error) {
+	args := m.Called(ctx, id, moderatorID, action, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Report), args.Error(1)
+}
+
+func (m *MockReportService) DismissReport(ctx context.Context, id, moderatorID int64, note string) (*entity.Report, error) {
+	args := m.Called(ctx, id, moderatorID, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Report), args.Error(1)
+}
+
+func setupReportRouter(reportService service.ReportService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewReportHandler(reportService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.POST("/reports", withUser, h.CreateReport)
+	r.GET("/moderation/reports", withUser, h.ListReports)
+	r.POST("/moderation/reports/:id/claim", withUser, h.ClaimReport)
+	r.POST("/moderation/reports/:id/resolve", withUser, h.ResolveReport)
+	r.POST("/moderation/reports/:id/dismiss", withUser, h.DismissReport)
+	return r
+}
+
+func TestReportHandler_CreateReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		created    bool
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "new report", body: `{"target_type":"comment","target_id":10,"reason":"spam"}`, created: true, wantStatus: http.StatusCreated},
+		{name: "duplicate report", body: `{"target_type":"comment","target_id":10,"reason":"spam"}`, wantStatus: http.StatusOK},
+		{name: "missing reason", body: `{"target_type":"comment","target_id":10}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid reason", body: `{"target_type":"comment","target_id":10,"reason":"meh"}`, mockErr: service.ErrInvalidReportReason, wantStatus: http.StatusBadRequest},
+		{name: "missing content", body: `{"target_type":"topic","target_id":99,"reason":"abuse"}`, mockErr: repository.ErrReportTargetNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockReportService)
+			if tt.wantStatus != http.StatusBadRequest || tt.mockErr != nil {
+				mockService.On("CreateReport", mock.Anything, mock.MatchedBy(func(r *entity.Report) bool {
+					return r.ReporterID == 5
+				})).Return(tt.created, tt.mockErr)
+			}
+			r := setupReportRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/reports", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReportHandler_ListReports(t *testing.T) {
+	mockService := new(MockReportService)
+	r := setupReportRouter(mockService)
+
+	filter := entity.ReportFilter{Status: entity.ReportStatusOpen, TargetType: entity.ReportTargetComment, Limit: 10}
+	mockService.On("ListReports", mock.Anything, filter).Return(&entity.ReportPage{
+		Reports:    []*entity.Report{{ID: 1, TargetType: entity.ReportTargetComment, TargetReports: 2}},
+		NextCursor: "next",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/moderation/reports?status=open&target_type=comment&limit=10", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp ReportListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Reports, 1)
+	assert.Equal(t, 2, resp.Reports[0].TargetReports)
+	assert.Equal(t, "next", resp.NextCursor)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/moderation/reports?limit=0", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestReportHandler_ClaimReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", url: "/moderation/reports/1/claim", wantStatus: http.StatusOK},
+		{name: "invalid id", url: "/moderation/reports/abc/claim", wantStatus: http.StatusBadRequest},
+		{name: "not found", url: "/moderation/reports/1/claim", mockErr: repository.ErrReportNotFound, wantStatus: http.StatusNotFound},
+		{name: "claimed by another moderator", url: "/moderation/reports/1/claim", mockErr: repository.ErrReportClaimed, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockReportService)
+			if tt.wantStatus != http.StatusBadRequest {
+				var report *entity.Report
+				if tt.mockErr == nil {
+					report = &entity.Report{ID: 1, Status: entity.ReportStatusClaimed}
+				}
+				mockService.On("ClaimReport", mock.Anything, int64(1), int64(5)).Return(report, tt.mockErr)
+			}
+			r := setupReportRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.url, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReportHandler_ResolveReport(t *testing.T) {
+	mockService := new(MockReportService)
+	r := setupReportRouter(mockService)
+
+	action := entity.ReportActionDelete
+	mockService.On("ResolveReport", mock.Anything, int64(1), int64(5), entity.ReportActionDelete, "spam bot").
+		Return(&entity.Report{ID: 1, Status: entity.ReportStatusResolved, Action: &action}, nil)
+	mockService.On("ResolveReport", mock.Anything, int64(2), int64(5), entity.ReportActionHide, "").
+		Return(nil, repository.ErrReportClosed)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/moderation/reports/1/resolve", bytes.NewBufferString(`{"action":"delete","note":"spam bot"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp ReportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "delete", resp.Action)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/moderation/reports/2/resolve", bytes.NewBufferString(`{"action":"hide"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestReportHandler_DismissReport(t *testing.T) {
+	mockService := new(MockReportService)
+	r := setupReportRouter(mockService)
+
+	mockService.On("DismissReport", mock.Anything, int64(1), int64(5), "").
+		Return(&entity.Report{ID: 1, Status: entity.ReportStatusDismissed}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/moderation/reports/1/dismiss", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
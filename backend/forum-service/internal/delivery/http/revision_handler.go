@@ -0,0 +1,249 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// RevisionHandler handles HTTP requests for the edit history of topics and comments
+type RevisionHandler struct {
+	revisionService service.RevisionService
+}
+
+// RevisionResponse represents an earlier version of a topic or comment
+// @Description Version of a topic or comment before an edit
+type RevisionResponse struct {
+	ID         int64  `json:"id" example:"1"`
+	TargetType string `json:"target_type" example:"topic" enums:"topic,comment"`
+	TargetID   int64  `json:"target_id" example:"10"`
+	Revision   int    `json:"revision" example:"1"`
+	EditorID   int64  `json:"editor_id,omitempty" example:"3"`
+	Title      string `json:"title,omitempty" example:"How to use Go"`
+	Content    string `json:"content" example:"This is a tutorial about Go"`
+	CreatedAt  string `json:"created_at" example:"2024-03-15T10:05:00Z"`
+}
+
+// RevisionDiffResponse represents a diff between two versions
+// @Description Unified diff between two versions of a topic or comment
+type RevisionDiffResponse struct {
+	TargetType string `json:"target_type" example:"topic" enums:"topic,comment"`
+	TargetID   int64  `json:"target_id" example:"10"`
+	From       int    `json:"from" example:"1"`
+	To         int    `json:"to,omitempty" example:"2"`
+	Diff       string `json:"diff" example:"--- revision 1\n+++ revision 2\n@@ -1 +1 @@\n-old\n+new\n"`
+}
+
+func NewRevisionHandler(revisionService service.RevisionService) *RevisionHandler {
+	return &RevisionHandler{
+		revisionService: revisionService,
+	}
+}
+
+// @Summary List topic revisions
+// @Description Get the earlier versions of a topic, oldest first. Each revision is the topic as it was before an edit by editor_id
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "Topic ID"
+// @Success 200 {array} RevisionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/revisions [get]
+func (h *RevisionHandler) ListTopicRevisions(c *gin.Context) {
+	h.listRevisions(c, entity.RevisionTargetTopic)
+}
+
+// @Summary List comment revisions
+// @Description Get the earlier versions of a comment, oldest first. Each revision is the comment as it was before an edit by editor_id
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Success 200 {array} RevisionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /comments/{id}/revisions [get]
+func (h *RevisionHandler) ListCommentRevisions(c *gin.Context) {
+	h.listRevisions(c, entity.RevisionTargetComment)
+}
+
+// @Summary Diff topic revisions
+// @Description Get a unified diff between two versions of a topic. Without to the diff is against the current version
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "Topic ID"
+// @Param from query int true "Older revision"
+// @Param to query int false "Newer revision"
+// @Success 200 {object} RevisionDiffResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/revisions/diff [get]
+func (h *RevisionHandler) DiffTopicRevisions(c *gin.Context) {
+	h.diffRevisions(c, entity.RevisionTargetTopic)
+}
+
+// @Summary Diff comment revisions
+// @Description Get a unified diff between two versions of a comment. Without to the diff is against the current version
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "Comment ID"
+// @Param from query int true "Older revision"
+// @Param to query int false "Newer revision"
+// @Success 200 {object} RevisionDiffResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /comments/{id}/revisions/diff [get]
+func (h *RevisionHandler) DiffCommentRevisions(c *gin.Context) {
+	h.diffRevisions(c, entity.RevisionTargetComment)
+}
+
+// @Summary Roll back a topic
+// @Description Bring a topic back to an earlier revision. The replaced version is kept as a new revision. Moderators only
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param revision path int true "Revision to roll back to"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/revisions/{revision}/rollback [post]
+func (h *RevisionHandler) RollbackTopic(c *gin.Context) {
+	h.rollback(c, entity.RevisionTargetTopic)
+}
+
+// @Summary Roll back a comment
+// @Description Bring a comment back to an earlier revision. The replaced version is kept as a new revision. Moderators only
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Comment ID"
+// @Param revision path int true "Revision to roll back to"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /comments/{id}/revisions/{revision}/rollback [post]
+func (h *RevisionHandler) RollbackComment(c *gin.Context) {
+	h.rollback(c, entity.RevisionTargetComment)
+}
+
+func (h *RevisionHandler) listRevisions(c *gin.Context, targetType entity.RevisionTargetType) {
+	targetID, ok := h.targetID(c, targetType)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.revisionService.ListRevisions(c.Request.Context(), targetType, targetID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *RevisionHandler) diffRevisions(c *gin.Context, targetType entity.RevisionTargetType) {
+	targetID, ok := h.targetID(c, targetType)
+	if !ok {
+		return
+	}
+
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from revision"})
+		return
+	}
+	var to int
+	if value := c.Query("to"); value != "" {
+		to, err = strconv.Atoi(value)
+		if err != nil || to <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to revision"})
+			return
+		}
+	}
+
+	diff, err := h.revisionService.DiffRevisions(c.Request.Context(), targetType, targetID, from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *RevisionHandler) rollback(c *gin.Context, targetType entity.RevisionTargetType) {
+	targetID, ok := h.targetID(c, targetType)
+	if !ok {
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.revisionService.RollbackRevision(c.Request.Context(), targetType, targetID, revision, userID.(int64)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rolled back to revision " + strconv.Itoa(revision)})
+}
+
+// targetID reads the topic or comment ID from the path
+func (h *RevisionHandler) targetID(c *gin.Context, targetType entity.RevisionTargetType) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		if targetType == entity.RevisionTargetTopic {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		}
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *RevisionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRevision), errors.Is(err, service.ErrInvalidRevisionTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrRevisionNotFound),
+		errors.Is(err, repository.ErrTopicNotFound),
+		errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrRevisionTooLarge):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling revision request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,144 @@
+package httpDelivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRevisionService struct {
+	mock.Mock
+}
+
+func (m *MockRevisionService) ListRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64) ([]*entity.Revision, error) {
+	args := m.Called(ctx, targetType, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Revision), args.Error(1)
+}
+
+func (m *MockRevisionService) DiffRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, from, to int) (*entity.RevisionDiff, error) {
+	args := m.Called(ctx, targetType, targetID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RevisionDiff), args.Error(1)
+}
+
+func (m *MockRevisionService) RollbackRevision(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, revision int, moderatorID int64) error {
+	args := m.Called(ctx, targetType, targetID, revision, moderatorID)
+	return args.Error(0)
+}
+
+func setupRevisionRouter(revisionService service.RevisionService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewRevisionHandler(revisionService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.GET("/topics/:id/revisions", h.ListTopicRevisions)
+	r.GET("/topics/:id/revisions/diff", h.DiffTopicRevisions)
+	r.POST("/topics/:id/revisions/:revision/rollback", withUser, h.RollbackTopic)
+	r.GET("/comments/:id/revisions", h.ListCommentRevisions)
+	r.GET("/comments/:id/revisions/diff", h.DiffCommentRevisions)
+	r.POST("/comments/:id/revisions/:revision/rollback", withUser, h.RollbackComment)
+	return r
+}
+
+func TestRevisionHandler_ListRevisions(t *testing.T) {
+	mockService := new(MockRevisionService)
+	r := setupRevisionRouter(mockService)
+
+	mockService.On("ListRevisions", mock.Anything, entity.RevisionTargetTopic, int64(1)).
+		Return([]*entity.Revision{{ID: 1, TargetType: entity.RevisionTargetTopic, TargetID: 1, Revision: 1, Title: "Old", Content: "old"}}, nil)
+	mockService.On("ListRevisions", mock.Anything, entity.RevisionTargetComment, int64(2)).
+		Return(nil, repository.ErrCommentNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/topics/1/revisions", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []RevisionResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 1)
+	assert.Equal(t, "Old", resp[0].Title)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/comments/2/revisions", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/comments/abc/revisions", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRevisionHandler_DiffRevisions(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		from, to   int
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "against current", url: "/topics/1/revisions/diff?from=1", from: 1, wantStatus: http.StatusOK},
+		{name: "two revisions", url: "/comments/1/revisions/diff?from=1&to=3", from: 1, to: 3, wantStatus: http.StatusOK},
+		{name: "missing from", url: "/topics/1/revisions/diff", wantStatus: http.StatusBadRequest},
+		{name: "invalid to", url: "/topics/1/revisions/diff?from=1&to=x", wantStatus: http.StatusBadRequest},
+		{name: "unknown revision", url: "/topics/1/revisions/diff?from=7", from: 7, mockErr: repository.ErrRevisionNotFound, wantStatus: http.StatusNotFound},
+		{name: "too large", url: "/topics/1/revisions/diff?from=1", from: 1, mockErr: service.ErrRevisionTooLarge, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockRevisionService)
+			if tt.from > 0 {
+				mockService.On("DiffRevisions", mock.Anything, mock.Anything, int64(1), tt.from, tt.to).
+					Return(&entity.RevisionDiff{From: tt.from, To: tt.to, Diff: "--- revision 1\n"}, tt.mockErr)
+			}
+			r := setupRevisionRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevisionHandler_Rollback(t *testing.T) {
+	mockService := new(MockRevisionService)
+	r := setupRevisionRouter(mockService)
+
+	mockService.On("RollbackRevision", mock.Anything, entity.RevisionTargetTopic, int64(1), 2, int64(5)).Return(nil)
+	mockService.On("RollbackRevision", mock.Anything, entity.RevisionTargetComment, int64(3), 9, int64(5)).Return(repository.ErrRevisionNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/topics/1/revisions/2/rollback", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/comments/3/revisions/9/rollback", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/comments/3/revisions/0/rollback", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
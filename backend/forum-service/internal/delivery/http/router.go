@@ -3,10 +3,12 @@ package httpDelivery
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -56,26 +58,158 @@ type SuccessResponse struct {
 // @description Type "Bearer" followed by a space and JWT token.
 
 type Router struct {
-	engine         *gin.Engine
-	topicUseCase   service.TopicService
-	commentUseCase usecase.CommentUseCase
-	userRepo       repository.UserRepository
-	chatRepo       repository.ChatRepository
-	port           string
-	upgrader       websocket.Upgrader
-	clients        map[*websocket.Conn]string // map[connection]username
-	authConfig     *middleware.AuthConfig
-	authURL        string
-	logger         *zap.Logger
+	engine              *gin.Engine
+	topicUseCase        service.TopicService
+	commentUseCase      usecase.CommentUseCase
+	userRepo            repository.UserRepository
+	chatRepo            repository.ChatRepository
+	port                string
+	upgrader            websocket.Upgrader
+	clients             map[*websocket.Conn]string // map[connection]username
+	writers             map[*websocket.Conn]*wsWriter
+	clientsMu           sync.Mutex // guards clients and writers
+	authConfig          *middleware.AuthConfig
+	authURL             string
+	logger              *zap.Logger
+	searchService       service.SearchService
+	categoryService     service.CategoryService
+	tagService          service.TagService
+	viewCounter         *service.ViewCounter
+	reportService       service.ReportService
+	revisionService     service.RevisionService
+	mentionService      service.MentionService
+	notificationService service.NotificationService
+	subscriptionService service.SubscriptionService
+	bookmarkService     service.BookmarkService
+	pollService         service.PollService
+	attachmentService   service.AttachmentService
+	avatarService       service.AvatarService
+	profileService      service.ProfileService
+	draftService        service.DraftService
+}
+
+// RouterOption configures an optional part of the API
+type RouterOption func(*Router)
+
+// WithSearchService enables the full-text search endpoint
+func WithSearchService(searchService service.SearchService) RouterOption {
+	return func(r *Router) {
+		r.searchService = searchService
+	}
+}
+
+// WithTagService enables the tag endpoints
+func WithTagService(tagService service.TagService) RouterOption {
+	return func(r *Router) {
+		r.tagService = tagService
+	}
+}
+
+// WithCategoryService enables the category endpoints
+func WithCategoryService(categoryService service.CategoryService) RouterOption {
+	return func(r *Router) {
+		r.categoryService = categoryService
+	}
+}
+
+// WithViewCounter enables view counting on GET /topics/:id
+func WithViewCounter(viewCounter *service.ViewCounter) RouterOption {
+	return func(r *Router) {
+		r.viewCounter = viewCounter
+	}
+}
+
+// WithReportService enables content reports and the moderation queue
+func WithReportService(reportService service.ReportService) RouterOption {
+	return func(r *Router) {
+		r.reportService = reportService
+	}
+}
+
+// WithRevisionService enables the edit history of topics and comments
+func WithRevisionService(revisionService service.RevisionService) RouterOption {
+	return func(r *Router) {
+		r.revisionService = revisionService
+	}
+}
+
+// WithMentionService enables the mention picker and the list of the user's mentions
+func WithMentionService(mentionService service.MentionService) RouterOption {
+	return func(r *Router) {
+		r.mentionService = mentionService
+	}
+}
+
+// WithNotificationService enables notifications and their live delivery
+// over the WebSocket
+func WithNotificationService(notificationService service.NotificationService) RouterOption {
+	return func(r *Router) {
+		r.notificationService = notificationService
+	}
+}
+
+// WithSubscriptionService enables topic subscriptions and unread comment
+// counts
+func WithSubscriptionService(subscriptionService service.SubscriptionService) RouterOption {
+	return func(r *Router) {
+		r.subscriptionService = subscriptionService
+	}
+}
+
+// WithPollService enables polls attached to topics
+func WithPollService(pollService service.PollService) RouterOption {
+	return func(r *Router) {
+		r.pollService = pollService
+	}
+}
+
+// WithAttachmentService enables file uploads attached to topics and
+// comments
+func WithAttachmentService(attachmentService service.AttachmentService) RouterOption {
+	return func(r *Router) {
+		r.attachmentService = attachmentService
+	}
+}
+
+// WithAvatarService enables avatar uploads and serves the avatars of all
+// users
+func WithAvatarService(avatarService service.AvatarService) RouterOption {
+	return func(r *Router) {
+		r.avatarService = avatarService
+	}
+}
+
+// WithProfileService enables public user profiles and their activity
+func WithProfileService(profileService service.ProfileService) RouterOption {
+	return func(r *Router) {
+		r.profileService = profileService
+	}
+}
+
+// WithDraftService enables drafts of topics and replies with autosave
+func WithDraftService(draftService service.DraftService) RouterOption {
+	return func(r *Router) {
+		r.draftService = draftService
+	}
+}
+
+// WithBookmarkService enables the user's bookmarks
+func WithBookmarkService(bookmarkService service.BookmarkService) RouterOption {
+	return func(r *Router) {
+		r.bookmarkService = bookmarkService
+	}
 }
 
 type WSMessage struct {
 	Type                 string          `json:"type"`
 	Token                string          `json:"token,omitempty"`
 	Content              string          `json:"content,omitempty"`
+	ContentHTML          string          `json:"content_html,omitempty"`
 	Author               string          `json:"author,omitempty"`
+	AuthorAvatar         string          `json:"author_avatar,omitempty"`
 	Data                 json.RawMessage `json:"data,omitempty"`
 	ID                   string          `json:"id,omitempty"`
+	MessageID            int64           `json:"message_id,omitempty"` // chat_messages.id, used to report a message
 	Timestamp            int64           `json:"timestamp,omitempty"`
 	LastMessageTimestamp int64           `json:"lastMessageTimestamp,omitempty"`
 }
@@ -87,6 +221,7 @@ func NewRouter(
 	chatRepo repository.ChatRepository,
 	port string,
 	authConfig *middleware.AuthConfig,
+	opts ...RouterOption,
 ) *Router {
 	// Инициализируем логгер
 	logger, err := zap.NewProduction()
@@ -100,7 +235,7 @@ func NewRouter(
 	// Настройка CORS
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173", "http://127.0.0.1:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Upgrade", "Connection", "Sec-WebSocket-Key", "Sec-WebSocket-Version", "Sec-WebSocket-Protocol"},
 		ExposeHeaders:    []string{"Content-Length", "Upgrade", "Connection", "Sec-WebSocket-Protocol"},
 		AllowCredentials: true,
@@ -143,10 +278,21 @@ func NewRouter(
 		port:           port,
 		upgrader:       upgrader,
 		clients:        make(map[*websocket.Conn]string),
+		writers:        make(map[*websocket.Conn]*wsWriter),
 		authConfig:     authConfig,
 		authURL:        authConfig.AuthServiceURL,
 		logger:         logger,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	topicHandler.viewCounter = r.viewCounter
+	topicHandler.subscriptionService = r.subscriptionService
+	if r.notificationService != nil {
+		r.notificationService.SetPublisher(r)
+	}
+
+	requireModerator := middleware.RequireRole(userRepo, entity.RoleModerator, entity.RoleAdmin)
 
 	// Группа маршрутов API v1
 	v1 := router.Group("/api/v1")
@@ -155,23 +301,40 @@ func NewRouter(
 		topics := v1.Group("/topics")
 		{
 			topics.GET("", topicHandler.GetAllTopics)
-			topics.GET("/:id", topicHandler.GetTopic)
-			topics.GET("/:id/comments", commentHandler.GetAllCommentsByTopic)
+			topics.GET("/scheduled", authMiddleware.AuthMiddleware(), topicHandler.ListScheduledTopics)
+			topics.GET("/:id", authMiddleware.OptionalAuthMiddleware(), topicHandler.GetTopic)
+			topics.GET("/:id/comments", authMiddleware.OptionalAuthMiddleware(), commentHandler.GetAllCommentsByTopic)
+			topics.GET("/:id/comments/tree", authMiddleware.OptionalAuthMiddleware(), commentHandler.GetCommentTree)
 			topics.POST("", authMiddleware.AuthMiddleware(), topicHandler.CreateTopic)
 			topics.PUT("/:id", authMiddleware.AuthMiddleware(), topicHandler.UpdateTopic)
 			topics.DELETE("/:id", authMiddleware.AuthMiddleware(), topicHandler.DeleteTopic)
+			topics.POST("/:id/restore", authMiddleware.AuthMiddleware(), requireModerator, topicHandler.RestoreTopic)
+			topics.PUT("/:id/pin", authMiddleware.AuthMiddleware(), requireModerator, topicHandler.PinTopic)
+			topics.DELETE("/:id/pin", authMiddleware.AuthMiddleware(), requireModerator, topicHandler.UnpinTopic)
+			topics.PUT("/:id/lock", authMiddleware.AuthMiddleware(), requireModerator, topicHandler.LockTopic)
+			topics.DELETE("/:id/lock", authMiddleware.AuthMiddleware(), requireModerator, topicHandler.UnlockTopic)
+			topics.PUT("/:id/announcement", authMiddleware.AuthMiddleware(), requireModerator, topicHandler.SetAnnouncement)
+			topics.DELETE("/:id/announcement", authMiddleware.AuthMiddleware(), requireModerator, topicHandler.ClearAnnouncement)
+			topics.PUT("/:id/schedule", authMiddleware.AuthMiddleware(), topicHandler.RescheduleTopic)
+			topics.DELETE("/:id/schedule", authMiddleware.AuthMiddleware(), topicHandler.CancelScheduledTopic)
 		}
 
 		// Маршруты для комментариев
 		comments := v1.Group("/comments")
 		{
-			comments.GET("/:id", commentHandler.GetComment)
+			comments.GET("/:id", authMiddleware.OptionalAuthMiddleware(), commentHandler.GetComment)
+			comments.GET("/:id/replies", authMiddleware.OptionalAuthMiddleware(), commentHandler.GetReplies)
+			comments.GET("/:id/likes", commentHandler.GetCommentLikes)
+			comments.POST("/:id/like", authMiddleware.AuthMiddleware(), commentHandler.LikeComment)
+			comments.DELETE("/:id/like", authMiddleware.AuthMiddleware(), commentHandler.UnlikeComment)
+			comments.POST("/:id/restore", authMiddleware.AuthMiddleware(), requireModerator, commentHandler.RestoreComment)
 		}
 
 		// Маршруты для комментариев к теме
 		topicComments := v1.Group("/topics/:id/comments")
 		{
 			topicComments.POST("", authMiddleware.AuthMiddleware(), commentHandler.CreateComment)
+			topicComments.PUT("/:commentId", authMiddleware.AuthMiddleware(), commentHandler.UpdateComment)
 			topicComments.DELETE("/:commentId", authMiddleware.AuthMiddleware(), commentHandler.DeleteComment)
 		}
 
@@ -187,6 +350,161 @@ func NewRouter(
 				c.JSON(http.StatusOK, messages)
 			})
 		}
+
+		// Маршруты для категорий
+		if r.categoryService != nil {
+			categoryHandler := NewCategoryHandler(r.categoryService)
+			requireAdmin := middleware.RequireRole(userRepo, entity.RoleAdmin)
+			categories := v1.Group("/categories")
+			{
+				categories.GET("", categoryHandler.GetAllCategories)
+				categories.POST("", authMiddleware.AuthMiddleware(), requireAdmin, categoryHandler.CreateCategory)
+				categories.PUT("/order", authMiddleware.AuthMiddleware(), requireAdmin, categoryHandler.ReorderCategories)
+				categories.PUT("/:id", authMiddleware.AuthMiddleware(), requireAdmin, categoryHandler.UpdateCategory)
+				categories.DELETE("/:id", authMiddleware.AuthMiddleware(), requireAdmin, categoryHandler.DeleteCategory)
+			}
+		}
+
+		// Маршруты для тегов
+		if r.tagService != nil {
+			tagHandler := NewTagHandler(r.tagService)
+			tags := v1.Group("/tags")
+			{
+				tags.GET("", tagHandler.GetAllTags)
+				tags.PUT("/:id", authMiddleware.AuthMiddleware(), requireModerator, tagHandler.RenameTag)
+				tags.POST("/:id/merge", authMiddleware.AuthMiddleware(), requireModerator, tagHandler.MergeTags)
+			}
+		}
+
+		// Маршруты для жалоб и очереди модерации
+		if r.reportService != nil {
+			reportHandler := NewReportHandler(r.reportService)
+			v1.POST("/reports", authMiddleware.AuthMiddleware(), reportHandler.CreateReport)
+			reports := v1.Group("/moderation/reports", authMiddleware.AuthMiddleware(), requireModerator)
+			{
+				reports.GET("", reportHandler.ListReports)
+				reports.POST("/:id/claim", reportHandler.ClaimReport)
+				reports.POST("/:id/resolve", reportHandler.ResolveReport)
+				reports.POST("/:id/dismiss", reportHandler.DismissReport)
+			}
+		}
+
+		// Маршруты для истории правок
+		if r.revisionService != nil {
+			revisionHandler := NewRevisionHandler(r.revisionService)
+			v1.GET("/topics/:id/revisions", revisionHandler.ListTopicRevisions)
+			v1.GET("/topics/:id/revisions/diff", revisionHandler.DiffTopicRevisions)
+			v1.POST("/topics/:id/revisions/:revision/rollback", authMiddleware.AuthMiddleware(), requireModerator, revisionHandler.RollbackTopic)
+			v1.GET("/comments/:id/revisions", revisionHandler.ListCommentRevisions)
+			v1.GET("/comments/:id/revisions/diff", revisionHandler.DiffCommentRevisions)
+			v1.POST("/comments/:id/revisions/:revision/rollback", authMiddleware.AuthMiddleware(), requireModerator, revisionHandler.RollbackComment)
+		}
+
+		// Маршруты для упоминаний
+		if r.mentionService != nil {
+			mentionHandler := NewMentionHandler(r.mentionService)
+			v1.GET("/users/suggest", mentionHandler.SuggestUsers)
+			v1.GET("/mentions", authMiddleware.AuthMiddleware(), mentionHandler.GetMentions)
+		}
+
+		// Маршруты для уведомлений
+		if r.notificationService != nil {
+			notificationHandler := NewNotificationHandler(r.notificationService)
+			notifications := v1.Group("/notifications", authMiddleware.AuthMiddleware())
+			{
+				notifications.GET("", notificationHandler.ListNotifications)
+				notifications.GET("/unread-count", notificationHandler.UnreadCount)
+				notifications.POST("/read-all", notificationHandler.MarkAllRead)
+				notifications.POST("/:id/read", notificationHandler.MarkRead)
+			}
+		}
+
+		// Маршруты для подписок на темы
+		if r.subscriptionService != nil {
+			subscriptionHandler := NewSubscriptionHandler(r.subscriptionService)
+			v1.GET("/topics/:id/subscription", authMiddleware.AuthMiddleware(), subscriptionHandler.GetSubscription)
+			v1.PUT("/topics/:id/subscription", authMiddleware.AuthMiddleware(), subscriptionHandler.Subscribe)
+			v1.DELETE("/topics/:id/subscription", authMiddleware.AuthMiddleware(), subscriptionHandler.Unsubscribe)
+			subscriptions := v1.Group("/subscriptions", authMiddleware.AuthMiddleware())
+			{
+				subscriptions.GET("", subscriptionHandler.ListWatchedTopics)
+				subscriptions.GET("/settings", subscriptionHandler.GetSettings)
+				subscriptions.PUT("/settings", subscriptionHandler.UpdateSettings)
+			}
+		}
+
+		// Маршруты для закладок
+		if r.bookmarkService != nil {
+			bookmarkHandler := NewBookmarkHandler(r.bookmarkService)
+			bookmarks := v1.Group("/bookmarks", authMiddleware.AuthMiddleware())
+			{
+				bookmarks.GET("", bookmarkHandler.ListBookmarks)
+				bookmarks.POST("", bookmarkHandler.CreateBookmark)
+				bookmarks.GET("/:id", bookmarkHandler.GetBookmark)
+				bookmarks.PUT("/:id", bookmarkHandler.UpdateBookmark)
+				bookmarks.DELETE("/:id", bookmarkHandler.DeleteBookmark)
+			}
+		}
+
+		// Маршруты для черновиков
+		if r.draftService != nil {
+			draftHandler := NewDraftHandler(r.draftService)
+			drafts := v1.Group("/drafts", authMiddleware.AuthMiddleware())
+			{
+				drafts.GET("", draftHandler.ListDrafts)
+				drafts.PUT("", draftHandler.SaveDraft)
+				drafts.DELETE("/:id", draftHandler.DeleteDraft)
+				drafts.POST("/:id/publish", draftHandler.PublishDraft)
+			}
+		}
+
+		// Маршруты для опросов в темах
+		if r.pollService != nil {
+			pollHandler := NewPollHandler(r.pollService)
+			v1.GET("/topics/:id/poll", authMiddleware.OptionalAuthMiddleware(), pollHandler.GetPoll)
+			v1.POST("/topics/:id/poll", authMiddleware.AuthMiddleware(), pollHandler.CreatePoll)
+			v1.PUT("/topics/:id/poll/vote", authMiddleware.AuthMiddleware(), pollHandler.Vote)
+		}
+
+		// Маршруты для вложений
+		if r.attachmentService != nil {
+			attachmentHandler := NewAttachmentHandler(r.attachmentService)
+			attachments := v1.Group("/attachments")
+			{
+				attachments.POST("", authMiddleware.AuthMiddleware(), attachmentHandler.UploadAttachment)
+				attachments.GET("/:id", authMiddleware.OptionalAuthMiddleware(), attachmentHandler.GetAttachment)
+				attachments.DELETE("/:id", authMiddleware.AuthMiddleware(), attachmentHandler.DeleteAttachment)
+				// Доступ к файлам дает подпись ссылки, а не токен
+				attachments.GET("/:id/download", attachmentHandler.Download)
+				attachments.GET("/:id/thumbnail", attachmentHandler.Thumbnail)
+			}
+			v1.GET("/topics/:id/attachments", attachmentHandler.ListTopicAttachments)
+			v1.POST("/topics/:id/attachments", authMiddleware.AuthMiddleware(), attachmentHandler.LinkTopicAttachments)
+			v1.POST("/comments/:id/attachments", authMiddleware.AuthMiddleware(), attachmentHandler.LinkCommentAttachments)
+		}
+
+		// Маршруты для аватаров
+		if r.avatarService != nil {
+			avatarHandler := NewAvatarHandler(r.avatarService)
+			v1.PUT("/users/me/avatar", authMiddleware.AuthMiddleware(), avatarHandler.UploadAvatar)
+			v1.DELETE("/users/me/avatar", authMiddleware.AuthMiddleware(), avatarHandler.DeleteAvatar)
+			v1.GET("/users/:id/avatar", avatarHandler.GetAvatar)
+		}
+
+		// Маршруты для профилей пользователей
+		if r.profileService != nil {
+			profileHandler := NewProfileHandler(r.profileService)
+			v1.GET("/users/:id", profileHandler.GetProfile)
+			v1.GET("/users/:id/topics", profileHandler.GetUserTopics)
+			v1.GET("/users/:id/comments", profileHandler.GetUserComments)
+			v1.PATCH("/users/me/profile", authMiddleware.AuthMiddleware(), profileHandler.UpdateProfile)
+		}
+
+		// Маршрут для полнотекстового поиска
+		if r.searchService != nil {
+			searchHandler := NewSearchHandler(r.searchService)
+			v1.GET("/search", searchHandler.Search)
+		}
 	}
 
 	// WebSocket маршрут
@@ -208,6 +526,8 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 		return
 	}
 	defer conn.Close()
+	r.addWriter(conn)
+	defer r.removeWriter(conn)
 
 	// Set read deadline
 	conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -236,11 +556,14 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 			}
 
 			response := WSMessage{
-				Type:      "message",
-				Content:   msg.Content,
-				Author:    msg.AuthorUsername,
-				ID:        fmt.Sprintf("%d:%d", msg.AuthorID, msgTime),
-				Timestamp: msgTime,
+				Type:         "message",
+				Content:      msg.Content,
+				ContentHTML:  msg.ContentHTML,
+				Author:       msg.AuthorUsername,
+				AuthorAvatar: msg.AuthorAvatar,
+				ID:           fmt.Sprintf("%d:%d", msg.AuthorID, msgTime),
+				MessageID:    msg.ID,
+				Timestamp:    msgTime,
 			}
 			responseBytes, err := json.Marshal(response)
 			if err != nil {
@@ -248,7 +571,7 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 					zap.Error(err))
 				continue
 			}
-			if err := conn.WriteMessage(websocket.TextMessage, responseBytes); err != nil {
+			if err := r.writeMessage(conn, websocket.TextMessage, responseBytes); err != nil {
 				r.logger.Error("Error sending message",
 					zap.Error(err))
 			}
@@ -299,7 +622,7 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 					zap.Error(err))
 				continue
 			}
-			if err := conn.WriteMessage(websocket.TextMessage, responseBytes); err != nil {
+			if err := r.writeMessage(conn, websocket.TextMessage, responseBytes); err != nil {
 				r.logger.Error("Error sending pong response",
 					zap.Error(err))
 			}
@@ -354,7 +677,7 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 						zap.Error(err))
 					continue
 				}
-				if err := conn.WriteMessage(messageType, errorBytes); err != nil {
+				if err := r.writeMessage(conn, messageType, errorBytes); err != nil {
 					r.logger.Error("Error sending error response",
 						zap.Error(err))
 				}
@@ -381,7 +704,9 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 			}
 
 			// Сохраняем информацию о пользователе
+			r.clientsMu.Lock()
 			r.clients[conn] = fmt.Sprintf("%d:%s", userID, userData.Username)
+			r.clientsMu.Unlock()
 			r.logger.Info("User connected",
 				zap.String("username", userData.Username),
 				zap.String("remote_addr", c.Request.RemoteAddr))
@@ -397,12 +722,23 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 					zap.Error(err))
 				continue
 			}
-			if err := conn.WriteMessage(messageType, responseBytes); err != nil {
+			if err := r.writeMessage(conn, messageType, responseBytes); err != nil {
 				r.logger.Error("Error sending auth success response",
 					zap.Error(err))
 				continue
 			}
 
+			// Сообщаем число непрочитанных уведомлений
+			if r.notificationService != nil {
+				unread, err := r.notificationService.UnreadCount(c.Request.Context(), userID)
+				if err != nil {
+					r.logger.Error("Error counting unread notifications",
+						zap.Error(err))
+				} else {
+					r.sendNotificationEvent(conn, &entity.NotificationEvent{Unread: unread})
+				}
+			}
+
 			// Загружаем и отправляем новые сообщения
 			recentMessages, err := r.chatRepo.GetRecentMessages(c.Request.Context(), 50)
 			if err != nil {
@@ -420,11 +756,14 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 					}
 
 					response := WSMessage{
-						Type:      "message",
-						Content:   msg.Content,
-						Author:    msg.AuthorUsername,
-						ID:        fmt.Sprintf("%d:%d", msg.AuthorID, msgTime),
-						Timestamp: msgTime,
+						Type:         "message",
+						Content:      msg.Content,
+						ContentHTML:  msg.ContentHTML,
+						Author:       msg.AuthorUsername,
+						AuthorAvatar: msg.AuthorAvatar,
+						ID:           fmt.Sprintf("%d:%d", msg.AuthorID, msgTime),
+						MessageID:    msg.ID,
+						Timestamp:    msgTime,
 					}
 					responseBytes, err := json.Marshal(response)
 					if err != nil {
@@ -432,7 +771,7 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 							zap.Error(err))
 						continue
 					}
-					if err := conn.WriteMessage(websocket.TextMessage, responseBytes); err != nil {
+					if err := r.writeMessage(conn, websocket.TextMessage, responseBytes); err != nil {
 						r.logger.Error("Error sending message",
 							zap.Error(err))
 					}
@@ -442,7 +781,9 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 		}
 
 		// Проверяем, авторизован ли клиент
+		r.clientsMu.Lock()
 		clientInfo, exists := r.clients[conn]
+		r.clientsMu.Unlock()
 		if !exists {
 			errorResponse := WSMessage{
 				Type:    "error",
@@ -454,7 +795,7 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 					zap.Error(err))
 				continue
 			}
-			if err := conn.WriteMessage(messageType, errorBytes); err != nil {
+			if err := r.writeMessage(conn, messageType, errorBytes); err != nil {
 				r.logger.Error("Error sending error response",
 					zap.Error(err))
 			}
@@ -494,14 +835,23 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 					zap.Error(err))
 				continue
 			}
+			if r.notificationService != nil {
+				r.notificationService.Mentioned(c.Request.Context(), entity.NotificationTarget{
+					TargetType: entity.NotificationTargetChatMessage,
+					TargetID:   message.ID,
+				}, userID, message.MentionedIDs)
+			}
 
 			// Отправляем сообщение всем клиентам, кроме отправителя
 			response := WSMessage{
-				Type:      "message",
-				Content:   wsMsg.Content,
-				Author:    username,
-				ID:        messageID,
-				Timestamp: time.Now().Unix(),
+				Type:         "message",
+				Content:      wsMsg.Content,
+				ContentHTML:  message.ContentHTML,
+				Author:       username,
+				AuthorAvatar: message.AuthorAvatar,
+				ID:           messageID,
+				MessageID:    message.ID,
+				Timestamp:    time.Now().Unix(),
 			}
 			responseBytes, err := json.Marshal(response)
 			if err != nil {
@@ -510,16 +860,18 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 				continue
 			}
 
-			for client := range r.clients {
+			for _, client := range r.connections(0) {
 				// Не отправляем сообщение отправителю
 				if client == conn {
 					continue
 				}
-				if err := client.WriteMessage(messageType, responseBytes); err != nil {
+				if err := r.writeMessage(client, messageType, responseBytes); err != nil {
 					r.logger.Error("Error broadcasting message",
 						zap.Error(err))
 					client.Close()
+					r.clientsMu.Lock()
 					delete(r.clients, client)
+					r.clientsMu.Unlock()
 				}
 			}
 
@@ -527,6 +879,7 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 			confirmation := WSMessage{
 				Type:      "message_sent",
 				ID:        messageID,
+				MessageID: message.ID,
 				Timestamp: time.Now().Unix(),
 			}
 			confirmationBytes, err := json.Marshal(confirmation)
@@ -535,7 +888,7 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 					zap.Error(err))
 				continue
 			}
-			if err := conn.WriteMessage(messageType, confirmationBytes); err != nil {
+			if err := r.writeMessage(conn, messageType, confirmationBytes); err != nil {
 				r.logger.Error("Error sending confirmation",
 					zap.Error(err))
 			}
@@ -543,11 +896,162 @@ func (r *Router) handleWebSocket(c *gin.Context) {
 	}
 
 	// Очищаем информацию о клиенте при отключении
+	r.clientsMu.Lock()
 	delete(r.clients, conn)
+	r.clientsMu.Unlock()
 	r.logger.Info("Client disconnected",
 		zap.String("remote_addr", c.Request.RemoteAddr))
 }
 
+const (
+	// wsWriteTimeout bounds a single write to a WebSocket connection
+	wsWriteTimeout = 10 * time.Second
+	// wsSendQueueSize is how many messages may wait for a connection before
+	// it is considered too slow and dropped
+	wsSendQueueSize = 64
+)
+
+var (
+	errWSClosed = errors.New("websocket connection is closed")
+	errWSSlow   = errors.New("websocket connection is too slow")
+)
+
+type wsFrame struct {
+	messageType int
+	data        []byte
+}
+
+// wsWriter owns the writes to one connection. Messages are queued and
+// written by its own goroutine, so a slow client holds up neither other
+// clients nor the request that produced the message.
+type wsWriter struct {
+	conn     *websocket.Conn
+	queue    chan wsFrame
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newWSWriter(conn *websocket.Conn) *wsWriter {
+	w := &wsWriter{
+		conn:  conn,
+		queue: make(chan wsFrame, wsSendQueueSize),
+		done:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *wsWriter) run() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case frame := <-w.queue:
+			w.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := w.conn.WriteMessage(frame.messageType, frame.data); err != nil {
+				// Чтение в обработчике соединения тоже упадет, и он уберет клиента
+				w.close()
+				return
+			}
+		}
+	}
+}
+
+// send queues a message. A connection that has fallen too far behind is
+// closed instead.
+func (w *wsWriter) send(messageType int, data []byte) error {
+	select {
+	case <-w.done:
+		return errWSClosed
+	default:
+	}
+	select {
+	case w.queue <- wsFrame{messageType: messageType, data: data}:
+		return nil
+	default:
+		w.close()
+		return errWSSlow
+	}
+}
+
+func (w *wsWriter) close() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.conn.Close()
+	})
+}
+
+func (r *Router) addWriter(conn *websocket.Conn) {
+	r.clientsMu.Lock()
+	defer r.clientsMu.Unlock()
+	r.writers[conn] = newWSWriter(conn)
+}
+
+func (r *Router) removeWriter(conn *websocket.Conn) {
+	r.clientsMu.Lock()
+	w := r.writers[conn]
+	delete(r.writers, conn)
+	r.clientsMu.Unlock()
+	if w != nil {
+		w.close()
+	}
+}
+
+// writeMessage queues a message for a connection. A connection supports
+// only one concurrent writer, and notifications are pushed from outside its
+// handler, so every write goes through the connection's wsWriter.
+func (r *Router) writeMessage(conn *websocket.Conn, messageType int, data []byte) error {
+	r.clientsMu.Lock()
+	w := r.writers[conn]
+	r.clientsMu.Unlock()
+	if w == nil {
+		return errWSClosed
+	}
+	return w.send(messageType, data)
+}
+
+// connections returns the authenticated connections of a user, or all of
+// them when userID is zero
+func (r *Router) connections(userID int64) []*websocket.Conn {
+	r.clientsMu.Lock()
+	defer r.clientsMu.Unlock()
+
+	var conns []*websocket.Conn
+	for conn, clientInfo := range r.clients {
+		if userID == 0 || strings.HasPrefix(clientInfo, strconv.FormatInt(userID, 10)+":") {
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
+// PublishNotification sends a notification event to every open connection
+// of a user
+func (r *Router) PublishNotification(userID int64, event *entity.NotificationEvent) {
+	for _, conn := range r.connections(userID) {
+		r.sendNotificationEvent(conn, event)
+	}
+}
+
+func (r *Router) sendNotificationEvent(conn *websocket.Conn, event *entity.NotificationEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Error("Error marshaling notification",
+			zap.Error(err))
+		return
+	}
+	responseBytes, err := json.Marshal(WSMessage{Type: "notification", Data: data})
+	if err != nil {
+		r.logger.Error("Error marshaling notification",
+			zap.Error(err))
+		return
+	}
+	if err := r.writeMessage(conn, websocket.TextMessage, responseBytes); err != nil {
+		r.logger.Error("Error sending notification",
+			zap.Error(err))
+	}
+}
+
 func (r *Router) Run(addr string) error {
 	return r.engine.Run(addr)
 }
@@ -6,8 +6,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/sout1235/forum2/backend/forum-service/internal/delivery/http/middleware"
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
 	"github.com/stretchr/testify/assert"
@@ -28,21 +30,85 @@ func (m *MockTopicService) GetTopicByID(ctx context.Context, id int64) (*entity.
 	return args.Get(0).(*entity.Topic), args.Error(1)
 }
 
-func (m *MockTopicService) GetAllTopics(ctx context.Context) ([]*entity.Topic, error) {
-	args := m.Called(ctx)
-	return args.Get(0).([]*entity.Topic), args.Error(1)
+func (m *MockTopicService) GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(*entity.TopicPage), args.Error(1)
 }
 
-func (m *MockTopicService) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
-	args := m.Called(ctx, topic)
+func (m *MockTopicService) UpdateTopic(ctx context.Context, topic *entity.Topic, userID int64) error {
+	args := m.Called(ctx, topic, userID)
+	return args.Error(0)
+}
+
+func (m *MockTopicService) DeleteTopic(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
 	return args.Error(0)
 }
 
-func (m *MockTopicService) DeleteTopic(ctx context.Context, id int64) error {
+func (m *MockTopicService) RestoreTopic(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTopicService) PinTopic(ctx context.Context, id int64, scope entity.PinScope, until *time.Time) (*entity.Topic, error) {
+	args := m.Called(ctx, id, scope, until)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *MockTopicService) UnpinTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	args := m.Called(ctx, id)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *MockTopicService) LockTopic(ctx context.Context, id, moderatorID int64, until *time.Time) (*entity.Topic, error) {
+	args := m.Called(ctx, id, moderatorID, until)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *MockTopicService) UnlockTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	args := m.Called(ctx, id)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *MockTopicService) SetAnnouncement(ctx context.Context, id int64, until *time.Time) (*entity.Topic, error) {
+	args := m.Called(ctx, id, until)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *MockTopicService) ClearAnnouncement(ctx context.Context, id int64) (*entity.Topic, error) {
+	args := m.Called(ctx, id)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *MockTopicService) GetScheduledTopic(ctx context.Context, id, viewerID int64) (*entity.Topic, error) {
+	args := m.Called(ctx, id, viewerID)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *MockTopicService) ListScheduledTopics(ctx context.Context, userID int64) ([]*entity.Topic, error) {
+	args := m.Called(ctx, userID)
+	topics, _ := args.Get(0).([]*entity.Topic)
+	return topics, args.Error(1)
+}
+
+func (m *MockTopicService) RescheduleTopic(ctx context.Context, id, userID int64, publishAt time.Time) (*entity.Topic, error) {
+	args := m.Called(ctx, id, userID, publishAt)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *MockTopicService) CancelScheduledTopic(ctx context.Context, id, userID int64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
 func (m *MockTopicService) IncrementViews(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -81,6 +147,11 @@ func (m *RouterTestUserRepoMock) GetUsernameByID(ctx context.Context, id int64)
 	return args.String(0), args.Error(1)
 }
 
+func (m *RouterTestUserRepoMock) GetUserRole(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
 func (m *RouterTestUserRepoMock) GetUserByID(ctx context.Context, id int64) (*entity.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -106,22 +177,22 @@ func TestRouter(t *testing.T) {
 	chatRepo := new(MockChatRepository)
 
 	// Настраиваем моки
-	topicService.On("GetAllTopics", mock.Anything).Return([]*entity.Topic{}, nil)
+	topicService.On("GetAllTopics", mock.Anything, mock.Anything).Return(&entity.TopicPage{Topics: []*entity.Topic{}}, nil)
 	topicService.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1}, nil)
-	commentUseCase.On("GetCommentsByTopicID", mock.Anything, int64(1)).Return([]*entity.Comment{}, nil)
-	commentUseCase.On("GetCommentByID", mock.Anything, int64(1)).Return(&entity.Comment{ID: 1}, nil)
+	commentUseCase.On("GetCommentsByTopicID", mock.Anything, int64(1), int64(0)).Return([]*entity.Comment{}, nil)
+	commentUseCase.On("GetCommentByID", mock.Anything, int64(1), int64(0)).Return(&entity.Comment{ID: 1}, nil)
 
 	// Для негативных сценариев (сначала!)
 	topicService.On("CreateTopic", mock.Anything, mock.MatchedBy(func(t *entity.Topic) bool { return t.Title == "" || t.Content == "" })).Return(assert.AnError)
-	topicService.On("UpdateTopic", mock.Anything, mock.MatchedBy(func(t *entity.Topic) bool { return t.Title == "" || t.Content == "" })).Return(assert.AnError)
+	topicService.On("UpdateTopic", mock.Anything, mock.MatchedBy(func(t *entity.Topic) bool { return t.Title == "" || t.Content == "" }), mock.Anything).Return(assert.AnError)
 	commentUseCase.On("CreateComment", mock.Anything, mock.MatchedBy(func(c *entity.Comment) bool { return c.Content == "" })).Return(assert.AnError)
 
 	// Для успешных сценариев (после негативных)
 	topicService.On("CreateTopic", mock.Anything, mock.Anything).Return(nil)
-	topicService.On("UpdateTopic", mock.Anything, mock.AnythingOfType("*entity.Topic")).Return(nil)
-	topicService.On("DeleteTopic", mock.Anything, int64(1)).Return(nil)
+	topicService.On("UpdateTopic", mock.Anything, mock.AnythingOfType("*entity.Topic"), mock.Anything).Return(nil)
+	topicService.On("DeleteTopic", mock.Anything, int64(1), mock.Anything).Return(nil)
 	commentUseCase.On("CreateComment", mock.Anything, mock.Anything).Return(nil)
-	commentUseCase.On("DeleteComment", mock.Anything, int64(1)).Return(nil)
+	commentUseCase.On("DeleteComment", mock.Anything, int64(1), mock.Anything).Return(nil)
 	userRepo.On("GetUsernameByID", mock.Anything, int64(1)).Return("testuser", nil)
 
 	// Создаем роутер с тестовым auth-сервисом
@@ -304,3 +375,53 @@ func TestRouter(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 }
+
+func TestWSWriter(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	serverConns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		serverConns <- conn
+	}))
+	defer server.Close()
+
+	dial := func() (*websocket.Conn, *wsWriter) {
+		client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		return client, newWSWriter(<-serverConns)
+	}
+
+	t.Run("delivers messages", func(t *testing.T) {
+		client, w := dial()
+		defer client.Close()
+		defer w.close()
+
+		assert.NoError(t, w.send(websocket.TextMessage, []byte("one")))
+		assert.NoError(t, w.send(websocket.TextMessage, []byte("two")))
+		for _, want := range []string{"one", "two"} {
+			_, data, err := client.ReadMessage()
+			assert.NoError(t, err)
+			assert.Equal(t, want, string(data))
+		}
+	})
+
+	t.Run("drops a client that does not read", func(t *testing.T) {
+		client, w := dial()
+		defer client.Close()
+
+		// Сокет забивается, очередь растет, и соединение закрывается, не блокируя отправителя
+		payload := make([]byte, 1<<20)
+		var err error
+		for i := 0; i < wsSendQueueSize*4 && err == nil; i++ {
+			err = w.send(websocket.BinaryMessage, payload)
+		}
+		assert.ErrorIs(t, err, errWSSlow)
+		assert.ErrorIs(t, w.send(websocket.TextMessage, []byte("late")), errWSClosed)
+	})
+}
@@ -0,0 +1,149 @@
+package httpDelivery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// SearchHandler handles HTTP requests for full-text search
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+// SearchResultResponse represents a single search hit
+// @Description Topic or comment matching a search query
+type SearchResultResponse struct {
+	Type       string  `json:"type" example:"comment" enums:"topic,comment"`
+	ID         int64   `json:"id" example:"10"`
+	TopicID    int64   `json:"topic_id" example:"1"`
+	TopicTitle string  `json:"topic_title" example:"How to use Go"`
+	CategoryID int64   `json:"category_id" example:"1"`
+	AuthorID   int64   `json:"author_id" example:"1"`
+	Author     Author  `json:"author"`
+	Snippet    string  `json:"snippet" example:"… a tutorial about <mark>Go</mark> programming …"`
+	Rank       float64 `json:"rank" example:"0.42"`
+	CreatedAt  string  `json:"created_at" example:"2024-03-15T10:00:00Z"`
+}
+
+// SearchResponse represents a page of search results
+// @Description Page of search results ordered by relevance
+type SearchResponse struct {
+	Results    []SearchResultResponse `json:"results"`
+	NextOffset int                    `json:"next_offset,omitempty" example:"20"`
+}
+
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// @Summary Search topics and comments
+// @Description Full-text search over topic and comment text. Results are ranked by relevance and contain an HTML snippet with matches wrapped in <mark>
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query (supports quoted phrases, OR and -exclusion)"
+// @Param category_id query int false "Only results from this category"
+// @Param author_id query int false "Only results written by this user"
+// @Param from query string false "Only results created at or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Only results created up to this date (YYYY-MM-DD, inclusive, or RFC 3339)"
+// @Param limit query int false "Items per page" default(20) maximum(50)
+// @Param offset query int false "Offset from the previous page's next_offset"
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := entity.SearchQuery{Query: c.Query("q")}
+
+	var err error
+	if query.CategoryID, err = parseOptionalID(c, "category_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+	if query.AuthorID, err = parseOptionalID(c, "author_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid author ID"})
+		return
+	}
+	if query.From, err = parseSearchDate(c.Query("from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+		return
+	}
+	if query.To, err = parseSearchDate(c.Query("to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		query.Limit = n
+	}
+	if offset := c.Query("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+			return
+		}
+		query.Offset = n
+	}
+
+	page, err := h.searchService.Search(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptySearchQuery) ||
+			errors.Is(err, service.ErrSearchQueryTooLong) ||
+			errors.Is(err, service.ErrInvalidSearchPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error searching for %q: %v", query.Query, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to search: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseOptionalID parses a positive int64 query parameter; a missing
+// parameter yields 0.
+func parseOptionalID(c *gin.Context, name string) (int64, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+// parseSearchDate accepts either a calendar date or an RFC 3339 timestamp.
+// A calendar date used as the end of a range covers the whole day.
+func parseSearchDate(raw string, endOfRange bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -0,0 +1,120 @@
+package httpDelivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSearchService struct {
+	mock.Mock
+}
+
+func (m *MockSearchService) Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SearchPage), args.Error(1)
+}
+
+func setupSearchRouter(searchService service.SearchService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewSearchHandler(searchService)
+	r.GET("/search", h.Search)
+	return r
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	mockService := new(MockSearchService)
+	r := setupSearchRouter(mockService)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	expected := entity.SearchQuery{
+		Query:      "горутины",
+		CategoryID: 2,
+		AuthorID:   3,
+		From:       &from,
+		To:         &to,
+		Limit:      10,
+		Offset:     10,
+	}
+	page := &entity.SearchPage{
+		Results: []*entity.SearchResult{
+			{Type: entity.SearchResultComment, ID: 5, TopicID: 1, Snippet: "<mark>горутины</mark>"},
+		},
+		NextOffset: 20,
+	}
+	mockService.On("Search", mock.Anything, expected).Return(page, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?q=%D0%B3%D0%BE%D1%80%D1%83%D1%82%D0%B8%D0%BD%D1%8B&category_id=2&author_id=3&from=2024-03-01&to=2024-03-31&limit=10&offset=10", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp SearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Results, 1)
+	assert.Equal(t, "<mark>горутины</mark>", resp.Results[0].Snippet)
+	assert.Equal(t, 20, resp.NextOffset)
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchHandler_Search_BadRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		serviceErr error
+	}{
+		{name: "invalid category", url: "/search?q=go&category_id=abc"},
+		{name: "invalid author", url: "/search?q=go&author_id=-1"},
+		{name: "invalid from", url: "/search?q=go&from=yesterday"},
+		{name: "invalid to", url: "/search?q=go&to=2024-13-01"},
+		{name: "invalid limit", url: "/search?q=go&limit=0"},
+		{name: "invalid offset", url: "/search?q=go&offset=-5"},
+		{name: "empty query", url: "/search?q=", serviceErr: service.ErrEmptySearchQuery},
+		{name: "inverted period", url: "/search?q=go&from=2024-04-01&to=2024-03-01", serviceErr: service.ErrInvalidSearchPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSearchService)
+			if tt.serviceErr != nil {
+				mockService.On("Search", mock.Anything, mock.Anything).Return(nil, tt.serviceErr)
+			}
+			r := setupSearchRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSearchHandler_Search_InternalError(t *testing.T) {
+	mockService := new(MockSearchService)
+	mockService.On("Search", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+	r := setupSearchRouter(mockService)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?q=go", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockService.AssertExpectations(t)
+}
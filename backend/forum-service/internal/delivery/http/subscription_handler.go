@@ -0,0 +1,288 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// SubscriptionHandler handles HTTP requests for the current user's topic subscriptions
+type SubscriptionHandler struct {
+	subscriptionService service.SubscriptionService
+}
+
+// SubscriptionRequest represents a change of the watch level on a topic
+// @Description Watch level to set on a topic
+type SubscriptionRequest struct {
+	Level string `json:"level" binding:"required" example:"watching" enums:"watching,tracking,muted"`
+}
+
+// SubscriptionResponse represents a subscription to a topic
+// @Description Watch level of the current user on a topic
+type SubscriptionResponse struct {
+	UserID    int64  `json:"user_id" example:"3"`
+	TopicID   int64  `json:"topic_id" example:"4"`
+	Level     string `json:"level" example:"watching" enums:"watching,tracking,muted"`
+	CreatedAt string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	UpdatedAt string `json:"updated_at" example:"2024-03-15T10:05:00Z"`
+}
+
+// WatchedTopicResponse represents a followed topic
+// @Description Followed topic with the number of comments the user hasn't seen
+type WatchedTopicResponse struct {
+	TopicID        int64  `json:"topic_id" example:"4"`
+	Title          string `json:"title" example:"Go generics"`
+	CommentCount   int    `json:"comment_count" example:"12"`
+	LastActivityAt string `json:"last_activity_at" example:"2024-03-15T10:05:00Z"`
+	Level          string `json:"level" example:"watching" enums:"watching,tracking,muted"`
+	UnreadComments int    `json:"unread_comments" example:"3"`
+}
+
+// WatchedTopicListResponse represents a page of followed topics
+// @Description Page of followed topics, most recently active first
+type WatchedTopicListResponse struct {
+	Topics     []WatchedTopicResponse `json:"topics"`
+	NextCursor string                 `json:"next_cursor,omitempty" example:"eyJrIjoid2F0Y2hlZCJ9"`
+}
+
+// WatchSettingsRequest represents a change of the subscription preferences
+// @Description Subscription preferences
+type WatchSettingsRequest struct {
+	AutoWatch *bool `json:"auto_watch" binding:"required" example:"false"`
+}
+
+// WatchSettingsResponse represents the subscription preferences
+// @Description Subscription preferences of the current user
+type WatchSettingsResponse struct {
+	AutoWatch bool `json:"auto_watch" example:"true"`
+}
+
+func NewSubscriptionHandler(subscriptionService service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// @Summary Get my subscription to a topic
+// @Description Get the current user's watch level on a topic
+// @Tags subscriptions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/subscription [get]
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	subscription, err := h.subscriptionService.GetSubscription(c.Request.Context(), userID.(int64), topicID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// @Summary Set my watch level on a topic
+// @Description Watch a topic (notified of every comment), track it (only unread counts) or mute it (only mentions)
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param subscription body SubscriptionRequest true "Watch level"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/subscription [put]
+func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.subscriptionService.Subscribe(c.Request.Context(), userID.(int64), topicID, entity.WatchLevel(req.Level))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// @Summary Unsubscribe from a topic
+// @Description Remove the current user's subscription to a topic. Commenting in the topic subscribes again; mute it to prevent that
+// @Tags subscriptions
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/subscription [delete]
+func (h *SubscriptionHandler) Unsubscribe(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.subscriptionService.Unsubscribe(c.Request.Context(), userID.(int64), topicID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List topics I follow
+// @Description Get the topics the current user watches or tracks, most recently active first, with unread comment counts
+// @Tags subscriptions
+// @Produce json
+// @Security BearerAuth
+// @Param level query string false "Only topics with this watch level; muted topics are listed only when asked for" Enums(watching, tracking, muted)
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} WatchedTopicListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions [get]
+func (h *SubscriptionHandler) ListWatchedTopics(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	filter := entity.WatchedTopicFilter{
+		UserID: userID.(int64),
+		Level:  entity.WatchLevel(c.Query("level")),
+		Cursor: c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	page, err := h.subscriptionService.ListWatchedTopics(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary Get my subscription settings
+// @Description Get whether the current user is subscribed to topics they create or comment on
+// @Tags subscriptions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} WatchSettingsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/settings [get]
+func (h *SubscriptionHandler) GetSettings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	settings, err := h.subscriptionService.GetWatchSettings(c.Request.Context(), userID.(int64))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary Update my subscription settings
+// @Description Turn automatic subscriptions to topics the current user creates or comments on on or off. Existing subscriptions are kept
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body WatchSettingsRequest true "Subscription settings"
+// @Success 200 {object} WatchSettingsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/settings [put]
+func (h *SubscriptionHandler) UpdateSettings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req WatchSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings := &entity.WatchSettings{AutoWatch: *req.AutoWatch}
+	if err := h.subscriptionService.UpdateWatchSettings(c.Request.Context(), userID.(int64), settings); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *SubscriptionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWatchLevel), errors.Is(err, repository.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrSubscriptionNotFound), errors.Is(err, repository.ErrTopicNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling subscription request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
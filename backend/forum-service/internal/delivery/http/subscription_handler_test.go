@@ -0,0 +1,195 @@
+package httpDelivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionService) GetSubscription(ctx context.Context, userID, topicID int64) (*entity.TopicSubscription, error) {
+	args := m.Called(ctx, userID, topicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TopicSubscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Subscribe(ctx context.Context, userID, topicID int64, level entity.WatchLevel) (*entity.TopicSubscription, error) {
+	args := m.Called(ctx, userID, topicID, level)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TopicSubscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) Unsubscribe(ctx context.Context, userID, topicID int64) error {
+	args := m.Called(ctx, userID, topicID)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionService) ListWatchedTopics(ctx context.Context, filter entity.WatchedTopicFilter) (*entity.WatchedTopicPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WatchedTopicPage), args.Error(1)
+}
+
+func (m *MockSubscriptionService) MarkTopicRead(ctx context.Context, userID, topicID int64) error {
+	args := m.Called(ctx, userID, topicID)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionService) GetWatchSettings(ctx context.Context, userID int64) (*entity.WatchSettings, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WatchSettings), args.Error(1)
+}
+
+func (m *MockSubscriptionService) UpdateWatchSettings(ctx context.Context, userID int64, settings *entity.WatchSettings) error {
+	args := m.Called(ctx, userID, settings)
+	return args.Error(0)
+}
+
+func setupSubscriptionRouter(subscriptionService service.SubscriptionService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewSubscriptionHandler(subscriptionService)
+	withUser := func(c *gin.Context) { c.Set("user_id", int64(5)) }
+	r.GET("/topics/:id/subscription", withUser, h.GetSubscription)
+	r.PUT("/topics/:id/subscription", withUser, h.Subscribe)
+	r.DELETE("/topics/:id/subscription", withUser, h.Unsubscribe)
+	r.GET("/subscriptions", withUser, h.ListWatchedTopics)
+	r.GET("/subscriptions/settings", withUser, h.GetSettings)
+	r.PUT("/subscriptions/settings", withUser, h.UpdateSettings)
+	return r
+}
+
+func TestSubscriptionHandler_Subscribe(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		level      entity.WatchLevel
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", url: "/topics/4/subscription", body: `{"level":"muted"}`, level: entity.WatchLevelMuted, wantStatus: http.StatusOK},
+		{name: "invalid level", url: "/topics/4/subscription", body: `{"level":"loud"}`, level: "loud", mockErr: service.ErrInvalidWatchLevel, wantStatus: http.StatusBadRequest},
+		{name: "topic not found", url: "/topics/4/subscription", body: `{"level":"watching"}`, level: entity.WatchLevelWatching, mockErr: repository.ErrTopicNotFound, wantStatus: http.StatusNotFound},
+		{name: "missing level", url: "/topics/4/subscription", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid id", url: "/topics/abc/subscription", body: `{"level":"muted"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSubscriptionService)
+			if tt.level != "" {
+				var subscription *entity.TopicSubscription
+				if tt.mockErr == nil {
+					subscription = &entity.TopicSubscription{UserID: 5, TopicID: 4, Level: tt.level}
+				}
+				mockService.On("Subscribe", mock.Anything, int64(5), int64(4), tt.level).Return(subscription, tt.mockErr)
+			}
+			r := setupSubscriptionRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", tt.url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var resp SubscriptionResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "muted", resp.Level)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubscriptionHandler_Unsubscribe(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	r := setupSubscriptionRouter(mockService)
+
+	mockService.On("Unsubscribe", mock.Anything, int64(5), int64(4)).Return(nil).Once()
+	mockService.On("Unsubscribe", mock.Anything, int64(5), int64(4)).Return(repository.ErrSubscriptionNotFound).Once()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/topics/4/subscription", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/topics/4/subscription", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSubscriptionHandler_ListWatchedTopics(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	r := setupSubscriptionRouter(mockService)
+
+	filter := entity.WatchedTopicFilter{UserID: 5, Level: entity.WatchLevelWatching, Limit: 10}
+	mockService.On("ListWatchedTopics", mock.Anything, filter).Return(&entity.WatchedTopicPage{
+		Topics:     []*entity.WatchedTopic{{TopicID: 4, Title: "Go generics", Level: entity.WatchLevelWatching, UnreadComments: 3}},
+		NextCursor: "next",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/subscriptions?level=watching&limit=10", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp WatchedTopicListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Topics, 1)
+	assert.Equal(t, 3, resp.Topics[0].UnreadComments)
+	assert.Equal(t, "next", resp.NextCursor)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/subscriptions?limit=0", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSubscriptionHandler_UpdateSettings(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	r := setupSubscriptionRouter(mockService)
+
+	mockService.On("UpdateWatchSettings", mock.Anything, int64(5), &entity.WatchSettings{AutoWatch: false}).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/subscriptions/settings", bytes.NewBufferString(`{"auto_watch":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"auto_watch":false}`, w.Body.String())
+
+	// Без значения настройка не меняется
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/subscriptions/settings", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,151 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+)
+
+// TagHandler handles HTTP requests for tags
+type TagHandler struct {
+	tagService service.TagService
+}
+
+// TagUsageResponse represents a tag with the number of topics using it
+// @Description Tag information with usage count
+type TagUsageResponse struct {
+	ID         int64  `json:"id" example:"1"`
+	Name       string `json:"name" example:"golang"`
+	TopicCount int    `json:"topic_count" example:"12"`
+	CreatedAt  string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+}
+
+// RenameTagRequest represents a new name for a tag
+// @Description New tag name
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required" example:"golang"`
+}
+
+// MergeTagRequest names the tag that absorbs another one
+// @Description Tag to merge into
+type MergeTagRequest struct {
+	TargetID int64 `json:"target_id" binding:"required" example:"2"`
+}
+
+func NewTagHandler(tagService service.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// @Summary Get all tags
+// @Description Get tags that are in use, most used first
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Success 200 {array} TagUsageResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags [get]
+func (h *TagHandler) GetAllTags(c *gin.Context) {
+	tags, err := h.tagService.GetAllTags(c.Request.Context())
+	if err != nil {
+		log.Printf("Error getting tags: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// @Summary Rename a tag
+// @Description Rename a tag on every topic that uses it. Moderators only
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Param tag body RenameTagRequest true "New tag name"
+// @Success 200 {object} Tag
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags/{id} [put]
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	tagID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	var req RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.RenameTag(c.Request.Context(), tagID, req.Name)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// @Summary Merge tags
+// @Description Move every topic from this tag to the target tag and delete this tag. Moderators only
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID of the tag to merge away"
+// @Param merge body MergeTagRequest true "Tag to merge into"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags/{id}/merge [post]
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	tagID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	var req MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tagService.MergeTags(c.Request.Context(), tagID, req.TargetID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tags merged"})
+}
+
+func (h *TagHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTag), errors.Is(err, service.ErrInvalidTagMerge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrTagExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling tag request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,125 @@
+package httpDelivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagService struct {
+	mock.Mock
+}
+
+func (m *MockTagService) GetAllTags(ctx context.Context) ([]*entity.Tag, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Tag), args.Error(1)
+}
+
+func (m *MockTagService) RenameTag(ctx context.Context, id int64, name string) (*entity.Tag, error) {
+	args := m.Called(ctx, id, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Tag), args.Error(1)
+}
+
+func (m *MockTagService) MergeTags(ctx context.Context, sourceID, targetID int64) error {
+	args := m.Called(ctx, sourceID, targetID)
+	return args.Error(0)
+}
+
+func setupTagRouter(tagService service.TagService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewTagHandler(tagService)
+	r.GET("/tags", h.GetAllTags)
+	r.PUT("/tags/:id", h.RenameTag)
+	r.POST("/tags/:id/merge", h.MergeTags)
+	return r
+}
+
+func TestTagHandler_GetAllTags(t *testing.T) {
+	mockService := new(MockTagService)
+	r := setupTagRouter(mockService)
+
+	mockService.On("GetAllTags", mock.Anything).Return([]*entity.Tag{{ID: 1, Name: "golang", TopicCount: 4}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tags", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []TagUsageResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 1)
+	assert.Equal(t, 4, resp[0].TopicCount)
+}
+
+func TestTagHandler_RenameTag(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		mockTag    *entity.Tag
+		mockErr    error
+		wantStatus int
+	}{
+		{name: "success", url: "/tags/1", body: `{"name":"Golang"}`, mockTag: &entity.Tag{ID: 1, Name: "golang"}, wantStatus: http.StatusOK},
+		{name: "invalid id", url: "/tags/abc", body: `{"name":"golang"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid name", url: "/tags/1", body: `{"name":"a;b"}`, mockErr: service.ErrInvalidTag, wantStatus: http.StatusBadRequest},
+		{name: "not found", url: "/tags/1", body: `{"name":"golang"}`, mockErr: repository.ErrTagNotFound, wantStatus: http.StatusNotFound},
+		{name: "name taken", url: "/tags/1", body: `{"name":"golang"}`, mockErr: repository.ErrTagExists, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTagService)
+			if tt.mockTag != nil || tt.mockErr != nil {
+				mockService.On("RenameTag", mock.Anything, int64(1), mock.Anything).Return(tt.mockTag, tt.mockErr)
+			}
+			r := setupTagRouter(mockService)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", tt.url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTagHandler_MergeTags(t *testing.T) {
+	mockService := new(MockTagService)
+	r := setupTagRouter(mockService)
+
+	mockService.On("MergeTags", mock.Anything, int64(2), int64(1)).Return(nil)
+	mockService.On("MergeTags", mock.Anything, int64(1), int64(1)).Return(service.ErrInvalidTagMerge)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tags/2/merge", bytes.NewBufferString(`{"target_id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/tags/1/merge", bytes.NewBufferString(`{"target_id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -1,21 +1,28 @@
 package httpDelivery
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 	"github.com/sout1235/forum2/backend/forum-service/internal/service"
 )
 
 // TopicHandler handles HTTP requests for topics
 type TopicHandler struct {
-	topicUseCase service.TopicService
-	userRepo     repository.UserRepository
+	topicUseCase        service.TopicService
+	userRepo            repository.UserRepository
+	viewCounter         *service.ViewCounter
+	subscriptionService service.SubscriptionService
 }
 
 // Author represents a topic or comment author
@@ -45,7 +52,8 @@ type Tag struct {
 type TopicResponse struct {
 	ID           int64     `json:"id" example:"1"`
 	Title        string    `json:"title" example:"How to use Go"`
-	Content      string    `json:"content" example:"This is a tutorial about Go programming language"`
+	Content      string    `json:"content" example:"This is a tutorial about **Go** programming language"`
+	ContentHTML  string    `json:"content_html" example:"<p>This is a tutorial about <strong>Go</strong> programming language</p>"`
 	AuthorID     int64     `json:"author_id" example:"1"`
 	Author       Author    `json:"author"`
 	CategoryID   int64     `json:"category_id" example:"1"`
@@ -56,18 +64,58 @@ type TopicResponse struct {
 	CreatedAt    string    `json:"created_at" example:"2024-03-15T10:00:00Z"`
 	UpdatedAt    string    `json:"updated_at" example:"2024-03-15T10:00:00Z"`
 	CommentCount int       `json:"comment_count" example:"5"`
+
+	Pinned            string `json:"pinned,omitempty" example:"category" enums:"category,global"`
+	PinnedUntil       string `json:"pinned_until,omitempty" example:"2024-04-01T00:00:00Z"`
+	Locked            bool   `json:"locked" example:"false"`
+	LockedUntil       string `json:"locked_until,omitempty" example:"2024-04-01T00:00:00Z"`
+	Announcement      bool   `json:"announcement" example:"false"`
+	AnnouncementUntil string `json:"announcement_until,omitempty" example:"2024-04-01T00:00:00Z"`
+
+	PublishAt string `json:"publish_at,omitempty" example:"2024-04-01T09:00:00Z"`
+}
+
+// PinTopicRequest represents pinning a topic
+// @Description Where to pin a topic and until when; without an expiry the pin stays until removed
+type PinTopicRequest struct {
+	Scope string     `json:"scope" binding:"required" example:"category" enums:"category,global"`
+	Until *time.Time `json:"until" example:"2024-04-01T00:00:00Z"`
+}
+
+// TopicFlagRequest represents setting a lock or an announcement on a topic
+// @Description Until when the flag holds; without an expiry it stays until removed
+type TopicFlagRequest struct {
+	Until *time.Time `json:"until" example:"2024-04-01T00:00:00Z"`
+}
+
+// ScheduleTopicRequest represents moving the publication of a scheduled topic
+// @Description When the topic is published; must be in the future
+type ScheduleTopicRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required" example:"2024-04-01T09:00:00Z"`
+}
+
+// TopicListResponse represents a page of topics
+// @Description Page of topics with a cursor pointing to the next page
+type TopicListResponse struct {
+	Topics     []TopicResponse `json:"topics"`
+	NextCursor string          `json:"next_cursor,omitempty" example:"eyJrIjoibmV3ZXN0IiwidiI6IjIwMjQtMDMtMTVUMTA6MDA6MDBaIiwiaWQiOjQyfQ"`
 }
 
 // Comment represents a comment on a topic
 // @Description Comment information
 type Comment struct {
-	ID        int64  `json:"id" example:"1"`
-	Content   string `json:"content" example:"Great post!"`
-	AuthorID  int64  `json:"author_id" example:"1"`
-	Author    Author `json:"author"`
-	TopicID   int64  `json:"topic_id" example:"1"`
-	CreatedAt string `json:"created_at" example:"2024-03-15T10:00:00Z"`
-	UpdatedAt string `json:"updated_at" example:"2024-03-15T10:00:00Z"`
+	ID          int64  `json:"id" example:"1"`
+	Content     string `json:"content" example:"Great post!"`
+	ContentHTML string `json:"content_html" example:"<p>Great post!</p>"`
+	AuthorID    int64  `json:"author_id" example:"1"`
+	Author      Author `json:"author"`
+	TopicID     int64  `json:"topic_id" example:"1"`
+	Likes       int    `json:"likes" example:"3"`
+	LikedByMe   bool   `json:"liked_by_me" example:"false"`
+	CreatedAt   string `json:"created_at" example:"2024-03-15T10:00:00Z"`
+	UpdatedAt   string `json:"updated_at" example:"2024-03-15T10:00:00Z"`
+	Edited      bool   `json:"edited" example:"true"`
+	EditedAt    string `json:"edited_at,omitempty" example:"2024-03-15T10:05:00Z"`
 }
 
 func NewTopicHandler(topicUseCase service.TopicService, userRepo repository.UserRepository) *TopicHandler {
@@ -78,7 +126,7 @@ func NewTopicHandler(topicUseCase service.TopicService, userRepo repository.User
 }
 
 // @Summary Create a new topic
-// @Description Create a new topic with the provided information
+// @Description Create a new topic with the provided information. With publish_at in the future the topic is scheduled: only the author and moderators see it until then
 // @Tags topics
 // @Accept json
 // @Produce json
@@ -106,6 +154,10 @@ func (h *TopicHandler) CreateTopic(c *gin.Context) {
 
 	err := h.topicUseCase.CreateTopic(c.Request.Context(), &topic)
 	if err != nil {
+		if isTopicValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -122,7 +174,7 @@ func (h *TopicHandler) CreateTopic(c *gin.Context) {
 }
 
 // @Summary Get a topic by ID
-// @Description Get detailed information about a specific topic
+// @Description Get detailed information about a specific topic. Counts a view, once per reader within a time window. Scheduled topics are shown to their author and moderators only and aren't counted
 // @Tags topics
 // @Accept json
 // @Produce json
@@ -140,8 +192,19 @@ func (h *TopicHandler) GetTopic(c *gin.Context) {
 	}
 
 	topic, err := h.topicUseCase.GetTopicByID(c.Request.Context(), topicID)
+	if errors.Is(err, repository.ErrTopicNotFound) && viewerID(c) != 0 {
+		// Автор и модераторы видят тему еще до публикации
+		scheduled, scheduledErr := h.topicUseCase.GetScheduledTopic(c.Request.Context(), topicID, viewerID(c))
+		if scheduledErr == nil {
+			c.JSON(http.StatusOK, scheduled)
+			return
+		}
+		if !errors.Is(scheduledErr, repository.ErrTopicNotFound) {
+			err = scheduledErr
+		}
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.handleError(c, err)
 		return
 	}
 
@@ -155,32 +218,80 @@ func (h *TopicHandler) GetTopic(c *gin.Context) {
 		topic.Author = author
 	}
 
+	if h.viewCounter != nil {
+		h.viewCounter.RecordView(topic.ID, service.ViewerKey(viewerID(c), c.ClientIP(), c.Request.UserAgent()))
+	}
+	// Открытая тема считается прочитанной подписчиком
+	if userID := viewerID(c); h.subscriptionService != nil && userID != 0 {
+		if err := h.subscriptionService.MarkTopicRead(c.Request.Context(), userID, topic.ID); err != nil {
+			log.Printf("Error marking topic %d read for user %d: %v", topic.ID, userID, err)
+		}
+	}
+
 	c.JSON(http.StatusOK, topic)
 }
 
 // @Summary Get all topics
-// @Description Get a list of all topics with pagination
+// @Description Get a page of topics. Pages are chained with the next_cursor value from the previous response
 // @Tags topics
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(10)
-// @Success 200 {array} TopicResponse
+// @Param sort query string false "Sort order" Enums(newest, views, comments, activity, hot, top) default(newest)
+// @Param period query string false "Only with sort=top: rank topics created within this period by all their points" Enums(week, month, all) default(week)
+// @Param category_id query int false "Only topics from this category"
+// @Param tag query string false "Only topics with this tag"
+// @Param limit query int false "Items per page" default(20) maximum(100)
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} TopicListResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /topics [get]
 func (h *TopicHandler) GetAllTopics(c *gin.Context) {
-	log.Printf("Getting all topics")
-	topics, err := h.topicUseCase.GetAllTopics(c.Request.Context())
+	filter := entity.TopicFilter{
+		Sort:   entity.TopicSort(c.Query("sort")),
+		Period: entity.TopicPeriod(c.Query("period")),
+		Cursor: c.Query("cursor"),
+	}
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		id, err := strconv.ParseInt(categoryID, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+			return
+		}
+		filter.CategoryID = id
+	}
+	if tag := c.Query("tag"); tag != "" {
+		name, err := service.NormalizeTagName(tag)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag"})
+			return
+		}
+		filter.Tag = name
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	log.Printf("Getting topics: sort=%q period=%q category_id=%d tag=%q limit=%d", filter.Sort, filter.Period, filter.CategoryID, filter.Tag, filter.Limit)
+	page, err := h.topicUseCase.GetAllTopics(c.Request.Context(), filter)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidSort) || errors.Is(err, repository.ErrInvalidPeriod) || errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		log.Printf("Error getting topics: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get topics: %v", err)})
 		return
 	}
-	log.Printf("Successfully retrieved %d topics", len(topics))
+	log.Printf("Successfully retrieved %d topics", len(page.Topics))
 
 	// Get author information for topics that don't have it
-	for i, topic := range topics {
+	for i, topic := range page.Topics {
 		if topic.AuthorID > 0 && (topic.Author == nil || topic.Author.Username == "") {
 			log.Printf("Getting author information for topic %d (author_id: %d)", topic.ID, topic.AuthorID)
 			author, err := h.userRepo.GetUserByID(c.Request.Context(), topic.AuthorID)
@@ -189,16 +300,16 @@ func (h *TopicHandler) GetAllTopics(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get author for topic %d: %v", topic.ID, err)})
 				return
 			}
-			topics[i].Author = author
+			page.Topics[i].Author = author
 			log.Printf("Successfully retrieved author for topic %d: %+v", topic.ID, author)
 		}
 	}
 
-	c.JSON(http.StatusOK, topics)
+	c.JSON(http.StatusOK, page)
 }
 
 // @Summary Update a topic
-// @Description Update an existing topic's information
+// @Description Update an existing topic's information. Only the author, moderators and admins may do this
 // @Tags topics
 // @Accept json
 // @Produce json
@@ -226,9 +337,15 @@ func (h *TopicHandler) UpdateTopic(c *gin.Context) {
 	}
 	topic.ID = topicID
 
-	err = h.topicUseCase.UpdateTopic(c.Request.Context(), &topic)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	err = h.topicUseCase.UpdateTopic(c.Request.Context(), &topic, userID.(int64))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.handleError(c, err)
 		return
 	}
 
@@ -236,7 +353,7 @@ func (h *TopicHandler) UpdateTopic(c *gin.Context) {
 }
 
 // @Summary Delete a topic
-// @Description Delete a topic by ID
+// @Description Delete a topic by ID. Only the author, moderators and admins may do this. Moderators can restore it until it is purged
 // @Tags topics
 // @Accept json
 // @Produce json
@@ -256,15 +373,339 @@ func (h *TopicHandler) DeleteTopic(c *gin.Context) {
 		return
 	}
 
-	err = h.topicUseCase.DeleteTopic(c.Request.Context(), topicID)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	err = h.topicUseCase.DeleteTopic(c.Request.Context(), topicID, userID.(int64))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Restore a topic
+// @Description Bring back a deleted topic with its comments. Moderators only
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/restore [post]
+func (h *TopicHandler) RestoreTopic(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	if err := h.topicUseCase.RestoreTopic(c.Request.Context(), topicID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Topic restored"})
+}
+
+// @Summary Pin a topic
+// @Description Keep a topic on top of its category listing, or of every listing it appears in. Moderators only
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param pin body PinTopicRequest true "Pin scope and expiry"
+// @Success 200 {object} TopicResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/pin [put]
+func (h *TopicHandler) PinTopic(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	var req PinTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	topic, err := h.topicUseCase.PinTopic(c.Request.Context(), topicID, entity.PinScope(req.Scope), req.Until)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
+// @Summary Unpin a topic
+// @Description Let a pinned topic take its regular place in listings. Moderators only
+// @Tags topics
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Success 200 {object} TopicResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/pin [delete]
+func (h *TopicHandler) UnpinTopic(c *gin.Context) {
+	h.clearFlag(c, h.topicUseCase.UnpinTopic)
+}
+
+// @Summary Lock a topic
+// @Description Stop new comments in a topic. The author is notified. Moderators only
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param lock body TopicFlagRequest false "Expiry"
+// @Success 200 {object} TopicResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/lock [put]
+func (h *TopicHandler) LockTopic(c *gin.Context) {
+	topicID, req, ok := h.bindFlagRequest(c)
+	if !ok {
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	topic, err := h.topicUseCase.LockTopic(c.Request.Context(), topicID, userID.(int64), req.Until)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
+// @Summary Unlock a topic
+// @Description Allow new comments in a locked topic again. Moderators only
+// @Tags topics
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Success 200 {object} TopicResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/lock [delete]
+func (h *TopicHandler) UnlockTopic(c *gin.Context) {
+	h.clearFlag(c, h.topicUseCase.UnlockTopic)
+}
+
+// @Summary Make a topic an announcement
+// @Description Mark a topic as an announcement, which comes before pinned topics in every listing it appears in. Moderators only
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param announcement body TopicFlagRequest false "Expiry"
+// @Success 200 {object} TopicResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/announcement [put]
+func (h *TopicHandler) SetAnnouncement(c *gin.Context) {
+	topicID, req, ok := h.bindFlagRequest(c)
+	if !ok {
+		return
+	}
+
+	topic, err := h.topicUseCase.SetAnnouncement(c.Request.Context(), topicID, req.Until)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
+// @Summary Remove the announcement mark from a topic
+// @Description Turn an announcement back into a regular topic. Moderators only
+// @Tags topics
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Success 200 {object} TopicResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/announcement [delete]
+func (h *TopicHandler) ClearAnnouncement(c *gin.Context) {
+	h.clearFlag(c, h.topicUseCase.ClearAnnouncement)
+}
+
+// @Summary List my scheduled topics
+// @Description Get the current user's topics waiting to be published, the soonest first
+// @Tags topics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} TopicResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/scheduled [get]
+func (h *TopicHandler) ListScheduledTopics(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	topics, err := h.topicUseCase.ListScheduledTopics(c.Request.Context(), userID.(int64))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, topics)
+}
+
+// @Summary Reschedule a topic
+// @Description Move the publication of a scheduled topic. Only the author, moderators and admins may do this
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Param schedule body ScheduleTopicRequest true "New publish time"
+// @Success 200 {object} TopicResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/schedule [put]
+func (h *TopicHandler) RescheduleTopic(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	var req ScheduleTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	topic, err := h.topicUseCase.RescheduleTopic(c.Request.Context(), topicID, userID.(int64), req.PublishAt)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
+// @Summary Cancel a scheduled topic
+// @Description Withdraw a topic before it is published. The topic is deleted; moderators can restore it until it is purged. Only the author, moderators and admins may do this
+// @Tags topics
+// @Security BearerAuth
+// @Param id path int true "Topic ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /topics/{id}/schedule [delete]
+func (h *TopicHandler) CancelScheduledTopic(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.topicUseCase.CancelScheduledTopic(c.Request.Context(), topicID, userID.(int64)); err != nil {
+		h.handleError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// bindFlagRequest reads the topic ID and the optional body of a request
+// setting a lock or an announcement
+func (h *TopicHandler) bindFlagRequest(c *gin.Context) (int64, TopicFlagRequest, bool) {
+	var req TopicFlagRequest
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return 0, req, false
+	}
+	// Тело можно не передавать: флаг тогда бессрочный
+	if c.Request.ContentLength == 0 {
+		return topicID, req, true
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, req, false
+	}
+	return topicID, req, true
+}
+
+// clearFlag removes a moderation flag from the topic in the path
+func (h *TopicHandler) clearFlag(c *gin.Context, clear func(ctx context.Context, id int64) (*entity.Topic, error)) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	topic, err := clear(c.Request.Context(), topicID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
 func (h *TopicHandler) UpdateCommentCount(c *gin.Context) {
 	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -280,3 +721,30 @@ func (h *TopicHandler) UpdateCommentCount(c *gin.Context) {
 
 	c.Status(http.StatusOK)
 }
+
+func (h *TopicHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case isTopicValidationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrTopicNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error handling topic request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// isTopicValidationError reports whether a topic was rejected because of
+// invalid input rather than a server-side failure
+func isTopicValidationError(err error) bool {
+	return errors.Is(err, service.ErrUnknownCategory) ||
+		errors.Is(err, service.ErrInvalidTag) ||
+		errors.Is(err, service.ErrTooManyTags) ||
+		errors.Is(err, service.ErrInvalidPinScope) ||
+		errors.Is(err, service.ErrExpiryInPast) ||
+		errors.Is(err, service.ErrPublishAtInPast)
+}
@@ -5,30 +5,24 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 	"github.com/sout1235/forum2/backend/forum-service/internal/service"
-	"github.com/sout1235/forum2/backend/forum-service/internal/usecase"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func setupTestRouter() (*gin.Engine, *TopicHandler) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
 
-	topicRepo := &MockTopicRepository{}
-	commentRepo := &MockCommentRepository{}
-	userRepo := &MockUserRepository{}
-
-	topicRepoIface := repository.TopicRepository(topicRepo)
-	commentRepoIface := repository.CommentRepository(commentRepo)
-	userRepoIface := repository.UserRepository(userRepo)
-
-	uc := usecase.NewTopicUseCase(topicRepoIface, commentRepoIface, userRepoIface)
-	var topicService service.TopicService = uc
-	h := NewTopicHandler(topicService, userRepoIface)
+	h := NewTopicHandler(new(MockTopicService), &MockUserRepository{})
 
 	return r, h
 }
@@ -124,20 +118,88 @@ func TestTopicHandler_GetAllTopics(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var resp []map[string]interface{}
+	var resp struct {
+		Topics     []map[string]interface{} `json:"topics"`
+		NextCursor string                   `json:"next_cursor"`
+	}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Len(t, resp, 2)
+	assert.Len(t, resp.Topics, 2)
 }
 
-func TestTopicHandler_UpdateTopic(t *testing.T) {
+func TestTopicHandler_GetAllTopics_InvalidQuery(t *testing.T) {
 	r, h := setupTestRouter()
-	r.PUT("/topics/:id", h.UpdateTopic)
+	r.GET("/topics", h.GetAllTopics)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid category", query: "?category_id=abc"},
+		{name: "negative category", query: "?category_id=-1"},
+		{name: "invalid limit", query: "?limit=abc"},
+		{name: "zero limit", query: "?limit=0"},
+		{name: "invalid tag", query: "?tag=a%3Bb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/topics"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestTopicHandler_GetAllTopics_Filter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	topicService := new(MockTopicService)
+	h := NewTopicHandler(topicService, nil)
+	r.GET("/topics", h.GetAllTopics)
+
+	filter := entity.TopicFilter{Sort: entity.TopicSortViews, CategoryID: 2, Tag: "go-modules", Limit: 5, Cursor: "abc"}
+	page := &entity.TopicPage{
+		Topics:     []*entity.Topic{{ID: 1, Title: "Topic", Author: &entity.User{ID: 1, Username: "user"}}},
+		NextCursor: "next",
+	}
+	topicService.On("GetAllTopics", mock.Anything, filter).Return(page, nil)
+	topicService.On("GetAllTopics", mock.Anything, entity.TopicFilter{Sort: "bogus"}).Return((*entity.TopicPage)(nil), repository.ErrInvalidSort)
+	topicService.On("GetAllTopics", mock.Anything, entity.TopicFilter{Sort: entity.TopicSortTop, Period: "year"}).Return((*entity.TopicPage)(nil), repository.ErrInvalidPeriod)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/topics?sort=views&category_id=2&tag=Go+Modules&limit=5&cursor=abc", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp TopicListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Topics, 1)
+	assert.Equal(t, "next", resp.NextCursor)
 
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/topics?sort=bogus", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/topics?sort=top&period=year", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	topicService.AssertExpectations(t)
+}
+
+func TestTopicHandler_UpdateTopic(t *testing.T) {
 	tests := []struct {
 		name       string
 		topicID    string
 		body       map[string]interface{}
+		userID     int64
+		serviceErr error
 		wantStatus int
 	}{
 		{
@@ -147,28 +209,70 @@ func TestTopicHandler_UpdateTopic(t *testing.T) {
 				"title":   "Updated Topic",
 				"content": "Updated Content",
 			},
+			userID:     1,
 			wantStatus: http.StatusOK,
 		},
 		{
-			name:    "missing title",
+			name:    "not the author",
 			topicID: "1",
 			body: map[string]interface{}{
+				"title":   "Updated Topic",
 				"content": "Updated Content",
 			},
-			wantStatus: http.StatusInternalServerError,
+			userID:     2,
+			serviceErr: policy.ErrForbidden,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:    "not found",
+			topicID: "999",
+			body: map[string]interface{}{
+				"title":   "Updated Topic",
+				"content": "Updated Content",
+			},
+			userID:     1,
+			serviceErr: repository.ErrTopicNotFound,
+			wantStatus: http.StatusNotFound,
 		},
 		{
-			name:    "missing content",
+			name:    "unknown category",
 			topicID: "1",
 			body: map[string]interface{}{
-				"title": "Updated Topic",
+				"title":       "Updated Topic",
+				"content":     "Updated Content",
+				"category_id": 42,
 			},
-			wantStatus: http.StatusInternalServerError,
+			userID:     1,
+			serviceErr: service.ErrUnknownCategory,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "not authenticated",
+			topicID: "1",
+			body: map[string]interface{}{
+				"title":   "Updated Topic",
+				"content": "Updated Content",
+			},
+			wantStatus: http.StatusUnauthorized,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			topicService := new(MockTopicService)
+			h := NewTopicHandler(topicService, nil)
+			r.PUT("/topics/:id", func(c *gin.Context) {
+				if tt.userID > 0 {
+					c.Set("user_id", tt.userID)
+				}
+			}, h.UpdateTopic)
+
+			if tt.userID > 0 {
+				topicService.On("UpdateTopic", mock.Anything, mock.AnythingOfType("*entity.Topic"), tt.userID).Return(tt.serviceErr)
+			}
+
 			body, _ := json.Marshal(tt.body)
 			req, _ := http.NewRequest(http.MethodPut, "/topics/"+tt.topicID, bytes.NewBuffer(body))
 			req.Header.Set("Content-Type", "application/json")
@@ -177,39 +281,245 @@ func TestTopicHandler_UpdateTopic(t *testing.T) {
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.wantStatus, w.Code)
+			topicService.AssertExpectations(t)
 		})
 	}
 }
 
 func TestTopicHandler_DeleteTopic(t *testing.T) {
-	r, h := setupTestRouter()
-	r.DELETE("/topics/:id", h.DeleteTopic)
-
 	tests := []struct {
 		name       string
 		topicID    string
+		userID     int64
+		serviceErr error
 		wantStatus int
 	}{
 		{
 			name:       "success",
 			topicID:    "1",
+			userID:     1,
 			wantStatus: http.StatusNoContent,
 		},
+		{
+			name:       "not the author",
+			topicID:    "1",
+			userID:     2,
+			serviceErr: policy.ErrForbidden,
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name:       "not found",
 			topicID:    "999",
-			wantStatus: http.StatusNoContent,
+			userID:     1,
+			serviceErr: repository.ErrTopicNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "not authenticated",
+			topicID:    "1",
+			wantStatus: http.StatusUnauthorized,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			topicService := new(MockTopicService)
+			h := NewTopicHandler(topicService, nil)
+			r.DELETE("/topics/:id", func(c *gin.Context) {
+				if tt.userID > 0 {
+					c.Set("user_id", tt.userID)
+				}
+			}, h.DeleteTopic)
+
+			if tt.userID > 0 {
+				id, _ := strconv.ParseInt(tt.topicID, 10, 64)
+				topicService.On("DeleteTopic", mock.Anything, id, tt.userID).Return(tt.serviceErr)
+			}
+
 			req, _ := http.NewRequest(http.MethodDelete, "/topics/"+tt.topicID, nil)
 			w := httptest.NewRecorder()
 
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.wantStatus, w.Code)
+			topicService.AssertExpectations(t)
 		})
 	}
 }
+
+func TestTopicHandler_RestoreTopic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	topicService := new(MockTopicService)
+	h := NewTopicHandler(topicService, nil)
+	r.POST("/topics/:id/restore", h.RestoreTopic)
+
+	topicService.On("RestoreTopic", mock.Anything, int64(1)).Return(nil)
+	topicService.On("RestoreTopic", mock.Anything, int64(2)).Return(repository.ErrTopicNotFound)
+
+	tests := []struct {
+		topicID    string
+		wantStatus int
+	}{
+		{topicID: "1", wantStatus: http.StatusOK},
+		{topicID: "2", wantStatus: http.StatusNotFound},
+		{topicID: "abc", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, "/topics/"+tt.topicID+"/restore", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tt.wantStatus, w.Code, tt.topicID)
+	}
+	topicService.AssertExpectations(t)
+}
+
+func TestTopicHandler_PinTopic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	topicService := new(MockTopicService)
+	h := NewTopicHandler(topicService, nil)
+	r.PUT("/topics/:id/pin", h.PinTopic)
+	r.DELETE("/topics/:id/pin", h.UnpinTopic)
+
+	topicService.On("PinTopic", mock.Anything, int64(1), entity.PinScopeGlobal, (*time.Time)(nil)).
+		Return(&entity.Topic{ID: 1, Pinned: entity.PinScopeGlobal}, nil)
+	topicService.On("PinTopic", mock.Anything, int64(1), entity.PinScope("everywhere"), (*time.Time)(nil)).
+		Return(nil, service.ErrInvalidPinScope)
+	topicService.On("UnpinTopic", mock.Anything, int64(2)).Return(nil, repository.ErrTopicNotFound)
+
+	tests := []struct {
+		method     string
+		topicID    string
+		body       string
+		wantStatus int
+	}{
+		{method: http.MethodPut, topicID: "1", body: `{"scope":"global"}`, wantStatus: http.StatusOK},
+		{method: http.MethodPut, topicID: "1", body: `{"scope":"everywhere"}`, wantStatus: http.StatusBadRequest},
+		{method: http.MethodPut, topicID: "1", body: `{}`, wantStatus: http.StatusBadRequest},
+		{method: http.MethodDelete, topicID: "2", wantStatus: http.StatusNotFound},
+		{method: http.MethodDelete, topicID: "abc", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, "/topics/"+tt.topicID+"/pin", bytes.NewBufferString(tt.body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tt.wantStatus, w.Code, tt.method+" "+tt.body)
+	}
+	topicService.AssertExpectations(t)
+}
+
+func TestTopicHandler_LockTopic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	topicService := new(MockTopicService)
+	h := NewTopicHandler(topicService, nil)
+	r.PUT("/topics/:id/lock", func(c *gin.Context) {
+		c.Set("user_id", int64(5))
+		h.LockTopic(c)
+	})
+
+	until, _ := time.Parse(time.RFC3339, "2030-01-01T00:00:00Z")
+	topicService.On("LockTopic", mock.Anything, int64(1), int64(5), (*time.Time)(nil)).
+		Return(&entity.Topic{ID: 1, Locked: true}, nil)
+	topicService.On("LockTopic", mock.Anything, int64(1), int64(5), &until).
+		Return(&entity.Topic{ID: 1, Locked: true, LockedUntil: &until}, nil)
+
+	// Тело необязательно
+	req, _ := http.NewRequest(http.MethodPut, "/topics/1/lock", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodPut, "/topics/1/lock", bytes.NewBufferString(`{"until":"2030-01-01T00:00:00Z"}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var topic entity.Topic
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &topic))
+	assert.True(t, topic.Locked)
+	assert.Equal(t, until, *topic.LockedUntil)
+
+	req, _ = http.NewRequest(http.MethodPut, "/topics/1/lock", bytes.NewBufferString(`{"until":"tomorrow"}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	topicService.AssertExpectations(t)
+}
+
+func TestTopicHandler_GetTopic_Scheduled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	topicService := new(MockTopicService)
+	h := NewTopicHandler(topicService, nil)
+	r.GET("/topics/:id", func(c *gin.Context) {
+		if c.GetHeader("X-User") != "" {
+			c.Set("user_id", int64(5))
+		}
+		h.GetTopic(c)
+	})
+
+	publishAt, _ := time.Parse(time.RFC3339, "2030-01-01T09:00:00Z")
+	topicService.On("GetTopicByID", mock.Anything, int64(1)).Return((*entity.Topic)(nil), repository.ErrTopicNotFound)
+	topicService.On("GetScheduledTopic", mock.Anything, int64(1), int64(5)).
+		Return(&entity.Topic{ID: 1, AuthorID: 5, PublishAt: &publishAt}, nil)
+
+	// Автор видит свою запланированную тему
+	req, _ := http.NewRequest(http.MethodGet, "/topics/1", nil)
+	req.Header.Set("X-User", "5")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"publish_at":"2030-01-01T09:00:00Z"`)
+
+	// Гостям она не видна
+	req, _ = http.NewRequest(http.MethodGet, "/topics/1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	topicService.AssertNumberOfCalls(t, "GetScheduledTopic", 1)
+}
+
+func TestTopicHandler_Schedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	topicService := new(MockTopicService)
+	h := NewTopicHandler(topicService, nil)
+	r.Use(func(c *gin.Context) { c.Set("user_id", int64(5)) })
+	r.GET("/topics/scheduled", h.ListScheduledTopics)
+	r.GET("/topics/:id", h.GetTopic)
+	r.PUT("/topics/:id/schedule", h.RescheduleTopic)
+	r.DELETE("/topics/:id/schedule", h.CancelScheduledTopic)
+
+	publishAt, _ := time.Parse(time.RFC3339, "2030-01-01T09:00:00Z")
+	topicService.On("ListScheduledTopics", mock.Anything, int64(5)).
+		Return([]*entity.Topic{{ID: 1, AuthorID: 5, PublishAt: &publishAt}}, nil)
+	topicService.On("RescheduleTopic", mock.Anything, int64(1), int64(5), publishAt).
+		Return(&entity.Topic{ID: 1, AuthorID: 5, PublishAt: &publishAt}, nil)
+	topicService.On("RescheduleTopic", mock.Anything, int64(2), int64(5), publishAt).
+		Return(nil, repository.ErrTopicNotFound)
+	topicService.On("CancelScheduledTopic", mock.Anything, int64(1), int64(5)).Return(nil)
+
+	tests := []struct {
+		method     string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{method: http.MethodGet, url: "/topics/scheduled", wantStatus: http.StatusOK},
+		{method: http.MethodPut, url: "/topics/1/schedule", body: `{"publish_at":"2030-01-01T09:00:00Z"}`, wantStatus: http.StatusOK},
+		{method: http.MethodPut, url: "/topics/2/schedule", body: `{"publish_at":"2030-01-01T09:00:00Z"}`, wantStatus: http.StatusNotFound},
+		{method: http.MethodPut, url: "/topics/1/schedule", body: `{}`, wantStatus: http.StatusBadRequest},
+		{method: http.MethodDelete, url: "/topics/1/schedule", wantStatus: http.StatusNoContent},
+		{method: http.MethodDelete, url: "/topics/abc/schedule", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tt.wantStatus, w.Code, tt.method+" "+tt.url+" "+tt.body)
+	}
+	topicService.AssertExpectations(t)
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// MaxAttachmentsPerPost limits how many files one topic or comment carries
+const MaxAttachmentsPerPost = 10
+
+// MaxAttachmentFilenameLength limits the stored original file name
+const MaxAttachmentFilenameLength = 255
+
+// AttachmentPostType is the kind of post an attachment belongs to
+type AttachmentPostType string
+
+const (
+	AttachmentPostTopic   AttachmentPostType = "topic"
+	AttachmentPostComment AttachmentPostType = "comment"
+)
+
+func (t AttachmentPostType) Valid() bool {
+	return t == AttachmentPostTopic || t == AttachmentPostComment
+}
+
+// Attachment is an uploaded file. Until it is linked to a post it is seen
+// only by the uploader and is removed after a while.
+type Attachment struct {
+	ID           int64              `json:"id"`
+	UploaderID   int64              `json:"uploader_id"`
+	PostType     AttachmentPostType `json:"post_type,omitempty"`
+	PostID       int64              `json:"post_id,omitempty"`
+	Filename     string             `json:"filename"`
+	ContentType  string             `json:"content_type"` // detected from the contents
+	Size         int64              `json:"size"`
+	StorageKey   string             `json:"-"`
+	ThumbnailKey string             `json:"-"` // empty if there is no preview
+	CreatedAt    time.Time          `json:"created_at"`
+
+	// Signed links, valid for a limited time
+	URL          string     `json:"url,omitempty"`
+	ThumbnailURL string     `json:"thumbnail_url,omitempty"`
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+}
+
+// IsLinked reports whether the attachment belongs to a post
+func (a *Attachment) IsLinked() bool {
+	return a.PostType != "" && a.PostID > 0
+}
@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+type BookmarkTargetType string
+
+const (
+	BookmarkTargetTopic   BookmarkTargetType = "topic"
+	BookmarkTargetComment BookmarkTargetType = "comment"
+)
+
+func (t BookmarkTargetType) Valid() bool {
+	return t == BookmarkTargetTopic || t == BookmarkTargetComment
+}
+
+const (
+	MaxBookmarkNoteLength   = 1000
+	BookmarkExcerptLength   = 200
+	DefaultBookmarkPageSize = 20
+	MaxBookmarkPageSize     = 100
+)
+
+// Bookmark is a topic or comment a user saved for later. The bookmark
+// outlives its target: once the target is deleted or hidden it stays in the
+// list with Available set to false and without the target's content.
+type Bookmark struct {
+	ID         int64              `json:"id"`
+	UserID     int64              `json:"user_id"`
+	TargetType BookmarkTargetType `json:"target_type"`
+	TargetID   int64              `json:"target_id"`
+	TopicID    int64              `json:"topic_id,omitempty"`
+	Title      string             `json:"title,omitempty"`   // title of the topic the target belongs to
+	Excerpt    string             `json:"excerpt,omitempty"` // beginning of a bookmarked comment
+	Available  bool               `json:"available"`
+	Note       string             `json:"note,omitempty"`
+	RemindAt   *time.Time         `json:"remind_at,omitempty"`
+	RemindedAt *time.Time         `json:"reminded_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+type BookmarkFilter struct {
+	UserID int64
+	Cursor string
+	Limit  int
+}
+
+// BookmarkPage is a page of bookmarks, newest first
+type BookmarkPage struct {
+	Bookmarks  []*Bookmark `json:"bookmarks"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+type Category struct {
+	ID             int64             `json:"id" db:"id"`
+	Name           string            `json:"name" db:"name"`
+	Description    string            `json:"description" db:"description"`
+	Position       int               `json:"position" db:"position"`
+	TopicCount     int               `json:"topic_count" db:"-"`
+	LatestActivity *CategoryActivity `json:"latest_activity,omitempty" db:"-"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// CategoryActivity points at the most recently active topic of a category
+type CategoryActivity struct {
+	TopicID    int64     `json:"topic_id"`
+	TopicTitle string    `json:"topic_title"`
+	At         time.Time `json:"at"`
+}
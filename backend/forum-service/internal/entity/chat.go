@@ -6,8 +6,11 @@ import "time"
 type ChatMessage struct {
 	ID             int64
 	Content        string
+	ContentHTML    string
 	AuthorID       int64
 	AuthorUsername string
+	AuthorAvatar   string
 	CreatedAt      time.Time
 	ExpiresAt      time.Time
+	MentionedIDs   []int64 // users mentioned in the message
 }
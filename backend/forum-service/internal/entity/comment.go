@@ -3,13 +3,91 @@ package entity
 import "time"
 
 type Comment struct {
-	ID        int64     `json:"id" db:"id"`
-	Content   string    `json:"content" db:"content"`
-	AuthorID  int64     `json:"author_id" db:"author_id"`
-	TopicID   int64     `json:"topic_id" db:"topic_id"`
-	ParentID  *int64    `json:"parent_id,omitempty" db:"parent_id"`
-	Likes     int       `json:"likes" db:"likes"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	Author    *User     `json:"author" db:"-"`
+	ID          int64      `json:"id" db:"id"`
+	Content     string     `json:"content" db:"content"`
+	ContentHTML string     `json:"content_html" db:"content_html"`
+	AuthorID    int64      `json:"author_id" db:"author_id"`
+	TopicID     int64      `json:"topic_id" db:"topic_id"`
+	ParentID    *int64     `json:"parent_id,omitempty" db:"parent_id"`
+	Likes       int        `json:"likes" db:"likes"`
+	LikedByMe   bool       `json:"liked_by_me" db:"-"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	Edited      bool       `json:"edited" db:"-"`
+	EditedAt    *time.Time `json:"edited_at,omitempty" db:"edited_at"`
+	Author      *User      `json:"author" db:"-"`
+	Deleted     bool       `json:"deleted,omitempty" db:"-"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Пользователи, впервые упомянутые при последнем сохранении
+	MentionedIDs []int64 `json:"-" db:"-"`
+
+	// Заполняются только при выборке дерева комментариев
+	Depth         int        `json:"depth,omitempty" db:"-"`
+	ReplyCount    int        `json:"reply_count,omitempty" db:"-"`
+	Replies       []*Comment `json:"replies,omitempty" db:"-"`
+	RepliesCursor string     `json:"replies_cursor,omitempty" db:"-"`
+}
+
+// DeletedCommentPlaceholder replaces the text of a deleted comment that is
+// kept in a thread so its replies stay in place
+const DeletedCommentPlaceholder = "[deleted]"
+
+// MaskDeleted turns a soft-deleted comment into a placeholder without its
+// text or author
+func (c *Comment) MaskDeleted() {
+	c.Deleted = c.DeletedAt != nil
+	if !c.Deleted {
+		return
+	}
+	c.Content = DeletedCommentPlaceholder
+	c.ContentHTML = DeletedCommentPlaceholder
+	c.AuthorID = 0
+	c.Author = nil
+	c.Likes = 0
+	c.LikedByMe = false
+}
+
+const (
+	DefaultCommentTreeDepth = 3
+	MaxCommentTreeDepth     = 10
+	DefaultRepliesPerNode   = 20
+	MaxRepliesPerNode       = 100
+)
+
+// CommentTreeQuery describes which part of a topic's comment tree to return:
+// the replies to ParentID (top-level comments when nil) and their own replies
+// down to Depth levels, at most Limit replies per comment.
+type CommentTreeQuery struct {
+	TopicID  int64
+	ParentID *int64
+	Depth    int
+	Limit    int
+	Cursor   string
+	ViewerID int64 // 0 for anonymous requests
+}
+
+// CommentTree is a page of top-level nodes of a comment tree. Replies that
+// didn't fit are continued with the replies_cursor of their parent.
+type CommentTree struct {
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+const (
+	DefaultCommentLikesPageSize = 20
+	MaxCommentLikesPageSize     = 100
+)
+
+// CommentLike is a user who liked a comment
+type CommentLike struct {
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Avatar    string    `json:"avatar"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentLikePage is a page of users who liked a comment, newest first
+type CommentLikePage struct {
+	Likes      []*CommentLike `json:"likes"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
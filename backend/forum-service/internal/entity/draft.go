@@ -0,0 +1,68 @@
+package entity
+
+import "time"
+
+// DraftContext is what a draft is going to become once published
+type DraftContext string
+
+const (
+	DraftContextTopic        DraftContext = "topic"         // a new topic
+	DraftContextTopicReply   DraftContext = "topic_reply"   // a comment on topic TargetID
+	DraftContextCommentReply DraftContext = "comment_reply" // a reply to comment TargetID
+)
+
+func (c DraftContext) Valid() bool {
+	switch c {
+	case DraftContextTopic, DraftContextTopicReply, DraftContextCommentReply:
+		return true
+	}
+	return false
+}
+
+const (
+	MaxDraftTitleLength   = 255
+	MaxDraftContentLength = 100000
+	DefaultDraftPageSize  = 20
+	MaxDraftPageSize      = 100
+)
+
+// Draft is unpublished text a user is writing. A user has at most one draft
+// per context and target; every save bumps Version, and a save based on an
+// older version is rejected so that two open tabs don't overwrite each other.
+type Draft struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Context    DraftContext `json:"context"`
+	TargetID   int64        `json:"target_id,omitempty"` // topic or comment replied to
+	Title      string       `json:"title,omitempty"`
+	Content    string       `json:"content"`
+	CategoryID int64        `json:"category_id,omitempty"`
+	TagNames   []string     `json:"tag_names"`
+	Version    int          `json:"version"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+}
+
+// DraftFilter selects a page of a user's drafts. A set Context narrows
+// the list to the draft for that context and TargetID.
+type DraftFilter struct {
+	UserID   int64
+	Context  DraftContext
+	TargetID int64
+	Cursor   string
+	Limit    int
+}
+
+// DraftPage is a page of drafts, most recently saved first
+type DraftPage struct {
+	Drafts     []*Draft `json:"drafts"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// PublishedDraft is what a draft became once published: a topic for a
+// DraftContextTopic draft, a comment otherwise
+type PublishedDraft struct {
+	Topic   *Topic   `json:"topic,omitempty"`
+	Comment *Comment `json:"comment,omitempty"`
+}
@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+type MentionTargetType string
+
+const (
+	MentionTargetTopic       MentionTargetType = "topic"
+	MentionTargetComment     MentionTargetType = "comment"
+	MentionTargetChatMessage MentionTargetType = "chat_message"
+)
+
+// MaxMentionsPerPost caps how many users one topic, comment or chat message
+// can mention. Mentions past the cap are left as plain text.
+const MaxMentionsPerPost = 10
+
+const (
+	DefaultMentionSuggestions = 10
+	MaxMentionSuggestions     = 25
+)
+
+const (
+	DefaultMentionPageSize = 20
+	MaxMentionPageSize     = 100
+)
+
+// Mention records that a post mentioned a user as @username
+type Mention struct {
+	ID             int64             `json:"id"`
+	TargetType     MentionTargetType `json:"target_type"`
+	TargetID       int64             `json:"target_id"`
+	TopicID        int64             `json:"topic_id,omitempty"` // the topic a mention in a comment belongs to
+	UserID         int64             `json:"user_id"`
+	AuthorID       int64             `json:"author_id,omitempty"`
+	AuthorUsername string            `json:"author_username,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// MentionPage is a page of the mentions of a user, newest first
+type MentionPage struct {
+	Mentions   []*Mention `json:"mentions"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,92 @@
+package entity
+
+import "time"
+
+type NotificationType string
+
+const (
+	NotificationTopicReply   NotificationType = "topic_reply"   // a comment in a topic the user watches
+	NotificationCommentReply NotificationType = "comment_reply" // a reply to the user's comment
+	NotificationMention      NotificationType = "mention"
+	NotificationLike         NotificationType = "like"
+	NotificationModeration   NotificationType = "moderation" // a moderator acted on the user's content
+	NotificationReminder     NotificationType = "reminder"   // a bookmark reminder came due
+)
+
+// Aggregated reports whether repeated events of this type on the same target
+// are folded into one unread notification, as in "5 people liked your comment"
+func (t NotificationType) Aggregated() bool {
+	switch t {
+	case NotificationTopicReply, NotificationCommentReply, NotificationLike:
+		return true
+	}
+	return false
+}
+
+type NotificationTargetType string
+
+const (
+	NotificationTargetTopic       NotificationTargetType = "topic"
+	NotificationTargetComment     NotificationTargetType = "comment"
+	NotificationTargetChatMessage NotificationTargetType = "chat_message"
+)
+
+// Moderation actions a user is notified of
+const (
+	ModerationHide     = "hide"
+	ModerationDelete   = "delete"
+	ModerationWarn     = "warn"
+	ModerationRollback = "rollback"
+	ModerationLock     = "lock"
+)
+
+const (
+	DefaultNotificationPageSize = 20
+	MaxNotificationPageSize     = 100
+)
+
+// NotificationTarget is the content a notification is about
+type NotificationTarget struct {
+	TargetType NotificationTargetType `json:"target_type"`
+	TargetID   int64                  `json:"target_id"`
+	TopicID    int64                  `json:"topic_id,omitempty"` // the topic the target belongs to
+}
+
+// Notification tells a user about something that happened to their content.
+// Aggregated notifications collect every user who caused the event while
+// the notification is unread.
+type Notification struct {
+	ID     int64            `json:"id"`
+	UserID int64            `json:"user_id"`
+	Type   NotificationType `json:"type"`
+	NotificationTarget
+	ActorID       int64     `json:"actor_id,omitempty"` // the latest user who caused the event
+	ActorUsername string    `json:"actor_username,omitempty"`
+	ActorCount    int       `json:"actor_count"`
+	Action        string    `json:"action,omitempty"` // moderation notifications only
+	Note          string    `json:"note,omitempty"`
+	Read          bool      `json:"read"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type NotificationFilter struct {
+	UserID     int64
+	UnreadOnly bool
+	Cursor     string
+	Limit      int
+}
+
+// NotificationPage is a page of notifications, most recently updated first
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	Unread        int             `json:"unread"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+}
+
+// NotificationEvent is pushed to a user's open connections when a
+// notification arrives or their unread count changes
+type NotificationEvent struct {
+	Notification *Notification `json:"notification,omitempty"`
+	Unread       int           `json:"unread"`
+}
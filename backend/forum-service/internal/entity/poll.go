@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+const (
+	MaxPollQuestionLength = 300
+	MaxPollOptionLength   = 200
+	MinPollOptions        = 2
+	MaxPollOptions        = 20
+)
+
+// Poll is a vote attached to a topic. Votes of anonymous polls are still
+// recorded per user, so a vote can be changed, but voters are never shown.
+type Poll struct {
+	ID          int64         `json:"id"`
+	TopicID     int64         `json:"topic_id"`
+	Question    string        `json:"question"`
+	Multiple    bool          `json:"multiple"`     // more than one option may be chosen
+	Anonymous   bool          `json:"anonymous"`    // voters are not listed
+	HideResults bool          `json:"hide_results"` // results are shown only to voters until the poll closes
+	ClosesAt    *time.Time    `json:"closes_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Options     []*PollOption `json:"options"`
+
+	// Filled in for the user viewing the poll
+	Closed         bool    `json:"closed"`
+	ResultsVisible bool    `json:"results_visible"`
+	TotalVoters    int     `json:"total_voters"` // zero while results are hidden
+	MyVotes        []int64 `json:"my_votes"`     // option IDs the viewer voted for
+}
+
+// PollOption is one of the answers of a poll
+type PollOption struct {
+	ID       int64   `json:"id"`
+	Text     string  `json:"text"`
+	Position int     `json:"position"`
+	Votes    int     `json:"votes"`            // zero while results are hidden
+	Voters   []*User `json:"voters,omitempty"` // only for public polls with visible results
+}
+
+// IsClosed reports whether voting in the poll has ended at the given time
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosesAt != nil && !now.Before(*p.ClosesAt)
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+const (
+	MaxProfileBioLength       = 1000
+	MaxProfileSignatureLength = 300
+	MaxProfileLocationLength  = 100
+	DefaultActivityPageSize   = 20
+	MaxActivityPageSize       = 100
+)
+
+// UserProfile is the public page of a user. Post counts include only
+// posts that can be seen by everyone.
+type UserProfile struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	Avatar       string     `json:"avatar"`
+	Role         string     `json:"role"`
+	Bio          string     `json:"bio"`
+	Signature    string     `json:"signature"`
+	Location     string     `json:"location"`
+	JoinedAt     *time.Time `json:"joined_at,omitempty"`
+	TopicCount   int        `json:"topic_count"`
+	CommentCount int        `json:"comment_count"`
+}
+
+// ProfileUpdate holds the fields a user changes on their profile; nil
+// fields are kept
+type ProfileUpdate struct {
+	Bio       *string
+	Signature *string
+	Location  *string
+}
+
+// ActivityFilter describes which page of a user's posts to return
+type ActivityFilter struct {
+	UserID int64
+	Cursor string
+	Limit  int
+}
+
+// UserComment is a comment in the activity of its author, with the title
+// of the topic it was posted in
+type UserComment struct {
+	ID          int64      `json:"id"`
+	TopicID     int64      `json:"topic_id"`
+	TopicTitle  string     `json:"topic_title"`
+	ParentID    *int64     `json:"parent_id,omitempty"`
+	Content     string     `json:"content"`
+	ContentHTML string     `json:"content_html"`
+	Likes       int        `json:"likes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+}
+
+// UserCommentPage is a page of the comments of a user, newest first
+type UserCommentPage struct {
+	Comments   []*UserComment `json:"comments"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
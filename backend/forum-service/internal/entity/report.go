@@ -0,0 +1,89 @@
+package entity
+
+import "time"
+
+type ReportTargetType string
+
+const (
+	ReportTargetTopic       ReportTargetType = "topic"
+	ReportTargetComment     ReportTargetType = "comment"
+	ReportTargetChatMessage ReportTargetType = "chat_message"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonSpam     ReportReason = "spam"
+	ReportReasonAbuse    ReportReason = "abuse"
+	ReportReasonOffTopic ReportReason = "off_topic"
+	ReportReasonOther    ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusClaimed   ReportStatus = "claimed"
+	ReportStatusResolved  ReportStatus = "resolved"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+// ReportAction is what a moderator did about the reported content
+type ReportAction string
+
+const (
+	ReportActionHide   ReportAction = "hide"
+	ReportActionDelete ReportAction = "delete"
+	ReportActionWarn   ReportAction = "warn"
+)
+
+const (
+	DefaultReportPageSize = 20
+	MaxReportPageSize     = 100
+	MaxReportDetails      = 1000
+)
+
+type Report struct {
+	ID              int64            `json:"id"`
+	TargetType      ReportTargetType `json:"target_type"`
+	TargetID        int64            `json:"target_id"`
+	TargetAuthorID  *int64           `json:"target_author_id,omitempty"`
+	ContentSnapshot string           `json:"content_snapshot"`
+	ReporterID      int64            `json:"reporter_id"`
+	Reason          ReportReason     `json:"reason"`
+	Details         string           `json:"details"`
+	Status          ReportStatus     `json:"status"`
+	ClaimedBy       *int64           `json:"claimed_by,omitempty"`
+	ClaimedAt       *time.Time       `json:"claimed_at,omitempty"`
+	ResolvedBy      *int64           `json:"resolved_by,omitempty"`
+	ResolvedAt      *time.Time       `json:"resolved_at,omitempty"`
+	Action          *ReportAction    `json:"action,omitempty"`
+	Note            string           `json:"note"`
+	CreatedAt       time.Time        `json:"created_at"`
+
+	// Число открытых жалоб на тот же контент, заполняется в очереди модерации
+	TargetReports int `json:"target_reports,omitempty"`
+}
+
+// ReportTarget is the reported content as it is when the report is filed
+type ReportTarget struct {
+	Type     ReportTargetType
+	ID       int64
+	AuthorID *int64
+	Content  string
+}
+
+// ReportFilter describes which page of the moderation queue to return.
+// An empty Status lists reports that still need a decision.
+type ReportFilter struct {
+	Status     ReportStatus
+	TargetType ReportTargetType
+	Cursor     string
+	Limit      int
+}
+
+// ReportPage is a page of the moderation queue, oldest first
+type ReportPage struct {
+	Reports    []*Report `json:"reports"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+type RevisionTargetType string
+
+const (
+	RevisionTargetTopic   RevisionTargetType = "topic"
+	RevisionTargetComment RevisionTargetType = "comment"
+)
+
+// Revision is a topic or comment as it was before an edit. Revisions of the
+// same content are numbered from 1; EditorID is the user whose edit replaced
+// this version.
+type Revision struct {
+	ID         int64              `json:"id"`
+	TargetType RevisionTargetType `json:"target_type"`
+	TargetID   int64              `json:"target_id"`
+	Revision   int                `json:"revision"`
+	EditorID   *int64             `json:"editor_id,omitempty"`
+	Title      string             `json:"title,omitempty"` // only topics have a title
+	Content    string             `json:"content"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// RevisionDiff is a unified diff between two versions of a topic or comment.
+// To is omitted when the diff is against the current version.
+type RevisionDiff struct {
+	TargetType RevisionTargetType `json:"target_type"`
+	TargetID   int64              `json:"target_id"`
+	From       int                `json:"from"`
+	To         int                `json:"to,omitempty"`
+	Diff       string             `json:"diff"`
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+type SearchResultType string
+
+const (
+	SearchResultTopic   SearchResultType = "topic"
+	SearchResultComment SearchResultType = "comment"
+)
+
+const (
+	DefaultSearchPageSize = 20
+	MaxSearchPageSize     = 50
+)
+
+// SearchQuery describes a full-text search request. Zero values of the
+// filter fields mean "no filter".
+type SearchQuery struct {
+	Query      string
+	CategoryID int64
+	AuthorID   int64
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// SearchResult is a single topic or comment matching a search query.
+// Snippet is HTML: the matched fragment escaped, with hits wrapped in <mark>.
+type SearchResult struct {
+	Type       SearchResultType `json:"type"`
+	ID         int64            `json:"id"`
+	TopicID    int64            `json:"topic_id"`
+	TopicTitle string           `json:"topic_title"`
+	CategoryID int64            `json:"category_id"`
+	AuthorID   int64            `json:"author_id"`
+	Author     *User            `json:"author,omitempty"`
+	Snippet    string           `json:"snippet"`
+	Rank       float64          `json:"rank"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+type SearchPage struct {
+	Results    []*SearchResult `json:"results"`
+	NextOffset int             `json:"next_offset,omitempty"`
+}
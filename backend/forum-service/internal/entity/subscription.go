@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// WatchLevel is how closely a user follows a topic
+type WatchLevel string
+
+const (
+	WatchLevelWatching WatchLevel = "watching" // notified of every new comment
+	WatchLevelTracking WatchLevel = "tracking" // unread comments are counted, no notifications
+	WatchLevelMuted    WatchLevel = "muted"    // no notifications from the topic except mentions
+)
+
+func (l WatchLevel) Valid() bool {
+	switch l {
+	case WatchLevelWatching, WatchLevelTracking, WatchLevelMuted:
+		return true
+	}
+	return false
+}
+
+const (
+	DefaultWatchedTopicsPageSize = 20
+	MaxWatchedTopicsPageSize     = 100
+)
+
+// TopicSubscription is a user's watch level on a topic. Authors start
+// watching their topics and commenters start tracking them unless they
+// turned automatic subscriptions off.
+type TopicSubscription struct {
+	UserID    int64      `json:"user_id"`
+	TopicID   int64      `json:"topic_id"`
+	Level     WatchLevel `json:"level"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// WatchedTopic is a topic in the list of topics a user follows
+type WatchedTopic struct {
+	TopicID        int64      `json:"topic_id"`
+	Title          string     `json:"title"`
+	CommentCount   int        `json:"comment_count"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	Level          WatchLevel `json:"level"`
+	UnreadComments int        `json:"unread_comments"` // comments by others since the user last opened the topic
+}
+
+type WatchedTopicFilter struct {
+	UserID int64
+	Level  WatchLevel // watching and tracking topics when empty
+	Cursor string
+	Limit  int
+}
+
+// WatchedTopicPage is a page of followed topics, most recently active first
+type WatchedTopicPage struct {
+	Topics     []*WatchedTopic `json:"topics"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// WatchSettings are a user's subscription preferences
+type WatchSettings struct {
+	AutoWatch bool `json:"auto_watch"` // subscribe to topics the user creates or comments on
+}
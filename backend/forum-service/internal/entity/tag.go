@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// MaxTagsPerTopic limits how many tags a single topic can carry
+const MaxTagsPerTopic = 5
+
+type Tag struct {
+	ID         int64     `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	TopicCount int       `json:"topic_count,omitempty" db:"-"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
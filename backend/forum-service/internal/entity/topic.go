@@ -3,15 +3,97 @@ package entity
 import "time"
 
 type Topic struct {
-	ID           int64      `json:"id" db:"id"`
-	Title        string     `json:"title" db:"title"`
-	Content      string     `json:"content" db:"content"`
-	AuthorID     int64      `json:"author_id" db:"author_id"`
-	CategoryID   int64      `json:"category_id" db:"category_id"`
-	Views        int        `json:"views" db:"views"`
-	CommentCount int        `json:"comment_count" db:"comment_count"`
-	Comments     []*Comment `json:"comments,omitempty"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
-	Author       *User      `json:"author,omitempty"`
+	ID             int64      `json:"id" db:"id"`
+	Title          string     `json:"title" db:"title"`
+	Content        string     `json:"content" db:"content"`
+	ContentHTML    string     `json:"content_html" db:"content_html"` // rendered from Content on every save
+	AuthorID       int64      `json:"author_id" db:"author_id"`
+	CategoryID     int64      `json:"category_id" db:"category_id"`
+	Views          int        `json:"views" db:"views"`
+	CommentCount   int        `json:"comment_count" db:"comment_count"`
+	Comments       []*Comment `json:"comments,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	LastActivityAt time.Time  `json:"last_activity_at" db:"last_activity_at"`
+	Author         *User      `json:"author,omitempty"`
+	Category       *Category  `json:"category,omitempty"`
+	Tags           []*Tag     `json:"tags"`
+	TagNames       []string   `json:"tag_names,omitempty" db:"-"` // nil keeps the current tags on update
+	MentionedIDs   []int64    `json:"-" db:"-"`                   // users newly mentioned by the last save
+
+	// Moderation flags. Each one lapses on its own once its expiry passes;
+	// expired flags read as unset.
+	Pinned            PinScope   `json:"pinned,omitempty" db:"pin_scope"`
+	PinnedUntil       *time.Time `json:"pinned_until,omitempty" db:"pinned_until"`
+	Locked            bool       `json:"locked" db:"locked"` // no new comments
+	LockedUntil       *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	Announcement      bool       `json:"announcement" db:"announcement"`
+	AnnouncementUntil *time.Time `json:"announcement_until,omitempty" db:"announcement_until"`
+
+	// PublishAt is set while the topic is scheduled. Until then only its
+	// author and moderators see it.
+	PublishAt *time.Time `json:"publish_at,omitempty" db:"publish_at"`
+
+	// Ranking scores, kept up to date as comments, likes and views come in.
+	// HotScore decays with time, TopScore doesn't.
+	HotScore float64 `json:"-" db:"hot_score"`
+	TopScore float64 `json:"-" db:"top_score"`
+}
+
+// PinScope defines the listings a pinned topic is kept on top of
+type PinScope string
+
+const (
+	PinScopeCategory PinScope = "category" // the listing of the topic's category
+	PinScopeGlobal   PinScope = "global"   // every listing the topic appears in
+)
+
+func (s PinScope) Valid() bool {
+	switch s {
+	case PinScopeCategory, PinScopeGlobal:
+		return true
+	}
+	return false
+}
+
+// TopicSort defines the order of a topic listing
+type TopicSort string
+
+const (
+	TopicSortNewest   TopicSort = "newest"
+	TopicSortViews    TopicSort = "views"
+	TopicSortComments TopicSort = "comments"
+	TopicSortActivity TopicSort = "activity"
+	TopicSortHot      TopicSort = "hot" // recent activity, older activity counting less
+	TopicSortTop      TopicSort = "top" // all-time points of topics created within TopicFilter.Period
+)
+
+// TopicPeriod limits a top listing to topics created within it
+type TopicPeriod string
+
+const (
+	TopicPeriodWeek  TopicPeriod = "week"
+	TopicPeriodMonth TopicPeriod = "month"
+	TopicPeriodAll   TopicPeriod = "all"
+)
+
+const (
+	DefaultTopicPageSize = 20
+	MaxTopicPageSize     = 100
+)
+
+// TopicFilter describes which page of topics to return
+type TopicFilter struct {
+	CategoryID int64
+	Tag        string
+	Sort       TopicSort
+	Period     TopicPeriod // only for TopicSortTop; week if empty
+	Cursor     string
+	Limit      int
+}
+
+// TopicPage is a single page of a topic listing
+type TopicPage struct {
+	Topics     []*Topic `json:"topics"`
+	NextCursor string   `json:"next_cursor,omitempty"`
 }
@@ -1,7 +1,33 @@
 package entity
 
+import "fmt"
+
 type User struct {
 	ID       int64  `json:"id" db:"id"`
 	Username string `json:"username" db:"username"`
 	Avatar   string `json:"avatar" db:"avatar"`
 }
+
+// Роли пользователей; назначаются в auth-service и приходят вместе с
+// проверкой токена. Если auth-service роль не сообщил, берется users.role
+// базы форума, а пользователь без записи в users считается RoleUser
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// DefaultAvatarURL is where the avatar of a user is served. Users who have
+// not uploaded a picture get a generated one there.
+func DefaultAvatarURL(userID int64) string {
+	return fmt.Sprintf("/api/v1/users/%d/avatar", userID)
+}
+
+// AvatarURL returns the stored avatar of a user, or the generated one if
+// the user has none
+func AvatarURL(userID int64, avatar string) string {
+	if avatar != "" {
+		return avatar
+	}
+	return DefaultAvatarURL(userID)
+}
@@ -0,0 +1,106 @@
+// Package markup turns the Markdown source of posts and chat messages into
+// HTML that is safe to put on a page as is.
+package markup
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// Renderer converts the source text of a topic, comment or chat message
+// into sanitized HTML
+type Renderer interface {
+	// Render returns the HTML of source. Mentions of the given users become
+	// links to their profiles; other mentions are left as text.
+	Render(source string, mentioned []MentionedUser) (string, error)
+	// Mentions returns the usernames mentioned in source, each once, in the
+	// order they appear. Mentions in code and link texts are skipped.
+	Mentions(source string) []string
+}
+
+// HighlightStyle is the chroma style used for fenced code blocks
+const HighlightStyle = "github"
+
+var mentionClass = regexp.MustCompile(`^mention$`)
+
+type markdownRenderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+// NewPostRenderer returns the renderer for topics and comments: CommonMark
+// with GFM tables, strikethrough, task lists and autolinks, and fenced code
+// highlighted on the server with inline styles.
+func NewPostRenderer() Renderer {
+	markdown := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			&mentionExtension{},
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(HighlightStyle),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(false)),
+			),
+		),
+	)
+
+	policy := bluemonday.UGCPolicy()
+	// Подсветка кода задает цвета через атрибут style
+	policy.AllowStyles("color", "background-color", "font-weight", "font-style", "text-decoration").
+		OnElements("pre", "span")
+	// Списки задач GFM
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	policy.AllowAttrs("class").Matching(mentionClass).OnElements("a")
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+
+	return &markdownRenderer{markdown: markdown, policy: policy}
+}
+
+// NewChatRenderer returns the renderer for chat messages. It keeps only
+// inline formatting: emphasis, strikethrough, code spans and links. Block
+// elements such as headings, lists, tables and images are reduced to their
+// text.
+func NewChatRenderer() Renderer {
+	markdown := goldmark.New(
+		goldmark.WithExtensions(extension.Strikethrough, extension.Linkify, &mentionExtension{}),
+	)
+
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("strong", "em", "del", "code", "br")
+	policy.AllowStandardURLs()
+	policy.AllowRelativeURLs(true)
+	policy.AllowAttrs("href").OnElements("a")
+	policy.AllowAttrs("class").Matching(mentionClass).OnElements("a")
+	policy.RequireNoFollowOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+
+	return &markdownRenderer{markdown: markdown, policy: policy}
+}
+
+func (r *markdownRenderer) Render(source string, mentioned []MentionedUser) (string, error) {
+	resolved := make(map[string]*MentionedUser, len(mentioned))
+	for i := range mentioned {
+		resolved[strings.ToLower(mentioned[i].Username)] = &mentioned[i]
+	}
+	pc := parser.NewContext()
+	pc.Set(mentionedKey, resolved)
+
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(source), &buf, parser.WithContext(pc)); err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+	return string(bytes.TrimSpace(r.policy.SanitizeBytes(buf.Bytes()))), nil
+}
+
+func (r *markdownRenderer) Mentions(source string) []string {
+	return findMentions(r.markdown, source)
+}
@@ -0,0 +1,87 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostRenderer(t *testing.T) {
+	r := NewPostRenderer()
+
+	tests := []struct {
+		name     string
+		source   string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "emphasis",
+			source:   "Hello **world**",
+			contains: []string{"<p>Hello <strong>world</strong></p>"},
+		},
+		{
+			name:     "table",
+			source:   "| a | b |\n|---|---|\n| 1 | 2 |",
+			contains: []string{"<table>", "<th>a</th>", "<td>2</td>"},
+		},
+		{
+			name:     "autolink",
+			source:   "see https://go.dev",
+			contains: []string{`<a href="https://go.dev"`, `rel="nofollow noopener"`, `target="_blank"`},
+		},
+		{
+			name:     "highlighted code",
+			source:   "```go\nfunc main() {}\n```",
+			contains: []string{"<pre", "<span style=\"color:", "main"},
+		},
+		{
+			name:     "task list",
+			source:   "- [x] done",
+			contains: []string{`<input checked="" disabled="" type="checkbox"`},
+		},
+		{
+			name:     "raw html",
+			source:   "<script>alert(1)</script><img src=x onerror=alert(1)>",
+			excludes: []string{"<script", "onerror", "alert(1)<"},
+		},
+		{
+			name:     "javascript link",
+			source:   "[click](javascript:alert(1))",
+			excludes: []string{"javascript:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := r.Render(tt.source, nil)
+			assert.NoError(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, html, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, html, s)
+			}
+		})
+	}
+}
+
+func TestChatRenderer(t *testing.T) {
+	r := NewChatRenderer()
+
+	html, err := r.Render("*hi* ~~there~~ `code` https://go.dev", nil)
+	assert.NoError(t, err)
+	assert.Contains(t, html, "<em>hi</em>")
+	assert.Contains(t, html, "<del>there</del>")
+	assert.Contains(t, html, "<code>code</code>")
+	assert.Contains(t, html, `<a href="https://go.dev"`)
+	assert.False(t, strings.HasPrefix(html, "<p>"))
+
+	html, err = r.Render("# Title\n\n| a |\n|---|\n| 1 |\n\n![x](https://example.com/x.png)<script>x</script>", nil)
+	assert.NoError(t, err)
+	for _, tag := range []string{"<h1", "<table", "<img", "<script"} {
+		assert.NotContains(t, html, tag)
+	}
+	assert.Contains(t, html, "Title")
+}
@@ -0,0 +1,147 @@
+package markup
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// MaxUsernameLength is the longest username a mention can refer to
+const MaxUsernameLength = 64
+
+// ProfileURL returns the path of a user's profile page
+func ProfileURL(userID int64) string {
+	return "/users/" + strconv.FormatInt(userID, 10)
+}
+
+// MentionedUser is a user that mentions in a render may link to
+type MentionedUser struct {
+	ID       int64
+	Username string
+}
+
+// KindMention is the node kind of an @username mention
+var KindMention = ast.NewNodeKind("Mention")
+
+// Mention is an @username reference in the text of a post. Username is
+// given as written; Resolved is the user it refers to, or nil if there is
+// no such user.
+type Mention struct {
+	ast.BaseInline
+	Username string
+	Resolved *MentionedUser
+}
+
+func (n *Mention) Kind() ast.NodeKind {
+	return KindMention
+}
+
+func (n *Mention) Dump(source []byte, level int) {
+	resolved := ""
+	if n.Resolved != nil {
+		resolved = n.Resolved.Username
+	}
+	ast.DumpHelper(n, source, level, map[string]string{"Username": n.Username, "Resolved": resolved}, nil)
+}
+
+// mentionedKey holds the users a render may link to, keyed by their
+// lowercased username
+var mentionedKey = parser.NewContextKey()
+
+type mentionParser struct{}
+
+func (p *mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (p *mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if pc.IsInLinkLabel() {
+		return nil
+	}
+	// Собака внутри слова (например, в адресе почты) упоминанием не считается
+	if prev := block.PrecendingCharacter(); unicode.IsLetter(prev) || unicode.IsDigit(prev) || isUsernameChar(prev) {
+		return nil
+	}
+
+	line, _ := block.PeekLine()
+	i := 1
+	for i < len(line) && i <= MaxUsernameLength && isUsernameChar(rune(line[i])) {
+		i++
+	}
+	// Точка или дефис в конце относятся к предложению, а не к имени
+	for i > 1 && (line[i-1] == '.' || line[i-1] == '-') {
+		i--
+	}
+	if i == 1 || (i < len(line) && isUsernameChar(rune(line[i])) && line[i] != '.' && line[i] != '-') {
+		return nil
+	}
+
+	mention := &Mention{Username: string(line[1:i])}
+	if mentioned, ok := pc.Get(mentionedKey).(map[string]*MentionedUser); ok {
+		mention.Resolved = mentioned[strings.ToLower(mention.Username)]
+	}
+	block.Advance(i)
+	return mention
+}
+
+func isUsernameChar(c rune) bool {
+	return c < unicode.MaxASCII && (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-')
+}
+
+type mentionRenderer struct{}
+
+func (r *mentionRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMention, r.renderMention)
+}
+
+func (r *mentionRenderer) renderMention(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	mention := node.(*Mention)
+	if mention.Resolved == nil {
+		_, _ = w.WriteString("@")
+		_, _ = w.Write(util.EscapeHTML([]byte(mention.Username)))
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<a href="`)
+	_, _ = w.WriteString(ProfileURL(mention.Resolved.ID))
+	_, _ = w.WriteString(`" class="mention">@`)
+	_, _ = w.Write(util.EscapeHTML([]byte(mention.Resolved.Username)))
+	_, _ = w.WriteString(`</a>`)
+	return ast.WalkContinue, nil
+}
+
+type mentionExtension struct{}
+
+func (e *mentionExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(&mentionParser{}, 500)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&mentionRenderer{}, 500)))
+}
+
+// findMentions returns the usernames mentioned in source in the order they
+// first appear, without repeats that differ only in case
+func findMentions(markdown goldmark.Markdown, source string) []string {
+	doc := markdown.Parser().Parse(text.NewReader([]byte(source)))
+	var usernames []string
+	seen := make(map[string]bool)
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if mention, ok := node.(*Mention); ok && entering {
+			key := strings.ToLower(mention.Username)
+			if !seen[key] {
+				seen[key] = true
+				usernames = append(usernames, mention.Username)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return usernames
+}
@@ -0,0 +1,41 @@
+package markup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMentions(t *testing.T) {
+	r := NewPostRenderer()
+
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{name: "single", source: "thanks @alice", want: []string{"alice"}},
+		{name: "repeated in other case", source: "@Bob and @bob, then @carol.", want: []string{"Bob", "carol"}},
+		{name: "dotted name", source: "cc @john.doe-2.", want: []string{"john.doe-2"}},
+		{name: "email", source: "write to alice@example.com", want: nil},
+		{name: "code", source: "`@alice` and\n\n```\n@bob\n```", want: nil},
+		{name: "link text", source: "[@alice](https://example.com) @dave", want: []string{"dave"}},
+		{name: "bare at", source: "@ and @@", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Mentions(tt.source))
+		})
+	}
+}
+
+func TestRenderMentions(t *testing.T) {
+	html, err := NewPostRenderer().Render("hi @Alice and @nobody", []MentionedUser{{ID: 7, Username: "alice"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `<p>hi <a href="/users/7" class="mention" rel="nofollow">@alice</a> and @nobody</p>`, html)
+
+	html, err = NewChatRenderer().Render("**@alice**", []MentionedUser{{ID: 7, Username: "alice"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `<strong><a href="/users/7" class="mention" rel="nofollow">@alice</a></strong>`, html)
+}
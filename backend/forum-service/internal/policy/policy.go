@@ -0,0 +1,121 @@
+// Package policy decides who may change forum content. Authors may change
+// their own topics and comments; moderators and admins may change anyone's.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type Resource string
+
+const (
+	ResourceTopic   Resource = "topic"
+	ResourceComment Resource = "comment"
+)
+
+type Action string
+
+const (
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var (
+	ErrUnauthenticated = errors.New("user not authenticated")
+	ErrForbidden       = errors.New("insufficient permissions")
+)
+
+// staffRoles lists the roles that may act on content written by other users
+var staffRoles = map[Resource]map[Action][]string{
+	ResourceTopic: {
+		ActionUpdate: {entity.RoleModerator, entity.RoleAdmin},
+		ActionDelete: {entity.RoleModerator, entity.RoleAdmin},
+	},
+	ResourceComment: {
+		ActionUpdate: {entity.RoleModerator, entity.RoleAdmin},
+		ActionDelete: {entity.RoleModerator, entity.RoleAdmin},
+	},
+}
+
+// Actor is the user on whose behalf a change is made
+type Actor struct {
+	UserID int64
+	Role   string
+}
+
+// IsStaff reports whether the actor is a moderator or an admin
+func (a Actor) IsStaff() bool {
+	return a.Role == entity.RoleModerator || a.Role == entity.RoleAdmin
+}
+
+// Can reports whether the actor may perform the action on a resource
+// written by ownerID.
+func Can(actor Actor, resource Resource, action Action, ownerID int64) bool {
+	if actor.UserID <= 0 {
+		return false
+	}
+	if actor.UserID == ownerID {
+		return true
+	}
+	for _, role := range staffRoles[resource][action] {
+		if actor.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleLookup resolves the forum role of a user
+type RoleLookup interface {
+	GetUserRole(ctx context.Context, id int64) (string, error)
+}
+
+type roleKey struct{}
+
+// ContextWithRole returns a copy of ctx carrying the role the auth service
+// reported for the caller
+func ContextWithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFromContext returns the role stored by ContextWithRole, if any
+func RoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(roleKey{}).(string)
+	return role, ok && role != ""
+}
+
+// Policy checks changes against the role the auth service reported for the
+// caller. Callers without one fall back to users.role of the forum database.
+type Policy struct {
+	roles RoleLookup
+}
+
+func New(roles RoleLookup) *Policy {
+	return &Policy{roles: roles}
+}
+
+// Authorize returns the actor for userID if they may perform the action on a
+// resource written by ownerID, and ErrForbidden otherwise.
+func (p *Policy) Authorize(ctx context.Context, userID int64, resource Resource, action Action, ownerID int64) (Actor, error) {
+	if userID <= 0 {
+		return Actor{}, ErrUnauthenticated
+	}
+	role, ok := RoleFromContext(ctx)
+	if !ok {
+		var err error
+		role, err = p.roles.GetUserRole(ctx, userID)
+		if err != nil {
+			return Actor{}, fmt.Errorf("failed to get user role: %w", err)
+		}
+	}
+
+	actor := Actor{UserID: userID, Role: role}
+	if !Can(actor, resource, action, ownerID) {
+		return Actor{}, ErrForbidden
+	}
+	return actor, nil
+}
@@ -0,0 +1,103 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type roleMap map[int64]string
+
+func (m roleMap) GetUserRole(_ context.Context, id int64) (string, error) {
+	role, ok := m[id]
+	if !ok {
+		return "", errors.New("lookup failed")
+	}
+	return role, nil
+}
+
+func TestCan(t *testing.T) {
+	const ownerID = 1
+	tests := []struct {
+		name     string
+		actor    Actor
+		resource Resource
+		action   Action
+		want     bool
+	}{
+		{"owner updates topic", Actor{UserID: ownerID, Role: entity.RoleUser}, ResourceTopic, ActionUpdate, true},
+		{"owner deletes topic", Actor{UserID: ownerID, Role: entity.RoleUser}, ResourceTopic, ActionDelete, true},
+		{"owner updates comment", Actor{UserID: ownerID, Role: entity.RoleUser}, ResourceComment, ActionUpdate, true},
+		{"owner deletes comment", Actor{UserID: ownerID, Role: entity.RoleUser}, ResourceComment, ActionDelete, true},
+		{"user updates others topic", Actor{UserID: 2, Role: entity.RoleUser}, ResourceTopic, ActionUpdate, false},
+		{"user deletes others topic", Actor{UserID: 2, Role: entity.RoleUser}, ResourceTopic, ActionDelete, false},
+		{"user updates others comment", Actor{UserID: 2, Role: entity.RoleUser}, ResourceComment, ActionUpdate, false},
+		{"user deletes others comment", Actor{UserID: 2, Role: entity.RoleUser}, ResourceComment, ActionDelete, false},
+		{"moderator updates topic", Actor{UserID: 3, Role: entity.RoleModerator}, ResourceTopic, ActionUpdate, true},
+		{"moderator deletes topic", Actor{UserID: 3, Role: entity.RoleModerator}, ResourceTopic, ActionDelete, true},
+		{"moderator deletes comment", Actor{UserID: 3, Role: entity.RoleModerator}, ResourceComment, ActionDelete, true},
+		{"admin updates comment", Actor{UserID: 4, Role: entity.RoleAdmin}, ResourceComment, ActionUpdate, true},
+		{"admin deletes topic", Actor{UserID: 4, Role: entity.RoleAdmin}, ResourceTopic, ActionDelete, true},
+		{"unknown role", Actor{UserID: 5, Role: "editor"}, ResourceTopic, ActionDelete, false},
+		{"anonymous", Actor{Role: entity.RoleAdmin}, ResourceTopic, ActionDelete, false},
+		{"unknown action", Actor{UserID: 4, Role: entity.RoleAdmin}, ResourceTopic, Action("pin"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Can(tt.actor, tt.resource, tt.action, ownerID))
+		})
+	}
+}
+
+func TestPolicy_Authorize(t *testing.T) {
+	p := New(roleMap{1: entity.RoleUser, 2: entity.RoleUser, 3: entity.RoleModerator})
+
+	tests := []struct {
+		name     string
+		userID   int64
+		ownerID  int64
+		wantRole string
+		wantErr  error
+	}{
+		{name: "owner", userID: 1, ownerID: 1, wantRole: entity.RoleUser},
+		{name: "other user", userID: 2, ownerID: 1, wantErr: ErrForbidden},
+		{name: "moderator", userID: 3, ownerID: 1, wantRole: entity.RoleModerator},
+		{name: "anonymous", userID: 0, ownerID: 1, wantErr: ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor, err := p.Authorize(context.Background(), tt.userID, ResourceComment, ActionDelete, tt.ownerID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, Actor{UserID: tt.userID, Role: tt.wantRole}, actor)
+		})
+	}
+}
+
+func TestPolicy_Authorize_LookupError(t *testing.T) {
+	p := New(roleMap{})
+
+	_, err := p.Authorize(context.Background(), 9, ResourceTopic, ActionUpdate, 9)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrForbidden)
+}
+
+func TestPolicy_Authorize_RoleFromContext(t *testing.T) {
+	p := New(roleMap{})
+	ctx := ContextWithRole(context.Background(), entity.RoleModerator)
+
+	actor, err := p.Authorize(ctx, 5, ResourceTopic, ActionDelete, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, Actor{UserID: 5, Role: entity.RoleModerator}, actor)
+
+	_, err = p.Authorize(ContextWithRole(context.Background(), entity.RoleUser), 5, ResourceTopic, ActionDelete, 1)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment *entity.Attachment) error
+	GetAttachment(ctx context.Context, id int64) (*entity.Attachment, bool, error)
+	GetTopicAttachments(ctx context.Context, topicID int64) ([]*entity.Attachment, error)
+	LinkAttachments(ctx context.Context, uploaderID int64, postType entity.AttachmentPostType, postID int64, ids []int64, limit int) error
+	DeleteAttachment(ctx context.Context, id int64) error
+	DeleteOrphanedAttachments(ctx context.Context, before time.Time, limit int) ([]*entity.Attachment, error)
+}
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrTooManyAttachments = errors.New("too many attachments for one post")
+)
+
+const attachmentColumns = `a.id, a.uploader_id, COALESCE(a.post_type, ''), COALESCE(a.post_id, 0), a.filename,
+	a.content_type, a.size, a.storage_key, COALESCE(a.thumbnail_key, ''), a.created_at`
+
+// attachmentVisibleExpr tells whether the post an attachment a belongs to
+// can be shown. Scheduled topics aren't public until they are published.
+const attachmentVisibleExpr = `CASE a.post_type
+	WHEN 'topic' THEN EXISTS (
+		SELECT 1 FROM topics t WHERE t.id = a.post_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL)
+	WHEN 'comment' THEN EXISTS (
+		SELECT 1 FROM comments c JOIN topics t ON t.id = c.topic_id
+		WHERE c.id = a.post_id AND c.hidden_at IS NULL AND c.deleted_at IS NULL
+			AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL)
+	ELSE FALSE END`
+
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func scanAttachment(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*entity.Attachment, error) {
+	a := &entity.Attachment{}
+	dest := append([]interface{}{
+		&a.ID,
+		&a.UploaderID,
+		&a.PostType,
+		&a.PostID,
+		&a.Filename,
+		&a.ContentType,
+		&a.Size,
+		&a.StorageKey,
+		&a.ThumbnailKey,
+		&a.CreatedAt,
+	}, extra...)
+	return a, row.Scan(dest...)
+}
+
+// CreateAttachment saves an upload that isn't linked to a post yet
+func (r *attachmentRepository) CreateAttachment(ctx context.Context, attachment *entity.Attachment) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO attachments (uploader_id, filename, content_type, size, storage_key, thumbnail_key)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+		RETURNING id, created_at`,
+		attachment.UploaderID, attachment.Filename, attachment.ContentType, attachment.Size,
+		attachment.StorageKey, attachment.ThumbnailKey,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	return nil
+}
+
+// GetAttachment returns an attachment and whether the post it belongs to can
+// be shown. Uploads that aren't linked yet are never visible.
+func (r *attachmentRepository) GetAttachment(ctx context.Context, id int64) (*entity.Attachment, bool, error) {
+	var visible bool
+	attachment, err := scanAttachment(r.db.QueryRowContext(ctx,
+		`SELECT `+attachmentColumns+`, `+attachmentVisibleExpr+` FROM attachments a WHERE a.id = $1`,
+		id,
+	), &visible)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, ErrAttachmentNotFound
+		}
+		return nil, false, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return attachment, visible, nil
+}
+
+// GetTopicAttachments returns the attachments of a topic and of its visible
+// comments in upload order
+func (r *attachmentRepository) GetTopicAttachments(ctx context.Context, topicID int64) ([]*entity.Attachment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+attachmentColumns+`
+		FROM attachments a
+		WHERE (a.post_type = 'topic' AND a.post_id = $1)
+			OR (a.post_type = 'comment' AND a.post_id IN (
+				SELECT id FROM comments WHERE topic_id = $1 AND hidden_at IS NULL AND deleted_at IS NULL))
+		ORDER BY a.id`,
+		topicID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []*entity.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// LinkAttachments links uploads of a user that aren't linked yet to a post.
+// Nothing is linked unless every upload qualifies and the post keeps at most
+// limit attachments. The post row stays locked until the links are made, so
+// concurrent calls for one post can't exceed the limit together.
+func (r *attachmentRepository) LinkAttachments(ctx context.Context, uploaderID int64, postType entity.AttachmentPostType, postID int64, ids []int64, limit int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем пост, чтобы вложения считались и привязывались по очереди
+	lockQuery, errNotFound := `SELECT id FROM topics WHERE id = $1 FOR UPDATE`, ErrTopicNotFound
+	if postType == entity.AttachmentPostComment {
+		lockQuery, errNotFound = `SELECT id FROM comments WHERE id = $1 FOR UPDATE`, ErrCommentNotFound
+	}
+	var lockedID int64
+	if err := tx.QueryRowContext(ctx, lockQuery, postID).Scan(&lockedID); err != nil {
+		if err == sql.ErrNoRows {
+			return errNotFound
+		}
+		return fmt.Errorf("failed to lock post: %w", err)
+	}
+
+	var count int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM attachments WHERE post_type = $1 AND post_id = $2`,
+		postType, postID,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to count attachments: %w", err)
+	}
+	if count+len(ids) > limit {
+		return ErrTooManyAttachments
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE attachments SET post_type = $2, post_id = $3
+		WHERE id = ANY($4) AND uploader_id = $1 AND post_id IS NULL`,
+		uploaderID, postType, postID, pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link attachments: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows != int64(len(ids)) {
+		return ErrAttachmentNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *attachmentRepository) DeleteAttachment(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM attachments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// DeleteOrphanedAttachments removes up to limit uploads that were not
+// linked to a post before the given time, or whose post was purged, and
+// returns them so their files can be deleted. Rows taken by another
+// instance are skipped.
+func (r *attachmentRepository) DeleteOrphanedAttachments(ctx context.Context, before time.Time, limit int) ([]*entity.Attachment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH a AS (
+			DELETE FROM attachments
+			WHERE id IN (
+				SELECT a.id FROM attachments a
+				WHERE (a.post_id IS NULL AND a.created_at < $1)
+					OR (a.post_type = 'topic' AND NOT EXISTS (SELECT 1 FROM topics WHERE id = a.post_id))
+					OR (a.post_type = 'comment' AND NOT EXISTS (SELECT 1 FROM comments WHERE id = a.post_id))
+				ORDER BY a.id
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+attachmentColumns+` FROM a`,
+		before, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete orphaned attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*entity.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orphaned attachments: %w", err)
+	}
+	return attachments, nil
+}
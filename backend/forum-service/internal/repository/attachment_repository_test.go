@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAttachmentRepo(t *testing.T) (AttachmentRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewAttachmentRepository(db), mock, func() { db.Close() }
+}
+
+var attachmentRowColumns = []string{"id", "uploader_id", "post_type", "post_id", "filename",
+	"content_type", "size", "storage_key", "thumbnail_key", "created_at"}
+
+func TestAttachmentRepository_CreateAttachment(t *testing.T) {
+	repo, mock, closeFn := newTestAttachmentRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	attachment := &entity.Attachment{
+		UploaderID:  5,
+		Filename:    "build.log",
+		ContentType: "text/plain",
+		Size:        11,
+		StorageKey:  "attachments/2024/03/a",
+	}
+	mock.ExpectQuery(`INSERT INTO attachments[\s\S]+NULLIF\(\$6, ''\)`).
+		WithArgs(int64(5), "build.log", "text/plain", int64(11), "attachments/2024/03/a", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, now))
+
+	assert.NoError(t, repo.CreateAttachment(context.Background(), attachment))
+	assert.Equal(t, int64(7), attachment.ID)
+	assert.Equal(t, now, attachment.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachmentRepository_GetAttachment(t *testing.T) {
+	repo, mock, closeFn := newTestAttachmentRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	// Вложения еще не опубликованной темы не показываются
+	mock.ExpectQuery(`SELECT a.id[\s\S]+CASE a.post_type\s+WHEN 'topic' THEN EXISTS \([^)]+AND t.publish_at IS NULL\)[\s\S]+FROM attachments a WHERE a.id = \$1`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows(append(attachmentRowColumns, "visible")).
+			AddRow(7, 5, "comment", 10, "shot.png", "image/png", 2048, "attachments/a", "attachments/a_thumb.jpg", now, true))
+
+	attachment, visible, err := repo.GetAttachment(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.True(t, visible)
+	assert.Equal(t, entity.AttachmentPostComment, attachment.PostType)
+	assert.Equal(t, int64(10), attachment.PostID)
+	assert.Equal(t, "attachments/a_thumb.jpg", attachment.ThumbnailKey)
+
+	mock.ExpectQuery(`FROM attachments a WHERE a.id = \$1`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows(append(attachmentRowColumns, "visible")))
+	_, _, err = repo.GetAttachment(context.Background(), 8)
+	assert.ErrorIs(t, err, ErrAttachmentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachmentRepository_LinkAttachments(t *testing.T) {
+	repo, mock, closeFn := newTestAttachmentRepo(t)
+	defer closeFn()
+
+	expectLock := func(count int) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM topics WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM attachments WHERE post_type = \$1 AND post_id = \$2`).
+			WithArgs(entity.AttachmentPostTopic, int64(4)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+	}
+
+	expectLock(8)
+	mock.ExpectExec(`UPDATE attachments SET post_type = \$2, post_id = \$3[\s\S]+uploader_id = \$1 AND post_id IS NULL`).
+		WithArgs(int64(5), entity.AttachmentPostTopic, int64(4), pq.Array([]int64{7, 8})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.LinkAttachments(context.Background(), 5, entity.AttachmentPostTopic, 4, []int64{7, 8}, 10))
+
+	// Лимит считается под блокировкой поста
+	expectLock(9)
+	mock.ExpectRollback()
+	err := repo.LinkAttachments(context.Background(), 5, entity.AttachmentPostTopic, 4, []int64{7, 8}, 10)
+	assert.ErrorIs(t, err, ErrTooManyAttachments)
+
+	// Чужая или уже привязанная загрузка отменяет всю привязку
+	expectLock(0)
+	mock.ExpectExec(`UPDATE attachments`).
+		WithArgs(int64(5), entity.AttachmentPostTopic, int64(4), pq.Array([]int64{7, 9})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+	err = repo.LinkAttachments(context.Background(), 5, entity.AttachmentPostTopic, 4, []int64{7, 9}, 10)
+	assert.ErrorIs(t, err, ErrAttachmentNotFound)
+
+	// Пост удалили, пока шла привязка
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM comments WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(3)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	err = repo.LinkAttachments(context.Background(), 5, entity.AttachmentPostComment, 3, []int64{7}, 10)
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachmentRepository_GetTopicAttachments(t *testing.T) {
+	repo, mock, closeFn := newTestAttachmentRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM attachments a[\s\S]+post_type = 'topic' AND a.post_id = \$1[\s\S]+SELECT id FROM comments WHERE topic_id = \$1 AND hidden_at IS NULL`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows(attachmentRowColumns).
+			AddRow(7, 5, "topic", 4, "shot.png", "image/png", 2048, "attachments/a", "", now).
+			AddRow(8, 6, "comment", 10, "build.log", "text/plain", 11, "attachments/b", "", now))
+
+	attachments, err := repo.GetTopicAttachments(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.Len(t, attachments, 2)
+	assert.Equal(t, entity.AttachmentPostComment, attachments[1].PostType)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachmentRepository_DeleteOrphanedAttachments(t *testing.T) {
+	repo, mock, closeFn := newTestAttachmentRepo(t)
+	defer closeFn()
+
+	before := time.Date(2024, 3, 14, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`DELETE FROM attachments[\s\S]+post_id IS NULL AND a.created_at < \$1[\s\S]+NOT EXISTS \(SELECT 1 FROM topics[\s\S]+FOR UPDATE SKIP LOCKED`).
+		WithArgs(before, 100).
+		WillReturnRows(sqlmock.NewRows(attachmentRowColumns).
+			AddRow(7, 5, "", 0, "shot.png", "image/png", 2048, "attachments/a", "attachments/a_thumb.jpg", before))
+
+	attachments, err := repo.DeleteOrphanedAttachments(context.Background(), before, 100)
+	assert.NoError(t, err)
+	assert.Len(t, attachments, 1)
+	assert.False(t, attachments[0].IsLinked())
+	assert.Equal(t, "attachments/a_thumb.jpg", attachments[0].ThumbnailKey)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
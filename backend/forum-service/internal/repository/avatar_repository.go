@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type AvatarRepository interface {
+	GetAvatar(ctx context.Context, userID int64) (key, username string, err error)
+	SetAvatar(ctx context.Context, userID int64, key, url string) (string, error)
+}
+
+var ErrUserNotFound = errors.New("user not found")
+
+type avatarRepository struct {
+	db *sql.DB
+}
+
+func NewAvatarRepository(db *sql.DB) AvatarRepository {
+	return &avatarRepository{db: db}
+}
+
+// GetAvatar returns the storage key of the uploaded avatar of a user, empty
+// if there is none, and the username
+func (r *avatarRepository) GetAvatar(ctx context.Context, userID int64) (string, string, error) {
+	var key, username string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(avatar_key, ''), username FROM users WHERE id = $1`,
+		userID,
+	).Scan(&key, &username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", "", ErrUserNotFound
+		}
+		return "", "", fmt.Errorf("failed to get avatar: %w", err)
+	}
+	return key, username, nil
+}
+
+// SetAvatar replaces the avatar of a user and returns the storage key of the
+// previous one. Empty key and url reset the user to the generated avatar.
+func (r *avatarRepository) SetAvatar(ctx context.Context, userID int64, key, url string) (string, error) {
+	var previous string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE users u SET avatar = NULLIF($2, ''), avatar_key = NULLIF($3, '')
+		FROM (SELECT id, avatar_key FROM users WHERE id = $1 FOR UPDATE) old
+		WHERE u.id = old.id
+		RETURNING COALESCE(old.avatar_key, '')`,
+		userID, url, key,
+	).Scan(&previous)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("failed to set avatar: %w", err)
+	}
+	return previous, nil
+}
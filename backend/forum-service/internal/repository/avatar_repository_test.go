@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAvatarRepo(t *testing.T) (AvatarRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewAvatarRepository(db), mock, func() { db.Close() }
+}
+
+func TestAvatarRepository_GetAvatar(t *testing.T) {
+	repo, mock, closeFn := newTestAvatarRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`SELECT COALESCE\(avatar_key, ''\), username FROM users WHERE id = \$1`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"avatar_key", "username"}).AddRow("avatars/5/abc", "alice"))
+	key, username, err := repo.GetAvatar(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "avatars/5/abc", key)
+	assert.Equal(t, "alice", username)
+
+	mock.ExpectQuery(`FROM users`).
+		WithArgs(int64(6)).
+		WillReturnRows(sqlmock.NewRows([]string{"avatar_key", "username"}))
+	_, _, err = repo.GetAvatar(context.Background(), 6)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAvatarRepository_SetAvatar(t *testing.T) {
+	repo, mock, closeFn := newTestAvatarRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`UPDATE users u SET avatar = NULLIF\(\$2, ''\), avatar_key = NULLIF\(\$3, ''\)[\s\S]+FOR UPDATE`).
+		WithArgs(int64(5), "/api/v1/users/5/avatar?v=def", "avatars/5/def").
+		WillReturnRows(sqlmock.NewRows([]string{"avatar_key"}).AddRow("avatars/5/abc"))
+	previous, err := repo.SetAvatar(context.Background(), 5, "avatars/5/def", "/api/v1/users/5/avatar?v=def")
+	assert.NoError(t, err)
+	assert.Equal(t, "avatars/5/abc", previous)
+
+	mock.ExpectQuery(`UPDATE users`).
+		WithArgs(int64(6), "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"avatar_key"}))
+	_, err = repo.SetAvatar(context.Background(), 6, "", "")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	mock.ExpectQuery(`UPDATE users`).
+		WillReturnError(errors.New("db down"))
+	_, err = repo.SetAvatar(context.Background(), 5, "", "")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type BookmarkRepository interface {
+	CreateBookmark(ctx context.Context, bookmark *entity.Bookmark) error
+	GetBookmark(ctx context.Context, userID, id int64) (*entity.Bookmark, error)
+	GetBookmarks(ctx context.Context, filter entity.BookmarkFilter) (*entity.BookmarkPage, error)
+	UpdateBookmark(ctx context.Context, bookmark *entity.Bookmark) error
+	DeleteBookmark(ctx context.Context, userID, id int64) error
+	ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]*entity.Bookmark, error)
+	ReleaseReminder(ctx context.Context, id int64, claimedAt time.Time) error
+}
+
+var (
+	ErrBookmarkNotFound = errors.New("bookmark not found")
+	ErrBookmarkExists   = errors.New("target is already bookmarked")
+)
+
+const bookmarksCursorKey = "bookmarks"
+
+// bookmarkJoins attach the target of bookmarks b: the comment c, if it is
+// one, the topic t and whether the target can still be shown
+const bookmarkJoins = `
+	LEFT JOIN comments c ON b.target_type = 'comment' AND c.id = b.target_id
+	LEFT JOIN topics t ON t.id = CASE WHEN b.target_type = 'topic' THEN b.target_id ELSE c.topic_id END
+	CROSS JOIN LATERAL (SELECT t.id IS NOT NULL AND t.deleted_at IS NULL AND t.hidden_at IS NULL AND t.publish_at IS NULL
+		AND (b.target_type = 'topic' OR (c.deleted_at IS NULL AND c.hidden_at IS NULL)) AS available) a`
+
+// bookmarkColumns are selected with bookmarkJoins. The content of an
+// unavailable target is left out.
+var bookmarkColumns = fmt.Sprintf(`b.id, b.user_id, b.target_type, b.target_id, COALESCE(t.id, 0),
+	CASE WHEN a.available THEN t.title ELSE '' END,
+	CASE WHEN a.available AND b.target_type = 'comment' THEN left(c.content, %d) ELSE '' END,
+	a.available, b.note, b.remind_at, b.reminded_at, b.created_at, b.updated_at`, entity.BookmarkExcerptLength)
+
+type bookmarkRepository struct {
+	db *sql.DB
+}
+
+func NewBookmarkRepository(db *sql.DB) BookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+func scanBookmark(row interface{ Scan(...interface{}) error }) (*entity.Bookmark, error) {
+	b := &entity.Bookmark{}
+	var remindAt, remindedAt sql.NullTime
+	err := row.Scan(
+		&b.ID,
+		&b.UserID,
+		&b.TargetType,
+		&b.TargetID,
+		&b.TopicID,
+		&b.Title,
+		&b.Excerpt,
+		&b.Available,
+		&b.Note,
+		&remindAt,
+		&remindedAt,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if remindAt.Valid {
+		b.RemindAt = &remindAt.Time
+	}
+	if remindedAt.Valid {
+		b.RemindedAt = &remindedAt.Time
+	}
+	return b, err
+}
+
+// CreateBookmark saves a bookmark of a visible topic or comment and fills in
+// the rest of its fields
+func (r *bookmarkRepository) CreateBookmark(ctx context.Context, bookmark *entity.Bookmark) error {
+	var found bool
+	var id sql.NullInt64
+	// Отличаем отсутствующую цель от уже существующей закладки
+	err := r.db.QueryRowContext(ctx, `
+		WITH target AS (
+			SELECT CASE $2::text
+				WHEN 'topic' THEN EXISTS (
+					SELECT 1 FROM topics WHERE id = $3 AND deleted_at IS NULL AND hidden_at IS NULL AND publish_at IS NULL)
+				ELSE EXISTS (
+					SELECT 1 FROM comments c JOIN topics t ON t.id = c.topic_id
+					WHERE c.id = $3 AND c.deleted_at IS NULL AND c.hidden_at IS NULL
+						AND t.deleted_at IS NULL AND t.hidden_at IS NULL)
+			END AS found
+		), inserted AS (
+			INSERT INTO bookmarks (user_id, target_type, target_id, note, remind_at)
+			SELECT $1::bigint, $2::text, $3::bigint, $4::text, $5::timestamptz FROM target WHERE found
+			ON CONFLICT (user_id, target_type, target_id) DO NOTHING
+			RETURNING id
+		)
+		SELECT (SELECT found FROM target), (SELECT id FROM inserted)`,
+		bookmark.UserID, bookmark.TargetType, bookmark.TargetID, bookmark.Note, bookmark.RemindAt,
+	).Scan(&found, &id)
+	if err != nil {
+		return fmt.Errorf("failed to create bookmark: %w", err)
+	}
+	if !found {
+		if bookmark.TargetType == entity.BookmarkTargetComment {
+			return ErrCommentNotFound
+		}
+		return ErrTopicNotFound
+	}
+	if !id.Valid {
+		return ErrBookmarkExists
+	}
+
+	saved, err := r.GetBookmark(ctx, bookmark.UserID, id.Int64)
+	if err != nil {
+		return err
+	}
+	*bookmark = *saved
+	return nil
+}
+
+func (r *bookmarkRepository) GetBookmark(ctx context.Context, userID, id int64) (*entity.Bookmark, error) {
+	bookmark, err := scanBookmark(r.db.QueryRowContext(ctx,
+		`SELECT `+bookmarkColumns+` FROM bookmarks b`+bookmarkJoins+`
+		WHERE b.id = $1 AND b.user_id = $2`,
+		id, userID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBookmarkNotFound
+		}
+		return nil, fmt.Errorf("failed to get bookmark: %w", err)
+	}
+	return bookmark, nil
+}
+
+// GetBookmarks returns a page of a user's bookmarks, newest first
+func (r *bookmarkRepository) GetBookmarks(ctx context.Context, filter entity.BookmarkFilter) (*entity.BookmarkPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = entity.DefaultBookmarkPageSize
+	}
+	if limit > entity.MaxBookmarkPageSize {
+		limit = entity.MaxBookmarkPageSize
+	}
+
+	args := []interface{}{filter.UserID}
+	conditions := "b.user_id = $1"
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor, bookmarksCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		before, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, before, cursor.ID)
+		conditions += fmt.Sprintf(" AND (b.created_at, b.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM bookmarks b%s
+		WHERE %s
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT $%d`, bookmarkColumns, bookmarkJoins, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.BookmarkPage{Bookmarks: []*entity.Bookmark{}}
+	for rows.Next() {
+		bookmark, err := scanBookmark(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+		page.Bookmarks = append(page.Bookmarks, bookmark)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bookmarks: %w", err)
+	}
+
+	if len(page.Bookmarks) > limit {
+		page.Bookmarks = page.Bookmarks[:limit]
+		last := page.Bookmarks[limit-1]
+		page.NextCursor = encodeCursor(bookmarksCursorKey, last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	return page, nil
+}
+
+// UpdateBookmark changes the note and the reminder of a bookmark and fills
+// in the rest of its fields. A changed reminder fires again.
+func (r *bookmarkRepository) UpdateBookmark(ctx context.Context, bookmark *entity.Bookmark) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE bookmarks
+		SET note = $3, remind_at = $4,
+			reminded_at = CASE WHEN remind_at IS DISTINCT FROM $4 THEN NULL ELSE reminded_at END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2`,
+		bookmark.ID, bookmark.UserID, bookmark.Note, bookmark.RemindAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update bookmark: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrBookmarkNotFound
+	}
+
+	saved, err := r.GetBookmark(ctx, bookmark.UserID, bookmark.ID)
+	if err != nil {
+		return err
+	}
+	*bookmark = *saved
+	return nil
+}
+
+func (r *bookmarkRepository) DeleteBookmark(ctx context.Context, userID, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM bookmarks WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete bookmark: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrBookmarkNotFound
+	}
+	return nil
+}
+
+// ClaimDueReminders marks up to limit reminders that are due at now as sent
+// and returns their bookmarks. Rows claimed by another instance are skipped,
+// so every reminder is sent once.
+func (r *bookmarkRepository) ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]*entity.Bookmark, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH b AS (
+			UPDATE bookmarks SET reminded_at = $1
+			WHERE id IN (
+				SELECT id FROM bookmarks
+				WHERE remind_at <= $1 AND reminded_at IS NULL
+				ORDER BY remind_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+bookmarkColumns+` FROM b`+bookmarkJoins,
+		now, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var bookmarks []*entity.Bookmark
+	for rows.Next() {
+		bookmark, err := scanBookmark(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating due reminders: %w", err)
+	}
+	return bookmarks, nil
+}
+
+// ReleaseReminder undoes a claim made at claimedAt by ClaimDueReminders, so
+// a reminder that couldn't be sent comes due again. A reminder rescheduled
+// in the meantime is left alone.
+func (r *bookmarkRepository) ReleaseReminder(ctx context.Context, id int64, claimedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE bookmarks SET reminded_at = NULL WHERE id = $1 AND reminded_at = $2`,
+		id, claimedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release reminder: %w", err)
+	}
+	return nil
+}
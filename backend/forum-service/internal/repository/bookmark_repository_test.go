@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var bookmarkTestColumns = []string{"id", "user_id", "target_type", "target_id", "topic_id", "title", "excerpt",
+	"available", "note", "remind_at", "reminded_at", "created_at", "updated_at"}
+
+func newTestBookmarkRepo(t *testing.T) (BookmarkRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewBookmarkRepository(db), mock, func() { db.Close() }
+}
+
+func TestBookmarkRepository_CreateBookmark(t *testing.T) {
+	repo, mock, closeFn := newTestBookmarkRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	remindAt := now.Add(24 * time.Hour)
+	bookmark := &entity.Bookmark{UserID: 2, TargetType: entity.BookmarkTargetComment, TargetID: 10, Note: "ctx", RemindAt: &remindAt}
+
+	mock.ExpectQuery(`WITH target AS[\s\S]+INSERT INTO bookmarks[\s\S]+ON CONFLICT \(user_id, target_type, target_id\) DO NOTHING`).
+		WithArgs(int64(2), entity.BookmarkTargetComment, int64(10), "ctx", &remindAt).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "id"}).AddRow(true, 1))
+	mock.ExpectQuery(`FROM bookmarks b[\s\S]+WHERE b.id = \$1 AND b.user_id = \$2`).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(bookmarkTestColumns).
+			AddRow(1, 2, "comment", 10, 4, "Go generics", "Cancel the context", true, "ctx", remindAt, nil, now, now))
+
+	assert.NoError(t, repo.CreateBookmark(context.Background(), bookmark))
+	assert.Equal(t, int64(1), bookmark.ID)
+	assert.Equal(t, int64(4), bookmark.TopicID)
+	assert.True(t, bookmark.Available)
+	assert.Equal(t, remindAt, *bookmark.RemindAt)
+	assert.Nil(t, bookmark.RemindedAt)
+
+	// Повторная закладка и закладка удаленного комментария
+	mock.ExpectQuery(`WITH target AS`).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "id"}).AddRow(true, nil))
+	mock.ExpectQuery(`WITH target AS`).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "id"}).AddRow(false, nil))
+
+	err := repo.CreateBookmark(context.Background(), &entity.Bookmark{UserID: 2, TargetType: entity.BookmarkTargetComment, TargetID: 10})
+	assert.ErrorIs(t, err, ErrBookmarkExists)
+	err = repo.CreateBookmark(context.Background(), &entity.Bookmark{UserID: 2, TargetType: entity.BookmarkTargetComment, TargetID: 11})
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookmarkRepository_GetBookmarks(t *testing.T) {
+	repo, mock, closeFn := newTestBookmarkRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	// Закладка удаленной темы остается в списке, но без содержимого
+	mock.ExpectQuery(`WHERE b.user_id = \$1\s+ORDER BY b.created_at DESC, b.id DESC\s+LIMIT \$2`).
+		WithArgs(int64(2), 2).
+		WillReturnRows(sqlmock.NewRows(bookmarkTestColumns).
+			AddRow(3, 2, "topic", 7, 0, "", "", false, "", nil, nil, now, now).
+			AddRow(1, 2, "comment", 10, 4, "Go generics", "Cancel the context", true, "", nil, nil, now.Add(-time.Hour), now))
+
+	page, err := repo.GetBookmarks(context.Background(), entity.BookmarkFilter{UserID: 2, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Bookmarks, 1)
+	assert.False(t, page.Bookmarks[0].Available)
+	assert.Empty(t, page.Bookmarks[0].Title)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mock.ExpectQuery(`AND \(b.created_at, b.id\) < \(\$2, \$3\)`).
+		WithArgs(int64(2), now, int64(3), 2).
+		WillReturnRows(sqlmock.NewRows(bookmarkTestColumns))
+
+	page, err = repo.GetBookmarks(context.Background(), entity.BookmarkFilter{UserID: 2, Cursor: page.NextCursor, Limit: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Bookmarks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookmarkRepository_UpdateBookmark(t *testing.T) {
+	repo, mock, closeFn := newTestBookmarkRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE bookmarks\s+SET note = \$3, remind_at = \$4,\s+reminded_at = CASE WHEN remind_at IS DISTINCT FROM \$4 THEN NULL ELSE reminded_at END`).
+		WithArgs(int64(1), int64(2), "new", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`WHERE b.id = \$1 AND b.user_id = \$2`).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(bookmarkTestColumns).
+			AddRow(1, 2, "topic", 4, 4, "Go generics", "", true, "new", nil, nil, now, now))
+	mock.ExpectExec(`UPDATE bookmarks`).
+		WithArgs(int64(9), int64(2), "", nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	bookmark := &entity.Bookmark{ID: 1, UserID: 2, Note: "new"}
+	assert.NoError(t, repo.UpdateBookmark(context.Background(), bookmark))
+	assert.Equal(t, "Go generics", bookmark.Title)
+	assert.ErrorIs(t, repo.UpdateBookmark(context.Background(), &entity.Bookmark{ID: 9, UserID: 2}), ErrBookmarkNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookmarkRepository_ClaimDueReminders(t *testing.T) {
+	repo, mock, closeFn := newTestBookmarkRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`UPDATE bookmarks SET reminded_at = \$1[\s\S]+WHERE remind_at <= \$1 AND reminded_at IS NULL[\s\S]+FOR UPDATE SKIP LOCKED`).
+		WithArgs(now, 100).
+		WillReturnRows(sqlmock.NewRows(bookmarkTestColumns).
+			AddRow(1, 2, "comment", 10, 4, "Go generics", "Cancel the context", true, "ctx", now.Add(-time.Minute), now, now, now))
+
+	bookmarks, err := repo.ClaimDueReminders(context.Background(), now, 100)
+	assert.NoError(t, err)
+	assert.Len(t, bookmarks, 1)
+	assert.Equal(t, now, *bookmarks[0].RemindedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookmarkRepository_ReleaseReminder(t *testing.T) {
+	repo, mock, closeFn := newTestBookmarkRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE bookmarks SET reminded_at = NULL WHERE id = \$1 AND reminded_at = \$2`).
+		WithArgs(int64(1), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.ReleaseReminder(context.Background(), 1, now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
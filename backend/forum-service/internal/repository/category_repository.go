@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category *entity.Category) error
+	GetCategoryByID(ctx context.Context, id int64) (*entity.Category, error)
+	GetAllCategories(ctx context.Context) ([]*entity.Category, error)
+	UpdateCategory(ctx context.Context, category *entity.Category) error
+	ReorderCategories(ctx context.Context, ids []int64) error
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("category with this name already exists")
+	ErrCategoryNotEmpty = errors.New("category still has topics")
+)
+
+// uniqueViolation is the PostgreSQL error code for unique constraint violations
+const uniqueViolation = "23505"
+
+type categoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) CreateCategory(ctx context.Context, category *entity.Category) error {
+	// Новая категория добавляется в конец списка
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO categories (name, description, position)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM categories))
+		RETURNING id, position, created_at, updated_at`,
+		category.Name, category.Description,
+	).Scan(&category.ID, &category.Position, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrCategoryExists
+		}
+		log.Printf("Error creating category: %v", err)
+		return fmt.Errorf("failed to create category: %w", err)
+	}
+	return nil
+}
+
+func (r *categoryRepository) GetCategoryByID(ctx context.Context, id int64) (*entity.Category, error) {
+	category := &entity.Category{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, name, COALESCE(description, ''), position, created_at, updated_at
+		FROM categories
+		WHERE id = $1`,
+		id,
+	).Scan(&category.ID, &category.Name, &category.Description, &category.Position, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return category, nil
+}
+
+// GetAllCategories returns categories in display order together with their
+// topic counts and the most recently active topic.
+func (r *categoryRepository) GetAllCategories(ctx context.Context) ([]*entity.Category, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.id, c.name, COALESCE(c.description, ''), c.position, c.created_at, c.updated_at,
+			(SELECT COUNT(*) FROM topics t WHERE t.category_id = c.id AND t.publish_at IS NULL) AS topic_count,
+			lt.id, lt.title, lt.last_activity_at
+		FROM categories c
+		LEFT JOIN LATERAL (
+			SELECT id, title, last_activity_at
+			FROM topics
+			WHERE category_id = c.id AND publish_at IS NULL
+			ORDER BY last_activity_at DESC, id DESC
+			LIMIT 1
+		) lt ON true
+		ORDER BY c.position, c.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []*entity.Category{}
+	for rows.Next() {
+		category := &entity.Category{}
+		var (
+			lastTopicID    sql.NullInt64
+			lastTopicTitle sql.NullString
+			lastActivityAt sql.NullTime
+		)
+		if err := rows.Scan(
+			&category.ID,
+			&category.Name,
+			&category.Description,
+			&category.Position,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+			&category.TopicCount,
+			&lastTopicID,
+			&lastTopicTitle,
+			&lastActivityAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		if lastTopicID.Valid {
+			category.LatestActivity = &entity.CategoryActivity{
+				TopicID:    lastTopicID.Int64,
+				TopicTitle: lastTopicTitle.String,
+				At:         lastActivityAt.Time,
+			}
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %w", err)
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	err := r.db.QueryRowContext(ctx,
+		`UPDATE categories SET name = $1, description = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING position, created_at, updated_at`,
+		category.Name, category.Description, category.ID,
+	).Scan(&category.Position, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCategoryNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrCategoryExists
+		}
+		log.Printf("Error updating category %d: %v", category.ID, err)
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+	return nil
+}
+
+// ReorderCategories places the given categories in the order they are listed.
+// Categories not mentioned keep their current position.
+func (r *categoryRepository) ReorderCategories(ctx context.Context, ids []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, id := range ids {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE categories SET position = $1, updated_at = NOW() WHERE id = $2`,
+			i+1, id,
+		)
+		if err != nil {
+			log.Printf("Error moving category %d: %v", id, err)
+			return fmt.Errorf("failed to reorder categories: %w", err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return ErrCategoryNotFound
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteCategory removes an empty category. Topics are never deleted along
+// with their category.
+func (r *categoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+	var hasTopics bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM topics WHERE category_id = $1)`, id,
+	).Scan(&hasTopics)
+	if err != nil {
+		return fmt.Errorf("failed to check category topics: %w", err)
+	}
+	if hasTopics {
+		return ErrCategoryNotEmpty
+	}
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting category %d: %v", id, err)
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCategoryRepo(t *testing.T) (CategoryRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	repo := NewCategoryRepository(db)
+	return repo, mock, func() { db.Close() }
+}
+
+func TestCategoryRepository_CreateCategory(t *testing.T) {
+	repo, mock, closeFn := newTestCategoryRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO categories (name, description, position)`)).
+		WithArgs("Новости", "Анонсы").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "position", "created_at", "updated_at"}).AddRow(4, 4, now, now))
+
+	category := &entity.Category{Name: "Новости", Description: "Анонсы"}
+	err := repo.CreateCategory(context.Background(), category)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), category.ID)
+	assert.Equal(t, 4, category.Position)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryRepository_CreateCategory_Duplicate(t *testing.T) {
+	repo, mock, closeFn := newTestCategoryRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO categories`)).
+		WillReturnError(&pq.Error{Code: uniqueViolation})
+
+	err := repo.CreateCategory(context.Background(), &entity.Category{Name: "Обсуждения"})
+	assert.ErrorIs(t, err, ErrCategoryExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryRepository_GetCategoryByID_NotFound(t *testing.T) {
+	repo, mock, closeFn := newTestCategoryRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM categories`)).
+		WithArgs(int64(99)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "position", "created_at", "updated_at"}))
+
+	category, err := repo.GetCategoryByID(context.Background(), 99)
+	assert.ErrorIs(t, err, ErrCategoryNotFound)
+	assert.Nil(t, category)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryRepository_GetAllCategories(t *testing.T) {
+	repo, mock, closeFn := newTestCategoryRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{
+		"id", "name", "description", "position", "created_at", "updated_at",
+		"topic_count", "lt_id", "lt_title", "lt_last_activity_at",
+	}).
+		AddRow(1, "Общие вопросы", "", 1, now, now, 2, 7, "Как начать", now).
+		AddRow(2, "Пустая", "", 2, now, now, 0, nil, nil, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`LEFT JOIN LATERAL`)).WillReturnRows(rows)
+
+	categories, err := repo.GetAllCategories(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, categories, 2)
+	assert.Equal(t, 2, categories[0].TopicCount)
+	assert.Equal(t, int64(7), categories[0].LatestActivity.TopicID)
+	assert.Equal(t, "Как начать", categories[0].LatestActivity.TopicTitle)
+	assert.Nil(t, categories[1].LatestActivity)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryRepository_ReorderCategories(t *testing.T) {
+	repo, mock, closeFn := newTestCategoryRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE categories SET position = $1`)).
+		WithArgs(1, int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE categories SET position = $1`)).
+		WithArgs(2, int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.ReorderCategories(context.Background(), []int64{3, 1})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryRepository_ReorderCategories_NotFound(t *testing.T) {
+	repo, mock, closeFn := newTestCategoryRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE categories SET position = $1`)).
+		WithArgs(1, int64(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.ReorderCategories(context.Background(), []int64{42})
+	assert.ErrorIs(t, err, ErrCategoryNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryRepository_DeleteCategory(t *testing.T) {
+	tests := []struct {
+		name      string
+		hasTopics bool
+		affected  int64
+		wantErr   error
+	}{
+		{name: "success", affected: 1},
+		{name: "has topics", hasTopics: true, wantErr: ErrCategoryNotEmpty},
+		{name: "not found", affected: 0, wantErr: ErrCategoryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, closeFn := newTestCategoryRepo(t)
+			defer closeFn()
+
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM topics WHERE category_id = $1)`)).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.hasTopics))
+			if !tt.hasTopics {
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM categories WHERE id = $1`)).
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			}
+
+			err := repo.DeleteCategory(context.Background(), 1)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/markup"
 )
 
 type ChatRepository interface {
@@ -14,36 +15,52 @@ type ChatRepository interface {
 }
 
 type chatRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	renderer markup.Renderer
 }
 
 func NewChatRepository(db *sql.DB) ChatRepository {
-	return &chatRepository{db: db}
+	return &chatRepository{db: db, renderer: markup.NewChatRenderer()}
 }
 
 func (r *chatRepository) SaveMessage(ctx context.Context, message *entity.ChatMessage) error {
 	query := `
-		INSERT INTO chat_messages (content, author_id, author_username, created_at, expires_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
+		INSERT INTO chat_messages (content, content_html, author_id, author_username, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, COALESCE((SELECT u.avatar FROM users u WHERE u.id = author_id), '')`
 
-	return r.db.QueryRowContext(
+	contentHTML, mentioned, err := renderContent(ctx, r.db, r.renderer, message.Content)
+	if err != nil {
+		return err
+	}
+	message.ContentHTML = contentHTML
+
+	err = r.db.QueryRowContext(
 		ctx,
 		query,
 		message.Content,
+		message.ContentHTML,
 		message.AuthorID,
 		message.AuthorUsername,
 		message.CreatedAt,
 		message.ExpiresAt,
-	).Scan(&message.ID)
+	).Scan(&message.ID, &message.AuthorAvatar)
+	if err != nil {
+		return err
+	}
+	message.AuthorAvatar = entity.AvatarURL(message.AuthorID, message.AuthorAvatar)
+
+	message.MentionedIDs, err = saveMentions(ctx, r.db, entity.MentionTargetChatMessage, message.ID, message.AuthorID, mentioned, false)
+	return err
 }
 
 func (r *chatRepository) GetRecentMessages(ctx context.Context, limit int) ([]*entity.ChatMessage, error) {
 	query := `
-		SELECT id, content, author_id, author_username, created_at, expires_at
-		FROM chat_messages
-		WHERE expires_at > CURRENT_TIMESTAMP
-		ORDER BY created_at DESC
+		SELECT m.id, m.content, COALESCE(m.content_html, ''), m.author_id, m.author_username, COALESCE(u.avatar, ''), m.created_at, m.expires_at
+		FROM chat_messages m
+		LEFT JOIN users u ON u.id = m.author_id
+		WHERE m.expires_at > CURRENT_TIMESTAMP AND m.hidden_at IS NULL
+		ORDER BY m.created_at DESC
 		LIMIT $1`
 
 	rows, err := r.db.QueryContext(ctx, query, limit)
@@ -58,14 +75,17 @@ func (r *chatRepository) GetRecentMessages(ctx context.Context, limit int) ([]*e
 		err := rows.Scan(
 			&msg.ID,
 			&msg.Content,
+			&msg.ContentHTML,
 			&msg.AuthorID,
 			&msg.AuthorUsername,
+			&msg.AuthorAvatar,
 			&msg.CreatedAt,
 			&msg.ExpiresAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		msg.AuthorAvatar = entity.AvatarURL(msg.AuthorID, msg.AuthorAvatar)
 		messages = append(messages, msg)
 	}
 
@@ -27,7 +27,7 @@ func TestChatRepository_SaveMessage(t *testing.T) {
 	// Создаем тестовое сообщение
 	now := time.Now()
 	message := &entity.ChatMessage{
-		Content:        "Test message",
+		Content:        "Test **message**",
 		AuthorID:       1,
 		AuthorUsername: "user1",
 		CreatedAt:      now,
@@ -36,8 +36,8 @@ func TestChatRepository_SaveMessage(t *testing.T) {
 
 	// Ожидаем, что будет выполнен запрос на сохранение сообщения
 	mock.ExpectQuery(`INSERT INTO chat_messages`).
-		WithArgs(message.Content, message.AuthorID, message.AuthorUsername, message.CreatedAt, message.ExpiresAt).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		WithArgs(message.Content, "Test <strong>message</strong>", message.AuthorID, message.AuthorUsername, message.CreatedAt, message.ExpiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "avatar"}).AddRow(1, ""))
 
 	// Вызываем тестируемый метод
 	err := repo.SaveMessage(context.Background(), message)
@@ -45,6 +45,8 @@ func TestChatRepository_SaveMessage(t *testing.T) {
 	// Проверяем результаты
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), message.ID)
+	assert.Equal(t, "Test <strong>message</strong>", message.ContentHTML)
+	assert.Equal(t, "/api/v1/users/1/avatar", message.AuthorAvatar)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -74,12 +76,11 @@ func TestChatRepository_GetRecentMessages(t *testing.T) {
 	}
 
 	// Ожидаем, что будет выполнен запрос на получение сообщений
-	rows := sqlmock.NewRows([]string{"id", "content", "author_id", "author_username", "created_at", "expires_at"})
-	for _, msg := range expectedMessages {
-		rows.AddRow(msg.ID, msg.Content, msg.AuthorID, msg.AuthorUsername, msg.CreatedAt, msg.ExpiresAt)
-	}
+	rows := sqlmock.NewRows([]string{"id", "content", "content_html", "author_id", "author_username", "avatar", "created_at", "expires_at"})
+	rows.AddRow(1, "Test message 1", "Test message 1", 1, "user1", "/api/v1/users/1/avatar?v=abc", now, now.Add(24*time.Hour))
+	rows.AddRow(2, "Test message 2", "Test message 2", 2, "user2", "", now, now.Add(24*time.Hour))
 
-	mock.ExpectQuery(`SELECT id, content, author_id, author_username, created_at, expires_at FROM chat_messages WHERE expires_at > CURRENT_TIMESTAMP ORDER BY created_at DESC LIMIT \$1`).
+	mock.ExpectQuery(`FROM chat_messages m\s+LEFT JOIN users u ON u.id = m.author_id\s+WHERE m.expires_at > CURRENT_TIMESTAMP AND m.hidden_at IS NULL\s+ORDER BY m.created_at DESC\s+LIMIT \$1`).
 		WithArgs(10).
 		WillReturnRows(rows)
 
@@ -91,6 +92,8 @@ func TestChatRepository_GetRecentMessages(t *testing.T) {
 	assert.Len(t, messages, 2)
 	assert.Equal(t, expectedMessages[0].Content, messages[0].Content)
 	assert.Equal(t, expectedMessages[1].Content, messages[1].Content)
+	assert.Equal(t, "/api/v1/users/1/avatar?v=abc", messages[0].AuthorAvatar)
+	assert.Equal(t, "/api/v1/users/2/avatar", messages[1].AuthorAvatar)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -98,15 +101,10 @@ func TestChatRepository_GetRecentMessages_ScanError(t *testing.T) {
 	repo, mock, closeFn := newTestChatRepo(t)
 	defer closeFn()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, content, author_id, author_username, created_at, expires_at 
-		FROM chat_messages 
-		WHERE expires_at > CURRENT_TIMESTAMP 
-		ORDER BY created_at DESC 
-		LIMIT $1`)).
+	mock.ExpectQuery(`FROM chat_messages m`).
 		WithArgs(10).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "author_id", "author_username", "created_at", "expires_at"}).
-			AddRow(nil, nil, nil, nil, nil, nil))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "content_html", "author_id", "author_username", "avatar", "created_at", "expires_at"}).
+			AddRow(nil, nil, nil, nil, nil, nil, nil, nil))
 
 	messages, err := repo.GetRecentMessages(context.Background(), 10)
 	assert.Error(t, err)
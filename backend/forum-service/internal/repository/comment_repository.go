@@ -4,37 +4,56 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/markup"
 )
 
 type CommentRepository interface {
 	GetCommentsByTopic(ctx context.Context, topicID int64) ([]*entity.Comment, error)
 	GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error)
 	CreateComment(ctx context.Context, comment *entity.Comment) error
-	UpdateComment(ctx context.Context, comment *entity.Comment) error
-	DeleteComment(ctx context.Context, id int64) error
-	LikeComment(ctx context.Context, id int64) error
+	UpdateComment(ctx context.Context, comment *entity.Comment, editorID int64) error
+	DeleteComment(ctx context.Context, id, deletedBy int64) error
+	RestoreComment(ctx context.Context, id int64) error
+	PurgeDeletedComments(ctx context.Context, before time.Time) (int64, error)
+	LikeComment(ctx context.Context, commentID, userID int64) (int, bool, error)
+	UnlikeComment(ctx context.Context, commentID, userID int64) (int, error)
+	GetCommentLikes(ctx context.Context, commentID int64, cursor string, limit int) (*entity.CommentLikePage, error)
+	GetLikedCommentIDs(ctx context.Context, userID int64, commentIDs []int64) (map[int64]bool, error)
+	GetCommentTree(ctx context.Context, query entity.CommentTreeQuery) (*entity.CommentTree, error)
 }
 
+var ErrCommentNotFound = errors.New("comment not found")
+
+const (
+	// repliesCursorKey identifies cursors that continue a list of replies
+	repliesCursorKey = "replies"
+	// likesCursorKey identifies cursors that continue a list of likes
+	likesCursorKey = "likes"
+)
+
 type commentRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	renderer markup.Renderer
 }
 
 func NewCommentRepository(db *sql.DB) CommentRepository {
-	return &commentRepository{db: db}
+	return &commentRepository{db: db, renderer: markup.NewPostRenderer()}
 }
 
 func (r *commentRepository) GetCommentsByTopic(ctx context.Context, topicID int64) ([]*entity.Comment, error) {
 	query := `
-		SELECT c.id, c.content, c.author_id, c.topic_id, c.parent_id, c.likes, c.created_at, c.updated_at,
-		u.username, u.avatar
+		SELECT c.id, c.content, COALESCE(c.content_html, ''), c.author_id, c.topic_id, c.parent_id, c.likes, c.created_at, c.updated_at,
+		c.edited_at, u.username, COALESCE(u.avatar, ''), c.deleted_at
 		FROM comments c
+		JOIN topics t ON t.id = c.topic_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL
 		LEFT JOIN users u ON c.author_id = u.id
-		WHERE c.topic_id = $1
+		WHERE c.topic_id = $1 AND c.hidden_at IS NULL
 		ORDER BY c.created_at ASC
 	`
 	rows, err := r.db.QueryContext(ctx, query, topicID)
@@ -51,18 +70,24 @@ func (r *commentRepository) GetCommentsByTopic(ctx context.Context, topicID int6
 		err := rows.Scan(
 			&comment.ID,
 			&comment.Content,
+			&comment.ContentHTML,
 			&comment.AuthorID,
 			&comment.TopicID,
 			&comment.ParentID,
 			&comment.Likes,
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
+			&comment.EditedAt,
 			&comment.Author.Username,
 			&comment.Author.Avatar,
+			&comment.DeletedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		comment.Author.Avatar = entity.AvatarURL(comment.AuthorID, comment.Author.Avatar)
+		comment.Edited = comment.EditedAt != nil
+		comment.MaskDeleted()
 		comments = append(comments, comment)
 	}
 
@@ -71,11 +96,12 @@ func (r *commentRepository) GetCommentsByTopic(ctx context.Context, topicID int6
 
 func (r *commentRepository) GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error) {
 	query := `
-		SELECT c.id, c.content, c.author_id, c.topic_id, c.parent_id, c.likes, c.created_at, c.updated_at,
-		u.username, u.avatar
+		SELECT c.id, c.content, COALESCE(c.content_html, ''), c.author_id, c.topic_id, c.parent_id, c.likes, c.created_at, c.updated_at,
+		c.edited_at, u.username, COALESCE(u.avatar, ''), c.deleted_at
 		FROM comments c
+		JOIN topics t ON t.id = c.topic_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL
 		LEFT JOIN users u ON c.author_id = u.id
-		WHERE c.id = $1
+		WHERE c.id = $1 AND c.hidden_at IS NULL
 	`
 	comment := &entity.Comment{
 		Author: &entity.User{},
@@ -83,38 +109,71 @@ func (r *commentRepository) GetCommentByID(ctx context.Context, id int64) (*enti
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&comment.ID,
 		&comment.Content,
+		&comment.ContentHTML,
 		&comment.AuthorID,
 		&comment.TopicID,
 		&comment.ParentID,
 		&comment.Likes,
 		&comment.CreatedAt,
 		&comment.UpdatedAt,
+		&comment.EditedAt,
 		&comment.Author.Username,
 		&comment.Author.Avatar,
+		&comment.DeletedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("comment not found")
+			return nil, ErrCommentNotFound
 		}
 		return nil, err
 	}
+	comment.Author.Avatar = entity.AvatarURL(comment.AuthorID, comment.Author.Avatar)
+	comment.Edited = comment.EditedAt != nil
+	comment.MaskDeleted()
 
 	return comment, nil
 }
 
+// CreateComment adds a comment to a topic, or returns ErrTopicNotFound if
+// the topic doesn't exist or has been deleted
 func (r *commentRepository) CreateComment(ctx context.Context, comment *entity.Comment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertComment(ctx, tx, r.renderer, comment); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertComment saves a new comment with its mentions and subscribes the
+// author to the topic. Drafts are published through it inside their own
+// transaction.
+func insertComment(ctx context.Context, q dbtx, renderer markup.Renderer, comment *entity.Comment) error {
 	query := `
-		INSERT INTO comments (content, author_id, topic_id, parent_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO comments (content, content_html, author_id, topic_id, parent_id, created_at, updated_at)
+		SELECT $1::text, $2::text, $3::bigint, $4::bigint, $5::bigint, $6::timestamptz, $7::timestamptz
+		WHERE EXISTS (SELECT 1 FROM topics WHERE id = $4 AND deleted_at IS NULL AND publish_at IS NULL AND NOT ` + topicLockedExpr + `)
 		RETURNING id
 	`
+	contentHTML, mentioned, err := renderContent(ctx, q, renderer, comment.Content)
+	if err != nil {
+		return err
+	}
 	now := time.Now()
 	comment.CreatedAt = now
 	comment.UpdatedAt = now
 
-	err := r.db.QueryRowContext(ctx,
+	err = q.QueryRowContext(ctx,
 		query,
 		comment.Content,
+		contentHTML,
 		comment.AuthorID,
 		comment.TopicID,
 		comment.ParentID,
@@ -123,62 +182,463 @@ func (r *commentRepository) CreateComment(ctx context.Context, comment *entity.C
 	).Scan(&comment.ID)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return commentRejection(ctx, q, comment.TopicID)
+		}
 		log.Printf("Error creating comment: %v", err)
 		return err
 	}
+	comment.ContentHTML = contentHTML
+	comment.MentionedIDs, err = saveMentions(ctx, q, entity.MentionTargetComment, comment.ID, comment.AuthorID, mentioned, false)
+	if err != nil {
+		return err
+	}
+	if err := autoSubscribe(ctx, q, comment.AuthorID, comment.TopicID, entity.WatchLevelTracking); err != nil {
+		return err
+	}
 
 	log.Printf("Comment created successfully with ID: %d for topic ID: %d", comment.ID, comment.TopicID)
 	return nil
 }
 
-func (r *commentRepository) UpdateComment(ctx context.Context, comment *entity.Comment) error {
+// commentRejection explains why a comment wasn't added to a topic: the topic
+// is either gone, not published yet or was locked when the comment was
+// inserted
+func commentRejection(ctx context.Context, q dbtx, topicID int64) error {
+	var exists bool
+	err := q.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM topics WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NULL)`,
+		topicID,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to get topic: %w", err)
+	}
+	if !exists {
+		return ErrTopicNotFound
+	}
+	return ErrTopicLocked
+}
+
+// UpdateComment changes the content of a comment on behalf of editorID and
+// marks it as edited. The previous content is kept as a revision.
+func (r *commentRepository) UpdateComment(ctx context.Context, comment *entity.Comment, editorID int64) error {
+	contentHTML, mentioned, err := renderContent(ctx, r.db, r.renderer, comment.Content)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous := &entity.Revision{TargetType: entity.RevisionTargetComment, TargetID: comment.ID}
+	var authorID int64
+	err = tx.QueryRowContext(ctx,
+		`SELECT content, author_id FROM comments WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		comment.ID,
+	).Scan(&previous.Content, &authorID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCommentNotFound
+		}
+		return fmt.Errorf("failed to lock comment: %w", err)
+	}
+	if previous.Content != comment.Content {
+		if err := saveRevision(ctx, tx, previous, editorID); err != nil {
+			return err
+		}
+	}
+
 	query := `
 		UPDATE comments 
-		SET content = $1, updated_at = $2
-		WHERE id = $3
+		SET content = $1, content_html = $2, updated_at = $3, edited_at = $3
+		WHERE id = $4
 	`
-	comment.UpdatedAt = time.Now()
-	result, err := r.db.ExecContext(ctx, query, comment.Content, comment.UpdatedAt, comment.ID)
-	if err != nil {
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, query, comment.Content, contentHTML, now, comment.ID); err != nil {
 		return err
 	}
+	var added []int64
+	if previous.Content != comment.Content {
+		if added, err = saveMentions(ctx, tx, entity.MentionTargetComment, comment.ID, authorID, mentioned, true); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	comment.ContentHTML = contentHTML
+	comment.MentionedIDs = added
+	comment.UpdatedAt = now
+	comment.EditedAt = &now
+	comment.Edited = true
+	return nil
+}
+
+// DeleteComment marks a comment as deleted by deletedBy (0 if unknown).
+// The row stays in place so its replies keep their parent. The topic's
+// comment count is updated in the same transaction.
+func (r *commentRepository) DeleteComment(ctx context.Context, id, deletedBy int64) error {
+	return r.setDeleted(ctx,
+		`UPDATE comments SET deleted_at = CURRENT_TIMESTAMP, deleted_by = NULLIF($2, 0) WHERE id = $1 AND deleted_at IS NULL RETURNING topic_id`,
+		id, deletedBy,
+	)
+}
 
-	rowsAffected, err := result.RowsAffected()
+// RestoreComment brings back a deleted comment and counts it in its topic
+// again
+func (r *commentRepository) RestoreComment(ctx context.Context, id int64) error {
+	return r.setDeleted(ctx,
+		`UPDATE comments SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING topic_id`,
+		id,
+	)
+}
+
+// setDeleted runs update, which deletes or restores a comment and returns
+// its topic, and recounts the comments of that topic in one transaction.
+// The comment count trigger only sees inserts and deletes, not soft
+// deletes.
+func (r *commentRepository) setDeleted(ctx context.Context, update string, args ...interface{}) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var topicID int64
+	if err := tx.QueryRowContext(ctx, update, args...).Scan(&topicID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCommentNotFound
+		}
+		log.Printf("Error changing deletion of comment %v: %v", args[0], err)
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	if err := recountComments(ctx, tx, topicID); err != nil {
 		return err
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
 
-	if rowsAffected == 0 {
-		return errors.New("comment not found")
+// PurgeDeletedComments permanently removes comments deleted before the given
+// time. A deleted comment that still has replies is kept as their placeholder
+// until the replies are gone too.
+func (r *commentRepository) PurgeDeletedComments(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM comments c
+		WHERE c.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id)`,
+		before,
+	)
+	if err != nil {
+		log.Printf("Error purging deleted comments: %v", err)
+		return 0, fmt.Errorf("failed to purge deleted comments: %w", err)
 	}
+	return result.RowsAffected()
+}
 
-	return nil
+// LikeComment records that the user likes the comment and returns the new
+// number of likes and whether the like is new. Liking a comment twice
+// changes nothing.
+func (r *commentRepository) LikeComment(ctx context.Context, commentID, userID int64) (int, bool, error) {
+	likes, delta, err := r.changeLike(ctx, commentID, func(tx *sql.Tx) (int, error) {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO comment_likes (comment_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			commentID, userID,
+		)
+		if err != nil {
+			log.Printf("Error liking comment %d by user %d: %v", commentID, userID, err)
+			return 0, fmt.Errorf("failed to like comment: %w", err)
+		}
+		n, err := res.RowsAffected()
+		return int(n), err
+	})
+	return likes, delta > 0, err
+}
+
+// UnlikeComment removes the user's like and returns the new number of likes
+func (r *commentRepository) UnlikeComment(ctx context.Context, commentID, userID int64) (int, error) {
+	likes, _, err := r.changeLike(ctx, commentID, func(tx *sql.Tx) (int, error) {
+		res, err := tx.ExecContext(ctx,
+			`DELETE FROM comment_likes WHERE comment_id = $1 AND user_id = $2`,
+			commentID, userID,
+		)
+		if err != nil {
+			log.Printf("Error unliking comment %d by user %d: %v", commentID, userID, err)
+			return 0, fmt.Errorf("failed to unlike comment: %w", err)
+		}
+		n, err := res.RowsAffected()
+		return -int(n), err
+	})
+	return likes, err
 }
 
-func (r *commentRepository) DeleteComment(ctx context.Context, id int64) error {
-	query := `DELETE FROM comments WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+// changeLike runs a like or unlike inside a transaction that holds the
+// comment row, so the likes counter always matches comment_likes. change
+// reports by how much the counter has to move; changeLike returns the new
+// counter and that change.
+func (r *commentRepository) changeLike(ctx context.Context, commentID int64, change func(tx *sql.Tx) (int, error)) (int, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	var likes int
+	err = tx.QueryRowContext(ctx, `SELECT likes FROM comments WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, commentID).Scan(&likes)
 	if err != nil {
-		return err
+		if err == sql.ErrNoRows {
+			return 0, 0, ErrCommentNotFound
+		}
+		return 0, 0, fmt.Errorf("failed to lock comment: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("comment not found")
+	delta, err := change(tx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if delta != 0 {
+		err = tx.QueryRowContext(ctx,
+			`UPDATE comments SET likes = GREATEST(likes + $1, 0) WHERE id = $2 RETURNING likes`,
+			delta, commentID,
+		).Scan(&likes)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to update likes: %w", err)
+		}
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return likes, delta, nil
+}
+
+// GetCommentLikes returns the users who liked a comment, most recent first
+func (r *commentRepository) GetCommentLikes(ctx context.Context, commentID int64, cursor string, limit int) (*entity.CommentLikePage, error) {
+	if limit <= 0 {
+		limit = entity.DefaultCommentLikesPageSize
+	}
+	if limit > entity.MaxCommentLikesPageSize {
+		limit = entity.MaxCommentLikesPageSize
+	}
+
+	args := []interface{}{commentID}
+	conditions := "cl.comment_id = $1"
+	if cursor != "" {
+		c, err := decodeCursor(cursor, likesCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		before, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, before, c.ID)
+		conditions += " AND (cl.created_at, cl.user_id) < ($2, $3)"
+	}
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT cl.user_id, COALESCE(u.username, ''), COALESCE(u.avatar, ''), cl.created_at
+		FROM comment_likes cl
+		LEFT JOIN users u ON u.id = cl.user_id
+		WHERE %s
+		ORDER BY cl.created_at DESC, cl.user_id DESC
+		LIMIT $%d`, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comment likes: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.CommentLikePage{Likes: []*entity.CommentLike{}}
+	for rows.Next() {
+		like := &entity.CommentLike{}
+		if err := rows.Scan(&like.UserID, &like.Username, &like.Avatar, &like.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment like: %w", err)
+		}
+		like.Avatar = entity.AvatarURL(like.UserID, like.Avatar)
+		page.Likes = append(page.Likes, like)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment likes: %w", err)
+	}
+
+	if len(page.Likes) > limit {
+		page.Likes = page.Likes[:limit]
+		last := page.Likes[limit-1]
+		page.NextCursor = encodeCursor(likesCursorKey, last.CreatedAt.Format(time.RFC3339Nano), last.UserID)
+	}
+	return page, nil
+}
+
+// GetLikedCommentIDs reports which of the given comments the user has liked
+func (r *commentRepository) GetLikedCommentIDs(ctx context.Context, userID int64, commentIDs []int64) (map[int64]bool, error) {
+	liked := make(map[int64]bool)
+	if userID <= 0 || len(commentIDs) == 0 {
+		return liked, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT comment_id FROM comment_likes WHERE user_id = $1 AND comment_id = ANY($2)`,
+		userID, pq.Array(commentIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query liked comments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan liked comment: %w", err)
+		}
+		liked[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating liked comments: %w", err)
+	}
+	return liked, nil
+}
+
+// GetCommentTree loads a slice of a topic's comment tree with a single
+// recursive query. Every level is cut at query.Limit replies per comment;
+// a comment whose replies didn't all fit gets a RepliesCursor to continue
+// from, and a comment at the depth limit is returned with its ReplyCount only.
+func (r *commentRepository) GetCommentTree(ctx context.Context, query entity.CommentTreeQuery) (*entity.CommentTree, error) {
+	depth := query.Depth
+	if depth <= 0 {
+		depth = entity.DefaultCommentTreeDepth
+	}
+	if depth > entity.MaxCommentTreeDepth {
+		depth = entity.MaxCommentTreeDepth
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = entity.DefaultRepliesPerNode
+	}
+	if limit > entity.MaxRepliesPerNode {
+		limit = entity.MaxRepliesPerNode
+	}
+
+	args := []interface{}{query.TopicID, depth, limit}
+	conditions := "c.topic_id = $1 AND c.hidden_at IS NULL AND EXISTS (SELECT 1 FROM topics t WHERE t.id = $1 AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL)"
+	if query.ParentID != nil {
+		args = append(args, *query.ParentID)
+		conditions += fmt.Sprintf(" AND c.parent_id = $%d", len(args))
+	} else {
+		conditions += " AND c.parent_id IS NULL"
+	}
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor, repliesCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		after, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, after, cursor.ID)
+		conditions += fmt.Sprintf(" AND (c.created_at, c.id) > ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	// Верхний уровень берется с запасом в одну запись, чтобы понять, есть ли
+	// следующая страница; лишняя запись (rn > limit) дальше не раскрывается.
+	// Для вложенных уровней наличие продолжения видно по reply_count.
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE tree AS (
+			(SELECT c.id, 1 AS depth, ROW_NUMBER() OVER (ORDER BY c.created_at, c.id) AS rn
+			FROM comments c
+			WHERE `+conditions+`
+			ORDER BY c.created_at, c.id
+			LIMIT $3 + 1)
+			UNION ALL
+			SELECT r.id, t.depth + 1, 1::bigint
+			FROM tree t
+			CROSS JOIN LATERAL (
+				SELECT id
+				FROM comments
+				WHERE parent_id = t.id AND hidden_at IS NULL
+				ORDER BY created_at, id
+				LIMIT $3
+			) r
+			WHERE t.depth < $2 AND t.rn <= $3
+		)
+		SELECT c.id, c.content, COALESCE(c.content_html, ''), c.author_id, c.topic_id, c.parent_id, c.likes, c.created_at, c.updated_at,
+			c.edited_at, COALESCE(u.username, ''), COALESCE(u.avatar, ''), tree.depth,
+			(SELECT COUNT(*) FROM comments rc WHERE rc.parent_id = c.id AND rc.hidden_at IS NULL) AS reply_count,
+			c.deleted_at
+		FROM tree
+		JOIN comments c ON c.id = tree.id
+		LEFT JOIN users u ON c.author_id = u.id
+		ORDER BY tree.depth, c.created_at, c.id`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comment tree: %w", err)
+	}
+	defer rows.Close()
+
+	tree := &entity.CommentTree{Comments: []*entity.Comment{}}
+	nodes := make(map[int64]*entity.Comment)
+	for rows.Next() {
+		comment := &entity.Comment{Author: &entity.User{}}
+		if err := rows.Scan(
+			&comment.ID,
+			&comment.Content,
+			&comment.ContentHTML,
+			&comment.AuthorID,
+			&comment.TopicID,
+			&comment.ParentID,
+			&comment.Likes,
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+			&comment.EditedAt,
+			&comment.Author.Username,
+			&comment.Author.Avatar,
+			&comment.Depth,
+			&comment.ReplyCount,
+			&comment.DeletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comment.Author.ID = comment.AuthorID
+		comment.Author.Avatar = entity.AvatarURL(comment.AuthorID, comment.Author.Avatar)
+		comment.Edited = comment.EditedAt != nil
+		comment.MaskDeleted()
+		nodes[comment.ID] = comment
+
+		// Строки упорядочены по глубине, поэтому родитель всегда уже прочитан
+		if comment.Depth == 1 {
+			tree.Comments = append(tree.Comments, comment)
+		} else if parent, ok := nodes[*comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, comment)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment tree: %w", err)
+	}
+
+	if len(tree.Comments) > limit {
+		tree.Comments = tree.Comments[:limit]
+		tree.NextCursor = repliesCursor(tree.Comments[limit-1])
+	}
+	for _, comment := range nodes {
+		if n := len(comment.Replies); n > 0 && n < comment.ReplyCount {
+			comment.RepliesCursor = repliesCursor(comment.Replies[n-1])
+		}
+	}
+
+	return tree, nil
 }
 
-func (r *commentRepository) LikeComment(ctx context.Context, id int64) error {
-	_, err := r.db.ExecContext(ctx, `
-		UPDATE comments
-		SET likes = likes + 1
-		WHERE id = $1
-	`, id)
-	return err
+func repliesCursor(last *entity.Comment) string {
+	return encodeCursor(repliesCursorKey, last.CreatedAt.Format(time.RFC3339Nano), last.ID)
 }
@@ -18,7 +18,7 @@ func newTestCommentRepo(t *testing.T) (CommentRepository, sqlmock.Sqlmock, func(
 	if err != nil {
 		t.Fatalf("failed to create sqlmock: %v", err)
 	}
-	repo := &commentRepository{db: db}
+	repo := NewCommentRepository(db)
 	return repo, mock, func() { db.Close() }
 }
 
@@ -107,6 +107,20 @@ func TestCommentRepository_GetCommentByID(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCommentRepository_GetCommentByID_HiddenTopic(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	// Комментарии скрытой или еще не опубликованной темы не видны
+	mock.ExpectQuery(`JOIN topics t ON t.id = c.topic_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL\s+LEFT JOIN users u ON c.author_id = u.id\s+WHERE c.id = \$1 AND c.hidden_at IS NULL`).
+		WithArgs(int64(1)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.GetCommentByID(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCommentRepository_CreateComment(t *testing.T) {
 	repo, mock, closeFn := newTestCommentRepo(t)
 	defer closeFn()
@@ -122,10 +136,15 @@ func TestCommentRepository_CreateComment(t *testing.T) {
 		UpdatedAt: now,
 	}
 
-	// Ожидаем, что будет выполнен запрос на вставку
+	// Комментарий и подписка автора сохраняются в одной транзакции
+	mock.ExpectBegin()
 	mock.ExpectQuery(`INSERT INTO comments`).
-		WithArgs(comment.Content, comment.AuthorID, comment.TopicID, comment.ParentID, comment.CreatedAt, comment.UpdatedAt).
+		WithArgs(comment.Content, "<p>Test comment</p>", comment.AuthorID, comment.TopicID, comment.ParentID, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO topic_subscriptions`).
+		WithArgs(comment.AuthorID, comment.TopicID, entity.WatchLevelTracking).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	// Вызываем тестируемый метод
 	err := repo.CreateComment(context.Background(), comment)
@@ -136,20 +155,175 @@ func TestCommentRepository_CreateComment(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCommentRepository_CreateComment_SubscribeError(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	// Без подписки автора комментарий не сохраняется
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO comments`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO topic_subscriptions`).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	err := repo.CreateComment(context.Background(), &entity.Comment{Content: "Reply", AuthorID: 1, TopicID: 1})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_CreateComment_Rejected(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	// Тема закрыта: вставка ничего не вернула, но тема на месте
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO comments (.+) NOT \(locked AND`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM topics WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NULL)`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	err := repo.CreateComment(context.Background(), &entity.Comment{Content: "Late reply", AuthorID: 1, TopicID: 1})
+	assert.ErrorIs(t, err, ErrTopicLocked)
+
+	// Тема удалена
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO comments`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM topics WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NULL)`)).
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+	err = repo.CreateComment(context.Background(), &entity.Comment{Content: "Reply", AuthorID: 1, TopicID: 2})
+	assert.ErrorIs(t, err, ErrTopicNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_UpdateComment(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT content, author_id FROM comments WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"content", "author_id"}).AddRow("Original comment", 2))
+	mock.ExpectExec(`INSERT INTO comment_revisions \(comment_id, revision, editor_id, content\)\s+SELECT \$1, COALESCE\(MAX\(revision\), 0\) \+ 1, NULLIF\(\$2::bigint, 0\), \$3 FROM comment_revisions WHERE comment_id = \$1`).
+		WithArgs(int64(1), int64(3), "Original comment").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE comments\s+SET content = \$1, content_html = \$2, updated_at = \$3, edited_at = \$3\s+WHERE id = \$4`).
+		WithArgs("Edited *comment*", "<p>Edited <em>comment</em></p>", sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM mentions WHERE target_type = \$1 AND target_id = \$2`).
+		WithArgs(entity.MentionTargetComment, int64(1), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	comment := &entity.Comment{ID: 1, Content: "Edited *comment*"}
+	err := repo.UpdateComment(context.Background(), comment, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>Edited <em>comment</em></p>", comment.ContentHTML)
+	assert.True(t, comment.Edited)
+	assert.NotNil(t, comment.EditedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Комментарий не найден или удален
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT content, author_id FROM comments`).
+		WithArgs(int64(2)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	err = repo.UpdateComment(context.Background(), &entity.Comment{ID: 2, Content: "Edited comment"}, 3)
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCommentRepository_DeleteComment(t *testing.T) {
 	repo, mock, closeFn := newTestCommentRepo(t)
 	defer closeFn()
 
-	// Ожидаем, что будет выполнен запрос на удаление комментария
-	mock.ExpectExec(`DELETE FROM comments WHERE id = \$1`).
-		WithArgs(1).
+	// Комментарий не удаляется, а помечается удаленным, и тема пересчитывает
+	// комментарии в той же транзакции
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE comments SET deleted_at = CURRENT_TIMESTAMP, deleted_by = NULLIF\(\$2, 0\) WHERE id = \$1 AND deleted_at IS NULL RETURNING topic_id`).
+		WithArgs(1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"topic_id"}).AddRow(3))
+	mock.ExpectExec(`UPDATE topics\s+SET comment_count = \(\s+SELECT COUNT\(\*\)\s+FROM comments\s+WHERE topic_id = \$1 AND deleted_at IS NULL`).
+		WithArgs(int64(3)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// Повторное удаление не находит комментарий
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE comments SET deleted_at`).
+		WithArgs(1, 5).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
 
 	// Вызываем тестируемый метод
-	err := repo.DeleteComment(context.Background(), 1)
+	err := repo.DeleteComment(context.Background(), 1, 5)
+	assert.NoError(t, err)
 
-	// Проверяем результаты
+	err = repo.DeleteComment(context.Background(), 1, 5)
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_RestoreComment(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE comments SET deleted_at = NULL, deleted_by = NULL WHERE id = \$1 AND deleted_at IS NOT NULL RETURNING topic_id`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"topic_id"}).AddRow(3))
+	mock.ExpectExec(`UPDATE topics\s+SET comment_count`).
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE comments SET deleted_at = NULL`).
+		WithArgs(2).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	assert.NoError(t, repo.RestoreComment(context.Background(), 1))
+	assert.ErrorIs(t, repo.RestoreComment(context.Background(), 2), ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_DeleteComment_RecountError(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	// Без пересчета удаление откатывается
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE comments SET deleted_at`).
+		WithArgs(1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"topic_id"}).AddRow(3))
+	mock.ExpectExec(`UPDATE topics\s+SET comment_count`).
+		WithArgs(int64(3)).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	err := repo.DeleteComment(context.Background(), 1, 5)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_PurgeDeletedComments(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	// Комментарии с живыми ответами остаются заглушками
+	before := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`DELETE FROM comments c WHERE c.deleted_at < \$1 AND NOT EXISTS`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	purged, err := repo.PurgeDeletedComments(context.Background(), before)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -157,16 +331,153 @@ func TestCommentRepository_LikeComment(t *testing.T) {
 	repo, mock, closeFn := newTestCommentRepo(t)
 	defer closeFn()
 
-	// Ожидаем, что будет выполнен запрос на увеличение количества лайков
-	mock.ExpectExec(`UPDATE comments SET likes = likes \+ 1 WHERE id = \$1`).
-		WithArgs(1).
+	// Первый лайк увеличивает счетчик в той же транзакции
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT likes FROM comments WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"likes"}).AddRow(2))
+	mock.ExpectExec(`INSERT INTO comment_likes \(comment_id, user_id\) VALUES \(\$1, \$2\) ON CONFLICT DO NOTHING`).
+		WithArgs(int64(1), int64(7)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE comments SET likes = GREATEST\(likes \+ \$1, 0\) WHERE id = \$2 RETURNING likes`).
+		WithArgs(1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"likes"}).AddRow(3))
+	mock.ExpectCommit()
 
-	// Вызываем тестируемый метод
-	err := repo.LikeComment(context.Background(), 1)
+	likes, liked, err := repo.LikeComment(context.Background(), 1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, likes)
+	assert.True(t, liked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
 
-	// Проверяем результаты
+func TestCommentRepository_LikeComment_AlreadyLiked(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	// Повторный лайк не трогает счетчик
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT likes FROM comments WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"likes"}).AddRow(3))
+	mock.ExpectExec(`INSERT INTO comment_likes`).
+		WithArgs(int64(1), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	likes, liked, err := repo.LikeComment(context.Background(), 1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, likes)
+	assert.False(t, liked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_LikeComment_NotFound(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT likes FROM comments WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, _, err := repo.LikeComment(context.Background(), 1, 7)
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_LikeComment_ExecError(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT likes FROM comments`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"likes"}).AddRow(0))
+	mock.ExpectExec(`INSERT INTO comment_likes`).
+		WithArgs(int64(1), int64(7)).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	_, _, err := repo.LikeComment(context.Background(), 1, 7)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_UnlikeComment(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT likes FROM comments WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"likes"}).AddRow(3))
+	mock.ExpectExec(`DELETE FROM comment_likes WHERE comment_id = \$1 AND user_id = \$2`).
+		WithArgs(int64(1), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE comments SET likes = GREATEST\(likes \+ \$1, 0\)`).
+		WithArgs(-1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"likes"}).AddRow(2))
+	mock.ExpectCommit()
+
+	likes, err := repo.UnlikeComment(context.Background(), 1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, likes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_GetCommentLikes(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"user_id", "username", "avatar", "created_at"}).
+		AddRow(3, "carol", "", now).
+		AddRow(2, "bob", "", now.Add(-time.Minute)).
+		AddRow(1, "alice", "", now.Add(-2*time.Minute))
+	mock.ExpectQuery(`FROM comment_likes cl\s+LEFT JOIN users u ON u.id = cl.user_id\s+WHERE cl.comment_id = \$1\s+ORDER BY cl.created_at DESC, cl.user_id DESC\s+LIMIT \$2`).
+		WithArgs(int64(5), 3).
+		WillReturnRows(rows)
+
+	page, err := repo.GetCommentLikes(context.Background(), 5, "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, page.Likes, 2)
+	assert.Equal(t, "bob", page.Likes[1].Username)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// Следующая страница начинается после последнего лайка
+	cursor, err := decodeCursor(page.NextCursor, likesCursorKey)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cursor.ID)
+	mock.ExpectQuery(`WHERE cl.comment_id = \$1 AND \(cl.created_at, cl.user_id\) < \(\$2, \$3\)`).
+		WithArgs(int64(5), sqlmock.AnyArg(), int64(2), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "avatar", "created_at"}).
+			AddRow(1, "alice", "", now.Add(-2*time.Minute)))
+
+	page, err = repo.GetCommentLikes(context.Background(), 5, page.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page.Likes, 1)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_GetLikedCommentIDs(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`SELECT comment_id FROM comment_likes WHERE user_id = \$1 AND comment_id = ANY\(\$2\)`).
+		WithArgs(int64(7), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"comment_id"}).AddRow(2))
+
+	liked, err := repo.GetLikedCommentIDs(context.Background(), 7, []int64{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]bool{2: true}, liked)
+
+	// Для анонимного пользователя запрос не выполняется
+	liked, err = repo.GetLikedCommentIDs(context.Background(), 0, []int64{1, 2})
+	assert.NoError(t, err)
+	assert.Empty(t, liked)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -214,34 +525,19 @@ func TestCommentRepository_CreateComment_QueryRowError(t *testing.T) {
 		TopicID:  1,
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`
 		INSERT INTO comments (content, author_id, topic_id, parent_id, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
 	`)).
-		WithArgs(comment.Content, comment.AuthorID, comment.TopicID, comment.ParentID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(comment.Content, sqlmock.AnyArg(), comment.AuthorID, comment.TopicID, comment.ParentID, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(assert.AnError)
 
 	err := repo.CreateComment(context.Background(), comment)
 	assert.Error(t, err)
 }
 
-func TestCommentRepository_LikeComment_ExecError(t *testing.T) {
-	repo, mock, closeFn := newTestCommentRepo(t)
-	defer closeFn()
-
-	mock.ExpectExec(regexp.QuoteMeta(`
-		UPDATE comments
-		SET likes = likes + 1
-		WHERE id = $1
-	`)).
-		WithArgs(int64(1)).
-		WillReturnError(assert.AnError)
-
-	err := repo.LikeComment(context.Background(), 1)
-	assert.Error(t, err)
-}
-
 func TestCommentRepository_GetCommentsByTopic_ScanError(t *testing.T) {
 	repo, mock, closeFn := newTestCommentRepo(t)
 	defer closeFn()
@@ -260,3 +556,77 @@ func TestCommentRepository_GetCommentsByTopic_ScanError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, comments)
 }
+
+func TestCommentRepository_GetCommentTree(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	one, two := int64(1), int64(2)
+	columns := []string{"id", "content", "content_html", "author_id", "topic_id", "parent_id", "likes", "created_at", "updated_at", "edited_at", "username", "avatar", "depth", "reply_count", "deleted_at"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "root", "<p>root</p>", 1, 7, nil, 0, now, now, nil, "alice", "", 1, 3, nil).
+		AddRow(4, "second root", "<p>second root</p>", 1, 7, nil, 0, now.Add(time.Minute), now, nil, "alice", "", 1, 0, nil).
+		AddRow(5, "extra root", "<p>extra root</p>", 1, 7, nil, 0, now.Add(2*time.Minute), now, nil, "alice", "", 1, 0, nil).
+		AddRow(2, "reply", "<p>reply</p>", 2, 7, one, 2, now, now, now, "bob", "", 2, 1, now).
+		AddRow(3, "second reply", "<p>second reply</p>", 2, 7, one, 0, now.Add(time.Second), now, nil, "bob", "", 2, 0, nil).
+		AddRow(6, "nested reply", "<p>nested reply</p>", 1, 7, two, 0, now, now, nil, "alice", "", 3, 0, nil)
+
+	mock.ExpectQuery(`WITH RECURSIVE tree AS .*c\.topic_id = \$1 AND c\.hidden_at IS NULL AND EXISTS \(SELECT 1 FROM topics t WHERE t\.id = \$1 AND t\.hidden_at IS NULL AND t\.deleted_at IS NULL AND t\.publish_at IS NULL\) AND c\.parent_id IS NULL`).
+		WithArgs(int64(7), 3, 2).
+		WillReturnRows(rows)
+
+	tree, err := repo.GetCommentTree(context.Background(), entity.CommentTreeQuery{TopicID: 7, Depth: 3, Limit: 2})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	if assert.Len(t, tree.Comments, 2) {
+		root := tree.Comments[0]
+		assert.Equal(t, int64(1), root.ID)
+		assert.Equal(t, 3, root.ReplyCount)
+		if assert.Len(t, root.Replies, 2) {
+			// Удаленный ответ остается заглушкой, а его ответы видны
+			deleted := root.Replies[0]
+			assert.Equal(t, int64(2), deleted.ID)
+			assert.True(t, deleted.Deleted)
+			assert.Equal(t, entity.DeletedCommentPlaceholder, deleted.Content)
+			assert.Equal(t, entity.DeletedCommentPlaceholder, deleted.ContentHTML)
+			assert.Zero(t, deleted.AuthorID)
+			assert.Zero(t, deleted.Likes)
+			assert.Len(t, deleted.Replies, 1)
+			assert.Empty(t, root.Replies[0].RepliesCursor)
+		}
+		assert.NotEmpty(t, root.RepliesCursor)
+		assert.Equal(t, int64(4), tree.Comments[1].ID)
+	}
+	assert.NotEmpty(t, tree.NextCursor)
+}
+
+func TestCommentRepository_GetCommentTree_Replies(t *testing.T) {
+	repo, mock, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	now := time.Now().UTC()
+	parentID := int64(1)
+	cursor := encodeCursor(repliesCursorKey, now.Format(time.RFC3339Nano), 3)
+	columns := []string{"id", "content", "content_html", "author_id", "topic_id", "parent_id", "likes", "created_at", "updated_at", "edited_at", "username", "avatar", "depth", "reply_count", "deleted_at"}
+
+	mock.ExpectQuery(`c\.parent_id = \$4 AND \(c\.created_at, c\.id\) > \(\$5, \$6\)`).
+		WithArgs(int64(7), entity.DefaultCommentTreeDepth, entity.DefaultRepliesPerNode, parentID, now, int64(3)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(8, "late reply", "<p>late reply</p>", 2, 7, parentID, 0, now.Add(time.Hour), now, nil, "bob", "", 1, 0, nil))
+
+	tree, err := repo.GetCommentTree(context.Background(), entity.CommentTreeQuery{TopicID: 7, ParentID: &parentID, Cursor: cursor})
+	assert.NoError(t, err)
+	assert.Len(t, tree.Comments, 1)
+	assert.Empty(t, tree.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCommentRepository_GetCommentTree_InvalidCursor(t *testing.T) {
+	repo, _, closeFn := newTestCommentRepo(t)
+	defer closeFn()
+
+	_, err := repo.GetCommentTree(context.Background(), entity.CommentTreeQuery{TopicID: 7, Cursor: "garbage"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
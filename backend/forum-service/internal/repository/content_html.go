@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/markup"
+)
+
+// contentHTMLBatchSize is how many rows RenderMissingHTML renders per query
+const contentHTMLBatchSize = 200
+
+// RenderMissingHTML fills in content_html for rows saved before it existed,
+// or whose HTML a migration reset, and returns how many rows were rendered.
+// New and edited rows get their HTML from the repositories, so only old rows
+// are touched. Mentions in old
+// rows are linked but not recorded.
+func RenderMissingHTML(ctx context.Context, db *sql.DB) (int64, error) {
+	sources := []struct {
+		table    string
+		renderer markup.Renderer
+	}{
+		{"topics", markup.NewPostRenderer()},
+		{"comments", markup.NewPostRenderer()},
+		{"chat_messages", markup.NewChatRenderer()},
+	}
+
+	var total int64
+	for _, source := range sources {
+		n, err := renderMissingHTML(ctx, db, source.table, source.renderer)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func renderMissingHTML(ctx context.Context, db *sql.DB, table string, renderer markup.Renderer) (int64, error) {
+	var rendered, lastID int64
+	for {
+		rows, err := db.QueryContext(ctx,
+			`SELECT id, content FROM `+table+` WHERE content_html IS NULL AND id > $1 ORDER BY id LIMIT $2`,
+			lastID, contentHTMLBatchSize,
+		)
+		if err != nil {
+			return rendered, fmt.Errorf("failed to query %s without html: %w", table, err)
+		}
+		type row struct {
+			id      int64
+			content string
+		}
+		var batch []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.content); err != nil {
+				rows.Close()
+				return rendered, fmt.Errorf("failed to scan %s: %w", table, err)
+			}
+			batch = append(batch, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rendered, fmt.Errorf("error iterating %s: %w", table, err)
+		}
+		if len(batch) == 0 {
+			return rendered, nil
+		}
+
+		for _, r := range batch {
+			contentHTML, _, err := renderContent(ctx, db, renderer, r.content)
+			if err != nil {
+				return rendered, err
+			}
+			// Строку могли отредактировать после выборки, тогда HTML уже есть
+			_, err = db.ExecContext(ctx,
+				`UPDATE `+table+` SET content_html = $1 WHERE id = $2 AND content_html IS NULL`,
+				contentHTML, r.id,
+			)
+			if err != nil {
+				return rendered, fmt.Errorf("failed to save html of %s: %w", table, err)
+			}
+			rendered++
+			lastID = r.id
+		}
+	}
+}
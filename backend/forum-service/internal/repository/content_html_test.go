@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMissingHTML(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	// Темы рендерятся пачками, пока не кончатся строки без HTML
+	mock.ExpectQuery(`SELECT id, content FROM topics WHERE content_html IS NULL AND id > \$1 ORDER BY id LIMIT \$2`).
+		WithArgs(int64(0), contentHTMLBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}).AddRow(3, "**bold**"))
+	mock.ExpectExec(`UPDATE topics SET content_html = \$1 WHERE id = \$2 AND content_html IS NULL`).
+		WithArgs("<p><strong>bold</strong></p>", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, content FROM topics`).
+		WithArgs(int64(3), contentHTMLBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}))
+
+	mock.ExpectQuery(`SELECT id, content FROM comments`).
+		WithArgs(int64(0), contentHTMLBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}))
+
+	// В чате блочная разметка не сохраняется
+	mock.ExpectQuery(`SELECT id, content FROM chat_messages`).
+		WithArgs(int64(0), contentHTMLBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}).AddRow(8, "# hi"))
+	mock.ExpectExec(`UPDATE chat_messages SET content_html`).
+		WithArgs("hi", int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, content FROM chat_messages`).
+		WithArgs(int64(8), contentHTMLBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}))
+
+	rendered, err := RenderMissingHTML(context.Background(), db)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rendered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
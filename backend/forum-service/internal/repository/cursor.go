@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor points at the last row of a page in a keyset-paginated listing.
+// Key identifies the ordering the cursor was issued for, so a cursor from one
+// sort mode can't be replayed against another. Rank is set for listings that
+// keep some rows on top regardless of Value.
+type pageCursor struct {
+	Key   string `json:"k"`
+	Rank  int    `json:"r,omitempty"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func encodeCursor(key, value string, id int64) string {
+	return encodeRankedCursor(key, 0, value, id)
+}
+
+func encodeRankedCursor(key string, rank int, value string, id int64) string {
+	data, _ := json.Marshal(pageCursor{Key: key, Rank: rank, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw, key string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Key != key || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/markup"
+)
+
+type DraftRepository interface {
+	SaveDraft(ctx context.Context, draft *entity.Draft) error
+	GetDraft(ctx context.Context, userID, id int64) (*entity.Draft, error)
+	GetDrafts(ctx context.Context, filter entity.DraftFilter) (*entity.DraftPage, error)
+	DeleteDraft(ctx context.Context, userID, id int64) error
+	PublishTopic(ctx context.Context, draft *entity.Draft, topic *entity.Topic) error
+	PublishComment(ctx context.Context, draft *entity.Draft, comment *entity.Comment) error
+	PurgeExpiredDrafts(ctx context.Context, now time.Time) (int64, error)
+}
+
+var (
+	ErrDraftNotFound = errors.New("draft not found")
+	ErrDraftConflict = errors.New("draft was changed elsewhere")
+)
+
+const draftsCursorKey = "drafts"
+
+// draftColumns are scanned by scanDraft
+const draftColumns = `id, user_id, context, target_id, title, content, COALESCE(category_id, 0), tag_names, version, created_at, updated_at, expires_at`
+
+// draftOpenExpr leaves out drafts that have expired but weren't purged yet
+const draftOpenExpr = `expires_at > CURRENT_TIMESTAMP`
+
+type draftRepository struct {
+	db       *sql.DB
+	renderer markup.Renderer
+}
+
+func NewDraftRepository(db *sql.DB) DraftRepository {
+	return &draftRepository{db: db, renderer: markup.NewPostRenderer()}
+}
+
+func scanDraft(row interface{ Scan(...interface{}) error }) (*entity.Draft, error) {
+	d := &entity.Draft{}
+	var tagNames pq.StringArray
+	err := row.Scan(
+		&d.ID,
+		&d.UserID,
+		&d.Context,
+		&d.TargetID,
+		&d.Title,
+		&d.Content,
+		&d.CategoryID,
+		&tagNames,
+		&d.Version,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+		&d.ExpiresAt,
+	)
+	d.TagNames = []string(tagNames)
+	if d.TagNames == nil {
+		d.TagNames = []string{}
+	}
+	return d, err
+}
+
+// SaveDraft stores the draft of a user for its context and target. Version
+// is the version the text is based on: 0 starts a new draft, anything else
+// has to match the saved draft. On success the draft gets its new version;
+// otherwise ErrDraftConflict is returned and nothing is changed.
+func (r *draftRepository) SaveDraft(ctx context.Context, draft *entity.Draft) error {
+	var categoryID interface{}
+	if draft.CategoryID > 0 {
+		categoryID = draft.CategoryID
+	}
+	tagNames := draft.TagNames
+	if tagNames == nil {
+		tagNames = []string{}
+	}
+
+	var row *sql.Row
+	if draft.Version == 0 {
+		// Новый черновик может заменить только истекший
+		row = r.db.QueryRowContext(ctx, `
+			INSERT INTO drafts (user_id, context, target_id, title, content, category_id, tag_names, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (user_id, context, target_id) DO UPDATE SET
+				title = EXCLUDED.title, content = EXCLUDED.content, category_id = EXCLUDED.category_id,
+				tag_names = EXCLUDED.tag_names, expires_at = EXCLUDED.expires_at,
+				version = drafts.version + 1, created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE drafts.expires_at <= CURRENT_TIMESTAMP
+			RETURNING id, version, created_at, updated_at`,
+			draft.UserID, draft.Context, draft.TargetID, draft.Title, draft.Content, categoryID, pq.Array(tagNames), draft.ExpiresAt,
+		)
+	} else {
+		row = r.db.QueryRowContext(ctx, `
+			UPDATE drafts SET
+				title = $5, content = $6, category_id = $7, tag_names = $8, expires_at = $9,
+				version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1 AND context = $2 AND target_id = $3 AND version = $4 AND `+draftOpenExpr+`
+			RETURNING id, version, created_at, updated_at`,
+			draft.UserID, draft.Context, draft.TargetID, draft.Version,
+			draft.Title, draft.Content, categoryID, pq.Array(tagNames), draft.ExpiresAt,
+		)
+	}
+
+	err := row.Scan(&draft.ID, &draft.Version, &draft.CreatedAt, &draft.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrDraftConflict
+		}
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+	draft.TagNames = tagNames
+	return nil
+}
+
+func (r *draftRepository) GetDraft(ctx context.Context, userID, id int64) (*entity.Draft, error) {
+	draft, err := scanDraft(r.db.QueryRowContext(ctx,
+		`SELECT `+draftColumns+` FROM drafts
+		WHERE id = $1 AND user_id = $2 AND `+draftOpenExpr,
+		id, userID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrDraftNotFound
+		}
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+	return draft, nil
+}
+
+// GetDrafts returns a page of a user's open drafts, most recently saved
+// first, optionally only those of one context and target
+func (r *draftRepository) GetDrafts(ctx context.Context, filter entity.DraftFilter) (*entity.DraftPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = entity.DefaultDraftPageSize
+	}
+	if limit > entity.MaxDraftPageSize {
+		limit = entity.MaxDraftPageSize
+	}
+
+	args := []interface{}{filter.UserID}
+	conditions := "user_id = $1 AND " + draftOpenExpr
+	if filter.Context != "" {
+		args = append(args, filter.Context, filter.TargetID)
+		conditions += fmt.Sprintf(" AND context = $%d AND target_id = $%d", len(args)-1, len(args))
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor, draftsCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		before, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, before, cursor.ID)
+		conditions += fmt.Sprintf(" AND (updated_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM drafts
+		WHERE %s
+		ORDER BY updated_at DESC, id DESC
+		LIMIT $%d`, draftColumns, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drafts: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.DraftPage{Drafts: []*entity.Draft{}}
+	for rows.Next() {
+		draft, err := scanDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		page.Drafts = append(page.Drafts, draft)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating drafts: %w", err)
+	}
+
+	if len(page.Drafts) > limit {
+		page.Drafts = page.Drafts[:limit]
+		last := page.Drafts[limit-1]
+		page.NextCursor = encodeCursor(draftsCursorKey, last.UpdatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	return page, nil
+}
+
+func (r *draftRepository) DeleteDraft(ctx context.Context, userID, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM drafts WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrDraftNotFound
+	}
+	return nil
+}
+
+// PublishTopic creates the topic written in a draft together with its tags
+// and deletes the draft in one transaction. TagNames must be normalized by
+// the caller.
+func (r *draftRepository) PublishTopic(ctx context.Context, draft *entity.Draft, topic *entity.Topic) error {
+	return r.publish(ctx, draft, func(tx *sql.Tx) error {
+		return insertTopic(ctx, tx, r.renderer, topic)
+	})
+}
+
+// PublishComment creates the comment written in a draft and deletes the
+// draft in one transaction
+func (r *draftRepository) PublishComment(ctx context.Context, draft *entity.Draft, comment *entity.Comment) error {
+	return r.publish(ctx, draft, func(tx *sql.Tx) error {
+		return insertComment(ctx, tx, r.renderer, comment)
+	})
+}
+
+// publish deletes the draft at the version it was read and runs insert in
+// the same transaction. A draft saved again in the meantime is kept and
+// ErrDraftConflict is returned.
+func (r *draftRepository) publish(ctx context.Context, draft *entity.Draft, insert func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM drafts WHERE id = $1 AND user_id = $2 AND version = $3 AND `+draftOpenExpr,
+		draft.ID, draft.UserID, draft.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrDraftConflict
+	}
+
+	if err := insert(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredDrafts deletes the drafts that expired before now
+func (r *draftRepository) PurgeExpiredDrafts(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM drafts WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired drafts: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var draftTestColumns = []string{"id", "user_id", "context", "target_id", "title", "content", "category_id",
+	"tag_names", "version", "created_at", "updated_at", "expires_at"}
+
+func newTestDraftRepo(t *testing.T) (DraftRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewDraftRepository(db), mock, func() { db.Close() }
+}
+
+func TestDraftRepository_SaveDraft(t *testing.T) {
+	repo, mock, closeFn := newTestDraftRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	expires := now.Add(30 * 24 * time.Hour)
+
+	// Первое сохранение создает черновик
+	draft := &entity.Draft{UserID: 2, Context: entity.DraftContextTopic, Title: "Go", Content: "draft", CategoryID: 3, ExpiresAt: expires}
+	mock.ExpectQuery(`INSERT INTO drafts[\s\S]+ON CONFLICT \(user_id, context, target_id\) DO UPDATE[\s\S]+WHERE drafts.expires_at <= CURRENT_TIMESTAMP`).
+		WithArgs(int64(2), entity.DraftContextTopic, int64(0), "Go", "draft", int64(3), pq.Array([]string{}), expires).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "created_at", "updated_at"}).AddRow(7, 1, now, now))
+	assert.NoError(t, repo.SaveDraft(context.Background(), draft))
+	assert.Equal(t, int64(7), draft.ID)
+	assert.Equal(t, 1, draft.Version)
+
+	// Следующее сохранение опирается на версию 1
+	draft.Content = "draft, longer"
+	mock.ExpectQuery(`UPDATE drafts SET[\s\S]+WHERE user_id = \$1 AND context = \$2 AND target_id = \$3 AND version = \$4`).
+		WithArgs(int64(2), entity.DraftContextTopic, int64(0), 1, "Go", "draft, longer", int64(3), pq.Array([]string{}), expires).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "created_at", "updated_at"}).AddRow(7, 2, now, now))
+	assert.NoError(t, repo.SaveDraft(context.Background(), draft))
+	assert.Equal(t, 2, draft.Version)
+
+	// Вкладка со старой версией ничего не перезаписывает
+	stale := &entity.Draft{UserID: 2, Context: entity.DraftContextTopic, Content: "old", Version: 1, ExpiresAt: expires}
+	mock.ExpectQuery(`UPDATE drafts SET`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "created_at", "updated_at"}))
+	assert.ErrorIs(t, repo.SaveDraft(context.Background(), stale), ErrDraftConflict)
+
+	// Как и вторая вкладка, начавшая новый черновик
+	mock.ExpectQuery(`INSERT INTO drafts`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "created_at", "updated_at"}))
+	stale.Version = 0
+	assert.ErrorIs(t, repo.SaveDraft(context.Background(), stale), ErrDraftConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDraftRepository_GetDrafts(t *testing.T) {
+	repo, mock, closeFn := newTestDraftRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Hour)
+	mock.ExpectQuery(`FROM drafts\s+WHERE user_id = \$1 AND expires_at > CURRENT_TIMESTAMP\s+ORDER BY updated_at DESC, id DESC\s+LIMIT \$2`).
+		WithArgs(int64(2), 2).
+		WillReturnRows(sqlmock.NewRows(draftTestColumns).
+			AddRow(8, 2, "topic_reply", 4, "", "reply", 0, "{}", 3, now, now, expires).
+			AddRow(7, 2, "topic", 0, "Go", "draft", 3, "{go,tips}", 1, now, now.Add(-time.Minute), expires))
+
+	page, err := repo.GetDrafts(context.Background(), entity.DraftFilter{UserID: 2, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Drafts, 1)
+	assert.Equal(t, entity.DraftContextTopicReply, page.Drafts[0].Context)
+	assert.Equal(t, []string{}, page.Drafts[0].TagNames)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mock.ExpectQuery(`AND context = \$2 AND target_id = \$3 AND \(updated_at, id\) < \(\$4, \$5\)`).
+		WithArgs(int64(2), entity.DraftContextTopic, int64(0), now, int64(8), entity.DefaultDraftPageSize+1).
+		WillReturnRows(sqlmock.NewRows(draftTestColumns).
+			AddRow(7, 2, "topic", 0, "Go", "draft", 3, "{go,tips}", 1, now, now.Add(-time.Minute), expires))
+	page, err = repo.GetDrafts(context.Background(), entity.DraftFilter{UserID: 2, Context: entity.DraftContextTopic, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "tips"}, page.Drafts[0].TagNames)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = repo.GetDrafts(context.Background(), entity.DraftFilter{UserID: 2, Cursor: encodeCursor(bookmarksCursorKey, now.Format(time.RFC3339Nano), 1)})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDraftRepository_PublishTopic(t *testing.T) {
+	repo, mock, closeFn := newTestDraftRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	draft := &entity.Draft{ID: 7, UserID: 2, Context: entity.DraftContextTopic, Version: 3}
+	topic := &entity.Topic{Title: "Go", Content: "Text", AuthorID: 2, CategoryID: 3, CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM drafts WHERE id = \$1 AND user_id = \$2 AND version = \$3`).
+		WithArgs(int64(7), int64(2), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO topics`).
+		WithArgs("Go", "Text", "<p>Text</p>", int64(2), int64(3), 0, 0, now, now, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(15))
+	mock.ExpectExec(`INSERT INTO topic_subscriptions`).
+		WithArgs(int64(2), int64(15), entity.WatchLevelWatching).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.PublishTopic(context.Background(), draft, topic))
+	assert.Equal(t, int64(15), topic.ID)
+
+	// Черновик, сохраненный в другой вкладке после чтения, не публикуется
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM drafts`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.PublishTopic(context.Background(), draft, &entity.Topic{}), ErrDraftConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDraftRepository_PublishComment_TopicLocked(t *testing.T) {
+	repo, mock, closeFn := newTestDraftRepo(t)
+	defer closeFn()
+
+	// Черновик остается, если тема закрылась, пока его писали
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM drafts`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO comments`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM topics WHERE id = \$1 AND deleted_at IS NULL AND publish_at IS NULL\)`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	draft := &entity.Draft{ID: 8, UserID: 2, Context: entity.DraftContextTopicReply, TargetID: 4, Version: 1}
+	err := repo.PublishComment(context.Background(), draft, &entity.Comment{Content: "Reply", AuthorID: 2, TopicID: 4})
+	assert.ErrorIs(t, err, ErrTopicLocked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDraftRepository_PurgeExpiredDrafts(t *testing.T) {
+	repo, mock, closeFn := newTestDraftRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`DELETE FROM drafts WHERE expires_at <= \$1`).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	n, err := repo.PurgeExpiredDrafts(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
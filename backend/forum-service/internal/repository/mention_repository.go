@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/markup"
+)
+
+type MentionRepository interface {
+	SuggestUsers(ctx context.Context, prefix string, limit int) ([]*entity.User, error)
+	GetMentions(ctx context.Context, userID int64, cursor string, limit int) (*entity.MentionPage, error)
+}
+
+const mentionsCursorKey = "mentions"
+
+// dbtx is what the mention helpers need from either *sql.DB or *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type mentionRepository struct {
+	db *sql.DB
+}
+
+func NewMentionRepository(db *sql.DB) MentionRepository {
+	return &mentionRepository{db: db}
+}
+
+// SuggestUsers returns users whose name starts with prefix, ignoring case,
+// shortest names first
+func (r *mentionRepository) SuggestUsers(ctx context.Context, prefix string, limit int) ([]*entity.User, error) {
+	if limit <= 0 {
+		limit = entity.DefaultMentionSuggestions
+	}
+	if limit > entity.MaxMentionSuggestions {
+		limit = entity.MaxMentionSuggestions
+	}
+	// Экранируем спецсимволы LIKE, чтобы префикс искался буквально
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(prefix)) + "%"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, username, COALESCE(avatar, '')
+		FROM users
+		WHERE lower(username) LIKE $1
+		ORDER BY length(username), lower(username), id
+		LIMIT $2`,
+		pattern, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*entity.User{}
+	for rows.Next() {
+		user := &entity.User{}
+		if err := rows.Scan(&user.ID, &user.Username, &user.Avatar); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		user.Avatar = entity.AvatarURL(user.ID, user.Avatar)
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+	return users, nil
+}
+
+// GetMentions returns the mentions of a user in content that is still
+// visible, newest first
+func (r *mentionRepository) GetMentions(ctx context.Context, userID int64, cursor string, limit int) (*entity.MentionPage, error) {
+	if limit <= 0 {
+		limit = entity.DefaultMentionPageSize
+	}
+	if limit > entity.MaxMentionPageSize {
+		limit = entity.MaxMentionPageSize
+	}
+
+	args := []interface{}{userID}
+	conditions := "m.user_id = $1"
+	if cursor != "" {
+		c, err := decodeCursor(cursor, mentionsCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		before, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, before, c.ID)
+		conditions += " AND (m.created_at, m.id) < ($2, $3)"
+	}
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+
+	// Упоминания в скрытом, удаленном или истекшем контенте не показываются
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT m.id, m.target_type, m.target_id, COALESCE(t.id, c.topic_id, 0), m.user_id,
+			COALESCE(m.author_id, 0), COALESCE(u.username, ''), m.created_at
+		FROM mentions m
+		LEFT JOIN topics t ON m.target_type = 'topic' AND t.id = m.target_id
+			AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL
+		LEFT JOIN comments c ON m.target_type = 'comment' AND c.id = m.target_id
+			AND c.hidden_at IS NULL AND c.deleted_at IS NULL
+		LEFT JOIN chat_messages cm ON m.target_type = 'chat_message' AND cm.id = m.target_id
+			AND cm.hidden_at IS NULL AND cm.expires_at > CURRENT_TIMESTAMP
+		LEFT JOIN users u ON u.id = m.author_id
+		WHERE %s AND COALESCE(t.id, c.id, cm.id) IS NOT NULL
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $%d`, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mentions: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.MentionPage{Mentions: []*entity.Mention{}}
+	for rows.Next() {
+		mention := &entity.Mention{}
+		if err := rows.Scan(
+			&mention.ID,
+			&mention.TargetType,
+			&mention.TargetID,
+			&mention.TopicID,
+			&mention.UserID,
+			&mention.AuthorID,
+			&mention.AuthorUsername,
+			&mention.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		page.Mentions = append(page.Mentions, mention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentions: %w", err)
+	}
+
+	if len(page.Mentions) > limit {
+		page.Mentions = page.Mentions[:limit]
+		last := page.Mentions[limit-1]
+		page.NextCursor = encodeCursor(mentionsCursorKey, last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	return page, nil
+}
+
+// renderContent renders source with links for the users it mentions and
+// returns those users. Only the first entity.MaxMentionsPerPost mentions
+// are looked up.
+func renderContent(ctx context.Context, q dbtx, renderer markup.Renderer, source string) (string, []*entity.User, error) {
+	mentioned, err := resolveMentions(ctx, q, renderer.Mentions(source))
+	if err != nil {
+		return "", nil, err
+	}
+	users := make([]markup.MentionedUser, len(mentioned))
+	for i, user := range mentioned {
+		users[i] = markup.MentionedUser{ID: user.ID, Username: user.Username}
+	}
+	html, err := renderer.Render(source, users)
+	if err != nil {
+		return "", nil, err
+	}
+	return html, mentioned, nil
+}
+
+// resolveMentions finds the users with the given names, ignoring case. When
+// two users differ only in case, the older account wins.
+func resolveMentions(ctx context.Context, q dbtx, usernames []string) ([]*entity.User, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+	if len(usernames) > entity.MaxMentionsPerPost {
+		usernames = usernames[:entity.MaxMentionsPerPost]
+	}
+	lowered := make([]string, len(usernames))
+	for i, username := range usernames {
+		lowered[i] = strings.ToLower(username)
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT DISTINCT ON (lower(username)) id, username
+		FROM users
+		WHERE lower(username) = ANY($1)
+		ORDER BY lower(username), id`,
+		pq.Array(lowered),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mentions: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*entity.User
+	for rows.Next() {
+		user := &entity.User{}
+		if err := rows.Scan(&user.ID, &user.Username); err != nil {
+			return nil, fmt.Errorf("failed to scan mentioned user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentioned users: %w", err)
+	}
+	return users, nil
+}
+
+// saveMentions makes users the mentions of a post and returns the IDs of the
+// users that weren't mentioned in it before. Mentions that were removed by
+// an edit are dropped when replace is set.
+func saveMentions(ctx context.Context, q dbtx, targetType entity.MentionTargetType, targetID, authorID int64, users []*entity.User, replace bool) ([]int64, error) {
+	ids := make([]int64, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+
+	if replace {
+		_, err := q.ExecContext(ctx,
+			`DELETE FROM mentions WHERE target_type = $1 AND target_id = $2 AND NOT (user_id = ANY($3))`,
+			targetType, targetID, pq.Array(ids),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove mentions: %w", err)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		INSERT INTO mentions (target_type, target_id, user_id, author_id)
+		SELECT $1, $2, user_id, NULLIF($4::bigint, 0) FROM unnest($3::bigint[]) AS user_id
+		ON CONFLICT (target_type, target_id, user_id) DO NOTHING
+		RETURNING user_id`,
+		targetType, targetID, pq.Array(ids), authorID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save mentions: %w", err)
+	}
+	defer rows.Close()
+
+	var added []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		added = append(added, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentions: %w", err)
+	}
+	return added, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMentionRepo(t *testing.T) (MentionRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewMentionRepository(db), mock, func() { db.Close() }
+}
+
+func TestMentionRepository_SuggestUsers(t *testing.T) {
+	repo, mock, closeFn := newTestMentionRepo(t)
+	defer closeFn()
+
+	// Подчеркивание в префиксе ищется буквально
+	mock.ExpectQuery(`SELECT id, username, COALESCE\(avatar, ''\)\s+FROM users\s+WHERE lower\(username\) LIKE \$1`).
+		WithArgs(`jo\_d%`, entity.MaxMentionSuggestions).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "avatar"}).
+			AddRow(3, "Jo_D", "").
+			AddRow(7, "jo_doe", "https://example.com/a.png"))
+
+	users, err := repo.SuggestUsers(context.Background(), "Jo_D", 100)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "jo_doe", users[1].Username)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMentionRepository_GetMentions(t *testing.T) {
+	repo, mock, closeFn := newTestMentionRepo(t)
+	defer closeFn()
+
+	created := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	columns := []string{"id", "target_type", "target_id", "topic_id", "user_id", "author_id", "username", "created_at"}
+	mock.ExpectQuery(`FROM mentions m[\s\S]+WHERE m.user_id = \$1 AND COALESCE\(t.id, c.id, cm.id\) IS NOT NULL[\s\S]+LIMIT \$2`).
+		WithArgs(int64(5), 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(9, "comment", 10, 4, 5, 2, "bob", created).
+			AddRow(8, "topic", 4, 4, 5, 2, "bob", created.Add(-time.Minute)))
+
+	page, err := repo.GetMentions(context.Background(), 5, "", 1)
+	assert.NoError(t, err)
+	assert.Len(t, page.Mentions, 1)
+	assert.Equal(t, entity.MentionTargetComment, page.Mentions[0].TargetType)
+	assert.Equal(t, int64(4), page.Mentions[0].TopicID)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// Следующая страница начинается после последнего упоминания
+	mock.ExpectQuery(`AND \(m.created_at, m.id\) < \(\$2, \$3\)`).
+		WithArgs(int64(5), created, int64(9), 2).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	page, err = repo.GetMentions(context.Background(), 5, page.NextCursor, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Mentions)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = repo.GetMentions(context.Background(), 5, "garbage", 1)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_CreateTopic_Mentions(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	topic := &entity.Topic{Title: "Review", Content: "ping @Alice and @ghost", AuthorID: 2, CategoryID: 1}
+
+	// Неизвестные пользователи остаются простым текстом
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT ON (lower(username)) id, username`)).
+		WithArgs(pq.Array([]string{"alice", "ghost"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(3, "alice"))
+	mock.ExpectQuery(`INSERT INTO topics`).
+		WithArgs(topic.Title, topic.Content,
+			`<p>ping <a href="/users/3" class="mention" rel="nofollow">@alice</a> and @ghost</p>`,
+			topic.AuthorID, topic.CategoryID, topic.Views, 0, topic.CreatedAt, topic.UpdatedAt, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`INSERT INTO mentions \(target_type, target_id, user_id, author_id\)[\s\S]+ON CONFLICT`).
+		WithArgs(entity.MentionTargetTopic, int64(1), pq.Array([]int64{3}), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(3))
+	mock.ExpectExec(`INSERT INTO topic_subscriptions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.CreateTopic(context.Background(), topic)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, topic.MentionedIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveMentions_Cap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	names := make([]string, entity.MaxMentionsPerPost+5)
+	for i := range names {
+		names[i] = string(rune('a' + i))
+	}
+	mock.ExpectQuery(`SELECT DISTINCT ON`).
+		WithArgs(pq.Array(names[:entity.MaxMentionsPerPost])).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}))
+
+	users, err := resolveMentions(context.Background(), db, names)
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+
+	// Без упоминаний запрос не выполняется
+	users, err = resolveMentions(context.Background(), db, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -125,6 +125,713 @@ func RunMigrations(db *sql.DB) error {
 		return err
 	}
 
+	// Добавляем колонку last_activity_at и индексы для постраничной выборки тем
+	_, err = db.Exec(`
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+		UPDATE topics SET last_activity_at = created_at WHERE last_activity_at IS NULL;
+		ALTER TABLE topics ALTER COLUMN last_activity_at SET NOT NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_topics_created_at_id ON topics(created_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_views_id ON topics(views DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_comment_count_id ON topics(comment_count DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_last_activity_at_id ON topics(last_activity_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_category_created_at_id ON topics(category_id, created_at DESC, id DESC);
+	`)
+	if err != nil {
+		log.Printf("Error adding topic listing columns and indexes: %v", err)
+		return err
+	}
+
+	// Создаем триггер, обновляющий время последней активности в теме
+	_, err = db.Exec(`
+		CREATE OR REPLACE FUNCTION touch_topic_last_activity()
+		RETURNS TRIGGER AS $$
+		BEGIN
+			UPDATE topics
+			SET last_activity_at = GREATEST(last_activity_at, NEW.created_at)
+			WHERE id = NEW.topic_id;
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS comments_touch_topic_activity ON comments;
+		CREATE TRIGGER comments_touch_topic_activity
+		AFTER INSERT ON comments
+		FOR EACH ROW
+		EXECUTE FUNCTION touch_topic_last_activity();
+	`)
+	if err != nil {
+		log.Printf("Error creating topic activity trigger: %v", err)
+		return err
+	}
+
+	// Добавляем поисковые векторы для полнотекстового поиска
+	_, err = db.Exec(`
+		CREATE OR REPLACE FUNCTION forum_search_vector(title TEXT, body TEXT)
+		RETURNS tsvector AS $$
+			SELECT setweight(to_tsvector('russian', COALESCE(title, '')), 'A') ||
+				setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+				setweight(to_tsvector('russian', COALESCE(body, '')), 'B') ||
+				setweight(to_tsvector('english', COALESCE(body, '')), 'B');
+		$$ LANGUAGE sql IMMUTABLE;
+
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS search_vector tsvector;
+		ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+		UPDATE topics SET search_vector = forum_search_vector(title, content) WHERE search_vector IS NULL;
+		UPDATE comments SET search_vector = forum_search_vector(NULL, content) WHERE search_vector IS NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_topics_search_vector ON topics USING GIN(search_vector);
+		CREATE INDEX IF NOT EXISTS idx_comments_search_vector ON comments USING GIN(search_vector);
+	`)
+	if err != nil {
+		log.Printf("Error adding search vectors: %v", err)
+		return err
+	}
+
+	// Создаем триггеры, обновляющие поисковые векторы
+	_, err = db.Exec(`
+		CREATE OR REPLACE FUNCTION topics_search_vector_update()
+		RETURNS TRIGGER AS $$
+		BEGIN
+			NEW.search_vector := forum_search_vector(NEW.title, NEW.content);
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS topics_search_vector_trigger ON topics;
+		CREATE TRIGGER topics_search_vector_trigger
+		BEFORE INSERT OR UPDATE OF title, content ON topics
+		FOR EACH ROW
+		EXECUTE FUNCTION topics_search_vector_update();
+
+		CREATE OR REPLACE FUNCTION comments_search_vector_update()
+		RETURNS TRIGGER AS $$
+		BEGIN
+			NEW.search_vector := forum_search_vector(NULL, NEW.content);
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS comments_search_vector_trigger ON comments;
+		CREATE TRIGGER comments_search_vector_trigger
+		BEFORE INSERT OR UPDATE OF content ON comments
+		FOR EACH ROW
+		EXECUTE FUNCTION comments_search_vector_update();
+	`)
+	if err != nil {
+		log.Printf("Error creating search vector triggers: %v", err)
+		return err
+	}
+
+	// Создаем таблицу categories, если она не существует
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS categories (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(50) NOT NULL UNIQUE,
+			description TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+
+		ALTER TABLE categories ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+		UPDATE categories SET position = id WHERE position = 0;
+		CREATE INDEX IF NOT EXISTS idx_categories_position ON categories(position, id);
+	`)
+	if err != nil {
+		log.Printf("Error creating categories table: %v", err)
+		return err
+	}
+
+	// Создаем таблицы тегов
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id BIGSERIAL PRIMARY KEY,
+			name VARCHAR(32) NOT NULL UNIQUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS topic_tags (
+			topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+			tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+			PRIMARY KEY (topic_id, tag_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_topic_tags_tag_id ON topic_tags(tag_id);
+	`)
+	if err != nil {
+		log.Printf("Error creating tags tables: %v", err)
+		return err
+	}
+
+	// Создаем индексы для дерева комментариев
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_comments_parent_created_at_id ON comments(parent_id, created_at, id);
+		CREATE INDEX IF NOT EXISTS idx_comments_topic_root_created_at_id ON comments(topic_id, created_at, id) WHERE parent_id IS NULL;
+	`)
+	if err != nil {
+		log.Printf("Error creating comment tree indexes: %v", err)
+		return err
+	}
+
+	// Добавляем отметку о редактировании комментариев
+	_, err = db.Exec(`
+		ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE;
+	`)
+	if err != nil {
+		log.Printf("Error adding comment edited_at column: %v", err)
+		return err
+	}
+
+	// Создаем таблицу лайков комментариев
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS comment_likes (
+			comment_id BIGINT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+			user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (comment_id, user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_comment_likes_comment_created_at ON comment_likes(comment_id, created_at DESC, user_id DESC);
+		CREATE INDEX IF NOT EXISTS idx_comment_likes_user_id ON comment_likes(user_id);
+
+		-- Лайки, поставленные до появления таблицы, не привязаны к
+		-- пользователям; счетчик приводится к числу записей в ней
+		UPDATE comments c SET likes = l.n
+		FROM (
+			SELECT c2.id, COUNT(cl.user_id) AS n
+			FROM comments c2
+			LEFT JOIN comment_likes cl ON cl.comment_id = c2.id
+			GROUP BY c2.id
+		) l
+		WHERE c.id = l.id AND c.likes IS DISTINCT FROM l.n;
+	`)
+	if err != nil {
+		log.Printf("Error creating comment_likes table: %v", err)
+		return err
+	}
+
+	// Создаем таблицы жалоб и очереди модерации
+	_, err = db.Exec(`
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS hidden_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+		ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+		ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS hidden_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+		CREATE TABLE IF NOT EXISTS reports (
+			id BIGSERIAL PRIMARY KEY,
+			target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('topic', 'comment', 'chat_message')),
+			target_id BIGINT NOT NULL,
+			target_author_id BIGINT,
+			content_snapshot TEXT NOT NULL DEFAULT '',
+			reporter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			reason VARCHAR(16) NOT NULL CHECK (reason IN ('spam', 'abuse', 'off_topic', 'other')),
+			details TEXT NOT NULL DEFAULT '',
+			status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'claimed', 'resolved', 'dismissed')),
+			claimed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+			claimed_at TIMESTAMP WITH TIME ZONE,
+			resolved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+			resolved_at TIMESTAMP WITH TIME ZONE,
+			action VARCHAR(16) CHECK (action IN ('hide', 'delete', 'warn')),
+			note TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_active_reporter ON reports(target_type, target_id, reporter_id) WHERE status IN ('open', 'claimed');
+		CREATE INDEX IF NOT EXISTS idx_reports_status_created_at ON reports(status, created_at, id);
+		CREATE INDEX IF NOT EXISTS idx_reports_target ON reports(target_type, target_id);
+
+		CREATE TABLE IF NOT EXISTS user_warnings (
+			id BIGSERIAL PRIMARY KEY,
+			user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			moderator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+			report_id BIGINT REFERENCES reports(id) ON DELETE SET NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_user_warnings_user_id ON user_warnings(user_id);
+	`)
+	if err != nil {
+		log.Printf("Error creating reports tables: %v", err)
+		return err
+	}
+
+	// Добавляем мягкое удаление тем и комментариев
+	_, err = db.Exec(`
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS deleted_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+		ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_topics_deleted_at ON topics(deleted_at) WHERE deleted_at IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(deleted_at) WHERE deleted_at IS NOT NULL;
+	`)
+	if err != nil {
+		log.Printf("Error adding soft delete columns: %v", err)
+		return err
+	}
+
+	// Создаем таблицы истории правок
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS topic_revisions (
+			id BIGSERIAL PRIMARY KEY,
+			topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+			revision INTEGER NOT NULL,
+			editor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+			title VARCHAR(255) NOT NULL,
+			content TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (topic_id, revision)
+		);
+
+		CREATE TABLE IF NOT EXISTS comment_revisions (
+			id BIGSERIAL PRIMARY KEY,
+			comment_id BIGINT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+			revision INTEGER NOT NULL,
+			editor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+			content TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (comment_id, revision)
+		);
+	`)
+	if err != nil {
+		log.Printf("Error creating revision tables: %v", err)
+		return err
+	}
+
+	// Добавляем колонки с отрисованным HTML
+	_, err = db.Exec(`
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS content_html TEXT;
+		ALTER TABLE comments ADD COLUMN IF NOT EXISTS content_html TEXT;
+		ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS content_html TEXT;
+	`)
+	if err != nil {
+		log.Printf("Error adding content_html columns: %v", err)
+		return err
+	}
+
+	// Создаем таблицу упоминаний
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS mentions (
+			id BIGSERIAL PRIMARY KEY,
+			target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('topic', 'comment', 'chat_message')),
+			target_id BIGINT NOT NULL,
+			user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			author_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (target_type, target_id, user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_mentions_user ON mentions(user_id, created_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_users_username_lower ON users(lower(username) text_pattern_ops);
+	`)
+	if err != nil {
+		log.Printf("Error creating mentions table: %v", err)
+		return err
+	}
+
+	// Создаем таблицу уведомлений
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS notifications (
+			id BIGSERIAL PRIMARY KEY,
+			user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			type VARCHAR(20) NOT NULL CHECK (type IN ('topic_reply', 'comment_reply', 'mention', 'like', 'moderation')),
+			target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('topic', 'comment', 'chat_message')),
+			target_id BIGINT NOT NULL,
+			topic_id BIGINT,
+			actor_ids BIGINT[] NOT NULL DEFAULT '{}',
+			action VARCHAR(20) NOT NULL DEFAULT '',
+			note TEXT NOT NULL DEFAULT '',
+			group_key TEXT,
+			read_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_group ON notifications(user_id, group_key) WHERE read_at IS NULL;
+		CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, updated_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+	`)
+	if err != nil {
+		log.Printf("Error creating notifications table: %v", err)
+		return err
+	}
+
+	// Создаем таблицы подписок на темы и настроек подписок. Существующие
+	// авторы и комментаторы подписываются только при создании таблицы
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS watch_settings (
+			user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+			auto_watch BOOLEAN NOT NULL DEFAULT TRUE
+		);
+
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1
+				FROM information_schema.tables
+				WHERE table_schema = current_schema() AND table_name = 'topic_subscriptions'
+			) THEN
+				CREATE TABLE topic_subscriptions (
+					user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+					topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+					level VARCHAR(20) NOT NULL CHECK (level IN ('watching', 'tracking', 'muted')),
+					last_read_comment_id BIGINT NOT NULL DEFAULT 0,
+					created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (user_id, topic_id)
+				);
+
+				INSERT INTO topic_subscriptions (user_id, topic_id, level, last_read_comment_id)
+				SELECT t.author_id, t.id, 'watching', COALESCE((SELECT MAX(c.id) FROM comments c WHERE c.topic_id = t.id), 0)
+				FROM topics t
+				WHERE EXISTS (SELECT 1 FROM users u WHERE u.id = t.author_id)
+					AND NOT EXISTS (SELECT 1 FROM watch_settings w WHERE w.user_id = t.author_id AND NOT w.auto_watch)
+				ON CONFLICT DO NOTHING;
+
+				INSERT INTO topic_subscriptions (user_id, topic_id, level, last_read_comment_id)
+				SELECT DISTINCT c.author_id, c.topic_id, 'tracking', COALESCE((SELECT MAX(l.id) FROM comments l WHERE l.topic_id = c.topic_id), 0)
+				FROM comments c
+				WHERE EXISTS (SELECT 1 FROM users u WHERE u.id = c.author_id)
+					AND NOT EXISTS (SELECT 1 FROM watch_settings w WHERE w.user_id = c.author_id AND NOT w.auto_watch)
+				ON CONFLICT DO NOTHING;
+			END IF;
+		END $$;
+
+		CREATE INDEX IF NOT EXISTS idx_topic_subscriptions_topic ON topic_subscriptions(topic_id, level);
+	`)
+	if err != nil {
+		log.Printf("Error creating topic subscriptions tables: %v", err)
+		return err
+	}
+
+	// Создаем таблицу закладок и разрешаем уведомления-напоминания
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS bookmarks (
+			id BIGSERIAL PRIMARY KEY,
+			user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('topic', 'comment')),
+			target_id BIGINT NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			remind_at TIMESTAMP WITH TIME ZONE,
+			reminded_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, target_type, target_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_bookmarks_user ON bookmarks(user_id, created_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_bookmarks_due ON bookmarks(remind_at) WHERE remind_at IS NOT NULL AND reminded_at IS NULL;
+
+		ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+		ALTER TABLE notifications ADD CONSTRAINT notifications_type_check
+			CHECK (type IN ('topic_reply', 'comment_reply', 'mention', 'like', 'moderation', 'reminder'));
+	`)
+	if err != nil {
+		log.Printf("Error creating bookmarks table: %v", err)
+		return err
+	}
+
+	// Добавляем темам закрепление, закрытие и объявления
+	_, err = db.Exec(`
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS pin_scope VARCHAR(20) CHECK (pin_scope IN ('category', 'global'));
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS pinned_until TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE;
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS announcement BOOLEAN NOT NULL DEFAULT FALSE;
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS announcement_until TIMESTAMP WITH TIME ZONE;
+	`)
+	if err != nil {
+		log.Printf("Error adding topic moderation flags: %v", err)
+		return err
+	}
+
+	// Создаем таблицы опросов
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS polls (
+			id BIGSERIAL PRIMARY KEY,
+			topic_id BIGINT NOT NULL UNIQUE REFERENCES topics(id) ON DELETE CASCADE,
+			question TEXT NOT NULL,
+			multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+			anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+			hide_results BOOLEAN NOT NULL DEFAULT FALSE,
+			closes_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS poll_options (
+			id BIGSERIAL PRIMARY KEY,
+			poll_id BIGINT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+			text TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			UNIQUE (poll_id, position)
+		);
+
+		CREATE TABLE IF NOT EXISTS poll_votes (
+			poll_id BIGINT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+			option_id BIGINT NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+			user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (option_id, user_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_poll_votes_user ON poll_votes(poll_id, user_id);
+	`)
+	if err != nil {
+		log.Printf("Error creating polls tables: %v", err)
+		return err
+	}
+
+	// Создаем таблицу вложений
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS attachments (
+			id BIGSERIAL PRIMARY KEY,
+			uploader_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			post_type VARCHAR(20) CHECK (post_type IN ('topic', 'comment')),
+			post_id BIGINT,
+			filename TEXT NOT NULL,
+			content_type VARCHAR(100) NOT NULL,
+			size BIGINT NOT NULL,
+			storage_key TEXT NOT NULL UNIQUE,
+			thumbnail_key TEXT,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			CHECK ((post_type IS NULL) = (post_id IS NULL))
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_attachments_post ON attachments(post_type, post_id);
+		CREATE INDEX IF NOT EXISTS idx_attachments_orphaned ON attachments(created_at) WHERE post_id IS NULL;
+	`)
+	if err != nil {
+		log.Printf("Error creating attachments table: %v", err)
+		return err
+	}
+
+	// Добавляем загруженные аватары пользователей
+	_, err = db.Exec(`
+		ALTER TABLE users ALTER COLUMN avatar TYPE TEXT;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key TEXT;
+	`)
+	if err != nil {
+		log.Printf("Error adding user avatars: %v", err)
+		return err
+	}
+
+	// Добавляем поля публичного профиля
+	_, err = db.Exec(`
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS bio TEXT;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS signature TEXT;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS location VARCHAR(100);
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+	`)
+	if err != nil {
+		log.Printf("Error adding user profile fields: %v", err)
+		return err
+	}
+
+	// Создаем таблицу черновиков
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS drafts (
+			id BIGSERIAL PRIMARY KEY,
+			user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			context VARCHAR(20) NOT NULL CHECK (context IN ('topic', 'topic_reply', 'comment_reply')),
+			target_id BIGINT NOT NULL DEFAULT 0,
+			title TEXT NOT NULL DEFAULT '',
+			content TEXT NOT NULL DEFAULT '',
+			category_id BIGINT,
+			tag_names TEXT[] NOT NULL DEFAULT '{}',
+			version INTEGER NOT NULL DEFAULT 1,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			UNIQUE (user_id, context, target_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_drafts_user ON drafts(user_id, updated_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_drafts_expires_at ON drafts(expires_at);
+	`)
+	if err != nil {
+		log.Printf("Error creating drafts table: %v", err)
+		return err
+	}
+
+	// Добавляем отложенную публикацию тем
+	_, err = db.Exec(`
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+		CREATE INDEX IF NOT EXISTS idx_topics_publish_at ON topics(publish_at) WHERE publish_at IS NOT NULL;
+	`)
+	if err != nil {
+		log.Printf("Error adding topic publish time: %v", err)
+		return err
+	}
+
+	// Добавляем hot и top рейтинги тем
+	_, err = db.Exec(`
+		CREATE OR REPLACE FUNCTION topic_hot_score(score DOUBLE PRECISION, points DOUBLE PRECISION, at TIMESTAMP WITH TIME ZONE)
+		RETURNS DOUBLE PRECISION AS $$
+		DECLARE
+			x DOUBLE PRECISION;
+		BEGIN
+			IF points = 0 THEN
+				RETURN score;
+			END IF;
+			x := LN(ABS(points)) / LN(2) + EXTRACT(EPOCH FROM at - TIMESTAMPTZ '2024-01-01 00:00:00+00') / 43200;
+			IF points > 0 THEN
+				IF ABS(score - x) > 64 THEN
+					RETURN GREATEST(score, x);
+				END IF;
+				RETURN GREATEST(score, x) + LN(1 + POWER(2, -ABS(score - x))) / LN(2);
+			END IF;
+			IF x >= score THEN
+				RETURN 0;
+			END IF;
+			IF score - x > 64 THEN
+				RETURN score;
+			END IF;
+			RETURN score + LN(1 - POWER(2, x - score)) / LN(2);
+		END;
+		$$ LANGUAGE plpgsql IMMUTABLE;
+
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS hot_score DOUBLE PRECISION;
+		ALTER TABLE topics ADD COLUMN IF NOT EXISTS top_score DOUBLE PRECISION;
+
+		UPDATE topics t
+		SET top_score = s.points, hot_score = topic_hot_score(0, s.points, t.last_activity_at)
+		FROM (
+			SELECT tp.id, 1 + 2 * tp.comment_count + 0.1 * tp.views + COUNT(cl.user_id) AS points
+			FROM topics tp
+			LEFT JOIN comments c ON c.topic_id = tp.id AND c.deleted_at IS NULL
+			LEFT JOIN comment_likes cl ON cl.comment_id = c.id
+			WHERE tp.hot_score IS NULL
+			GROUP BY tp.id
+		) s
+		WHERE t.id = s.id;
+
+		ALTER TABLE topics ALTER COLUMN hot_score SET DEFAULT topic_hot_score(0, 1, CURRENT_TIMESTAMP);
+		ALTER TABLE topics ALTER COLUMN hot_score SET NOT NULL;
+		ALTER TABLE topics ALTER COLUMN top_score SET DEFAULT 1;
+		ALTER TABLE topics ALTER COLUMN top_score SET NOT NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_topics_hot_score ON topics(hot_score DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_top_score ON topics(top_score DESC, id DESC);
+
+		CREATE OR REPLACE FUNCTION score_topic_comment_points(cid BIGINT, tid BIGINT, created TIMESTAMP WITH TIME ZONE, direction DOUBLE PRECISION)
+		RETURNS VOID AS $$
+		DECLARE
+			liked_at TIMESTAMP WITH TIME ZONE;
+		BEGIN
+			-- Очки снимаются с теми же временами, с которыми добавлялись
+			UPDATE topics
+			SET hot_score = topic_hot_score(hot_score, direction * 2, created), top_score = top_score + direction * 2
+			WHERE id = tid;
+			FOR liked_at IN SELECT created_at FROM comment_likes WHERE comment_id = cid LOOP
+				UPDATE topics
+				SET hot_score = topic_hot_score(hot_score, direction, liked_at), top_score = top_score + direction
+				WHERE id = tid;
+			END LOOP;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		CREATE OR REPLACE FUNCTION score_topic_comment()
+		RETURNS TRIGGER AS $$
+		BEGIN
+			IF TG_OP = 'INSERT' THEN
+				PERFORM score_topic_comment_points(NEW.id, NEW.topic_id, NEW.created_at, 1);
+			ELSIF TG_OP = 'DELETE' THEN
+				-- Лайки еще не удалены каскадом, поэтому триггер срабатывает до удаления
+				IF OLD.deleted_at IS NULL THEN
+					PERFORM score_topic_comment_points(OLD.id, OLD.topic_id, OLD.created_at, -1);
+				END IF;
+				RETURN OLD;
+			ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+				PERFORM score_topic_comment_points(NEW.id, NEW.topic_id, NEW.created_at, -1);
+			ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+				PERFORM score_topic_comment_points(NEW.id, NEW.topic_id, NEW.created_at, 1);
+			END IF;
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS comments_score_topic ON comments;
+		CREATE TRIGGER comments_score_topic
+		AFTER INSERT OR UPDATE OF deleted_at ON comments
+		FOR EACH ROW
+		EXECUTE FUNCTION score_topic_comment();
+
+		DROP TRIGGER IF EXISTS comments_unscore_topic ON comments;
+		CREATE TRIGGER comments_unscore_topic
+		BEFORE DELETE ON comments
+		FOR EACH ROW
+		EXECUTE FUNCTION score_topic_comment();
+
+		-- Лайки удаленных комментариев теме не засчитываются
+		CREATE OR REPLACE FUNCTION score_topic_comment_like()
+		RETURNS TRIGGER AS $$
+		BEGIN
+			IF TG_OP = 'INSERT' THEN
+				UPDATE topics t
+				SET hot_score = topic_hot_score(t.hot_score, 1, NEW.created_at), top_score = t.top_score + 1
+				FROM comments c
+				WHERE c.id = NEW.comment_id AND c.deleted_at IS NULL AND t.id = c.topic_id;
+			ELSE
+				UPDATE topics t
+				SET hot_score = topic_hot_score(t.hot_score, -1, OLD.created_at), top_score = t.top_score - 1
+				FROM comments c
+				WHERE c.id = OLD.comment_id AND c.deleted_at IS NULL AND t.id = c.topic_id;
+			END IF;
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS comment_likes_score_topic ON comment_likes;
+		CREATE TRIGGER comment_likes_score_topic
+		AFTER INSERT OR DELETE ON comment_likes
+		FOR EACH ROW
+		EXECUTE FUNCTION score_topic_comment_like();
+	`)
+	if err != nil {
+		log.Printf("Error adding topic scores: %v", err)
+		return err
+	}
+
+	// Упоминания со ссылкой на профиль по имени отрисовываются заново
+	_, err = db.Exec(`
+		UPDATE topics SET content_html = NULL
+		WHERE content_html ~ 'href="/users/[^"]*[^0-9"][^"]*" class="mention"';
+		UPDATE comments SET content_html = NULL
+		WHERE content_html ~ 'href="/users/[^"]*[^0-9"][^"]*" class="mention"';
+		UPDATE chat_messages SET content_html = NULL
+		WHERE content_html ~ 'href="/users/[^"]*[^0-9"][^"]*" class="mention"';
+	`)
+	if err != nil {
+		log.Printf("Error resetting mention links: %v", err)
+		return err
+	}
+
+	// Создаем индекс для выборки объявлений и закрепленных тем
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_topics_flagged ON topics(id) WHERE announcement OR pin_scope IS NOT NULL;
+	`)
+	if err != nil {
+		log.Printf("Error creating flagged topics index: %v", err)
+		return err
+	}
+
+	// Создаем индексы для выборки тем категории по каждой сортировке
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_topics_category_views_id ON topics(category_id, views DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_category_comment_count_id ON topics(category_id, comment_count DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_category_last_activity_at_id ON topics(category_id, last_activity_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_category_hot_score_id ON topics(category_id, hot_score DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_topics_category_top_score_id ON topics(category_id, top_score DESC, id DESC);
+	`)
+	if err != nil {
+		log.Printf("Error creating category topic listing indexes: %v", err)
+		return err
+	}
+
 	log.Println("Migrations completed successfully")
 	return nil
 }
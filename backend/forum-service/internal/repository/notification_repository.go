@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, notification *entity.Notification) error
+	GetNotifications(ctx context.Context, filter entity.NotificationFilter) (*entity.NotificationPage, error)
+	CreateNotifications(ctx context.Context, notification *entity.Notification, userIDs []int64) ([]*entity.Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int, error)
+	CountUnreadByUsers(ctx context.Context, userIDs []int64) (map[int64]int, error)
+	MarkRead(ctx context.Context, userID, id int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+}
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+const notificationsCursorKey = "notifications"
+
+// notificationColumns are selected from notifications n joined with the
+// latest actor u
+const notificationColumns = `n.id, n.user_id, n.type, n.target_type, n.target_id, COALESCE(n.topic_id, 0),
+	COALESCE(n.actor_ids[cardinality(n.actor_ids)], 0), COALESCE(u.username, ''), cardinality(n.actor_ids),
+	n.action, n.note, n.read_at IS NOT NULL, n.created_at, n.updated_at`
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func scanNotification(row interface{ Scan(...interface{}) error }) (*entity.Notification, error) {
+	n := &entity.Notification{}
+	err := row.Scan(
+		&n.ID,
+		&n.UserID,
+		&n.Type,
+		&n.TargetType,
+		&n.TargetID,
+		&n.TopicID,
+		&n.ActorID,
+		&n.ActorUsername,
+		&n.ActorCount,
+		&n.Action,
+		&n.Note,
+		&n.Read,
+		&n.CreatedAt,
+		&n.UpdatedAt,
+	)
+	return n, err
+}
+
+// CreateNotification saves a notification and fills in its ID, topic, actors
+// and timestamps. An aggregated notification joins the unread one of the same
+// type on the same target, if there is one, instead of making a new row.
+func (r *notificationRepository) CreateNotification(ctx context.Context, notification *entity.Notification) error {
+	rows, err := r.insertNotifications(ctx, notification, []int64{notification.UserID})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to create notification: %w", err)
+		}
+		return fmt.Errorf("failed to create notification: %w", sql.ErrNoRows)
+	}
+	saved, err := scanNotification(rows)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	*notification = *saved
+	return nil
+}
+
+// CreateNotifications sends a copy of notification to each of userIDs, the
+// same way CreateNotification does, and returns the saved copies
+func (r *notificationRepository) CreateNotifications(ctx context.Context, notification *entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+	// Повторный получатель сломал бы ON CONFLICT DO UPDATE
+	seen := make(map[int64]bool, len(userIDs))
+	recipients := make([]int64, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if !seen[userID] {
+			seen[userID] = true
+			recipients = append(recipients, userID)
+		}
+	}
+	if len(recipients) == 0 {
+		return []*entity.Notification{}, nil
+	}
+
+	rows, err := r.insertNotifications(ctx, notification, recipients)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]*entity.Notification, 0, len(recipients))
+	for rows.Next() {
+		saved, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, saved)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) insertNotifications(ctx context.Context, notification *entity.Notification, userIDs []int64) (*sql.Rows, error) {
+	var groupKey string
+	if notification.Type.Aggregated() {
+		groupKey = fmt.Sprintf("%s:%s:%d", notification.Type, notification.TargetType, notification.TargetID)
+	}
+	actorIDs := []int64{}
+	if notification.ActorID != 0 {
+		actorIDs = append(actorIDs, notification.ActorID)
+	}
+
+	// Тема комментария берется из самого комментария, если ее не передали.
+	// Повторный актор переносится в конец списка, а не добавляется еще раз.
+	rows, err := r.db.QueryContext(ctx, `
+		WITH n AS (
+			INSERT INTO notifications (user_id, type, target_type, target_id, topic_id, actor_ids, action, note, group_key)
+			SELECT recipients.user_id, $2, $3::text, $4::bigint,
+				COALESCE(NULLIF($5::bigint, 0),
+					CASE WHEN $3::text = 'topic' THEN $4::bigint END,
+					(SELECT topic_id FROM comments WHERE $3::text = 'comment' AND id = $4::bigint)),
+				$6::bigint[], $7, $8, NULLIF($9, '')
+			FROM unnest($1::bigint[]) AS recipients(user_id)
+			ON CONFLICT (user_id, group_key) WHERE read_at IS NULL
+			DO UPDATE SET actor_ids = array_remove(notifications.actor_ids, EXCLUDED.actor_ids[1]) || EXCLUDED.actor_ids,
+				updated_at = CURRENT_TIMESTAMP
+			RETURNING *
+		)
+		SELECT `+notificationColumns+`
+		FROM n
+		LEFT JOIN users u ON u.id = n.actor_ids[cardinality(n.actor_ids)]`,
+		pq.Array(userIDs), notification.Type, notification.TargetType, notification.TargetID, notification.TopicID,
+		pq.Array(actorIDs), notification.Action, notification.Note, groupKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+	return rows, nil
+}
+
+// GetNotifications returns a page of a user's notifications, most recently
+// updated first. Unread is left for the caller to fill in.
+func (r *notificationRepository) GetNotifications(ctx context.Context, filter entity.NotificationFilter) (*entity.NotificationPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = entity.DefaultNotificationPageSize
+	}
+	if limit > entity.MaxNotificationPageSize {
+		limit = entity.MaxNotificationPageSize
+	}
+
+	args := []interface{}{filter.UserID}
+	conditions := "n.user_id = $1"
+	if filter.UnreadOnly {
+		conditions += " AND n.read_at IS NULL"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor, notificationsCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		before, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, before, cursor.ID)
+		conditions += fmt.Sprintf(" AND (n.updated_at, n.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM notifications n
+		LEFT JOIN users u ON u.id = n.actor_ids[cardinality(n.actor_ids)]
+		WHERE %s
+		ORDER BY n.updated_at DESC, n.id DESC
+		LIMIT $%d`, notificationColumns, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.NotificationPage{Notifications: []*entity.Notification{}}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		page.Notifications = append(page.Notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	if len(page.Notifications) > limit {
+		page.Notifications = page.Notifications[:limit]
+		last := page.Notifications[limit-1]
+		page.NextCursor = encodeCursor(notificationsCursorKey, last.UpdatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	return page, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`,
+		userID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// CountUnreadByUsers returns the number of unread notifications of each of
+// userIDs. Users without unread notifications are left out.
+func (r *notificationRepository) CountUnreadByUsers(ctx context.Context, userIDs []int64) (map[int64]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id, COUNT(*) FROM notifications
+		WHERE user_id = ANY($1) AND read_at IS NULL
+		GROUP BY user_id`,
+		pq.Array(userIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int, len(userIDs))
+	for rows.Next() {
+		var userID int64
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan unread count: %w", err)
+		}
+		counts[userID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unread counts: %w", err)
+	}
+	return counts, nil
+}
+
+// MarkRead marks one of the user's notifications as read. Marking a read
+// notification again is not an error.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id int64) error {
+	var read bool
+	err := r.db.QueryRowContext(ctx, `
+		WITH updated AS (
+			UPDATE notifications SET read_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND user_id = $2 AND read_at IS NULL
+			RETURNING id
+		)
+		SELECT EXISTS (SELECT 1 FROM updated)
+			OR EXISTS (SELECT 1 FROM notifications WHERE id = $1 AND user_id = $2)`,
+		id, userID,
+	).Scan(&read)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	if !read {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and
+// returns how many there were
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND read_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return result.RowsAffected()
+}
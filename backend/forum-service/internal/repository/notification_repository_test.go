@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var notificationTestColumns = []string{"id", "user_id", "type", "target_type", "target_id", "topic_id",
+	"actor_id", "username", "actor_count", "action", "note", "read", "created_at", "updated_at"}
+
+func newTestNotificationRepo(t *testing.T) (NotificationRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewNotificationRepository(db), mock, func() { db.Close() }
+}
+
+func TestNotificationRepository_CreateNotification(t *testing.T) {
+	repo, mock, closeFn := newTestNotificationRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	// Лайки одного комментария копятся в одной записи
+	like := &entity.Notification{
+		UserID:             2,
+		Type:               entity.NotificationLike,
+		NotificationTarget: entity.NotificationTarget{TargetType: entity.NotificationTargetComment, TargetID: 10},
+		ActorID:            7,
+	}
+	mock.ExpectQuery(`INSERT INTO notifications[\s\S]+ON CONFLICT \(user_id, group_key\) WHERE read_at IS NULL`).
+		WithArgs(pq.Array([]int64{2}), entity.NotificationLike, entity.NotificationTargetComment, int64(10), int64(0),
+			pq.Array([]int64{7}), "", "", "like:comment:10").
+		WillReturnRows(sqlmock.NewRows(notificationTestColumns).
+			AddRow(1, 2, "like", "comment", 10, 4, 7, "bob", 5, "", "", false, now.Add(-time.Hour), now))
+
+	assert.NoError(t, repo.CreateNotification(context.Background(), like))
+	assert.Equal(t, int64(1), like.ID)
+	assert.Equal(t, int64(4), like.TopicID)
+	assert.Equal(t, 5, like.ActorCount)
+	assert.Equal(t, "bob", like.ActorUsername)
+
+	// Модерация не группируется и не называет модератора
+	moderation := &entity.Notification{
+		UserID:             2,
+		Type:               entity.NotificationModeration,
+		NotificationTarget: entity.NotificationTarget{TargetType: entity.NotificationTargetTopic, TargetID: 4},
+		Action:             entity.ModerationHide,
+	}
+	mock.ExpectQuery(`INSERT INTO notifications`).
+		WithArgs(pq.Array([]int64{2}), entity.NotificationModeration, entity.NotificationTargetTopic, int64(4), int64(0),
+			pq.Array([]int64{}), "hide", "", "").
+		WillReturnRows(sqlmock.NewRows(notificationTestColumns).
+			AddRow(2, 2, "moderation", "topic", 4, 4, 0, "", 0, "hide", "", false, now, now))
+
+	assert.NoError(t, repo.CreateNotification(context.Background(), moderation))
+	assert.Equal(t, 0, moderation.ActorCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_CreateNotifications(t *testing.T) {
+	repo, mock, closeFn := newTestNotificationRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	reply := &entity.Notification{
+		Type:               entity.NotificationTopicReply,
+		NotificationTarget: entity.NotificationTarget{TargetType: entity.NotificationTargetTopic, TargetID: 4},
+		ActorID:            7,
+	}
+	// Повторные получатели отбрасываются
+	mock.ExpectQuery(`FROM unnest\(\$1::bigint\[\]\) AS recipients\(user_id\)`).
+		WithArgs(pq.Array([]int64{2, 3}), entity.NotificationTopicReply, entity.NotificationTargetTopic, int64(4), int64(0),
+			pq.Array([]int64{7}), "", "", "topic_reply:topic:4").
+		WillReturnRows(sqlmock.NewRows(notificationTestColumns).
+			AddRow(1, 2, "topic_reply", "topic", 4, 4, 7, "bob", 1, "", "", false, now, now).
+			AddRow(2, 3, "topic_reply", "topic", 4, 4, 7, "bob", 3, "", "", false, now, now))
+
+	notifications, err := repo.CreateNotifications(context.Background(), reply, []int64{2, 3, 2})
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, int64(3), notifications[1].UserID)
+	assert.Equal(t, 3, notifications[1].ActorCount)
+
+	notifications, err = repo.CreateNotifications(context.Background(), reply, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, notifications)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_CountUnreadByUsers(t *testing.T) {
+	repo, mock, closeFn := newTestNotificationRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`WHERE user_id = ANY\(\$1\) AND read_at IS NULL\s+GROUP BY user_id`).
+		WithArgs(pq.Array([]int64{2, 3})).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "count"}).AddRow(2, 4))
+
+	counts, err := repo.CountUnreadByUsers(context.Background(), []int64{2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int{2: 4}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_GetNotifications(t *testing.T) {
+	repo, mock, closeFn := newTestNotificationRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM notifications n[\s\S]+WHERE n.user_id = \$1 AND n.read_at IS NULL\s+ORDER BY n.updated_at DESC, n.id DESC\s+LIMIT \$2`).
+		WithArgs(int64(2), 2).
+		WillReturnRows(sqlmock.NewRows(notificationTestColumns).
+			AddRow(3, 2, "topic_reply", "topic", 4, 4, 7, "bob", 2, "", "", false, now, now).
+			AddRow(1, 2, "mention", "comment", 10, 4, 8, "carol", 1, "", "", false, now, now.Add(-time.Minute)))
+
+	page, err := repo.GetNotifications(context.Background(), entity.NotificationFilter{UserID: 2, UnreadOnly: true, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Notifications, 1)
+	assert.Equal(t, entity.NotificationTopicReply, page.Notifications[0].Type)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mock.ExpectQuery(`AND \(n.updated_at, n.id\) < \(\$2, \$3\)`).
+		WithArgs(int64(2), now, int64(3), 2).
+		WillReturnRows(sqlmock.NewRows(notificationTestColumns))
+
+	page, err = repo.GetNotifications(context.Background(), entity.NotificationFilter{UserID: 2, Cursor: page.NextCursor, Limit: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Notifications)
+
+	_, err = repo.GetNotifications(context.Background(), entity.NotificationFilter{UserID: 2, Cursor: encodeCursor(mentionsCursorKey, now.Format(time.RFC3339Nano), 3)})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_MarkRead(t *testing.T) {
+	repo, mock, closeFn := newTestNotificationRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`UPDATE notifications SET read_at = CURRENT_TIMESTAMP\s+WHERE id = \$1 AND user_id = \$2 AND read_at IS NULL`).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	// Чужое уведомление выглядит как несуществующее
+	mock.ExpectQuery(`UPDATE notifications SET read_at`).
+		WithArgs(int64(5), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	assert.NoError(t, repo.MarkRead(context.Background(), 2, 1))
+	assert.ErrorIs(t, repo.MarkRead(context.Background(), 2, 5), ErrNotificationNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_MarkAllRead(t *testing.T) {
+	repo, mock, closeFn := newTestNotificationRepo(t)
+	defer closeFn()
+
+	mock.ExpectExec(`UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE user_id = \$1 AND read_at IS NULL`).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM notifications WHERE user_id = \$1 AND read_at IS NULL`).
+		WithArgs(int64(2)).
+		WillReturnError(sql.ErrConnDone)
+
+	marked, err := repo.MarkAllRead(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), marked)
+
+	_, err = repo.CountUnread(context.Background(), 2)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type PollRepository interface {
+	CreatePoll(ctx context.Context, poll *entity.Poll) error
+	GetPollByTopic(ctx context.Context, topicID int64) (*entity.Poll, error)
+	GetUserVotes(ctx context.Context, pollID, userID int64) ([]int64, error)
+	SetVotes(ctx context.Context, pollID, userID int64, optionIDs []int64) error
+	GetVoters(ctx context.Context, pollID int64) (map[int64][]*entity.User, error)
+}
+
+var (
+	ErrPollNotFound      = errors.New("poll not found")
+	ErrPollExists        = errors.New("topic already has a poll")
+	ErrInvalidPollOption = errors.New("option does not belong to the poll")
+)
+
+type pollRepository struct {
+	db *sql.DB
+}
+
+func NewPollRepository(db *sql.DB) PollRepository {
+	return &pollRepository{db: db}
+}
+
+// CreatePoll saves a poll with its options and fills in their IDs.
+// A topic carries at most one poll.
+func (r *pollRepository) CreatePoll(ctx context.Context, poll *entity.Poll) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO polls (topic_id, question, multiple_choice, anonymous, hide_results, closes_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		poll.TopicID, poll.Question, poll.Multiple, poll.Anonymous, poll.HideResults, poll.ClosesAt,
+	).Scan(&poll.ID, &poll.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrPollExists
+		}
+		return fmt.Errorf("failed to create poll: %w", err)
+	}
+
+	texts := make([]string, len(poll.Options))
+	for i, option := range poll.Options {
+		texts[i] = option.Text
+	}
+	rows, err := tx.QueryContext(ctx, `
+		INSERT INTO poll_options (poll_id, text, position)
+		SELECT $1, o.text, o.position FROM unnest($2::text[]) WITH ORDINALITY AS o(text, position)
+		RETURNING id, position`,
+		poll.ID, pq.Array(texts),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create poll options: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var position int
+		if err := rows.Scan(&id, &position); err != nil {
+			return fmt.Errorf("failed to scan poll option: %w", err)
+		}
+		// Позиции нумеруются с единицы в порядке вариантов
+		if position >= 1 && position <= len(poll.Options) {
+			poll.Options[position-1].ID = id
+			poll.Options[position-1].Position = position
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating poll options: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetPollByTopic returns the poll of a visible topic with the number of
+// votes for every option
+func (r *pollRepository) GetPollByTopic(ctx context.Context, topicID int64) (*entity.Poll, error) {
+	poll := &entity.Poll{}
+	var closesAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT p.id, p.topic_id, p.question, p.multiple_choice, p.anonymous, p.hide_results, p.closes_at, p.created_at,
+			(SELECT COUNT(DISTINCT v.user_id) FROM poll_votes v WHERE v.poll_id = p.id)
+		FROM polls p
+		JOIN topics t ON t.id = p.topic_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL
+		WHERE p.topic_id = $1`,
+		topicID,
+	).Scan(
+		&poll.ID,
+		&poll.TopicID,
+		&poll.Question,
+		&poll.Multiple,
+		&poll.Anonymous,
+		&poll.HideResults,
+		&closesAt,
+		&poll.CreatedAt,
+		&poll.TotalVoters,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPollNotFound
+		}
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	if closesAt.Valid {
+		poll.ClosesAt = &closesAt.Time
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT o.id, o.text, o.position, COUNT(v.user_id)
+		FROM poll_options o
+		LEFT JOIN poll_votes v ON v.option_id = o.id
+		WHERE o.poll_id = $1
+		GROUP BY o.id
+		ORDER BY o.position`,
+		poll.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query poll options: %w", err)
+	}
+	defer rows.Close()
+
+	poll.Options = []*entity.PollOption{}
+	for rows.Next() {
+		option := &entity.PollOption{}
+		if err := rows.Scan(&option.ID, &option.Text, &option.Position, &option.Votes); err != nil {
+			return nil, fmt.Errorf("failed to scan poll option: %w", err)
+		}
+		poll.Options = append(poll.Options, option)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating poll options: %w", err)
+	}
+	return poll, nil
+}
+
+// GetUserVotes returns the options a user voted for
+func (r *pollRepository) GetUserVotes(ctx context.Context, pollID, userID int64) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT option_id FROM poll_votes WHERE poll_id = $1 AND user_id = $2 ORDER BY option_id`,
+		pollID, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query poll votes: %w", err)
+	}
+	defer rows.Close()
+
+	optionIDs := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan poll vote: %w", err)
+		}
+		optionIDs = append(optionIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating poll votes: %w", err)
+	}
+	return optionIDs, nil
+}
+
+// SetVotes replaces a user's votes in a poll with votes for the given
+// options, all of which must belong to the poll. Votes in one poll are saved
+// one at a time, so concurrent requests can't leave a user with two votes
+// in a single-choice poll.
+func (r *pollRepository) SetVotes(ctx context.Context, pollID, userID int64, optionIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем опрос до конца транзакции
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM polls WHERE id = $1 FOR UPDATE`, pollID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPollNotFound
+		}
+		return fmt.Errorf("failed to lock poll: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM poll_votes WHERE poll_id = $1 AND user_id = $2`,
+		pollID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear poll votes: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO poll_votes (poll_id, option_id, user_id)
+		SELECT $1, id, $2 FROM poll_options WHERE poll_id = $1 AND id = ANY($3)`,
+		pollID, userID, pq.Array(optionIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save poll votes: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows != int64(len(optionIDs)) {
+		return ErrInvalidPollOption
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetVoters returns the users who voted for each option of a poll, in the
+// order they voted
+func (r *pollRepository) GetVoters(ctx context.Context, pollID int64) (map[int64][]*entity.User, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT v.option_id, u.id, u.username, COALESCE(u.avatar, '')
+		FROM poll_votes v
+		JOIN users u ON u.id = v.user_id
+		WHERE v.poll_id = $1
+		ORDER BY v.created_at, u.id`,
+		pollID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query poll voters: %w", err)
+	}
+	defer rows.Close()
+
+	voters := make(map[int64][]*entity.User)
+	for rows.Next() {
+		var optionID int64
+		user := &entity.User{}
+		if err := rows.Scan(&optionID, &user.ID, &user.Username, &user.Avatar); err != nil {
+			return nil, fmt.Errorf("failed to scan poll voter: %w", err)
+		}
+		user.Avatar = entity.AvatarURL(user.ID, user.Avatar)
+		voters[optionID] = append(voters[optionID], user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating poll voters: %w", err)
+	}
+	return voters, nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPollRepo(t *testing.T) (PollRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewPollRepository(db), mock, func() { db.Close() }
+}
+
+func TestPollRepository_CreatePoll(t *testing.T) {
+	repo, mock, closeFn := newTestPollRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	poll := &entity.Poll{
+		TopicID:  4,
+		Question: "Which day?",
+		Options:  []*entity.PollOption{{Text: "Monday"}, {Text: "Friday"}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO polls`).
+		WithArgs(int64(4), "Which day?", false, false, false, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, now))
+	mock.ExpectQuery(`INSERT INTO poll_options[\s\S]+unnest\(\$2::text\[\]\) WITH ORDINALITY`).
+		WithArgs(int64(1), pq.Array([]string{"Monday", "Friday"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "position"}).AddRow(11, 2).AddRow(10, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.CreatePoll(context.Background(), poll))
+	assert.Equal(t, int64(1), poll.ID)
+	assert.Equal(t, now, poll.CreatedAt)
+	assert.Equal(t, int64(10), poll.Options[0].ID)
+	assert.Equal(t, int64(11), poll.Options[1].ID)
+	assert.Equal(t, 2, poll.Options[1].Position)
+
+	// У темы уже есть опрос
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO polls`).
+		WillReturnError(&pq.Error{Code: uniqueViolation})
+	mock.ExpectRollback()
+	err := repo.CreatePoll(context.Background(), &entity.Poll{TopicID: 4, Question: "Again?"})
+	assert.ErrorIs(t, err, ErrPollExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollRepository_GetPollByTopic(t *testing.T) {
+	repo, mock, closeFn := newTestPollRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	closesAt := now.Add(time.Hour)
+	mock.ExpectQuery(`FROM polls p\s+JOIN topics t ON t.id = p.topic_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL\s+WHERE p.topic_id = \$1`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic_id", "question", "multiple_choice", "anonymous", "hide_results", "closes_at", "created_at", "voters"}).
+			AddRow(1, 4, "Which day?", true, false, true, closesAt, now, 3))
+	mock.ExpectQuery(`FROM poll_options o\s+LEFT JOIN poll_votes v ON v.option_id = o.id\s+WHERE o.poll_id = \$1\s+GROUP BY o.id\s+ORDER BY o.position`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "text", "position", "votes"}).
+			AddRow(10, "Monday", 1, 1).
+			AddRow(11, "Friday", 2, 3))
+
+	poll, err := repo.GetPollByTopic(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.True(t, poll.Multiple)
+	assert.True(t, poll.HideResults)
+	assert.Equal(t, closesAt, *poll.ClosesAt)
+	assert.Equal(t, 3, poll.TotalVoters)
+	assert.Len(t, poll.Options, 2)
+	assert.Equal(t, 3, poll.Options[1].Votes)
+
+	mock.ExpectQuery(`FROM polls p`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = repo.GetPollByTopic(context.Background(), 5)
+	assert.ErrorIs(t, err, ErrPollNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollRepository_SetVotes(t *testing.T) {
+	repo, mock, closeFn := newTestPollRepo(t)
+	defer closeFn()
+
+	// Прежний голос заменяется новым, пока опрос заблокирован
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM polls WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`DELETE FROM poll_votes WHERE poll_id = \$1 AND user_id = \$2`).
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO poll_votes[\s\S]+FROM poll_options WHERE poll_id = \$1 AND id = ANY\(\$3\)`).
+		WithArgs(int64(1), int64(2), pq.Array([]int64{10, 11})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SetVotes(context.Background(), 1, 2, []int64{10, 11}))
+
+	// Вариант из другого опроса не засчитывается, голос остается прежним
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM polls WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`DELETE FROM poll_votes`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO poll_votes`).
+		WithArgs(int64(1), int64(2), pq.Array([]int64{99})).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.SetVotes(context.Background(), 1, 2, []int64{99}), ErrInvalidPollOption)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM polls WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`DELETE FROM poll_votes`).
+		WillReturnError(errors.New("db down"))
+	mock.ExpectRollback()
+	assert.Error(t, repo.SetVotes(context.Background(), 1, 2, []int64{10}))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM polls WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.SetVotes(context.Background(), 1, 2, []int64{10}), ErrPollNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollRepository_GetUserVotesAndVoters(t *testing.T) {
+	repo, mock, closeFn := newTestPollRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`SELECT option_id FROM poll_votes WHERE poll_id = \$1 AND user_id = \$2`).
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"option_id"}).AddRow(10).AddRow(11))
+	votes, err := repo.GetUserVotes(context.Background(), 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{10, 11}, votes)
+
+	mock.ExpectQuery(`FROM poll_votes v\s+JOIN users u ON u.id = v.user_id\s+WHERE v.poll_id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"option_id", "id", "username", "avatar"}).
+			AddRow(10, 2, "alice", "").
+			AddRow(11, 2, "alice", "").
+			AddRow(11, 3, "bob", "https://example.com/bob.png"))
+	voters, err := repo.GetVoters(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, voters[10], 1)
+	assert.Len(t, voters[11], 2)
+	assert.Equal(t, "bob", voters[11][1].Username)
+	assert.Equal(t, "/api/v1/users/2/avatar", voters[10][0].Avatar)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type ProfileRepository interface {
+	GetProfile(ctx context.Context, userID int64) (*entity.UserProfile, error)
+	UpdateProfile(ctx context.Context, userID int64, update entity.ProfileUpdate) error
+	GetUserTopics(ctx context.Context, filter entity.ActivityFilter) (*entity.TopicPage, error)
+	GetUserComments(ctx context.Context, filter entity.ActivityFilter) (*entity.UserCommentPage, error)
+}
+
+const (
+	userTopicsCursorKey   = "user_topics"
+	userCommentsCursorKey = "user_comments"
+)
+
+type profileRepository struct {
+	db *sql.DB
+}
+
+func NewProfileRepository(db *sql.DB) ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+// GetProfile returns the profile of a user with the number of their
+// visible topics and comments
+func (r *profileRepository) GetProfile(ctx context.Context, userID int64) (*entity.UserProfile, error) {
+	profile := &entity.UserProfile{}
+	var joinedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT u.id, u.username, COALESCE(u.avatar, ''), COALESCE(NULLIF(u.role, ''), 'user'),
+			COALESCE(u.bio, ''), COALESCE(u.signature, ''), COALESCE(u.location, ''), u.created_at,
+			(SELECT COUNT(*) FROM topics t
+				WHERE t.author_id = u.id AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL),
+			(SELECT COUNT(*) FROM comments c
+				JOIN topics t ON t.id = c.topic_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL
+				WHERE c.author_id = u.id AND c.hidden_at IS NULL AND c.deleted_at IS NULL)
+		FROM users u
+		WHERE u.id = $1`,
+		userID,
+	).Scan(
+		&profile.ID,
+		&profile.Username,
+		&profile.Avatar,
+		&profile.Role,
+		&profile.Bio,
+		&profile.Signature,
+		&profile.Location,
+		&joinedAt,
+		&profile.TopicCount,
+		&profile.CommentCount,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	profile.Avatar = entity.AvatarURL(profile.ID, profile.Avatar)
+	if joinedAt.Valid {
+		profile.JoinedAt = &joinedAt.Time
+	}
+	return profile, nil
+}
+
+// UpdateProfile saves the fields of the update that are set; an empty
+// string clears a field
+func (r *profileRepository) UpdateProfile(ctx context.Context, userID int64, update entity.ProfileUpdate) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET
+			bio = CASE WHEN $2::boolean THEN NULLIF($3, '') ELSE bio END,
+			signature = CASE WHEN $4::boolean THEN NULLIF($5, '') ELSE signature END,
+			location = CASE WHEN $6::boolean THEN NULLIF($7, '') ELSE location END
+		WHERE id = $1`,
+		userID,
+		update.Bio != nil, stringValue(update.Bio),
+		update.Signature != nil, stringValue(update.Signature),
+		update.Location != nil, stringValue(update.Location),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// GetUserTopics returns the visible topics of a user, newest first
+func (r *profileRepository) GetUserTopics(ctx context.Context, filter entity.ActivityFilter) (*entity.TopicPage, error) {
+	limit := activityPageSize(filter.Limit)
+	args := []interface{}{filter.UserID}
+	conditions := "author_id = $1 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NULL"
+	if filter.Cursor != "" {
+		before, id, err := decodeTimeCursor(filter.Cursor, userTopicsCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, before, id)
+		conditions += " AND (created_at, id) < ($2, $3)"
+	}
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT `+topicColumns+`
+		FROM topics
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user topics: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.TopicPage{Topics: []*entity.Topic{}}
+	for rows.Next() {
+		topic, err := scanTopic(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan topic: %w", err)
+		}
+		page.Topics = append(page.Topics, topic)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user topics: %w", err)
+	}
+
+	if len(page.Topics) > limit {
+		page.Topics = page.Topics[:limit]
+		last := page.Topics[limit-1]
+		page.NextCursor = encodeCursor(userTopicsCursorKey, last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	return page, nil
+}
+
+// GetUserComments returns the visible comments of a user in visible topics,
+// newest first
+func (r *profileRepository) GetUserComments(ctx context.Context, filter entity.ActivityFilter) (*entity.UserCommentPage, error) {
+	limit := activityPageSize(filter.Limit)
+	args := []interface{}{filter.UserID}
+	conditions := "c.author_id = $1 AND c.hidden_at IS NULL AND c.deleted_at IS NULL"
+	if filter.Cursor != "" {
+		before, id, err := decodeTimeCursor(filter.Cursor, userCommentsCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, before, id)
+		conditions += " AND (c.created_at, c.id) < ($2, $3)"
+	}
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT c.id, c.topic_id, t.title, c.parent_id, c.content, COALESCE(c.content_html, ''), c.likes,
+			c.created_at, c.edited_at
+		FROM comments c
+		JOIN topics t ON t.id = c.topic_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL
+		WHERE %s
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $%d`, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user comments: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.UserCommentPage{Comments: []*entity.UserComment{}}
+	for rows.Next() {
+		comment := &entity.UserComment{}
+		if err := rows.Scan(
+			&comment.ID,
+			&comment.TopicID,
+			&comment.TopicTitle,
+			&comment.ParentID,
+			&comment.Content,
+			&comment.ContentHTML,
+			&comment.Likes,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		page.Comments = append(page.Comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user comments: %w", err)
+	}
+
+	if len(page.Comments) > limit {
+		page.Comments = page.Comments[:limit]
+		last := page.Comments[limit-1]
+		page.NextCursor = encodeCursor(userCommentsCursorKey, last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	return page, nil
+}
+
+func activityPageSize(limit int) int {
+	if limit <= 0 {
+		return entity.DefaultActivityPageSize
+	}
+	return min(limit, entity.MaxActivityPageSize)
+}
+
+// decodeTimeCursor reads a cursor of a listing ordered by (created_at, id)
+func decodeTimeCursor(raw, key string) (time.Time, int64, error) {
+	c, err := decodeCursor(raw, key)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	before, err := time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return before, c.ID, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
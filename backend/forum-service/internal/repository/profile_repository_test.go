@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestProfileRepo(t *testing.T) (ProfileRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewProfileRepository(db), mock, func() { db.Close() }
+}
+
+func TestProfileRepository_GetProfile(t *testing.T) {
+	repo, mock, closeFn := newTestProfileRepo(t)
+	defer closeFn()
+
+	joined := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM users u\s+WHERE u.id = \$1`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "avatar", "role", "bio", "signature", "location", "created_at", "topics", "comments"}).
+			AddRow(5, "alice", "", "moderator", "Gopher", "-- alice", "Berlin", joined, 3, 12))
+
+	profile, err := repo.GetProfile(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", profile.Username)
+	assert.Equal(t, "/api/v1/users/5/avatar", profile.Avatar)
+	assert.Equal(t, entity.RoleModerator, profile.Role)
+	assert.Equal(t, "Berlin", profile.Location)
+	assert.Equal(t, joined, *profile.JoinedAt)
+	assert.Equal(t, 3, profile.TopicCount)
+	assert.Equal(t, 12, profile.CommentCount)
+
+	mock.ExpectQuery(`FROM users u`).
+		WithArgs(int64(6)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = repo.GetProfile(context.Background(), 6)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProfileRepository_UpdateProfile(t *testing.T) {
+	repo, mock, closeFn := newTestProfileRepo(t)
+	defer closeFn()
+
+	// Незаданные поля не меняются, пустая строка очищает поле
+	bio, location := "Gopher", ""
+	mock.ExpectExec(`UPDATE users SET\s+bio = CASE WHEN \$2::boolean THEN NULLIF\(\$3, ''\) ELSE bio END`).
+		WithArgs(int64(5), true, "Gopher", false, "", true, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdateProfile(context.Background(), 5, entity.ProfileUpdate{Bio: &bio, Location: &location}))
+
+	mock.ExpectExec(`UPDATE users`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.UpdateProfile(context.Background(), 6, entity.ProfileUpdate{Bio: &bio}), ErrUserNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProfileRepository_GetUserTopics(t *testing.T) {
+	repo, mock, closeFn := newTestProfileRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM topics\s+WHERE author_id = \$1 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NULL\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$2`).
+		WithArgs(int64(5), 3).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(9, "Third", "c", "<p>c</p>", 5, 1, 0, 0, now, now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0).
+			AddRow(8, "Second", "b", "<p>b</p>", 5, 1, 0, 0, now.Add(-time.Hour), now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0).
+			AddRow(7, "First", "a", "<p>a</p>", 5, 1, 0, 0, now.Add(-2*time.Hour), now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0))
+
+	page, err := repo.GetUserTopics(context.Background(), entity.ActivityFilter{UserID: 5, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mock.ExpectQuery(`AND \(created_at, id\) < \(\$2, \$3\)`).
+		WithArgs(int64(5), now.Add(-time.Hour), int64(8), 3).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(7, "First", "a", "<p>a</p>", 5, 1, 0, 0, now.Add(-2*time.Hour), now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0))
+	page, err = repo.GetUserTopics(context.Background(), entity.ActivityFilter{UserID: 5, Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 1)
+	assert.Empty(t, page.NextCursor)
+
+	// Курсор из другого списка не принимается
+	_, err = repo.GetUserTopics(context.Background(), entity.ActivityFilter{UserID: 5, Cursor: encodeCursor(userCommentsCursorKey, now.Format(time.RFC3339Nano), 1)})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProfileRepository_GetUserComments(t *testing.T) {
+	repo, mock, closeFn := newTestProfileRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	parentID := int64(3)
+	mock.ExpectQuery(`FROM comments c\s+JOIN topics t ON t.id = c.topic_id AND t.hidden_at IS NULL AND t.deleted_at IS NULL\s+WHERE c.author_id = \$1 AND c.hidden_at IS NULL AND c.deleted_at IS NULL\s+ORDER BY c.created_at DESC, c.id DESC`).
+		WithArgs(int64(5), entity.DefaultActivityPageSize+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic_id", "title", "parent_id", "content", "content_html", "likes", "created_at", "edited_at"}).
+			AddRow(11, 4, "Go tips", parentID, "Nice", "<p>Nice</p>", 2, now, nil))
+
+	page, err := repo.GetUserComments(context.Background(), entity.ActivityFilter{UserID: 5})
+	assert.NoError(t, err)
+	assert.Len(t, page.Comments, 1)
+	assert.Equal(t, "Go tips", page.Comments[0].TopicTitle)
+	assert.Equal(t, parentID, *page.Comments[0].ParentID)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = repo.GetUserComments(context.Background(), entity.ActivityFilter{UserID: 5, Cursor: "garbage"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
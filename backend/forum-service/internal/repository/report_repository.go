@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type ReportRepository interface {
+	GetReportTarget(ctx context.Context, targetType entity.ReportTargetType, targetID int64) (*entity.ReportTarget, error)
+	CreateReport(ctx context.Context, report *entity.Report) (bool, error)
+	CountActiveReports(ctx context.Context, targetType entity.ReportTargetType, targetID int64) (int, error)
+	AutoHideTarget(ctx context.Context, targetType entity.ReportTargetType, targetID int64) error
+	GetReportByID(ctx context.Context, id int64) (*entity.Report, error)
+	ListReports(ctx context.Context, filter entity.ReportFilter) (*entity.ReportPage, error)
+	ClaimReport(ctx context.Context, id, moderatorID int64) (*entity.Report, error)
+	ResolveReport(ctx context.Context, id, moderatorID int64, action entity.ReportAction, note string) (*entity.Report, error)
+	DismissReport(ctx context.Context, id, moderatorID int64, note string) (*entity.Report, error)
+}
+
+var (
+	ErrReportNotFound       = errors.New("report not found")
+	ErrReportTargetNotFound = errors.New("reported content not found")
+	ErrReportClaimed        = errors.New("report is claimed by another moderator")
+	ErrReportClosed         = errors.New("report is already closed")
+)
+
+// reportsCursorKey identifies cursors that continue the moderation queue
+const reportsCursorKey = "reports"
+
+// reportTargetTables maps a report target to the table holding it. All of
+// them have author_id, content, hidden_at and hidden_by columns.
+var reportTargetTables = map[entity.ReportTargetType]string{
+	entity.ReportTargetTopic:       "topics",
+	entity.ReportTargetComment:     "comments",
+	entity.ReportTargetChatMessage: "chat_messages",
+}
+
+// softDeletedTargets lists the targets that are moved to the trash rather
+// than removed; chat messages expire on their own and are deleted outright
+var softDeletedTargets = map[entity.ReportTargetType]bool{
+	entity.ReportTargetTopic:   true,
+	entity.ReportTargetComment: true,
+}
+
+const reportColumns = `id, target_type, target_id, target_author_id, content_snapshot, reporter_id, reason, details,
+	status, claimed_by, claimed_at, resolved_by, resolved_at, action, note, created_at`
+
+func scanReport(row topicScanner, extra ...interface{}) (*entity.Report, error) {
+	report := &entity.Report{}
+	dest := []interface{}{
+		&report.ID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.TargetAuthorID,
+		&report.ContentSnapshot,
+		&report.ReporterID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.ClaimedBy,
+		&report.ClaimedAt,
+		&report.ResolvedBy,
+		&report.ResolvedAt,
+		&report.Action,
+		&report.Note,
+		&report.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+type reportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// GetReportTarget loads the content a report is filed against, hidden or not
+func (r *reportRepository) GetReportTarget(ctx context.Context, targetType entity.ReportTargetType, targetID int64) (*entity.ReportTarget, error) {
+	table, ok := reportTargetTables[targetType]
+	if !ok {
+		return nil, ErrReportTargetNotFound
+	}
+
+	query := `SELECT author_id, content FROM ` + table + ` WHERE id = $1`
+	if softDeletedTargets[targetType] {
+		query += ` AND deleted_at IS NULL`
+	}
+
+	target := &entity.ReportTarget{Type: targetType, ID: targetID}
+	err := r.db.QueryRowContext(ctx, query, targetID).Scan(&target.AuthorID, &target.Content)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReportTargetNotFound
+		}
+		return nil, fmt.Errorf("failed to get reported content: %w", err)
+	}
+	return target, nil
+}
+
+// CreateReport files a report. A user who already has an open report on the
+// same content gets that report back with the new reason and details instead
+// of a second one; the returned flag is true only for a new report.
+func (r *reportRepository) CreateReport(ctx context.Context, report *entity.Report) (bool, error) {
+	var created bool
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO reports (target_type, target_id, target_author_id, content_snapshot, reporter_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (target_type, target_id, reporter_id) WHERE status IN ('open', 'claimed')
+		DO UPDATE SET reason = EXCLUDED.reason, details = EXCLUDED.details
+		RETURNING id, status, created_at, (xmax = 0)`,
+		report.TargetType, report.TargetID, report.TargetAuthorID, report.ContentSnapshot,
+		report.ReporterID, report.Reason, report.Details,
+	).Scan(&report.ID, &report.Status, &report.CreatedAt, &created)
+	if err != nil {
+		log.Printf("Error creating report on %s %d: %v", report.TargetType, report.TargetID, err)
+		return false, fmt.Errorf("failed to create report: %w", err)
+	}
+	return created, nil
+}
+
+// CountActiveReports returns how many users have reported the content and
+// are still waiting for a decision
+func (r *reportRepository) CountActiveReports(ctx context.Context, targetType entity.ReportTargetType, targetID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM reports WHERE target_type = $1 AND target_id = $2 AND status IN ('open', 'claimed')`,
+		targetType, targetID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reports: %w", err)
+	}
+	return count, nil
+}
+
+// AutoHideTarget hides reported content until a moderator reviews it.
+// Content that is already hidden is left as it is.
+func (r *reportRepository) AutoHideTarget(ctx context.Context, targetType entity.ReportTargetType, targetID int64) error {
+	table, ok := reportTargetTables[targetType]
+	if !ok {
+		return ErrReportTargetNotFound
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE `+table+` SET hidden_at = CURRENT_TIMESTAMP, hidden_by = NULL WHERE id = $1 AND hidden_at IS NULL`,
+		targetID,
+	)
+	if err != nil {
+		log.Printf("Error hiding %s %d: %v", targetType, targetID, err)
+		return fmt.Errorf("failed to hide reported content: %w", err)
+	}
+	return nil
+}
+
+func (r *reportRepository) GetReportByID(ctx context.Context, id int64) (*entity.Report, error) {
+	report, err := scanReport(r.db.QueryRowContext(ctx,
+		`SELECT `+reportColumns+` FROM reports WHERE id = $1`,
+		id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	return report, nil
+}
+
+// ListReports returns a page of the moderation queue, oldest report first.
+// Every report carries the number of active reports on the same content.
+func (r *reportRepository) ListReports(ctx context.Context, filter entity.ReportFilter) (*entity.ReportPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = entity.DefaultReportPageSize
+	}
+	if limit > entity.MaxReportPageSize {
+		limit = entity.MaxReportPageSize
+	}
+
+	var args []interface{}
+	conditions := "r.status IN ('open', 'claimed')"
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = fmt.Sprintf("r.status = $%d", len(args))
+	}
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		conditions += fmt.Sprintf(" AND r.target_type = $%d", len(args))
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor, reportsCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		after, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, after, cursor.ID)
+		conditions += fmt.Sprintf(" AND (r.created_at, r.id) > ($%d, $%d)", len(args)-1, len(args))
+	}
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT r.%s,
+			(SELECT COUNT(*) FROM reports a
+			WHERE a.target_type = r.target_type AND a.target_id = r.target_id AND a.status IN ('open', 'claimed'))
+		FROM reports r
+		WHERE %s
+		ORDER BY r.created_at, r.id
+		LIMIT $%d`, reportColumns, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		log.Printf("Error listing reports: %v", err)
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.ReportPage{Reports: []*entity.Report{}}
+	for rows.Next() {
+		var targetReports int
+		report, err := scanReport(rows, &targetReports)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		report.TargetReports = targetReports
+		page.Reports = append(page.Reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reports: %w", err)
+	}
+
+	if len(page.Reports) > limit {
+		page.Reports = page.Reports[:limit]
+		last := page.Reports[limit-1]
+		page.NextCursor = encodeCursor(reportsCursorKey, last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	return page, nil
+}
+
+// ClaimReport assigns an open report to a moderator. Claiming a report the
+// moderator already holds is a no-op.
+func (r *reportRepository) ClaimReport(ctx context.Context, id, moderatorID int64) (*entity.Report, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	report, err := lockReport(ctx, tx, id, moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == entity.ReportStatusOpen {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE reports SET status = 'claimed', claimed_by = $2, claimed_at = CURRENT_TIMESTAMP WHERE id = $1`,
+			id, moderatorID,
+		); err != nil {
+			log.Printf("Error claiming report %d: %v", id, err)
+			return nil, fmt.Errorf("failed to claim report: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetReportByID(ctx, id)
+}
+
+// ResolveReport applies a moderator's action to the reported content and
+// closes every active report on it. It fails with ErrReportClaimed if
+// another moderator has claimed any of those reports.
+func (r *reportRepository) ResolveReport(ctx context.Context, id, moderatorID int64, action entity.ReportAction, note string) (*entity.Report, error) {
+	return r.closeReport(ctx, id, moderatorID, entity.ReportStatusResolved, &action, note)
+}
+
+// DismissReport closes every active report on the content without action
+// and brings back content that was hidden automatically. Like
+// ResolveReport, it leaves reports claimed by other moderators alone.
+func (r *reportRepository) DismissReport(ctx context.Context, id, moderatorID int64, note string) (*entity.Report, error) {
+	return r.closeReport(ctx, id, moderatorID, entity.ReportStatusDismissed, nil, note)
+}
+
+func (r *reportRepository) closeReport(ctx context.Context, id, moderatorID int64, status entity.ReportStatus, action *entity.ReportAction, note string) (*entity.Report, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	report, err := lockReport(ctx, tx, id, moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockTargetReports(ctx, tx, report, moderatorID); err != nil {
+		return nil, err
+	}
+	table := reportTargetTables[report.TargetType]
+
+	// Контент может быть уже удален (например, истекшее сообщение чата),
+	// поэтому действия над ним не проверяют число затронутых строк
+	var query string
+	var args []interface{}
+	switch {
+	case action == nil:
+		query = `UPDATE ` + table + ` SET hidden_at = NULL WHERE id = $1 AND hidden_at IS NOT NULL AND hidden_by IS NULL`
+		args = []interface{}{report.TargetID}
+	case *action == entity.ReportActionHide:
+		query = `UPDATE ` + table + ` SET hidden_at = COALESCE(hidden_at, CURRENT_TIMESTAMP), hidden_by = $2 WHERE id = $1`
+		args = []interface{}{report.TargetID, moderatorID}
+	case *action == entity.ReportActionDelete && report.TargetType == entity.ReportTargetComment:
+		// Удаленный комментарий перестает учитываться в счетчике темы
+		var topicID int64
+		err := tx.QueryRowContext(ctx,
+			`UPDATE comments SET deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP), deleted_by = $2 WHERE id = $1 RETURNING topic_id`,
+			report.TargetID, moderatorID,
+		).Scan(&topicID)
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("Error applying report %d decision: %v", id, err)
+			return nil, fmt.Errorf("failed to apply report decision: %w", err)
+		}
+		if err == nil {
+			if err := recountComments(ctx, tx, topicID); err != nil {
+				return nil, err
+			}
+		}
+	case *action == entity.ReportActionDelete && softDeletedTargets[report.TargetType]:
+		query = `UPDATE ` + table + ` SET deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP), deleted_by = $2 WHERE id = $1`
+		args = []interface{}{report.TargetID, moderatorID}
+	case *action == entity.ReportActionDelete:
+		query = `DELETE FROM ` + table + ` WHERE id = $1`
+		args = []interface{}{report.TargetID}
+	case *action == entity.ReportActionWarn:
+		if report.TargetAuthorID != nil {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO user_warnings (user_id, moderator_id, report_id, reason) VALUES ($1, $2, $3, $4)`,
+				*report.TargetAuthorID, moderatorID, id, note,
+			); err != nil {
+				log.Printf("Error warning user %d: %v", *report.TargetAuthorID, err)
+				return nil, fmt.Errorf("failed to warn user: %w", err)
+			}
+		}
+		// Предупреждение оставляет контент на месте
+		query = `UPDATE ` + table + ` SET hidden_at = NULL WHERE id = $1 AND hidden_at IS NOT NULL AND hidden_by IS NULL`
+		args = []interface{}{report.TargetID}
+	default:
+		return nil, fmt.Errorf("unknown report action %q", *action)
+	}
+	if query != "" {
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			log.Printf("Error applying report %d decision: %v", id, err)
+			return nil, fmt.Errorf("failed to apply report decision: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE reports SET status = $3, resolved_by = $4, resolved_at = CURRENT_TIMESTAMP, action = $5, note = $6
+		WHERE target_type = $1 AND target_id = $2 AND status IN ('open', 'claimed')`,
+		report.TargetType, report.TargetID, status, moderatorID, action, note,
+	); err != nil {
+		log.Printf("Error closing reports on %s %d: %v", report.TargetType, report.TargetID, err)
+		return nil, fmt.Errorf("failed to close reports: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetReportByID(ctx, id)
+}
+
+// lockTargetReports locks the other active reports on the content of
+// report. They are closed together with it, so none of them may be claimed
+// by another moderator.
+func lockTargetReports(ctx context.Context, tx *sql.Tx, report *entity.Report, moderatorID int64) error {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT claimed_by FROM reports
+		WHERE target_type = $1 AND target_id = $2 AND status IN ('open', 'claimed')
+		ORDER BY id
+		FOR UPDATE`,
+		report.TargetType, report.TargetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to lock reports: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var claimedBy sql.NullInt64
+		if err := rows.Scan(&claimedBy); err != nil {
+			return fmt.Errorf("failed to scan report: %w", err)
+		}
+		if claimedBy.Valid && claimedBy.Int64 != moderatorID {
+			return ErrReportClaimed
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating reports: %w", err)
+	}
+	return nil
+}
+
+// lockReport locks a report that moderatorID may still act on
+func lockReport(ctx context.Context, tx *sql.Tx, id, moderatorID int64) (*entity.Report, error) {
+	report, err := scanReport(tx.QueryRowContext(ctx,
+		`SELECT `+reportColumns+` FROM reports WHERE id = $1 FOR UPDATE`,
+		id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	switch report.Status {
+	case entity.ReportStatusResolved, entity.ReportStatusDismissed:
+		return nil, ErrReportClosed
+	case entity.ReportStatusClaimed:
+		if report.ClaimedBy == nil || *report.ClaimedBy != moderatorID {
+			return nil, ErrReportClaimed
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var reportTestColumns = []string{"id", "target_type", "target_id", "target_author_id", "content_snapshot", "reporter_id", "reason", "details",
+	"status", "claimed_by", "claimed_at", "resolved_by", "resolved_at", "action", "note", "created_at"}
+
+func newTestReportRepo(t *testing.T) (ReportRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	repo := &reportRepository{db: db}
+	return repo, mock, func() { db.Close() }
+}
+
+func reportRow(id int64, status entity.ReportStatus, claimedBy interface{}, now time.Time) []driver.Value {
+	return []driver.Value{id, "comment", int64(10), int64(3), "buy now", int64(7), "spam", "", string(status), claimedBy, nil, nil, nil, nil, "", now}
+}
+
+func TestReportRepository_GetReportTarget(t *testing.T) {
+	repo, mock, closeFn := newTestReportRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`SELECT author_id, content FROM chat_messages WHERE id = \$1`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "content"}).AddRow(int64(3), "hello"))
+	mock.ExpectQuery(`SELECT author_id, content FROM topics WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(int64(99)).
+		WillReturnError(sql.ErrNoRows)
+
+	target, err := repo.GetReportTarget(context.Background(), entity.ReportTargetChatMessage, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), *target.AuthorID)
+	assert.Equal(t, "hello", target.Content)
+
+	_, err = repo.GetReportTarget(context.Background(), entity.ReportTargetTopic, 99)
+	assert.ErrorIs(t, err, ErrReportTargetNotFound)
+
+	_, err = repo.GetReportTarget(context.Background(), "user", 1)
+	assert.ErrorIs(t, err, ErrReportTargetNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_CreateReport(t *testing.T) {
+	repo, mock, closeFn := newTestReportRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	authorID := int64(3)
+	report := &entity.Report{
+		TargetType: entity.ReportTargetComment, TargetID: 10, TargetAuthorID: &authorID,
+		ContentSnapshot: "buy now", ReporterID: 7, Reason: entity.ReportReasonSpam,
+	}
+
+	mock.ExpectQuery(`INSERT INTO reports .* ON CONFLICT \(target_type, target_id, reporter_id\) WHERE status IN \('open', 'claimed'\)\s+DO UPDATE`).
+		WithArgs(entity.ReportTargetComment, int64(10), &authorID, "buy now", int64(7), entity.ReportReasonSpam, "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at", "inserted"}).AddRow(int64(1), "open", now, true))
+
+	created, err := repo.CreateReport(context.Background(), report)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(1), report.ID)
+	assert.Equal(t, entity.ReportStatusOpen, report.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_AutoHideTarget(t *testing.T) {
+	repo, mock, closeFn := newTestReportRepo(t)
+	defer closeFn()
+
+	mock.ExpectExec(`UPDATE comments SET hidden_at = CURRENT_TIMESTAMP, hidden_by = NULL WHERE id = \$1 AND hidden_at IS NULL`).
+		WithArgs(int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.AutoHideTarget(context.Background(), entity.ReportTargetComment, 10))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_ListReports(t *testing.T) {
+	repo, mock, closeFn := newTestReportRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	columns := append(append([]string{}, reportTestColumns...), "target_reports")
+	mock.ExpectQuery(`FROM reports r\s+WHERE r.status IN \('open', 'claimed'\) AND r.target_type = \$1\s+ORDER BY r.created_at, r.id\s+LIMIT \$2`).
+		WithArgs(entity.ReportTargetComment, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(append(reportRow(1, entity.ReportStatusOpen, nil, now), 2)...).
+			AddRow(append(reportRow(2, entity.ReportStatusClaimed, int64(5), now.Add(time.Second)), 2)...))
+
+	page, err := repo.ListReports(context.Background(), entity.ReportFilter{TargetType: entity.ReportTargetComment, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Reports, 1)
+	assert.Equal(t, 2, page.Reports[0].TargetReports)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mock.ExpectQuery(`WHERE r.status = \$1 AND \(r.created_at, r.id\) > \(\$2, \$3\)`).
+		WithArgs(entity.ReportStatusOpen, sqlmock.AnyArg(), int64(1), 2).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	page, err = repo.ListReports(context.Background(), entity.ReportFilter{Status: entity.ReportStatusOpen, Cursor: page.NextCursor, Limit: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Reports)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_ClaimReport(t *testing.T) {
+	repo, mock, closeFn := newTestReportRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .* FROM reports WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(reportTestColumns).AddRow(reportRow(1, entity.ReportStatusOpen, nil, now)...))
+	mock.ExpectExec(`UPDATE reports SET status = 'claimed', claimed_by = \$2`).
+		WithArgs(int64(1), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT .* FROM reports WHERE id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(reportTestColumns).AddRow(reportRow(1, entity.ReportStatusClaimed, int64(5), now)...))
+
+	report, err := repo.ClaimReport(context.Background(), 1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.ReportStatusClaimed, report.Status)
+	assert.Equal(t, int64(5), *report.ClaimedBy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_ClaimReport_Conflicts(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		status    entity.ReportStatus
+		claimedBy interface{}
+		wantErr   error
+	}{
+		{name: "claimed by another moderator", status: entity.ReportStatusClaimed, claimedBy: int64(6), wantErr: ErrReportClaimed},
+		{name: "already resolved", status: entity.ReportStatusResolved, wantErr: ErrReportClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, closeFn := newTestReportRepo(t)
+			defer closeFn()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FOR UPDATE`).
+				WillReturnRows(sqlmock.NewRows(reportTestColumns).AddRow(reportRow(1, tt.status, tt.claimedBy, now)...))
+			mock.ExpectRollback()
+
+			_, err := repo.ClaimReport(context.Background(), 1, 5)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	repo, mock, closeFn := newTestReportRepo(t)
+	defer closeFn()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := repo.ClaimReport(context.Background(), 1, 5)
+	assert.ErrorIs(t, err, ErrReportNotFound)
+}
+
+func TestReportRepository_ResolveReport(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		action entity.ReportAction
+		expect func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name:   "hide",
+			action: entity.ReportActionHide,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE comments SET hidden_at = COALESCE\(hidden_at, CURRENT_TIMESTAMP\), hidden_by = \$2 WHERE id = \$1`).
+					WithArgs(int64(10), int64(5)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:   "delete",
+			action: entity.ReportActionDelete,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE comments SET deleted_at = COALESCE\(deleted_at, CURRENT_TIMESTAMP\), deleted_by = \$2 WHERE id = \$1 RETURNING topic_id`).
+					WithArgs(int64(10), int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"topic_id"}).AddRow(int64(4)))
+				mock.ExpectExec(`UPDATE topics\s+SET comment_count = \(\s+SELECT COUNT\(\*\)\s+FROM comments\s+WHERE topic_id = \$1 AND deleted_at IS NULL`).
+					WithArgs(int64(4)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:   "warn",
+			action: entity.ReportActionWarn,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO user_warnings \(user_id, moderator_id, report_id, reason\)`).
+					WithArgs(int64(3), int64(5), int64(1), "be nice").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE comments SET hidden_at = NULL WHERE id = \$1 AND hidden_at IS NOT NULL AND hidden_by IS NULL`).
+					WithArgs(int64(10)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, closeFn := newTestReportRepo(t)
+			defer closeFn()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FOR UPDATE`).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows(reportTestColumns).AddRow(reportRow(1, entity.ReportStatusClaimed, int64(5), now)...))
+			mock.ExpectQuery(`SELECT claimed_by FROM reports\s+WHERE target_type = \$1 AND target_id = \$2 AND status IN \('open', 'claimed'\)\s+ORDER BY id\s+FOR UPDATE`).
+				WithArgs(entity.ReportTargetComment, int64(10)).
+				WillReturnRows(sqlmock.NewRows([]string{"claimed_by"}).AddRow(int64(5)).AddRow(nil))
+			tt.expect(mock)
+			mock.ExpectExec(`UPDATE reports SET status = \$3, resolved_by = \$4, resolved_at = CURRENT_TIMESTAMP, action = \$5, note = \$6\s+WHERE target_type = \$1 AND target_id = \$2 AND status IN \('open', 'claimed'\)`).
+				WithArgs(entity.ReportTargetComment, int64(10), entity.ReportStatusResolved, int64(5), sqlmock.AnyArg(), "be nice").
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectCommit()
+			mock.ExpectQuery(`SELECT .* FROM reports WHERE id = \$1`).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows(reportTestColumns).AddRow(reportRow(1, entity.ReportStatusResolved, int64(5), now)...))
+
+			report, err := repo.ResolveReport(context.Background(), 1, 5, tt.action, "be nice")
+			assert.NoError(t, err)
+			assert.Equal(t, entity.ReportStatusResolved, report.Status)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReportRepository_DismissReport(t *testing.T) {
+	repo, mock, closeFn := newTestReportRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(reportTestColumns).AddRow(reportRow(1, entity.ReportStatusOpen, nil, now)...))
+	mock.ExpectQuery(`SELECT claimed_by FROM reports`).
+		WithArgs(entity.ReportTargetComment, int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"claimed_by"}).AddRow(nil))
+	mock.ExpectExec(`UPDATE comments SET hidden_at = NULL WHERE id = \$1 AND hidden_at IS NOT NULL AND hidden_by IS NULL`).
+		WithArgs(int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE reports SET status = \$3`).
+		WithArgs(entity.ReportTargetComment, int64(10), entity.ReportStatusDismissed, int64(5), nil, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT .* FROM reports WHERE id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(reportTestColumns).AddRow(reportRow(1, entity.ReportStatusDismissed, nil, now)...))
+
+	report, err := repo.DismissReport(context.Background(), 1, 5, "")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.ReportStatusDismissed, report.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_CloseReport_ClaimedElsewhere(t *testing.T) {
+	repo, mock, closeFn := newTestReportRepo(t)
+	defer closeFn()
+
+	// Жалоба открыта, но другую жалобу на тот же комментарий взял модератор 6
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(reportTestColumns).AddRow(reportRow(1, entity.ReportStatusOpen, nil, now)...))
+	mock.ExpectQuery(`SELECT claimed_by FROM reports`).
+		WithArgs(entity.ReportTargetComment, int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"claimed_by"}).AddRow(nil).AddRow(int64(6)))
+	mock.ExpectRollback()
+
+	_, err := repo.ResolveReport(context.Background(), 1, 5, entity.ReportActionHide, "")
+	assert.ErrorIs(t, err, ErrReportClaimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type RevisionRepository interface {
+	ListRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64) ([]*entity.Revision, error)
+	GetRevision(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, revision int) (*entity.Revision, error)
+}
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// revisionSource describes where the revisions of a kind of content are kept
+type revisionSource struct {
+	table string // table with the revisions
+	key   string // column referencing the edited row
+	title string // title expression; comments have none
+}
+
+var revisionSources = map[entity.RevisionTargetType]revisionSource{
+	entity.RevisionTargetTopic:   {table: "topic_revisions", key: "topic_id", title: "title"},
+	entity.RevisionTargetComment: {table: "comment_revisions", key: "comment_id", title: "''"},
+}
+
+type revisionRepository struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+func scanRevision(row topicScanner, targetType entity.RevisionTargetType) (*entity.Revision, error) {
+	revision := &entity.Revision{TargetType: targetType}
+	err := row.Scan(
+		&revision.ID,
+		&revision.TargetID,
+		&revision.Revision,
+		&revision.EditorID,
+		&revision.Title,
+		&revision.Content,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (s revisionSource) columns() string {
+	return `id, ` + s.key + `, revision, editor_id, ` + s.title + `, content, created_at`
+}
+
+// ListRevisions returns every earlier version of a topic or comment, oldest first
+func (r *revisionRepository) ListRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64) ([]*entity.Revision, error) {
+	source, ok := revisionSources[targetType]
+	if !ok {
+		return nil, ErrRevisionNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+source.columns()+` FROM `+source.table+` WHERE `+source.key+` = $1 ORDER BY revision`,
+		targetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*entity.Revision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows, targetType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (r *revisionRepository) GetRevision(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, number int) (*entity.Revision, error) {
+	source, ok := revisionSources[targetType]
+	if !ok {
+		return nil, ErrRevisionNotFound
+	}
+
+	revision, err := scanRevision(r.db.QueryRowContext(ctx,
+		`SELECT `+source.columns()+` FROM `+source.table+` WHERE `+source.key+` = $1 AND revision = $2`,
+		targetID, number,
+	), targetType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return revision, nil
+}
+
+// saveRevision stores the version of a topic or comment that an edit by
+// editorID (0 if unknown) is about to replace. It must run in the edit's
+// transaction with the edited row locked, so revision numbers don't clash.
+func saveRevision(ctx context.Context, tx *sql.Tx, previous *entity.Revision, editorID int64) error {
+	source, ok := revisionSources[previous.TargetType]
+	if !ok {
+		return fmt.Errorf("no revisions for %s", previous.TargetType)
+	}
+
+	columns, values := source.key+`, revision, editor_id, content`, `$1, COALESCE(MAX(revision), 0) + 1, NULLIF($2::bigint, 0), $3`
+	args := []interface{}{previous.TargetID, editorID, previous.Content}
+	if previous.TargetType == entity.RevisionTargetTopic {
+		columns += `, title`
+		values += `, $4`
+		args = append(args, previous.Title)
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO `+source.table+` (`+columns+`)
+		SELECT `+values+` FROM `+source.table+` WHERE `+source.key+` = $1`,
+		args...,
+	)
+	if err != nil {
+		log.Printf("Error saving revision of %s %d: %v", previous.TargetType, previous.TargetID, err)
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+	return nil
+}
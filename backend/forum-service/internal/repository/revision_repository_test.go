@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var revisionTestColumns = []string{"id", "target_id", "revision", "editor_id", "title", "content", "created_at"}
+
+func newTestRevisionRepo(t *testing.T) (RevisionRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	repo := &revisionRepository{db: db}
+	return repo, mock, func() { db.Close() }
+}
+
+func TestRevisionRepository_ListRevisions(t *testing.T) {
+	repo, mock, closeFn := newTestRevisionRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, topic_id, revision, editor_id, title, content, created_at FROM topic_revisions WHERE topic_id = \$1 ORDER BY revision`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(revisionTestColumns).
+			AddRow(int64(10), int64(1), 1, int64(2), "First", "First text", now).
+			AddRow(int64(11), int64(1), 2, nil, "Second", "Second text", now))
+	mock.ExpectQuery(`SELECT id, comment_id, revision, editor_id, '', content, created_at FROM comment_revisions WHERE comment_id = \$1`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(revisionTestColumns))
+
+	revisions, err := repo.ListRevisions(context.Background(), entity.RevisionTargetTopic, 1)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, entity.RevisionTargetTopic, revisions[0].TargetType)
+	assert.Equal(t, 1, revisions[0].Revision)
+	assert.Equal(t, int64(2), *revisions[0].EditorID)
+	assert.Equal(t, "Second", revisions[1].Title)
+	assert.Nil(t, revisions[1].EditorID)
+
+	revisions, err = repo.ListRevisions(context.Background(), entity.RevisionTargetComment, 5)
+	assert.NoError(t, err)
+	assert.NotNil(t, revisions)
+	assert.Empty(t, revisions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevisionRepository_GetRevision(t *testing.T) {
+	repo, mock, closeFn := newTestRevisionRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`FROM comment_revisions WHERE comment_id = \$1 AND revision = \$2`).
+		WithArgs(int64(5), 2).
+		WillReturnRows(sqlmock.NewRows(revisionTestColumns).
+			AddRow(int64(7), int64(5), 2, int64(3), "", "Old text", time.Now()))
+	mock.ExpectQuery(`FROM comment_revisions WHERE comment_id = \$1 AND revision = \$2`).
+		WithArgs(int64(5), 9).
+		WillReturnError(sql.ErrNoRows)
+
+	revision, err := repo.GetRevision(context.Background(), entity.RevisionTargetComment, 5, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Old text", revision.Content)
+	assert.Equal(t, entity.RevisionTargetComment, revision.TargetType)
+
+	_, err = repo.GetRevision(context.Background(), entity.RevisionTargetComment, 5, 9)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+
+	_, err = repo.GetRevision(context.Background(), entity.RevisionTargetType("chat_message"), 5, 1)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error)
+}
+
+// ts_headline wraps matches in these private-use characters instead of HTML
+// tags, so the surrounding user text can be escaped before the real <mark>
+// tags are put in.
+const (
+	snippetStartSel = "\uE000"
+	snippetStopSel  = "\uE001"
+)
+
+var snippetHeadlineOptions = fmt.Sprintf(
+	`StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`,
+	snippetStartSel, snippetStopSel,
+)
+
+type searchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+func (r *searchRepository) Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = entity.DefaultSearchPageSize
+	}
+	if limit > entity.MaxSearchPageSize {
+		limit = entity.MaxSearchPageSize
+	}
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	// $1 — поисковый запрос, $2 — настройки ts_headline
+	args := []interface{}{query.Query, snippetHeadlineOptions}
+	var topicFilters, commentFilters []string
+	if query.CategoryID > 0 {
+		args = append(args, query.CategoryID)
+		cond := fmt.Sprintf("t.category_id = $%d", len(args))
+		topicFilters = append(topicFilters, cond)
+		commentFilters = append(commentFilters, cond)
+	}
+	if query.AuthorID > 0 {
+		args = append(args, query.AuthorID)
+		topicFilters = append(topicFilters, fmt.Sprintf("t.author_id = $%d", len(args)))
+		commentFilters = append(commentFilters, fmt.Sprintf("c.author_id = $%d", len(args)))
+	}
+	if query.From != nil {
+		args = append(args, *query.From)
+		topicFilters = append(topicFilters, fmt.Sprintf("t.created_at >= $%d", len(args)))
+		commentFilters = append(commentFilters, fmt.Sprintf("c.created_at >= $%d", len(args)))
+	}
+	if query.To != nil {
+		args = append(args, *query.To)
+		topicFilters = append(topicFilters, fmt.Sprintf("t.created_at < $%d", len(args)))
+		commentFilters = append(commentFilters, fmt.Sprintf("c.created_at < $%d", len(args)))
+	}
+	args = append(args, limit+1, offset)
+
+	sqlQuery := fmt.Sprintf(`
+		WITH q AS (
+			SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS query
+		)
+		SELECT type, id, topic_id, topic_title, category_id, author_id, snippet, rank, created_at
+		FROM (
+			SELECT 'topic' AS type, t.id, t.id AS topic_id, t.title AS topic_title,
+				COALESCE(t.category_id, 0) AS category_id, t.author_id,
+				ts_headline('russian', t.content, q.query, $2) AS snippet,
+				ts_rank_cd(t.search_vector, q.query, 32) AS rank, t.created_at
+			FROM topics t, q
+			WHERE t.search_vector @@ q.query AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL%s
+			UNION ALL
+			SELECT 'comment', c.id, c.topic_id, t.title,
+				COALESCE(t.category_id, 0), c.author_id,
+				ts_headline('russian', c.content, q.query, $2),
+				ts_rank_cd(c.search_vector, q.query, 32), c.created_at
+			FROM comments c JOIN topics t ON t.id = c.topic_id, q
+			WHERE c.search_vector @@ q.query AND c.hidden_at IS NULL AND c.deleted_at IS NULL
+				AND t.hidden_at IS NULL AND t.deleted_at IS NULL%s
+		) results
+		ORDER BY rank DESC, created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d`,
+		joinFilters(topicFilters), joinFilters(commentFilters), len(args)-1, len(args),
+	)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		log.Printf("Error searching for %q: %v", query.Query, err)
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.SearchPage{Results: make([]*entity.SearchResult, 0, limit)}
+	for rows.Next() {
+		result := &entity.SearchResult{}
+		var snippet string
+		if err := rows.Scan(
+			&result.Type,
+			&result.ID,
+			&result.TopicID,
+			&result.TopicTitle,
+			&result.CategoryID,
+			&result.AuthorID,
+			&snippet,
+			&result.Rank,
+			&result.CreatedAt,
+		); err != nil {
+			log.Printf("Error scanning search result: %v", err)
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		result.Snippet = highlightSnippet(snippet)
+		page.Results = append(page.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating search results: %v", err)
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	if len(page.Results) > limit {
+		page.Results = page.Results[:limit]
+		page.NextOffset = offset + limit
+	}
+	return page, nil
+}
+
+func joinFilters(filters []string) string {
+	if len(filters) == 0 {
+		return ""
+	}
+	return " AND " + strings.Join(filters, " AND ")
+}
+
+// highlightSnippet escapes a ts_headline fragment and turns the match
+// markers into <mark> tags.
+func highlightSnippet(raw string) string {
+	escaped := html.EscapeString(raw)
+	escaped = strings.ReplaceAll(escaped, snippetStartSel, "<mark>")
+	return strings.ReplaceAll(escaped, snippetStopSel, "</mark>")
+}
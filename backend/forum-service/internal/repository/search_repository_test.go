@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var searchTestColumns = []string{"type", "id", "topic_id", "topic_title", "category_id", "author_id", "snippet", "rank", "created_at"}
+
+func newTestSearchRepo(t *testing.T) (SearchRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	repo := NewSearchRepository(db)
+	return repo, mock, func() { db.Close() }
+}
+
+func TestSearchRepository_Search(t *testing.T) {
+	repo, mock, closeFn := newTestSearchRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	rows := sqlmock.NewRows(searchTestColumns).
+		AddRow("topic", 1, 1, "Go", 2, 3, "Учим Go <b>вместе</b>", 0.8, now).
+		AddRow("comment", 7, 1, "Go", 2, 4, "горутины", 0.5, now)
+
+	mock.ExpectQuery(`websearch_to_tsquery\('russian', \$1\) \|\| websearch_to_tsquery\('english', \$1\)`).
+		WithArgs("go", snippetHeadlineOptions, entity.DefaultSearchPageSize+1, 0).
+		WillReturnRows(rows)
+
+	page, err := repo.Search(context.Background(), entity.SearchQuery{Query: "go"})
+	assert.NoError(t, err)
+	assert.Len(t, page.Results, 2)
+	assert.Equal(t, entity.SearchResultTopic, page.Results[0].Type)
+	assert.Equal(t, "Учим <mark>Go</mark> &lt;b&gt;вместе&lt;/b&gt;", page.Results[0].Snippet)
+	assert.Equal(t, entity.SearchResultComment, page.Results[1].Type)
+	assert.Equal(t, int64(7), page.Results[1].ID)
+	assert.Equal(t, 0, page.NextOffset)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchRepository_Search_Filters(t *testing.T) {
+	repo, mock, closeFn := newTestSearchRepo(t)
+	defer closeFn()
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`t\.category_id = \$3 AND t\.author_id = \$4 AND t\.created_at >= \$5 AND t\.created_at < \$6`+
+		`(?s:.*)t\.category_id = \$3 AND c\.author_id = \$4 AND c\.created_at >= \$5 AND c\.created_at < \$6`+
+		`(?s:.*)LIMIT \$7 OFFSET \$8`).
+		WithArgs("форум", snippetHeadlineOptions, int64(2), int64(3), from, to, 11, 20).
+		WillReturnRows(sqlmock.NewRows(searchTestColumns))
+
+	page, err := repo.Search(context.Background(), entity.SearchQuery{
+		Query:      "форум",
+		CategoryID: 2,
+		AuthorID:   3,
+		From:       &from,
+		To:         &to,
+		Limit:      10,
+		Offset:     20,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchRepository_Search_NextOffset(t *testing.T) {
+	repo, mock, closeFn := newTestSearchRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	rows := sqlmock.NewRows(searchTestColumns)
+	for i := 1; i <= 3; i++ {
+		rows.AddRow("comment", i, 1, "Go", 1, 1, "snippet", 0.1, now)
+	}
+	mock.ExpectQuery(`LIMIT \$3 OFFSET \$4`).
+		WithArgs("go", snippetHeadlineOptions, 3, 4).
+		WillReturnRows(rows)
+
+	page, err := repo.Search(context.Background(), entity.SearchQuery{Query: "go", Limit: 2, Offset: 4})
+	assert.NoError(t, err)
+	assert.Len(t, page.Results, 2)
+	assert.Equal(t, 6, page.NextOffset)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchRepository_Search_Error(t *testing.T) {
+	repo, mock, closeFn := newTestSearchRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`websearch_to_tsquery`).WillReturnError(errors.New("db error"))
+
+	page, err := repo.Search(context.Background(), entity.SearchQuery{Query: "go"})
+	assert.Error(t, err)
+	assert.Nil(t, page)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
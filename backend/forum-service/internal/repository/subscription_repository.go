@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type SubscriptionRepository interface {
+	GetSubscription(ctx context.Context, userID, topicID int64) (*entity.TopicSubscription, error)
+	SetSubscription(ctx context.Context, subscription *entity.TopicSubscription) error
+	DeleteSubscription(ctx context.Context, userID, topicID int64) error
+	GetWatchedTopics(ctx context.Context, filter entity.WatchedTopicFilter) (*entity.WatchedTopicPage, error)
+	GetSubscribers(ctx context.Context, topicID int64) (map[int64]entity.WatchLevel, error)
+	MarkTopicRead(ctx context.Context, userID, topicID int64) error
+	GetWatchSettings(ctx context.Context, userID int64) (*entity.WatchSettings, error)
+	SetWatchSettings(ctx context.Context, userID int64, settings *entity.WatchSettings) error
+}
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+const watchedTopicsCursorKey = "watched"
+
+type subscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewSubscriptionRepository(db *sql.DB) SubscriptionRepository {
+	return &subscriptionRepository{db: db}
+}
+
+// autoSubscribe subscribes a user to a topic they created or commented on,
+// unless they already have a subscription or turned automatic ones off.
+// Comments already in the topic count as read.
+func autoSubscribe(ctx context.Context, q dbtx, userID, topicID int64, level entity.WatchLevel) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO topic_subscriptions (user_id, topic_id, level, last_read_comment_id)
+		SELECT $1::bigint, $2::bigint, $3::text, COALESCE((SELECT MAX(id) FROM comments WHERE topic_id = $2), 0)
+		WHERE EXISTS (SELECT 1 FROM users WHERE id = $1)
+			AND NOT EXISTS (SELECT 1 FROM watch_settings WHERE user_id = $1 AND NOT auto_watch)
+		ON CONFLICT (user_id, topic_id) DO NOTHING`,
+		userID, topicID, level,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe user to topic: %w", err)
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) GetSubscription(ctx context.Context, userID, topicID int64) (*entity.TopicSubscription, error) {
+	subscription := &entity.TopicSubscription{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, topic_id, level, created_at, updated_at
+		FROM topic_subscriptions
+		WHERE user_id = $1 AND topic_id = $2`,
+		userID, topicID,
+	).Scan(
+		&subscription.UserID,
+		&subscription.TopicID,
+		&subscription.Level,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// SetSubscription creates or changes a user's subscription to a topic and
+// fills in its timestamps. A new subscription starts with every existing
+// comment read.
+func (r *subscriptionRepository) SetSubscription(ctx context.Context, subscription *entity.TopicSubscription) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO topic_subscriptions (user_id, topic_id, level, last_read_comment_id)
+		SELECT $1::bigint, $2::bigint, $3::text, COALESCE((SELECT MAX(id) FROM comments WHERE topic_id = $2), 0)
+		WHERE EXISTS (SELECT 1 FROM topics WHERE id = $2 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NULL)
+		ON CONFLICT (user_id, topic_id)
+		DO UPDATE SET level = EXCLUDED.level, updated_at = CURRENT_TIMESTAMP
+		RETURNING created_at, updated_at`,
+		subscription.UserID, subscription.TopicID, subscription.Level,
+	).Scan(&subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrTopicNotFound
+		}
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteSubscription removes a user's subscription to a topic. The user is
+// subscribed again if they comment in the topic later; muting the topic
+// prevents that.
+func (r *subscriptionRepository) DeleteSubscription(ctx context.Context, userID, topicID int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM topic_subscriptions WHERE user_id = $1 AND topic_id = $2`,
+		userID, topicID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// GetWatchedTopics returns a page of the topics a user follows, most
+// recently active first, with the number of comments by other users since
+// the user last opened each topic
+func (r *subscriptionRepository) GetWatchedTopics(ctx context.Context, filter entity.WatchedTopicFilter) (*entity.WatchedTopicPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = entity.DefaultWatchedTopicsPageSize
+	}
+	if limit > entity.MaxWatchedTopicsPageSize {
+		limit = entity.MaxWatchedTopicsPageSize
+	}
+
+	args := []interface{}{filter.UserID}
+	conditions := "s.user_id = $1 AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL"
+	// Заглушенные темы показываются, только если их запросили явно
+	if filter.Level != "" {
+		args = append(args, filter.Level)
+		conditions += fmt.Sprintf(" AND s.level = $%d", len(args))
+	} else {
+		conditions += " AND s.level <> 'muted'"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor, watchedTopicsCursorKey)
+		if err != nil {
+			return nil, err
+		}
+		before, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		args = append(args, before, cursor.ID)
+		conditions += fmt.Sprintf(" AND (t.last_activity_at, t.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	// Берем на одну запись больше, чтобы понять, есть ли следующая страница
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT t.id, t.title, t.comment_count, t.last_activity_at, s.level,
+			(SELECT COUNT(*) FROM comments c
+			WHERE c.topic_id = t.id AND c.id > s.last_read_comment_id AND c.author_id <> s.user_id
+				AND c.hidden_at IS NULL AND c.deleted_at IS NULL)
+		FROM topic_subscriptions s
+		JOIN topics t ON t.id = s.topic_id
+		WHERE %s
+		ORDER BY t.last_activity_at DESC, t.id DESC
+		LIMIT $%d`, conditions, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watched topics: %w", err)
+	}
+	defer rows.Close()
+
+	page := &entity.WatchedTopicPage{Topics: []*entity.WatchedTopic{}}
+	for rows.Next() {
+		topic := &entity.WatchedTopic{}
+		err := rows.Scan(
+			&topic.TopicID,
+			&topic.Title,
+			&topic.CommentCount,
+			&topic.LastActivityAt,
+			&topic.Level,
+			&topic.UnreadComments,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan watched topic: %w", err)
+		}
+		page.Topics = append(page.Topics, topic)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating watched topics: %w", err)
+	}
+
+	if len(page.Topics) > limit {
+		page.Topics = page.Topics[:limit]
+		last := page.Topics[limit-1]
+		page.NextCursor = encodeCursor(watchedTopicsCursorKey, last.LastActivityAt.Format(time.RFC3339Nano), last.TopicID)
+	}
+	return page, nil
+}
+
+// GetSubscribers returns the watch level of every user subscribed to a topic
+func (r *subscriptionRepository) GetSubscribers(ctx context.Context, topicID int64) (map[int64]entity.WatchLevel, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, level FROM topic_subscriptions WHERE topic_id = $1`,
+		topicID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	subscribers := make(map[int64]entity.WatchLevel)
+	for rows.Next() {
+		var userID int64
+		var level entity.WatchLevel
+		if err := rows.Scan(&userID, &level); err != nil {
+			return nil, fmt.Errorf("failed to scan subscriber: %w", err)
+		}
+		subscribers[userID] = level
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subscribers: %w", err)
+	}
+	return subscribers, nil
+}
+
+// MarkTopicRead marks every comment in a topic as read by a subscribed
+// user. Nothing is recorded for users who aren't subscribed.
+func (r *subscriptionRepository) MarkTopicRead(ctx context.Context, userID, topicID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE topic_subscriptions
+		SET last_read_comment_id = COALESCE((SELECT MAX(id) FROM comments WHERE topic_id = $2), 0)
+		WHERE user_id = $1 AND topic_id = $2`,
+		userID, topicID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark topic read: %w", err)
+	}
+	return nil
+}
+
+// GetWatchSettings returns a user's subscription preferences, or the
+// defaults if the user never changed them
+func (r *subscriptionRepository) GetWatchSettings(ctx context.Context, userID int64) (*entity.WatchSettings, error) {
+	settings := &entity.WatchSettings{AutoWatch: true}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT auto_watch FROM watch_settings WHERE user_id = $1`,
+		userID,
+	).Scan(&settings.AutoWatch)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get watch settings: %w", err)
+	}
+	return settings, nil
+}
+
+func (r *subscriptionRepository) SetWatchSettings(ctx context.Context, userID int64, settings *entity.WatchSettings) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO watch_settings (user_id, auto_watch) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET auto_watch = EXCLUDED.auto_watch`,
+		userID, settings.AutoWatch,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save watch settings: %w", err)
+	}
+	return nil
+}
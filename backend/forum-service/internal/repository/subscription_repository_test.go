@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+var watchedTopicTestColumns = []string{"id", "title", "comment_count", "last_activity_at", "level", "unread"}
+
+func newTestSubscriptionRepo(t *testing.T) (SubscriptionRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	return NewSubscriptionRepository(db), mock, func() { db.Close() }
+}
+
+func TestSubscriptionRepository_SetSubscription(t *testing.T) {
+	repo, mock, closeFn := newTestSubscriptionRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`INSERT INTO topic_subscriptions[\s\S]+ON CONFLICT \(user_id, topic_id\)\s+DO UPDATE SET level = EXCLUDED.level`).
+		WithArgs(int64(2), int64(4), entity.WatchLevelMuted).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now.Add(-time.Hour), now))
+	// Удаленная тема не находится
+	mock.ExpectQuery(`INSERT INTO topic_subscriptions`).
+		WithArgs(int64(2), int64(9), entity.WatchLevelWatching).
+		WillReturnError(sql.ErrNoRows)
+
+	subscription := &entity.TopicSubscription{UserID: 2, TopicID: 4, Level: entity.WatchLevelMuted}
+	assert.NoError(t, repo.SetSubscription(context.Background(), subscription))
+	assert.Equal(t, now, subscription.UpdatedAt)
+
+	err := repo.SetSubscription(context.Background(), &entity.TopicSubscription{UserID: 2, TopicID: 9, Level: entity.WatchLevelWatching})
+	assert.ErrorIs(t, err, ErrTopicNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubscriptionRepository_DeleteSubscription(t *testing.T) {
+	repo, mock, closeFn := newTestSubscriptionRepo(t)
+	defer closeFn()
+
+	mock.ExpectExec(`DELETE FROM topic_subscriptions WHERE user_id = \$1 AND topic_id = \$2`).
+		WithArgs(int64(2), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM topic_subscriptions`).
+		WithArgs(int64(2), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.DeleteSubscription(context.Background(), 2, 4))
+	assert.ErrorIs(t, repo.DeleteSubscription(context.Background(), 2, 4), ErrSubscriptionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubscriptionRepository_GetWatchedTopics(t *testing.T) {
+	repo, mock, closeFn := newTestSubscriptionRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`WHERE s.user_id = \$1 AND t.hidden_at IS NULL AND t.deleted_at IS NULL AND t.publish_at IS NULL AND s.level <> 'muted'\s+ORDER BY t.last_activity_at DESC, t.id DESC\s+LIMIT \$2`).
+		WithArgs(int64(2), 2).
+		WillReturnRows(sqlmock.NewRows(watchedTopicTestColumns).
+			AddRow(4, "Go generics", 12, now, "watching", 3).
+			AddRow(3, "Channels", 5, now.Add(-time.Hour), "tracking", 0))
+
+	page, err := repo.GetWatchedTopics(context.Background(), entity.WatchedTopicFilter{UserID: 2, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 1)
+	assert.Equal(t, 3, page.Topics[0].UnreadComments)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mock.ExpectQuery(`AND s.level = \$2 AND \(t.last_activity_at, t.id\) < \(\$3, \$4\)`).
+		WithArgs(int64(2), entity.WatchLevelMuted, now, int64(4), 2).
+		WillReturnRows(sqlmock.NewRows(watchedTopicTestColumns))
+
+	page, err = repo.GetWatchedTopics(context.Background(), entity.WatchedTopicFilter{UserID: 2, Level: entity.WatchLevelMuted, Cursor: page.NextCursor, Limit: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Topics)
+
+	_, err = repo.GetWatchedTopics(context.Background(), entity.WatchedTopicFilter{UserID: 2, Cursor: encodeCursor(notificationsCursorKey, now.Format(time.RFC3339Nano), 4)})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubscriptionRepository_GetSubscribers(t *testing.T) {
+	repo, mock, closeFn := newTestSubscriptionRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(`SELECT user_id, level FROM topic_subscriptions WHERE topic_id = \$1`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "level"}).
+			AddRow(2, "watching").
+			AddRow(3, "muted"))
+
+	subscribers, err := repo.GetSubscribers(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]entity.WatchLevel{2: entity.WatchLevelWatching, 3: entity.WatchLevelMuted}, subscribers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubscriptionRepository_WatchSettings(t *testing.T) {
+	repo, mock, closeFn := newTestSubscriptionRepo(t)
+	defer closeFn()
+
+	// Пока настройки не меняли, автоподписка включена
+	mock.ExpectQuery(`SELECT auto_watch FROM watch_settings WHERE user_id = \$1`).
+		WithArgs(int64(2)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO watch_settings \(user_id, auto_watch\) VALUES \(\$1, \$2\)`).
+		WithArgs(int64(2), false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	settings, err := repo.GetWatchSettings(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.True(t, settings.AutoWatch)
+	assert.NoError(t, repo.SetWatchSettings(context.Background(), 2, &entity.WatchSettings{AutoWatch: false}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+)
+
+type TagRepository interface {
+	GetAllTags(ctx context.Context) ([]*entity.Tag, error)
+	GetTagByID(ctx context.Context, id int64) (*entity.Tag, error)
+	GetTagsByTopicIDs(ctx context.Context, topicIDs []int64) (map[int64][]*entity.Tag, error)
+	RenameTag(ctx context.Context, id int64, name string) error
+	MergeTags(ctx context.Context, sourceID, targetID int64) error
+}
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag with this name already exists")
+)
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// GetAllTags returns tags that are in use, most used first
+func (r *tagRepository) GetAllTags(ctx context.Context) ([]*entity.Tag, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.id, t.name, t.created_at, COUNT(tt.topic_id) AS topic_count
+		FROM tags t
+		JOIN topic_tags tt ON tt.tag_id = t.id
+		GROUP BY t.id
+		ORDER BY topic_count DESC, t.name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*entity.Tag{}
+	for rows.Next() {
+		tag := &entity.Tag{}
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.TopicCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) GetTagByID(ctx context.Context, id int64) (*entity.Tag, error) {
+	tag := &entity.Tag{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, name, created_at FROM tags WHERE id = $1`, id,
+	).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return tag, nil
+}
+
+// GetTagsByTopicIDs loads the tags of several topics in one query
+func (r *tagRepository) GetTagsByTopicIDs(ctx context.Context, topicIDs []int64) (map[int64][]*entity.Tag, error) {
+	result := make(map[int64][]*entity.Tag, len(topicIDs))
+	if len(topicIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tt.topic_id, t.id, t.name, t.created_at
+		FROM topic_tags tt
+		JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.topic_id = ANY($1)
+		ORDER BY t.name`,
+		pq.Array(topicIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query topic tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var topicID int64
+		tag := &entity.Tag{}
+		if err := rows.Scan(&topicID, &tag.ID, &tag.Name, &tag.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan topic tag: %w", err)
+		}
+		result[topicID] = append(result[topicID], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating topic tags: %w", err)
+	}
+	return result, nil
+}
+
+// setTopicTags replaces the tags of a topic within q. Topics are tagged
+// through it in the same transaction that saves them.
+func setTopicTags(ctx context.Context, q dbtx, topicID int64, names []string) ([]*entity.Tag, error) {
+	if _, err := q.ExecContext(ctx, `DELETE FROM topic_tags WHERE topic_id = $1`, topicID); err != nil {
+		log.Printf("Error clearing tags of topic %d: %v", topicID, err)
+		return nil, fmt.Errorf("failed to clear topic tags: %w", err)
+	}
+
+	tags := make([]*entity.Tag, 0, len(names))
+	for _, name := range names {
+		tag := &entity.Tag{}
+		// DO UPDATE вместо DO NOTHING, чтобы RETURNING вернул уже существующий тег
+		err := q.QueryRowContext(ctx,
+			`INSERT INTO tags (name) VALUES ($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id, name, created_at`,
+			name,
+		).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+		if err != nil {
+			log.Printf("Error saving tag %q: %v", name, err)
+			return nil, fmt.Errorf("failed to save tag: %w", err)
+		}
+
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO topic_tags (topic_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			topicID, tag.ID,
+		); err != nil {
+			log.Printf("Error tagging topic %d with %q: %v", topicID, name, err)
+			return nil, fmt.Errorf("failed to tag topic: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) RenameTag(ctx context.Context, id int64, name string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE tags SET name = $1 WHERE id = $2`, name, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrTagExists
+		}
+		log.Printf("Error renaming tag %d: %v", id, err)
+		return fmt.Errorf("failed to rename tag: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// MergeTags moves every topic tagged with the source tag over to the target
+// tag and removes the source tag.
+func (r *tagRepository) MergeTags(ctx context.Context, sourceID, targetID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var found int
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM tags WHERE id IN ($1, $2)`, sourceID, targetID,
+	).Scan(&found); err != nil {
+		return fmt.Errorf("failed to check tags: %w", err)
+	}
+	if found != 2 {
+		return ErrTagNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO topic_tags (topic_id, tag_id)
+		SELECT topic_id, $2 FROM topic_tags WHERE tag_id = $1
+		ON CONFLICT DO NOTHING`,
+		sourceID, targetID,
+	); err != nil {
+		log.Printf("Error moving topics from tag %d to %d: %v", sourceID, targetID, err)
+		return fmt.Errorf("failed to merge tags: %w", err)
+	}
+
+	// Связи со старым тегом удаляются каскадно
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, sourceID); err != nil {
+		log.Printf("Error deleting merged tag %d: %v", sourceID, err)
+		return fmt.Errorf("failed to delete merged tag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTagRepo(t *testing.T) (TagRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	repo := NewTagRepository(db)
+	return repo, mock, func() { db.Close() }
+}
+
+func TestTagRepository_GetAllTags(t *testing.T) {
+	repo, mock, closeFn := newTestTagRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY topic_count DESC, t.name`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at", "topic_count"}).
+			AddRow(1, "golang", now, 5).
+			AddRow(2, "postgres", now, 2))
+
+	tags, err := repo.GetAllTags(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, tags, 2)
+	assert.Equal(t, "golang", tags[0].Name)
+	assert.Equal(t, 5, tags[0].TopicCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTagRepository_GetTagsByTopicIDs(t *testing.T) {
+	repo, mock, closeFn := newTestTagRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE tt.topic_id = ANY($1)`)).
+		WithArgs(pq.Array([]int64{1, 2})).
+		WillReturnRows(sqlmock.NewRows([]string{"topic_id", "id", "name", "created_at"}).
+			AddRow(1, 1, "golang", now).
+			AddRow(1, 2, "postgres", now))
+
+	tags, err := repo.GetTagsByTopicIDs(context.Background(), []int64{1, 2})
+	assert.NoError(t, err)
+	assert.Len(t, tags[1], 2)
+	assert.Empty(t, tags[2])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTagRepository_RenameTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		affected int64
+		wantErr  error
+	}{
+		{name: "success", affected: 1},
+		{name: "not found", affected: 0, wantErr: ErrTagNotFound},
+		{name: "name taken", err: &pq.Error{Code: uniqueViolation}, wantErr: ErrTagExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, closeFn := newTestTagRepo(t)
+			defer closeFn()
+
+			expect := mock.ExpectExec(regexp.QuoteMeta(`UPDATE tags SET name = $1 WHERE id = $2`)).WithArgs("golang", int64(1))
+			if tt.err != nil {
+				expect.WillReturnError(tt.err)
+			} else {
+				expect.WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			}
+
+			err := repo.RenameTag(context.Background(), 1, "golang")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTagRepository_MergeTags(t *testing.T) {
+	repo, mock, closeFn := newTestTagRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM tags WHERE id IN ($1, $2)`)).
+		WithArgs(int64(2), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT topic_id, $2 FROM topic_tags WHERE tag_id = $1`)).
+		WithArgs(int64(2), int64(1)).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM tags WHERE id = $1`)).
+		WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.MergeTags(context.Background(), 2, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTagRepository_MergeTags_NotFound(t *testing.T) {
+	repo, mock, closeFn := newTestTagRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM tags WHERE id IN ($1, $2)`)).
+		WithArgs(int64(2), int64(99)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := repo.MergeTags(context.Background(), 2, 99)
+	assert.ErrorIs(t, err, ErrTagNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,38 +3,209 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/markup"
 )
 
 type TopicRepository interface {
 	CreateTopic(ctx context.Context, topic *entity.Topic) error
 	GetTopicByID(ctx context.Context, id int64) (*entity.Topic, error)
-	GetAllTopics(ctx context.Context) ([]*entity.Topic, error)
-	UpdateTopic(ctx context.Context, topic *entity.Topic) error
-	DeleteTopic(ctx context.Context, id int64) error
+	GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error)
+	UpdateTopic(ctx context.Context, topic *entity.Topic, editorID int64) error
+	DeleteTopic(ctx context.Context, id, deletedBy int64) error
+	RestoreTopic(ctx context.Context, id int64) error
+	PurgeDeletedTopics(ctx context.Context, before time.Time) (int64, error)
 	UpdateCommentCount(ctx context.Context, topicID int64) error
+	AddViews(ctx context.Context, views map[int64]int) error
+	SetPin(ctx context.Context, id int64, scope entity.PinScope, until *time.Time) error
+	SetLock(ctx context.Context, id int64, locked bool, until *time.Time) error
+	SetAnnouncement(ctx context.Context, id int64, announcement bool, until *time.Time) error
+	GetScheduledTopic(ctx context.Context, id int64) (*entity.Topic, error)
+	GetScheduledTopics(ctx context.Context, authorID int64) ([]*entity.Topic, error)
+	RescheduleTopic(ctx context.Context, id int64, publishAt time.Time) error
+	PublishDueTopics(ctx context.Context, now time.Time, limit int) ([]*entity.Topic, error)
+}
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort")
+	ErrInvalidPeriod = errors.New("invalid period")
+	ErrTopicNotFound = errors.New("topic not found")
+	ErrTopicLocked   = errors.New("topic is locked")
+)
+
+// topicSortColumns maps a listing order to the column it is keyed on.
+// Every listing is ordered by (column DESC, id DESC).
+var topicSortColumns = map[entity.TopicSort]string{
+	entity.TopicSortNewest:   "created_at",
+	entity.TopicSortViews:    "views",
+	entity.TopicSortComments: "comment_count",
+	entity.TopicSortActivity: "last_activity_at",
+	entity.TopicSortHot:      "hot_score",
+	entity.TopicSortTop:      "top_score",
+}
+
+// Points a topic earns for activity: a topic counts as topicPoints when it
+// is published, every view as topicViewPoints. Comments and likes are
+// counted by triggers on comments and comment_likes.
+const (
+	topicPoints     = "1"
+	topicViewPoints = "0.1"
+)
+
+// topicPeriodIntervals maps the period of a top listing to how far back
+// topics are taken from; an empty interval takes all of them
+var topicPeriodIntervals = map[entity.TopicPeriod]string{
+	entity.TopicPeriodWeek:  "7 days",
+	entity.TopicPeriodMonth: "30 days",
+	entity.TopicPeriodAll:   "",
+}
+
+// Moderation flags of a topic that are still in effect
+const (
+	topicPinnedExpr       = `(pin_scope IS NOT NULL AND (pinned_until IS NULL OR pinned_until > CURRENT_TIMESTAMP))`
+	topicGlobalPinnedExpr = `(pin_scope = 'global' AND (pinned_until IS NULL OR pinned_until > CURRENT_TIMESTAMP))`
+	topicLockedExpr       = `(locked AND (locked_until IS NULL OR locked_until > CURRENT_TIMESTAMP))`
+	topicAnnouncementExpr = `(announcement AND (announcement_until IS NULL OR announcement_until > CURRENT_TIMESTAMP))`
+)
+
+// topicColumns are scanned by scanTopic. Expired moderation flags are
+// returned as unset.
+const topicColumns = `id, title, content, COALESCE(content_html, ''), author_id, category_id, views, comment_count, created_at, updated_at, last_activity_at, ` +
+	`CASE WHEN ` + topicPinnedExpr + ` THEN pin_scope ELSE '' END, CASE WHEN ` + topicPinnedExpr + ` THEN pinned_until END, ` +
+	topicLockedExpr + `, CASE WHEN ` + topicLockedExpr + ` THEN locked_until END, ` +
+	topicAnnouncementExpr + `, CASE WHEN ` + topicAnnouncementExpr + ` THEN announcement_until END, publish_at, hot_score, top_score`
+
+type topicScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTopic(row topicScanner) (*entity.Topic, error) {
+	topic := &entity.Topic{}
+	var pinnedUntil, lockedUntil, announcementUntil, publishAt sql.NullTime
+	err := row.Scan(
+		&topic.ID,
+		&topic.Title,
+		&topic.Content,
+		&topic.ContentHTML,
+		&topic.AuthorID,
+		&topic.CategoryID,
+		&topic.Views,
+		&topic.CommentCount,
+		&topic.CreatedAt,
+		&topic.UpdatedAt,
+		&topic.LastActivityAt,
+		&topic.Pinned,
+		&pinnedUntil,
+		&topic.Locked,
+		&lockedUntil,
+		&topic.Announcement,
+		&announcementUntil,
+		&publishAt,
+		&topic.HotScore,
+		&topic.TopScore,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if pinnedUntil.Valid {
+		topic.PinnedUntil = &pinnedUntil.Time
+	}
+	if lockedUntil.Valid {
+		topic.LockedUntil = &lockedUntil.Time
+	}
+	if announcementUntil.Valid {
+		topic.AnnouncementUntil = &announcementUntil.Time
+	}
+	if publishAt.Valid {
+		topic.PublishAt = &publishAt.Time
+	}
+	return topic, nil
+}
+
+// topicRankExpr ranks topics within a listing: announcements come first,
+// then topics pinned to the listing, then the rest. A category listing
+// keeps both category and global pins on top, any other listing only
+// global ones.
+func topicRankExpr(categoryListing bool) string {
+	return `CASE WHEN ` + topicAnnouncementExpr + ` THEN 2 WHEN ` + topicListingPinnedExpr(categoryListing) + ` THEN 1 ELSE 0 END`
+}
+
+// topicFlaggedExpr matches the topics that topicRankExpr ranks above the
+// rest
+func topicFlaggedExpr(categoryListing bool) string {
+	return `(` + topicAnnouncementExpr + ` OR ` + topicListingPinnedExpr(categoryListing) + `)`
+}
+
+func topicListingPinnedExpr(categoryListing bool) string {
+	if categoryListing {
+		return topicPinnedExpr
+	}
+	return topicGlobalPinnedExpr
+}
+
+// topicRank computes topicRankExpr for a loaded topic
+func topicRank(topic *entity.Topic, categoryListing bool) int {
+	switch {
+	case topic.Announcement:
+		return 2
+	case topic.Pinned == entity.PinScopeGlobal, categoryListing && topic.Pinned != "":
+		return 1
+	}
+	return 0
 }
 
 type topicRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	renderer markup.Renderer
 }
 
 func NewTopicRepository(db *sql.DB) TopicRepository {
-	return &topicRepository{db: db}
+	return &topicRepository{db: db, renderer: markup.NewPostRenderer()}
 }
 
+// CreateTopic saves a new topic together with its tags, its mentions and
+// the subscription of its author in one transaction. TagNames must be
+// normalized by the caller.
 func (r *topicRepository) CreateTopic(ctx context.Context, topic *entity.Topic) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertTopic(ctx, tx, r.renderer, topic); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertTopic saves a new topic with its tags and mentions and subscribes
+// the author to it. Drafts are published through it inside their own
+// transaction.
+func insertTopic(ctx context.Context, q dbtx, renderer markup.Renderer, topic *entity.Topic) error {
 	log.Printf("Creating new topic: Title=%s, AuthorID=%d, CategoryID=%d",
 		topic.Title, topic.AuthorID, topic.CategoryID)
 
-	err := r.db.QueryRowContext(ctx,
-		`INSERT INTO topics (title, content, author_id, category_id, views, comment_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
-		topic.Title, topic.Content, topic.AuthorID, topic.CategoryID, topic.Views, 0, topic.CreatedAt, topic.UpdatedAt,
+	contentHTML, mentioned, err := renderContent(ctx, q, renderer, topic.Content)
+	if err != nil {
+		return err
+	}
+
+	err = q.QueryRowContext(ctx,
+		`INSERT INTO topics (title, content, content_html, author_id, category_id, views, comment_count, created_at, updated_at, publish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+		topic.Title, topic.Content, contentHTML, topic.AuthorID, topic.CategoryID, topic.Views, 0, topic.CreatedAt, topic.UpdatedAt, topic.PublishAt,
 	).Scan(&topic.ID)
 
 	if err != nil {
@@ -42,37 +213,147 @@ func (r *topicRepository) CreateTopic(ctx context.Context, topic *entity.Topic)
 		return fmt.Errorf("failed to create topic: %w", err)
 	}
 
+	topic.ContentHTML = contentHTML
 	log.Printf("Successfully created topic with ID=%d", topic.ID)
-	return nil
+
+	topic.MentionedIDs, err = saveMentions(ctx, q, entity.MentionTargetTopic, topic.ID, topic.AuthorID, mentioned, false)
+	if err != nil {
+		return err
+	}
+	topic.Tags = []*entity.Tag{}
+	if len(topic.TagNames) > 0 {
+		if topic.Tags, err = setTopicTags(ctx, q, topic.ID, topic.TagNames); err != nil {
+			return err
+		}
+	}
+	return autoSubscribe(ctx, q, topic.AuthorID, topic.ID, entity.WatchLevelWatching)
 }
 
 func (r *topicRepository) GetTopicByID(ctx context.Context, id int64) (*entity.Topic, error) {
-	topic := &entity.Topic{}
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, title, content, author_id, category_id, views, comment_count, created_at, updated_at
+	topic, err := scanTopic(r.db.QueryRowContext(ctx,
+		`SELECT `+topicColumns+`
 		FROM topics
-		WHERE id = $1`,
+		WHERE id = $1 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NULL`,
 		id,
-	).Scan(
-		&topic.ID, &topic.Title, &topic.Content, &topic.AuthorID, &topic.CategoryID,
-		&topic.Views, &topic.CommentCount, &topic.CreatedAt, &topic.UpdatedAt,
-	)
+	))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("topic not found")
+			return nil, ErrTopicNotFound
 		}
 		return nil, fmt.Errorf("failed to get topic: %w", err)
 	}
 	return topic, nil
 }
 
-func (r *topicRepository) GetAllTopics(ctx context.Context) ([]*entity.Topic, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, title, content, author_id, category_id, views, comment_count, created_at, updated_at
-		FROM topics
-		ORDER BY created_at DESC
-	`)
+func (r *topicRepository) GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error) {
+	sort := filter.Sort
+	if sort == "" {
+		sort = entity.TopicSortNewest
+	}
+	column, ok := topicSortColumns[sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+	// Курсор top-выдачи действует только для своего периода
+	cursorKey := string(sort)
+	var interval string
+	if sort == entity.TopicSortTop {
+		period := filter.Period
+		if period == "" {
+			period = entity.TopicPeriodWeek
+		}
+		if interval, ok = topicPeriodIntervals[period]; !ok {
+			return nil, ErrInvalidPeriod
+		}
+		cursorKey += ":" + string(period)
+	} else if filter.Period != "" {
+		return nil, ErrInvalidPeriod
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = entity.DefaultTopicPageSize
+	}
+	if limit > entity.MaxTopicPageSize {
+		limit = entity.MaxTopicPageSize
+	}
+
+	// Скрытые модераторами, удаленные и еще не опубликованные темы не
+	// попадают в выдачу
+	conditions := []string{"hidden_at IS NULL", "deleted_at IS NULL", "publish_at IS NULL"}
+	categoryListing := filter.CategoryID > 0
+	var args []interface{}
+	if filter.CategoryID > 0 {
+		args = append(args, filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("category_id = $%d", len(args)))
+	}
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		conditions = append(conditions, fmt.Sprintf(
+			"id IN (SELECT tt.topic_id FROM topic_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tg.name = $%d)", len(args)))
+	}
+	if interval != "" {
+		conditions = append(conditions, "created_at > CURRENT_TIMESTAMP - INTERVAL '"+interval+"'")
+	}
+	var cursor *pageCursor
+	if filter.Cursor != "" {
+		var err error
+		if cursor, err = decodeCursor(filter.Cursor, cursorKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// Объявления и закрепленные темы идут первыми. Их единицы, поэтому они
+	// выбираются отдельным запросом, а остальные темы листаются по индексу
+	// на колонке сортировки. Берем на одну запись больше, чтобы понять, есть
+	// ли следующая страница.
+	page := &entity.TopicPage{Topics: []*entity.Topic{}}
+	flagged := topicFlaggedExpr(categoryListing)
+	if cursor == nil || cursor.Rank > 0 {
+		rank := topicRankExpr(categoryListing)
+		flaggedArgs := append([]interface{}{}, args...)
+		flaggedConditions := append(append([]string{}, conditions...), flagged)
+		if cursor != nil {
+			flaggedArgs = append(flaggedArgs, cursor.Rank, cursor.Value, cursor.ID)
+			flaggedConditions = append(flaggedConditions, fmt.Sprintf("(%s, %s, id) < ($%d, $%d, $%d)",
+				rank, column, len(flaggedArgs)-2, len(flaggedArgs)-1, len(flaggedArgs)))
+		}
+		flaggedArgs = append(flaggedArgs, limit+1)
+		topics, err := r.queryTopics(ctx, `SELECT `+topicColumns+` FROM topics WHERE `+strings.Join(flaggedConditions, " AND ")+
+			fmt.Sprintf(` ORDER BY %s DESC, %s DESC, id DESC LIMIT $%d`, rank, column, len(flaggedArgs)), flaggedArgs...)
+		if err != nil {
+			return nil, err
+		}
+		page.Topics = append(page.Topics, topics...)
+	}
+
+	if len(page.Topics) <= limit {
+		conditions = append(conditions, "NOT "+flagged)
+		if cursor != nil && cursor.Rank == 0 {
+			args = append(args, cursor.Value, cursor.ID)
+			conditions = append(conditions, fmt.Sprintf("(%s, id) < ($%d, $%d)", column, len(args)-1, len(args)))
+		}
+		args = append(args, limit+1-len(page.Topics))
+		topics, err := r.queryTopics(ctx, `SELECT `+topicColumns+` FROM topics WHERE `+strings.Join(conditions, " AND ")+
+			fmt.Sprintf(` ORDER BY %s DESC, id DESC LIMIT $%d`, column, len(args)), args...)
+		if err != nil {
+			return nil, err
+		}
+		page.Topics = append(page.Topics, topics...)
+	}
+
+	if len(page.Topics) > limit {
+		page.Topics = page.Topics[:limit]
+		last := page.Topics[limit-1]
+		page.NextCursor = encodeRankedCursor(cursorKey, topicRank(last, categoryListing), topicSortValue(last, sort), last.ID)
+	}
+
+	return page, nil
+}
+
+func (r *topicRepository) queryTopics(ctx context.Context, query string, args ...interface{}) ([]*entity.Topic, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query topics: %w", err)
 	}
@@ -80,55 +361,351 @@ func (r *topicRepository) GetAllTopics(ctx context.Context) ([]*entity.Topic, er
 
 	var topics []*entity.Topic
 	for rows.Next() {
-		topic := &entity.Topic{}
-		err := rows.Scan(
-			&topic.ID,
-			&topic.Title,
-			&topic.Content,
-			&topic.AuthorID,
-			&topic.CategoryID,
-			&topic.Views,
-			&topic.CommentCount,
-			&topic.CreatedAt,
-			&topic.UpdatedAt,
-		)
+		topic, err := scanTopic(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan topic: %w", err)
 		}
 		topics = append(topics, topic)
 	}
-
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating topics: %w", err)
 	}
+	return topics, nil
+}
+
+// topicSortValue returns the value of the sort column for the given topic
+// in a form that PostgreSQL can compare against the column again.
+func topicSortValue(topic *entity.Topic, sort entity.TopicSort) string {
+	switch sort {
+	case entity.TopicSortViews:
+		return strconv.Itoa(topic.Views)
+	case entity.TopicSortComments:
+		return strconv.Itoa(topic.CommentCount)
+	case entity.TopicSortActivity:
+		return topic.LastActivityAt.Format(time.RFC3339Nano)
+	case entity.TopicSortHot:
+		return strconv.FormatFloat(topic.HotScore, 'g', -1, 64)
+	case entity.TopicSortTop:
+		return strconv.FormatFloat(topic.TopScore, 'g', -1, 64)
+	default:
+		return topic.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// UpdateTopic saves the changes editorID made to a topic. If the title or
+// content changes, the previous version is kept as a revision. Tags are
+// replaced in the same transaction unless TagNames is nil.
+func (r *topicRepository) UpdateTopic(ctx context.Context, topic *entity.Topic, editorID int64) error {
+	contentHTML, mentioned, err := renderContent(ctx, r.db, r.renderer, topic.Content)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous := &entity.Revision{TargetType: entity.RevisionTargetTopic, TargetID: topic.ID}
+	var authorID int64
+	err = tx.QueryRowContext(ctx,
+		`SELECT title, content, author_id FROM topics WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		topic.ID,
+	).Scan(&previous.Title, &previous.Content, &authorID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrTopicNotFound
+		}
+		return fmt.Errorf("failed to lock topic: %w", err)
+	}
+	if previous.Title != topic.Title || previous.Content != topic.Content {
+		if err := saveRevision(ctx, tx, previous, editorID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE topics SET title = $1, content = $2, content_html = $3, category_id = $4, updated_at = NOW() 
+		WHERE id = $5`,
+		topic.Title, topic.Content, contentHTML, topic.CategoryID, topic.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update topic: %w", err)
+	}
+	var added []int64
+	if previous.Content != topic.Content {
+		if added, err = saveMentions(ctx, tx, entity.MentionTargetTopic, topic.ID, authorID, mentioned, true); err != nil {
+			return err
+		}
+	}
+	var tags []*entity.Tag
+	if topic.TagNames != nil {
+		if tags, err = setTopicTags(ctx, tx, topic.ID, topic.TagNames); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	topic.ContentHTML = contentHTML
+	topic.MentionedIDs = added
+	if topic.TagNames != nil {
+		topic.Tags = tags
+	}
+	return nil
+}
+
+// DeleteTopic marks a topic as deleted by deletedBy. Its comments are kept
+// until the topic is purged.
+func (r *topicRepository) DeleteTopic(ctx context.Context, id, deletedBy int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE topics SET deleted_at = CURRENT_TIMESTAMP, deleted_by = NULLIF($2, 0) WHERE id = $1 AND deleted_at IS NULL`,
+		id, deletedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete topic: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete topic: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTopicNotFound
+	}
+	return nil
+}
+
+// RestoreTopic brings back a deleted topic together with its comments
+func (r *topicRepository) RestoreTopic(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE topics SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore topic: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to restore topic: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTopicNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedTopics permanently removes topics deleted before the given
+// time, cascading to their comments
+func (r *topicRepository) PurgeDeletedTopics(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM topics WHERE deleted_at < $1`, before)
+	if err != nil {
+		log.Printf("Error purging deleted topics: %v", err)
+		return 0, fmt.Errorf("failed to purge deleted topics: %w", err)
+	}
+	return result.RowsAffected()
+}
 
+// SetPin pins a topic with the given scope until the given time, or for
+// good if until is nil. An empty scope unpins it.
+func (r *topicRepository) SetPin(ctx context.Context, id int64, scope entity.PinScope, until *time.Time) error {
+	return r.setFlags(ctx, id, `pin_scope = NULLIF($2, ''), pinned_until = $3`, string(scope), until)
+}
+
+// SetLock locks a topic against new comments until the given time, or for
+// good if until is nil
+func (r *topicRepository) SetLock(ctx context.Context, id int64, locked bool, until *time.Time) error {
+	return r.setFlags(ctx, id, `locked = $2, locked_until = $3`, locked, until)
+}
+
+// SetAnnouncement marks a topic as an announcement until the given time,
+// or for good if until is nil
+func (r *topicRepository) SetAnnouncement(ctx context.Context, id int64, announcement bool, until *time.Time) error {
+	return r.setFlags(ctx, id, `announcement = $2, announcement_until = $3`, announcement, until)
+}
+
+// setFlags applies assignments to a visible topic
+func (r *topicRepository) setFlags(ctx context.Context, id int64, assignments string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE topics SET `+assignments+` WHERE id = $1 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NULL`,
+		append([]interface{}{id}, args...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update topic flags: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update topic flags: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTopicNotFound
+	}
+	return nil
+}
+
+// GetScheduledTopic returns a topic that is waiting to be published, or
+// ErrTopicNotFound if there is no such topic
+func (r *topicRepository) GetScheduledTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	topic, err := scanTopic(r.db.QueryRowContext(ctx,
+		`SELECT `+topicColumns+`
+		FROM topics
+		WHERE id = $1 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NOT NULL`,
+		id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTopicNotFound
+		}
+		return nil, fmt.Errorf("failed to get scheduled topic: %w", err)
+	}
+	return topic, nil
+}
+
+// GetScheduledTopics returns the topics of an author that are waiting to be
+// published, the soonest first
+func (r *topicRepository) GetScheduledTopics(ctx context.Context, authorID int64) ([]*entity.Topic, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+topicColumns+`
+		FROM topics
+		WHERE author_id = $1 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NOT NULL
+		ORDER BY publish_at, id`,
+		authorID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled topics: %w", err)
+	}
+	defer rows.Close()
+
+	topics := []*entity.Topic{}
+	for rows.Next() {
+		topic, err := scanTopic(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan topic: %w", err)
+		}
+		topics = append(topics, topic)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled topics: %w", err)
+	}
 	return topics, nil
 }
 
-func (r *topicRepository) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE topics SET title = $1, content = $2, category_id = $3, updated_at = NOW() 
-		WHERE id = $4`,
-		topic.Title, topic.Content, topic.CategoryID, topic.ID,
+// RescheduleTopic moves the publication of a scheduled topic to publishAt.
+// A topic that has already been published can't be rescheduled.
+func (r *topicRepository) RescheduleTopic(ctx context.Context, id int64, publishAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE topics SET publish_at = $2 WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NOT NULL`,
+		id, publishAt,
 	)
-	return err
+	if err != nil {
+		return fmt.Errorf("failed to reschedule topic: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to reschedule topic: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTopicNotFound
+	}
+	return nil
 }
 
-func (r *topicRepository) DeleteTopic(ctx context.Context, id int64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM topics WHERE id = $1`, id)
-	return err
+// PublishDueTopics publishes up to limit scheduled topics whose time came
+// at now and returns them with the users mentioned in them. A published
+// topic is dated by its scheduled time. Rows claimed by another instance
+// are skipped, so every topic is published once.
+func (r *topicRepository) PublishDueTopics(ctx context.Context, now time.Time, limit int) ([]*entity.Topic, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE topics t
+		SET publish_at = NULL, created_at = d.publish_at, updated_at = d.publish_at, last_activity_at = d.publish_at,
+			hot_score = topic_hot_score(0, `+topicPoints+`, d.publish_at)
+		FROM (
+			SELECT id, publish_at FROM topics
+			WHERE publish_at <= $1 AND deleted_at IS NULL
+			ORDER BY publish_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		) d
+		WHERE t.id = d.id
+		RETURNING t.id, t.title, t.author_id, t.created_at,
+			ARRAY(SELECT m.user_id FROM mentions m WHERE m.target_type = 'topic' AND m.target_id = t.id ORDER BY m.user_id)`,
+		now, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish scheduled topics: %w", err)
+	}
+	defer rows.Close()
+
+	topics := []*entity.Topic{}
+	for rows.Next() {
+		topic := &entity.Topic{}
+		var mentioned pq.Int64Array
+		if err := rows.Scan(&topic.ID, &topic.Title, &topic.AuthorID, &topic.CreatedAt, &mentioned); err != nil {
+			return nil, fmt.Errorf("failed to scan published topic: %w", err)
+		}
+		topic.UpdatedAt = topic.CreatedAt
+		topic.LastActivityAt = topic.CreatedAt
+		topic.MentionedIDs = []int64(mentioned)
+		topics = append(topics, topic)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating published topics: %w", err)
+	}
+	return topics, nil
 }
 
 func (r *topicRepository) UpdateCommentCount(ctx context.Context, topicID int64) error {
-	_, err := r.db.ExecContext(ctx, `
+	return recountComments(ctx, r.db, topicID)
+}
+
+// recountComments sets the comment count of a topic to the number of its
+// comments that aren't deleted
+func recountComments(ctx context.Context, q dbtx, topicID int64) error {
+	_, err := q.ExecContext(ctx, `
 		UPDATE topics 
 		SET comment_count = (
 			SELECT COUNT(*) 
 			FROM comments 
-			WHERE topic_id = $1
+			WHERE topic_id = $1 AND deleted_at IS NULL
 		)
 		WHERE id = $1`, topicID)
-	return err
+	if err != nil {
+		return fmt.Errorf("failed to update comment count: %w", err)
+	}
+	return nil
+}
+
+// AddViews adds the given number of views to each topic with a single
+// statement. Increments are applied in the database, so concurrent batches
+// never overwrite each other.
+func (r *topicRepository) AddViews(ctx context.Context, views map[int64]int) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(views))
+	counts := make([]int64, 0, len(views))
+	for id, n := range views {
+		ids = append(ids, id)
+		counts = append(counts, int64(n))
+	}
+
+	// Просмотры весят меньше комментариев и лайков, см. topic_hot_score
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE topics t
+		SET views = t.views + v.n,
+			hot_score = topic_hot_score(t.hot_score, v.n * `+topicViewPoints+`, CURRENT_TIMESTAMP),
+			top_score = t.top_score + v.n * `+topicViewPoints+`
+		FROM unnest($1::bigint[], $2::bigint[]) AS v(id, n)
+		WHERE t.id = v.id AND t.deleted_at IS NULL`,
+		pq.Array(ids), pq.Array(counts),
+	)
+	if err != nil {
+		log.Printf("Error adding views to %d topics: %v", len(views), err)
+		return fmt.Errorf("failed to add topic views: %w", err)
+	}
+	return nil
 }
 
 func (r *topicRepository) GetDB() *sql.DB {
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"regexp"
 	"testing"
 	"time"
@@ -17,7 +18,7 @@ func newTestTopicRepo(t *testing.T) (TopicRepository, sqlmock.Sqlmock, func()) {
 	if err != nil {
 		t.Fatalf("failed to create sqlmock: %v", err)
 	}
-	repo := &topicRepository{db: db}
+	repo := NewTopicRepository(db)
 	return repo, mock, func() { db.Close() }
 }
 
@@ -28,7 +29,7 @@ func TestTopicRepository_CreateTopic(t *testing.T) {
 	now := time.Now()
 	topic := &entity.Topic{
 		Title:      "Test Topic",
-		Content:    "Test Content",
+		Content:    "Test <script>alert(1)</script>**Content**",
 		AuthorID:   1,
 		CategoryID: 1,
 		Views:      0,
@@ -36,18 +37,74 @@ func TestTopicRepository_CreateTopic(t *testing.T) {
 		UpdatedAt:  now,
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		INSERT INTO topics (title, content, author_id, category_id, views, comment_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+		INSERT INTO topics (title, content, content_html, author_id, category_id, views, comment_count, created_at, updated_at, publish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id
 	`)).
-		WithArgs(topic.Title, topic.Content, topic.AuthorID, topic.CategoryID, topic.Views, 0, topic.CreatedAt, topic.UpdatedAt).
+		WithArgs(topic.Title, topic.Content, "<p>Test alert(1)<strong>Content</strong></p>", topic.AuthorID, topic.CategoryID, topic.Views, 0, topic.CreatedAt, topic.UpdatedAt, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO topic_subscriptions`).
+		WithArgs(topic.AuthorID, int64(1), entity.WatchLevelWatching).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	err := repo.CreateTopic(context.Background(), topic)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), topic.ID)
+	assert.Equal(t, "<p>Test alert(1)<strong>Content</strong></p>", topic.ContentHTML)
+	assert.Empty(t, topic.Tags)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestTopicRepository_CreateTopic_Tags(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	topic := &entity.Topic{Title: "Topic", Content: "Content", AuthorID: 1, CategoryID: 1, TagNames: []string{"golang"}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO topics`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM topic_tags WHERE topic_id = $1`)).
+		WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO tags (name) VALUES ($1)`)).
+		WithArgs("golang").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).AddRow(3, "golang", time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO topic_tags (topic_id, tag_id) VALUES ($1, $2)`)).
+		WithArgs(int64(1), int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO topic_subscriptions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.CreateTopic(context.Background(), topic)
+	assert.NoError(t, err)
+	if assert.Len(t, topic.Tags, 1) {
+		assert.Equal(t, "golang", topic.Tags[0].Name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_CreateTopic_TagError(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	topic := &entity.Topic{Title: "Topic", Content: "Content", AuthorID: 1, CategoryID: 1, TagNames: []string{"golang"}}
+
+	// Тема без тегов не остается: вставка откатывается вместе с ними
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO topics`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM topic_tags WHERE topic_id = $1`)).
+		WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO tags (name) VALUES ($1)`)).
+		WithArgs("golang").WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	err := repo.CreateTopic(context.Background(), topic)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var topicTestColumns = []string{"id", "title", "content", "content_html", "author_id", "category_id", "views", "comment_count", "created_at", "updated_at", "last_activity_at", "pin_scope", "pinned_until", "locked", "locked_until", "announcement", "announcement_until", "publish_at", "hot_score", "top_score"}
+
 func TestTopicRepository_GetTopicByID(t *testing.T) {
 	repo, mock, closeFn := newTestTopicRepo(t)
 	defer closeFn()
@@ -64,28 +121,26 @@ func TestTopicRepository_GetTopicByID(t *testing.T) {
 		UpdatedAt:  now,
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, title, content, author_id, category_id, views, created_at, updated_at 
-		FROM topics WHERE id = $1
-	`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + topicColumns + `
+		FROM topics
+		WHERE id = $1`)).
 		WithArgs(int64(1)).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "category_id", "views", "created_at", "updated_at"}).
-			AddRow(expectedTopic.ID, expectedTopic.Title, expectedTopic.Content, expectedTopic.AuthorID, expectedTopic.CategoryID, expectedTopic.Views, expectedTopic.CreatedAt, expectedTopic.UpdatedAt))
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(expectedTopic.ID, expectedTopic.Title, expectedTopic.Content, "<p>Test Content</p>", expectedTopic.AuthorID, expectedTopic.CategoryID, expectedTopic.Views, 0, expectedTopic.CreatedAt, expectedTopic.UpdatedAt, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0))
 
 	topic, err := repo.GetTopicByID(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedTopic.Title, topic.Title)
 	assert.Equal(t, expectedTopic.Content, topic.Content)
+	assert.Equal(t, "<p>Test Content</p>", topic.ContentHTML)
+	assert.Equal(t, now, topic.LastActivityAt)
 }
 
 func TestTopicRepository_GetTopicByID_NotFound(t *testing.T) {
 	repo, mock, closeFn := newTestTopicRepo(t)
 	defer closeFn()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, title, content, author_id, category_id, views, created_at, updated_at 
-		FROM topics WHERE id = $1
-	`)).
+	mock.ExpectQuery(`SELECT (.+) FROM topics WHERE id = \$1`).
 		WithArgs(int64(1)).
 		WillReturnError(sql.ErrNoRows)
 
@@ -94,44 +149,281 @@ func TestTopicRepository_GetTopicByID_NotFound(t *testing.T) {
 	assert.Nil(t, topic)
 }
 
+const topicListingBase = `FROM topics WHERE hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NULL`
+
+// flaggedTopicsQuery is the query for the announcements and pins on top of
+// a listing
+func flaggedTopicsQuery(categoryListing bool, conditions, column string, cursor bool, limitArg int) string {
+	query := topicListingBase + conditions + ` AND ` + topicFlaggedExpr(categoryListing)
+	if cursor {
+		query += fmt.Sprintf(` AND (%s, %s, id) < ($%d, $%d, $%d)`, topicRankExpr(categoryListing), column, limitArg-3, limitArg-2, limitArg-1)
+	}
+	return query + fmt.Sprintf(` ORDER BY %s DESC, %s DESC, id DESC LIMIT $%d`, topicRankExpr(categoryListing), column, limitArg)
+}
+
+// restTopicsQuery is the query for the rest of a listing
+func restTopicsQuery(categoryListing bool, conditions, column string, cursor bool, limitArg int) string {
+	query := topicListingBase + conditions + ` AND NOT ` + topicFlaggedExpr(categoryListing)
+	if cursor {
+		query += fmt.Sprintf(` AND (%s, id) < ($%d, $%d)`, column, limitArg-2, limitArg-1)
+	}
+	return query + fmt.Sprintf(` ORDER BY %s DESC, id DESC LIMIT $%d`, column, limitArg)
+}
+
 func TestTopicRepository_GetAllTopics(t *testing.T) {
 	repo, mock, closeFn := newTestTopicRepo(t)
 	defer closeFn()
 
 	now := time.Now()
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT t.id, t.title, t.content, t.author_id, t.category_id, t.views, t.comment_count, t.created_at, t.updated_at
-		FROM topics t
-		ORDER BY t.created_at DESC
-	`)).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "category_id", "views", "comment_count", "created_at", "updated_at"}).
-			AddRow(1, "Test Topic 1", "Test Content 1", 1, 1, 0, 0, now, now).
-			AddRow(2, "Test Topic 2", "Test Content 2", 1, 1, 0, 0, now, now))
+	mock.ExpectQuery(regexp.QuoteMeta(flaggedTopicsQuery(false, "", "created_at", false, 1))).
+		WithArgs(entity.DefaultTopicPageSize + 1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(false, "", "created_at", false, 1))).
+		WithArgs(entity.DefaultTopicPageSize + 1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(1, "Test Topic 1", "Test Content 1", "<p>Test Content 1</p>", 1, 1, 0, 0, now, now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0).
+			AddRow(2, "Test Topic 2", "Test Content 2", "<p>Test Content 2</p>", 1, 1, 0, 0, now, now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0))
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 2)
+	assert.Equal(t, int64(1), page.Topics[0].ID)
+	assert.Equal(t, "Test Topic 1", page.Topics[0].Title)
+	assert.Equal(t, int64(2), page.Topics[1].ID)
+	assert.Equal(t, "Test Topic 2", page.Topics[1].Title)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_GetAllTopics_NextCursor(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(flaggedTopicsQuery(true, " AND category_id = $1", "views", false, 2))).
+		WithArgs(int64(3), 3).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(true, " AND category_id = $1", "views", false, 2))).
+		WithArgs(int64(3), 3).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(5, "Topic 5", "Content", "<p>Content</p>", 1, 3, 50, 0, now, now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0).
+			AddRow(4, "Topic 4", "Content", "<p>Content</p>", 1, 3, 40, 0, now, now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0).
+			AddRow(3, "Topic 3", "Content", "<p>Content</p>", 1, 3, 30, 0, now, now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0))
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortViews, CategoryID: 3, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// Следующая страница начинается после последней темы текущей, и
+	// закрепленные темы больше не запрашиваются
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(true, " AND category_id = $1", "views", true, 4))).
+		WithArgs(int64(3), "40", int64(4), 3).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(3, "Topic 3", "Content", "<p>Content</p>", 1, 3, 30, 0, now, now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0))
 
-	topics, err := repo.GetAllTopics(context.Background())
+	page, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortViews, CategoryID: 3, Limit: 2, Cursor: page.NextCursor})
 	assert.NoError(t, err)
-	assert.Len(t, topics, 2)
-	assert.Equal(t, int64(1), topics[0].ID)
-	assert.Equal(t, "Test Topic 1", topics[0].Title)
-	assert.Equal(t, int64(2), topics[1].ID)
-	assert.Equal(t, "Test Topic 2", topics[1].Title)
+	assert.Len(t, page.Topics, 1)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_GetAllTopics_PinnedFirst(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	until := now.Add(time.Hour)
+	// Закрепленных тем хватает на всю страницу, остальные не запрашиваются
+	mock.ExpectQuery(regexp.QuoteMeta(flaggedTopicsQuery(false, "", "created_at", false, 1))).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(1, "Rules", "Content", "<p>Content</p>", 1, 1, 0, 0, now, now, now, "", nil, true, nil, true, until, nil, 0.0, 1.0).
+			AddRow(7, "Welcome", "Content", "<p>Content</p>", 1, 2, 0, 0, now, now, now, "global", nil, false, nil, false, nil, nil, 0.0, 1.0))
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 1)
+	assert.True(t, page.Topics[0].Announcement)
+	assert.Equal(t, until, *page.Topics[0].AnnouncementUntil)
+	assert.True(t, page.Topics[0].Locked)
+
+	// Курсор помнит, что страница закончилась на объявлении: дальше идут
+	// оставшиеся закрепленные темы, а за ними остальные с начала
+	mock.ExpectQuery(regexp.QuoteMeta(flaggedTopicsQuery(false, "", "created_at", true, 4))).
+		WithArgs(2, now.Format(time.RFC3339Nano), int64(1), 2).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(7, "Welcome", "Content", "<p>Content</p>", 1, 2, 0, 0, now, now, now, "global", nil, false, nil, false, nil, nil, 0.0, 1.0))
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(false, "", "created_at", false, 1))).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(9, "Hello", "Content", "<p>Content</p>", 1, 2, 0, 0, now, now, now, "", nil, false, nil, false, nil, nil, 0.0, 1.0))
+
+	page, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Limit: 1, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 1)
+	assert.Equal(t, entity.PinScopeGlobal, page.Topics[0].Pinned)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_GetAllTopics_Hot(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(flaggedTopicsQuery(false, "", "hot_score", false, 1))).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(false, "", "hot_score", false, 1))).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(4, "Topic 4", "Content", "<p>Content</p>", 1, 3, 40, 5, now, now, now, "", nil, false, nil, false, nil, nil, 52.25, 12.0).
+			AddRow(3, "Topic 3", "Content", "<p>Content</p>", 1, 3, 30, 0, now, now, now, "", nil, false, nil, false, nil, nil, 51.5, 1.0))
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortHot, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 1)
+
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(false, "", "hot_score", true, 3))).
+		WithArgs("52.25", int64(4), 2).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+
+	_, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortHot, Limit: 1, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+
+	// Период задается только для top
+	_, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortHot, Period: entity.TopicPeriodWeek})
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_GetAllTopics_Top(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	now := time.Now()
+	week := ` AND created_at > CURRENT_TIMESTAMP - INTERVAL '7 days'`
+	// Без периода берутся темы за неделю
+	mock.ExpectQuery(regexp.QuoteMeta(flaggedTopicsQuery(false, week, "top_score", false, 1))).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(false, week, "top_score", false, 1))).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(4, "Topic 4", "Content", "<p>Content</p>", 1, 3, 40, 5, now, now, now, "", nil, false, nil, false, nil, nil, 52.25, 12.0).
+			AddRow(3, "Topic 3", "Content", "<p>Content</p>", 1, 3, 30, 0, now, now, now, "", nil, false, nil, false, nil, nil, 51.5, 1.0))
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortTop, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Topics, 1)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(false, week, "top_score", true, 3))).
+		WithArgs("12", int64(4), 2).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+
+	_, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortTop, Period: entity.TopicPeriodWeek, Limit: 1, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+
+	// Курсор недели не подходит для выдачи за все время
+	_, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortTop, Period: entity.TopicPeriodAll, Cursor: page.NextCursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	mock.ExpectQuery(regexp.QuoteMeta(flaggedTopicsQuery(false, "", "top_score", false, 1))).
+		WithArgs(entity.DefaultTopicPageSize + 1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(false, "", "top_score", false, 1))).
+		WithArgs(entity.DefaultTopicPageSize + 1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+
+	_, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortTop, Period: entity.TopicPeriodAll})
+	assert.NoError(t, err)
+
+	_, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortTop, Period: "year"})
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRank(t *testing.T) {
+	assert.Equal(t, 2, topicRank(&entity.Topic{Announcement: true}, false))
+	assert.Equal(t, 1, topicRank(&entity.Topic{Pinned: entity.PinScopeGlobal}, false))
+	assert.Equal(t, 0, topicRank(&entity.Topic{Pinned: entity.PinScopeCategory}, false))
+	assert.Equal(t, 1, topicRank(&entity.Topic{Pinned: entity.PinScopeCategory}, true))
+	assert.Equal(t, 0, topicRank(&entity.Topic{Locked: true}, true))
+}
+
+func TestTopicRepository_GetAllTopics_Tag(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	tag := ` AND category_id = $1 AND id IN (SELECT tt.topic_id FROM topic_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tg.name = $2)`
+	mock.ExpectQuery(regexp.QuoteMeta(flaggedTopicsQuery(true, tag, "created_at", false, 3))).
+		WithArgs(int64(2), "golang", entity.DefaultTopicPageSize+1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+	mock.ExpectQuery(regexp.QuoteMeta(restTopicsQuery(true, tag, "created_at", false, 3))).
+		WithArgs(int64(2), "golang", entity.DefaultTopicPageSize+1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{CategoryID: 2, Tag: "golang"})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Topics)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_GetAllTopics_LimitIsCapped(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`DESC, last_activity_at DESC, id DESC LIMIT $1`)).
+		WithArgs(entity.MaxTopicPageSize + 1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY last_activity_at DESC, id DESC LIMIT $1`)).
+		WithArgs(entity.MaxTopicPageSize + 1).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns))
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortActivity, Limit: 1000})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Topics)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_GetAllTopics_InvalidSort(t *testing.T) {
+	repo, _, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: "random"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	assert.Nil(t, page)
+}
+
+func TestTopicRepository_GetAllTopics_InvalidCursor(t *testing.T) {
+	repo, _, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.Nil(t, page)
+
+	// Курсор, выданный для другой сортировки, не принимается
+	cursor := encodeCursor(string(entity.TopicSortViews), "10", 1)
+	page, err = repo.GetAllTopics(context.Background(), entity.TopicFilter{Sort: entity.TopicSortNewest, Cursor: cursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.Nil(t, page)
 }
 
 func TestTopicRepository_GetAllTopics_ScanError(t *testing.T) {
 	repo, mock, closeFn := newTestTopicRepo(t)
 	defer closeFn()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT t.id, t.title, t.content, t.author_id, t.category_id, t.views, t.comment_count, t.created_at, t.updated_at
-		FROM topics t
-		ORDER BY t.created_at DESC
-	`)).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "category_id", "views", "comment_count", "created_at", "updated_at"}).
-			AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectQuery(`SELECT (.+) FROM topics WHERE hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NULL AND (.+) ORDER BY (.+) DESC, created_at DESC, id DESC`).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
 
-	topics, err := repo.GetAllTopics(context.Background())
+	page, err := repo.GetAllTopics(context.Background(), entity.TopicFilter{})
 	assert.Error(t, err)
-	assert.Nil(t, topics)
+	assert.Nil(t, page)
 }
 
 func TestTopicRepository_UpdateTopic(t *testing.T) {
@@ -145,27 +437,138 @@ func TestTopicRepository_UpdateTopic(t *testing.T) {
 		CategoryID: 2,
 	}
 
+	// Прежняя версия сохраняется в истории правок
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT title, content, author_id FROM topics WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`)).
+		WithArgs(topic.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"title", "content", "author_id"}).AddRow("Old Topic", "Old Content", 1))
+	mock.ExpectExec(`INSERT INTO topic_revisions \(topic_id, revision, editor_id, content, title\)\s+SELECT \$1, COALESCE\(MAX\(revision\), 0\) \+ 1`).
+		WithArgs(topic.ID, int64(5), "Old Content", "Old Topic").
+		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectExec(regexp.QuoteMeta(`
-		UPDATE topics SET title = $1, content = $2, category_id = $3, updated_at = NOW() 
-		WHERE id = $4
-	`)).
-		WithArgs(topic.Title, topic.Content, topic.CategoryID, topic.ID).
+		UPDATE topics SET title = $1, content = $2, content_html = $3, category_id = $4, updated_at = NOW() 
+		WHERE id = $5`)).
+		WithArgs(topic.Title, topic.Content, "<p>Updated Content</p>", topic.CategoryID, topic.ID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Упоминания, убранные правкой, удаляются
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM mentions WHERE target_type = $1 AND target_id = $2 AND NOT (user_id = ANY($3))`)).
+		WithArgs(entity.MentionTargetTopic, topic.ID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
 
-	err := repo.UpdateTopic(context.Background(), topic)
+	err := repo.UpdateTopic(context.Background(), topic, 5)
 	assert.NoError(t, err)
+	assert.Equal(t, "<p>Updated Content</p>", topic.ContentHTML)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_UpdateTopic_CategoryOnly(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	topic := &entity.Topic{ID: 1, Title: "Topic", Content: "Content", CategoryID: 2}
+
+	// Без изменения текста новая версия не появляется
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT title, content, author_id FROM topics`).
+		WithArgs(topic.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"title", "content", "author_id"}).AddRow("Topic", "Content", 1))
+	mock.ExpectExec(`UPDATE topics SET title`).
+		WithArgs(topic.Title, topic.Content, sqlmock.AnyArg(), topic.CategoryID, topic.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.UpdateTopic(context.Background(), topic, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_UpdateTopic_Tags(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	topic := &entity.Topic{ID: 1, Title: "Topic", Content: "Content", CategoryID: 2, TagNames: []string{}}
+
+	// Пустой список снимает все теги в транзакции правки
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT title, content, author_id FROM topics`).
+		WithArgs(topic.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"title", "content", "author_id"}).AddRow("Topic", "Content", 1))
+	mock.ExpectExec(`UPDATE topics SET title`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM topic_tags WHERE topic_id = $1`)).
+		WithArgs(topic.ID).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := repo.UpdateTopic(context.Background(), topic, 5)
+	assert.NoError(t, err)
+	assert.NotNil(t, topic.Tags)
+	assert.Empty(t, topic.Tags)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_UpdateTopic_NotFound(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT title, content, author_id FROM topics`).
+		WithArgs(int64(1)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	err := repo.UpdateTopic(context.Background(), &entity.Topic{ID: 1, Title: "Topic"}, 5)
+	assert.ErrorIs(t, err, ErrTopicNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestTopicRepository_DeleteTopic(t *testing.T) {
 	repo, mock, closeFn := newTestTopicRepo(t)
 	defer closeFn()
 
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM topics WHERE id = $1`)).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE topics SET deleted_at = CURRENT_TIMESTAMP, deleted_by = NULLIF($2, 0) WHERE id = $1 AND deleted_at IS NULL`)).
+		WithArgs(int64(1), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE topics SET deleted_at`).
+		WithArgs(int64(2), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteTopic(context.Background(), 1, 5)
+	assert.NoError(t, err)
+
+	err = repo.DeleteTopic(context.Background(), 2, 5)
+	assert.ErrorIs(t, err, ErrTopicNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_RestoreTopic(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE topics SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL`)).
 		WithArgs(int64(1)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE topics SET deleted_at = NULL`).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.RestoreTopic(context.Background(), 1))
+	assert.ErrorIs(t, repo.RestoreTopic(context.Background(), 2), ErrTopicNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
 
-	err := repo.DeleteTopic(context.Background(), 1)
+func TestTopicRepository_PurgeDeletedTopics(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	before := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM topics WHERE deleted_at < $1`)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	purged, err := repo.PurgeDeletedTopics(context.Background(), before)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestTopicRepository_UpdateCommentCount(t *testing.T) {
@@ -177,7 +580,7 @@ func TestTopicRepository_UpdateCommentCount(t *testing.T) {
 		SET comment_count = (
 			SELECT COUNT(*) 
 			FROM comments 
-			WHERE topic_id = $1
+			WHERE topic_id = $1 AND deleted_at IS NULL
 		)
 		WHERE id = $1
 	`)).
@@ -199,26 +602,29 @@ func TestTopicRepository_UpdateTopic_ExecError(t *testing.T) {
 		CategoryID: 2,
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`
-		UPDATE topics SET title = $1, content = $2, category_id = $3, updated_at = NOW() 
-		WHERE id = $4
-	`)).
-		WithArgs(topic.Title, topic.Content, topic.CategoryID, topic.ID).
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT title, content, author_id FROM topics`).
+		WithArgs(topic.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"title", "content", "author_id"}).AddRow("Updated Topic", "Updated Content", 1))
+	mock.ExpectExec(`UPDATE topics SET title`).
+		WithArgs(topic.Title, topic.Content, sqlmock.AnyArg(), topic.CategoryID, topic.ID).
 		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
 
-	err := repo.UpdateTopic(context.Background(), topic)
+	err := repo.UpdateTopic(context.Background(), topic, 5)
 	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestTopicRepository_DeleteTopic_ExecError(t *testing.T) {
 	repo, mock, closeFn := newTestTopicRepo(t)
 	defer closeFn()
 
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM topics WHERE id = $1`)).
-		WithArgs(int64(1)).
+	mock.ExpectExec(`UPDATE topics SET deleted_at`).
+		WithArgs(int64(1), int64(5)).
 		WillReturnError(assert.AnError)
 
-	err := repo.DeleteTopic(context.Background(), 1)
+	err := repo.DeleteTopic(context.Background(), 1, 5)
 	assert.Error(t, err)
 }
 
@@ -231,7 +637,7 @@ func TestTopicRepository_UpdateCommentCount_ExecError(t *testing.T) {
 		SET comment_count = (
 			SELECT COUNT(*) 
 			FROM comments 
-			WHERE topic_id = $1
+			WHERE topic_id = $1 AND deleted_at IS NULL
 		)
 		WHERE id = $1
 	`)).
@@ -254,6 +660,7 @@ func TestTopicRepository_CreateTopic_QueryRowError(t *testing.T) {
 		Views:      0,
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`
 		INSERT INTO topics (title, content, author_id, category_id, views, comment_count, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
@@ -265,14 +672,34 @@ func TestTopicRepository_CreateTopic_QueryRowError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestTopicRepository_SetFlags(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	until := time.Now().Add(24 * time.Hour)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE topics SET pin_scope = NULLIF($2, ''), pinned_until = $3 WHERE id = $1 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NULL`)).
+		WithArgs(int64(1), "global", &until).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SetPin(context.Background(), 1, entity.PinScopeGlobal, &until))
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE topics SET locked = $2, locked_until = $3 WHERE id = $1`)).
+		WithArgs(int64(1), true, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SetLock(context.Background(), 1, true, nil))
+
+	// Удаленную или скрытую тему изменить нельзя
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE topics SET announcement = $2, announcement_until = $3 WHERE id = $1`)).
+		WithArgs(int64(2), false, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.SetAnnouncement(context.Background(), 2, false, nil), ErrTopicNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestTopicRepository_GetTopicByID_QueryRowError(t *testing.T) {
 	repo, mock, closeFn := newTestTopicRepo(t)
 	defer closeFn()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, title, content, author_id, category_id, views, created_at, updated_at 
-		FROM topics WHERE id = $1
-	`)).
+	mock.ExpectQuery(`SELECT (.+) FROM topics WHERE id = \$1`).
 		WithArgs(int64(1)).
 		WillReturnError(assert.AnError)
 
@@ -280,3 +707,82 @@ func TestTopicRepository_GetTopicByID_QueryRowError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, topic)
 }
+
+func TestTopicRepository_AddViews(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	mock.ExpectExec(`UPDATE topics t\s+SET views = t.views \+ v.n,\s+hot_score = topic_hot_score\(t.hot_score, v.n \* 0.1, CURRENT_TIMESTAMP\),\s+top_score = t.top_score \+ v.n \* 0.1\s+FROM unnest\(\$1::bigint\[\], \$2::bigint\[\]\) AS v\(id, n\)`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.AddViews(context.Background(), map[int64]int{1: 3, 2: 1})
+	assert.NoError(t, err)
+
+	// Пустой батч не выполняет запрос
+	err = repo.AddViews(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_GetScheduledTopic(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	publishAt := now.Add(2 * time.Hour)
+	mock.ExpectQuery(`FROM topics\s+WHERE id = \$1 AND hidden_at IS NULL AND deleted_at IS NULL AND publish_at IS NOT NULL`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows(topicTestColumns).
+			AddRow(3, "Release notes", "Soon", "<p>Soon</p>", 2, 1, 0, 0, now, now, now, "", nil, false, nil, false, nil, publishAt, 0.0, 1.0))
+	mock.ExpectQuery(`FROM topics\s+WHERE id = \$1`).
+		WithArgs(int64(4)).
+		WillReturnError(sql.ErrNoRows)
+
+	topic, err := repo.GetScheduledTopic(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, publishAt, *topic.PublishAt)
+
+	// Опубликованная тема больше не считается запланированной
+	_, err = repo.GetScheduledTopic(context.Background(), 4)
+	assert.ErrorIs(t, err, ErrTopicNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_RescheduleTopic(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	publishAt := time.Date(2024, 3, 16, 9, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE topics SET publish_at = $2 WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NOT NULL`)).
+		WithArgs(int64(3), publishAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE topics SET publish_at`).
+		WithArgs(int64(4), publishAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.RescheduleTopic(context.Background(), 3, publishAt))
+	assert.ErrorIs(t, repo.RescheduleTopic(context.Background(), 4, publishAt), ErrTopicNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicRepository_PublishDueTopics(t *testing.T) {
+	repo, mock, closeFn := newTestTopicRepo(t)
+	defer closeFn()
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Minute)
+	mock.ExpectQuery(`UPDATE topics t\s+SET publish_at = NULL, created_at = d.publish_at[\s\S]+WHERE publish_at <= \$1 AND deleted_at IS NULL[\s\S]+FOR UPDATE SKIP LOCKED`).
+		WithArgs(now, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "created_at", "mentioned"}).
+			AddRow(3, "Release notes", 2, due, "{5,7}").
+			AddRow(4, "Roadmap", 2, due, "{}"))
+
+	topics, err := repo.PublishDueTopics(context.Background(), now, 50)
+	assert.NoError(t, err)
+	assert.Len(t, topics, 2)
+	assert.Equal(t, due, topics[0].LastActivityAt)
+	assert.Equal(t, []int64{5, 7}, topics[0].MentionedIDs)
+	assert.Empty(t, topics[1].MentionedIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -11,9 +11,10 @@ func CreateTriggers(db *sql.DB) error {
 		CREATE OR REPLACE FUNCTION update_comment_count()
 		RETURNS TRIGGER AS $$
 		BEGIN
-			IF TG_OP = 'INSERT' THEN
+			-- Удаленные комментарии уже вычтены при удалении, см. DeleteComment
+			IF TG_OP = 'INSERT' AND NEW.deleted_at IS NULL THEN
 				UPDATE topics SET comment_count = comment_count + 1 WHERE id = NEW.topic_id;
-			ELSIF TG_OP = 'DELETE' THEN
+			ELSIF TG_OP = 'DELETE' AND OLD.deleted_at IS NULL THEN
 				UPDATE topics SET comment_count = comment_count - 1 WHERE id = OLD.topic_id;
 			END IF;
 			RETURN NULL;
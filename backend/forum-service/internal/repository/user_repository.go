@@ -17,6 +17,7 @@ import (
 type UserRepository interface {
 	GetUsernameByID(ctx context.Context, id int64) (string, error)
 	GetUserByID(ctx context.Context, id int64) (*entity.User, error)
+	GetUserRole(ctx context.Context, id int64) (string, error)
 }
 
 type UserRepositoryImpl struct {
@@ -37,8 +38,9 @@ func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id int64) (*entity
 
 	// Try to get from local database first
 	user := &entity.User{ID: id}
-	err := r.db.QueryRowContext(ctx, "SELECT username, avatar FROM users WHERE id = $1", id).Scan(&user.Username, &user.Avatar)
+	err := r.db.QueryRowContext(ctx, "SELECT username, COALESCE(avatar, '') FROM users WHERE id = $1", id).Scan(&user.Username, &user.Avatar)
 	if err == nil {
+		user.Avatar = entity.AvatarURL(id, user.Avatar)
 		log.Printf("Found user in local DB: %+v", user)
 		return user, nil
 	} else if err != sql.ErrNoRows {
@@ -76,7 +78,7 @@ func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id int64) (*entity
 		return &entity.User{
 			ID:       id,
 			Username: fmt.Sprintf("User_%d", id),
-			Avatar:   "",
+			Avatar:   entity.DefaultAvatarURL(id),
 		}, nil
 	}
 
@@ -96,7 +98,7 @@ func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id int64) (*entity
 	}
 
 	// Save user to local database
-	_, err = r.db.ExecContext(ctx, "INSERT INTO users (id, username, avatar) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET username = $2, avatar = $3",
+	_, err = r.db.ExecContext(ctx, "INSERT INTO users (id, username, avatar) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET username = $2, avatar = COALESCE(users.avatar, $3)",
 		id, userData.Username, userData.Avatar)
 	if err != nil {
 		log.Printf("Error saving user to local DB: %v", err)
@@ -105,7 +107,7 @@ func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id int64) (*entity
 	user = &entity.User{
 		ID:       id,
 		Username: userData.Username,
-		Avatar:   userData.Avatar,
+		Avatar:   entity.AvatarURL(id, userData.Avatar),
 	}
 	log.Printf("Found user from auth-service: %+v", user)
 	return user, nil
@@ -180,3 +182,18 @@ func (r *UserRepositoryImpl) GetUsernameByID(ctx context.Context, id int64) (str
 	log.Printf("Found username from auth-service: %s for user ID: %d", user.Username, id)
 	return user.Username, nil
 }
+
+// GetUserRole returns the forum role of a user. Users without a row in the
+// forum database, or without a role, are treated as regular users.
+func (r *UserRepositoryImpl) GetUserRole(ctx context.Context, id int64) (string, error) {
+	var role sql.NullString
+	err := r.db.QueryRowContext(ctx, "SELECT role FROM users WHERE id = $1", id).Scan(&role)
+	if err == sql.ErrNoRows || (err == nil && role.String == "") {
+		return entity.RoleUser, nil
+	}
+	if err != nil {
+		log.Printf("Error getting role for user %d: %v", id, err)
+		return "", err
+	}
+	return role.String, nil
+}
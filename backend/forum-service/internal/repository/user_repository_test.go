@@ -224,3 +224,45 @@ func TestUserRepository_GetUsernameByID_NotFoundAnywhere(t *testing.T) {
 	assert.Equal(t, "User_3", username)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepository_GetUserRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     *sqlmock.Rows
+		err      error
+		wantRole string
+		wantErr  bool
+	}{
+		{name: "admin", rows: sqlmock.NewRows([]string{"role"}).AddRow("admin"), wantRole: "admin"},
+		{name: "empty role", rows: sqlmock.NewRows([]string{"role"}).AddRow(nil), wantRole: "user"},
+		{name: "not synced yet", err: sql.ErrNoRows, wantRole: "user"},
+		{name: "db error", err: sql.ErrConnDone, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock DB: %v", err)
+			}
+			defer db.Close()
+			repo := &UserRepositoryImpl{db: db}
+
+			expect := mock.ExpectQuery(regexp.QuoteMeta("SELECT role FROM users WHERE id = $1")).WithArgs(1)
+			if tt.err != nil {
+				expect.WillReturnError(tt.err)
+			} else {
+				expect.WillReturnRows(tt.rows)
+			}
+
+			role, err := repo.GetUserRole(context.Background(), 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantRole, role)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/storage"
+)
+
+const (
+	// DefaultAttachmentCollectInterval is used when no positive interval is given
+	DefaultAttachmentCollectInterval = time.Hour
+	// attachmentCollectBatchSize is how many attachments are removed per query
+	attachmentCollectBatchSize = 100
+)
+
+// AttachmentCollector removes uploads that were never linked to a post
+// within the grace period, and attachments of purged posts, together with
+// their files. Rows are removed before the files, so several instances may
+// run it at the same time.
+type AttachmentCollector struct {
+	attachmentRepo repository.AttachmentRepository
+	storage        storage.Storage
+	grace          time.Duration
+	now            func() time.Time
+}
+
+// NewAttachmentCollector creates a collector that gives uploads the grace
+// period to be linked to a post
+func NewAttachmentCollector(attachmentRepo repository.AttachmentRepository, store storage.Storage, grace time.Duration) *AttachmentCollector {
+	return &AttachmentCollector{
+		attachmentRepo: attachmentRepo,
+		storage:        store,
+		grace:          grace,
+		now:            time.Now,
+	}
+}
+
+// Collect removes every orphaned attachment and returns how many were removed
+func (c *AttachmentCollector) Collect(ctx context.Context) (int, error) {
+	before := c.now().Add(-c.grace)
+	removed := 0
+	for {
+		attachments, err := c.attachmentRepo.DeleteOrphanedAttachments(ctx, before, attachmentCollectBatchSize)
+		if err != nil {
+			return removed, err
+		}
+		for _, attachment := range attachments {
+			deleteAttachmentFiles(ctx, c.storage, attachment)
+		}
+		removed += len(attachments)
+		if len(attachments) < attachmentCollectBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// Run collects orphaned attachments every interval until the context is
+// cancelled
+func (c *AttachmentCollector) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultAttachmentCollectInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			removed, err := c.Collect(ctx)
+			if err != nil {
+				log.Printf("Error collecting orphaned attachments: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("Removed %d orphaned attachments", removed)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
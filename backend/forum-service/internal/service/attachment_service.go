@@ -0,0 +1,396 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/storage"
+	"github.com/sout1235/forum2/backend/forum-service/internal/thumbnail"
+)
+
+var (
+	ErrEmptyAttachment            = errors.New("file is empty")
+	ErrAttachmentTooLarge         = errors.New("file is too large")
+	ErrAttachmentTypeNotAllowed   = errors.New("file type is not allowed")
+	ErrInvalidAttachmentPost      = errors.New("attachments can be linked only to a topic or a comment")
+	ErrNoAttachments              = errors.New("no attachments given")
+	ErrTooManyAttachments         = repository.ErrTooManyAttachments
+	ErrInvalidAttachmentSignature = errors.New("invalid or expired download link")
+	ErrMissingSigningKey          = errors.New("attachment signing key is required")
+)
+
+// AttachmentVariant is the file of an attachment a download link points to
+type AttachmentVariant string
+
+const (
+	AttachmentOriginal  AttachmentVariant = "download"
+	AttachmentThumbnail AttachmentVariant = "thumbnail"
+)
+
+// Defaults for the zero fields of AttachmentConfig
+const (
+	DefaultMaxAttachmentSize    = 10 << 20
+	DefaultAttachmentURLTTL     = time.Hour
+	DefaultAttachmentThumbSize  = 320
+	DefaultAttachmentURLPrefix  = "/api/v1/attachments"
+	attachmentStoragePrefix     = "attachments"
+	attachmentThumbnailSuffix   = "_thumb.jpg"
+	attachmentThumbnailMimeType = "image/jpeg"
+)
+
+// DefaultAttachmentTypes are the content types accepted when no list is
+// configured: common images, plain text such as logs, PDF and archives
+var DefaultAttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"text/plain", "application/pdf", "application/zip", "application/x-gzip",
+}
+
+// AttachmentConfig sets the limits of uploads and how download links are
+// signed
+type AttachmentConfig struct {
+	MaxSize       int64
+	AllowedTypes  []string
+	ThumbnailSize int
+	// Download links are signed with SigningKey and expire after URLTTL.
+	// The key is required and must be the same on every instance.
+	SigningKey []byte
+	URLTTL     time.Duration
+	URLPrefix  string
+}
+
+type attachmentService struct {
+	attachmentRepo repository.AttachmentRepository
+	topicRepo      repository.TopicRepository
+	commentRepo    repository.CommentRepository
+	storage        storage.Storage
+	policy         *policy.Policy
+	cfg            AttachmentConfig
+	allowed        map[string]bool
+	now            func() time.Time
+}
+
+// NewAttachmentService creates a new instance of AttachmentService. It
+// fails with ErrMissingSigningKey if no signing key is given.
+func NewAttachmentService(
+	attachmentRepo repository.AttachmentRepository,
+	topicRepo repository.TopicRepository,
+	commentRepo repository.CommentRepository,
+	userRepo repository.UserRepository,
+	store storage.Storage,
+	cfg AttachmentConfig,
+) (AttachmentService, error) {
+	if len(cfg.SigningKey) == 0 {
+		return nil, ErrMissingSigningKey
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxAttachmentSize
+	}
+	if len(cfg.AllowedTypes) == 0 {
+		cfg.AllowedTypes = DefaultAttachmentTypes
+	}
+	if cfg.ThumbnailSize <= 0 {
+		cfg.ThumbnailSize = DefaultAttachmentThumbSize
+	}
+	if cfg.URLTTL <= 0 {
+		cfg.URLTTL = DefaultAttachmentURLTTL
+	}
+	if cfg.URLPrefix == "" {
+		cfg.URLPrefix = DefaultAttachmentURLPrefix
+	}
+	allowed := make(map[string]bool, len(cfg.AllowedTypes))
+	for _, t := range cfg.AllowedTypes {
+		allowed[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		topicRepo:      topicRepo,
+		commentRepo:    commentRepo,
+		storage:        store,
+		policy:         policy.New(userRepo),
+		cfg:            cfg,
+		allowed:        allowed,
+		now:            time.Now,
+	}, nil
+}
+
+func (s *attachmentService) MaxUploadSize() int64 {
+	return s.cfg.MaxSize
+}
+
+// Upload stores a file of userID that isn't linked to a post yet. The type
+// is detected from the contents; the name and type given by the client are
+// not trusted.
+func (s *attachmentService) Upload(ctx context.Context, userID int64, filename string, r io.Reader) (*entity.Attachment, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.cfg.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptyAttachment
+	}
+	if int64(len(data)) > s.cfg.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || !s.allowed[contentType] {
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	key, err := newStorageKey(s.now())
+	if err != nil {
+		return nil, err
+	}
+	attachment := &entity.Attachment{
+		UploaderID:  userID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  key,
+	}
+	if err := s.storage.Put(ctx, key, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return nil, err
+	}
+
+	// Без превью вложение остается полезным, поэтому ошибка не фатальна
+	if thumbnail.Supported(contentType) {
+		thumb, err := thumbnail.Generate(data, s.cfg.ThumbnailSize)
+		if err == nil {
+			thumbKey := key + attachmentThumbnailSuffix
+			err = s.storage.Put(ctx, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), attachmentThumbnailMimeType)
+			if err == nil {
+				attachment.ThumbnailKey = thumbKey
+			}
+		}
+		if err != nil {
+			log.Printf("Error generating thumbnail for %s: %v", key, err)
+		}
+	}
+
+	if err := s.attachmentRepo.CreateAttachment(ctx, attachment); err != nil {
+		deleteAttachmentFiles(ctx, s.storage, attachment)
+		return nil, err
+	}
+	s.sign(attachment)
+	return attachment, nil
+}
+
+// GetAttachment returns an attachment with fresh download links. Uploads
+// that aren't linked yet are seen only by the uploader.
+func (s *attachmentService) GetAttachment(ctx context.Context, id, viewerID int64) (*entity.Attachment, error) {
+	attachment, visible, err := s.attachmentRepo.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !visible && (attachment.IsLinked() || attachment.UploaderID != viewerID) {
+		return nil, repository.ErrAttachmentNotFound
+	}
+	s.sign(attachment)
+	return attachment, nil
+}
+
+// GetTopicAttachments returns the attachments of a topic and its comments
+// with fresh download links
+func (s *attachmentService) GetTopicAttachments(ctx context.Context, topicID int64) ([]*entity.Attachment, error) {
+	if _, err := s.topicRepo.GetTopicByID(ctx, topicID); err != nil {
+		return nil, err
+	}
+	attachments, err := s.attachmentRepo.GetTopicAttachments(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		s.sign(attachment)
+	}
+	return attachments, nil
+}
+
+// LinkAttachments links uploads of userID to a post they may edit
+func (s *attachmentService) LinkAttachments(ctx context.Context, userID int64, postType entity.AttachmentPostType, postID int64, ids []int64) error {
+	if !postType.Valid() {
+		return ErrInvalidAttachmentPost
+	}
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return ErrNoAttachments
+	}
+	resource, ownerID, err := s.postOwner(ctx, postType, postID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.policy.Authorize(ctx, userID, resource, policy.ActionUpdate, ownerID); err != nil {
+		return err
+	}
+	return s.attachmentRepo.LinkAttachments(ctx, userID, postType, postID, ids, entity.MaxAttachmentsPerPost)
+}
+
+// DeleteAttachment removes an attachment and its files. An upload that isn't
+// linked yet may be removed by the uploader; a linked one also by a
+// moderator or an admin.
+func (s *attachmentService) DeleteAttachment(ctx context.Context, id, userID int64) error {
+	attachment, _, err := s.attachmentRepo.GetAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !attachment.IsLinked() {
+		if attachment.UploaderID != userID {
+			return repository.ErrAttachmentNotFound
+		}
+	} else {
+		resource := policy.ResourceTopic
+		if attachment.PostType == entity.AttachmentPostComment {
+			resource = policy.ResourceComment
+		}
+		if _, err := s.policy.Authorize(ctx, userID, resource, policy.ActionUpdate, attachment.UploaderID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.attachmentRepo.DeleteAttachment(ctx, id); err != nil {
+		return err
+	}
+	deleteAttachmentFiles(ctx, s.storage, attachment)
+	return nil
+}
+
+// OpenAttachment checks a signed download link and returns the file it
+// points to. The caller closes the reader.
+func (s *attachmentService) OpenAttachment(ctx context.Context, id int64, variant AttachmentVariant, expires int64, signature string) (*entity.Attachment, io.ReadCloser, error) {
+	if variant != AttachmentOriginal && variant != AttachmentThumbnail {
+		return nil, nil, repository.ErrAttachmentNotFound
+	}
+	if expires < s.now().Unix() || !hmac.Equal([]byte(signature), []byte(s.signature(id, variant, expires))) {
+		return nil, nil, ErrInvalidAttachmentSignature
+	}
+	attachment, _, err := s.attachmentRepo.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := attachment.StorageKey
+	if variant == AttachmentThumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, nil, repository.ErrAttachmentNotFound
+		}
+		key = attachment.ThumbnailKey
+		attachment.ContentType = attachmentThumbnailMimeType
+	}
+	file, err := s.storage.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, repository.ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	return attachment, file, nil
+}
+
+// postOwner returns the policy resource of a post and its author
+func (s *attachmentService) postOwner(ctx context.Context, postType entity.AttachmentPostType, postID int64) (policy.Resource, int64, error) {
+	if postType == entity.AttachmentPostTopic {
+		topic, err := s.topicRepo.GetTopicByID(ctx, postID)
+		if err != nil {
+			return "", 0, err
+		}
+		return policy.ResourceTopic, topic.AuthorID, nil
+	}
+	comment, err := s.commentRepo.GetCommentByID(ctx, postID)
+	if err != nil {
+		return "", 0, err
+	}
+	if comment.DeletedAt != nil {
+		return "", 0, repository.ErrCommentNotFound
+	}
+	return policy.ResourceComment, comment.AuthorID, nil
+}
+
+// sign fills in download links that expire after the configured time
+func (s *attachmentService) sign(attachment *entity.Attachment) {
+	expiresAt := s.now().Add(s.cfg.URLTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	attachment.URLExpiresAt = &expiresAt
+	attachment.URL = s.signedURL(attachment.ID, AttachmentOriginal, expires)
+	if attachment.ThumbnailKey != "" {
+		attachment.ThumbnailURL = s.signedURL(attachment.ID, AttachmentThumbnail, expires)
+	}
+}
+
+func (s *attachmentService) signedURL(id int64, variant AttachmentVariant, expires int64) string {
+	return fmt.Sprintf("%s/%d/%s?expires=%d&signature=%s", s.cfg.URLPrefix, id, variant, expires, s.signature(id, variant, expires))
+}
+
+func (s *attachmentService) signature(id int64, variant AttachmentVariant, expires int64) string {
+	mac := hmac.New(sha256.New, s.cfg.SigningKey)
+	fmt.Fprintf(mac, "%d/%s/%d", id, variant, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deleteAttachmentFiles removes the files of an attachment. A file that
+// can't be removed only takes up space, so errors are logged.
+func deleteAttachmentFiles(ctx context.Context, store storage.Storage, attachment *entity.Attachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("Error deleting attachment file %s: %v", key, err)
+		}
+	}
+}
+
+// newStorageKey returns a random key grouped by upload month
+func newStorageKey(now time.Time) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate storage key: %w", err)
+	}
+	return fmt.Sprintf("%s/%s/%s", attachmentStoragePrefix, now.UTC().Format("2006/01"), hex.EncodeToString(b)), nil
+}
+
+// cleanFilename keeps the last element of a client-supplied path without
+// control characters, shortened to the stored length
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if runes := []rune(name); len(runes) > entity.MaxAttachmentFilenameLength {
+		name = string(runes[:entity.MaxAttachmentFilenameLength])
+	}
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
+
+// uniqueIDs drops repeated and non-positive IDs, keeping the order
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
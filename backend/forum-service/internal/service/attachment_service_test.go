@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAttachmentRepo struct {
+	mock.Mock
+}
+
+func (m *mockAttachmentRepo) CreateAttachment(ctx context.Context, attachment *entity.Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *mockAttachmentRepo) GetAttachment(ctx context.Context, id int64) (*entity.Attachment, bool, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*entity.Attachment), args.Bool(1), args.Error(2)
+}
+
+func (m *mockAttachmentRepo) GetTopicAttachments(ctx context.Context, topicID int64) ([]*entity.Attachment, error) {
+	args := m.Called(ctx, topicID)
+	return args.Get(0).([]*entity.Attachment), args.Error(1)
+}
+
+func (m *mockAttachmentRepo) LinkAttachments(ctx context.Context, uploaderID int64, postType entity.AttachmentPostType, postID int64, ids []int64, limit int) error {
+	args := m.Called(ctx, uploaderID, postType, postID, ids, limit)
+	return args.Error(0)
+}
+
+func (m *mockAttachmentRepo) DeleteAttachment(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockAttachmentRepo) DeleteOrphanedAttachments(ctx context.Context, before time.Time, limit int) ([]*entity.Attachment, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]*entity.Attachment), args.Error(1)
+}
+
+var attachmentTestNow = time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+type attachmentTestDeps struct {
+	attachmentRepo *mockAttachmentRepo
+	topicRepo      *mockTopicRepo
+	commentRepo    *mockCommentRepo
+	userRepo       *mockUserRepo
+	storage        *storage.LocalStorage
+}
+
+func newTestAttachmentService(t *testing.T, cfg AttachmentConfig) (*attachmentService, attachmentTestDeps) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	deps := attachmentTestDeps{
+		attachmentRepo: new(mockAttachmentRepo),
+		topicRepo:      new(mockTopicRepo),
+		commentRepo:    new(mockCommentRepo),
+		userRepo:       new(mockUserRepo),
+		storage:        store,
+	}
+	cfg.SigningKey = []byte("secret")
+	svc, err := NewAttachmentService(deps.attachmentRepo, deps.topicRepo, deps.commentRepo, deps.userRepo, store, cfg)
+	require.NoError(t, err)
+	s := svc.(*attachmentService)
+	s.now = func() time.Time { return attachmentTestNow }
+	return s, deps
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+// openSigned follows a signed link the way the download endpoint does
+func openSigned(s *attachmentService, link string) (*entity.Attachment, []byte, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, nil, err
+	}
+	parts := strings.Split(u.Path, "/")
+	id, _ := strconv.ParseInt(parts[len(parts)-2], 10, 64)
+	expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	attachment, file, err := s.OpenAttachment(context.Background(), id, AttachmentVariant(parts[len(parts)-1]), expires, u.Query().Get("signature"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	return attachment, data, err
+}
+
+func TestAttachmentService_Upload(t *testing.T) {
+	s, deps := newTestAttachmentService(t, AttachmentConfig{})
+	var saved *entity.Attachment
+	deps.attachmentRepo.On("CreateAttachment", mock.Anything, mock.AnythingOfType("*entity.Attachment")).
+		Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*entity.Attachment)
+			saved.ID = 7
+		}).Return(nil)
+
+	// Имя и тип от клиента не учитываются
+	data := testPNG(t, 640, 480)
+	attachment, err := s.Upload(context.Background(), 5, "..\\..\\screen\x00shot.txt", bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "screenshot.txt", attachment.Filename)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.Equal(t, int64(len(data)), attachment.Size)
+	assert.True(t, strings.HasPrefix(attachment.StorageKey, "attachments/2024/03/"))
+	assert.NotEmpty(t, attachment.ThumbnailKey)
+	assert.Equal(t, attachmentTestNow.Add(time.Hour), *attachment.URLExpiresAt)
+
+	deps.attachmentRepo.On("GetAttachment", mock.Anything, int64(7)).Return(saved, false, nil)
+	_, original, err := openSigned(s, attachment.URL)
+	require.NoError(t, err)
+	assert.Equal(t, data, original)
+	thumb, thumbData, err := openSigned(s, attachment.ThumbnailURL)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", thumb.ContentType)
+	img, _, err := image.DecodeConfig(bytes.NewReader(thumbData))
+	require.NoError(t, err)
+	assert.Equal(t, 320, img.Width)
+}
+
+func TestAttachmentService_Upload_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"empty", nil, ErrEmptyAttachment},
+		{"too large", bytes.Repeat([]byte("a"), 65), ErrAttachmentTooLarge},
+		{"html", []byte("<!DOCTYPE html><script>alert(1)</script>"), ErrAttachmentTypeNotAllowed},
+		{"not allowed", []byte("%PDF-1.4"), ErrAttachmentTypeNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, deps := newTestAttachmentService(t, AttachmentConfig{MaxSize: 64, AllowedTypes: []string{"text/plain"}})
+			_, err := s.Upload(context.Background(), 5, "a.txt", bytes.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.wantErr)
+			deps.attachmentRepo.AssertNotCalled(t, "CreateAttachment", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestNewAttachmentService_MissingSigningKey(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	_, err = NewAttachmentService(new(mockAttachmentRepo), new(mockTopicRepo), new(mockCommentRepo), new(mockUserRepo), store, AttachmentConfig{})
+	assert.ErrorIs(t, err, ErrMissingSigningKey)
+}
+
+func TestAttachmentService_OpenAttachment_InvalidLink(t *testing.T) {
+	s, deps := newTestAttachmentService(t, AttachmentConfig{})
+	deps.attachmentRepo.On("GetAttachment", mock.Anything, int64(7)).
+		Return(&entity.Attachment{ID: 7, StorageKey: "attachments/2024/03/a"}, true, nil)
+	attachment, err := s.GetAttachment(context.Background(), 7, 0)
+	require.NoError(t, err)
+	assert.Empty(t, attachment.ThumbnailURL)
+
+	// Подпись не подходит к другому вложению и другому файлу
+	_, _, err = openSigned(s, strings.Replace(attachment.URL, "/7/", "/8/", 1))
+	assert.ErrorIs(t, err, ErrInvalidAttachmentSignature)
+	_, _, err = openSigned(s, strings.Replace(attachment.URL, "/download", "/thumbnail", 1))
+	assert.ErrorIs(t, err, ErrInvalidAttachmentSignature)
+
+	// Ссылка перестает работать после срока
+	s.now = func() time.Time { return attachmentTestNow.Add(time.Hour + time.Second) }
+	_, _, err = openSigned(s, attachment.URL)
+	assert.ErrorIs(t, err, ErrInvalidAttachmentSignature)
+}
+
+func TestAttachmentService_GetAttachment_Visibility(t *testing.T) {
+	s, deps := newTestAttachmentService(t, AttachmentConfig{})
+	deps.attachmentRepo.On("GetAttachment", mock.Anything, int64(1)).
+		Return(&entity.Attachment{ID: 1, UploaderID: 5}, false, nil)
+	deps.attachmentRepo.On("GetAttachment", mock.Anything, int64(2)).
+		Return(&entity.Attachment{ID: 2, UploaderID: 5, PostType: entity.AttachmentPostComment, PostID: 3}, false, nil)
+
+	// Непривязанный файл видит только загрузивший
+	_, err := s.GetAttachment(context.Background(), 1, 5)
+	assert.NoError(t, err)
+	_, err = s.GetAttachment(context.Background(), 1, 6)
+	assert.ErrorIs(t, err, repository.ErrAttachmentNotFound)
+	_, err = s.GetAttachment(context.Background(), 1, 0)
+	assert.ErrorIs(t, err, repository.ErrAttachmentNotFound)
+	// Вложения скрытых записей не видны даже загрузившему
+	_, err = s.GetAttachment(context.Background(), 2, 5)
+	assert.ErrorIs(t, err, repository.ErrAttachmentNotFound)
+}
+
+func TestAttachmentService_LinkAttachments(t *testing.T) {
+	s, deps := newTestAttachmentService(t, AttachmentConfig{})
+	deps.topicRepo.On("GetTopicByID", mock.Anything, int64(4)).Return(&entity.Topic{ID: 4, AuthorID: 5}, nil)
+	deps.userRepo.On("GetUserRole", mock.Anything, int64(5)).Return(entity.RoleUser, nil)
+	deps.userRepo.On("GetUserRole", mock.Anything, int64(6)).Return(entity.RoleUser, nil)
+	deps.attachmentRepo.On("LinkAttachments", mock.Anything, int64(5), entity.AttachmentPostTopic, int64(4), []int64{1, 2}, entity.MaxAttachmentsPerPost).Return(nil)
+	deps.attachmentRepo.On("LinkAttachments", mock.Anything, int64(5), entity.AttachmentPostTopic, int64(4), []int64{1, 2, 3}, entity.MaxAttachmentsPerPost).
+		Return(repository.ErrTooManyAttachments)
+
+	// Повторы схлопываются
+	err := s.LinkAttachments(context.Background(), 5, entity.AttachmentPostTopic, 4, []int64{1, 2, 1})
+	assert.NoError(t, err)
+
+	err = s.LinkAttachments(context.Background(), 5, entity.AttachmentPostTopic, 4, []int64{1, 2, 3})
+	assert.ErrorIs(t, err, ErrTooManyAttachments)
+	err = s.LinkAttachments(context.Background(), 6, entity.AttachmentPostTopic, 4, []int64{1})
+	assert.ErrorIs(t, err, policy.ErrForbidden)
+	err = s.LinkAttachments(context.Background(), 5, entity.AttachmentPostTopic, 4, nil)
+	assert.ErrorIs(t, err, ErrNoAttachments)
+	err = s.LinkAttachments(context.Background(), 5, "chat", 4, []int64{1})
+	assert.ErrorIs(t, err, ErrInvalidAttachmentPost)
+	deps.attachmentRepo.AssertNumberOfCalls(t, "LinkAttachments", 2)
+}
+
+func TestAttachmentService_LinkAttachments_DeletedComment(t *testing.T) {
+	s, deps := newTestAttachmentService(t, AttachmentConfig{})
+	deletedAt := attachmentTestNow
+	deps.commentRepo.On("GetCommentByID", mock.Anything, int64(3)).
+		Return(&entity.Comment{ID: 3, AuthorID: 5, DeletedAt: &deletedAt}, nil)
+
+	err := s.LinkAttachments(context.Background(), 5, entity.AttachmentPostComment, 3, []int64{1})
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound)
+	deps.attachmentRepo.AssertNotCalled(t, "LinkAttachments", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAttachmentService_DeleteAttachment(t *testing.T) {
+	s, deps := newTestAttachmentService(t, AttachmentConfig{})
+	ctx := context.Background()
+	require.NoError(t, deps.storage.Put(ctx, "attachments/a", strings.NewReader("a"), 1, "text/plain"))
+	deps.attachmentRepo.On("GetAttachment", mock.Anything, int64(1)).
+		Return(&entity.Attachment{ID: 1, UploaderID: 5, StorageKey: "attachments/a"}, false, nil)
+	deps.attachmentRepo.On("GetAttachment", mock.Anything, int64(2)).
+		Return(&entity.Attachment{ID: 2, UploaderID: 5, PostType: entity.AttachmentPostTopic, PostID: 4, StorageKey: "attachments/b"}, true, nil)
+	deps.userRepo.On("GetUserRole", mock.Anything, int64(6)).Return(entity.RoleUser, nil)
+	deps.attachmentRepo.On("DeleteAttachment", mock.Anything, int64(1)).Return(nil)
+
+	assert.ErrorIs(t, s.DeleteAttachment(ctx, 1, 6), repository.ErrAttachmentNotFound)
+	assert.ErrorIs(t, s.DeleteAttachment(ctx, 2, 6), policy.ErrForbidden)
+	assert.NoError(t, s.DeleteAttachment(ctx, 1, 5))
+	_, err := deps.storage.Open(ctx, "attachments/a")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	deps.attachmentRepo.AssertNumberOfCalls(t, "DeleteAttachment", 1)
+}
+
+func TestAttachmentCollector_Collect(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "attachments/a", strings.NewReader("a"), 1, "image/png"))
+	require.NoError(t, store.Put(ctx, "attachments/a_thumb.jpg", strings.NewReader("t"), 1, "image/jpeg"))
+
+	repo := new(mockAttachmentRepo)
+	full := make([]*entity.Attachment, attachmentCollectBatchSize)
+	for i := range full {
+		full[i] = &entity.Attachment{ID: int64(i + 2), StorageKey: "attachments/missing"}
+	}
+	before := attachmentTestNow.Add(-24 * time.Hour)
+	// Полная пачка означает, что могут быть еще
+	repo.On("DeleteOrphanedAttachments", mock.Anything, before, attachmentCollectBatchSize).Return(full, nil).Once()
+	repo.On("DeleteOrphanedAttachments", mock.Anything, before, attachmentCollectBatchSize).Return([]*entity.Attachment{
+		{ID: 1, StorageKey: "attachments/a", ThumbnailKey: "attachments/a_thumb.jpg"},
+	}, nil).Once()
+
+	c := NewAttachmentCollector(repo, store, 24*time.Hour)
+	c.now = func() time.Time { return attachmentTestNow }
+	removed, err := c.Collect(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, attachmentCollectBatchSize+1, removed)
+	_, err = store.Open(ctx, "attachments/a")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = store.Open(ctx, "attachments/a_thumb.jpg")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/avatar"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/storage"
+)
+
+var (
+	ErrEmptyAvatar             = errors.New("avatar file is empty")
+	ErrAvatarTooLarge          = errors.New("avatar file is too large")
+	ErrAvatarTypeNotAllowed    = errors.New("avatar must be a PNG, JPEG or WebP image")
+	ErrInvalidAvatarDimensions = errors.New("avatar dimensions are out of range")
+)
+
+// Defaults for the zero fields of AvatarConfig
+const (
+	DefaultMaxAvatarSize = 5 << 20
+	DefaultAvatarMinSide = 64
+	DefaultAvatarMaxSide = 4096
+	avatarStoragePrefix  = "avatars"
+	avatarMimeType       = "image/png"
+	generatedAvatarType  = "image/svg+xml"
+)
+
+// DefaultAvatarSizes are the square sizes, in pixels, every uploaded avatar
+// is stored in
+var DefaultAvatarSizes = []int{32, 64, 128, 256}
+
+// AvatarConfig sets the limits of uploaded avatars and the look of
+// generated ones
+type AvatarConfig struct {
+	MaxSize int64
+	MinSide int
+	MaxSide int
+	Sizes   []int
+	Style   avatar.Style
+}
+
+// AvatarFile is an avatar picture ready to be sent
+type AvatarFile struct {
+	ContentType string
+	Body        io.ReadCloser
+	// Generated is set for the avatars of users without an upload
+	Generated bool
+}
+
+type avatarService struct {
+	avatarRepo repository.AvatarRepository
+	userRepo   repository.UserRepository
+	storage    storage.Storage
+	cfg        AvatarConfig
+}
+
+// NewAvatarService creates a new instance of AvatarService
+func NewAvatarService(
+	avatarRepo repository.AvatarRepository,
+	userRepo repository.UserRepository,
+	store storage.Storage,
+	cfg AvatarConfig,
+) AvatarService {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxAvatarSize
+	}
+	if cfg.MinSide <= 0 {
+		cfg.MinSide = DefaultAvatarMinSide
+	}
+	if cfg.MaxSide <= 0 {
+		cfg.MaxSide = DefaultAvatarMaxSide
+	}
+	if len(cfg.Sizes) == 0 {
+		cfg.Sizes = DefaultAvatarSizes
+	}
+	cfg.Sizes = slices.Sorted(slices.Values(cfg.Sizes))
+	if !cfg.Style.Valid() {
+		cfg.Style = avatar.StyleIdenticon
+	}
+	return &avatarService{
+		avatarRepo: avatarRepo,
+		userRepo:   userRepo,
+		storage:    store,
+		cfg:        cfg,
+	}
+}
+
+func (s *avatarService) MaxUploadSize() int64 {
+	return s.cfg.MaxSize
+}
+
+// UploadAvatar crops the picture to a square, stores it in every configured
+// size and makes it the avatar of the user instead of the previous one
+func (s *avatarService) UploadAvatar(ctx context.Context, userID int64, r io.Reader) (*entity.User, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.cfg.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptyAvatar
+	}
+	if int64(len(data)) > s.cfg.MaxSize {
+		return nil, ErrAvatarTooLarge
+	}
+	// Тип определяется по содержимому, а не по заявленному клиентом
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || (contentType != "image/png" && contentType != "image/jpeg" && contentType != "image/webp") {
+		return nil, ErrAvatarTypeNotAllowed
+	}
+
+	images, err := avatar.Resize(data, avatar.Limits{MinSide: s.cfg.MinSide, MaxSide: s.cfg.MaxSide}, s.cfg.Sizes)
+	switch {
+	case errors.Is(err, avatar.ErrUnsupportedFormat):
+		return nil, ErrAvatarTypeNotAllowed
+	case errors.Is(err, avatar.ErrTooSmall), errors.Is(err, avatar.ErrTooLarge):
+		return nil, fmt.Errorf("%w: width and height must be between %d and %d pixels",
+			ErrInvalidAvatarDimensions, s.cfg.MinSide, s.cfg.MaxSide)
+	case err != nil:
+		return nil, err
+	}
+
+	// Пользователь мог еще не попасть в локальную базу из auth-service
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate avatar key: %w", err)
+	}
+	version := hex.EncodeToString(token)
+	key := fmt.Sprintf("%s/%d/%s", avatarStoragePrefix, userID, version)
+	for _, size := range s.cfg.Sizes {
+		img := images[size]
+		if err := s.storage.Put(ctx, avatarFileKey(key, size), bytes.NewReader(img), int64(len(img)), avatarMimeType); err != nil {
+			s.deleteFiles(ctx, key)
+			return nil, err
+		}
+	}
+
+	// Версия в ссылке сбрасывает кэш браузеров после замены аватара
+	url := entity.DefaultAvatarURL(userID) + "?v=" + version
+	previous, err := s.avatarRepo.SetAvatar(ctx, userID, key, url)
+	if err != nil {
+		s.deleteFiles(ctx, key)
+		return nil, err
+	}
+	s.deleteFiles(ctx, previous)
+
+	return &entity.User{ID: userID, Username: user.Username, Avatar: url}, nil
+}
+
+// DeleteAvatar removes the uploaded avatar; the user gets the generated one
+func (s *avatarService) DeleteAvatar(ctx context.Context, userID int64) (*entity.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.avatarRepo.SetAvatar(ctx, userID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	s.deleteFiles(ctx, previous)
+
+	return &entity.User{ID: userID, Username: user.Username, Avatar: entity.DefaultAvatarURL(userID)}, nil
+}
+
+// OpenAvatar returns the smallest stored size that is at least size pixels,
+// the largest one for size 0, or a generated avatar if the user has none
+func (s *avatarService) OpenAvatar(ctx context.Context, userID int64, size int) (*AvatarFile, error) {
+	key, username, err := s.avatarRepo.GetAvatar(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if key != "" {
+		body, err := s.storage.Open(ctx, avatarFileKey(key, s.pickSize(size)))
+		if err == nil {
+			return &AvatarFile{ContentType: avatarMimeType, Body: body}, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		log.Printf("Avatar files of user %d are missing, serving a generated one", userID)
+	}
+
+	// Сгенерированный аватар зависит только от ID и имени, поэтому одинаков
+	// при каждом запросе
+	svg := avatar.Generate(s.cfg.Style, strconv.FormatInt(userID, 10), username)
+	return &AvatarFile{
+		ContentType: generatedAvatarType,
+		Body:        io.NopCloser(bytes.NewReader(svg)),
+		Generated:   true,
+	}, nil
+}
+
+func (s *avatarService) pickSize(size int) int {
+	for _, stored := range s.cfg.Sizes {
+		if size > 0 && stored >= size {
+			return stored
+		}
+	}
+	return s.cfg.Sizes[len(s.cfg.Sizes)-1]
+}
+
+// deleteFiles removes the stored sizes of an avatar. A file left behind
+// only takes space, so errors are logged.
+func (s *avatarService) deleteFiles(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	for _, size := range s.cfg.Sizes {
+		if err := s.storage.Delete(ctx, avatarFileKey(key, size)); err != nil {
+			log.Printf("Error deleting avatar file of %s: %v", key, err)
+		}
+	}
+}
+
+func avatarFileKey(key string, size int) string {
+	return fmt.Sprintf("%s/%d.png", key, size)
+}
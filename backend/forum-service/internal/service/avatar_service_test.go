@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/avatar"
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAvatarRepo struct {
+	mock.Mock
+}
+
+func (m *mockAvatarRepo) GetAvatar(ctx context.Context, userID int64) (string, string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *mockAvatarRepo) SetAvatar(ctx context.Context, userID int64, key, url string) (string, error) {
+	args := m.Called(ctx, userID, key, url)
+	return args.String(0), args.Error(1)
+}
+
+func newTestAvatarService(t *testing.T, cfg AvatarConfig) (*avatarService, *mockAvatarRepo, *mockUserRepo, string) {
+	root := t.TempDir()
+	store, err := storage.NewLocalStorage(root)
+	require.NoError(t, err)
+	avatarRepo := new(mockAvatarRepo)
+	userRepo := new(mockUserRepo)
+	return NewAvatarService(avatarRepo, userRepo, store, cfg).(*avatarService), avatarRepo, userRepo, root
+}
+
+func readAvatar(t *testing.T, file *AvatarFile) []byte {
+	defer file.Body.Close()
+	data, err := io.ReadAll(file.Body)
+	require.NoError(t, err)
+	return data
+}
+
+func TestAvatarService_UploadAvatar(t *testing.T) {
+	s, avatarRepo, userRepo, root := newTestAvatarService(t, AvatarConfig{Sizes: []int{128, 32}})
+	userRepo.On("GetUserByID", mock.Anything, int64(5)).Return(&entity.User{ID: 5, Username: "alice"}, nil)
+
+	// Старый аватар удаляется после замены
+	oldKey := "avatars/5/old"
+	for _, name := range []string{"32.png", "128.png"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, oldKey), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, oldKey, name), []byte("old"), 0o644))
+	}
+	var key string
+	avatarRepo.On("SetAvatar", mock.Anything, int64(5), mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { key = args.String(2) }).
+		Return(oldKey, nil)
+
+	user, err := s.UploadAvatar(context.Background(), 5, bytes.NewReader(testPNG(t, 300, 200)))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user.Username)
+	assert.True(t, strings.HasPrefix(key, "avatars/5/"))
+	assert.Equal(t, "/api/v1/users/5/avatar?v="+strings.TrimPrefix(key, "avatars/5/"), user.Avatar)
+	assert.NoFileExists(t, filepath.Join(root, oldKey, "32.png"))
+
+	avatarRepo.On("GetAvatar", mock.Anything, int64(5)).Return(key, "alice", nil)
+	tests := map[int]int{0: 128, 16: 32, 32: 32, 64: 128, 512: 128}
+	for requested, want := range tests {
+		file, err := s.OpenAvatar(context.Background(), 5, requested)
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", file.ContentType)
+		assert.False(t, file.Generated)
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(readAvatar(t, file)))
+		require.NoError(t, err)
+		assert.Equal(t, want, cfg.Width, "size %d", requested)
+		assert.Equal(t, want, cfg.Height, "size %d", requested)
+	}
+}
+
+func TestAvatarService_UploadAvatar_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"empty", nil, ErrEmptyAvatar},
+		{"too large", bytes.Repeat([]byte("a"), 4097), ErrAvatarTooLarge},
+		{"not an image", []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), ErrAvatarTypeNotAllowed},
+		{"too small", testPNG(t, 100, 20), ErrInvalidAvatarDimensions},
+		{"too wide", testPNG(t, 600, 100), ErrInvalidAvatarDimensions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, avatarRepo, _, _ := newTestAvatarService(t, AvatarConfig{MaxSize: 4096, MaxSide: 512})
+			_, err := s.UploadAvatar(context.Background(), 5, bytes.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.wantErr)
+			avatarRepo.AssertNotCalled(t, "SetAvatar", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAvatarService_UploadAvatar_SaveFailed(t *testing.T) {
+	s, avatarRepo, userRepo, root := newTestAvatarService(t, AvatarConfig{})
+	userRepo.On("GetUserByID", mock.Anything, int64(5)).Return(&entity.User{ID: 5, Username: "alice"}, nil)
+	avatarRepo.On("SetAvatar", mock.Anything, int64(5), mock.Anything, mock.Anything).Return("", repository.ErrUserNotFound)
+
+	_, err := s.UploadAvatar(context.Background(), 5, bytes.NewReader(testPNG(t, 100, 100)))
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	// Файлы несохраненного аватара не остаются в хранилище
+	files, err := filepath.Glob(filepath.Join(root, "avatars", "5", "*", "*.png"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestAvatarService_DeleteAvatar(t *testing.T) {
+	s, avatarRepo, userRepo, _ := newTestAvatarService(t, AvatarConfig{})
+	userRepo.On("GetUserByID", mock.Anything, int64(5)).Return(&entity.User{ID: 5, Username: "alice"}, nil)
+	avatarRepo.On("SetAvatar", mock.Anything, int64(5), "", "").Return("avatars/5/old", nil)
+
+	user, err := s.DeleteAvatar(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/users/5/avatar", user.Avatar)
+	avatarRepo.AssertExpectations(t)
+}
+
+func TestAvatarService_OpenAvatar_Generated(t *testing.T) {
+	s, avatarRepo, _, _ := newTestAvatarService(t, AvatarConfig{Style: avatar.StyleInitials})
+	avatarRepo.On("GetAvatar", mock.Anything, int64(5)).Return("", "john doe", nil)
+	avatarRepo.On("GetAvatar", mock.Anything, int64(6)).Return("", "", repository.ErrUserNotFound)
+	// Файлы пропали из хранилища
+	avatarRepo.On("GetAvatar", mock.Anything, int64(7)).Return("avatars/7/gone", "bob", nil)
+
+	file, err := s.OpenAvatar(context.Background(), 5, 64)
+	require.NoError(t, err)
+	assert.True(t, file.Generated)
+	assert.Equal(t, "image/svg+xml", file.ContentType)
+	first := readAvatar(t, file)
+	assert.Contains(t, string(first), ">JD</text>")
+
+	file, err = s.OpenAvatar(context.Background(), 5, 128)
+	require.NoError(t, err)
+	assert.Equal(t, first, readAvatar(t, file))
+
+	file, err = s.OpenAvatar(context.Background(), 6, 0)
+	require.NoError(t, err)
+	assert.Equal(t, avatar.Generate(avatar.StyleIdenticon, "6", ""), readAvatar(t, file))
+
+	file, err = s.OpenAvatar(context.Background(), 7, 0)
+	require.NoError(t, err)
+	assert.Contains(t, string(readAvatar(t, file)), ">B</text>")
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+const (
+	// DefaultReminderInterval is used when no positive reminder interval is given
+	DefaultReminderInterval = time.Minute
+	// reminderBatchSize is how many reminders are claimed per query
+	reminderBatchSize = 100
+)
+
+// BookmarkReminder sends the bookmark reminders that came due. Reminders are
+// claimed before they are sent, so several instances may run it at the same
+// time without sending one twice. A reminder that fails to send is released
+// and tried again on the next run.
+type BookmarkReminder struct {
+	bookmarkRepo repository.BookmarkRepository
+	notifier     Notifier
+	now          func() time.Time
+}
+
+// NewBookmarkReminder creates a reminder that notifies users through
+// notifier
+func NewBookmarkReminder(bookmarkRepo repository.BookmarkRepository, notifier Notifier) *BookmarkReminder {
+	if notifier == nil {
+		notifier = NopNotifier
+	}
+	return &BookmarkReminder{
+		bookmarkRepo: bookmarkRepo,
+		notifier:     notifier,
+		now:          time.Now,
+	}
+}
+
+// SendDue sends every reminder due by now and returns how many were sent.
+// It stops at the first batch in which a reminder failed.
+func (r *BookmarkReminder) SendDue(ctx context.Context) (int, error) {
+	now := r.now()
+	sent := 0
+	for {
+		bookmarks, err := r.bookmarkRepo.ClaimDueReminders(ctx, now, reminderBatchSize)
+		if err != nil {
+			return sent, err
+		}
+		var failed error
+		for _, bookmark := range bookmarks {
+			if err := r.notifier.BookmarkReminder(ctx, bookmark); err != nil {
+				failed = fmt.Errorf("failed to send reminder of bookmark %d: %w", bookmark.ID, err)
+				// Снятое требование вернет напоминание в очередь к следующему запуску
+				if err := r.bookmarkRepo.ReleaseReminder(ctx, bookmark.ID, now); err != nil {
+					log.Printf("Error releasing reminder of bookmark %d: %v", bookmark.ID, err)
+				}
+				continue
+			}
+			sent++
+		}
+		if failed != nil {
+			return sent, failed
+		}
+		if len(bookmarks) < reminderBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+// Run sends due reminders every interval until the context is cancelled
+func (r *BookmarkReminder) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReminderInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sent, err := r.SendDue(ctx)
+			if err != nil {
+				log.Printf("Error sending bookmark reminders: %v", err)
+				continue
+			}
+			if sent > 0 {
+				log.Printf("Sent %d bookmark reminders", sent)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBookmarkReminder_SendDue(t *testing.T) {
+	repo := new(mockBookmarkRepo)
+	notifier := new(mockNotifier)
+	reminder := NewBookmarkReminder(repo, notifier)
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	reminder.now = func() time.Time { return now }
+
+	// Полная пачка означает, что напоминания могли остаться
+	full := make([]*entity.Bookmark, reminderBatchSize)
+	for i := range full {
+		full[i] = &entity.Bookmark{ID: int64(i + 1), UserID: 2}
+	}
+	last := &entity.Bookmark{ID: 500, UserID: 3, TargetType: entity.BookmarkTargetTopic, TargetID: 4, Note: "read later"}
+	repo.On("ClaimDueReminders", mock.Anything, now, reminderBatchSize).Return(full, nil).Once()
+	repo.On("ClaimDueReminders", mock.Anything, now, reminderBatchSize).Return([]*entity.Bookmark{last}, nil).Once()
+	notifier.On("BookmarkReminder", mock.Anything, mock.Anything).Return(nil)
+
+	sent, err := reminder.SendDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, reminderBatchSize+1, sent)
+	notifier.AssertCalled(t, "BookmarkReminder", mock.Anything, last)
+	repo.AssertExpectations(t)
+}
+
+func TestBookmarkReminder_SendDue_Error(t *testing.T) {
+	repo := new(mockBookmarkRepo)
+	notifier := new(mockNotifier)
+	reminder := NewBookmarkReminder(repo, notifier)
+
+	repo.On("ClaimDueReminders", mock.Anything, mock.Anything, reminderBatchSize).Return(nil, errors.New("db down"))
+
+	_, err := reminder.SendDue(context.Background())
+	assert.Error(t, err)
+	notifier.AssertNotCalled(t, "BookmarkReminder", mock.Anything, mock.Anything)
+}
+
+func TestBookmarkReminder_SendDue_NotifyError(t *testing.T) {
+	repo := new(mockBookmarkRepo)
+	notifier := new(mockNotifier)
+	reminder := NewBookmarkReminder(repo, notifier)
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	reminder.now = func() time.Time { return now }
+
+	lost := &entity.Bookmark{ID: 1, UserID: 2}
+	delivered := &entity.Bookmark{ID: 2, UserID: 3}
+	repo.On("ClaimDueReminders", mock.Anything, now, reminderBatchSize).Return([]*entity.Bookmark{lost, delivered}, nil).Once()
+	notifier.On("BookmarkReminder", mock.Anything, lost).Return(errors.New("db down"))
+	notifier.On("BookmarkReminder", mock.Anything, delivered).Return(nil)
+	// Неотправленное напоминание снова ждет своей очереди
+	repo.On("ReleaseReminder", mock.Anything, int64(1), now).Return(nil).Once()
+
+	sent, err := reminder.SendDue(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, sent)
+	repo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
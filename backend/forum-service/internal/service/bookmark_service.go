@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+var (
+	ErrInvalidBookmarkTarget = errors.New("bookmark target must be a topic or a comment")
+	ErrBookmarkNoteTooLong   = errors.New("bookmark note is too long")
+	ErrReminderInPast        = errors.New("reminder time must be in the future")
+)
+
+type bookmarkService struct {
+	bookmarkRepo repository.BookmarkRepository
+	now          func() time.Time
+}
+
+// NewBookmarkService creates a new instance of BookmarkService
+func NewBookmarkService(bookmarkRepo repository.BookmarkRepository) BookmarkService {
+	return &bookmarkService{
+		bookmarkRepo: bookmarkRepo,
+		now:          time.Now,
+	}
+}
+
+func (s *bookmarkService) CreateBookmark(ctx context.Context, bookmark *entity.Bookmark) error {
+	if !bookmark.TargetType.Valid() {
+		return ErrInvalidBookmarkTarget
+	}
+	if err := s.validate(bookmark, nil); err != nil {
+		return err
+	}
+	return s.bookmarkRepo.CreateBookmark(ctx, bookmark)
+}
+
+func (s *bookmarkService) GetBookmark(ctx context.Context, userID, id int64) (*entity.Bookmark, error) {
+	return s.bookmarkRepo.GetBookmark(ctx, userID, id)
+}
+
+func (s *bookmarkService) ListBookmarks(ctx context.Context, filter entity.BookmarkFilter) (*entity.BookmarkPage, error) {
+	return s.bookmarkRepo.GetBookmarks(ctx, filter)
+}
+
+// UpdateBookmark replaces the note and the reminder of a user's bookmark.
+// A reminder that already fired may be kept as it is.
+func (s *bookmarkService) UpdateBookmark(ctx context.Context, bookmark *entity.Bookmark) error {
+	current, err := s.bookmarkRepo.GetBookmark(ctx, bookmark.UserID, bookmark.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(bookmark, current.RemindAt); err != nil {
+		return err
+	}
+	return s.bookmarkRepo.UpdateBookmark(ctx, bookmark)
+}
+
+func (s *bookmarkService) DeleteBookmark(ctx context.Context, userID, id int64) error {
+	return s.bookmarkRepo.DeleteBookmark(ctx, userID, id)
+}
+
+// validate checks the note and the reminder of a bookmark. A new reminder
+// has to be in the future; the current one is accepted as it is.
+func (s *bookmarkService) validate(bookmark *entity.Bookmark, currentRemindAt *time.Time) error {
+	if utf8.RuneCountInString(bookmark.Note) > entity.MaxBookmarkNoteLength {
+		return ErrBookmarkNoteTooLong
+	}
+	if bookmark.RemindAt == nil {
+		return nil
+	}
+	if currentRemindAt != nil && currentRemindAt.Equal(*bookmark.RemindAt) {
+		return nil
+	}
+	if !bookmark.RemindAt.After(s.now()) {
+		return ErrReminderInPast
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBookmarkRepo struct {
+	mock.Mock
+}
+
+func (m *mockBookmarkRepo) CreateBookmark(ctx context.Context, bookmark *entity.Bookmark) error {
+	args := m.Called(ctx, bookmark)
+	return args.Error(0)
+}
+
+func (m *mockBookmarkRepo) GetBookmark(ctx context.Context, userID, id int64) (*entity.Bookmark, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Bookmark), args.Error(1)
+}
+
+func (m *mockBookmarkRepo) GetBookmarks(ctx context.Context, filter entity.BookmarkFilter) (*entity.BookmarkPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BookmarkPage), args.Error(1)
+}
+
+func (m *mockBookmarkRepo) UpdateBookmark(ctx context.Context, bookmark *entity.Bookmark) error {
+	args := m.Called(ctx, bookmark)
+	return args.Error(0)
+}
+
+func (m *mockBookmarkRepo) DeleteBookmark(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *mockBookmarkRepo) ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]*entity.Bookmark, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Bookmark), args.Error(1)
+}
+
+func (m *mockBookmarkRepo) ReleaseReminder(ctx context.Context, id int64, claimedAt time.Time) error {
+	args := m.Called(ctx, id, claimedAt)
+	return args.Error(0)
+}
+
+func TestBookmarkService_CreateBookmark(t *testing.T) {
+	repo := new(mockBookmarkRepo)
+	s := NewBookmarkService(repo).(*bookmarkService)
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	later := now.Add(24 * time.Hour)
+	earlier := now.Add(-time.Minute)
+	bookmark := &entity.Bookmark{UserID: 2, TargetType: entity.BookmarkTargetComment, TargetID: 10, RemindAt: &later}
+	repo.On("CreateBookmark", mock.Anything, bookmark).Return(nil).Once()
+
+	assert.NoError(t, s.CreateBookmark(context.Background(), bookmark))
+
+	tests := []struct {
+		name     string
+		bookmark *entity.Bookmark
+		wantErr  error
+	}{
+		{"chat message", &entity.Bookmark{TargetType: "chat_message", TargetID: 1}, ErrInvalidBookmarkTarget},
+		{"long note", &entity.Bookmark{TargetType: entity.BookmarkTargetTopic, TargetID: 1, Note: strings.Repeat("я", entity.MaxBookmarkNoteLength+1)}, ErrBookmarkNoteTooLong},
+		{"past reminder", &entity.Bookmark{TargetType: entity.BookmarkTargetTopic, TargetID: 1, RemindAt: &earlier}, ErrReminderInPast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, s.CreateBookmark(context.Background(), tt.bookmark), tt.wantErr)
+		})
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestBookmarkService_UpdateBookmark(t *testing.T) {
+	repo := new(mockBookmarkRepo)
+	s := NewBookmarkService(repo).(*bookmarkService)
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	// Сработавшее напоминание можно оставить как есть, но не перенести в прошлое
+	fired := now.Add(-time.Hour)
+	earlier := now.Add(-time.Minute)
+	repo.On("GetBookmark", mock.Anything, int64(2), int64(1)).Return(&entity.Bookmark{ID: 1, UserID: 2, RemindAt: &fired}, nil)
+	kept := &entity.Bookmark{ID: 1, UserID: 2, Note: "new note", RemindAt: &fired}
+	repo.On("UpdateBookmark", mock.Anything, kept).Return(nil).Once()
+
+	assert.NoError(t, s.UpdateBookmark(context.Background(), kept))
+	assert.ErrorIs(t, s.UpdateBookmark(context.Background(), &entity.Bookmark{ID: 1, UserID: 2, RemindAt: &earlier}), ErrReminderInPast)
+
+	repo.On("GetBookmark", mock.Anything, int64(2), int64(9)).Return(nil, repository.ErrBookmarkNotFound)
+	assert.ErrorIs(t, s.UpdateBookmark(context.Background(), &entity.Bookmark{ID: 9, UserID: 2}), repository.ErrBookmarkNotFound)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+// maxCategoryNameLength matches categories.name VARCHAR(50)
+const maxCategoryNameLength = 50
+
+var (
+	ErrInvalidCategoryName  = errors.New("category name must be between 1 and 50 characters")
+	ErrInvalidCategoryOrder = errors.New("category order must list each category once")
+)
+
+type categoryService struct {
+	categoryRepo repository.CategoryRepository
+}
+
+// NewCategoryService creates a new instance of CategoryService
+func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryService {
+	return &categoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *categoryService) CreateCategory(ctx context.Context, category *entity.Category) error {
+	if err := normalizeCategory(category); err != nil {
+		return err
+	}
+	return s.categoryRepo.CreateCategory(ctx, category)
+}
+
+func (s *categoryService) GetAllCategories(ctx context.Context) ([]*entity.Category, error) {
+	return s.categoryRepo.GetAllCategories(ctx)
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	if err := normalizeCategory(category); err != nil {
+		return err
+	}
+	return s.categoryRepo.UpdateCategory(ctx, category)
+}
+
+func (s *categoryService) ReorderCategories(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return ErrInvalidCategoryOrder
+	}
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 || seen[id] {
+			return ErrInvalidCategoryOrder
+		}
+		seen[id] = true
+	}
+	return s.categoryRepo.ReorderCategories(ctx, ids)
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, id int64) error {
+	return s.categoryRepo.DeleteCategory(ctx, id)
+}
+
+func normalizeCategory(category *entity.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	category.Description = strings.TrimSpace(category.Description)
+	if category.Name == "" || utf8.RuneCountInString(category.Name) > maxCategoryNameLength {
+		return ErrInvalidCategoryName
+	}
+	return nil
+}
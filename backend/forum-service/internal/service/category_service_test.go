@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCategoryRepo struct {
+	mock.Mock
+}
+
+func (m *mockCategoryRepo) CreateCategory(ctx context.Context, category *entity.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *mockCategoryRepo) GetCategoryByID(ctx context.Context, id int64) (*entity.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Category), args.Error(1)
+}
+
+func (m *mockCategoryRepo) GetAllCategories(ctx context.Context) ([]*entity.Category, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Category), args.Error(1)
+}
+
+func (m *mockCategoryRepo) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *mockCategoryRepo) ReorderCategories(ctx context.Context, ids []int64) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *mockCategoryRepo) DeleteCategory(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCategoryService_CreateCategory(t *testing.T) {
+	mockCategoryRepo := new(mockCategoryRepo)
+	categoryService := NewCategoryService(mockCategoryRepo)
+
+	category := &entity.Category{Name: "  Новости  ", Description: " Анонсы "}
+	mockCategoryRepo.On("CreateCategory", mock.Anything, category).Return(nil)
+
+	err := categoryService.CreateCategory(context.Background(), category)
+	assert.NoError(t, err)
+	assert.Equal(t, "Новости", category.Name)
+	assert.Equal(t, "Анонсы", category.Description)
+	mockCategoryRepo.AssertExpectations(t)
+}
+
+func TestCategoryService_CreateCategory_InvalidName(t *testing.T) {
+	names := []string{"", "   ", "Очень длинное название категории, которое не влезает"}
+
+	for _, name := range names {
+		mockCategoryRepo := new(mockCategoryRepo)
+		categoryService := NewCategoryService(mockCategoryRepo)
+
+		err := categoryService.CreateCategory(context.Background(), &entity.Category{Name: name})
+		assert.ErrorIs(t, err, ErrInvalidCategoryName)
+		mockCategoryRepo.AssertNotCalled(t, "CreateCategory", mock.Anything, mock.Anything)
+	}
+}
+
+func TestCategoryService_ReorderCategories(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []int64
+		wantErr error
+	}{
+		{name: "valid", ids: []int64{3, 1, 2}},
+		{name: "empty", ids: nil, wantErr: ErrInvalidCategoryOrder},
+		{name: "duplicate", ids: []int64{1, 2, 1}, wantErr: ErrInvalidCategoryOrder},
+		{name: "invalid id", ids: []int64{1, 0}, wantErr: ErrInvalidCategoryOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCategoryRepo := new(mockCategoryRepo)
+			categoryService := NewCategoryService(mockCategoryRepo)
+			if tt.wantErr == nil {
+				mockCategoryRepo.On("ReorderCategories", mock.Anything, tt.ids).Return(nil)
+			}
+
+			err := categoryService.ReorderCategories(context.Background(), tt.ids)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockCategoryRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -40,16 +40,21 @@ func (s *commentService) DeleteComment(ctx context.Context, id int64) error {
 	if err != nil {
 		return err
 	}
-	if err := s.commentRepo.DeleteComment(ctx, id); err != nil {
+	if err := s.commentRepo.DeleteComment(ctx, id, 0); err != nil {
 		return err
 	}
 	return s.topicRepo.UpdateCommentCount(ctx, comment.TopicID)
 }
 
-func (s *commentService) LikeComment(ctx context.Context, id int64) error {
-	return s.commentRepo.LikeComment(ctx, id)
+func (s *commentService) LikeComment(ctx context.Context, id, userID int64) (int, error) {
+	likes, _, err := s.commentRepo.LikeComment(ctx, id, userID)
+	return likes, err
+}
+
+func (s *commentService) UnlikeComment(ctx context.Context, id, userID int64) (int, error) {
+	return s.commentRepo.UnlikeComment(ctx, id, userID)
 }
 
 func (s *commentService) UpdateComment(ctx context.Context, comment *entity.Comment) error {
-	return s.commentRepo.UpdateComment(ctx, comment)
+	return s.commentRepo.UpdateComment(ctx, comment, 0)
 }
@@ -35,18 +35,57 @@ func (m *mockCommentRepo) GetCommentsByTopic(ctx context.Context, topicID int64)
 	return args.Get(0).([]*entity.Comment), args.Error(1)
 }
 
-func (m *mockCommentRepo) DeleteComment(ctx context.Context, id int64) error {
-	args := m.Called(ctx, id)
+func (m *mockCommentRepo) DeleteComment(ctx context.Context, id, deletedBy int64) error {
+	args := m.Called(ctx, id, deletedBy)
 	return args.Error(0)
 }
 
-func (m *mockCommentRepo) LikeComment(ctx context.Context, id int64) error {
+func (m *mockCommentRepo) RestoreComment(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *mockCommentRepo) UpdateComment(ctx context.Context, comment *entity.Comment) error {
-	args := m.Called(ctx, comment)
+func (m *mockCommentRepo) PurgeDeletedComments(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockCommentRepo) LikeComment(ctx context.Context, commentID, userID int64) (int, bool, error) {
+	args := m.Called(ctx, commentID, userID)
+	return args.Int(0), args.Bool(1), args.Error(2)
+}
+
+func (m *mockCommentRepo) UnlikeComment(ctx context.Context, commentID, userID int64) (int, error) {
+	args := m.Called(ctx, commentID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockCommentRepo) GetCommentLikes(ctx context.Context, commentID int64, cursor string, limit int) (*entity.CommentLikePage, error) {
+	args := m.Called(ctx, commentID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CommentLikePage), args.Error(1)
+}
+
+func (m *mockCommentRepo) GetLikedCommentIDs(ctx context.Context, userID int64, commentIDs []int64) (map[int64]bool, error) {
+	args := m.Called(ctx, userID, commentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]bool), args.Error(1)
+}
+
+func (m *mockCommentRepo) GetCommentTree(ctx context.Context, query entity.CommentTreeQuery) (*entity.CommentTree, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CommentTree), args.Error(1)
+}
+
+func (m *mockCommentRepo) UpdateComment(ctx context.Context, comment *entity.Comment, editorID int64) error {
+	args := m.Called(ctx, comment, editorID)
 	return args.Error(0)
 }
 
@@ -67,29 +106,88 @@ func (m *mockTopicRepoForComment) GetTopicByID(ctx context.Context, id int64) (*
 	return args.Get(0).(*entity.Topic), args.Error(1)
 }
 
-func (m *mockTopicRepoForComment) GetAllTopics(ctx context.Context) ([]*entity.Topic, error) {
-	args := m.Called(ctx)
+func (m *mockTopicRepoForComment) GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*entity.Topic), args.Error(1)
+	return args.Get(0).(*entity.TopicPage), args.Error(1)
 }
 
-func (m *mockTopicRepoForComment) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
-	args := m.Called(ctx, topic)
+func (m *mockTopicRepoForComment) UpdateTopic(ctx context.Context, topic *entity.Topic, editorID int64) error {
+	args := m.Called(ctx, topic, editorID)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepoForComment) DeleteTopic(ctx context.Context, id, deletedBy int64) error {
+	args := m.Called(ctx, id, deletedBy)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepoForComment) RestoreTopic(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *mockTopicRepoForComment) DeleteTopic(ctx context.Context, id int64) error {
+func (m *mockTopicRepoForComment) SetPin(ctx context.Context, id int64, scope entity.PinScope, until *time.Time) error {
+	args := m.Called(ctx, id, scope, until)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepoForComment) SetLock(ctx context.Context, id int64, locked bool, until *time.Time) error {
+	args := m.Called(ctx, id, locked, until)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepoForComment) SetAnnouncement(ctx context.Context, id int64, announcement bool, until *time.Time) error {
+	args := m.Called(ctx, id, announcement, until)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepoForComment) GetScheduledTopic(ctx context.Context, id int64) (*entity.Topic, error) {
 	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Topic), args.Error(1)
+}
+
+func (m *mockTopicRepoForComment) GetScheduledTopics(ctx context.Context, authorID int64) ([]*entity.Topic, error) {
+	args := m.Called(ctx, authorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Topic), args.Error(1)
+}
+
+func (m *mockTopicRepoForComment) RescheduleTopic(ctx context.Context, id int64, publishAt time.Time) error {
+	args := m.Called(ctx, id, publishAt)
 	return args.Error(0)
 }
 
+func (m *mockTopicRepoForComment) PublishDueTopics(ctx context.Context, now time.Time, limit int) ([]*entity.Topic, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Topic), args.Error(1)
+}
+
+func (m *mockTopicRepoForComment) PurgeDeletedTopics(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *mockTopicRepoForComment) UpdateCommentCount(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *mockTopicRepoForComment) AddViews(ctx context.Context, views map[int64]int) error {
+	args := m.Called(ctx, views)
+	return args.Error(0)
+}
+
 func TestCommentService_CreateComment(t *testing.T) {
 	mockCommentRepo := new(mockCommentRepo)
 	mockTopicRepo := new(mockTopicRepoForComment)
@@ -206,7 +304,7 @@ func TestCommentService_DeleteComment(t *testing.T) {
 	}
 
 	mockCommentRepo.On("GetCommentByID", mock.Anything, int64(1)).Return(comment, nil)
-	mockCommentRepo.On("DeleteComment", mock.Anything, int64(1)).Return(nil)
+	mockCommentRepo.On("DeleteComment", mock.Anything, int64(1), int64(0)).Return(nil)
 	mockTopicRepo.On("UpdateCommentCount", mock.Anything, int64(1)).Return(nil)
 
 	err := service.DeleteComment(context.Background(), 1)
@@ -220,9 +318,10 @@ func TestCommentService_LikeComment(t *testing.T) {
 	mockTopicRepo := new(mockTopicRepoForComment)
 	service := NewCommentService(mockCommentRepo, mockTopicRepo)
 
-	mockCommentRepo.On("LikeComment", mock.Anything, int64(1)).Return(nil)
+	mockCommentRepo.On("LikeComment", mock.Anything, int64(1), int64(2)).Return(1, true, nil)
 
-	err := service.LikeComment(context.Background(), 1)
+	likes, err := service.LikeComment(context.Background(), 1, 2)
 	assert.NoError(t, err)
+	assert.Equal(t, 1, likes)
 	mockCommentRepo.AssertExpectations(t)
 }
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+// DefaultPurgeInterval is used when no positive purge interval is given
+const DefaultPurgeInterval = time.Hour
+
+// DeletedContentPurger permanently removes topics and comments that have
+// been deleted for longer than the retention period. Purging is a plain
+// DELETE by age, so several instances may run it at the same time.
+type DeletedContentPurger struct {
+	topicRepo   repository.TopicRepository
+	commentRepo repository.CommentRepository
+	retention   time.Duration
+	now         func() time.Time
+}
+
+// NewDeletedContentPurger creates a purger that keeps deleted content
+// restorable for the given retention period
+func NewDeletedContentPurger(topicRepo repository.TopicRepository, commentRepo repository.CommentRepository, retention time.Duration) *DeletedContentPurger {
+	return &DeletedContentPurger{
+		topicRepo:   topicRepo,
+		commentRepo: commentRepo,
+		retention:   retention,
+		now:         time.Now,
+	}
+}
+
+// Purge removes everything deleted before the retention period started and
+// returns the number of topics and comments removed
+func (p *DeletedContentPurger) Purge(ctx context.Context) (int64, int64, error) {
+	before := p.now().Add(-p.retention)
+
+	// Сначала темы: вместе с ними каскадно уходят и их комментарии
+	topics, err := p.topicRepo.PurgeDeletedTopics(ctx, before)
+	if err != nil {
+		return 0, 0, err
+	}
+	comments, err := p.commentRepo.PurgeDeletedComments(ctx, before)
+	if err != nil {
+		return topics, 0, err
+	}
+	return topics, comments, nil
+}
+
+// Run purges deleted content every interval until the context is cancelled
+func (p *DeletedContentPurger) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPurgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			topics, comments, err := p.Purge(ctx)
+			if err != nil {
+				log.Printf("Error purging deleted content: %v", err)
+				continue
+			}
+			if topics > 0 || comments > 0 {
+				log.Printf("Purged %d deleted topics and %d deleted comments", topics, comments)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
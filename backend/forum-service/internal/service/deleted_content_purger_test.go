@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeletedContentPurger_Purge(t *testing.T) {
+	topicRepo := new(mockTopicRepo)
+	commentRepo := new(mockCommentRepo)
+	purger := NewDeletedContentPurger(topicRepo, commentRepo, 30*24*time.Hour)
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	purger.now = func() time.Time { return now }
+
+	// Удаляется только то, что лежит в корзине дольше срока хранения
+	before := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	topicRepo.On("PurgeDeletedTopics", mock.Anything, before).Return(int64(2), nil).Once()
+	commentRepo.On("PurgeDeletedComments", mock.Anything, before).Return(int64(5), nil).Once()
+
+	topics, comments, err := purger.Purge(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), topics)
+	assert.Equal(t, int64(5), comments)
+	topicRepo.AssertExpectations(t)
+	commentRepo.AssertExpectations(t)
+}
+
+func TestDeletedContentPurger_Purge_Error(t *testing.T) {
+	topicRepo := new(mockTopicRepo)
+	commentRepo := new(mockCommentRepo)
+	purger := NewDeletedContentPurger(topicRepo, commentRepo, time.Hour)
+
+	topicRepo.On("PurgeDeletedTopics", mock.Anything, mock.Anything).Return(int64(0), errors.New("db down"))
+
+	_, _, err := purger.Purge(context.Background())
+	assert.Error(t, err)
+	commentRepo.AssertNotCalled(t, "PurgeDeletedComments", mock.Anything, mock.Anything)
+}
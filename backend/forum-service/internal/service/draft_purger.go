@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+// DraftPurger deletes drafts that expired. Expired drafts are already left
+// out of every listing, so purging only frees the space and several
+// instances may run it at the same time.
+type DraftPurger struct {
+	draftRepo repository.DraftRepository
+	now       func() time.Time
+}
+
+func NewDraftPurger(draftRepo repository.DraftRepository) *DraftPurger {
+	return &DraftPurger{
+		draftRepo: draftRepo,
+		now:       time.Now,
+	}
+}
+
+// Purge deletes the expired drafts and returns how many there were
+func (p *DraftPurger) Purge(ctx context.Context) (int64, error) {
+	return p.draftRepo.PurgeExpiredDrafts(ctx, p.now())
+}
+
+// Run purges expired drafts every interval until the context is cancelled
+func (p *DraftPurger) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPurgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n, err := p.Purge(ctx)
+			if err != nil {
+				log.Printf("Error purging expired drafts: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Purged %d expired drafts", n)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
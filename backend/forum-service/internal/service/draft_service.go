@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+// DefaultDraftTTL is used when no positive draft lifetime is given
+const DefaultDraftTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidDraft    = errors.New("invalid draft")
+	ErrDraftIncomplete = errors.New("draft is not ready to be published")
+)
+
+type draftService struct {
+	draftRepo    repository.DraftRepository
+	topicRepo    repository.TopicRepository
+	commentRepo  repository.CommentRepository
+	categoryRepo repository.CategoryRepository
+	userRepo     repository.UserRepository
+	notifier     Notifier
+	ttl          time.Duration
+	now          func() time.Time
+}
+
+// NewDraftService creates a new instance of DraftService. A draft expires
+// ttl after it was last saved. notifier is told about published topics
+// and comments the same way as about ones created directly; nil turns that
+// off.
+func NewDraftService(draftRepo repository.DraftRepository, topicRepo repository.TopicRepository, commentRepo repository.CommentRepository,
+	categoryRepo repository.CategoryRepository, userRepo repository.UserRepository,
+	notifier Notifier, ttl time.Duration) DraftService {
+	if notifier == nil {
+		notifier = NopNotifier
+	}
+	if ttl <= 0 {
+		ttl = DefaultDraftTTL
+	}
+	return &draftService{
+		draftRepo:    draftRepo,
+		topicRepo:    topicRepo,
+		commentRepo:  commentRepo,
+		categoryRepo: categoryRepo,
+		userRepo:     userRepo,
+		notifier:     notifier,
+		ttl:          ttl,
+		now:          time.Now,
+	}
+}
+
+// SaveDraft stores the draft of a user for its context and target. The
+// draft's Version is the version the text is based on, 0 for a new draft;
+// ErrDraftConflict is returned if the draft was saved elsewhere since.
+func (s *draftService) SaveDraft(ctx context.Context, draft *entity.Draft) error {
+	if err := validateDraft(draft); err != nil {
+		return err
+	}
+	if draft.Context != entity.DraftContextTopic {
+		// У ответа нет заголовка, раздела и тегов
+		draft.Title, draft.CategoryID, draft.TagNames = "", 0, nil
+	}
+
+	if draft.Version == 0 {
+		// Цель ответа проверяем один раз, при первом сохранении
+		if err := s.checkTarget(ctx, draft); err != nil {
+			return err
+		}
+		// Пользователь мог еще не попасть в локальную базу из auth-service
+		if _, err := s.userRepo.GetUserByID(ctx, draft.UserID); err != nil {
+			return err
+		}
+	}
+
+	draft.ExpiresAt = s.now().Add(s.ttl)
+	return s.draftRepo.SaveDraft(ctx, draft)
+}
+
+func (s *draftService) ListDrafts(ctx context.Context, filter entity.DraftFilter) (*entity.DraftPage, error) {
+	if filter.Context != "" && !filter.Context.Valid() {
+		return nil, fmt.Errorf("%w: unknown context %q", ErrInvalidDraft, filter.Context)
+	}
+	return s.draftRepo.GetDrafts(ctx, filter)
+}
+
+func (s *draftService) DeleteDraft(ctx context.Context, userID, id int64) error {
+	return s.draftRepo.DeleteDraft(ctx, userID, id)
+}
+
+// PublishDraft turns a user's draft into a topic or a comment and deletes
+// it. A non-zero version has to match the saved draft, so that text the
+// user hasn't seen isn't published.
+func (s *draftService) PublishDraft(ctx context.Context, userID, id int64, version int) (*entity.PublishedDraft, error) {
+	draft, err := s.draftRepo.GetDraft(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != draft.Version {
+		return nil, repository.ErrDraftConflict
+	}
+	if strings.TrimSpace(draft.Content) == "" {
+		return nil, fmt.Errorf("%w: content is empty", ErrDraftIncomplete)
+	}
+
+	var published *entity.PublishedDraft
+	if draft.Context == entity.DraftContextTopic {
+		published, err = s.publishTopic(ctx, draft)
+	} else {
+		published, err = s.publishComment(ctx, draft)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if published.Topic != nil {
+		published.Topic.Author = author
+	} else {
+		published.Comment.Author = author
+	}
+	return published, nil
+}
+
+func (s *draftService) publishTopic(ctx context.Context, draft *entity.Draft) (*entity.PublishedDraft, error) {
+	if strings.TrimSpace(draft.Title) == "" {
+		return nil, fmt.Errorf("%w: title is empty", ErrDraftIncomplete)
+	}
+	category, err := lookupCategory(ctx, s.categoryRepo, draft.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	tagNames, err := normalizeTagNames(draft.TagNames)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	topic := &entity.Topic{
+		Title:      draft.Title,
+		Content:    draft.Content,
+		AuthorID:   draft.UserID,
+		CategoryID: draft.CategoryID,
+		TagNames:   tagNames,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	// Теги сохраняются в одной транзакции с темой
+	if err := s.draftRepo.PublishTopic(ctx, draft, topic); err != nil {
+		return nil, err
+	}
+	topic.Category = category
+	topic.TagNames = nil
+	s.notifier.Mentioned(ctx, topicTarget(topic.ID), topic.AuthorID, topic.MentionedIDs)
+	return &entity.PublishedDraft{Topic: topic}, nil
+}
+
+func (s *draftService) publishComment(ctx context.Context, draft *entity.Draft) (*entity.PublishedDraft, error) {
+	comment := &entity.Comment{
+		Content:  draft.Content,
+		AuthorID: draft.UserID,
+		TopicID:  draft.TargetID,
+	}
+	if draft.Context == entity.DraftContextCommentReply {
+		parent, err := s.replyParent(ctx, draft.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		comment.TopicID = parent.TopicID
+		comment.ParentID = &parent.ID
+	}
+
+	if err := s.draftRepo.PublishComment(ctx, draft, comment); err != nil {
+		return nil, err
+	}
+	s.notifier.CommentCreated(ctx, comment)
+	return &entity.PublishedDraft{Comment: comment}, nil
+}
+
+// checkTarget makes sure the topic or comment a draft replies to exists
+func (s *draftService) checkTarget(ctx context.Context, draft *entity.Draft) error {
+	switch draft.Context {
+	case entity.DraftContextTopicReply:
+		_, err := s.topicRepo.GetTopicByID(ctx, draft.TargetID)
+		return err
+	case entity.DraftContextCommentReply:
+		_, err := s.replyParent(ctx, draft.TargetID)
+		return err
+	}
+	return nil
+}
+
+// replyParent returns the comment a reply is written to. Deleted comments
+// can't be replied to.
+func (s *draftService) replyParent(ctx context.Context, id int64) (*entity.Comment, error) {
+	parent, err := s.commentRepo.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Deleted {
+		return nil, repository.ErrCommentNotFound
+	}
+	return parent, nil
+}
+
+// validateDraft checks the context of a draft and the length of its text.
+// Drafts may be incomplete; completeness is checked on publishing.
+func validateDraft(draft *entity.Draft) error {
+	if !draft.Context.Valid() {
+		return fmt.Errorf("%w: unknown context %q", ErrInvalidDraft, draft.Context)
+	}
+	if draft.Context == entity.DraftContextTopic && draft.TargetID != 0 {
+		return fmt.Errorf("%w: a new topic has no target", ErrInvalidDraft)
+	}
+	if draft.Context != entity.DraftContextTopic && draft.TargetID <= 0 {
+		return fmt.Errorf("%w: a reply needs a target", ErrInvalidDraft)
+	}
+	if draft.Version < 0 {
+		return fmt.Errorf("%w: version must not be negative", ErrInvalidDraft)
+	}
+	if utf8.RuneCountInString(draft.Title) > entity.MaxDraftTitleLength {
+		return fmt.Errorf("%w: title must be at most %d characters", ErrInvalidDraft, entity.MaxDraftTitleLength)
+	}
+	if utf8.RuneCountInString(draft.Content) > entity.MaxDraftContentLength {
+		return fmt.Errorf("%w: content must be at most %d characters", ErrInvalidDraft, entity.MaxDraftContentLength)
+	}
+	return nil
+}
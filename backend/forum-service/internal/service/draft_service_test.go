@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDraftRepo struct {
+	mock.Mock
+}
+
+func (m *mockDraftRepo) SaveDraft(ctx context.Context, draft *entity.Draft) error {
+	args := m.Called(ctx, draft)
+	return args.Error(0)
+}
+
+func (m *mockDraftRepo) GetDraft(ctx context.Context, userID, id int64) (*entity.Draft, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Draft), args.Error(1)
+}
+
+func (m *mockDraftRepo) GetDrafts(ctx context.Context, filter entity.DraftFilter) (*entity.DraftPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.DraftPage), args.Error(1)
+}
+
+func (m *mockDraftRepo) DeleteDraft(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *mockDraftRepo) PublishTopic(ctx context.Context, draft *entity.Draft, topic *entity.Topic) error {
+	args := m.Called(ctx, draft, topic)
+	return args.Error(0)
+}
+
+func (m *mockDraftRepo) PublishComment(ctx context.Context, draft *entity.Draft, comment *entity.Comment) error {
+	args := m.Called(ctx, draft, comment)
+	return args.Error(0)
+}
+
+func (m *mockDraftRepo) PurgeExpiredDrafts(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type draftTestDeps struct {
+	draftRepo    *mockDraftRepo
+	topicRepo    *mockTopicRepo
+	commentRepo  *mockCommentRepo
+	categoryRepo *mockCategoryRepo
+	userRepo     *mockUserRepo
+	notifier     *mockNotifier
+}
+
+func newTestDraftService() (*draftService, draftTestDeps) {
+	d := draftTestDeps{
+		draftRepo:    new(mockDraftRepo),
+		topicRepo:    new(mockTopicRepo),
+		commentRepo:  new(mockCommentRepo),
+		categoryRepo: new(mockCategoryRepo),
+		userRepo:     new(mockUserRepo),
+		notifier:     new(mockNotifier),
+	}
+	s := NewDraftService(d.draftRepo, d.topicRepo, d.commentRepo, d.categoryRepo, d.userRepo, d.notifier, 7*24*time.Hour).(*draftService)
+	return s, d
+}
+
+func TestDraftService_SaveDraft(t *testing.T) {
+	s, d := newTestDraftService()
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	// Первое сохранение ответа проверяет тему и пользователя
+	d.topicRepo.On("GetTopicByID", mock.Anything, int64(4)).Return(&entity.Topic{ID: 4}, nil)
+	d.userRepo.On("GetUserByID", mock.Anything, int64(2)).Return(&entity.User{ID: 2}, nil)
+	d.draftRepo.On("SaveDraft", mock.Anything, mock.MatchedBy(func(draft *entity.Draft) bool {
+		return draft.Title == "" && draft.TagNames == nil && draft.ExpiresAt.Equal(now.Add(7*24*time.Hour))
+	})).Return(nil).Once()
+
+	draft := &entity.Draft{UserID: 2, Context: entity.DraftContextTopicReply, TargetID: 4, Title: "ignored", TagNames: []string{"go"}, Content: "reply"}
+	require.NoError(t, s.SaveDraft(context.Background(), draft))
+
+	// Последующие сохранения ничего не проверяют повторно
+	d.draftRepo.On("SaveDraft", mock.Anything, mock.Anything).Return(repository.ErrDraftConflict).Once()
+	draft.Version = 3
+	assert.ErrorIs(t, s.SaveDraft(context.Background(), draft), repository.ErrDraftConflict)
+	d.topicRepo.AssertNumberOfCalls(t, "GetTopicByID", 1)
+	d.userRepo.AssertNumberOfCalls(t, "GetUserByID", 1)
+}
+
+func TestDraftService_SaveDraft_Invalid(t *testing.T) {
+	s, d := newTestDraftService()
+
+	tests := []struct {
+		name  string
+		draft entity.Draft
+	}{
+		{"unknown context", entity.Draft{Context: "page"}},
+		{"topic with target", entity.Draft{Context: entity.DraftContextTopic, TargetID: 3}},
+		{"reply without target", entity.Draft{Context: entity.DraftContextCommentReply}},
+		{"title too long", entity.Draft{Context: entity.DraftContextTopic, Title: string(make([]rune, entity.MaxDraftTitleLength+1))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, s.SaveDraft(context.Background(), &tt.draft), ErrInvalidDraft)
+		})
+	}
+	d.draftRepo.AssertNotCalled(t, "SaveDraft", mock.Anything, mock.Anything)
+}
+
+func TestDraftService_PublishDraft_Topic(t *testing.T) {
+	s, d := newTestDraftService()
+
+	draft := &entity.Draft{ID: 7, UserID: 2, Context: entity.DraftContextTopic, Title: "Go", Content: "Text", CategoryID: 3, TagNames: []string{"Go"}, Version: 2}
+	d.draftRepo.On("GetDraft", mock.Anything, int64(2), int64(7)).Return(draft, nil)
+	d.categoryRepo.On("GetCategoryByID", mock.Anything, int64(3)).Return(&entity.Category{ID: 3, Name: "General"}, nil)
+	d.draftRepo.On("PublishTopic", mock.Anything, draft, mock.AnythingOfType("*entity.Topic")).
+		Run(func(args mock.Arguments) {
+			// Репозиторий получает нормализованные теги и сохраняет их вместе с темой
+			topic := args.Get(2).(*entity.Topic)
+			assert.Equal(t, []string{"go"}, topic.TagNames)
+			topic.ID = 15
+			topic.MentionedIDs = []int64{9}
+			topic.Tags = []*entity.Tag{{ID: 1, Name: "go"}}
+		}).Return(nil)
+	d.notifier.On("Mentioned", mock.Anything, topicTarget(15), int64(2), []int64{9}).Return()
+	d.userRepo.On("GetUserByID", mock.Anything, int64(2)).Return(&entity.User{ID: 2, Username: "alice"}, nil)
+
+	published, err := s.PublishDraft(context.Background(), 2, 7, 2)
+	require.NoError(t, err)
+	require.NotNil(t, published.Topic)
+	assert.Nil(t, published.Comment)
+	assert.Equal(t, "General", published.Topic.Category.Name)
+	assert.Equal(t, "alice", published.Topic.Author.Username)
+	assert.Len(t, published.Topic.Tags, 1)
+	assert.Nil(t, published.Topic.TagNames)
+	d.notifier.AssertExpectations(t)
+
+	// Опубликовать можно только ту версию, которую видел пользователь
+	_, err = s.PublishDraft(context.Background(), 2, 7, 1)
+	assert.ErrorIs(t, err, repository.ErrDraftConflict)
+}
+
+func TestDraftService_PublishDraft_CommentReply(t *testing.T) {
+	s, d := newTestDraftService()
+
+	draft := &entity.Draft{ID: 8, UserID: 2, Context: entity.DraftContextCommentReply, TargetID: 11, Content: "Agreed", Version: 1}
+	d.draftRepo.On("GetDraft", mock.Anything, int64(2), int64(8)).Return(draft, nil)
+	d.commentRepo.On("GetCommentByID", mock.Anything, int64(11)).Return(&entity.Comment{ID: 11, TopicID: 4}, nil)
+	d.draftRepo.On("PublishComment", mock.Anything, draft, mock.MatchedBy(func(c *entity.Comment) bool {
+		return c.TopicID == 4 && c.ParentID != nil && *c.ParentID == 11 && c.AuthorID == 2
+	})).Return(nil)
+	d.notifier.On("CommentCreated", mock.Anything, mock.AnythingOfType("*entity.Comment")).Return()
+	d.userRepo.On("GetUserByID", mock.Anything, int64(2)).Return(&entity.User{ID: 2, Username: "alice"}, nil)
+
+	published, err := s.PublishDraft(context.Background(), 2, 8, 0)
+	require.NoError(t, err)
+	require.NotNil(t, published.Comment)
+	assert.Equal(t, "alice", published.Comment.Author.Username)
+	d.notifier.AssertExpectations(t)
+}
+
+func TestDraftService_PublishDraft_Incomplete(t *testing.T) {
+	s, d := newTestDraftService()
+
+	d.draftRepo.On("GetDraft", mock.Anything, int64(2), int64(7)).
+		Return(&entity.Draft{ID: 7, UserID: 2, Context: entity.DraftContextTopic, Content: "No title yet", CategoryID: 3}, nil)
+	d.draftRepo.On("GetDraft", mock.Anything, int64(2), int64(8)).
+		Return(&entity.Draft{ID: 8, UserID: 2, Context: entity.DraftContextTopicReply, TargetID: 4, Content: "  "}, nil)
+
+	_, err := s.PublishDraft(context.Background(), 2, 7, 0)
+	assert.ErrorIs(t, err, ErrDraftIncomplete)
+	_, err = s.PublishDraft(context.Background(), 2, 8, 0)
+	assert.ErrorIs(t, err, ErrDraftIncomplete)
+	d.draftRepo.AssertNotCalled(t, "PublishTopic", mock.Anything, mock.Anything, mock.Anything)
+	d.draftRepo.AssertNotCalled(t, "PublishComment", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
 )
@@ -12,7 +13,8 @@ type CommentService interface {
 	GetCommentsByTopicID(ctx context.Context, topicID int64) ([]*entity.Comment, error)
 	UpdateComment(ctx context.Context, comment *entity.Comment) error
 	DeleteComment(ctx context.Context, id int64) error
-	LikeComment(ctx context.Context, id int64) error
+	LikeComment(ctx context.Context, id, userID int64) (int, error)
+	UnlikeComment(ctx context.Context, id, userID int64) (int, error)
 }
 
 type UserService interface {
@@ -25,3 +27,122 @@ type ChatService interface {
 	GetRecentMessages(ctx context.Context, limit int) ([]*entity.ChatMessage, error)
 	DeleteExpiredMessages(ctx context.Context) error
 }
+
+type SearchService interface {
+	Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error)
+}
+
+type CategoryService interface {
+	CreateCategory(ctx context.Context, category *entity.Category) error
+	GetAllCategories(ctx context.Context) ([]*entity.Category, error)
+	UpdateCategory(ctx context.Context, category *entity.Category) error
+	ReorderCategories(ctx context.Context, ids []int64) error
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+type TagService interface {
+	GetAllTags(ctx context.Context) ([]*entity.Tag, error)
+	RenameTag(ctx context.Context, id int64, name string) (*entity.Tag, error)
+	MergeTags(ctx context.Context, sourceID, targetID int64) error
+}
+
+type ReportService interface {
+	CreateReport(ctx context.Context, report *entity.Report) (bool, error)
+	ListReports(ctx context.Context, filter entity.ReportFilter) (*entity.ReportPage, error)
+	ClaimReport(ctx context.Context, id, moderatorID int64) (*entity.Report, error)
+	ResolveReport(ctx context.Context, id, moderatorID int64, action entity.ReportAction, note string) (*entity.Report, error)
+	DismissReport(ctx context.Context, id, moderatorID int64, note string) (*entity.Report, error)
+}
+
+type RevisionService interface {
+	ListRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64) ([]*entity.Revision, error)
+	DiffRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, from, to int) (*entity.RevisionDiff, error)
+	RollbackRevision(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, revision int, moderatorID int64) error
+}
+
+type MentionService interface {
+	SuggestUsers(ctx context.Context, prefix string, limit int) ([]*entity.User, error)
+	GetMentions(ctx context.Context, userID int64, cursor string, limit int) (*entity.MentionPage, error)
+}
+
+// Notifier is told about the events users are notified of. It reports no
+// errors: a lost notification must not fail the action that caused it. The
+// exception is a bookmark reminder, which is nothing but its notification
+// and is retried when it can't be saved.
+type Notifier interface {
+	CommentCreated(ctx context.Context, comment *entity.Comment)
+	CommentLiked(ctx context.Context, commentID, userID int64)
+	Mentioned(ctx context.Context, target entity.NotificationTarget, authorID int64, userIDs []int64)
+	Moderated(ctx context.Context, target entity.NotificationTarget, authorID, moderatorID int64, action, note string)
+	BookmarkReminder(ctx context.Context, bookmark *entity.Bookmark) error
+}
+
+// NotificationPublisher delivers notification events to the open
+// connections of a user
+type NotificationPublisher interface {
+	PublishNotification(userID int64, event *entity.NotificationEvent)
+}
+
+type NotificationService interface {
+	Notifier
+	ListNotifications(ctx context.Context, filter entity.NotificationFilter) (*entity.NotificationPage, error)
+	UnreadCount(ctx context.Context, userID int64) (int, error)
+	MarkRead(ctx context.Context, userID, id int64) (int, error)
+	MarkAllRead(ctx context.Context, userID int64) (int, error)
+	SetPublisher(publisher NotificationPublisher)
+}
+
+type SubscriptionService interface {
+	GetSubscription(ctx context.Context, userID, topicID int64) (*entity.TopicSubscription, error)
+	Subscribe(ctx context.Context, userID, topicID int64, level entity.WatchLevel) (*entity.TopicSubscription, error)
+	Unsubscribe(ctx context.Context, userID, topicID int64) error
+	ListWatchedTopics(ctx context.Context, filter entity.WatchedTopicFilter) (*entity.WatchedTopicPage, error)
+	MarkTopicRead(ctx context.Context, userID, topicID int64) error
+	GetWatchSettings(ctx context.Context, userID int64) (*entity.WatchSettings, error)
+	UpdateWatchSettings(ctx context.Context, userID int64, settings *entity.WatchSettings) error
+}
+
+type BookmarkService interface {
+	CreateBookmark(ctx context.Context, bookmark *entity.Bookmark) error
+	GetBookmark(ctx context.Context, userID, id int64) (*entity.Bookmark, error)
+	ListBookmarks(ctx context.Context, filter entity.BookmarkFilter) (*entity.BookmarkPage, error)
+	UpdateBookmark(ctx context.Context, bookmark *entity.Bookmark) error
+	DeleteBookmark(ctx context.Context, userID, id int64) error
+}
+
+type PollService interface {
+	CreatePoll(ctx context.Context, poll *entity.Poll, userID int64) error
+	GetPoll(ctx context.Context, topicID, viewerID int64) (*entity.Poll, error)
+	Vote(ctx context.Context, topicID, userID int64, optionIDs []int64) (*entity.Poll, error)
+}
+
+type AttachmentService interface {
+	MaxUploadSize() int64
+	Upload(ctx context.Context, userID int64, filename string, r io.Reader) (*entity.Attachment, error)
+	GetAttachment(ctx context.Context, id, viewerID int64) (*entity.Attachment, error)
+	GetTopicAttachments(ctx context.Context, topicID int64) ([]*entity.Attachment, error)
+	LinkAttachments(ctx context.Context, userID int64, postType entity.AttachmentPostType, postID int64, ids []int64) error
+	DeleteAttachment(ctx context.Context, id, userID int64) error
+	OpenAttachment(ctx context.Context, id int64, variant AttachmentVariant, expires int64, signature string) (*entity.Attachment, io.ReadCloser, error)
+}
+
+type AvatarService interface {
+	MaxUploadSize() int64
+	UploadAvatar(ctx context.Context, userID int64, r io.Reader) (*entity.User, error)
+	DeleteAvatar(ctx context.Context, userID int64) (*entity.User, error)
+	OpenAvatar(ctx context.Context, userID int64, size int) (*AvatarFile, error)
+}
+
+type ProfileService interface {
+	GetProfile(ctx context.Context, userID int64) (*entity.UserProfile, error)
+	UpdateProfile(ctx context.Context, userID int64, update entity.ProfileUpdate) (*entity.UserProfile, error)
+	GetUserTopics(ctx context.Context, filter entity.ActivityFilter) (*entity.TopicPage, error)
+	GetUserComments(ctx context.Context, filter entity.ActivityFilter) (*entity.UserCommentPage, error)
+}
+
+type DraftService interface {
+	SaveDraft(ctx context.Context, draft *entity.Draft) error
+	ListDrafts(ctx context.Context, filter entity.DraftFilter) (*entity.DraftPage, error)
+	DeleteDraft(ctx context.Context, userID, id int64) error
+	PublishDraft(ctx context.Context, userID, id int64, version int) (*entity.PublishedDraft, error)
+}
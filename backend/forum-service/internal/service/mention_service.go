@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/markup"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+var ErrInvalidMentionPrefix = errors.New("prefix must be 1 to 64 characters long")
+
+type mentionService struct {
+	mentionRepo repository.MentionRepository
+}
+
+// NewMentionService creates a new instance of MentionService
+func NewMentionService(mentionRepo repository.MentionRepository) MentionService {
+	return &mentionService{
+		mentionRepo: mentionRepo,
+	}
+}
+
+// SuggestUsers returns users for the mention picker whose name starts with
+// prefix. A leading @ is ignored.
+func (s *mentionService) SuggestUsers(ctx context.Context, prefix string, limit int) ([]*entity.User, error) {
+	prefix = strings.TrimPrefix(strings.TrimSpace(prefix), "@")
+	if prefix == "" || len(prefix) > markup.MaxUsernameLength {
+		return nil, ErrInvalidMentionPrefix
+	}
+	return s.mentionRepo.SuggestUsers(ctx, prefix, limit)
+}
+
+func (s *mentionService) GetMentions(ctx context.Context, userID int64, cursor string, limit int) (*entity.MentionPage, error) {
+	return s.mentionRepo.GetMentions(ctx, userID, cursor, limit)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockMentionRepo struct {
+	mock.Mock
+}
+
+func (m *mockMentionRepo) SuggestUsers(ctx context.Context, prefix string, limit int) ([]*entity.User, error) {
+	args := m.Called(ctx, prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.User), args.Error(1)
+}
+
+func (m *mockMentionRepo) GetMentions(ctx context.Context, userID int64, cursor string, limit int) (*entity.MentionPage, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.MentionPage), args.Error(1)
+}
+
+func TestMentionService_SuggestUsers(t *testing.T) {
+	repo := new(mockMentionRepo)
+	s := NewMentionService(repo)
+
+	repo.On("SuggestUsers", mock.Anything, "al", 5).Return([]*entity.User{{ID: 1, Username: "alice"}}, nil)
+
+	users, err := s.SuggestUsers(context.Background(), " @al", 5)
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	_, err = s.SuggestUsers(context.Background(), "@", 5)
+	assert.ErrorIs(t, err, ErrInvalidMentionPrefix)
+	repo.AssertExpectations(t)
+}
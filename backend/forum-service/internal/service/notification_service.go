@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+// NopNotifier drops every event. Constructors use it when they are given
+// no notifier.
+var NopNotifier Notifier = nopNotifier{}
+
+type nopNotifier struct{}
+
+func (nopNotifier) CommentCreated(context.Context, *entity.Comment) {}
+
+func (nopNotifier) CommentLiked(context.Context, int64, int64) {}
+
+func (nopNotifier) Mentioned(context.Context, entity.NotificationTarget, int64, []int64) {}
+
+func (nopNotifier) Moderated(context.Context, entity.NotificationTarget, int64, int64, string, string) {
+}
+
+func (nopNotifier) BookmarkReminder(context.Context, *entity.Bookmark) error { return nil }
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	commentRepo      repository.CommentRepository
+	subscriptionRepo repository.SubscriptionRepository
+	publisher        NotificationPublisher
+}
+
+// NewNotificationService creates a new instance of NotificationService. The
+// comment and subscription repositories are used to find whom an event
+// concerns.
+func NewNotificationService(notificationRepo repository.NotificationRepository, commentRepo repository.CommentRepository, subscriptionRepo repository.SubscriptionRepository) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		commentRepo:      commentRepo,
+		subscriptionRepo: subscriptionRepo,
+	}
+}
+
+// SetPublisher makes the service push new notifications and unread counts
+// through publisher. It must be called before the service is used.
+func (s *notificationService) SetPublisher(publisher NotificationPublisher) {
+	s.publisher = publisher
+}
+
+// CommentCreated notifies everyone the new comment mentions, the author of
+// the parent comment and everyone watching the topic. Each user gets one
+// notification; a mention beats a reply, a reply to a comment beats a new
+// comment in a watched topic. Users who muted the topic only hear about
+// mentions.
+func (s *notificationService) CommentCreated(ctx context.Context, comment *entity.Comment) {
+	notified := map[int64]bool{comment.AuthorID: true}
+	for _, userID := range comment.MentionedIDs {
+		notified[userID] = true
+	}
+	s.Mentioned(ctx, entity.NotificationTarget{
+		TargetType: entity.NotificationTargetComment,
+		TargetID:   comment.ID,
+		TopicID:    comment.TopicID,
+	}, comment.AuthorID, comment.MentionedIDs)
+
+	subscribers, err := s.subscriptionRepo.GetSubscribers(ctx, comment.TopicID)
+	if err != nil {
+		log.Printf("Error loading subscribers of topic %d for notifications: %v", comment.TopicID, err)
+	}
+
+	if comment.ParentID != nil {
+		parent, err := s.commentRepo.GetCommentByID(ctx, *comment.ParentID)
+		if err != nil {
+			log.Printf("Error loading parent of comment %d for notifications: %v", comment.ID, err)
+		} else if !notified[parent.AuthorID] && !parent.Deleted && subscribers[parent.AuthorID] != entity.WatchLevelMuted {
+			notified[parent.AuthorID] = true
+			s.notify(ctx, &entity.Notification{
+				UserID: parent.AuthorID,
+				Type:   entity.NotificationCommentReply,
+				NotificationTarget: entity.NotificationTarget{
+					TargetType: entity.NotificationTargetComment,
+					TargetID:   parent.ID,
+					TopicID:    parent.TopicID,
+				},
+				ActorID: comment.AuthorID,
+			})
+		}
+	}
+
+	var watchers []int64
+	for userID, level := range subscribers {
+		if level == entity.WatchLevelWatching && !notified[userID] {
+			watchers = append(watchers, userID)
+		}
+	}
+	sort.Slice(watchers, func(i, j int) bool { return watchers[i] < watchers[j] })
+	s.notifyAll(ctx, &entity.Notification{
+		Type: entity.NotificationTopicReply,
+		NotificationTarget: entity.NotificationTarget{
+			TargetType: entity.NotificationTargetTopic,
+			TargetID:   comment.TopicID,
+			TopicID:    comment.TopicID,
+		},
+		ActorID: comment.AuthorID,
+	}, watchers)
+}
+
+func (s *notificationService) CommentLiked(ctx context.Context, commentID, userID int64) {
+	comment, err := s.commentRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		log.Printf("Error loading comment %d for notifications: %v", commentID, err)
+		return
+	}
+	s.notify(ctx, &entity.Notification{
+		UserID: comment.AuthorID,
+		Type:   entity.NotificationLike,
+		NotificationTarget: entity.NotificationTarget{
+			TargetType: entity.NotificationTargetComment,
+			TargetID:   comment.ID,
+			TopicID:    comment.TopicID,
+		},
+		ActorID: userID,
+	})
+}
+
+// Mentioned notifies the users newly mentioned in target by authorID
+func (s *notificationService) Mentioned(ctx context.Context, target entity.NotificationTarget, authorID int64, userIDs []int64) {
+	for _, userID := range userIDs {
+		s.notify(ctx, &entity.Notification{
+			UserID:             userID,
+			Type:               entity.NotificationMention,
+			NotificationTarget: target,
+			ActorID:            authorID,
+		})
+	}
+}
+
+// Moderated tells the author of target that a moderator acted on it. The
+// moderator isn't named in the notification.
+func (s *notificationService) Moderated(ctx context.Context, target entity.NotificationTarget, authorID, moderatorID int64, action, note string) {
+	if authorID == moderatorID {
+		return
+	}
+	s.notify(ctx, &entity.Notification{
+		UserID:             authorID,
+		Type:               entity.NotificationModeration,
+		NotificationTarget: target,
+		Action:             action,
+		Note:               note,
+	})
+}
+
+// BookmarkReminder reminds a user of a bookmark with the note they left on it
+func (s *notificationService) BookmarkReminder(ctx context.Context, bookmark *entity.Bookmark) error {
+	return s.notify(ctx, &entity.Notification{
+		UserID: bookmark.UserID,
+		Type:   entity.NotificationReminder,
+		NotificationTarget: entity.NotificationTarget{
+			TargetType: entity.NotificationTargetType(bookmark.TargetType),
+			TargetID:   bookmark.TargetID,
+			TopicID:    bookmark.TopicID,
+		},
+		Note: bookmark.Note,
+	})
+}
+
+// notify saves a notification and pushes it to the user. Users aren't
+// notified of their own actions. The error is only of interest to callers
+// that retry; it has already been logged.
+func (s *notificationService) notify(ctx context.Context, notification *entity.Notification) error {
+	if notification.UserID == 0 || notification.UserID == notification.ActorID {
+		return nil
+	}
+	if err := s.notificationRepo.CreateNotification(ctx, notification); err != nil {
+		log.Printf("Error notifying user %d: %v", notification.UserID, err)
+		return err
+	}
+	s.publish(ctx, notification.UserID, notification)
+	return nil
+}
+
+// notifyAll sends a copy of notification to each of userIDs with one query
+// and pushes every copy to its user
+func (s *notificationService) notifyAll(ctx context.Context, notification *entity.Notification, userIDs []int64) {
+	recipients := make([]int64, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != 0 && userID != notification.ActorID {
+			recipients = append(recipients, userID)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+	notifications, err := s.notificationRepo.CreateNotifications(ctx, notification, recipients)
+	if err != nil {
+		log.Printf("Error notifying %d users: %v", len(recipients), err)
+		return
+	}
+	if s.publisher == nil {
+		return
+	}
+	unread, err := s.notificationRepo.CountUnreadByUsers(ctx, recipients)
+	if err != nil {
+		log.Printf("Error counting unread notifications of %d users: %v", len(recipients), err)
+		return
+	}
+	for _, saved := range notifications {
+		s.publisher.PublishNotification(saved.UserID, &entity.NotificationEvent{Notification: saved, Unread: unread[saved.UserID]})
+	}
+}
+
+func (s *notificationService) publish(ctx context.Context, userID int64, notification *entity.Notification) {
+	if s.publisher == nil {
+		return
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		log.Printf("Error counting unread notifications of user %d: %v", userID, err)
+		return
+	}
+	s.publisher.PublishNotification(userID, &entity.NotificationEvent{Notification: notification, Unread: unread})
+}
+
+func (s *notificationService) ListNotifications(ctx context.Context, filter entity.NotificationFilter) (*entity.NotificationPage, error) {
+	page, err := s.notificationRepo.GetNotifications(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if page.Unread, err = s.notificationRepo.CountUnread(ctx, filter.UserID); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (s *notificationService) UnreadCount(ctx context.Context, userID int64) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks a notification of userID as read and returns how many are
+// left unread. The user's other connections get the new count.
+func (s *notificationService) MarkRead(ctx context.Context, userID, id int64) (int, error) {
+	if err := s.notificationRepo.MarkRead(ctx, userID, id); err != nil {
+		return 0, err
+	}
+	return s.unreadChanged(ctx, userID)
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int64) (int, error) {
+	if _, err := s.notificationRepo.MarkAllRead(ctx, userID); err != nil {
+		return 0, err
+	}
+	return s.unreadChanged(ctx, userID)
+}
+
+func (s *notificationService) unreadChanged(ctx context.Context, userID int64) (int, error) {
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if s.publisher != nil {
+		s.publisher.PublishNotification(userID, &entity.NotificationEvent{Unread: unread})
+	}
+	return unread, nil
+}
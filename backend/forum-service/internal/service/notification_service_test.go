@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockNotificationRepo struct {
+	mock.Mock
+}
+
+func (m *mockNotificationRepo) CreateNotification(ctx context.Context, notification *entity.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *mockNotificationRepo) GetNotifications(ctx context.Context, filter entity.NotificationFilter) (*entity.NotificationPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationPage), args.Error(1)
+}
+
+func (m *mockNotificationRepo) CreateNotifications(ctx context.Context, notification *entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+	args := m.Called(ctx, notification, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Notification), args.Error(1)
+}
+
+func (m *mockNotificationRepo) CountUnreadByUsers(ctx context.Context, userIDs []int64) (map[int64]int, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]int), args.Error(1)
+}
+
+func (m *mockNotificationRepo) CountUnread(ctx context.Context, userID int64) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockNotificationRepo) MarkRead(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *mockNotificationRepo) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) CommentCreated(ctx context.Context, comment *entity.Comment) {
+	m.Called(ctx, comment)
+}
+
+func (m *mockNotifier) CommentLiked(ctx context.Context, commentID, userID int64) {
+	m.Called(ctx, commentID, userID)
+}
+
+func (m *mockNotifier) Mentioned(ctx context.Context, target entity.NotificationTarget, authorID int64, userIDs []int64) {
+	m.Called(ctx, target, authorID, userIDs)
+}
+
+func (m *mockNotifier) Moderated(ctx context.Context, target entity.NotificationTarget, authorID, moderatorID int64, action, note string) {
+	m.Called(ctx, target, authorID, moderatorID, action, note)
+}
+
+func (m *mockNotifier) BookmarkReminder(ctx context.Context, bookmark *entity.Bookmark) error {
+	args := m.Called(ctx, bookmark)
+	return args.Error(0)
+}
+
+type recordingPublisher struct {
+	events map[int64][]*entity.NotificationEvent
+}
+
+func (p *recordingPublisher) PublishNotification(userID int64, event *entity.NotificationEvent) {
+	if p.events == nil {
+		p.events = map[int64][]*entity.NotificationEvent{}
+	}
+	p.events[userID] = append(p.events[userID], event)
+}
+
+func notifiedUser(userID int64, notificationType entity.NotificationType) interface{} {
+	return mock.MatchedBy(func(n *entity.Notification) bool {
+		return n.UserID == userID && n.Type == notificationType
+	})
+}
+
+func TestNotificationService_CommentCreated(t *testing.T) {
+	notificationRepo := new(mockNotificationRepo)
+	commentRepo := new(mockCommentRepo)
+	subscriptionRepo := new(mockSubscriptionRepo)
+	publisher := &recordingPublisher{}
+	s := NewNotificationService(notificationRepo, commentRepo, subscriptionRepo)
+	s.SetPublisher(publisher)
+
+	parentID := int64(20)
+	comment := &entity.Comment{ID: 21, TopicID: 4, AuthorID: 7, ParentID: &parentID, MentionedIDs: []int64{3}}
+
+	// Автор темы упомянут, а автор родителя получает ответ, поэтому о новом
+	// комментарии в теме узнают только остальные наблюдающие
+	subscriptionRepo.On("GetSubscribers", mock.Anything, int64(4)).Return(map[int64]entity.WatchLevel{
+		2: entity.WatchLevelWatching,
+		3: entity.WatchLevelWatching,
+		5: entity.WatchLevelTracking,
+		6: entity.WatchLevelWatching,
+		7: entity.WatchLevelWatching,
+		8: entity.WatchLevelWatching,
+	}, nil)
+	commentRepo.On("GetCommentByID", mock.Anything, parentID).Return(&entity.Comment{ID: parentID, TopicID: 4, AuthorID: 2}, nil)
+	notificationRepo.On("CreateNotification", mock.Anything, notifiedUser(3, entity.NotificationMention)).Return(nil).Once()
+	notificationRepo.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n *entity.Notification) bool {
+		return n.UserID == 2 && n.Type == entity.NotificationCommentReply && n.TargetID == parentID && n.ActorID == 7
+	})).Return(nil).Once()
+	notificationRepo.On("CountUnread", mock.Anything, int64(3)).Return(1, nil)
+	notificationRepo.On("CountUnread", mock.Anything, int64(2)).Return(4, nil)
+	notificationRepo.On("CreateNotifications", mock.Anything, mock.MatchedBy(func(n *entity.Notification) bool {
+		return n.Type == entity.NotificationTopicReply && n.TargetID == 4 && n.ActorID == 7
+	}), []int64{6, 8}).Return([]*entity.Notification{
+		{ID: 30, UserID: 6, Type: entity.NotificationTopicReply},
+		{ID: 31, UserID: 8, Type: entity.NotificationTopicReply},
+	}, nil).Once()
+	notificationRepo.On("CountUnreadByUsers", mock.Anything, []int64{6, 8}).Return(map[int64]int{6: 2, 8: 1}, nil)
+
+	s.CommentCreated(context.Background(), comment)
+
+	notificationRepo.AssertExpectations(t)
+	assert.Len(t, publisher.events[3], 1)
+	assert.Equal(t, 4, publisher.events[2][0].Unread)
+	assert.Equal(t, entity.NotificationCommentReply, publisher.events[2][0].Notification.Type)
+	assert.Equal(t, 2, publisher.events[6][0].Unread)
+	assert.Equal(t, int64(31), publisher.events[8][0].Notification.ID)
+	assert.Empty(t, publisher.events[5])
+}
+
+func TestNotificationService_CommentCreated_Muted(t *testing.T) {
+	notificationRepo := new(mockNotificationRepo)
+	commentRepo := new(mockCommentRepo)
+	subscriptionRepo := new(mockSubscriptionRepo)
+	s := NewNotificationService(notificationRepo, commentRepo, subscriptionRepo)
+
+	parentID := int64(20)
+	// Заглушившему тему не приходят ни ответы, ни новые комментарии, а
+	// свой комментарий в своей теме уведомлений не создает
+	subscriptionRepo.On("GetSubscribers", mock.Anything, int64(4)).Return(map[int64]entity.WatchLevel{
+		2: entity.WatchLevelMuted,
+		7: entity.WatchLevelWatching,
+	}, nil)
+	commentRepo.On("GetCommentByID", mock.Anything, parentID).Return(&entity.Comment{ID: parentID, TopicID: 4, AuthorID: 2}, nil)
+
+	s.CommentCreated(context.Background(), &entity.Comment{ID: 21, TopicID: 4, AuthorID: 7, ParentID: &parentID})
+	notificationRepo.AssertNotCalled(t, "CreateNotification", mock.Anything, mock.Anything)
+	notificationRepo.AssertNotCalled(t, "CreateNotifications", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationService_Moderated(t *testing.T) {
+	notificationRepo := new(mockNotificationRepo)
+	s := NewNotificationService(notificationRepo, new(mockCommentRepo), new(mockSubscriptionRepo))
+
+	target := entity.NotificationTarget{TargetType: entity.NotificationTargetComment, TargetID: 10}
+	notificationRepo.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n *entity.Notification) bool {
+		return n.UserID == 2 && n.Action == entity.ModerationHide && n.Note == "ads" && n.ActorID == 0
+	})).Return(nil).Once()
+
+	s.Moderated(context.Background(), target, 2, 5, entity.ModerationHide, "ads")
+	// Модератор, удаливший свой комментарий, уведомления не получает
+	s.Moderated(context.Background(), target, 5, 5, entity.ModerationDelete, "")
+	notificationRepo.AssertExpectations(t)
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	notificationRepo := new(mockNotificationRepo)
+	publisher := &recordingPublisher{}
+	s := NewNotificationService(notificationRepo, new(mockCommentRepo), new(mockSubscriptionRepo))
+	s.SetPublisher(publisher)
+
+	notificationRepo.On("MarkRead", mock.Anything, int64(3), int64(1)).Return(nil)
+	notificationRepo.On("MarkRead", mock.Anything, int64(3), int64(2)).Return(repository.ErrNotificationNotFound)
+	notificationRepo.On("CountUnread", mock.Anything, int64(3)).Return(2, nil)
+
+	unread, err := s.MarkRead(context.Background(), 3, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, unread)
+	// Остальные вкладки пользователя получают новое число
+	assert.Equal(t, []*entity.NotificationEvent{{Unread: 2}}, publisher.events[3])
+
+	_, err = s.MarkRead(context.Background(), 3, 2)
+	assert.ErrorIs(t, err, repository.ErrNotificationNotFound)
+	notificationRepo.AssertExpectations(t)
+}
+
+func TestNotificationService_BookmarkReminder(t *testing.T) {
+	notificationRepo := new(mockNotificationRepo)
+	s := NewNotificationService(notificationRepo, new(mockCommentRepo), new(mockSubscriptionRepo))
+
+	notificationRepo.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n *entity.Notification) bool {
+		return n.UserID == 2 && n.Type == entity.NotificationReminder && n.TargetType == entity.NotificationTargetComment &&
+			n.TargetID == 10 && n.TopicID == 4 && n.Note == "read later" && n.ActorID == 0
+	})).Return(nil).Once()
+
+	err := s.BookmarkReminder(context.Background(), &entity.Bookmark{
+		UserID: 2, TargetType: entity.BookmarkTargetComment, TargetID: 10, TopicID: 4, Note: "read later",
+	})
+	assert.NoError(t, err)
+
+	// Ошибку сохранения видит напоминалка, чтобы повторить попытку
+	notificationRepo.On("CreateNotification", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+	err = s.BookmarkReminder(context.Background(), &entity.Bookmark{UserID: 2, TargetType: entity.BookmarkTargetTopic, TargetID: 4})
+	assert.Error(t, err)
+	notificationRepo.AssertExpectations(t)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+var (
+	ErrInvalidPollQuestion = errors.New("poll question must be between 1 and 300 characters")
+	ErrInvalidPollOptions  = errors.New("poll must have between 2 and 20 distinct options of up to 200 characters")
+	ErrPollCloseInPast     = errors.New("poll close time must be in the future")
+	ErrPollClosed          = errors.New("poll is closed")
+	ErrInvalidPollVote     = errors.New("vote must choose one option, or at least one in a multiple-choice poll")
+)
+
+type pollService struct {
+	pollRepo  repository.PollRepository
+	topicRepo repository.TopicRepository
+	policy    *policy.Policy
+	now       func() time.Time
+}
+
+// NewPollService creates a new instance of PollService
+func NewPollService(pollRepo repository.PollRepository, topicRepo repository.TopicRepository, userRepo repository.UserRepository) PollService {
+	return &pollService{
+		pollRepo:  pollRepo,
+		topicRepo: topicRepo,
+		policy:    policy.New(userRepo),
+		now:       time.Now,
+	}
+}
+
+// CreatePoll attaches a poll to a topic on behalf of userID, who must be
+// allowed to edit the topic
+func (s *pollService) CreatePoll(ctx context.Context, poll *entity.Poll, userID int64) error {
+	if err := s.validate(poll); err != nil {
+		return err
+	}
+	topic, err := s.topicRepo.GetTopicByID(ctx, poll.TopicID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.policy.Authorize(ctx, userID, policy.ResourceTopic, policy.ActionUpdate, topic.AuthorID); err != nil {
+		return err
+	}
+	if err := s.pollRepo.CreatePoll(ctx, poll); err != nil {
+		return err
+	}
+	poll.ResultsVisible = !poll.HideResults
+	poll.MyVotes = []int64{}
+	return nil
+}
+
+// GetPoll returns the poll of a topic as viewerID sees it; zero stands for
+// a guest. Hidden results come back as zero counts.
+func (s *pollService) GetPoll(ctx context.Context, topicID, viewerID int64) (*entity.Poll, error) {
+	poll, err := s.pollRepo.GetPollByTopic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	poll.Closed = poll.IsClosed(s.now())
+	poll.MyVotes = []int64{}
+	if viewerID > 0 {
+		if poll.MyVotes, err = s.pollRepo.GetUserVotes(ctx, poll.ID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	poll.ResultsVisible = !poll.HideResults || poll.Closed || len(poll.MyVotes) > 0
+	if !poll.ResultsVisible {
+		poll.TotalVoters = 0
+		for _, option := range poll.Options {
+			option.Votes = 0
+		}
+		return poll, nil
+	}
+	if poll.Anonymous || poll.TotalVoters == 0 {
+		return poll, nil
+	}
+	voters, err := s.pollRepo.GetVoters(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range poll.Options {
+		option.Voters = voters[option.ID]
+	}
+	return poll, nil
+}
+
+// Vote records userID's choice in the poll of a topic, replacing the
+// previous one, and returns the poll with the results
+func (s *pollService) Vote(ctx context.Context, topicID, userID int64, optionIDs []int64) (*entity.Poll, error) {
+	poll, err := s.pollRepo.GetPollByTopic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.IsClosed(s.now()) {
+		return nil, ErrPollClosed
+	}
+
+	seen := make(map[int64]bool, len(optionIDs))
+	choice := make([]int64, 0, len(optionIDs))
+	for _, id := range optionIDs {
+		if !seen[id] {
+			seen[id] = true
+			choice = append(choice, id)
+		}
+	}
+	if len(choice) == 0 || (!poll.Multiple && len(choice) > 1) {
+		return nil, ErrInvalidPollVote
+	}
+	if err := s.pollRepo.SetVotes(ctx, poll.ID, userID, choice); err != nil {
+		return nil, err
+	}
+	return s.GetPoll(ctx, topicID, userID)
+}
+
+// validate normalizes the question and the options of a new poll and
+// checks their limits
+func (s *pollService) validate(poll *entity.Poll) error {
+	poll.Question = strings.TrimSpace(poll.Question)
+	if poll.Question == "" || utf8.RuneCountInString(poll.Question) > entity.MaxPollQuestionLength {
+		return ErrInvalidPollQuestion
+	}
+	if len(poll.Options) < entity.MinPollOptions || len(poll.Options) > entity.MaxPollOptions {
+		return ErrInvalidPollOptions
+	}
+	seen := make(map[string]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		option.Text = strings.TrimSpace(option.Text)
+		key := strings.ToLower(option.Text)
+		if option.Text == "" || utf8.RuneCountInString(option.Text) > entity.MaxPollOptionLength || seen[key] {
+			return ErrInvalidPollOptions
+		}
+		seen[key] = true
+	}
+	if poll.ClosesAt != nil && !poll.ClosesAt.After(s.now()) {
+		return ErrPollCloseInPast
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPollRepo struct {
+	mock.Mock
+}
+
+func (m *mockPollRepo) CreatePoll(ctx context.Context, poll *entity.Poll) error {
+	args := m.Called(ctx, poll)
+	return args.Error(0)
+}
+
+func (m *mockPollRepo) GetPollByTopic(ctx context.Context, topicID int64) (*entity.Poll, error) {
+	args := m.Called(ctx, topicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Poll), args.Error(1)
+}
+
+func (m *mockPollRepo) GetUserVotes(ctx context.Context, pollID, userID int64) ([]int64, error) {
+	args := m.Called(ctx, pollID, userID)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *mockPollRepo) SetVotes(ctx context.Context, pollID, userID int64, optionIDs []int64) error {
+	args := m.Called(ctx, pollID, userID, optionIDs)
+	return args.Error(0)
+}
+
+func (m *mockPollRepo) GetVoters(ctx context.Context, pollID int64) (map[int64][]*entity.User, error) {
+	args := m.Called(ctx, pollID)
+	return args.Get(0).(map[int64][]*entity.User), args.Error(1)
+}
+
+var pollTestNow = time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+func newTestPollService() (*pollService, *mockPollRepo, *mockTopicRepo, *mockUserRepo) {
+	pollRepo := new(mockPollRepo)
+	topicRepo := new(mockTopicRepo)
+	userRepo := new(mockUserRepo)
+	s := NewPollService(pollRepo, topicRepo, userRepo).(*pollService)
+	s.now = func() time.Time { return pollTestNow }
+	return s, pollRepo, topicRepo, userRepo
+}
+
+// testPoll returns a poll with two options and votes for both
+func testPoll() *entity.Poll {
+	return &entity.Poll{
+		ID:          1,
+		TopicID:     4,
+		Question:    "Which day?",
+		TotalVoters: 3,
+		Options: []*entity.PollOption{
+			{ID: 10, Text: "Monday", Position: 1, Votes: 1},
+			{ID: 11, Text: "Friday", Position: 2, Votes: 2},
+		},
+	}
+}
+
+func TestPollService_CreatePoll(t *testing.T) {
+	s, pollRepo, topicRepo, userRepo := newTestPollService()
+	topicRepo.On("GetTopicByID", mock.Anything, int64(4)).Return(&entity.Topic{ID: 4, AuthorID: 2}, nil)
+	userRepo.On("GetUserRole", mock.Anything, int64(2)).Return(entity.RoleUser, nil)
+	userRepo.On("GetUserRole", mock.Anything, int64(3)).Return(entity.RoleUser, nil)
+	pollRepo.On("CreatePoll", mock.Anything, mock.AnythingOfType("*entity.Poll")).Return(nil)
+
+	poll := &entity.Poll{
+		TopicID:     4,
+		Question:    "  Which day?  ",
+		HideResults: true,
+		Options:     []*entity.PollOption{{Text: "Monday "}, {Text: "Friday"}},
+	}
+	assert.NoError(t, s.CreatePoll(context.Background(), poll, 2))
+	assert.Equal(t, "Which day?", poll.Question)
+	assert.Equal(t, "Monday", poll.Options[0].Text)
+	assert.False(t, poll.ResultsVisible)
+
+	// Опрос может добавить только тот, кто может править тему
+	err := s.CreatePoll(context.Background(), &entity.Poll{
+		TopicID:  4,
+		Question: "Which day?",
+		Options:  []*entity.PollOption{{Text: "Monday"}, {Text: "Friday"}},
+	}, 3)
+	assert.ErrorIs(t, err, policy.ErrForbidden)
+	pollRepo.AssertNumberOfCalls(t, "CreatePoll", 1)
+}
+
+func TestPollService_CreatePoll_Invalid(t *testing.T) {
+	past := pollTestNow.Add(-time.Minute)
+	options := func(texts ...string) []*entity.PollOption {
+		var result []*entity.PollOption
+		for _, text := range texts {
+			result = append(result, &entity.PollOption{Text: text})
+		}
+		return result
+	}
+	many := make([]string, entity.MaxPollOptions+1)
+	for i := range many {
+		many[i] = strings.Repeat("o", i+1)
+	}
+
+	tests := []struct {
+		name    string
+		poll    *entity.Poll
+		wantErr error
+	}{
+		{"empty question", &entity.Poll{Question: " ", Options: options("a", "b")}, ErrInvalidPollQuestion},
+		{"long question", &entity.Poll{Question: strings.Repeat("q", entity.MaxPollQuestionLength+1), Options: options("a", "b")}, ErrInvalidPollQuestion},
+		{"one option", &entity.Poll{Question: "Q", Options: options("a")}, ErrInvalidPollOptions},
+		{"too many options", &entity.Poll{Question: "Q", Options: options(many...)}, ErrInvalidPollOptions},
+		{"empty option", &entity.Poll{Question: "Q", Options: options("a", "  ")}, ErrInvalidPollOptions},
+		{"duplicate options", &entity.Poll{Question: "Q", Options: options("Yes", "yes")}, ErrInvalidPollOptions},
+		{"closed already", &entity.Poll{Question: "Q", Options: options("a", "b"), ClosesAt: &past}, ErrPollCloseInPast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, pollRepo, topicRepo, _ := newTestPollService()
+			tt.poll.TopicID = 4
+			assert.ErrorIs(t, s.CreatePoll(context.Background(), tt.poll, 2), tt.wantErr)
+			topicRepo.AssertNotCalled(t, "GetTopicByID", mock.Anything, mock.Anything)
+			pollRepo.AssertNotCalled(t, "CreatePoll", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPollService_GetPoll_HiddenResults(t *testing.T) {
+	s, pollRepo, _, _ := newTestPollService()
+	poll := testPoll()
+	poll.HideResults = true
+	pollRepo.On("GetPollByTopic", mock.Anything, int64(4)).Return(poll, nil)
+	pollRepo.On("GetUserVotes", mock.Anything, int64(1), int64(5)).Return([]int64{}, nil)
+
+	// Пока пользователь не проголосовал, результаты скрыты
+	result, err := s.GetPoll(context.Background(), 4, 5)
+	assert.NoError(t, err)
+	assert.False(t, result.ResultsVisible)
+	assert.Zero(t, result.TotalVoters)
+	assert.Zero(t, result.Options[1].Votes)
+	assert.Empty(t, result.MyVotes)
+	pollRepo.AssertNotCalled(t, "GetVoters", mock.Anything, mock.Anything)
+}
+
+func TestPollService_GetPoll_Visible(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepare    func(*entity.Poll)
+		viewerID   int64
+		myVotes    []int64
+		wantVoters bool
+	}{
+		{"voted", func(p *entity.Poll) { p.HideResults = true }, 5, []int64{11}, true},
+		{"closed", func(p *entity.Poll) {
+			p.HideResults = true
+			closesAt := pollTestNow.Add(-time.Hour)
+			p.ClosesAt = &closesAt
+		}, 0, nil, true},
+		{"anonymous", func(p *entity.Poll) { p.Anonymous = true }, 5, []int64{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, pollRepo, _, _ := newTestPollService()
+			poll := testPoll()
+			tt.prepare(poll)
+			pollRepo.On("GetPollByTopic", mock.Anything, int64(4)).Return(poll, nil)
+			if tt.viewerID > 0 {
+				pollRepo.On("GetUserVotes", mock.Anything, int64(1), tt.viewerID).Return(tt.myVotes, nil)
+			}
+			pollRepo.On("GetVoters", mock.Anything, int64(1)).Return(map[int64][]*entity.User{
+				11: {{ID: 5, Username: "alice"}},
+			}, nil)
+
+			result, err := s.GetPoll(context.Background(), 4, tt.viewerID)
+			assert.NoError(t, err)
+			assert.True(t, result.ResultsVisible)
+			assert.Equal(t, 3, result.TotalVoters)
+			assert.Equal(t, 2, result.Options[1].Votes)
+			if tt.wantVoters {
+				assert.Equal(t, "alice", result.Options[1].Voters[0].Username)
+			} else {
+				assert.Nil(t, result.Options[1].Voters)
+				pollRepo.AssertNotCalled(t, "GetVoters", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPollService_Vote(t *testing.T) {
+	s, pollRepo, _, _ := newTestPollService()
+	poll := testPoll()
+	pollRepo.On("GetPollByTopic", mock.Anything, int64(4)).Return(poll, nil)
+	pollRepo.On("SetVotes", mock.Anything, int64(1), int64(5), []int64{11}).Return(nil)
+	pollRepo.On("GetUserVotes", mock.Anything, int64(1), int64(5)).Return([]int64{11}, nil)
+	pollRepo.On("GetVoters", mock.Anything, int64(1)).Return(map[int64][]*entity.User{}, nil)
+
+	// Повторы в запросе схлопываются
+	result, err := s.Vote(context.Background(), 4, 5, []int64{11, 11})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{11}, result.MyVotes)
+
+	// В опросе с одним ответом можно выбрать только один вариант
+	_, err = s.Vote(context.Background(), 4, 5, []int64{10, 11})
+	assert.ErrorIs(t, err, ErrInvalidPollVote)
+	_, err = s.Vote(context.Background(), 4, 5, nil)
+	assert.ErrorIs(t, err, ErrInvalidPollVote)
+	pollRepo.AssertNumberOfCalls(t, "SetVotes", 1)
+}
+
+func TestPollService_Vote_Closed(t *testing.T) {
+	s, pollRepo, _, _ := newTestPollService()
+	poll := testPoll()
+	poll.ClosesAt = &pollTestNow
+	pollRepo.On("GetPollByTopic", mock.Anything, int64(4)).Return(poll, nil)
+	pollRepo.On("GetPollByTopic", mock.Anything, int64(5)).Return(nil, repository.ErrPollNotFound)
+
+	_, err := s.Vote(context.Background(), 4, 5, []int64{10})
+	assert.ErrorIs(t, err, ErrPollClosed)
+	_, err = s.Vote(context.Background(), 5, 5, []int64{10})
+	assert.ErrorIs(t, err, repository.ErrPollNotFound)
+	pollRepo.AssertNotCalled(t, "SetVotes", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
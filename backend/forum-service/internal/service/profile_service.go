@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+var ErrInvalidProfile = errors.New("invalid profile")
+
+type profileService struct {
+	profileRepo repository.ProfileRepository
+	userRepo    repository.UserRepository
+}
+
+// NewProfileService creates a new instance of ProfileService
+func NewProfileService(profileRepo repository.ProfileRepository, userRepo repository.UserRepository) ProfileService {
+	return &profileService{
+		profileRepo: profileRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// GetProfile returns the public profile of a user. A user that hasn't been
+// synced from auth-service yet is looked up there first.
+func (s *profileService) GetProfile(ctx context.Context, userID int64) (*entity.UserProfile, error) {
+	profile, err := s.profileRepo.GetProfile(ctx, userID)
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return profile, err
+	}
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.profileRepo.GetProfile(ctx, userID)
+}
+
+// UpdateProfile changes the bio, signature or location of a user and
+// returns the updated profile
+func (s *profileService) UpdateProfile(ctx context.Context, userID int64, update entity.ProfileUpdate) (*entity.UserProfile, error) {
+	fields := []struct {
+		name      string
+		value     *string
+		maxLength int
+		multiline bool
+	}{
+		{"bio", update.Bio, entity.MaxProfileBioLength, true},
+		{"signature", update.Signature, entity.MaxProfileSignatureLength, true},
+		{"location", update.Location, entity.MaxProfileLocationLength, false},
+	}
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		*f.value = cleanProfileText(*f.value, f.multiline)
+		if utf8.RuneCountInString(*f.value) > f.maxLength {
+			return nil, fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidProfile, f.name, f.maxLength)
+		}
+	}
+
+	// Пользователь мог еще не попасть в локальную базу из auth-service
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.profileRepo.UpdateProfile(ctx, userID, update); err != nil {
+		return nil, err
+	}
+	return s.profileRepo.GetProfile(ctx, userID)
+}
+
+func (s *profileService) GetUserTopics(ctx context.Context, filter entity.ActivityFilter) (*entity.TopicPage, error) {
+	return s.profileRepo.GetUserTopics(ctx, filter)
+}
+
+func (s *profileService) GetUserComments(ctx context.Context, filter entity.ActivityFilter) (*entity.UserCommentPage, error) {
+	return s.profileRepo.GetUserComments(ctx, filter)
+}
+
+// cleanProfileText trims the text and drops control characters; line
+// breaks are kept in multiline fields
+func cleanProfileText(text string, multiline bool) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' && multiline {
+			return r
+		}
+		if unicode.IsControl(r) {
+			if r == '\n' || r == '\t' {
+				return ' '
+			}
+			return -1
+		}
+		return r
+	}, text)
+	return strings.TrimSpace(text)
+}
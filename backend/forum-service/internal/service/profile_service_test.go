@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockProfileRepo struct {
+	mock.Mock
+}
+
+func (m *mockProfileRepo) GetProfile(ctx context.Context, userID int64) (*entity.UserProfile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserProfile), args.Error(1)
+}
+
+func (m *mockProfileRepo) UpdateProfile(ctx context.Context, userID int64, update entity.ProfileUpdate) error {
+	args := m.Called(ctx, userID, update)
+	return args.Error(0)
+}
+
+func (m *mockProfileRepo) GetUserTopics(ctx context.Context, filter entity.ActivityFilter) (*entity.TopicPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TopicPage), args.Error(1)
+}
+
+func (m *mockProfileRepo) GetUserComments(ctx context.Context, filter entity.ActivityFilter) (*entity.UserCommentPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserCommentPage), args.Error(1)
+}
+
+func TestProfileService_GetProfile_SyncsUser(t *testing.T) {
+	profileRepo := new(mockProfileRepo)
+	userRepo := new(mockUserRepo)
+	s := NewProfileService(profileRepo, userRepo)
+
+	// Пользователя еще нет в локальной базе: он подтягивается из auth-service
+	profileRepo.On("GetProfile", mock.Anything, int64(5)).Return(nil, repository.ErrUserNotFound).Once()
+	userRepo.On("GetUserByID", mock.Anything, int64(5)).Return(&entity.User{ID: 5, Username: "alice"}, nil)
+	profileRepo.On("GetProfile", mock.Anything, int64(5)).Return(&entity.UserProfile{ID: 5, Username: "alice"}, nil).Once()
+
+	profile, err := s.GetProfile(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", profile.Username)
+	profileRepo.AssertExpectations(t)
+
+	// Неизвестный auth-service пользователь так и не появляется в базе
+	profileRepo.On("GetProfile", mock.Anything, int64(6)).Return(nil, repository.ErrUserNotFound)
+	userRepo.On("GetUserByID", mock.Anything, int64(6)).Return(&entity.User{ID: 6, Username: "User_6"}, nil)
+	_, err = s.GetProfile(context.Background(), 6)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+}
+
+func TestProfileService_UpdateProfile(t *testing.T) {
+	profileRepo := new(mockProfileRepo)
+	userRepo := new(mockUserRepo)
+	s := NewProfileService(profileRepo, userRepo)
+
+	userRepo.On("GetUserByID", mock.Anything, int64(5)).Return(&entity.User{ID: 5, Username: "alice"}, nil)
+	bio, location := "  Gopher\r\nfrom Go\x00 ", "Berlin\nMitte"
+	want := entity.ProfileUpdate{Bio: strPtr("Gopher\nfrom Go"), Location: strPtr("Berlin Mitte")}
+	profileRepo.On("UpdateProfile", mock.Anything, int64(5), want).Return(nil)
+	profileRepo.On("GetProfile", mock.Anything, int64(5)).Return(&entity.UserProfile{ID: 5, Bio: "Gopher\nfrom Go"}, nil)
+
+	profile, err := s.UpdateProfile(context.Background(), 5, entity.ProfileUpdate{Bio: &bio, Location: &location})
+	require.NoError(t, err)
+	assert.Equal(t, "Gopher\nfrom Go", profile.Bio)
+	profileRepo.AssertExpectations(t)
+}
+
+func TestProfileService_UpdateProfile_TooLong(t *testing.T) {
+	profileRepo := new(mockProfileRepo)
+	s := NewProfileService(profileRepo, new(mockUserRepo))
+
+	signature := strings.Repeat("ж", entity.MaxProfileSignatureLength+1)
+	_, err := s.UpdateProfile(context.Background(), 5, entity.ProfileUpdate{Signature: &signature})
+	assert.ErrorIs(t, err, ErrInvalidProfile)
+	assert.Contains(t, err.Error(), "signature")
+	profileRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
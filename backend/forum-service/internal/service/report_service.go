@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+var (
+	ErrInvalidReportTarget  = errors.New("report target must be a topic, comment or chat_message")
+	ErrInvalidReportReason  = errors.New("report reason must be one of spam, abuse, off_topic, other")
+	ErrInvalidReportAction  = errors.New("report action must be one of hide, delete, warn")
+	ErrInvalidReportStatus  = errors.New("report status must be one of open, claimed, resolved, dismissed")
+	ErrReportDetailsTooLong = fmt.Errorf("report details must be at most %d characters", entity.MaxReportDetails)
+	ErrReportOwnContent     = errors.New("you can't report your own content")
+)
+
+type reportService struct {
+	reportRepo        repository.ReportRepository
+	autoHideThreshold int
+	notifier          Notifier
+}
+
+// NewReportService creates a new instance of ReportService. Content reported
+// by autoHideThreshold users is hidden until a moderator reviews it; zero
+// disables auto-hiding. notifier tells authors what was done to their
+// content; nil turns that off.
+func NewReportService(reportRepo repository.ReportRepository, autoHideThreshold int, notifier Notifier) ReportService {
+	if notifier == nil {
+		notifier = NopNotifier
+	}
+	return &reportService{
+		reportRepo:        reportRepo,
+		autoHideThreshold: autoHideThreshold,
+		notifier:          notifier,
+	}
+}
+
+// CreateReport files a report on behalf of report.ReporterID. It reports
+// whether a new report was created rather than an earlier one updated.
+func (s *reportService) CreateReport(ctx context.Context, report *entity.Report) (bool, error) {
+	switch report.TargetType {
+	case entity.ReportTargetTopic, entity.ReportTargetComment, entity.ReportTargetChatMessage:
+	default:
+		return false, ErrInvalidReportTarget
+	}
+	switch report.Reason {
+	case entity.ReportReasonSpam, entity.ReportReasonAbuse, entity.ReportReasonOffTopic, entity.ReportReasonOther:
+	default:
+		return false, ErrInvalidReportReason
+	}
+	report.Details = strings.TrimSpace(report.Details)
+	if utf8.RuneCountInString(report.Details) > entity.MaxReportDetails {
+		return false, ErrReportDetailsTooLong
+	}
+
+	target, err := s.reportRepo.GetReportTarget(ctx, report.TargetType, report.TargetID)
+	if err != nil {
+		return false, err
+	}
+	if target.AuthorID != nil && *target.AuthorID == report.ReporterID {
+		return false, ErrReportOwnContent
+	}
+	// Сохраняем текст на момент жалобы: автор может успеть его изменить
+	report.TargetAuthorID = target.AuthorID
+	report.ContentSnapshot = target.Content
+
+	created, err := s.reportRepo.CreateReport(ctx, report)
+	if err != nil {
+		return false, err
+	}
+	if !created || s.autoHideThreshold <= 0 {
+		return created, nil
+	}
+
+	count, err := s.reportRepo.CountActiveReports(ctx, report.TargetType, report.TargetID)
+	if err != nil {
+		return created, err
+	}
+	if count >= s.autoHideThreshold {
+		if err := s.reportRepo.AutoHideTarget(ctx, report.TargetType, report.TargetID); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+func (s *reportService) ListReports(ctx context.Context, filter entity.ReportFilter) (*entity.ReportPage, error) {
+	switch filter.Status {
+	case "", entity.ReportStatusOpen, entity.ReportStatusClaimed, entity.ReportStatusResolved, entity.ReportStatusDismissed:
+	default:
+		return nil, ErrInvalidReportStatus
+	}
+	switch filter.TargetType {
+	case "", entity.ReportTargetTopic, entity.ReportTargetComment, entity.ReportTargetChatMessage:
+	default:
+		return nil, ErrInvalidReportTarget
+	}
+	return s.reportRepo.ListReports(ctx, filter)
+}
+
+func (s *reportService) ClaimReport(ctx context.Context, id, moderatorID int64) (*entity.Report, error) {
+	return s.reportRepo.ClaimReport(ctx, id, moderatorID)
+}
+
+func (s *reportService) ResolveReport(ctx context.Context, id, moderatorID int64, action entity.ReportAction, note string) (*entity.Report, error) {
+	switch action {
+	case entity.ReportActionHide, entity.ReportActionDelete, entity.ReportActionWarn:
+	default:
+		return nil, ErrInvalidReportAction
+	}
+	report, err := s.reportRepo.ResolveReport(ctx, id, moderatorID, action, strings.TrimSpace(note))
+	if err != nil {
+		return nil, err
+	}
+	if report.TargetAuthorID != nil {
+		s.notifier.Moderated(ctx, entity.NotificationTarget{
+			TargetType: entity.NotificationTargetType(report.TargetType),
+			TargetID:   report.TargetID,
+		}, *report.TargetAuthorID, moderatorID, string(action), report.Note)
+	}
+	return report, nil
+}
+
+func (s *reportService) DismissReport(ctx context.Context, id, moderatorID int64, note string) (*entity.Report, error) {
+	return s.reportRepo.DismissReport(ctx, id, moderatorID, strings.TrimSpace(note))
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReportRepo struct {
+	mock.Mock
+}
+
+func (m *mockReportRepo) GetReportTarget(ctx context.Context, targetType entity.ReportTargetType, targetID int64) (*entity.ReportTarget, error) {
+	args := m.Called(ctx, targetType, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ReportTarget), args.Error(1)
+}
+
+func (m *mockReportRepo) CreateReport(ctx context.Context, report *entity.Report) (bool, error) {
+	args := m.Called(ctx, report)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockReportRepo) CountActiveReports(ctx context.Context, targetType entity.ReportTargetType, targetID int64) (int, error) {
+	args := m.Called(ctx, targetType, targetID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockReportRepo) AutoHideTarget(ctx context.Context, targetType entity.ReportTargetType, targetID int64) error {
+	args := m.Called(ctx, targetType, targetID)
+	return args.Error(0)
+}
+
+func (m *mockReportRepo) GetReportByID(ctx context.Context, id int64) (*entity.Report, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Report), args.Error(1)
+}
+
+func (m *mockReportRepo) ListReports(ctx context.Context, filter entity.ReportFilter) (*entity.ReportPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ReportPage), args.Error(1)
+}
+
+func (m *mockReportRepo) ClaimReport(ctx context.Context, id, moderatorID int64) (*entity.Report, error) {
+	args := m.Called(ctx, id, moderatorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Report), args.Error(1)
+}
+
+func (m *mockReportRepo) ResolveReport(ctx context.Context, id, moderatorID int64, action entity.ReportAction, note string) (*entity.Report, error) {
+	args := m.Called(ctx, id, moderatorID, action, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Report), args.Error(1)
+}
+
+func (m *mockReportRepo) DismissReport(ctx context.Context, id, moderatorID int64, note string) (*entity.Report, error) {
+	args := m.Called(ctx, id, moderatorID, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Report), args.Error(1)
+}
+
+func TestReportService_CreateReport(t *testing.T) {
+	authorID := int64(3)
+	target := &entity.ReportTarget{Type: entity.ReportTargetComment, ID: 10, AuthorID: &authorID, Content: "buy now"}
+
+	tests := []struct {
+		name        string
+		created     bool
+		count       int
+		wantHide    bool
+		wantCreated bool
+	}{
+		{name: "below threshold", created: true, count: 2, wantCreated: true},
+		{name: "reaches threshold", created: true, count: 3, wantHide: true, wantCreated: true},
+		{name: "duplicate report", created: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockReportRepo)
+			s := NewReportService(repo, 3, nil)
+
+			repo.On("GetReportTarget", mock.Anything, entity.ReportTargetComment, int64(10)).Return(target, nil)
+			repo.On("CreateReport", mock.Anything, mock.MatchedBy(func(r *entity.Report) bool {
+				return r.ContentSnapshot == "buy now" && *r.TargetAuthorID == authorID && r.Details == "ads"
+			})).Return(tt.created, nil)
+			if tt.created {
+				repo.On("CountActiveReports", mock.Anything, entity.ReportTargetComment, int64(10)).Return(tt.count, nil)
+			}
+			if tt.wantHide {
+				repo.On("AutoHideTarget", mock.Anything, entity.ReportTargetComment, int64(10)).Return(nil)
+			}
+
+			created, err := s.CreateReport(context.Background(), &entity.Report{
+				TargetType: entity.ReportTargetComment,
+				TargetID:   10,
+				ReporterID: 7,
+				Reason:     entity.ReportReasonSpam,
+				Details:    "  ads ",
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCreated, created)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReportService_CreateReport_AutoHideDisabled(t *testing.T) {
+	repo := new(mockReportRepo)
+	s := NewReportService(repo, 0, nil)
+
+	repo.On("GetReportTarget", mock.Anything, entity.ReportTargetTopic, int64(1)).
+		Return(&entity.ReportTarget{Type: entity.ReportTargetTopic, ID: 1}, nil)
+	repo.On("CreateReport", mock.Anything, mock.Anything).Return(true, nil)
+
+	created, err := s.CreateReport(context.Background(), &entity.Report{
+		TargetType: entity.ReportTargetTopic, TargetID: 1, ReporterID: 7, Reason: entity.ReportReasonAbuse,
+	})
+	assert.NoError(t, err)
+	assert.True(t, created)
+	repo.AssertNotCalled(t, "CountActiveReports", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReportService_CreateReport_Invalid(t *testing.T) {
+	ownerID := int64(7)
+	tests := []struct {
+		name    string
+		report  entity.Report
+		target  *entity.ReportTarget
+		wantErr error
+	}{
+		{
+			name:    "unknown target type",
+			report:  entity.Report{TargetType: "user", TargetID: 1, Reason: entity.ReportReasonSpam},
+			wantErr: ErrInvalidReportTarget,
+		},
+		{
+			name:    "unknown reason",
+			report:  entity.Report{TargetType: entity.ReportTargetTopic, TargetID: 1, Reason: "boring"},
+			wantErr: ErrInvalidReportReason,
+		},
+		{
+			name: "details too long",
+			report: entity.Report{TargetType: entity.ReportTargetTopic, TargetID: 1, Reason: entity.ReportReasonOther,
+				Details: strings.Repeat("я", entity.MaxReportDetails+1)},
+			wantErr: ErrReportDetailsTooLong,
+		},
+		{
+			name:    "missing content",
+			report:  entity.Report{TargetType: entity.ReportTargetChatMessage, TargetID: 1, Reason: entity.ReportReasonAbuse},
+			wantErr: repository.ErrReportTargetNotFound,
+		},
+		{
+			name:    "own content",
+			report:  entity.Report{TargetType: entity.ReportTargetTopic, TargetID: 1, ReporterID: ownerID, Reason: entity.ReportReasonSpam},
+			target:  &entity.ReportTarget{Type: entity.ReportTargetTopic, ID: 1, AuthorID: &ownerID},
+			wantErr: ErrReportOwnContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockReportRepo)
+			s := NewReportService(repo, 3, nil)
+			if tt.target != nil {
+				repo.On("GetReportTarget", mock.Anything, tt.report.TargetType, tt.report.TargetID).Return(tt.target, nil)
+			} else {
+				repo.On("GetReportTarget", mock.Anything, mock.Anything, mock.Anything).Return(nil, repository.ErrReportTargetNotFound)
+			}
+
+			_, err := s.CreateReport(context.Background(), &tt.report)
+			assert.ErrorIs(t, err, tt.wantErr)
+			repo.AssertNotCalled(t, "CreateReport", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReportService_ListReports_InvalidFilter(t *testing.T) {
+	s := NewReportService(new(mockReportRepo), 3, nil)
+
+	_, err := s.ListReports(context.Background(), entity.ReportFilter{Status: "closed"})
+	assert.ErrorIs(t, err, ErrInvalidReportStatus)
+
+	_, err = s.ListReports(context.Background(), entity.ReportFilter{TargetType: "user"})
+	assert.ErrorIs(t, err, ErrInvalidReportTarget)
+}
+
+func TestReportService_ResolveReport(t *testing.T) {
+	repo := new(mockReportRepo)
+	notifier := new(mockNotifier)
+	s := NewReportService(repo, 3, notifier)
+
+	authorID := int64(2)
+	resolved := &entity.Report{ID: 1, TargetType: entity.ReportTargetComment, TargetID: 10, TargetAuthorID: &authorID, Status: entity.ReportStatusResolved, Note: "ads"}
+	repo.On("ResolveReport", mock.Anything, int64(1), int64(5), entity.ReportActionHide, "ads").Return(resolved, nil)
+	notifier.On("Moderated", mock.Anything, entity.NotificationTarget{TargetType: entity.NotificationTargetComment, TargetID: 10}, authorID, int64(5), "hide", "ads")
+
+	report, err := s.ResolveReport(context.Background(), 1, 5, entity.ReportActionHide, " ads ")
+	assert.NoError(t, err)
+	assert.Equal(t, resolved, report)
+
+	_, err = s.ResolveReport(context.Background(), 1, 5, "ban", "")
+	assert.ErrorIs(t, err, ErrInvalidReportAction)
+	repo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/textdiff"
+)
+
+var (
+	ErrInvalidRevisionTarget = errors.New("revision target must be a topic or comment")
+	ErrInvalidRevision       = errors.New("revision must be a positive number")
+	ErrRevisionTooLarge      = errors.New("revisions are too large to diff")
+)
+
+type revisionService struct {
+	revisionRepo repository.RevisionRepository
+	topicRepo    repository.TopicRepository
+	commentRepo  repository.CommentRepository
+	notifier     Notifier
+}
+
+// NewRevisionService creates a new instance of RevisionService. notifier
+// tells authors about rollbacks; nil turns that off.
+func NewRevisionService(revisionRepo repository.RevisionRepository, topicRepo repository.TopicRepository, commentRepo repository.CommentRepository, notifier Notifier) RevisionService {
+	if notifier == nil {
+		notifier = NopNotifier
+	}
+	return &revisionService{
+		revisionRepo: revisionRepo,
+		topicRepo:    topicRepo,
+		commentRepo:  commentRepo,
+		notifier:     notifier,
+	}
+}
+
+// ListRevisions returns the earlier versions of a topic or comment that is
+// still visible, oldest first
+func (s *revisionService) ListRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64) ([]*entity.Revision, error) {
+	if _, err := s.current(ctx, targetType, targetID); err != nil {
+		return nil, err
+	}
+	return s.revisionRepo.ListRevisions(ctx, targetType, targetID)
+}
+
+// DiffRevisions returns a unified diff from revision from to revision to, or
+// to the current version when to is zero
+func (s *revisionService) DiffRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, from, to int) (*entity.RevisionDiff, error) {
+	if from <= 0 || to < 0 {
+		return nil, ErrInvalidRevision
+	}
+	current, err := s.current(ctx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	older, err := s.revisionRepo.GetRevision(ctx, targetType, targetID, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, newerName := current, "current"
+	if to > 0 {
+		if newer, err = s.revisionRepo.GetRevision(ctx, targetType, targetID, to); err != nil {
+			return nil, err
+		}
+		newerName = fmt.Sprintf("revision %d", to)
+	}
+
+	diff, err := textdiff.Unified(fmt.Sprintf("revision %d", from), newerName,
+		revisionText(older), revisionText(newer), textdiff.DefaultContext)
+	if err != nil {
+		return nil, ErrRevisionTooLarge
+	}
+	return &entity.RevisionDiff{
+		TargetType: targetType,
+		TargetID:   targetID,
+		From:       from,
+		To:         to,
+		Diff:       diff,
+	}, nil
+}
+
+// RollbackRevision brings a topic or comment back to an earlier revision on
+// behalf of a moderator. The version being replaced becomes a new revision,
+// so a rollback can itself be undone. Only moderators may call it; the
+// router enforces the role.
+func (s *revisionService) RollbackRevision(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, number int, moderatorID int64) error {
+	if number <= 0 {
+		return ErrInvalidRevision
+	}
+
+	switch targetType {
+	case entity.RevisionTargetTopic:
+		topic, err := s.topicRepo.GetTopicByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		revision, err := s.revisionRepo.GetRevision(ctx, targetType, targetID, number)
+		if err != nil {
+			return err
+		}
+		topic.Title = revision.Title
+		topic.Content = revision.Content
+		if err := s.topicRepo.UpdateTopic(ctx, topic, moderatorID); err != nil {
+			return err
+		}
+		s.notifier.Moderated(ctx, topicTarget(topic.ID), topic.AuthorID, moderatorID, entity.ModerationRollback, "")
+		return nil
+	case entity.RevisionTargetComment:
+		comment, err := s.commentRepo.GetCommentByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if comment.Deleted {
+			return repository.ErrCommentNotFound
+		}
+		revision, err := s.revisionRepo.GetRevision(ctx, targetType, targetID, number)
+		if err != nil {
+			return err
+		}
+		if err := s.commentRepo.UpdateComment(ctx, &entity.Comment{ID: targetID, Content: revision.Content}, moderatorID); err != nil {
+			return err
+		}
+		s.notifier.Moderated(ctx, entity.NotificationTarget{
+			TargetType: entity.NotificationTargetComment,
+			TargetID:   comment.ID,
+			TopicID:    comment.TopicID,
+		}, comment.AuthorID, moderatorID, entity.ModerationRollback, "")
+		return nil
+	default:
+		return ErrInvalidRevisionTarget
+	}
+}
+
+// current returns the current version of a topic or comment in the shape of
+// a revision, or a not found error if it is hidden or deleted
+func (s *revisionService) current(ctx context.Context, targetType entity.RevisionTargetType, targetID int64) (*entity.Revision, error) {
+	switch targetType {
+	case entity.RevisionTargetTopic:
+		topic, err := s.topicRepo.GetTopicByID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		return &entity.Revision{TargetType: targetType, TargetID: targetID, Title: topic.Title, Content: topic.Content}, nil
+	case entity.RevisionTargetComment:
+		comment, err := s.commentRepo.GetCommentByID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if comment.Deleted {
+			return nil, repository.ErrCommentNotFound
+		}
+		return &entity.Revision{TargetType: targetType, TargetID: targetID, Content: comment.Content}, nil
+	default:
+		return nil, ErrInvalidRevisionTarget
+	}
+}
+
+// revisionText is what a diff compares: the title and the text of a topic,
+// or just the text of a comment
+func revisionText(revision *entity.Revision) string {
+	if revision.TargetType == entity.RevisionTargetTopic {
+		return revision.Title + "\n\n" + revision.Content
+	}
+	return revision.Content
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/textdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRevisionRepo struct {
+	mock.Mock
+}
+
+func (m *mockRevisionRepo) ListRevisions(ctx context.Context, targetType entity.RevisionTargetType, targetID int64) ([]*entity.Revision, error) {
+	args := m.Called(ctx, targetType, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Revision), args.Error(1)
+}
+
+func (m *mockRevisionRepo) GetRevision(ctx context.Context, targetType entity.RevisionTargetType, targetID int64, revision int) (*entity.Revision, error) {
+	args := m.Called(ctx, targetType, targetID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Revision), args.Error(1)
+}
+
+func TestRevisionService_ListRevisions(t *testing.T) {
+	revisionRepo := new(mockRevisionRepo)
+	topicRepo := new(mockTopicRepo)
+	commentRepo := new(mockCommentRepo)
+	s := NewRevisionService(revisionRepo, topicRepo, commentRepo, nil)
+
+	revisions := []*entity.Revision{{ID: 1, Revision: 1, Content: "old"}}
+	topicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1}, nil)
+	revisionRepo.On("ListRevisions", mock.Anything, entity.RevisionTargetTopic, int64(1)).Return(revisions, nil)
+
+	got, err := s.ListRevisions(context.Background(), entity.RevisionTargetTopic, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, revisions, got)
+
+	// История удаленного комментария не показывается
+	commentRepo.On("GetCommentByID", mock.Anything, int64(2)).Return(&entity.Comment{ID: 2, Deleted: true}, nil)
+	_, err = s.ListRevisions(context.Background(), entity.RevisionTargetComment, 2)
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound)
+
+	_, err = s.ListRevisions(context.Background(), entity.RevisionTargetType("chat_message"), 3)
+	assert.ErrorIs(t, err, ErrInvalidRevisionTarget)
+	revisionRepo.AssertNumberOfCalls(t, "ListRevisions", 1)
+}
+
+func TestRevisionService_DiffRevisions(t *testing.T) {
+	revisionRepo := new(mockRevisionRepo)
+	topicRepo := new(mockTopicRepo)
+	s := NewRevisionService(revisionRepo, topicRepo, new(mockCommentRepo), nil)
+
+	topicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, Title: "Title", Content: "third"}, nil)
+	revisionRepo.On("GetRevision", mock.Anything, entity.RevisionTargetTopic, int64(1), 1).
+		Return(&entity.Revision{TargetType: entity.RevisionTargetTopic, Revision: 1, Title: "Title", Content: "first"}, nil)
+	revisionRepo.On("GetRevision", mock.Anything, entity.RevisionTargetTopic, int64(1), 2).
+		Return(&entity.Revision{TargetType: entity.RevisionTargetTopic, Revision: 2, Title: "Title", Content: "second"}, nil)
+
+	diff, err := s.DiffRevisions(context.Background(), entity.RevisionTargetTopic, 1, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, diff.To)
+	assert.Equal(t, "--- revision 1\n+++ current\n@@ -1,3 +1,3 @@\n Title\n \n-first\n+third\n", diff.Diff)
+
+	diff, err = s.DiffRevisions(context.Background(), entity.RevisionTargetTopic, 1, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, diff.To)
+	assert.Contains(t, diff.Diff, "+++ revision 2\n")
+	assert.Contains(t, diff.Diff, "+second\n")
+
+	_, err = s.DiffRevisions(context.Background(), entity.RevisionTargetTopic, 1, 0, 2)
+	assert.ErrorIs(t, err, ErrInvalidRevision)
+
+	revisionRepo.On("GetRevision", mock.Anything, entity.RevisionTargetTopic, int64(1), 3).
+		Return(&entity.Revision{TargetType: entity.RevisionTargetTopic, Revision: 3, Title: "Title",
+			Content: strings.Repeat("line\n", textdiff.MaxLines)}, nil)
+	_, err = s.DiffRevisions(context.Background(), entity.RevisionTargetTopic, 1, 1, 3)
+	assert.ErrorIs(t, err, ErrRevisionTooLarge)
+}
+
+func TestRevisionService_RollbackRevision(t *testing.T) {
+	revisionRepo := new(mockRevisionRepo)
+	topicRepo := new(mockTopicRepo)
+	commentRepo := new(mockCommentRepo)
+	s := NewRevisionService(revisionRepo, topicRepo, commentRepo, nil)
+
+	topic := &entity.Topic{ID: 1, Title: "Vandalized", Content: "spam", CategoryID: 3}
+	topicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(topic, nil)
+	revisionRepo.On("GetRevision", mock.Anything, entity.RevisionTargetTopic, int64(1), 2).
+		Return(&entity.Revision{Title: "Original", Content: "text"}, nil)
+	topicRepo.On("UpdateTopic", mock.Anything, mock.MatchedBy(func(t *entity.Topic) bool {
+		return t.Title == "Original" && t.Content == "text" && t.CategoryID == 3
+	}), int64(9)).Return(nil)
+
+	err := s.RollbackRevision(context.Background(), entity.RevisionTargetTopic, 1, 2, 9)
+	assert.NoError(t, err)
+
+	commentRepo.On("GetCommentByID", mock.Anything, int64(5)).Return(&entity.Comment{ID: 5, Content: "spam"}, nil)
+	revisionRepo.On("GetRevision", mock.Anything, entity.RevisionTargetComment, int64(5), 7).
+		Return(nil, repository.ErrRevisionNotFound)
+	err = s.RollbackRevision(context.Background(), entity.RevisionTargetComment, 5, 7, 9)
+	assert.ErrorIs(t, err, repository.ErrRevisionNotFound)
+
+	revisionRepo.On("GetRevision", mock.Anything, entity.RevisionTargetComment, int64(5), 1).
+		Return(&entity.Revision{Content: "fine"}, nil)
+	commentRepo.On("UpdateComment", mock.Anything, &entity.Comment{ID: 5, Content: "fine"}, int64(9)).Return(nil)
+	err = s.RollbackRevision(context.Background(), entity.RevisionTargetComment, 5, 1, 9)
+	assert.NoError(t, err)
+
+	topicRepo.AssertExpectations(t)
+	commentRepo.AssertExpectations(t)
+}
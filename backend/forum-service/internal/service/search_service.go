@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+const maxSearchQueryLength = 200
+
+var (
+	ErrEmptySearchQuery    = errors.New("search query is empty")
+	ErrSearchQueryTooLong  = errors.New("search query is too long")
+	ErrInvalidSearchPeriod = errors.New("search period start is after its end")
+)
+
+type searchService struct {
+	searchRepo repository.SearchRepository
+	userRepo   repository.UserRepository
+}
+
+// NewSearchService creates a new instance of SearchService
+func NewSearchService(searchRepo repository.SearchRepository, userRepo repository.UserRepository) SearchService {
+	return &searchService{
+		searchRepo: searchRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (s *searchService) Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error) {
+	query.Query = strings.TrimSpace(query.Query)
+	if query.Query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	if utf8.RuneCountInString(query.Query) > maxSearchQueryLength {
+		return nil, ErrSearchQueryTooLong
+	}
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return nil, ErrInvalidSearchPeriod
+	}
+
+	page, err := s.searchRepo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get author information, looking each author up only once per page
+	authors := make(map[int64]*entity.User)
+	for _, result := range page.Results {
+		if result.AuthorID <= 0 {
+			continue
+		}
+		author, ok := authors[result.AuthorID]
+		if !ok {
+			author, err = s.userRepo.GetUserByID(ctx, result.AuthorID)
+			if err != nil {
+				return nil, err
+			}
+			authors[result.AuthorID] = author
+		}
+		result.Author = author
+	}
+
+	return page, nil
+}
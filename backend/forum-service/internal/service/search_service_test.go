@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSearchRepo struct {
+	mock.Mock
+}
+
+func (m *mockSearchRepo) Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SearchPage), args.Error(1)
+}
+
+func TestSearchService_Search(t *testing.T) {
+	mockSearchRepo := new(mockSearchRepo)
+	mockUserRepo := new(mockUserRepo)
+	searchService := NewSearchService(mockSearchRepo, mockUserRepo)
+
+	page := &entity.SearchPage{Results: []*entity.SearchResult{
+		{Type: entity.SearchResultTopic, ID: 1, AuthorID: 1},
+		{Type: entity.SearchResultComment, ID: 2, AuthorID: 1},
+		{Type: entity.SearchResultComment, ID: 3, AuthorID: 2},
+	}}
+	author1 := &entity.User{ID: 1, Username: "alice"}
+	author2 := &entity.User{ID: 2, Username: "bob"}
+
+	mockSearchRepo.On("Search", mock.Anything, entity.SearchQuery{Query: "горутины"}).Return(page, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(author1, nil).Once()
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(2)).Return(author2, nil).Once()
+
+	result, err := searchService.Search(context.Background(), entity.SearchQuery{Query: "  горутины "})
+	assert.NoError(t, err)
+	assert.Len(t, result.Results, 3)
+	assert.Equal(t, author1, result.Results[0].Author)
+	assert.Equal(t, author1, result.Results[1].Author)
+	assert.Equal(t, author2, result.Results[2].Author)
+	mockSearchRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestSearchService_Search_InvalidQuery(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   entity.SearchQuery
+		wantErr error
+	}{
+		{"empty", entity.SearchQuery{Query: "   "}, ErrEmptySearchQuery},
+		{"too long", entity.SearchQuery{Query: strings.Repeat("я", maxSearchQueryLength+1)}, ErrSearchQueryTooLong},
+		{"inverted period", entity.SearchQuery{Query: "go", From: &from, To: &to}, ErrInvalidSearchPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSearchRepo := new(mockSearchRepo)
+			searchService := NewSearchService(mockSearchRepo, new(mockUserRepo))
+
+			result, err := searchService.Search(context.Background(), tt.query)
+			assert.True(t, errors.Is(err, tt.wantErr))
+			assert.Nil(t, result)
+			mockSearchRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+var ErrInvalidWatchLevel = errors.New("watch level must be watching, tracking or muted")
+
+type subscriptionService struct {
+	subscriptionRepo repository.SubscriptionRepository
+}
+
+// NewSubscriptionService creates a new instance of SubscriptionService
+func NewSubscriptionService(subscriptionRepo repository.SubscriptionRepository) SubscriptionService {
+	return &subscriptionService{
+		subscriptionRepo: subscriptionRepo,
+	}
+}
+
+func (s *subscriptionService) GetSubscription(ctx context.Context, userID, topicID int64) (*entity.TopicSubscription, error) {
+	return s.subscriptionRepo.GetSubscription(ctx, userID, topicID)
+}
+
+// Subscribe sets the user's watch level on a topic, subscribing them if
+// they weren't
+func (s *subscriptionService) Subscribe(ctx context.Context, userID, topicID int64, level entity.WatchLevel) (*entity.TopicSubscription, error) {
+	if !level.Valid() {
+		return nil, ErrInvalidWatchLevel
+	}
+	subscription := &entity.TopicSubscription{UserID: userID, TopicID: topicID, Level: level}
+	if err := s.subscriptionRepo.SetSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *subscriptionService) Unsubscribe(ctx context.Context, userID, topicID int64) error {
+	return s.subscriptionRepo.DeleteSubscription(ctx, userID, topicID)
+}
+
+func (s *subscriptionService) ListWatchedTopics(ctx context.Context, filter entity.WatchedTopicFilter) (*entity.WatchedTopicPage, error) {
+	if filter.Level != "" && !filter.Level.Valid() {
+		return nil, ErrInvalidWatchLevel
+	}
+	return s.subscriptionRepo.GetWatchedTopics(ctx, filter)
+}
+
+// MarkTopicRead resets the user's unread comment count of a topic they
+// opened
+func (s *subscriptionService) MarkTopicRead(ctx context.Context, userID, topicID int64) error {
+	return s.subscriptionRepo.MarkTopicRead(ctx, userID, topicID)
+}
+
+func (s *subscriptionService) GetWatchSettings(ctx context.Context, userID int64) (*entity.WatchSettings, error) {
+	return s.subscriptionRepo.GetWatchSettings(ctx, userID)
+}
+
+func (s *subscriptionService) UpdateWatchSettings(ctx context.Context, userID int64, settings *entity.WatchSettings) error {
+	return s.subscriptionRepo.SetWatchSettings(ctx, userID, settings)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSubscriptionRepo struct {
+	mock.Mock
+}
+
+func (m *mockSubscriptionRepo) GetSubscription(ctx context.Context, userID, topicID int64) (*entity.TopicSubscription, error) {
+	args := m.Called(ctx, userID, topicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TopicSubscription), args.Error(1)
+}
+
+func (m *mockSubscriptionRepo) SetSubscription(ctx context.Context, subscription *entity.TopicSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *mockSubscriptionRepo) DeleteSubscription(ctx context.Context, userID, topicID int64) error {
+	args := m.Called(ctx, userID, topicID)
+	return args.Error(0)
+}
+
+func (m *mockSubscriptionRepo) GetWatchedTopics(ctx context.Context, filter entity.WatchedTopicFilter) (*entity.WatchedTopicPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WatchedTopicPage), args.Error(1)
+}
+
+func (m *mockSubscriptionRepo) GetSubscribers(ctx context.Context, topicID int64) (map[int64]entity.WatchLevel, error) {
+	args := m.Called(ctx, topicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]entity.WatchLevel), args.Error(1)
+}
+
+func (m *mockSubscriptionRepo) MarkTopicRead(ctx context.Context, userID, topicID int64) error {
+	args := m.Called(ctx, userID, topicID)
+	return args.Error(0)
+}
+
+func (m *mockSubscriptionRepo) GetWatchSettings(ctx context.Context, userID int64) (*entity.WatchSettings, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WatchSettings), args.Error(1)
+}
+
+func (m *mockSubscriptionRepo) SetWatchSettings(ctx context.Context, userID int64, settings *entity.WatchSettings) error {
+	args := m.Called(ctx, userID, settings)
+	return args.Error(0)
+}
+
+func TestSubscriptionService_Subscribe(t *testing.T) {
+	repo := new(mockSubscriptionRepo)
+	s := NewSubscriptionService(repo)
+
+	repo.On("SetSubscription", mock.Anything, &entity.TopicSubscription{UserID: 2, TopicID: 4, Level: entity.WatchLevelMuted}).Return(nil).Once()
+	repo.On("SetSubscription", mock.Anything, &entity.TopicSubscription{UserID: 2, TopicID: 9, Level: entity.WatchLevelWatching}).Return(repository.ErrTopicNotFound).Once()
+
+	subscription, err := s.Subscribe(context.Background(), 2, 4, entity.WatchLevelMuted)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.WatchLevelMuted, subscription.Level)
+
+	_, err = s.Subscribe(context.Background(), 2, 9, entity.WatchLevelWatching)
+	assert.ErrorIs(t, err, repository.ErrTopicNotFound)
+
+	_, err = s.Subscribe(context.Background(), 2, 4, "normal")
+	assert.ErrorIs(t, err, ErrInvalidWatchLevel)
+	repo.AssertExpectations(t)
+}
+
+func TestSubscriptionService_ListWatchedTopics(t *testing.T) {
+	repo := new(mockSubscriptionRepo)
+	s := NewSubscriptionService(repo)
+
+	filter := entity.WatchedTopicFilter{UserID: 2, Level: entity.WatchLevelTracking}
+	repo.On("GetWatchedTopics", mock.Anything, filter).Return(&entity.WatchedTopicPage{
+		Topics: []*entity.WatchedTopic{{TopicID: 4, UnreadComments: 3}},
+	}, nil)
+
+	page, err := s.ListWatchedTopics(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, page.Topics[0].UnreadComments)
+
+	_, err = s.ListWatchedTopics(context.Background(), entity.WatchedTopicFilter{UserID: 2, Level: "all"})
+	assert.ErrorIs(t, err, ErrInvalidWatchLevel)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+// maxTagLength matches tags.name VARCHAR(32)
+const maxTagLength = 32
+
+var (
+	ErrInvalidTag      = errors.New("tags may contain only letters, digits and - _ + # . and be at most 32 characters long")
+	ErrTooManyTags     = fmt.Errorf("a topic can have at most %d tags", entity.MaxTagsPerTopic)
+	ErrInvalidTagMerge = errors.New("a tag can't be merged into itself")
+)
+
+type tagService struct {
+	tagRepo repository.TagRepository
+}
+
+// NewTagService creates a new instance of TagService
+func NewTagService(tagRepo repository.TagRepository) TagService {
+	return &tagService{
+		tagRepo: tagRepo,
+	}
+}
+
+func (s *tagService) GetAllTags(ctx context.Context) ([]*entity.Tag, error) {
+	return s.tagRepo.GetAllTags(ctx)
+}
+
+func (s *tagService) RenameTag(ctx context.Context, id int64, name string) (*entity.Tag, error) {
+	name, err := NormalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tagRepo.RenameTag(ctx, id, name); err != nil {
+		return nil, err
+	}
+	return s.tagRepo.GetTagByID(ctx, id)
+}
+
+func (s *tagService) MergeTags(ctx context.Context, sourceID, targetID int64) error {
+	if sourceID == targetID {
+		return ErrInvalidTagMerge
+	}
+	return s.tagRepo.MergeTags(ctx, sourceID, targetID)
+}
+
+// NormalizeTagName lowercases a tag name and joins its words with dashes,
+// so "Go  Modules" and "go-modules" end up as the same tag.
+func NormalizeTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), "-")
+	if name == "" || utf8.RuneCountInString(name) > maxTagLength {
+		return "", ErrInvalidTag
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_+#.", r) {
+			return "", ErrInvalidTag
+		}
+	}
+	return name, nil
+}
+
+// normalizeTagNames normalizes and deduplicates the tags of a topic
+func normalizeTagNames(names []string) ([]string, error) {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		normalized, err := NormalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		result = append(result, normalized)
+	}
+	if len(result) > entity.MaxTagsPerTopic {
+		return nil, ErrTooManyTags
+	}
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTagRepo struct {
+	mock.Mock
+}
+
+func (m *mockTagRepo) GetAllTags(ctx context.Context) ([]*entity.Tag, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Tag), args.Error(1)
+}
+
+func (m *mockTagRepo) GetTagByID(ctx context.Context, id int64) (*entity.Tag, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Tag), args.Error(1)
+}
+
+func (m *mockTagRepo) GetTagsByTopicIDs(ctx context.Context, topicIDs []int64) (map[int64][]*entity.Tag, error) {
+	args := m.Called(ctx, topicIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64][]*entity.Tag), args.Error(1)
+}
+
+func (m *mockTagRepo) RenameTag(ctx context.Context, id int64, name string) error {
+	args := m.Called(ctx, id, name)
+	return args.Error(0)
+}
+
+func (m *mockTagRepo) MergeTags(ctx context.Context, sourceID, targetID int64) error {
+	args := m.Called(ctx, sourceID, targetID)
+	return args.Error(0)
+}
+
+func TestNormalizeTagName(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "Golang", want: "golang"},
+		{in: "  Go   Modules ", want: "go-modules"},
+		{in: "C++", want: "c++"},
+		{in: "Базы Данных", want: "базы-данных"},
+		{in: "", wantErr: true},
+		{in: "drop;table", wantErr: true},
+		{in: "очень-длинный-тег-который-не-помещается", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeTagName(tt.in)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidTag, tt.in)
+			continue
+		}
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestTagService_RenameTag(t *testing.T) {
+	mockTagRepo := new(mockTagRepo)
+	tagService := NewTagService(mockTagRepo)
+
+	mockTagRepo.On("RenameTag", mock.Anything, int64(1), "golang").Return(nil)
+	mockTagRepo.On("GetTagByID", mock.Anything, int64(1)).Return(&entity.Tag{ID: 1, Name: "golang"}, nil)
+
+	tag, err := tagService.RenameTag(context.Background(), 1, " GoLang ")
+	assert.NoError(t, err)
+	assert.Equal(t, "golang", tag.Name)
+	mockTagRepo.AssertExpectations(t)
+}
+
+func TestTagService_MergeTags(t *testing.T) {
+	mockTagRepo := new(mockTagRepo)
+	tagService := NewTagService(mockTagRepo)
+
+	mockTagRepo.On("MergeTags", mock.Anything, int64(2), int64(1)).Return(nil)
+
+	assert.NoError(t, tagService.MergeTags(context.Background(), 2, 1))
+	assert.ErrorIs(t, tagService.MergeTags(context.Background(), 1, 1), ErrInvalidTagMerge)
+	mockTagRepo.AssertExpectations(t)
+}
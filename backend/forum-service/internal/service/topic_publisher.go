@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+const (
+	// DefaultPublishInterval is used when no positive publish interval is given
+	DefaultPublishInterval = 15 * time.Second
+	// publishBatchSize is how many scheduled topics are published per query
+	publishBatchSize = 100
+)
+
+// TopicPublisher publishes scheduled topics once their time comes. The
+// schedule lives in the database, so topics that came due while the service
+// was down are published on start, and every topic is claimed by a single
+// instance when several run at the same time.
+type TopicPublisher struct {
+	topicRepo repository.TopicRepository
+	notifier  Notifier
+	now       func() time.Time
+}
+
+// NewTopicPublisher creates a publisher that tells notifier about the users
+// mentioned in published topics
+func NewTopicPublisher(topicRepo repository.TopicRepository, notifier Notifier) *TopicPublisher {
+	if notifier == nil {
+		notifier = NopNotifier
+	}
+	return &TopicPublisher{
+		topicRepo: topicRepo,
+		notifier:  notifier,
+		now:       time.Now,
+	}
+}
+
+// PublishDue publishes every topic due by now and returns how many there were
+func (p *TopicPublisher) PublishDue(ctx context.Context) (int, error) {
+	now := p.now()
+	published := 0
+	for {
+		topics, err := p.topicRepo.PublishDueTopics(ctx, now, publishBatchSize)
+		if err != nil {
+			return published, err
+		}
+		// Упоминания в отложенной теме становятся видны только сейчас
+		for _, topic := range topics {
+			p.notifier.Mentioned(ctx, topicTarget(topic.ID), topic.AuthorID, topic.MentionedIDs)
+		}
+		published += len(topics)
+		if len(topics) < publishBatchSize {
+			return published, nil
+		}
+	}
+}
+
+// Run publishes due topics right away and then every interval until the
+// context is cancelled
+func (p *TopicPublisher) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPublishInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := p.PublishDue(ctx)
+		if err != nil {
+			log.Printf("Error publishing scheduled topics: %v", err)
+		} else if published > 0 {
+			log.Printf("Published %d scheduled topics", published)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
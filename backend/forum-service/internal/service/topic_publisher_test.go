@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTopicPublisher_PublishDue(t *testing.T) {
+	repo := new(mockTopicRepo)
+	notifier := new(mockNotifier)
+	publisher := NewTopicPublisher(repo, notifier)
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	publisher.now = func() time.Time { return now }
+
+	// Полная пачка означает, что темы могли остаться
+	full := make([]*entity.Topic, publishBatchSize)
+	for i := range full {
+		full[i] = &entity.Topic{ID: int64(i + 1), AuthorID: 2}
+	}
+	last := &entity.Topic{ID: 500, AuthorID: 3, MentionedIDs: []int64{7}}
+	repo.On("PublishDueTopics", mock.Anything, now, publishBatchSize).Return(full, nil).Once()
+	repo.On("PublishDueTopics", mock.Anything, now, publishBatchSize).Return([]*entity.Topic{last}, nil).Once()
+	notifier.On("Mentioned", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	published, err := publisher.PublishDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, publishBatchSize+1, published)
+	notifier.AssertCalled(t, "Mentioned", mock.Anything, topicTarget(500), int64(3), []int64{7})
+	repo.AssertExpectations(t)
+}
+
+func TestTopicPublisher_PublishDue_Error(t *testing.T) {
+	repo := new(mockTopicRepo)
+	notifier := new(mockNotifier)
+	publisher := NewTopicPublisher(repo, notifier)
+
+	repo.On("PublishDueTopics", mock.Anything, mock.Anything, publishBatchSize).Return(nil, errors.New("db down"))
+
+	_, err := publisher.PublishDue(context.Background())
+	assert.Error(t, err)
+	notifier.AssertNotCalled(t, "Mentioned", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
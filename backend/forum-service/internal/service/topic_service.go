@@ -2,35 +2,91 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 )
 
 type TopicService interface {
 	CreateTopic(ctx context.Context, topic *entity.Topic) error
 	GetTopicByID(ctx context.Context, id int64) (*entity.Topic, error)
-	GetAllTopics(ctx context.Context) ([]*entity.Topic, error)
-	UpdateTopic(ctx context.Context, topic *entity.Topic) error
-	DeleteTopic(ctx context.Context, id int64) error
+	GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error)
+	UpdateTopic(ctx context.Context, topic *entity.Topic, userID int64) error
+	DeleteTopic(ctx context.Context, id, userID int64) error
+	RestoreTopic(ctx context.Context, id int64) error
 	UpdateCommentCount(ctx context.Context, topicID int64) error
+	PinTopic(ctx context.Context, id int64, scope entity.PinScope, until *time.Time) (*entity.Topic, error)
+	UnpinTopic(ctx context.Context, id int64) (*entity.Topic, error)
+	LockTopic(ctx context.Context, id, moderatorID int64, until *time.Time) (*entity.Topic, error)
+	UnlockTopic(ctx context.Context, id int64) (*entity.Topic, error)
+	SetAnnouncement(ctx context.Context, id int64, until *time.Time) (*entity.Topic, error)
+	ClearAnnouncement(ctx context.Context, id int64) (*entity.Topic, error)
+	GetScheduledTopic(ctx context.Context, id, viewerID int64) (*entity.Topic, error)
+	ListScheduledTopics(ctx context.Context, userID int64) ([]*entity.Topic, error)
+	RescheduleTopic(ctx context.Context, id, userID int64, publishAt time.Time) (*entity.Topic, error)
+	CancelScheduledTopic(ctx context.Context, id, userID int64) error
 }
 
+var (
+	ErrUnknownCategory = errors.New("unknown category")
+	ErrInvalidPinScope = errors.New("invalid pin scope")
+	ErrExpiryInPast    = errors.New("expiry must be in the future")
+	ErrPublishAtInPast = errors.New("publish time must be in the future")
+)
+
 type topicService struct {
-	topicRepo repository.TopicRepository
-	userRepo  repository.UserRepository
+	topicRepo    repository.TopicRepository
+	userRepo     repository.UserRepository
+	categoryRepo repository.CategoryRepository
+	tagRepo      repository.TagRepository
+	notifier     Notifier
+	policy       *policy.Policy
 }
 
-// NewTopicService creates a new instance of TopicService
-func NewTopicService(topicRepo repository.TopicRepository, userRepo repository.UserRepository) TopicService {
+// NewTopicService creates a new instance of TopicService. notifier is told
+// about mentions and moderators deleting topics; nil turns that off.
+func NewTopicService(topicRepo repository.TopicRepository, userRepo repository.UserRepository, categoryRepo repository.CategoryRepository, tagRepo repository.TagRepository, notifier Notifier) TopicService {
+	if notifier == nil {
+		notifier = NopNotifier
+	}
 	return &topicService{
-		topicRepo: topicRepo,
-		userRepo:  userRepo,
+		topicRepo:    topicRepo,
+		userRepo:     userRepo,
+		categoryRepo: categoryRepo,
+		tagRepo:      tagRepo,
+		notifier:     notifier,
+		policy:       policy.New(userRepo),
 	}
 }
 
+// CreateTopic saves a new topic. A topic with PublishAt set stays scheduled,
+// visible only to its author and moderators, until TopicPublisher publishes
+// it.
 func (s *topicService) CreateTopic(ctx context.Context, topic *entity.Topic) error {
-	return s.topicRepo.CreateTopic(ctx, topic)
+	if topic.PublishAt != nil && !topic.PublishAt.After(time.Now()) {
+		return ErrPublishAtInPast
+	}
+	category, err := s.lookupCategory(ctx, topic.CategoryID)
+	if err != nil {
+		return err
+	}
+	if topic.TagNames, err = normalizeTagNames(topic.TagNames); err != nil {
+		return err
+	}
+	// Теги сохраняются в одной транзакции с темой
+	if err := s.topicRepo.CreateTopic(ctx, topic); err != nil {
+		return err
+	}
+	topic.Category = category
+	topic.TagNames = nil
+	// Об упоминаниях в отложенной теме сообщит публикатор
+	if topic.PublishAt == nil {
+		s.notifier.Mentioned(ctx, topicTarget(topic.ID), topic.AuthorID, topic.MentionedIDs)
+	}
+	return nil
 }
 
 func (s *topicService) GetTopicByID(ctx context.Context, id int64) (*entity.Topic, error) {
@@ -38,7 +94,11 @@ func (s *topicService) GetTopicByID(ctx context.Context, id int64) (*entity.Topi
 	if err != nil {
 		return nil, err
 	}
+	return s.completeTopic(ctx, topic)
+}
 
+// completeTopic fills in the author, the category and the tags of a topic
+func (s *topicService) completeTopic(ctx context.Context, topic *entity.Topic) (*entity.Topic, error) {
 	// Get author information
 	if topic.AuthorID > 0 {
 		author, err := s.userRepo.GetUserByID(ctx, topic.AuthorID)
@@ -48,17 +108,24 @@ func (s *topicService) GetTopicByID(ctx context.Context, id int64) (*entity.Topi
 		topic.Author = author
 	}
 
+	if err := s.attachCategories(ctx, []*entity.Topic{topic}); err != nil {
+		return nil, err
+	}
+	if err := s.attachTags(ctx, []*entity.Topic{topic}); err != nil {
+		return nil, err
+	}
+
 	return topic, nil
 }
 
-func (s *topicService) GetAllTopics(ctx context.Context) ([]*entity.Topic, error) {
-	topics, err := s.topicRepo.GetAllTopics(ctx)
+func (s *topicService) GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error) {
+	page, err := s.topicRepo.GetAllTopics(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get author information for each topic that doesn't have it
-	for _, topic := range topics {
+	for _, topic := range page.Topics {
 		if topic.AuthorID > 0 && (topic.Author == nil || topic.Author.Username == "") {
 			author, err := s.userRepo.GetUserByID(ctx, topic.AuthorID)
 			if err != nil {
@@ -68,17 +135,304 @@ func (s *topicService) GetAllTopics(ctx context.Context) ([]*entity.Topic, error
 		}
 	}
 
+	if err := s.attachCategories(ctx, page.Topics); err != nil {
+		return nil, err
+	}
+	if err := s.attachTags(ctx, page.Topics); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// UpdateTopic changes a topic on behalf of userID, who must be its author
+// or a moderator.
+func (s *topicService) UpdateTopic(ctx context.Context, topic *entity.Topic, userID int64) error {
+	current, err := s.topicRepo.GetTopicByID(ctx, topic.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.policy.Authorize(ctx, userID, policy.ResourceTopic, policy.ActionUpdate, current.AuthorID); err != nil {
+		return err
+	}
+	topic.AuthorID = current.AuthorID
+
+	// A topic keeps its category unless a new one is given
+	if topic.CategoryID == 0 {
+		topic.CategoryID = current.CategoryID
+	}
+	category, err := s.lookupCategory(ctx, topic.CategoryID)
+	if err != nil {
+		return err
+	}
+	// Без списка тегов тема сохраняет прежние, иначе они заменяются в
+	// одной транзакции с правкой
+	keepTags := topic.TagNames == nil
+	if !keepTags {
+		if topic.TagNames, err = normalizeTagNames(topic.TagNames); err != nil {
+			return err
+		}
+	}
+	if err := s.topicRepo.UpdateTopic(ctx, topic, userID); err != nil {
+		return err
+	}
+	topic.Category = category
+	topic.TagNames = nil
+	s.notifier.Mentioned(ctx, topicTarget(topic.ID), topic.AuthorID, topic.MentionedIDs)
+
+	if keepTags {
+		return s.attachTags(ctx, []*entity.Topic{topic})
+	}
+	return nil
+}
+
+// DeleteTopic moves a topic to the trash on behalf of userID, who must be
+// its author or a moderator.
+func (s *topicService) DeleteTopic(ctx context.Context, id, userID int64) error {
+	topic, err := s.topicRepo.GetTopicByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.policy.Authorize(ctx, userID, policy.ResourceTopic, policy.ActionDelete, topic.AuthorID); err != nil {
+		return err
+	}
+	if err := s.topicRepo.DeleteTopic(ctx, id, userID); err != nil {
+		return err
+	}
+	s.notifier.Moderated(ctx, topicTarget(id), topic.AuthorID, userID, entity.ModerationDelete, "")
+	return nil
+}
+
+// RestoreTopic brings back a deleted topic. Only moderators may call it;
+// the router enforces the role.
+func (s *topicService) RestoreTopic(ctx context.Context, id int64) error {
+	return s.topicRepo.RestoreTopic(ctx, id)
+}
+
+// PinTopic keeps a topic on top of the listings in scope until the given
+// time, or until it is unpinned if until is nil, and returns the topic.
+// Only moderators may change topic flags; the router enforces the role.
+func (s *topicService) PinTopic(ctx context.Context, id int64, scope entity.PinScope, until *time.Time) (*entity.Topic, error) {
+	if !scope.Valid() {
+		return nil, ErrInvalidPinScope
+	}
+	if err := checkExpiry(until); err != nil {
+		return nil, err
+	}
+	if err := s.topicRepo.SetPin(ctx, id, scope, until); err != nil {
+		return nil, err
+	}
+	return s.GetTopicByID(ctx, id)
+}
+
+func (s *topicService) UnpinTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	if err := s.topicRepo.SetPin(ctx, id, "", nil); err != nil {
+		return nil, err
+	}
+	return s.GetTopicByID(ctx, id)
+}
+
+// LockTopic stops new comments in a topic and lets its author know
+func (s *topicService) LockTopic(ctx context.Context, id, moderatorID int64, until *time.Time) (*entity.Topic, error) {
+	if err := checkExpiry(until); err != nil {
+		return nil, err
+	}
+	if err := s.topicRepo.SetLock(ctx, id, true, until); err != nil {
+		return nil, err
+	}
+	topic, err := s.GetTopicByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.notifier.Moderated(ctx, topicTarget(id), topic.AuthorID, moderatorID, entity.ModerationLock, "")
+	return topic, nil
+}
+
+func (s *topicService) UnlockTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	if err := s.topicRepo.SetLock(ctx, id, false, nil); err != nil {
+		return nil, err
+	}
+	return s.GetTopicByID(ctx, id)
+}
+
+func (s *topicService) SetAnnouncement(ctx context.Context, id int64, until *time.Time) (*entity.Topic, error) {
+	if err := checkExpiry(until); err != nil {
+		return nil, err
+	}
+	if err := s.topicRepo.SetAnnouncement(ctx, id, true, until); err != nil {
+		return nil, err
+	}
+	return s.GetTopicByID(ctx, id)
+}
+
+func (s *topicService) ClearAnnouncement(ctx context.Context, id int64) (*entity.Topic, error) {
+	if err := s.topicRepo.SetAnnouncement(ctx, id, false, nil); err != nil {
+		return nil, err
+	}
+	return s.GetTopicByID(ctx, id)
+}
+
+// GetScheduledTopic returns a topic waiting to be published. Only its author
+// and moderators may see it; anyone else gets ErrTopicNotFound, as if the
+// topic didn't exist yet.
+func (s *topicService) GetScheduledTopic(ctx context.Context, id, viewerID int64) (*entity.Topic, error) {
+	topic, err := s.scheduledTopic(ctx, id, viewerID, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+	return s.completeTopic(ctx, topic)
+}
+
+// ListScheduledTopics returns the topics of a user waiting to be published,
+// the soonest first
+func (s *topicService) ListScheduledTopics(ctx context.Context, userID int64) ([]*entity.Topic, error) {
+	topics, err := s.topicRepo.GetScheduledTopics(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(topics) == 0 {
+		return topics, nil
+	}
+
+	author, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, topic := range topics {
+		topic.Author = author
+	}
+	if err := s.attachCategories(ctx, topics); err != nil {
+		return nil, err
+	}
+	if err := s.attachTags(ctx, topics); err != nil {
+		return nil, err
+	}
 	return topics, nil
 }
 
-func (s *topicService) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
-	return s.topicRepo.UpdateTopic(ctx, topic)
+// RescheduleTopic moves the publication of a scheduled topic on behalf of
+// userID, who must be its author or a moderator
+func (s *topicService) RescheduleTopic(ctx context.Context, id, userID int64, publishAt time.Time) (*entity.Topic, error) {
+	if !publishAt.After(time.Now()) {
+		return nil, ErrPublishAtInPast
+	}
+	if _, err := s.scheduledTopic(ctx, id, userID, policy.ActionUpdate); err != nil {
+		return nil, err
+	}
+	// Публикатор мог успеть опубликовать тему, тогда ее не найти
+	if err := s.topicRepo.RescheduleTopic(ctx, id, publishAt); err != nil {
+		return nil, err
+	}
+	return s.GetScheduledTopic(ctx, id, userID)
+}
+
+// CancelScheduledTopic withdraws a scheduled topic on behalf of userID, who
+// must be its author or a moderator. The topic goes to the trash like a
+// deleted one.
+func (s *topicService) CancelScheduledTopic(ctx context.Context, id, userID int64) error {
+	topic, err := s.scheduledTopic(ctx, id, userID, policy.ActionDelete)
+	if err != nil {
+		return err
+	}
+	if err := s.topicRepo.DeleteTopic(ctx, id, userID); err != nil {
+		return err
+	}
+	s.notifier.Moderated(ctx, topicTarget(id), topic.AuthorID, userID, entity.ModerationDelete, "")
+	return nil
+}
+
+// scheduledTopic returns a scheduled topic if userID may perform the action
+// on it. Other users can't tell the topic from a missing one.
+func (s *topicService) scheduledTopic(ctx context.Context, id, userID int64, action policy.Action) (*entity.Topic, error) {
+	topic, err := s.topicRepo.GetScheduledTopic(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.policy.Authorize(ctx, userID, policy.ResourceTopic, action, topic.AuthorID); err != nil {
+		if errors.Is(err, policy.ErrUnauthenticated) || errors.Is(err, policy.ErrForbidden) {
+			return nil, repository.ErrTopicNotFound
+		}
+		return nil, err
+	}
+	return topic, nil
 }
 
-func (s *topicService) DeleteTopic(ctx context.Context, id int64) error {
-	return s.topicRepo.DeleteTopic(ctx, id)
+// checkExpiry rejects a flag that would already have lapsed
+func checkExpiry(until *time.Time) error {
+	if until != nil && !until.After(time.Now()) {
+		return ErrExpiryInPast
+	}
+	return nil
 }
 
 func (s *topicService) UpdateCommentCount(ctx context.Context, topicID int64) error {
 	return s.topicRepo.UpdateCommentCount(ctx, topicID)
 }
+
+// lookupCategory returns the category a topic is being filed under,
+// or ErrUnknownCategory if it doesn't exist.
+func (s *topicService) lookupCategory(ctx context.Context, id int64) (*entity.Category, error) {
+	return lookupCategory(ctx, s.categoryRepo, id)
+}
+
+func lookupCategory(ctx context.Context, categoryRepo repository.CategoryRepository, id int64) (*entity.Category, error) {
+	if id <= 0 {
+		return nil, ErrUnknownCategory
+	}
+	category, err := categoryRepo.GetCategoryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return nil, ErrUnknownCategory
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// attachCategories fills in Category for each topic, loading every
+// category only once.
+func (s *topicService) attachCategories(ctx context.Context, topics []*entity.Topic) error {
+	categories := make(map[int64]*entity.Category)
+	for _, topic := range topics {
+		if topic.CategoryID <= 0 {
+			continue
+		}
+		category, ok := categories[topic.CategoryID]
+		if !ok {
+			var err error
+			category, err = s.categoryRepo.GetCategoryByID(ctx, topic.CategoryID)
+			if err != nil && !errors.Is(err, repository.ErrCategoryNotFound) {
+				return err
+			}
+			categories[topic.CategoryID] = category
+		}
+		topic.Category = category
+	}
+	return nil
+}
+
+// attachTags fills in Tags for each topic with a single query
+func (s *topicService) attachTags(ctx context.Context, topics []*entity.Topic) error {
+	if len(topics) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(topics))
+	for i, topic := range topics {
+		ids[i] = topic.ID
+	}
+	tags, err := s.tagRepo.GetTagsByTopicIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, topic := range topics {
+		topic.Tags = tags[topic.ID]
+		if topic.Tags == nil {
+			topic.Tags = []*entity.Tag{}
+		}
+	}
+	return nil
+}
+
+func topicTarget(id int64) entity.NotificationTarget {
+	return entity.NotificationTarget{TargetType: entity.NotificationTargetTopic, TargetID: id, TopicID: id}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -27,29 +29,88 @@ func (m *mockTopicRepo) GetTopicByID(ctx context.Context, id int64) (*entity.Top
 	return args.Get(0).(*entity.Topic), args.Error(1)
 }
 
-func (m *mockTopicRepo) GetAllTopics(ctx context.Context) ([]*entity.Topic, error) {
-	args := m.Called(ctx)
+func (m *mockTopicRepo) GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*entity.Topic), args.Error(1)
+	return args.Get(0).(*entity.TopicPage), args.Error(1)
 }
 
-func (m *mockTopicRepo) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
-	args := m.Called(ctx, topic)
+func (m *mockTopicRepo) UpdateTopic(ctx context.Context, topic *entity.Topic, editorID int64) error {
+	args := m.Called(ctx, topic, editorID)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepo) DeleteTopic(ctx context.Context, id, deletedBy int64) error {
+	args := m.Called(ctx, id, deletedBy)
 	return args.Error(0)
 }
 
-func (m *mockTopicRepo) DeleteTopic(ctx context.Context, id int64) error {
+func (m *mockTopicRepo) RestoreTopic(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *mockTopicRepo) SetPin(ctx context.Context, id int64, scope entity.PinScope, until *time.Time) error {
+	args := m.Called(ctx, id, scope, until)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepo) SetLock(ctx context.Context, id int64, locked bool, until *time.Time) error {
+	args := m.Called(ctx, id, locked, until)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepo) SetAnnouncement(ctx context.Context, id int64, announcement bool, until *time.Time) error {
+	args := m.Called(ctx, id, announcement, until)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepo) GetScheduledTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Topic), args.Error(1)
+}
+
+func (m *mockTopicRepo) GetScheduledTopics(ctx context.Context, authorID int64) ([]*entity.Topic, error) {
+	args := m.Called(ctx, authorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Topic), args.Error(1)
+}
+
+func (m *mockTopicRepo) RescheduleTopic(ctx context.Context, id int64, publishAt time.Time) error {
+	args := m.Called(ctx, id, publishAt)
+	return args.Error(0)
+}
+
+func (m *mockTopicRepo) PublishDueTopics(ctx context.Context, now time.Time, limit int) ([]*entity.Topic, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Topic), args.Error(1)
+}
+
+func (m *mockTopicRepo) PurgeDeletedTopics(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *mockTopicRepo) UpdateCommentCount(ctx context.Context, topicID int64) error {
 	args := m.Called(ctx, topicID)
 	return args.Error(0)
 }
 
+func (m *mockTopicRepo) AddViews(ctx context.Context, views map[int64]int) error {
+	args := m.Called(ctx, views)
+	return args.Error(0)
+}
+
 type mockUserRepo struct {
 	mock.Mock
 }
@@ -59,6 +120,11 @@ func (m *mockUserRepo) GetUsernameByID(ctx context.Context, id int64) (string, e
 	return args.String(0), args.Error(1)
 }
 
+func (m *mockUserRepo) GetUserRole(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
 func (m *mockUserRepo) GetUserByID(ctx context.Context, id int64) (*entity.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -70,7 +136,9 @@ func (m *mockUserRepo) GetUserByID(ctx context.Context, id int64) (*entity.User,
 func TestTopicService_CreateTopic(t *testing.T) {
 	mockTopicRepo := new(mockTopicRepo)
 	mockUserRepo := new(mockUserRepo)
-	topicService := NewTopicService(mockTopicRepo, mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
 
 	topic := &entity.Topic{
 		Title:        "Test Topic",
@@ -83,17 +151,47 @@ func TestTopicService_CreateTopic(t *testing.T) {
 		UpdatedAt:    time.Now(),
 	}
 
+	category := &entity.Category{ID: 1, Name: "General"}
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(category, nil)
 	mockTopicRepo.On("CreateTopic", mock.Anything, topic).Return(nil)
 
 	err := topicService.CreateTopic(context.Background(), topic)
 	assert.NoError(t, err)
+	assert.Equal(t, category, topic.Category)
 	mockTopicRepo.AssertExpectations(t)
+	mockCategoryRepo.AssertExpectations(t)
+}
+
+func TestTopicService_CreateTopic_UnknownCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		categoryID int64
+	}{
+		{name: "missing", categoryID: 0},
+		{name: "not found", categoryID: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTopicRepo := new(mockTopicRepo)
+			mockCategoryRepo := new(mockCategoryRepo)
+			topicService := NewTopicService(mockTopicRepo, new(mockUserRepo), mockCategoryRepo, new(mockTagRepo), nil)
+
+			mockCategoryRepo.On("GetCategoryByID", mock.Anything, tt.categoryID).Return(nil, repository.ErrCategoryNotFound)
+
+			err := topicService.CreateTopic(context.Background(), &entity.Topic{Title: "Test", Content: "Test", CategoryID: tt.categoryID})
+			assert.ErrorIs(t, err, ErrUnknownCategory)
+			mockTopicRepo.AssertNotCalled(t, "CreateTopic", mock.Anything, mock.Anything)
+		})
+	}
 }
 
 func TestTopicService_GetTopicByID(t *testing.T) {
 	mockTopicRepo := new(mockTopicRepo)
 	mockUserRepo := new(mockUserRepo)
-	topicService := NewTopicService(mockTopicRepo, mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
 
 	expectedTopic := &entity.Topic{
 		ID:           1,
@@ -108,17 +206,24 @@ func TestTopicService_GetTopicByID(t *testing.T) {
 	}
 
 	mockTopicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(expectedTopic, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Username: "user1"}, nil)
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(&entity.Category{ID: 1, Name: "General"}, nil)
+	mockTagRepo.On("GetTagsByTopicIDs", mock.Anything, []int64{1}).Return(map[int64][]*entity.Tag{1: {{ID: 1, Name: "golang"}}}, nil)
 
 	topic, err := topicService.GetTopicByID(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedTopic, topic)
+	assert.Equal(t, "General", topic.Category.Name)
+	assert.Equal(t, "golang", topic.Tags[0].Name)
 	mockTopicRepo.AssertExpectations(t)
 }
 
 func TestTopicService_GetAllTopics(t *testing.T) {
 	mockTopicRepo := new(mockTopicRepo)
 	mockUserRepo := new(mockUserRepo)
-	topicService := NewTopicService(mockTopicRepo, mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
 
 	expectedTopics := []*entity.Topic{
 		{
@@ -145,18 +250,33 @@ func TestTopicService_GetAllTopics(t *testing.T) {
 		},
 	}
 
-	mockTopicRepo.On("GetAllTopics", mock.Anything).Return(expectedTopics, nil)
+	filter := entity.TopicFilter{Sort: entity.TopicSortViews, CategoryID: 1, Limit: 2}
+	expectedPage := &entity.TopicPage{Topics: expectedTopics, NextCursor: "next"}
+	mockTopicRepo.On("GetAllTopics", mock.Anything, filter).Return(expectedPage, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Username: "user1"}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(2)).Return(&entity.User{ID: 2, Username: "user2"}, nil)
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(&entity.Category{ID: 1, Name: "General"}, nil).Once()
+	mockTagRepo.On("GetTagsByTopicIDs", mock.Anything, []int64{1, 2}).Return(map[int64][]*entity.Tag{2: {{ID: 1, Name: "golang"}}}, nil)
 
-	topics, err := topicService.GetAllTopics(context.Background())
+	page, err := topicService.GetAllTopics(context.Background(), filter)
 	assert.NoError(t, err)
-	assert.Equal(t, expectedTopics, topics)
+	assert.Equal(t, expectedPage, page)
+	assert.Equal(t, "next", page.NextCursor)
+	assert.Equal(t, "user1", page.Topics[0].Author.Username)
+	assert.Equal(t, "user2", page.Topics[1].Author.Username)
+	assert.Same(t, page.Topics[0].Category, page.Topics[1].Category)
+	assert.Empty(t, page.Topics[0].Tags)
+	assert.Len(t, page.Topics[1].Tags, 1)
 	mockTopicRepo.AssertExpectations(t)
+	mockCategoryRepo.AssertExpectations(t)
 }
 
 func TestTopicService_UpdateTopic(t *testing.T) {
 	mockTopicRepo := new(mockTopicRepo)
 	mockUserRepo := new(mockUserRepo)
-	topicService := NewTopicService(mockTopicRepo, mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
 
 	topic := &entity.Topic{
 		ID:           1,
@@ -170,29 +290,154 @@ func TestTopicService_UpdateTopic(t *testing.T) {
 		UpdatedAt:    time.Now(),
 	}
 
-	mockTopicRepo.On("UpdateTopic", mock.Anything, topic).Return(nil)
+	mockTopicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 1, CategoryID: 1}, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(1)).Return(entity.RoleUser, nil)
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(2)).Return(&entity.Category{ID: 2}, nil)
+	mockTopicRepo.On("UpdateTopic", mock.Anything, topic, int64(1)).Return(nil)
+	mockTagRepo.On("GetTagsByTopicIDs", mock.Anything, []int64{1}).Return(map[int64][]*entity.Tag{}, nil)
 
-	err := topicService.UpdateTopic(context.Background(), topic)
+	err := topicService.UpdateTopic(context.Background(), topic, 1)
 	assert.NoError(t, err)
 	mockTopicRepo.AssertExpectations(t)
 }
 
+func TestTopicService_UpdateTopic_KeepsCategory(t *testing.T) {
+	mockTopicRepo := new(mockTopicRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	mockUserRepo := new(mockUserRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
+
+	topic := &entity.Topic{ID: 1, Title: "Updated Topic", Content: "Updated Content", TagNames: []string{}}
+
+	// Модератор правит чужую тему
+	mockTopicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 5, CategoryID: 3}, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(2)).Return(entity.RoleModerator, nil)
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(3)).Return(&entity.Category{ID: 3}, nil)
+	// Пустой список снимает теги в той же транзакции, что и правка
+	mockTopicRepo.On("UpdateTopic", mock.Anything, topic, int64(2)).Run(func(args mock.Arguments) {
+		updated := args.Get(1).(*entity.Topic)
+		assert.Equal(t, []string{}, updated.TagNames)
+		updated.Tags = []*entity.Tag{}
+	}).Return(nil)
+
+	err := topicService.UpdateTopic(context.Background(), topic, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), topic.CategoryID)
+	assert.Equal(t, int64(5), topic.AuthorID)
+	assert.Empty(t, topic.Tags)
+	assert.Nil(t, topic.TagNames)
+	mockTopicRepo.AssertExpectations(t)
+}
+
+func TestTopicService_CreateTopic_Tags(t *testing.T) {
+	mockTopicRepo := new(mockTopicRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, new(mockUserRepo), mockCategoryRepo, mockTagRepo, nil)
+
+	topic := &entity.Topic{Title: "Test", Content: "Test", CategoryID: 1, TagNames: []string{"Go  Modules", "go-modules", "Postgres"}}
+	tags := []*entity.Tag{{ID: 1, Name: "go-modules"}, {ID: 2, Name: "postgres"}}
+
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(&entity.Category{ID: 1}, nil)
+	// Репозиторий получает нормализованные теги и сохраняет их вместе с темой
+	mockTopicRepo.On("CreateTopic", mock.Anything, topic).Run(func(args mock.Arguments) {
+		created := args.Get(1).(*entity.Topic)
+		assert.Equal(t, []string{"go-modules", "postgres"}, created.TagNames)
+		created.ID = 10
+		created.Tags = tags
+	}).Return(nil)
+
+	err := topicService.CreateTopic(context.Background(), topic)
+	assert.NoError(t, err)
+	assert.Equal(t, tags, topic.Tags)
+	assert.Nil(t, topic.TagNames)
+}
+
+func TestTopicService_CreateTopic_InvalidTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		wantErr error
+	}{
+		{name: "too many", tags: []string{"a", "b", "c", "d", "e", "f"}, wantErr: ErrTooManyTags},
+		{name: "bad characters", tags: []string{"<script>"}, wantErr: ErrInvalidTag},
+		{name: "blank", tags: []string{"  "}, wantErr: ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTopicRepo := new(mockTopicRepo)
+			mockCategoryRepo := new(mockCategoryRepo)
+			topicService := NewTopicService(mockTopicRepo, new(mockUserRepo), mockCategoryRepo, new(mockTagRepo), nil)
+
+			mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(&entity.Category{ID: 1}, nil)
+
+			err := topicService.CreateTopic(context.Background(), &entity.Topic{Title: "Test", Content: "Test", CategoryID: 1, TagNames: tt.tags})
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockTopicRepo.AssertNotCalled(t, "CreateTopic", mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestTopicService_DeleteTopic(t *testing.T) {
 	mockTopicRepo := new(mockTopicRepo)
 	mockUserRepo := new(mockUserRepo)
-	topicService := NewTopicService(mockTopicRepo, mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
 
-	mockTopicRepo.On("DeleteTopic", mock.Anything, int64(1)).Return(nil)
+	mockTopicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 1}, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(1)).Return(entity.RoleUser, nil)
+	mockTopicRepo.On("DeleteTopic", mock.Anything, int64(1), int64(1)).Return(nil)
 
-	err := topicService.DeleteTopic(context.Background(), 1)
+	err := topicService.DeleteTopic(context.Background(), 1, 1)
 	assert.NoError(t, err)
 	mockTopicRepo.AssertExpectations(t)
 }
 
+func TestTopicService_Mutations_Forbidden(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(TopicService) error
+	}{
+		{
+			name: "update",
+			mutate: func(s TopicService) error {
+				return s.UpdateTopic(context.Background(), &entity.Topic{ID: 1, Title: "Title", Content: "Content"}, 2)
+			},
+		},
+		{
+			name: "delete",
+			mutate: func(s TopicService) error {
+				return s.DeleteTopic(context.Background(), 1, 2)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTopicRepo := new(mockTopicRepo)
+			mockUserRepo := new(mockUserRepo)
+			topicService := NewTopicService(mockTopicRepo, mockUserRepo, new(mockCategoryRepo), new(mockTagRepo), nil)
+
+			mockTopicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 1}, nil)
+			mockUserRepo.On("GetUserRole", mock.Anything, int64(2)).Return(entity.RoleUser, nil)
+
+			err := tt.mutate(topicService)
+			assert.ErrorIs(t, err, policy.ErrForbidden)
+			mockTopicRepo.AssertNotCalled(t, "UpdateTopic", mock.Anything, mock.Anything, mock.Anything)
+			mockTopicRepo.AssertNotCalled(t, "DeleteTopic", mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestTopicService_UpdateCommentCount(t *testing.T) {
 	mockTopicRepo := new(mockTopicRepo)
 	mockUserRepo := new(mockUserRepo)
-	topicService := NewTopicService(mockTopicRepo, mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
 
 	mockTopicRepo.On("UpdateCommentCount", mock.Anything, int64(1)).Return(nil)
 
@@ -200,3 +445,152 @@ func TestTopicService_UpdateCommentCount(t *testing.T) {
 	assert.NoError(t, err)
 	mockTopicRepo.AssertExpectations(t)
 }
+
+func TestTopicService_PinTopic(t *testing.T) {
+	mockTopicRepo := new(mockTopicRepo)
+	mockUserRepo := new(mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
+
+	until := time.Now().Add(time.Hour)
+	mockTopicRepo.On("SetPin", mock.Anything, int64(1), entity.PinScopeCategory, &until).Return(nil)
+	mockTopicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 1, CategoryID: 1, Pinned: entity.PinScopeCategory, PinnedUntil: &until}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Username: "user1"}, nil)
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(&entity.Category{ID: 1, Name: "General"}, nil)
+	mockTagRepo.On("GetTagsByTopicIDs", mock.Anything, []int64{1}).Return(map[int64][]*entity.Tag{}, nil)
+
+	topic, err := topicService.PinTopic(context.Background(), 1, entity.PinScopeCategory, &until)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.PinScopeCategory, topic.Pinned)
+	assert.Equal(t, "General", topic.Category.Name)
+
+	// Неизвестная область и истекший срок отклоняются до записи
+	_, err = topicService.PinTopic(context.Background(), 1, "everywhere", nil)
+	assert.ErrorIs(t, err, ErrInvalidPinScope)
+	past := time.Now().Add(-time.Minute)
+	_, err = topicService.PinTopic(context.Background(), 1, entity.PinScopeGlobal, &past)
+	assert.ErrorIs(t, err, ErrExpiryInPast)
+	mockTopicRepo.AssertNumberOfCalls(t, "SetPin", 1)
+}
+
+func TestTopicService_LockTopic(t *testing.T) {
+	mockTopicRepo := new(mockTopicRepo)
+	mockUserRepo := new(mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	notifier := new(mockNotifier)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, notifier)
+
+	mockTopicRepo.On("SetLock", mock.Anything, int64(1), true, (*time.Time)(nil)).Return(nil)
+	mockTopicRepo.On("GetTopicByID", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 1, CategoryID: 1, Locked: true}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Username: "user1"}, nil)
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(&entity.Category{ID: 1, Name: "General"}, nil)
+	mockTagRepo.On("GetTagsByTopicIDs", mock.Anything, []int64{1}).Return(map[int64][]*entity.Tag{}, nil)
+	notifier.On("Moderated", mock.Anything, topicTarget(1), int64(1), int64(5), entity.ModerationLock, "").Return()
+
+	topic, err := topicService.LockTopic(context.Background(), 1, 5, nil)
+	assert.NoError(t, err)
+	assert.True(t, topic.Locked)
+	notifier.AssertExpectations(t)
+
+	// Снятие закрытия не уведомляет автора
+	mockTopicRepo.On("SetLock", mock.Anything, int64(1), false, (*time.Time)(nil)).Return(nil)
+	_, err = topicService.UnlockTopic(context.Background(), 1)
+	assert.NoError(t, err)
+	notifier.AssertNumberOfCalls(t, "Moderated", 1)
+	mockTopicRepo.AssertExpectations(t)
+}
+
+func TestTopicService_CreateTopic_Scheduled(t *testing.T) {
+	mockTopicRepo := new(mockTopicRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	notifier := new(mockNotifier)
+	topicService := NewTopicService(mockTopicRepo, new(mockUserRepo), mockCategoryRepo, new(mockTagRepo), notifier)
+
+	// Время публикации в прошлом отклоняется
+	past := time.Now().Add(-time.Minute)
+	err := topicService.CreateTopic(context.Background(), &entity.Topic{Title: "Test", Content: "Test", CategoryID: 1, PublishAt: &past})
+	assert.ErrorIs(t, err, ErrPublishAtInPast)
+	mockTopicRepo.AssertNotCalled(t, "CreateTopic", mock.Anything, mock.Anything)
+
+	// Об упоминаниях в отложенной теме сообщают при публикации
+	future := time.Now().Add(time.Hour)
+	topic := &entity.Topic{Title: "Test", Content: "Hi @bob", AuthorID: 1, CategoryID: 1, PublishAt: &future}
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(&entity.Category{ID: 1, Name: "General"}, nil)
+	mockTopicRepo.On("CreateTopic", mock.Anything, topic).
+		Run(func(args mock.Arguments) { args.Get(1).(*entity.Topic).MentionedIDs = []int64{4} }).
+		Return(nil)
+
+	assert.NoError(t, topicService.CreateTopic(context.Background(), topic))
+	notifier.AssertNotCalled(t, "Mentioned", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTopicService_GetScheduledTopic(t *testing.T) {
+	mockTopicRepo := new(mockTopicRepo)
+	mockUserRepo := new(mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
+
+	publishAt := time.Now().Add(time.Hour)
+	mockTopicRepo.On("GetScheduledTopic", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 1, CategoryID: 1, PublishAt: &publishAt}, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(1)).Return(entity.RoleUser, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(2)).Return(entity.RoleUser, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(3)).Return(entity.RoleModerator, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Username: "user1"}, nil)
+	mockCategoryRepo.On("GetCategoryByID", mock.Anything, int64(1)).Return(&entity.Category{ID: 1, Name: "General"}, nil)
+	mockTagRepo.On("GetTagsByTopicIDs", mock.Anything, []int64{1}).Return(map[int64][]*entity.Tag{}, nil)
+
+	topic, err := topicService.GetScheduledTopic(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", topic.Author.Username)
+
+	_, err = topicService.GetScheduledTopic(context.Background(), 1, 3)
+	assert.NoError(t, err)
+
+	// Другие пользователи не узнают о запланированной теме
+	_, err = topicService.GetScheduledTopic(context.Background(), 1, 2)
+	assert.ErrorIs(t, err, repository.ErrTopicNotFound)
+	_, err = topicService.GetScheduledTopic(context.Background(), 1, 0)
+	assert.ErrorIs(t, err, repository.ErrTopicNotFound)
+}
+
+func TestTopicService_RescheduleTopic(t *testing.T) {
+	mockTopicRepo := new(mockTopicRepo)
+	mockUserRepo := new(mockUserRepo)
+	mockCategoryRepo := new(mockCategoryRepo)
+	mockTagRepo := new(mockTagRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, mockCategoryRepo, mockTagRepo, nil)
+
+	_, err := topicService.RescheduleTopic(context.Background(), 1, 1, time.Now().Add(-time.Minute))
+	assert.ErrorIs(t, err, ErrPublishAtInPast)
+
+	publishAt := time.Now().Add(24 * time.Hour)
+	mockTopicRepo.On("GetScheduledTopic", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 1, PublishAt: &publishAt}, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(1)).Return(entity.RoleUser, nil)
+	mockTopicRepo.On("RescheduleTopic", mock.Anything, int64(1), publishAt).Return(nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Username: "user1"}, nil)
+	mockTagRepo.On("GetTagsByTopicIDs", mock.Anything, []int64{1}).Return(map[int64][]*entity.Tag{}, nil)
+
+	topic, err := topicService.RescheduleTopic(context.Background(), 1, 1, publishAt)
+	assert.NoError(t, err)
+	assert.Equal(t, publishAt, *topic.PublishAt)
+	mockTopicRepo.AssertExpectations(t)
+}
+
+func TestTopicService_CancelScheduledTopic(t *testing.T) {
+	mockTopicRepo := new(mockTopicRepo)
+	mockUserRepo := new(mockUserRepo)
+	topicService := NewTopicService(mockTopicRepo, mockUserRepo, new(mockCategoryRepo), new(mockTagRepo), nil)
+
+	publishAt := time.Now().Add(time.Hour)
+	mockTopicRepo.On("GetScheduledTopic", mock.Anything, int64(1)).Return(&entity.Topic{ID: 1, AuthorID: 1, PublishAt: &publishAt}, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(1)).Return(entity.RoleUser, nil)
+	mockUserRepo.On("GetUserRole", mock.Anything, int64(2)).Return(entity.RoleUser, nil)
+	mockTopicRepo.On("DeleteTopic", mock.Anything, int64(1), int64(1)).Return(nil)
+
+	assert.ErrorIs(t, topicService.CancelScheduledTopic(context.Background(), 1, 2), repository.ErrTopicNotFound)
+	assert.NoError(t, topicService.CancelScheduledTopic(context.Background(), 1, 1))
+	mockTopicRepo.AssertNumberOfCalls(t, "DeleteTopic", 1)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+)
+
+// DefaultViewFlushInterval is used when no positive flush interval is given
+const DefaultViewFlushInterval = 10 * time.Second
+
+// ViewCounter counts topic views in memory and writes them to the database in
+// batches. A viewer is counted at most once per topic within the window.
+type ViewCounter struct {
+	topicRepo repository.TopicRepository
+	window    time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	seen    map[viewKey]time.Time
+	pending map[int64]int
+}
+
+type viewKey struct {
+	topicID int64
+	viewer  string
+}
+
+// NewViewCounter creates a ViewCounter that ignores repeated views of a topic
+// by the same viewer for the given window.
+func NewViewCounter(topicRepo repository.TopicRepository, window time.Duration) *ViewCounter {
+	return &ViewCounter{
+		topicRepo: topicRepo,
+		window:    window,
+		now:       time.Now,
+		seen:      make(map[viewKey]time.Time),
+		pending:   make(map[int64]int),
+	}
+}
+
+// ViewerKey identifies who is reading a topic: the user for signed-in
+// readers, otherwise the client IP and user agent.
+func ViewerKey(userID int64, ip, userAgent string) string {
+	if userID > 0 {
+		return fmt.Sprintf("u:%d", userID)
+	}
+	// Храним хеш, а не сам user agent, чтобы не раздувать память
+	h := fnv.New64a()
+	h.Write([]byte(ip))
+	h.Write([]byte{0})
+	h.Write([]byte(userAgent))
+	return fmt.Sprintf("a:%x", h.Sum64())
+}
+
+// RecordView counts a view of the topic unless the viewer has already been
+// counted within the window. It reports whether the view was counted.
+func (v *ViewCounter) RecordView(topicID int64, viewer string) bool {
+	now := v.now()
+	key := viewKey{topicID: topicID, viewer: viewer}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if last, ok := v.seen[key]; ok && now.Sub(last) < v.window {
+		return false
+	}
+	v.seen[key] = now
+	v.pending[topicID]++
+	return true
+}
+
+// Flush writes the buffered views to the database. Views that couldn't be
+// written are kept for the next flush.
+func (v *ViewCounter) Flush(ctx context.Context) error {
+	v.mu.Lock()
+	batch := v.pending
+	v.pending = make(map[int64]int)
+	v.pruneSeen()
+	v.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := v.topicRepo.AddViews(ctx, batch); err != nil {
+		v.mu.Lock()
+		for topicID, n := range batch {
+			v.pending[topicID] += n
+		}
+		v.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes buffered views every interval until the context is cancelled,
+// then flushes one last time.
+func (v *ViewCounter) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultViewFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := v.Flush(ctx); err != nil {
+				log.Printf("Error flushing topic views: %v", err)
+			}
+		case <-ctx.Done():
+			if err := v.Flush(context.Background()); err != nil {
+				log.Printf("Error flushing topic views: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// pruneSeen forgets viewers whose window has passed. Must be called with mu held.
+func (v *ViewCounter) pruneSeen() {
+	now := v.now()
+	for key, last := range v.seen {
+		if now.Sub(last) >= v.window {
+			delete(v.seen, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestViewCounter(window time.Duration) (*ViewCounter, *mockTopicRepo, *time.Time) {
+	repo := new(mockTopicRepo)
+	counter := NewViewCounter(repo, window)
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	counter.now = func() time.Time { return now }
+	return counter, repo, &now
+}
+
+func TestViewCounter_RecordView(t *testing.T) {
+	counter, repo, now := newTestViewCounter(30 * time.Minute)
+
+	user := ViewerKey(7, "10.0.0.1", "firefox")
+	assert.True(t, counter.RecordView(1, user))
+	assert.False(t, counter.RecordView(1, user))
+	// Другая тема считается отдельно
+	assert.True(t, counter.RecordView(2, user))
+
+	// Анонимные читатели различаются по IP и user agent
+	assert.True(t, counter.RecordView(1, ViewerKey(0, "10.0.0.1", "firefox")))
+	assert.False(t, counter.RecordView(1, ViewerKey(0, "10.0.0.1", "firefox")))
+	assert.True(t, counter.RecordView(1, ViewerKey(0, "10.0.0.1", "chrome")))
+
+	// После окна просмотр засчитывается снова
+	*now = now.Add(30 * time.Minute)
+	assert.True(t, counter.RecordView(1, user))
+
+	repo.On("AddViews", mock.Anything, map[int64]int{1: 4, 2: 1}).Return(nil).Once()
+	assert.NoError(t, counter.Flush(context.Background()))
+	repo.AssertExpectations(t)
+}
+
+func TestViewCounter_Flush(t *testing.T) {
+	counter, repo, now := newTestViewCounter(time.Minute)
+
+	// Пустой буфер не трогает базу
+	assert.NoError(t, counter.Flush(context.Background()))
+
+	counter.RecordView(1, "a")
+	counter.RecordView(1, "b")
+	repo.On("AddViews", mock.Anything, map[int64]int{1: 2}).Return(errors.New("db down")).Once()
+	assert.Error(t, counter.Flush(context.Background()))
+
+	// Несохраненные просмотры переносятся в следующий батч
+	counter.RecordView(1, "c")
+	repo.On("AddViews", mock.Anything, map[int64]int{1: 3}).Return(nil).Once()
+	assert.NoError(t, counter.Flush(context.Background()))
+	repo.AssertExpectations(t)
+
+	// Устаревшие записи о читателях удаляются при сбросе
+	*now = now.Add(time.Minute)
+	assert.NoError(t, counter.Flush(context.Background()))
+	assert.Empty(t, counter.seen)
+}
+
+func TestViewerKey(t *testing.T) {
+	assert.Equal(t, "u:7", ViewerKey(7, "10.0.0.1", "firefox"))
+	assert.Equal(t, ViewerKey(0, "10.0.0.1", "firefox"), ViewerKey(0, "10.0.0.1", "firefox"))
+	assert.NotEqual(t, ViewerKey(0, "10.0.0.1", "firefox"), ViewerKey(0, "10.0.0.2", "firefox"))
+}
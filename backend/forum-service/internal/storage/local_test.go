@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "attachments/2024/03/a.txt", strings.NewReader("hello"), 5, "text/plain"))
+	r, err := s.Open(ctx, "attachments/2024/03/a.txt")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "hello", string(body))
+
+	// Файл неполного размера не сохраняется
+	err = s.Put(ctx, "attachments/short.txt", strings.NewReader("hi"), 5, "text/plain")
+	assert.Error(t, err)
+	_, err = s.Open(ctx, "attachments/short.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Delete(ctx, "attachments/2024/03/a.txt"))
+	require.NoError(t, s.Delete(ctx, "attachments/2024/03/a.txt"))
+	_, err = s.Open(ctx, "attachments/2024/03/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestValidKey(t *testing.T) {
+	for _, key := range []string{"", "/etc/passwd", "a/../../b", "a//b", "./a", "a\\b", "a/"} {
+		assert.False(t, validKey(key), key)
+	}
+	for _, key := range []string{"a", "attachments/2024/03/5f0c.png", "a b/c.d"} {
+		assert.True(t, validKey(key), key)
+	}
+}
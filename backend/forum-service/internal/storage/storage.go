@@ -0,0 +1,40 @@
+// Package storage keeps uploaded files. Files are addressed by keys such as
+// "attachments/2024/03/5f0c.png"; a key never starts with a slash or
+// contains "." or ".." segments.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage is a place to keep uploaded files
+type Storage interface {
+	// Put saves size bytes read from r under the key, replacing any object
+	// already stored there
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the contents of the object; ErrNotFound if there is none
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey reports whether the key can be used by every backend
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+// Package textdiff builds line-based unified diffs of topic and comment text.
+package textdiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultContext is the number of unchanged lines shown around each change
+	DefaultContext = 3
+	// MaxLines is the longest text, in lines, that Unified diffs. Diffing two
+	// unrelated texts takes time quadratic in their length.
+	MaxLines = 5000
+)
+
+var ErrTooLarge = errors.New("text is too large to diff")
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// Unified returns a unified diff that turns a into b, labelled with fromName
+// and toName, or an empty string if the texts have the same lines. It
+// returns ErrTooLarge if either text has more than MaxLines lines.
+func Unified(fromName, toName, a, b string, context int) (string, error) {
+	if context < 0 {
+		context = 0
+	}
+	aLines, bLines := splitLines(a), splitLines(b)
+	if len(aLines) > MaxLines || len(bLines) > MaxLines {
+		return "", ErrTooLarge
+	}
+	ops := diffLines(aLines, bLines)
+
+	// Номера строк в a и b перед каждой операцией
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	var changes []int
+	for i, o := range ops {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if o.kind != '+' {
+			aLine[i+1]++
+		}
+		if o.kind != '-' {
+			bLine[i+1]++
+		}
+		if o.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for first := 0; first < len(changes); {
+		// Изменения, между которыми не больше 2*context общих строк, идут одним блоком
+		last := first
+		for last+1 < len(changes) && changes[last+1]-changes[last]-1 <= 2*context {
+			last++
+		}
+		start := max(changes[first]-context, 0)
+		end := min(changes[last]+context+1, len(ops))
+		writeHunk(&out, ops[start:end], aLine[start], aLine[end], bLine[start], bLine[end])
+		first = last + 1
+	}
+	return out.String(), nil
+}
+
+func writeHunk(out *strings.Builder, ops []op, aFrom, aTo, bFrom, bTo int) {
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aFrom, aTo), hunkRange(bFrom, bTo))
+	for _, o := range ops {
+		out.WriteByte(o.kind)
+		out.WriteString(o.text)
+		out.WriteByte('\n')
+	}
+}
+
+// hunkRange formats the lines [from, to) the way diff -u does: an empty
+// range points at the line before it
+func hunkRange(from, to int) string {
+	count := to - from
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", from)
+	case 1:
+		return fmt.Sprintf("%d", from+1)
+	default:
+		return fmt.Sprintf("%d,%d", from+1, count)
+	}
+}
+
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns a shortest edit script from a to b. It uses Myers'
+// linear space algorithm: O((n+m)·d) time for d changed lines and O(n+m)
+// memory however long the texts are.
+func diffLines(a, b []string) []op {
+	d := &differ{ops: make([]op, 0, len(a)+len(b))}
+	d.compare(a, b)
+	return d.ops
+}
+
+type differ struct {
+	ops []op
+	// Диагонали прямого и обратного поиска, переиспользуются между вызовами
+	forward, backward []int
+}
+
+func (d *differ) compare(a, b []string) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for _, line := range a[:prefix] {
+		d.ops = append(d.ops, op{' ', line})
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	tail := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			d.ops = append(d.ops, op{'+', line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			d.ops = append(d.ops, op{'-', line})
+		}
+	default:
+		if x, y, ok := d.split(a, b); ok {
+			d.compare(a[:x], b[:y])
+			d.compare(a[x:], b[y:])
+			break
+		}
+		// Общих строк нет: весь фрагмент заменяется
+		for _, line := range a {
+			d.ops = append(d.ops, op{'-', line})
+		}
+		for _, line := range b {
+			d.ops = append(d.ops, op{'+', line})
+		}
+	}
+
+	for _, line := range tail {
+		d.ops = append(d.ops, op{' ', line})
+	}
+}
+
+// split finds the middle snake of a shortest edit script from a to b and
+// returns the point where it ends. It reports false when a and b have no
+// line in common. Both a and b must be non-empty and differ in their first
+// and last lines.
+func (d *differ) split(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	size := 2*maxD + 3
+	if cap(d.forward) < size {
+		d.forward = make([]int, size)
+		d.backward = make([]int, size)
+	}
+	vf, vb := d.forward[:size], d.backward[:size]
+	for i := range vf {
+		vf[i], vb[i] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+
+	delta := n - m
+	odd := delta%2 != 0
+	// Диагонали, ушедшие за край таблицы, дальше не просматриваются
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for k := 0; k < maxD; k++ {
+		for kf := -k + fStart; kf <= k-fEnd; kf += 2 {
+			i := offset + kf
+			var x int
+			if kf == -k || (kf != k && vf[i-1] < vf[i+1]) {
+				x = vf[i+1]
+			} else {
+				x = vf[i-1] + 1
+			}
+			y := x - kf
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if j := offset + delta - kf; j >= 0 && j < size && vb[j] != -1 && x >= n-vb[j] {
+					return x, y, true
+				}
+			}
+		}
+
+		for kb := -k + bStart; kb <= k-bEnd; kb += 2 {
+			i := offset + kb
+			var x int
+			if kb == -k || (kb != k && vb[i-1] < vb[i+1]) {
+				x = vb[i+1]
+			} else {
+				x = vb[i-1] + 1
+			}
+			y := x - kb
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			vb[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if j := offset + delta - kb; j >= 0 && j < size && vf[j] != -1 {
+					fx := vf[j]
+					if fx >= n-x {
+						return fx, fx - (delta - kb), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,174 @@
+package textdiff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{
+			name: "same text",
+			a:    "one\ntwo",
+			b:    "one\ntwo\n",
+			want: "",
+		},
+		{
+			name:    "changed line",
+			a:       "one\ntwo\nthree",
+			b:       "one\n2\nthree",
+			context: 3,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		},
+		{
+			name:    "added to empty",
+			a:       "",
+			b:       "hello",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -0,0 +1 @@\n+hello\n",
+		},
+		{
+			name:    "removed everything",
+			a:       "hello\nworld",
+			b:       "",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-hello\n-world\n",
+		},
+		{
+			name:    "distant changes make two hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8",
+			b:       "one\n2\n3\n4\n5\n6\n7\neight",
+			context: 1,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,2 +1,2 @@\n-1\n+one\n 2\n" +
+				"@@ -7,2 +7,2 @@\n 7\n-8\n+eight\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			a:       "1\n2\n3\n4",
+			b:       "one\n2\n3\nfour",
+			context: 1,
+			want: "--- a\n+++ b\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n-4\n+four\n",
+		},
+		{
+			name:    "inserted line",
+			a:       "a\nc",
+			b:       "a\nb\nc",
+			context: 0,
+			want:    "--- a\n+++ b\n@@ -1,0 +2 @@\n+b\n",
+		},
+		{
+			name:    "windows line endings",
+			a:       "one\r\ntwo",
+			b:       "one\ntwo",
+			context: 3,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := Unified("a", "b", tt.a, tt.b, tt.context)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, diff)
+		})
+	}
+}
+
+func TestUnified_TooLarge(t *testing.T) {
+	long := strings.Repeat("line\n", MaxLines+1)
+
+	_, err := Unified("a", "b", long, "short", DefaultContext)
+	assert.ErrorIs(t, err, ErrTooLarge)
+	_, err = Unified("a", "b", "short", long, DefaultContext)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestUnified_LargeInput(t *testing.T) {
+	a := make([]string, MaxLines)
+	b := make([]string, MaxLines)
+	for i := range a {
+		a[i] = fmt.Sprintf("line %d", i)
+		b[i] = fmt.Sprintf("other %d", i)
+	}
+
+	// Совсем разные тексты — худший случай для диффа
+	start := time.Now()
+	diff, err := Unified("a", "b", strings.Join(a, "\n"), strings.Join(b, "\n"), DefaultContext)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*MaxLines, strings.Count(diff, "\n")-3)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// Редкие правки в длинном тексте
+	copy(b, a)
+	for i := 100; i < len(b); i += 1000 {
+		b[i] = "changed"
+	}
+	diff, err = Unified("a", "b", strings.Join(a, "\n"), strings.Join(b, "\n"), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, MaxLines/1000, strings.Count(diff, "+changed\n"))
+	assert.Equal(t, MaxLines/1000, strings.Count(diff, "@@ -"))
+}
+
+func TestDiffLines_Shortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(3)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 2000; i++ {
+		a, b := randomLines(), randomLines()
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, o := range ops {
+			if o.kind != '+' {
+				gotA = append(gotA, o.text)
+			}
+			if o.kind != '-' {
+				gotB = append(gotB, o.text)
+			}
+			if o.kind != ' ' {
+				changes++
+			}
+		}
+		assert.Equal(t, strings.Join(a, ","), strings.Join(gotA, ","))
+		assert.Equal(t, strings.Join(b, ","), strings.Join(gotB, ","))
+		assert.Equal(t, len(a)+len(b)-2*lcsLength(a, b), changes, "a=%v b=%v", a, b)
+	}
+}
+
+// lcsLength is the textbook quadratic LCS the diff is checked against
+func lcsLength(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	return dp[0][0]
+}
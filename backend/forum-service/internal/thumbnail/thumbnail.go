@@ -0,0 +1,111 @@
+// Package thumbnail makes small JPEG previews of uploaded images
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+
+	// Поддерживаемые форматы регистрируются в image.Decode
+	_ "image/gif"
+	_ "image/png"
+)
+
+// MaxPixels bounds the size of images that are decoded, so a small file
+// can't unpack into a huge bitmap
+const MaxPixels = 24_000_000
+
+// MaxConcurrent is how many images are decoded at once. Every decoded image
+// takes up to 4×MaxPixels bytes, and uploads wait for a free slot.
+const MaxConcurrent = 4
+
+var slots = make(chan struct{}, MaxConcurrent)
+
+var ErrTooLarge = errors.New("image is too large")
+
+// Supported reports whether previews can be made for a content type
+func Supported(contentType string) bool {
+	switch contentType {
+	case "image/png", "image/jpeg", "image/gif":
+		return true
+	}
+	return false
+}
+
+// Generate decodes an image and returns a JPEG that fits into a size×size
+// square. Smaller images keep their size. Transparent areas become white.
+func Generate(data []byte, size int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	slots <- struct{}{}
+	defer func() { <-slots }()
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, scale(src, size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encode(w io.Writer, img image.Image) error {
+	if err := jpeg.Encode(w, img, &jpeg.Options{Quality: 85}); err != nil {
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return nil
+}
+
+// scale shrinks an image to fit into a size×size square by averaging the
+// source pixels that fall into every target pixel
+func scale(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/b.Dx())
+		} else {
+			w, h = max(1, w*size/b.Dy()), size
+		}
+	}
+
+	// Исходные строки для каждой строки превью кладутся на белый фон, чтобы
+	// прозрачность не стала черной. Полосы хватает на самую высокую из них,
+	// так что копия всей картинки не нужна.
+	strip := image.NewRGBA(image.Rect(0, 0, b.Dx(), (b.Dy()+h-1)/h))
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*b.Dy()/h, max((y+1)*b.Dy()/h, y*b.Dy()/h+1)
+		rows := image.Rect(0, 0, b.Dx(), y1-y0)
+		draw.Draw(strip, rows, image.White, image.Point{}, draw.Src)
+		draw.Draw(strip, rows, src, image.Pt(b.Min.X, b.Min.Y+y0), draw.Over)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*b.Dx()/w, max((x+1)*b.Dx()/w, x*b.Dx()/w+1)
+			var r, g, bl, n uint32
+			for sy := 0; sy < y1-y0; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := strip.RGBAAt(sx, sy)
+					r += uint32(c.R)
+					g += uint32(c.G)
+					bl += uint32(c.B)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: 255})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,79 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pngImage(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantW, wantH  int
+	}{
+		{"landscape", 800, 400, 320, 160},
+		{"portrait", 300, 900, 106, 320},
+		{"small", 100, 50, 100, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Generate(pngImage(t, tt.width, tt.height, color.NRGBA{R: 200, A: 255}), 320)
+			require.NoError(t, err)
+			img, err := jpeg.Decode(bytes.NewReader(data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantW, img.Bounds().Dx())
+			assert.Equal(t, tt.wantH, img.Bounds().Dy())
+		})
+	}
+}
+
+func TestGenerate_Transparent(t *testing.T) {
+	data, err := Generate(pngImage(t, 10, 10, color.NRGBA{}), 320)
+	require.NoError(t, err)
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	r, g, b, _ := img.At(5, 5).RGBA()
+	assert.Greater(t, r>>8, uint32(240))
+	assert.Greater(t, g>>8, uint32(240))
+	assert.Greater(t, b>>8, uint32(240))
+}
+
+func TestGenerate_TooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.White}), nil))
+	data := buf.Bytes()
+	// Заголовок GIF обещает картинку 6000×5000, больше MaxPixels
+	binary.LittleEndian.PutUint16(data[6:], 6000)
+	binary.LittleEndian.PutUint16(data[8:], 5000)
+
+	_, err := Generate(data, 320)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestGenerate_Invalid(t *testing.T) {
+	_, err := Generate([]byte("not an image"), 320)
+	assert.Error(t, err)
+	assert.True(t, Supported("image/png"))
+	assert.False(t, Supported("image/webp"))
+}
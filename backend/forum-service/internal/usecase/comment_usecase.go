@@ -2,32 +2,63 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
+	"github.com/sout1235/forum2/backend/forum-service/internal/service"
 )
 
 type CommentUseCase interface {
-	GetCommentsByTopicID(ctx context.Context, topicID int64) ([]*entity.Comment, error)
-	GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error)
+	GetCommentsByTopicID(ctx context.Context, topicID, viewerID int64) ([]*entity.Comment, error)
+	GetCommentByID(ctx context.Context, id, viewerID int64) (*entity.Comment, error)
 	CreateComment(ctx context.Context, comment *entity.Comment) error
-	DeleteComment(ctx context.Context, id int64) error
-	LikeComment(ctx context.Context, id int64) error
+	UpdateComment(ctx context.Context, id, userID int64, content string) (*entity.Comment, error)
+	DeleteComment(ctx context.Context, id, userID int64) error
+	RestoreComment(ctx context.Context, id int64) error
+	LikeComment(ctx context.Context, id, userID int64) (int, error)
+	UnlikeComment(ctx context.Context, id, userID int64) (int, error)
+	GetCommentLikes(ctx context.Context, id int64, cursor string, limit int) (*entity.CommentLikePage, error)
+	GetCommentTree(ctx context.Context, query entity.CommentTreeQuery) (*entity.CommentTree, error)
 }
 
+var (
+	ErrInvalidParentComment = errors.New("parent comment belongs to another topic")
+	ErrEmptyComment         = errors.New("comment content is empty")
+	ErrEditWindowExpired    = errors.New("comment can no longer be edited")
+)
+
 type commentUseCase struct {
 	commentRepo repository.CommentRepository
 	userRepo    repository.UserRepository
+	editWindow  time.Duration
+	notifier    service.Notifier
+	policy      *policy.Policy
 }
 
-func NewCommentUseCase(commentRepo repository.CommentRepository, userRepo repository.UserRepository) CommentUseCase {
+// NewCommentUseCase creates a comment use case. Authors may edit their
+// comments for editWindow after posting, moderators at any time; zero lifts
+// the limit. notifier is told about new comments, likes, mentions and
+// moderators deleting comments; nil turns that off.
+func NewCommentUseCase(commentRepo repository.CommentRepository, userRepo repository.UserRepository, editWindow time.Duration, notifier service.Notifier) CommentUseCase {
+	if notifier == nil {
+		notifier = service.NopNotifier
+	}
 	return &commentUseCase{
 		commentRepo: commentRepo,
 		userRepo:    userRepo,
+		editWindow:  editWindow,
+		notifier:    notifier,
+		policy:      policy.New(userRepo),
 	}
 }
 
-func (uc *commentUseCase) GetCommentsByTopicID(ctx context.Context, topicID int64) ([]*entity.Comment, error) {
+// GetCommentsByTopicID returns the comments of a topic; viewerID, when not
+// zero, is used to fill in LikedByMe.
+func (uc *commentUseCase) GetCommentsByTopicID(ctx context.Context, topicID, viewerID int64) ([]*entity.Comment, error) {
 	comments, err := uc.commentRepo.GetCommentsByTopic(ctx, topicID)
 	if err != nil {
 		return nil, err
@@ -43,10 +74,14 @@ func (uc *commentUseCase) GetCommentsByTopicID(ctx context.Context, topicID int6
 		}
 	}
 
+	if err := uc.markLiked(ctx, viewerID, comments); err != nil {
+		return nil, err
+	}
+
 	return comments, nil
 }
 
-func (uc *commentUseCase) GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error) {
+func (uc *commentUseCase) GetCommentByID(ctx context.Context, id, viewerID int64) (*entity.Comment, error) {
 	comment, err := uc.commentRepo.GetCommentByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -60,14 +95,32 @@ func (uc *commentUseCase) GetCommentByID(ctx context.Context, id int64) (*entity
 		comment.Author = author
 	}
 
+	if err := uc.markLiked(ctx, viewerID, []*entity.Comment{comment}); err != nil {
+		return nil, err
+	}
+
 	return comment, nil
 }
 
 func (uc *commentUseCase) CreateComment(ctx context.Context, comment *entity.Comment) error {
+	if comment.ParentID != nil {
+		parent, err := uc.commentRepo.GetCommentByID(ctx, *comment.ParentID)
+		if err != nil {
+			if errors.Is(err, repository.ErrCommentNotFound) {
+				return ErrInvalidParentComment
+			}
+			return err
+		}
+		if parent.TopicID != comment.TopicID || parent.Deleted {
+			return ErrInvalidParentComment
+		}
+	}
+
 	err := uc.commentRepo.CreateComment(ctx, comment)
 	if err != nil {
 		return err
 	}
+	uc.notifier.CommentCreated(ctx, comment)
 
 	if comment.AuthorID > 0 && (comment.Author == nil || comment.Author.Username == "") {
 		author, err := uc.userRepo.GetUserByID(ctx, comment.AuthorID)
@@ -80,10 +133,181 @@ func (uc *commentUseCase) CreateComment(ctx context.Context, comment *entity.Com
 	return nil
 }
 
-func (uc *commentUseCase) DeleteComment(ctx context.Context, id int64) error {
-	return uc.commentRepo.DeleteComment(ctx, id)
+// UpdateComment replaces the content of a comment on behalf of userID, who
+// must be its author or a moderator.
+func (uc *commentUseCase) UpdateComment(ctx context.Context, id, userID int64, content string) (*entity.Comment, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, ErrEmptyComment
+	}
+
+	comment, err := uc.commentRepo.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Deleted {
+		return nil, repository.ErrCommentNotFound
+	}
+	actor, err := uc.policy.Authorize(ctx, userID, policy.ResourceComment, policy.ActionUpdate, comment.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsStaff() && uc.editWindow > 0 && time.Since(comment.CreatedAt) > uc.editWindow {
+		return nil, ErrEditWindowExpired
+	}
+
+	comment.Content = content
+	if err := uc.commentRepo.UpdateComment(ctx, comment, userID); err != nil {
+		return nil, err
+	}
+	uc.notifier.Mentioned(ctx, commentTarget(comment), comment.AuthorID, comment.MentionedIDs)
+
+	if comment.AuthorID > 0 && (comment.Author == nil || comment.Author.Username == "") {
+		author, err := uc.userRepo.GetUserByID(ctx, comment.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		comment.Author = author
+	}
+
+	return comment, nil
+}
+
+// DeleteComment deletes a comment on behalf of userID, who must be its author
+// or a moderator. The comment stays in its thread as a placeholder.
+func (uc *commentUseCase) DeleteComment(ctx context.Context, id, userID int64) error {
+	comment, err := uc.commentRepo.GetCommentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if comment.Deleted {
+		return repository.ErrCommentNotFound
+	}
+	if _, err := uc.policy.Authorize(ctx, userID, policy.ResourceComment, policy.ActionDelete, comment.AuthorID); err != nil {
+		return err
+	}
+	if err := uc.commentRepo.DeleteComment(ctx, id, userID); err != nil {
+		return err
+	}
+	uc.notifier.Moderated(ctx, commentTarget(comment), comment.AuthorID, userID, entity.ModerationDelete, "")
+	return nil
+}
+
+// RestoreComment brings back a deleted comment. Only moderators may call it;
+// the router enforces the role.
+func (uc *commentUseCase) RestoreComment(ctx context.Context, id int64) error {
+	return uc.commentRepo.RestoreComment(ctx, id)
 }
 
-func (uc *commentUseCase) LikeComment(ctx context.Context, id int64) error {
-	return uc.commentRepo.LikeComment(ctx, id)
+func (uc *commentUseCase) LikeComment(ctx context.Context, id, userID int64) (int, error) {
+	likes, liked, err := uc.commentRepo.LikeComment(ctx, id, userID)
+	if err != nil {
+		return 0, err
+	}
+	// Повторный лайк ничего не меняет, и автору о нем не сообщается
+	if liked {
+		uc.notifier.CommentLiked(ctx, id, userID)
+	}
+	return likes, nil
+}
+
+func (uc *commentUseCase) UnlikeComment(ctx context.Context, id, userID int64) (int, error) {
+	return uc.commentRepo.UnlikeComment(ctx, id, userID)
+}
+
+// GetCommentLikes lists the users who liked a comment, newest first
+func (uc *commentUseCase) GetCommentLikes(ctx context.Context, id int64, cursor string, limit int) (*entity.CommentLikePage, error) {
+	if _, err := uc.commentRepo.GetCommentByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return uc.commentRepo.GetCommentLikes(ctx, id, cursor, limit)
+}
+
+// GetCommentTree returns a part of a topic's comment tree. When a parent
+// comment is given its topic is taken from the comment itself.
+func (uc *commentUseCase) GetCommentTree(ctx context.Context, query entity.CommentTreeQuery) (*entity.CommentTree, error) {
+	if query.ParentID != nil {
+		parent, err := uc.commentRepo.GetCommentByID(ctx, *query.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		query.TopicID = parent.TopicID
+	}
+
+	tree, err := uc.commentRepo.GetCommentTree(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make(map[int64]*entity.User)
+	if err := uc.attachAuthors(ctx, tree.Comments, authors); err != nil {
+		return nil, err
+	}
+	if err := uc.markLiked(ctx, query.ViewerID, tree.Comments); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// attachAuthors walks the tree and loads authors that the query couldn't join
+func (uc *commentUseCase) attachAuthors(ctx context.Context, comments []*entity.Comment, authors map[int64]*entity.User) error {
+	for _, comment := range comments {
+		if comment.AuthorID > 0 && (comment.Author == nil || comment.Author.Username == "") {
+			author, ok := authors[comment.AuthorID]
+			if !ok {
+				var err error
+				author, err = uc.userRepo.GetUserByID(ctx, comment.AuthorID)
+				if err != nil {
+					return err
+				}
+				authors[comment.AuthorID] = author
+			}
+			comment.Author = author
+		}
+		if err := uc.attachAuthors(ctx, comment.Replies, authors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markLiked sets LikedByMe on the comments and all their loaded replies
+func (uc *commentUseCase) markLiked(ctx context.Context, viewerID int64, comments []*entity.Comment) error {
+	if viewerID <= 0 || len(comments) == 0 {
+		return nil
+	}
+
+	var ids []int64
+	var collect func([]*entity.Comment)
+	collect = func(comments []*entity.Comment) {
+		for _, comment := range comments {
+			if !comment.Deleted {
+				ids = append(ids, comment.ID)
+			}
+			collect(comment.Replies)
+		}
+	}
+	collect(comments)
+
+	liked, err := uc.commentRepo.GetLikedCommentIDs(ctx, viewerID, ids)
+	if err != nil {
+		return err
+	}
+
+	var mark func([]*entity.Comment)
+	mark = func(comments []*entity.Comment) {
+		for _, comment := range comments {
+			comment.LikedByMe = liked[comment.ID]
+			mark(comment.Replies)
+		}
+	}
+	mark(comments)
+	return nil
+}
+
+func commentTarget(comment *entity.Comment) entity.NotificationTarget {
+	return entity.NotificationTarget{
+		TargetType: entity.NotificationTargetComment,
+		TargetID:   comment.ID,
+		TopicID:    comment.TopicID,
+	}
 }
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
+	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -35,21 +38,85 @@ func (m *MockCommentRepository) CreateComment(ctx context.Context, comment *enti
 	return args.Error(0)
 }
 
-func (m *MockCommentRepository) UpdateComment(ctx context.Context, comment *entity.Comment) error {
-	args := m.Called(ctx, comment)
+func (m *MockCommentRepository) UpdateComment(ctx context.Context, comment *entity.Comment, editorID int64) error {
+	args := m.Called(ctx, comment, editorID)
 	return args.Error(0)
 }
 
-func (m *MockCommentRepository) DeleteComment(ctx context.Context, id int64) error {
-	args := m.Called(ctx, id)
+func (m *MockCommentRepository) DeleteComment(ctx context.Context, id, deletedBy int64) error {
+	args := m.Called(ctx, id, deletedBy)
 	return args.Error(0)
 }
 
-func (m *MockCommentRepository) LikeComment(ctx context.Context, id int64) error {
+func (m *MockCommentRepository) RestoreComment(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockCommentRepository) PurgeDeletedComments(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCommentRepository) LikeComment(ctx context.Context, commentID, userID int64) (int, bool, error) {
+	args := m.Called(ctx, commentID, userID)
+	return args.Int(0), args.Bool(1), args.Error(2)
+}
+
+func (m *MockCommentRepository) UnlikeComment(ctx context.Context, commentID, userID int64) (int, error) {
+	args := m.Called(ctx, commentID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentLikes(ctx context.Context, commentID int64, cursor string, limit int) (*entity.CommentLikePage, error) {
+	args := m.Called(ctx, commentID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CommentLikePage), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetLikedCommentIDs(ctx context.Context, userID int64, commentIDs []int64) (map[int64]bool, error) {
+	args := m.Called(ctx, userID, commentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]bool), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentTree(ctx context.Context, query entity.CommentTreeQuery) (*entity.CommentTree, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CommentTree), args.Error(1)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) CommentCreated(ctx context.Context, comment *entity.Comment) {
+	m.Called(ctx, comment)
+}
+
+func (m *MockNotifier) CommentLiked(ctx context.Context, commentID, userID int64) {
+	m.Called(ctx, commentID, userID)
+}
+
+func (m *MockNotifier) Mentioned(ctx context.Context, target entity.NotificationTarget, authorID int64, userIDs []int64) {
+	m.Called(ctx, target, authorID, userIDs)
+}
+
+func (m *MockNotifier) Moderated(ctx context.Context, target entity.NotificationTarget, authorID, moderatorID int64, action, note string) {
+	m.Called(ctx, target, authorID, moderatorID, action, note)
+}
+
+func (m *MockNotifier) BookmarkReminder(ctx context.Context, bookmark *entity.Bookmark) error {
+	args := m.Called(ctx, bookmark)
+	return args.Error(0)
+}
+
 type MockUserRepository struct {
 	mock.Mock
 }
@@ -59,6 +126,11 @@ func (m *MockUserRepository) GetUsernameByID(ctx context.Context, id int64) (str
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockUserRepository) GetUserRole(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockUserRepository) GetUserByID(ctx context.Context, id int64) (*entity.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -106,7 +178,7 @@ func TestCommentUseCase_GetCommentsByTopic(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCommentRepo := new(MockCommentRepository)
 			mockUserRepo := new(MockUserRepository)
-			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo)
+			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
 
 			mockCommentRepo.On("GetCommentsByTopic", mock.Anything, tt.topicID).
 				Return(tt.mockComments, tt.mockError)
@@ -116,7 +188,7 @@ func TestCommentUseCase_GetCommentsByTopic(t *testing.T) {
 					Return(tt.mockUsername, tt.mockUserError)
 			}
 
-			comments, err := uc.GetCommentsByTopicID(context.Background(), tt.topicID)
+			comments, err := uc.GetCommentsByTopicID(context.Background(), tt.topicID, 0)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -171,7 +243,7 @@ func TestCommentUseCase_GetCommentByID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCommentRepo := new(MockCommentRepository)
 			mockUserRepo := new(MockUserRepository)
-			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo)
+			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
 
 			mockCommentRepo.On("GetCommentByID", mock.Anything, tt.commentID).
 				Return(tt.mockComment, tt.mockError)
@@ -181,7 +253,7 @@ func TestCommentUseCase_GetCommentByID(t *testing.T) {
 					Return(tt.mockUsername, tt.mockUserError)
 			}
 
-			comment, err := uc.GetCommentByID(context.Background(), tt.commentID)
+			comment, err := uc.GetCommentByID(context.Background(), tt.commentID, 0)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -243,7 +315,7 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCommentRepo := new(MockCommentRepository)
 			mockUserRepo := new(MockUserRepository)
-			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo)
+			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
 
 			mockCommentRepo.On("CreateComment", mock.Anything, tt.comment).
 				Return(tt.mockError)
@@ -269,20 +341,214 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 	}
 }
 
+func TestCommentUseCase_CreateComment_Parent(t *testing.T) {
+	parentID := int64(5)
+	tests := []struct {
+		name          string
+		parent        *entity.Comment
+		parentError   error
+		expectedError error
+	}{
+		{
+			name:          "parent in another topic",
+			parent:        &entity.Comment{ID: parentID, TopicID: 2},
+			expectedError: ErrInvalidParentComment,
+		},
+		{
+			name:          "parent not found",
+			parentError:   repository.ErrCommentNotFound,
+			expectedError: ErrInvalidParentComment,
+		},
+		{
+			name:   "parent in the same topic",
+			parent: &entity.Comment{ID: parentID, TopicID: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommentRepo := new(MockCommentRepository)
+			mockUserRepo := new(MockUserRepository)
+			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
+
+			comment := &entity.Comment{
+				TopicID:  1,
+				ParentID: &parentID,
+				Content:  "Reply",
+				Author:   &entity.User{Username: "testuser"},
+			}
+			if tt.parent != nil {
+				mockCommentRepo.On("GetCommentByID", mock.Anything, parentID).Return(tt.parent, nil)
+			} else {
+				mockCommentRepo.On("GetCommentByID", mock.Anything, parentID).Return(nil, tt.parentError)
+			}
+			if tt.expectedError == nil {
+				mockCommentRepo.On("CreateComment", mock.Anything, comment).Return(nil)
+			}
+
+			err := uc.CreateComment(context.Background(), comment)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockCommentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCommentUseCase_GetCommentTree(t *testing.T) {
+	mockCommentRepo := new(MockCommentRepository)
+	mockUserRepo := new(MockUserRepository)
+	uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
+
+	parentID := int64(5)
+	reply := &entity.Comment{ID: 6, AuthorID: 3, TopicID: 9, ParentID: &parentID, Author: &entity.User{}}
+	nested := &entity.Comment{ID: 7, AuthorID: 3, TopicID: 9, Author: &entity.User{}}
+	reply.Replies = []*entity.Comment{nested}
+
+	mockCommentRepo.On("GetCommentByID", mock.Anything, parentID).Return(&entity.Comment{ID: parentID, TopicID: 9}, nil)
+	mockCommentRepo.On("GetCommentTree", mock.Anything, entity.CommentTreeQuery{TopicID: 9, ParentID: &parentID}).
+		Return(&entity.CommentTree{Comments: []*entity.Comment{reply}}, nil)
+	// Автор загружается один раз на все дерево
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(3)).Return(&entity.User{ID: 3, Username: "carol"}, nil).Once()
+
+	tree, err := uc.GetCommentTree(context.Background(), entity.CommentTreeQuery{TopicID: 1, ParentID: &parentID})
+	assert.NoError(t, err)
+	assert.Equal(t, "carol", tree.Comments[0].Author.Username)
+	assert.Equal(t, "carol", tree.Comments[0].Replies[0].Author.Username)
+	mockCommentRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestCommentUseCase_UpdateComment(t *testing.T) {
+	tests := []struct {
+		name          string
+		editWindow    time.Duration
+		userID        int64
+		role          string
+		content       string
+		createdAt     time.Time
+		expectedError error
+	}{
+		{
+			name:       "author inside the edit window",
+			editWindow: 15 * time.Minute,
+			userID:     1,
+			role:       entity.RoleUser,
+			content:    "Edited",
+			createdAt:  time.Now().Add(-time.Minute),
+		},
+		{
+			name:      "no edit window",
+			userID:    1,
+			role:      entity.RoleUser,
+			content:   "Edited",
+			createdAt: time.Now().Add(-24 * time.Hour),
+		},
+		{
+			name:          "not the author",
+			editWindow:    15 * time.Minute,
+			userID:        2,
+			role:          entity.RoleUser,
+			content:       "Edited",
+			createdAt:     time.Now(),
+			expectedError: policy.ErrForbidden,
+		},
+		{
+			name:          "edit window expired",
+			editWindow:    15 * time.Minute,
+			userID:        1,
+			role:          entity.RoleUser,
+			content:       "Edited",
+			createdAt:     time.Now().Add(-time.Hour),
+			expectedError: ErrEditWindowExpired,
+		},
+		{
+			name:       "moderator after the edit window",
+			editWindow: 15 * time.Minute,
+			userID:     3,
+			role:       entity.RoleModerator,
+			content:    "Edited",
+			createdAt:  time.Now().Add(-time.Hour),
+		},
+		{
+			name:          "empty content",
+			userID:        1,
+			content:       "   ",
+			expectedError: ErrEmptyComment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommentRepo := new(MockCommentRepository)
+			mockUserRepo := new(MockUserRepository)
+			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, tt.editWindow, nil)
+
+			existing := &entity.Comment{
+				ID:        10,
+				AuthorID:  1,
+				TopicID:   1,
+				Content:   "Original",
+				CreatedAt: tt.createdAt,
+				Author:    &entity.User{ID: 1, Username: "testuser"},
+			}
+			if tt.expectedError != ErrEmptyComment {
+				mockCommentRepo.On("GetCommentByID", mock.Anything, int64(10)).Return(existing, nil)
+				mockUserRepo.On("GetUserRole", mock.Anything, tt.userID).Return(tt.role, nil)
+			}
+			if tt.expectedError == nil {
+				mockCommentRepo.On("UpdateComment", mock.Anything, existing, tt.userID).Return(nil)
+			}
+
+			comment, err := uc.UpdateComment(context.Background(), 10, tt.userID, tt.content)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.content, comment.Content)
+			}
+			mockCommentRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestCommentUseCase_DeleteComment(t *testing.T) {
 	tests := []struct {
 		name          string
 		commentID     int64
+		userID        int64
+		role          string
 		mockError     error
 		expectedError error
 	}{
 		{
-			name:      "success",
+			name:      "author",
 			commentID: 1,
+			userID:    1,
+			role:      entity.RoleUser,
+		},
+		{
+			name:      "moderator",
+			commentID: 1,
+			userID:    2,
+			role:      entity.RoleModerator,
+		},
+		{
+			name:          "other user",
+			commentID:     1,
+			userID:        3,
+			role:          entity.RoleUser,
+			expectedError: policy.ErrForbidden,
 		},
 		{
 			name:          "repository error",
 			commentID:     1,
+			userID:        1,
+			role:          entity.RoleUser,
 			mockError:     errors.New("repository error"),
 			expectedError: errors.New("repository error"),
 		},
@@ -292,12 +558,17 @@ func TestCommentUseCase_DeleteComment(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCommentRepo := new(MockCommentRepository)
 			mockUserRepo := new(MockUserRepository)
-			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo)
+			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
 
-			mockCommentRepo.On("DeleteComment", mock.Anything, tt.commentID).
-				Return(tt.mockError)
+			mockCommentRepo.On("GetCommentByID", mock.Anything, tt.commentID).
+				Return(&entity.Comment{ID: tt.commentID, AuthorID: 1}, nil)
+			mockUserRepo.On("GetUserRole", mock.Anything, tt.userID).Return(tt.role, nil)
+			if tt.expectedError != policy.ErrForbidden {
+				mockCommentRepo.On("DeleteComment", mock.Anything, tt.commentID, tt.userID).
+					Return(tt.mockError)
+			}
 
-			err := uc.DeleteComment(context.Background(), tt.commentID)
+			err := uc.DeleteComment(context.Background(), tt.commentID, tt.userID)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -311,16 +582,55 @@ func TestCommentUseCase_DeleteComment(t *testing.T) {
 	}
 }
 
+func TestCommentUseCase_DeleteComment_AlreadyDeleted(t *testing.T) {
+	mockCommentRepo := new(MockCommentRepository)
+	mockUserRepo := new(MockUserRepository)
+	uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
+
+	// Удаленный комментарий виден только как заглушка
+	mockCommentRepo.On("GetCommentByID", mock.Anything, int64(1)).
+		Return(&entity.Comment{ID: 1, Deleted: true, Content: entity.DeletedCommentPlaceholder}, nil)
+
+	err := uc.DeleteComment(context.Background(), 1, 1)
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound)
+
+	_, err = uc.UpdateComment(context.Background(), 1, 1, "back again")
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound)
+	mockCommentRepo.AssertNotCalled(t, "DeleteComment", mock.Anything, mock.Anything, mock.Anything)
+	mockCommentRepo.AssertNotCalled(t, "UpdateComment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCommentUseCase_RestoreComment(t *testing.T) {
+	mockCommentRepo := new(MockCommentRepository)
+	uc := NewCommentUseCase(mockCommentRepo, new(MockUserRepository), 0, nil)
+
+	mockCommentRepo.On("RestoreComment", mock.Anything, int64(1)).Return(nil)
+	mockCommentRepo.On("RestoreComment", mock.Anything, int64(2)).Return(repository.ErrCommentNotFound)
+
+	assert.NoError(t, uc.RestoreComment(context.Background(), 1))
+	assert.ErrorIs(t, uc.RestoreComment(context.Background(), 2), repository.ErrCommentNotFound)
+	mockCommentRepo.AssertExpectations(t)
+}
+
 func TestCommentUseCase_LikeComment(t *testing.T) {
 	tests := []struct {
 		name          string
 		commentID     int64
+		likes         int
+		liked         bool
 		mockError     error
 		expectedError error
 	}{
 		{
 			name:      "success",
 			commentID: 1,
+			likes:     4,
+			liked:     true,
+		},
+		{
+			name:      "already liked",
+			commentID: 1,
+			likes:     4,
 		},
 		{
 			name:          "repository error",
@@ -334,21 +644,71 @@ func TestCommentUseCase_LikeComment(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCommentRepo := new(MockCommentRepository)
 			mockUserRepo := new(MockUserRepository)
-			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo)
-
-			mockCommentRepo.On("LikeComment", mock.Anything, tt.commentID).
-				Return(tt.mockError)
+			notifier := new(MockNotifier)
+			uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, notifier)
+
+			mockCommentRepo.On("LikeComment", mock.Anything, tt.commentID, int64(2)).
+				Return(tt.likes, tt.liked, tt.mockError)
+			// Автор узнает о лайке, только если он новый
+			if tt.liked {
+				notifier.On("CommentLiked", mock.Anything, tt.commentID, int64(2))
+			}
 
-			err := uc.LikeComment(context.Background(), tt.commentID)
+			likes, err := uc.LikeComment(context.Background(), tt.commentID, 2)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectedError.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.likes, likes)
 			}
 
 			mockCommentRepo.AssertExpectations(t)
+			notifier.AssertExpectations(t)
 		})
 	}
 }
+
+func TestCommentUseCase_GetCommentTree_LikedByMe(t *testing.T) {
+	mockCommentRepo := new(MockCommentRepository)
+	mockUserRepo := new(MockUserRepository)
+	uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
+
+	root := &entity.Comment{ID: 1, TopicID: 9, Author: &entity.User{Username: "alice"}}
+	reply := &entity.Comment{ID: 2, TopicID: 9, Author: &entity.User{Username: "bob"}}
+	root.Replies = []*entity.Comment{reply}
+
+	query := entity.CommentTreeQuery{TopicID: 9, ViewerID: 4}
+	mockCommentRepo.On("GetCommentTree", mock.Anything, query).
+		Return(&entity.CommentTree{Comments: []*entity.Comment{root}}, nil)
+	// Лайки проверяются одним запросом на все дерево
+	mockCommentRepo.On("GetLikedCommentIDs", mock.Anything, int64(4), []int64{1, 2}).
+		Return(map[int64]bool{2: true}, nil).Once()
+
+	tree, err := uc.GetCommentTree(context.Background(), query)
+	assert.NoError(t, err)
+	assert.False(t, tree.Comments[0].LikedByMe)
+	assert.True(t, tree.Comments[0].Replies[0].LikedByMe)
+	mockCommentRepo.AssertExpectations(t)
+}
+
+func TestCommentUseCase_GetCommentLikes(t *testing.T) {
+	mockCommentRepo := new(MockCommentRepository)
+	mockUserRepo := new(MockUserRepository)
+	uc := NewCommentUseCase(mockCommentRepo, mockUserRepo, 0, nil)
+
+	page := &entity.CommentLikePage{Likes: []*entity.CommentLike{{UserID: 2, Username: "bob"}}}
+	mockCommentRepo.On("GetCommentByID", mock.Anything, int64(1)).Return(&entity.Comment{ID: 1}, nil)
+	mockCommentRepo.On("GetCommentLikes", mock.Anything, int64(1), "", 20).Return(page, nil)
+
+	result, err := uc.GetCommentLikes(context.Background(), 1, "", 20)
+	assert.NoError(t, err)
+	assert.Equal(t, page, result)
+
+	// Для несуществующего комментария список не запрашивается
+	mockCommentRepo.On("GetCommentByID", mock.Anything, int64(2)).Return(nil, repository.ErrCommentNotFound)
+	_, err = uc.GetCommentLikes(context.Background(), 2, "", 20)
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound)
+	mockCommentRepo.AssertExpectations(t)
+}
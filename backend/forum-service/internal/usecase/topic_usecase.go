@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 	"github.com/sout1235/forum2/backend/forum-service/internal/repository"
 )
 
@@ -15,6 +16,7 @@ type TopicUseCase struct {
 	topicRepo   repository.TopicRepository
 	commentRepo repository.CommentRepository
 	userRepo    repository.UserRepository
+	policy      *policy.Policy
 }
 
 func NewTopicUseCase(topicRepo repository.TopicRepository, commentRepo repository.CommentRepository, userRepo repository.UserRepository) *TopicUseCase {
@@ -22,6 +24,7 @@ func NewTopicUseCase(topicRepo repository.TopicRepository, commentRepo repositor
 		topicRepo:   topicRepo,
 		commentRepo: commentRepo,
 		userRepo:    userRepo,
+		policy:      policy.New(userRepo),
 	}
 }
 
@@ -42,14 +45,14 @@ func (uc *TopicUseCase) GetTopicByID(ctx context.Context, id int64) (*entity.Top
 	return uc.topicRepo.GetTopicByID(ctx, id)
 }
 
-func (uc *TopicUseCase) GetAllTopics(ctx context.Context) ([]*entity.Topic, error) {
-	topics, err := uc.topicRepo.GetAllTopics(ctx)
+func (uc *TopicUseCase) GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error) {
+	page, err := uc.topicRepo.GetAllTopics(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	// Получаем имена пользователей для каждого топика
-	for _, topic := range topics {
+	for _, topic := range page.Topics {
 		username, err := uc.userRepo.GetUsernameByID(ctx, topic.AuthorID)
 		if err != nil {
 			log.Printf("Error getting username for user %d: %v", topic.AuthorID, err)
@@ -61,30 +64,48 @@ func (uc *TopicUseCase) GetAllTopics(ctx context.Context) ([]*entity.Topic, erro
 		}
 	}
 
-	return topics, nil
+	return page, nil
 }
 
-func (uc *TopicUseCase) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
+func (uc *TopicUseCase) UpdateTopic(ctx context.Context, topic *entity.Topic, userID int64) error {
 	if topic.Title == "" || topic.Content == "" {
 		return errors.New("title and content are required")
 	}
 
-	topic.UpdatedAt = time.Now()
-	return uc.topicRepo.UpdateTopic(ctx, topic)
-}
+	current, err := uc.topicRepo.GetTopicByID(ctx, topic.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := uc.policy.Authorize(ctx, userID, policy.ResourceTopic, policy.ActionUpdate, current.AuthorID); err != nil {
+		return err
+	}
 
-func (uc *TopicUseCase) DeleteTopic(ctx context.Context, id int64) error {
-	return uc.topicRepo.DeleteTopic(ctx, id)
+	topic.AuthorID = current.AuthorID
+	topic.UpdatedAt = time.Now()
+	return uc.topicRepo.UpdateTopic(ctx, topic, userID)
 }
 
-func (uc *TopicUseCase) IncrementViews(ctx context.Context, id int64) error {
+func (uc *TopicUseCase) DeleteTopic(ctx context.Context, id, userID int64) error {
 	topic, err := uc.topicRepo.GetTopicByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if _, err := uc.policy.Authorize(ctx, userID, policy.ResourceTopic, policy.ActionDelete, topic.AuthorID); err != nil {
+		return err
+	}
+	return uc.topicRepo.DeleteTopic(ctx, id, userID)
+}
 
-	topic.Views++
-	return uc.topicRepo.UpdateTopic(ctx, topic)
+// RestoreTopic brings back a deleted topic. Only moderators may call it;
+// the router enforces the role.
+func (uc *TopicUseCase) RestoreTopic(ctx context.Context, id int64) error {
+	return uc.topicRepo.RestoreTopic(ctx, id)
+}
+
+// IncrementViews counts a single view of a topic. Page loads go through
+// service.ViewCounter, which deduplicates and batches them instead.
+func (uc *TopicUseCase) IncrementViews(ctx context.Context, id int64) error {
+	return uc.topicRepo.AddViews(ctx, map[int64]int{id: 1})
 }
 
 func (uc *TopicUseCase) UpdateCommentCount(ctx context.Context, topicID int64) error {
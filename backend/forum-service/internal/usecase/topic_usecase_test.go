@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/sout1235/forum2/backend/forum-service/internal/entity"
+	"github.com/sout1235/forum2/backend/forum-service/internal/policy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -27,29 +29,88 @@ func (m *MockTopicRepository) GetTopicByID(ctx context.Context, id int64) (*enti
 	return args.Get(0).(*entity.Topic), args.Error(1)
 }
 
-func (m *MockTopicRepository) GetAllTopics(ctx context.Context) ([]*entity.Topic, error) {
-	args := m.Called(ctx)
+func (m *MockTopicRepository) GetAllTopics(ctx context.Context, filter entity.TopicFilter) (*entity.TopicPage, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*entity.Topic), args.Error(1)
+	return args.Get(0).(*entity.TopicPage), args.Error(1)
 }
 
-func (m *MockTopicRepository) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
-	args := m.Called(ctx, topic)
+func (m *MockTopicRepository) UpdateTopic(ctx context.Context, topic *entity.Topic, editorID int64) error {
+	args := m.Called(ctx, topic, editorID)
+	return args.Error(0)
+}
+
+func (m *MockTopicRepository) DeleteTopic(ctx context.Context, id, deletedBy int64) error {
+	args := m.Called(ctx, id, deletedBy)
 	return args.Error(0)
 }
 
-func (m *MockTopicRepository) DeleteTopic(ctx context.Context, id int64) error {
+func (m *MockTopicRepository) RestoreTopic(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTopicRepository) SetPin(ctx context.Context, id int64, scope entity.PinScope, until *time.Time) error {
+	args := m.Called(ctx, id, scope, until)
+	return args.Error(0)
+}
+
+func (m *MockTopicRepository) SetLock(ctx context.Context, id int64, locked bool, until *time.Time) error {
+	args := m.Called(ctx, id, locked, until)
+	return args.Error(0)
+}
+
+func (m *MockTopicRepository) SetAnnouncement(ctx context.Context, id int64, announcement bool, until *time.Time) error {
+	args := m.Called(ctx, id, announcement, until)
+	return args.Error(0)
+}
+
+func (m *MockTopicRepository) GetScheduledTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Topic), args.Error(1)
+}
+
+func (m *MockTopicRepository) GetScheduledTopics(ctx context.Context, authorID int64) ([]*entity.Topic, error) {
+	args := m.Called(ctx, authorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Topic), args.Error(1)
+}
+
+func (m *MockTopicRepository) RescheduleTopic(ctx context.Context, id int64, publishAt time.Time) error {
+	args := m.Called(ctx, id, publishAt)
+	return args.Error(0)
+}
+
+func (m *MockTopicRepository) PublishDueTopics(ctx context.Context, now time.Time, limit int) ([]*entity.Topic, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Topic), args.Error(1)
+}
+
+func (m *MockTopicRepository) PurgeDeletedTopics(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockTopicRepository) UpdateCommentCount(ctx context.Context, topicID int64) error {
 	args := m.Called(ctx, topicID)
 	return args.Error(0)
 }
 
+func (m *MockTopicRepository) AddViews(ctx context.Context, views map[int64]int) error {
+	args := m.Called(ctx, views)
+	return args.Error(0)
+}
+
 func TestTopicUseCase_CreateTopic(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -215,8 +276,12 @@ func TestTopicUseCase_GetAllTopics(t *testing.T) {
 			mockUserRepo := new(MockUserRepository)
 			uc := NewTopicUseCase(mockTopicRepo, mockCommentRepo, mockUserRepo)
 
-			mockTopicRepo.On("GetAllTopics", mock.Anything).
-				Return(tt.mockTopics, tt.mockError)
+			var mockPage *entity.TopicPage
+			if tt.mockError == nil {
+				mockPage = &entity.TopicPage{Topics: tt.mockTopics}
+			}
+			mockTopicRepo.On("GetAllTopics", mock.Anything, entity.TopicFilter{}).
+				Return(mockPage, tt.mockError)
 
 			if tt.mockError == nil {
 				for _, topic := range tt.mockTopics {
@@ -237,18 +302,18 @@ func TestTopicUseCase_GetAllTopics(t *testing.T) {
 				}
 			}
 
-			topics, err := uc.GetAllTopics(context.Background())
+			page, err := uc.GetAllTopics(context.Background(), entity.TopicFilter{})
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectedError.Error(), err.Error())
-				assert.Nil(t, topics)
+				assert.Nil(t, page)
 			} else {
 				assert.NoError(t, err)
-				assert.NotNil(t, topics)
-				assert.Equal(t, len(tt.mockTopics), len(topics))
+				assert.NotNil(t, page)
+				assert.Equal(t, len(tt.mockTopics), len(page.Topics))
 
-				for _, topic := range topics {
+				for _, topic := range page.Topics {
 					if tt.mockUserError == nil {
 						assert.Equal(t, tt.mockUsernames[topic.AuthorID], topic.Author.Username)
 					} else {
@@ -267,16 +332,41 @@ func TestTopicUseCase_UpdateTopic(t *testing.T) {
 	tests := []struct {
 		name          string
 		topic         *entity.Topic
+		userID        int64
+		role          string
 		mockError     error
 		expectedError error
 	}{
 		{
-			name: "success",
+			name: "author",
 			topic: &entity.Topic{
 				ID:      1,
 				Title:   "Updated Topic",
 				Content: "Updated Content",
 			},
+			userID: 1,
+			role:   entity.RoleUser,
+		},
+		{
+			name: "moderator",
+			topic: &entity.Topic{
+				ID:      1,
+				Title:   "Updated Topic",
+				Content: "Updated Content",
+			},
+			userID: 2,
+			role:   entity.RoleModerator,
+		},
+		{
+			name: "other user",
+			topic: &entity.Topic{
+				ID:      1,
+				Title:   "Updated Topic",
+				Content: "Updated Content",
+			},
+			userID:        3,
+			role:          entity.RoleUser,
+			expectedError: policy.ErrForbidden,
 		},
 		{
 			name: "empty title",
@@ -285,6 +375,7 @@ func TestTopicUseCase_UpdateTopic(t *testing.T) {
 				Title:   "",
 				Content: "Updated Content",
 			},
+			userID:        1,
 			expectedError: errors.New("title and content are required"),
 		},
 		{
@@ -294,6 +385,7 @@ func TestTopicUseCase_UpdateTopic(t *testing.T) {
 				Title:   "Updated Topic",
 				Content: "",
 			},
+			userID:        1,
 			expectedError: errors.New("title and content are required"),
 		},
 		{
@@ -303,6 +395,8 @@ func TestTopicUseCase_UpdateTopic(t *testing.T) {
 				Title:   "Updated Topic",
 				Content: "Updated Content",
 			},
+			userID:        1,
+			role:          entity.RoleUser,
 			mockError:     errors.New("repository error"),
 			expectedError: errors.New("repository error"),
 		},
@@ -315,12 +409,17 @@ func TestTopicUseCase_UpdateTopic(t *testing.T) {
 			mockUserRepo := new(MockUserRepository)
 			uc := NewTopicUseCase(mockTopicRepo, mockCommentRepo, mockUserRepo)
 
-			if tt.expectedError == nil || tt.expectedError.Error() != "title and content are required" {
-				mockTopicRepo.On("UpdateTopic", mock.Anything, mock.AnythingOfType("*entity.Topic")).
+			if tt.role != "" {
+				mockTopicRepo.On("GetTopicByID", mock.Anything, tt.topic.ID).
+					Return(&entity.Topic{ID: tt.topic.ID, AuthorID: 1}, nil)
+				mockUserRepo.On("GetUserRole", mock.Anything, tt.userID).Return(tt.role, nil)
+			}
+			if tt.role != "" && tt.expectedError != policy.ErrForbidden {
+				mockTopicRepo.On("UpdateTopic", mock.Anything, mock.AnythingOfType("*entity.Topic"), tt.userID).
 					Return(tt.mockError)
 			}
 
-			err := uc.UpdateTopic(context.Background(), tt.topic)
+			err := uc.UpdateTopic(context.Background(), tt.topic, tt.userID)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -328,6 +427,7 @@ func TestTopicUseCase_UpdateTopic(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotZero(t, tt.topic.UpdatedAt)
+				assert.Equal(t, int64(1), tt.topic.AuthorID)
 			}
 
 			mockTopicRepo.AssertExpectations(t)
@@ -339,16 +439,35 @@ func TestTopicUseCase_DeleteTopic(t *testing.T) {
 	tests := []struct {
 		name          string
 		topicID       int64
+		userID        int64
+		role          string
 		mockError     error
 		expectedError error
 	}{
 		{
-			name:    "success",
+			name:    "author",
 			topicID: 1,
+			userID:  1,
+			role:    entity.RoleUser,
+		},
+		{
+			name:    "admin",
+			topicID: 1,
+			userID:  2,
+			role:    entity.RoleAdmin,
+		},
+		{
+			name:          "other user",
+			topicID:       1,
+			userID:        3,
+			role:          entity.RoleUser,
+			expectedError: policy.ErrForbidden,
 		},
 		{
 			name:          "repository error",
 			topicID:       1,
+			userID:        1,
+			role:          entity.RoleUser,
 			mockError:     errors.New("repository error"),
 			expectedError: errors.New("repository error"),
 		},
@@ -361,10 +480,15 @@ func TestTopicUseCase_DeleteTopic(t *testing.T) {
 			mockUserRepo := new(MockUserRepository)
 			uc := NewTopicUseCase(mockTopicRepo, mockCommentRepo, mockUserRepo)
 
-			mockTopicRepo.On("DeleteTopic", mock.Anything, tt.topicID).
-				Return(tt.mockError)
+			mockTopicRepo.On("GetTopicByID", mock.Anything, tt.topicID).
+				Return(&entity.Topic{ID: tt.topicID, AuthorID: 1}, nil)
+			mockUserRepo.On("GetUserRole", mock.Anything, tt.userID).Return(tt.role, nil)
+			if tt.expectedError != policy.ErrForbidden {
+				mockTopicRepo.On("DeleteTopic", mock.Anything, tt.topicID, tt.userID).
+					Return(tt.mockError)
+			}
 
-			err := uc.DeleteTopic(context.Background(), tt.topicID)
+			err := uc.DeleteTopic(context.Background(), tt.topicID, tt.userID)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -382,34 +506,19 @@ func TestTopicUseCase_IncrementViews(t *testing.T) {
 	tests := []struct {
 		name          string
 		topicID       int64
-		mockTopic     *entity.Topic
 		mockError     error
 		expectedError error
 	}{
 		{
 			name:    "success",
 			topicID: 1,
-			mockTopic: &entity.Topic{
-				ID:    1,
-				Views: 5,
-			},
 		},
 		{
-			name:          "get topic error",
+			name:          "repository error",
 			topicID:       1,
 			mockError:     errors.New("repository error"),
 			expectedError: errors.New("repository error"),
 		},
-		{
-			name:    "update error",
-			topicID: 1,
-			mockTopic: &entity.Topic{
-				ID:    1,
-				Views: 5,
-			},
-			mockError:     errors.New("update error"),
-			expectedError: errors.New("update error"),
-		},
 	}
 
 	for _, tt := range tests {
@@ -419,18 +528,9 @@ func TestTopicUseCase_IncrementViews(t *testing.T) {
 			mockUserRepo := new(MockUserRepository)
 			uc := NewTopicUseCase(mockTopicRepo, mockCommentRepo, mockUserRepo)
 
-			mockTopicRepo.On("GetTopicByID", mock.Anything, tt.topicID).
-				Return(tt.mockTopic, tt.mockError)
-
-			if tt.mockError == nil {
-				mockTopicRepo.On("UpdateTopic", mock.Anything, mock.AnythingOfType("*entity.Topic")).
-					Return(tt.mockError)
-			}
-
-			oldViews := 0
-			if tt.mockTopic != nil {
-				oldViews = tt.mockTopic.Views
-			}
+			// Просмотр добавляется атомарно, без чтения темы
+			mockTopicRepo.On("AddViews", mock.Anything, map[int64]int{tt.topicID: 1}).
+				Return(tt.mockError)
 
 			err := uc.IncrementViews(context.Background(), tt.topicID)
 
@@ -439,7 +539,6 @@ func TestTopicUseCase_IncrementViews(t *testing.T) {
 				assert.Equal(t, tt.expectedError.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, oldViews+1, tt.mockTopic.Views)
 			}
 
 			mockTopicRepo.AssertExpectations(t)
@@ -0,0 +1,37 @@
+-- Счетчик комментариев используется для сортировки тем
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS comment_count INTEGER DEFAULT 0;
+
+-- Добавляем время последней активности в теме
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE topics t
+SET last_activity_at = GREATEST(t.created_at, COALESCE(
+    (SELECT MAX(c.created_at) FROM comments c WHERE c.topic_id = t.id),
+    t.created_at
+));
+
+ALTER TABLE topics ALTER COLUMN last_activity_at SET NOT NULL;
+
+-- Индексы для постраничной выборки тем по курсору
+CREATE INDEX IF NOT EXISTS idx_topics_created_at_id ON topics(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_views_id ON topics(views DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_comment_count_id ON topics(comment_count DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_last_activity_at_id ON topics(last_activity_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_category_created_at_id ON topics(category_id, created_at DESC, id DESC);
+
+-- Обновляем время последней активности при добавлении комментария
+CREATE OR REPLACE FUNCTION touch_topic_last_activity()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE topics
+    SET last_activity_at = GREATEST(last_activity_at, NEW.created_at)
+    WHERE id = NEW.topic_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_touch_topic_activity ON comments;
+CREATE TRIGGER comments_touch_topic_activity
+    AFTER INSERT ON comments
+    FOR EACH ROW
+    EXECUTE FUNCTION touch_topic_last_activity();
@@ -0,0 +1,48 @@
+-- Полнотекстовый поиск по темам и комментариям.
+-- Индексируем текст и русской, и английской конфигурацией: большинство
+-- сообщений на русском, но английские термины тоже должны находиться.
+CREATE OR REPLACE FUNCTION forum_search_vector(title TEXT, body TEXT)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('russian', COALESCE(title, '')), 'A') ||
+           setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+           setweight(to_tsvector('russian', COALESCE(body, '')), 'B') ||
+           setweight(to_tsvector('english', COALESCE(body, '')), 'B');
+$$ LANGUAGE sql IMMUTABLE;
+
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS search_vector tsvector;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+UPDATE topics SET search_vector = forum_search_vector(title, content) WHERE search_vector IS NULL;
+UPDATE comments SET search_vector = forum_search_vector(NULL, content) WHERE search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_topics_search_vector ON topics USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS idx_comments_search_vector ON comments USING GIN(search_vector);
+
+-- Поддерживаем поисковые векторы в актуальном состоянии
+CREATE OR REPLACE FUNCTION topics_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := forum_search_vector(NEW.title, NEW.content);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS topics_search_vector_trigger ON topics;
+CREATE TRIGGER topics_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, content ON topics
+    FOR EACH ROW
+    EXECUTE FUNCTION topics_search_vector_update();
+
+CREATE OR REPLACE FUNCTION comments_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := forum_search_vector(NULL, NEW.content);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_search_vector_trigger ON comments;
+CREATE TRIGGER comments_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF content ON comments
+    FOR EACH ROW
+    EXECUTE FUNCTION comments_search_vector_update();
@@ -0,0 +1,6 @@
+-- Порядок отображения категорий
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+UPDATE categories SET position = id WHERE position = 0;
+
+CREATE INDEX IF NOT EXISTS idx_categories_position ON categories(position, id);
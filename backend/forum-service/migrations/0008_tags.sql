@@ -0,0 +1,14 @@
+-- Теги для сквозных тем, не привязанных к категориям
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(32) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS topic_tags (
+    topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (topic_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_tags_tag_id ON topic_tags(tag_id);
@@ -0,0 +1,4 @@
+-- Индексы для выборки дерева комментариев: ответы к комментарию и
+-- комментарии верхнего уровня в порядке создания
+CREATE INDEX IF NOT EXISTS idx_comments_parent_created_at_id ON comments(parent_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_topic_root_created_at_id ON comments(topic_id, created_at, id) WHERE parent_id IS NULL;
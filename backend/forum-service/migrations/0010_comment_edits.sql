@@ -0,0 +1,2 @@
+-- Время последнего редактирования комментария; NULL, если его не правили
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,22 @@
+-- Лайки комментариев: один лайк от пользователя на комментарий.
+-- comments.likes остается денормализованным счетчиком этой таблицы.
+CREATE TABLE IF NOT EXISTS comment_likes (
+    comment_id BIGINT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_likes_comment_created_at ON comment_likes(comment_id, created_at DESC, user_id DESC);
+CREATE INDEX IF NOT EXISTS idx_comment_likes_user_id ON comment_likes(user_id);
+
+-- Лайки, поставленные до появления таблицы, не привязаны к пользователям;
+-- счетчик приводится к числу записей в ней
+UPDATE comments c SET likes = l.n
+FROM (
+    SELECT c2.id, COUNT(cl.user_id) AS n
+    FROM comments c2
+    LEFT JOIN comment_likes cl ON cl.comment_id = c2.id
+    GROUP BY c2.id
+) l
+WHERE c.id = l.id AND c.likes IS DISTINCT FROM l.n;
@@ -0,0 +1,45 @@
+-- Жалобы на темы, комментарии и сообщения чата и очередь модерации.
+-- hidden_at скрывает контент из выдачи; hidden_by = NULL означает, что
+-- контент скрыт автоматически по числу жалоб и ждет решения модератора.
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS hidden_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS hidden_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS reports (
+    id BIGSERIAL PRIMARY KEY,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('topic', 'comment', 'chat_message')),
+    target_id BIGINT NOT NULL,
+    target_author_id BIGINT,
+    content_snapshot TEXT NOT NULL DEFAULT '',
+    reporter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(16) NOT NULL CHECK (reason IN ('spam', 'abuse', 'off_topic', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'claimed', 'resolved', 'dismissed')),
+    claimed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE,
+    resolved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    action VARCHAR(16) CHECK (action IN ('hide', 'delete', 'warn')),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Повторная жалоба пользователя на тот же контент схлопывается, пока
+-- предыдущая не рассмотрена
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_active_reporter ON reports(target_type, target_id, reporter_id) WHERE status IN ('open', 'claimed');
+CREATE INDEX IF NOT EXISTS idx_reports_status_created_at ON reports(status, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_reports_target ON reports(target_type, target_id);
+
+CREATE TABLE IF NOT EXISTS user_warnings (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    moderator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    report_id BIGINT REFERENCES reports(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_warnings_user_id ON user_warnings(user_id);
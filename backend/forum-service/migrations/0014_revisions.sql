@@ -0,0 +1,23 @@
+-- История правок тем и комментариев: перед каждым изменением текста
+-- сохраняется прежняя версия и автор правки. Версии нумеруются с 1
+-- отдельно для каждой темы и комментария.
+CREATE TABLE IF NOT EXISTS topic_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    editor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (topic_id, revision)
+);
+
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    comment_id BIGINT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    editor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (comment_id, revision)
+);
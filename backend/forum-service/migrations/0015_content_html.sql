@@ -0,0 +1,6 @@
+-- HTML, отрисованный из Markdown-текста тем, комментариев и сообщений чата.
+-- Заполняется сервисом при каждом сохранении; NULL означает, что строка
+-- создана до появления колонки и будет отрисована при старте сервиса.
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS content_html TEXT;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS content_html TEXT;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS content_html TEXT;
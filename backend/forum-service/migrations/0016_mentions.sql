@@ -0,0 +1,15 @@
+-- Упоминания пользователей (@username) в темах, комментариях и чате.
+-- Имя ищется без учета регистра, по тому же индексу работает подсказка
+-- имен по префиксу.
+CREATE TABLE IF NOT EXISTS mentions (
+    id BIGSERIAL PRIMARY KEY,
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('topic', 'comment', 'chat_message')),
+    target_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    author_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (target_type, target_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mentions_user ON mentions(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_users_username_lower ON users(lower(username) text_pattern_ops);
@@ -0,0 +1,22 @@
+-- Уведомления пользователей. Повторяющиеся события (ответы, лайки) на один
+-- объект копятся в одной непрочитанной записи: group_key у них одинаковый,
+-- а actor_ids хранит всех, кто вызвал событие, последний в конце.
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('topic_reply', 'comment_reply', 'mention', 'like', 'moderation')),
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('topic', 'comment', 'chat_message')),
+    target_id BIGINT NOT NULL,
+    topic_id BIGINT,
+    actor_ids BIGINT[] NOT NULL DEFAULT '{}',
+    action VARCHAR(20) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    group_key TEXT,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_group ON notifications(user_id, group_key) WHERE read_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
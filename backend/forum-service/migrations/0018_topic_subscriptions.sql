@@ -0,0 +1,48 @@
+-- Настройки подписок. Пользователи приходят из сервиса авторизации,
+-- поэтому настройки хранятся отдельно; нет строки - автоподписка включена.
+CREATE TABLE IF NOT EXISTS watch_settings (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    auto_watch BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Подписки пользователей на темы. last_read_comment_id - последний
+-- комментарий, который был в теме, когда пользователь ее открывал;
+-- по нему считаются непрочитанные.
+--
+-- Авторы подписываются на свои темы, а комментаторы - на обсуждаемые
+-- темы только при создании таблицы: повторный запуск миграции не должен
+-- возвращать подписки, от которых пользователи отказались.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM information_schema.tables
+        WHERE table_schema = current_schema() AND table_name = 'topic_subscriptions'
+    ) THEN
+        CREATE TABLE topic_subscriptions (
+            user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+            level VARCHAR(20) NOT NULL CHECK (level IN ('watching', 'tracking', 'muted')),
+            last_read_comment_id BIGINT NOT NULL DEFAULT 0,
+            created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY (user_id, topic_id)
+        );
+
+        INSERT INTO topic_subscriptions (user_id, topic_id, level, last_read_comment_id)
+        SELECT t.author_id, t.id, 'watching', COALESCE((SELECT MAX(c.id) FROM comments c WHERE c.topic_id = t.id), 0)
+        FROM topics t
+        WHERE EXISTS (SELECT 1 FROM users u WHERE u.id = t.author_id)
+            AND NOT EXISTS (SELECT 1 FROM watch_settings w WHERE w.user_id = t.author_id AND NOT w.auto_watch)
+        ON CONFLICT DO NOTHING;
+
+        INSERT INTO topic_subscriptions (user_id, topic_id, level, last_read_comment_id)
+        SELECT DISTINCT c.author_id, c.topic_id, 'tracking', COALESCE((SELECT MAX(l.id) FROM comments l WHERE l.topic_id = c.topic_id), 0)
+        FROM comments c
+        WHERE EXISTS (SELECT 1 FROM users u WHERE u.id = c.author_id)
+            AND NOT EXISTS (SELECT 1 FROM watch_settings w WHERE w.user_id = c.author_id AND NOT w.auto_watch)
+        ON CONFLICT DO NOTHING;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_topic_subscriptions_topic ON topic_subscriptions(topic_id, level);
@@ -0,0 +1,22 @@
+-- Закладки пользователей. Внешнего ключа на цель нет: закладка остается,
+-- когда тему или комментарий удаляют, и показывается как недоступная.
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('topic', 'comment')),
+    target_id BIGINT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    remind_at TIMESTAMP WITH TIME ZONE,
+    reminded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, target_type, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user ON bookmarks(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_due ON bookmarks(remind_at) WHERE remind_at IS NOT NULL AND reminded_at IS NULL;
+
+-- Напоминания по закладкам приходят уведомлениями
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check
+    CHECK (type IN ('topic_reply', 'comment_reply', 'mention', 'like', 'moderation', 'reminder'));
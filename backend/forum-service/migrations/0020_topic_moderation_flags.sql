@@ -0,0 +1,8 @@
+-- Закрепление, закрытие и объявления. У каждого флага свой срок действия;
+-- NULL означает бессрочно.
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS pin_scope VARCHAR(20) CHECK (pin_scope IN ('category', 'global'));
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS pinned_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS announcement BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS announcement_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,31 @@
+-- Опросы в темах: не больше одного на тему
+CREATE TABLE IF NOT EXISTS polls (
+    id BIGSERIAL PRIMARY KEY,
+    topic_id BIGINT NOT NULL UNIQUE REFERENCES topics(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    hide_results BOOLEAN NOT NULL DEFAULT FALSE,
+    closes_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id BIGSERIAL PRIMARY KEY,
+    poll_id BIGINT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    UNIQUE (poll_id, position)
+);
+
+-- Голоса анонимных опросов тоже хранятся по пользователям, чтобы голос
+-- можно было изменить; наружу они не отдаются
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id BIGINT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    option_id BIGINT NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (option_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_user ON poll_votes(poll_id, user_id);
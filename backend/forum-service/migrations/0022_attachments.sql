@@ -0,0 +1,19 @@
+-- Загруженные файлы. Пока файл не привязан к теме или комментарию, post_type
+-- и post_id пусты; такие файлы удаляются сборщиком мусора. Внешнего ключа на
+-- запись нет: вложения окончательно удаленных записей тоже собираются.
+CREATE TABLE IF NOT EXISTS attachments (
+    id BIGSERIAL PRIMARY KEY,
+    uploader_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_type VARCHAR(20) CHECK (post_type IN ('topic', 'comment')),
+    post_id BIGINT,
+    filename TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    thumbnail_key TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((post_type IS NULL) = (post_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_post ON attachments(post_type, post_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_orphaned ON attachments(created_at) WHERE post_id IS NULL;
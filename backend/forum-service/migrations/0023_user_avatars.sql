@@ -0,0 +1,5 @@
+-- Загруженные аватары. avatar_key - общий префикс ключей файлов всех
+-- размеров в хранилище; у пользователей без загрузки он пуст, и им
+-- отдается сгенерированный аватар.
+ALTER TABLE users ALTER COLUMN avatar TYPE TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key TEXT;
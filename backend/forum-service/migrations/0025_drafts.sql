@@ -0,0 +1,21 @@
+-- Черновики: у пользователя не больше одного черновика на контекст
+-- (новая тема, ответ в тему, ответ на комментарий). target_id равен 0 для
+-- новой темы. Каждое сохранение увеличивает version.
+CREATE TABLE IF NOT EXISTS drafts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    context VARCHAR(20) NOT NULL CHECK (context IN ('topic', 'topic_reply', 'comment_reply')),
+    target_id BIGINT NOT NULL DEFAULT 0,
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    category_id BIGINT,
+    tag_names TEXT[] NOT NULL DEFAULT '{}',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (user_id, context, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_drafts_user ON drafts(user_id, updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_drafts_expires_at ON drafts(expires_at);
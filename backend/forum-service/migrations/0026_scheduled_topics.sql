@@ -0,0 +1,5 @@
+-- Отложенная публикация: пока publish_at задан, тема видна только автору и
+-- модераторам. Публикатор сбрасывает publish_at, когда время наступает.
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_topics_publish_at ON topics(publish_at) WHERE publish_at IS NOT NULL;
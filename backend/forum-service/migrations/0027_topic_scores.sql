@@ -0,0 +1,128 @@
+-- Рейтинг тем. hot_score хранит log2 суммы очков темы, каждое из которых
+-- вдвое теряет вес за 12 часов: x = log2(очки) + (время - 2024-01-01) / 12ч.
+-- Тема дает 1 очко, комментарий 2, лайк 1, просмотр 0.1. Счет хранится в
+-- колонке и обновляется при каждой активности, поэтому выдача листается по
+-- индексу. Снятый лайк вычитает ровно то, что добавил, а удаленный
+-- комментарий - свои очки и очки своих лайков. top_score хранит сумму очков
+-- без затухания.
+CREATE OR REPLACE FUNCTION topic_hot_score(score DOUBLE PRECISION, points DOUBLE PRECISION, at TIMESTAMP WITH TIME ZONE)
+RETURNS DOUBLE PRECISION AS $$
+DECLARE
+	x DOUBLE PRECISION;
+BEGIN
+	IF points = 0 THEN
+		RETURN score;
+	END IF;
+	x := LN(ABS(points)) / LN(2) + EXTRACT(EPOCH FROM at - TIMESTAMPTZ '2024-01-01 00:00:00+00') / 43200;
+	IF points > 0 THEN
+		IF ABS(score - x) > 64 THEN
+			RETURN GREATEST(score, x);
+		END IF;
+		RETURN GREATEST(score, x) + LN(1 + POWER(2, -ABS(score - x))) / LN(2);
+	END IF;
+	IF x >= score THEN
+		RETURN 0;
+	END IF;
+	IF score - x > 64 THEN
+		RETURN score;
+	END IF;
+	RETURN score + LN(1 - POWER(2, x - score)) / LN(2);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS hot_score DOUBLE PRECISION;
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS top_score DOUBLE PRECISION;
+
+UPDATE topics t
+SET top_score = s.points, hot_score = topic_hot_score(0, s.points, t.last_activity_at)
+FROM (
+	SELECT tp.id, 1 + 2 * tp.comment_count + 0.1 * tp.views + COUNT(cl.user_id) AS points
+	FROM topics tp
+	LEFT JOIN comments c ON c.topic_id = tp.id AND c.deleted_at IS NULL
+	LEFT JOIN comment_likes cl ON cl.comment_id = c.id
+	WHERE tp.hot_score IS NULL
+	GROUP BY tp.id
+) s
+WHERE t.id = s.id;
+
+ALTER TABLE topics ALTER COLUMN hot_score SET DEFAULT topic_hot_score(0, 1, CURRENT_TIMESTAMP);
+ALTER TABLE topics ALTER COLUMN hot_score SET NOT NULL;
+ALTER TABLE topics ALTER COLUMN top_score SET DEFAULT 1;
+ALTER TABLE topics ALTER COLUMN top_score SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_topics_hot_score ON topics(hot_score DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_top_score ON topics(top_score DESC, id DESC);
+
+CREATE OR REPLACE FUNCTION score_topic_comment_points(cid BIGINT, tid BIGINT, created TIMESTAMP WITH TIME ZONE, direction DOUBLE PRECISION)
+RETURNS VOID AS $$
+DECLARE
+	liked_at TIMESTAMP WITH TIME ZONE;
+BEGIN
+	-- Очки снимаются с теми же временами, с которыми добавлялись
+	UPDATE topics
+	SET hot_score = topic_hot_score(hot_score, direction * 2, created), top_score = top_score + direction * 2
+	WHERE id = tid;
+	FOR liked_at IN SELECT created_at FROM comment_likes WHERE comment_id = cid LOOP
+		UPDATE topics
+		SET hot_score = topic_hot_score(hot_score, direction, liked_at), top_score = top_score + direction
+		WHERE id = tid;
+	END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION score_topic_comment()
+RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		PERFORM score_topic_comment_points(NEW.id, NEW.topic_id, NEW.created_at, 1);
+	ELSIF TG_OP = 'DELETE' THEN
+		-- Лайки еще не удалены каскадом, поэтому триггер срабатывает до удаления
+		IF OLD.deleted_at IS NULL THEN
+			PERFORM score_topic_comment_points(OLD.id, OLD.topic_id, OLD.created_at, -1);
+		END IF;
+		RETURN OLD;
+	ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+		PERFORM score_topic_comment_points(NEW.id, NEW.topic_id, NEW.created_at, -1);
+	ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+		PERFORM score_topic_comment_points(NEW.id, NEW.topic_id, NEW.created_at, 1);
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_score_topic ON comments;
+CREATE TRIGGER comments_score_topic
+AFTER INSERT OR UPDATE OF deleted_at ON comments
+FOR EACH ROW
+EXECUTE FUNCTION score_topic_comment();
+
+DROP TRIGGER IF EXISTS comments_unscore_topic ON comments;
+CREATE TRIGGER comments_unscore_topic
+BEFORE DELETE ON comments
+FOR EACH ROW
+EXECUTE FUNCTION score_topic_comment();
+
+-- Лайки удаленных комментариев теме не засчитываются
+CREATE OR REPLACE FUNCTION score_topic_comment_like()
+RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		UPDATE topics t
+		SET hot_score = topic_hot_score(t.hot_score, 1, NEW.created_at), top_score = t.top_score + 1
+		FROM comments c
+		WHERE c.id = NEW.comment_id AND c.deleted_at IS NULL AND t.id = c.topic_id;
+	ELSE
+		UPDATE topics t
+		SET hot_score = topic_hot_score(t.hot_score, -1, OLD.created_at), top_score = t.top_score - 1
+		FROM comments c
+		WHERE c.id = OLD.comment_id AND c.deleted_at IS NULL AND t.id = c.topic_id;
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comment_likes_score_topic ON comment_likes;
+CREATE TRIGGER comment_likes_score_topic
+AFTER INSERT OR DELETE ON comment_likes
+FOR EACH ROW
+EXECUTE FUNCTION score_topic_comment_like();
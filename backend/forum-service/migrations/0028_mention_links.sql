@@ -0,0 +1,9 @@
+-- Упоминания раньше ссылались на /users/<имя>, а профиль открывается по
+-- /users/<id>. HTML таких строк сбрасывается и отрисовывается заново при
+-- старте сервиса.
+UPDATE topics SET content_html = NULL
+WHERE content_html ~ 'href="/users/[^"]*[^0-9"][^"]*" class="mention"';
+UPDATE comments SET content_html = NULL
+WHERE content_html ~ 'href="/users/[^"]*[^0-9"][^"]*" class="mention"';
+UPDATE chat_messages SET content_html = NULL
+WHERE content_html ~ 'href="/users/[^"]*[^0-9"][^"]*" class="mention"';
@@ -0,0 +1,3 @@
+-- Объявления и закрепленные темы выбираются для выдачи отдельным запросом,
+-- остальные темы листаются по индексам колонок сортировки.
+CREATE INDEX IF NOT EXISTS idx_topics_flagged ON topics(id) WHERE announcement OR pin_scope IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Индексы для постраничной выборки тем категории по каждой сортировке;
+-- для created_at индекс создан в 0005
+CREATE INDEX IF NOT EXISTS idx_topics_category_views_id ON topics(category_id, views DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_category_comment_count_id ON topics(category_id, comment_count DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_category_last_activity_at_id ON topics(category_id, last_activity_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_category_hot_score_id ON topics(category_id, hot_score DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_topics_category_top_score_id ON topics(category_id, top_score DESC, id DESC);
@@ -37,10 +37,12 @@ func setupTestEnvironment() (*httpDelivery.Router, error) {
 	commentRepo := repository.NewCommentRepository(db)
 	userRepo := repository.NewUserRepository(db, "http://localhost:8080")
 	chatRepo := repository.NewChatRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
 
 	// Initialize services and use cases
-	topicService := service.NewTopicService(topicRepo, userRepo)
-	commentUseCase := usecase.NewCommentUseCase(commentRepo, userRepo)
+	topicService := service.NewTopicService(topicRepo, userRepo, categoryRepo, tagRepo, nil)
+	commentUseCase := usecase.NewCommentUseCase(commentRepo, userRepo, 0, nil)
 
 	// Initialize router
 	router := httpDelivery.NewRouter(
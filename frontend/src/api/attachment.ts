@@ -0,0 +1,28 @@
+import axiosInstance from '../config/axios';
+import { Attachment } from '../types/attachment';
+
+export const attachmentApi = {
+  upload: (file: File) => {
+    const data = new FormData();
+    data.append('file', file);
+    return axiosInstance.post<Attachment>('/attachments', data, {
+      headers: { 'Content-Type': 'multipart/form-data' }
+    });
+  },
+
+  getAttachment: (id: number) =>
+    axiosInstance.get<Attachment>(`/attachments/${id}`),
+
+  deleteAttachment: (id: number) =>
+    axiosInstance.delete(`/attachments/${id}`),
+
+  // Attachments of the topic and of its comments
+  getTopicAttachments: (topicId: number) =>
+    axiosInstance.get<Attachment[]>(`/topics/${topicId}/attachments`),
+
+  attachToTopic: (topicId: number, attachmentIds: number[]) =>
+    axiosInstance.post(`/topics/${topicId}/attachments`, { attachment_ids: attachmentIds }),
+
+  attachToComment: (commentId: number, attachmentIds: number[]) =>
+    axiosInstance.post(`/comments/${commentId}/attachments`, { attachment_ids: attachmentIds })
+};
@@ -0,0 +1,25 @@
+import axiosInstance from '../config/axios';
+import {
+  Bookmark,
+  BookmarkListParams,
+  BookmarkPage,
+  CreateBookmarkData,
+  UpdateBookmarkData
+} from '../types/bookmark';
+
+export const bookmarkApi = {
+  getBookmarks: (params?: BookmarkListParams) =>
+    axiosInstance.get<BookmarkPage>('/bookmarks', { params }),
+
+  getBookmark: (id: number) =>
+    axiosInstance.get<Bookmark>(`/bookmarks/${id}`),
+
+  createBookmark: (data: CreateBookmarkData) =>
+    axiosInstance.post<Bookmark>('/bookmarks', data),
+
+  updateBookmark: (id: number, data: UpdateBookmarkData) =>
+    axiosInstance.put<Bookmark>(`/bookmarks/${id}`, data),
+
+  deleteBookmark: (id: number) =>
+    axiosInstance.delete(`/bookmarks/${id}`)
+};
@@ -0,0 +1,19 @@
+import axiosInstance from '../config/axios';
+import { Category } from '../types/topic';
+
+export const categoryApi = {
+  getAllCategories: () =>
+    axiosInstance.get<Category[]>('/categories'),
+
+  createCategory: (data: { name: string; description?: string }) =>
+    axiosInstance.post<Category>('/categories', data),
+
+  updateCategory: (id: number, data: { name: string; description?: string }) =>
+    axiosInstance.put<Category>(`/categories/${id}`, data),
+
+  reorderCategories: (ids: number[]) =>
+    axiosInstance.put('/categories/order', { ids }),
+
+  deleteCategory: (id: number) =>
+    axiosInstance.delete(`/categories/${id}`)
+};
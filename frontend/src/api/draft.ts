@@ -0,0 +1,18 @@
+import axiosInstance from '../config/axios';
+import { Draft, DraftListParams, DraftPage, PublishedDraft, SaveDraftData } from '../types/draft';
+
+export const draftApi = {
+  getDrafts: (params?: DraftListParams) =>
+    axiosInstance.get<DraftPage>('/drafts', { params }),
+
+  saveDraft: (data: SaveDraftData) =>
+    axiosInstance.put<Draft>('/drafts', data),
+
+  deleteDraft: (id: number) =>
+    axiosInstance.delete(`/drafts/${id}`),
+
+  // Passing the version last seen keeps text saved in another tab from
+  // being published unseen
+  publishDraft: (id: number, version?: number) =>
+    axiosInstance.post<PublishedDraft>(`/drafts/${id}/publish`, version ? { version } : undefined)
+};
@@ -0,0 +1,10 @@
+import axiosInstance from '../config/axios';
+import { MentionPage, UserSuggestion } from '../types/mention';
+
+export const mentionApi = {
+  suggestUsers: (q: string, limit?: number) =>
+    axiosInstance.get<UserSuggestion[]>('/users/suggest', { params: { q, limit } }),
+
+  getMentions: (params?: { cursor?: string; limit?: number }) =>
+    axiosInstance.get<MentionPage>('/mentions', { params })
+};
@@ -0,0 +1,16 @@
+import axiosInstance from '../config/axios';
+import { NotificationListParams, NotificationPage } from '../types/notification';
+
+export const notificationApi = {
+  getNotifications: (params?: NotificationListParams) =>
+    axiosInstance.get<NotificationPage>('/notifications', { params }),
+
+  getUnreadCount: () =>
+    axiosInstance.get<{ unread: number }>('/notifications/unread-count'),
+
+  markRead: (id: number) =>
+    axiosInstance.post<{ unread: number }>(`/notifications/${id}/read`),
+
+  markAllRead: () =>
+    axiosInstance.post<{ unread: number }>('/notifications/read-all')
+};
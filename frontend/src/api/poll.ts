@@ -0,0 +1,14 @@
+import axiosInstance from '../config/axios';
+import { CreatePollData, Poll } from '../types/poll';
+
+export const pollApi = {
+  getPoll: (topicId: number) =>
+    axiosInstance.get<Poll>(`/topics/${topicId}/poll`),
+
+  createPoll: (topicId: number, data: CreatePollData) =>
+    axiosInstance.post<Poll>(`/topics/${topicId}/poll`, data),
+
+  // Replaces the current user's previous vote
+  vote: (topicId: number, optionIds: number[]) =>
+    axiosInstance.put<Poll>(`/topics/${topicId}/poll/vote`, { option_ids: optionIds })
+};
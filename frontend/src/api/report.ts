@@ -0,0 +1,19 @@
+import axiosInstance from '../config/axios';
+import { CreateReportDto, Report, ReportAction, ReportListParams, ReportPage } from '../types/report';
+
+export const reportApi = {
+  createReport: (data: CreateReportDto) =>
+    axiosInstance.post<Report>('/reports', data),
+
+  getReports: (params?: ReportListParams) =>
+    axiosInstance.get<ReportPage>('/moderation/reports', { params }),
+
+  claimReport: (id: number) =>
+    axiosInstance.post<Report>(`/moderation/reports/${id}/claim`),
+
+  resolveReport: (id: number, action: ReportAction, note?: string) =>
+    axiosInstance.post<Report>(`/moderation/reports/${id}/resolve`, { action, note }),
+
+  dismissReport: (id: number, note?: string) =>
+    axiosInstance.post<Report>(`/moderation/reports/${id}/dismiss`, { note })
+};
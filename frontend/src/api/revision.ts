@@ -0,0 +1,22 @@
+import axiosInstance from '../config/axios';
+import { Revision, RevisionDiff } from '../types/revision';
+
+export const revisionApi = {
+  getTopicRevisions: (topicId: number) =>
+    axiosInstance.get<Revision[]>(`/topics/${topicId}/revisions`),
+
+  diffTopicRevisions: (topicId: number, from: number, to?: number) =>
+    axiosInstance.get<RevisionDiff>(`/topics/${topicId}/revisions/diff`, { params: { from, to } }),
+
+  rollbackTopic: (topicId: number, revision: number) =>
+    axiosInstance.post(`/topics/${topicId}/revisions/${revision}/rollback`),
+
+  getCommentRevisions: (commentId: number) =>
+    axiosInstance.get<Revision[]>(`/comments/${commentId}/revisions`),
+
+  diffCommentRevisions: (commentId: number, from: number, to?: number) =>
+    axiosInstance.get<RevisionDiff>(`/comments/${commentId}/revisions/diff`, { params: { from, to } }),
+
+  rollbackComment: (commentId: number, revision: number) =>
+    axiosInstance.post(`/comments/${commentId}/revisions/${revision}/rollback`)
+};
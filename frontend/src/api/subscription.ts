@@ -0,0 +1,28 @@
+import axiosInstance from '../config/axios';
+import {
+  TopicSubscription,
+  WatchedTopicListParams,
+  WatchedTopicPage,
+  WatchLevel,
+  WatchSettings
+} from '../types/subscription';
+
+export const subscriptionApi = {
+  getSubscription: (topicId: number) =>
+    axiosInstance.get<TopicSubscription>(`/topics/${topicId}/subscription`),
+
+  setWatchLevel: (topicId: number, level: WatchLevel) =>
+    axiosInstance.put<TopicSubscription>(`/topics/${topicId}/subscription`, { level }),
+
+  unsubscribe: (topicId: number) =>
+    axiosInstance.delete(`/topics/${topicId}/subscription`),
+
+  getWatchedTopics: (params?: WatchedTopicListParams) =>
+    axiosInstance.get<WatchedTopicPage>('/subscriptions', { params }),
+
+  getSettings: () =>
+    axiosInstance.get<WatchSettings>('/subscriptions/settings'),
+
+  updateSettings: (settings: WatchSettings) =>
+    axiosInstance.put<WatchSettings>('/subscriptions/settings', settings)
+};
@@ -0,0 +1,13 @@
+import axiosInstance from '../config/axios';
+import { Tag } from '../types/topic';
+
+export const tagApi = {
+  getAllTags: () =>
+    axiosInstance.get<Tag[]>('/tags'),
+
+  renameTag: (id: number, name: string) =>
+    axiosInstance.put<Tag>(`/tags/${id}`, { name }),
+
+  mergeTags: (id: number, targetId: number) =>
+    axiosInstance.post(`/tags/${id}/merge`, { target_id: targetId })
+};
@@ -1,9 +1,9 @@
 import axiosInstance from '../config/axios';
-import { Topic, CreateTopicDto, Comment, CreateCommentDto } from '../types/topic';
+import { Topic, TopicPage, TopicListParams, CreateTopicDto, Comment, CommentTree, CommentTreeParams, CommentLikePage, CommentLikeResult, CreateCommentDto, PinScope } from '../types/topic';
 
 export const topicApi = {
-  getAllTopics: () => 
-    axiosInstance.get<Topic[]>('/topics'),
+  getAllTopics: (params?: TopicListParams) =>
+    axiosInstance.get<TopicPage>('/topics', { params }),
 
   getTopic: (id: number) =>
     axiosInstance.get<Topic>(`/topics/${id}`),
@@ -17,9 +17,60 @@ export const topicApi = {
   deleteTopic: (id: number) =>
     axiosInstance.delete(`/topics/${id}`),
 
-  createComment: (topicId: number, data: { content: string }) =>
+  restoreTopic: (id: number) =>
+    axiosInstance.post(`/topics/${id}/restore`),
+
+  pinTopic: (id: number, data: { scope: PinScope; until?: string }) =>
+    axiosInstance.put<Topic>(`/topics/${id}/pin`, data),
+
+  unpinTopic: (id: number) =>
+    axiosInstance.delete<Topic>(`/topics/${id}/pin`),
+
+  lockTopic: (id: number, data?: { until?: string }) =>
+    axiosInstance.put<Topic>(`/topics/${id}/lock`, data),
+
+  unlockTopic: (id: number) =>
+    axiosInstance.delete<Topic>(`/topics/${id}/lock`),
+
+  setAnnouncement: (id: number, data?: { until?: string }) =>
+    axiosInstance.put<Topic>(`/topics/${id}/announcement`, data),
+
+  clearAnnouncement: (id: number) =>
+    axiosInstance.delete<Topic>(`/topics/${id}/announcement`),
+
+  getScheduledTopics: () =>
+    axiosInstance.get<Topic[]>('/topics/scheduled'),
+
+  rescheduleTopic: (id: number, data: { publish_at: string }) =>
+    axiosInstance.put<Topic>(`/topics/${id}/schedule`, data),
+
+  cancelScheduledTopic: (id: number) =>
+    axiosInstance.delete(`/topics/${id}/schedule`),
+
+  getCommentTree: (topicId: number, params?: CommentTreeParams) =>
+    axiosInstance.get<CommentTree>(`/topics/${topicId}/comments/tree`, { params }),
+
+  getReplies: (commentId: number, params?: CommentTreeParams) =>
+    axiosInstance.get<CommentTree>(`/comments/${commentId}/replies`, { params }),
+
+  createComment: (topicId: number, data: { content: string; parent_id?: number }) =>
     axiosInstance.post<Comment>(`/topics/${topicId}/comments`, data),
 
+  updateComment: (topicId: number, commentId: number, data: { content: string }) =>
+    axiosInstance.put<Comment>(`/topics/${topicId}/comments/${commentId}`, data),
+
   deleteComment: (topicId: number, commentId: number) =>
-    axiosInstance.delete(`/topics/${topicId}/comments/${commentId}`)
+    axiosInstance.delete(`/topics/${topicId}/comments/${commentId}`),
+
+  restoreComment: (commentId: number) =>
+    axiosInstance.post(`/comments/${commentId}/restore`),
+
+  likeComment: (commentId: number) =>
+    axiosInstance.post<CommentLikeResult>(`/comments/${commentId}/like`),
+
+  unlikeComment: (commentId: number) =>
+    axiosInstance.delete<CommentLikeResult>(`/comments/${commentId}/like`),
+
+  getCommentLikes: (commentId: number, params?: { limit?: number; cursor?: string }) =>
+    axiosInstance.get<CommentLikePage>(`/comments/${commentId}/likes`, { params })
 }; 
\ No newline at end of file
@@ -0,0 +1,40 @@
+import axiosInstance from '../config/axios';
+import { UserSummary } from '../types/user';
+import { TopicPage } from '../types/topic';
+import { UserProfile, UpdateProfileData, UserCommentPage, ActivityParams } from '../types/profile';
+
+export const userApi = {
+  // PNG, JPEG or WebP; the middle square of the picture is kept
+  uploadAvatar: (file: File) => {
+    const data = new FormData();
+    data.append('file', file);
+    return axiosInstance.put<UserSummary>('/users/me/avatar', data, {
+      headers: { 'Content-Type': 'multipart/form-data' }
+    });
+  },
+
+  deleteAvatar: () =>
+    axiosInstance.delete<UserSummary>('/users/me/avatar'),
+
+  getProfile: (id: number) =>
+    axiosInstance.get<UserProfile>(`/users/${id}`),
+
+  updateProfile: (data: UpdateProfileData) =>
+    axiosInstance.patch<UserProfile>('/users/me/profile', data),
+
+  getUserTopics: (id: number, params?: ActivityParams) =>
+    axiosInstance.get<TopicPage>(`/users/${id}/topics`, { params }),
+
+  getUserComments: (id: number, params?: ActivityParams) =>
+    axiosInstance.get<UserCommentPage>(`/users/${id}/comments`, { params })
+};
+
+// avatarUrl turns an avatar from the API into an absolute link, asking for
+// the smallest stored size of at least size pixels
+export const avatarUrl = (avatar: string, size?: number) => {
+  const url = new URL(avatar, axiosInstance.defaults.baseURL);
+  if (size) {
+    url.searchParams.set('size', String(size));
+  }
+  return url.toString();
+};
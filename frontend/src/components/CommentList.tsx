@@ -107,6 +107,14 @@ export const CommentList: React.FC<CommentListProps> = ({
                     <span className="text-gray-500 text-sm ml-2">
                       {new Date(comment.created_at).toLocaleString()}
                     </span>
+                    {comment.edited && comment.edited_at && (
+                      <span
+                        className="text-gray-400 text-sm ml-2"
+                        title={new Date(comment.edited_at).toLocaleString()}
+                      >
+                        (изменено)
+                      </span>
+                    )}
                   </div>
                 }
                 description={
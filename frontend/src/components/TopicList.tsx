@@ -39,7 +39,7 @@ export const TopicList: React.FC<TopicListProps> = ({
   const fetchTopics = async () => {
     try {
       const response = await topicApi.getAllTopics();
-      dispatch(setTopics(response.data));
+      dispatch(setTopics(response.data.topics));
     } catch (error) {
       console.error('Ошибка при загрузке тем:', error);
       message.error('Не удалось загрузить темы');
@@ -64,7 +64,7 @@ export const TopicList: React.FC<TopicListProps> = ({
         onTopicsChange();
       } else {
         const response = await topicApi.getAllTopics();
-        dispatch(setTopics(response.data));
+        dispatch(setTopics(response.data.topics));
       }
     } catch (error) {
       console.error('Ошибка при удалении темы:', error);
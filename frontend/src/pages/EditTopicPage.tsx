@@ -54,7 +54,7 @@ const EditTopicPage: React.FC = () => {
       
       // Обновляем список тем
       const topicsResponse = await topicApi.getAllTopics();
-      dispatch(setTopics(topicsResponse.data));
+      dispatch(setTopics(topicsResponse.data.topics));
       
       message.success('Тема успешно обновлена');
       navigate(`/topics/${id}`);
@@ -14,7 +14,7 @@ const Home: React.FC = () => {
       try {
       dispatch(setLoading(true));
       const response = await topicApi.getAllTopics();
-      dispatch(setTopics(response.data));
+      dispatch(setTopics(response.data.topics));
       } catch (error) {
       console.error('Ошибка при загрузке тем:', error);
       const errorMessage = error instanceof Error ? error.message : 'Не удалось загрузить темы';
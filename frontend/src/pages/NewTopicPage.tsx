@@ -1,7 +1,9 @@
-import React, { useState } from 'react';
-import { Form, Input, Button, message } from 'antd';
+import React, { useEffect, useState } from 'react';
+import { Form, Input, Button, Select, message } from 'antd';
 import { useNavigate } from 'react-router-dom';
 import { topicApi } from '../api/topic';
+import { categoryApi } from '../api/category';
+import { Category } from '../types/topic';
 import { useDispatch } from 'react-redux';
 import { setTopics } from '../store/slices/topicsSlice';
 
@@ -12,6 +14,16 @@ const NewTopicPage: React.FC = () => {
   const navigate = useNavigate();
   const dispatch = useDispatch();
   const [loading, setLoading] = useState(false);
+  const [categories, setCategories] = useState<Category[]>([]);
+
+  useEffect(() => {
+    categoryApi.getAllCategories()
+      .then((response) => setCategories(response.data))
+      .catch((error) => {
+        console.error('Ошибка при загрузке категорий:', error);
+        message.error('Не удалось загрузить категории');
+      });
+  }, []);
 
   const handleSubmit = async (values: any) => {
     try {
@@ -21,7 +33,7 @@ const NewTopicPage: React.FC = () => {
       
       // Обновляем список тем
       const response = await topicApi.getAllTopics();
-      dispatch(setTopics(response.data));
+      dispatch(setTopics(response.data.topics));
       
       navigate('/');
     } catch (error) {
@@ -52,6 +64,20 @@ const NewTopicPage: React.FC = () => {
           <Input placeholder="Введите заголовок темы" />
         </Form.Item>
 
+        <Form.Item
+          name="category_id"
+          label="Категория"
+          rules={[{ required: true, message: 'Пожалуйста, выберите категорию' }]}
+        >
+          <Select placeholder="Выберите категорию">
+            {categories.map((category) => (
+              <Select.Option key={category.id} value={category.id}>
+                {category.name}
+              </Select.Option>
+            ))}
+          </Select>
+        </Form.Item>
+
         <Form.Item
           name="content"
           label="Содержание"
@@ -84,7 +84,7 @@ const TopicPage: React.FC = () => {
       
       // Обновляем список тем на главной странице
       const topicsResponse = await topicApi.getAllTopics();
-      dispatch(setTopics(topicsResponse.data));
+      dispatch(setTopics(topicsResponse.data.topics));
     } catch (error) {
       console.error('Ошибка при добавлении комментария:', error);
       message.error('Не удалось добавить комментарий');
@@ -113,7 +113,7 @@ const TopicPage: React.FC = () => {
       
       // Обновляем список тем на главной странице
       const topicsResponse = await topicApi.getAllTopics();
-      dispatch(setTopics(topicsResponse.data));
+      dispatch(setTopics(topicsResponse.data.topics));
     } catch (error) {
       console.error('Ошибка при удалении комментария:', error);
       message.error('Не удалось удалить комментарий');
@@ -0,0 +1,18 @@
+export type AttachmentPostType = 'topic' | 'comment';
+
+// url and thumbnail_url are signed links that stop working at
+// url_expires_at; fetch the attachment again for fresh ones. An upload
+// without post_type isn't attached to a post yet and is removed after a while.
+export interface Attachment {
+  id: number;
+  uploader_id: number;
+  post_type?: AttachmentPostType;
+  post_id?: number;
+  filename: string;
+  content_type: string;
+  size: number;
+  created_at: string;
+  url: string;
+  thumbnail_url?: string;
+  url_expires_at: string;
+}
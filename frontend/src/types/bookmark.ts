@@ -0,0 +1,42 @@
+export type BookmarkTargetType = 'topic' | 'comment';
+
+// A bookmark whose target was deleted or hidden stays in the list with
+// available set to false and without a title or excerpt
+export interface Bookmark {
+  id: number;
+  user_id: number;
+  target_type: BookmarkTargetType;
+  target_id: number;
+  topic_id?: number;
+  title?: string;
+  excerpt?: string;
+  available: boolean;
+  note?: string;
+  remind_at?: string;
+  reminded_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface BookmarkPage {
+  bookmarks: Bookmark[];
+  next_cursor?: string;
+}
+
+export interface CreateBookmarkData {
+  target_type: BookmarkTargetType;
+  target_id: number;
+  note?: string;
+  remind_at?: string;
+}
+
+// Omitting remind_at removes the reminder
+export interface UpdateBookmarkData {
+  note?: string;
+  remind_at?: string;
+}
+
+export interface BookmarkListParams {
+  cursor?: string;
+  limit?: number;
+}
@@ -0,0 +1,49 @@
+import { Comment, Topic } from './topic';
+
+// topic is a new topic; the replies point at the topic or comment in target_id
+export type DraftContext = 'topic' | 'topic_reply' | 'comment_reply';
+
+export interface Draft {
+  id: number;
+  user_id: number;
+  context: DraftContext;
+  target_id?: number;
+  title?: string;
+  content: string;
+  category_id?: number;
+  tag_names: string[];
+  version: number;
+  created_at: string;
+  updated_at: string;
+  expires_at: string;
+}
+
+export interface DraftPage {
+  drafts: Draft[];
+  next_cursor?: string;
+}
+
+// version is the one returned by the previous save, 0 for a new draft.
+// A save based on an older version fails with 409 Conflict.
+export interface SaveDraftData {
+  context: DraftContext;
+  target_id?: number;
+  title?: string;
+  content: string;
+  category_id?: number;
+  tag_names?: string[];
+  version: number;
+}
+
+// With context only the draft for that context and target_id is returned
+export interface DraftListParams {
+  context?: DraftContext;
+  target_id?: number;
+  cursor?: string;
+  limit?: number;
+}
+
+export interface PublishedDraft {
+  topic?: Topic;
+  comment?: Comment;
+}
@@ -0,0 +1,23 @@
+export type MentionTargetType = 'topic' | 'comment' | 'chat_message';
+
+export interface Mention {
+  id: number;
+  target_type: MentionTargetType;
+  target_id: number;
+  topic_id?: number;
+  user_id: number;
+  author_id?: number;
+  author_username?: string;
+  created_at: string;
+}
+
+export interface MentionPage {
+  mentions: Mention[];
+  next_cursor?: string;
+}
+
+export interface UserSuggestion {
+  id: number;
+  username: string;
+  avatar?: string;
+}
@@ -0,0 +1,39 @@
+export type NotificationType = 'topic_reply' | 'comment_reply' | 'mention' | 'like' | 'moderation' | 'reminder';
+export type NotificationTargetType = 'topic' | 'comment' | 'chat_message';
+export type ModerationAction = 'hide' | 'delete' | 'warn' | 'rollback';
+
+export interface Notification {
+  id: number;
+  user_id: number;
+  type: NotificationType;
+  target_type: NotificationTargetType;
+  target_id: number;
+  topic_id?: number;
+  actor_id?: number;
+  actor_username?: string;
+  actor_count: number;
+  action?: ModerationAction;
+  note?: string;
+  read: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface NotificationPage {
+  notifications: Notification[];
+  unread: number;
+  next_cursor?: string;
+}
+
+export interface NotificationListParams {
+  unread?: boolean;
+  cursor?: string;
+  limit?: number;
+}
+
+// Payload of the "notification" WebSocket message. Without a notification
+// it only updates the unread count.
+export interface NotificationEvent {
+  notification?: Notification;
+  unread: number;
+}
@@ -0,0 +1,36 @@
+import { Author } from './topic';
+
+// Voters are listed only in public polls whose results are visible
+export interface PollOption {
+  id: number;
+  text: string;
+  position: number;
+  votes: number;
+  voters?: Author[];
+}
+
+// While results_visible is false, votes and total_voters are zero
+export interface Poll {
+  id: number;
+  topic_id: number;
+  question: string;
+  multiple: boolean;
+  anonymous: boolean;
+  hide_results: boolean;
+  closes_at?: string;
+  created_at: string;
+  options: PollOption[];
+  closed: boolean;
+  results_visible: boolean;
+  total_voters: number;
+  my_votes: number[];
+}
+
+export interface CreatePollData {
+  question: string;
+  options: string[];
+  multiple?: boolean;
+  anonymous?: boolean;
+  hide_results?: boolean;
+  closes_at?: string;
+}
@@ -0,0 +1,42 @@
+// Public profile of a user; the counts include only posts visible to everyone
+export interface UserProfile {
+  id: number;
+  username: string;
+  avatar: string;
+  role: 'user' | 'moderator' | 'admin';
+  bio: string;
+  signature: string;
+  location: string;
+  joined_at?: string;
+  topic_count: number;
+  comment_count: number;
+}
+
+// Omitted fields are kept; an empty string clears a field
+export interface UpdateProfileData {
+  bio?: string;
+  signature?: string;
+  location?: string;
+}
+
+export interface UserComment {
+  id: number;
+  topic_id: number;
+  topic_title: string;
+  parent_id?: number;
+  content: string;
+  content_html: string;
+  likes: number;
+  created_at: string;
+  edited_at?: string;
+}
+
+export interface UserCommentPage {
+  comments: UserComment[];
+  next_cursor?: string;
+}
+
+export interface ActivityParams {
+  cursor?: string;
+  limit?: number;
+}
@@ -0,0 +1,43 @@
+export type ReportTargetType = 'topic' | 'comment' | 'chat_message';
+export type ReportReason = 'spam' | 'abuse' | 'off_topic' | 'other';
+export type ReportStatus = 'open' | 'claimed' | 'resolved' | 'dismissed';
+export type ReportAction = 'hide' | 'delete' | 'warn';
+
+export interface Report {
+  id: number;
+  target_type: ReportTargetType;
+  target_id: number;
+  target_author_id?: number;
+  content_snapshot: string;
+  reporter_id: number;
+  reason: ReportReason;
+  details: string;
+  status: ReportStatus;
+  claimed_by?: number;
+  claimed_at?: string;
+  resolved_by?: number;
+  resolved_at?: string;
+  action?: ReportAction;
+  note: string;
+  created_at: string;
+  target_reports?: number;
+}
+
+export interface ReportPage {
+  reports: Report[];
+  next_cursor?: string;
+}
+
+export interface CreateReportDto {
+  target_type: ReportTargetType;
+  target_id: number;
+  reason: ReportReason;
+  details?: string;
+}
+
+export interface ReportListParams {
+  status?: ReportStatus;
+  target_type?: ReportTargetType;
+  limit?: number;
+  cursor?: string;
+}
@@ -0,0 +1,20 @@
+export type RevisionTargetType = 'topic' | 'comment';
+
+export interface Revision {
+  id: number;
+  target_type: RevisionTargetType;
+  target_id: number;
+  revision: number;
+  editor_id?: number;
+  title?: string;
+  content: string;
+  created_at: string;
+}
+
+export interface RevisionDiff {
+  target_type: RevisionTargetType;
+  target_id: number;
+  from: number;
+  to?: number;
+  diff: string;
+}
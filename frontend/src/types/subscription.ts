@@ -0,0 +1,34 @@
+export type WatchLevel = 'watching' | 'tracking' | 'muted';
+
+export interface TopicSubscription {
+  user_id: number;
+  topic_id: number;
+  level: WatchLevel;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface WatchedTopic {
+  topic_id: number;
+  title: string;
+  comment_count: number;
+  last_activity_at: string;
+  level: WatchLevel;
+  unread_comments: number;
+}
+
+export interface WatchedTopicPage {
+  topics: WatchedTopic[];
+  next_cursor?: string;
+}
+
+// Muted topics are listed only when asked for by level
+export interface WatchedTopicListParams {
+  level?: WatchLevel;
+  cursor?: string;
+  limit?: number;
+}
+
+export interface WatchSettings {
+  auto_watch: boolean;
+}
@@ -4,51 +4,137 @@ export interface Author {
   avatar?: string;
 }
 
+export interface CategoryActivity {
+  topic_id: number;
+  topic_title: string;
+  at: string;
+}
+
 export interface Category {
   id: number;
   name: string;
   description?: string;
+  position?: number;
+  topic_count?: number;
+  latest_activity?: CategoryActivity;
 }
 
 export interface Tag {
   id: number;
   name: string;
+  topic_count?: number;
 }
 
 export interface Topic {
   id: number;
   title: string;
   content: string;
+  // Sanitized HTML rendered from the Markdown in content
+  content_html: string;
   author_id: number;
   author: Author;
   category_id: number;
-  category: Category;
+  category?: Category;
   views: number;
   tags: Tag[];
   created_at: string;
   updated_at: string;
   comment_count: number;
+  last_activity_at: string;
   comments?: Comment[];
+  // Moderation flags; expired ones are not returned
+  pinned?: PinScope;
+  pinned_until?: string;
+  locked: boolean;
+  locked_until?: string;
+  announcement: boolean;
+  announcement_until?: string;
+  // Set while the topic is scheduled; only the author and moderators see it
+  publish_at?: string;
+}
+
+// category: on top of the topic's category; global: on top of every listing
+export type PinScope = 'category' | 'global';
+
+export type TopicSort = 'newest' | 'views' | 'comments' | 'activity' | 'hot' | 'top';
+
+// Period of the top listing; week if omitted
+export type TopicPeriod = 'week' | 'month' | 'all';
+
+export interface TopicListParams {
+  sort?: TopicSort;
+  period?: TopicPeriod;
+  category_id?: number;
+  tag?: string;
+  limit?: number;
+  cursor?: string;
+}
+
+export interface TopicPage {
+  topics: Topic[];
+  next_cursor?: string;
 }
 
 export interface Comment {
   id: number;
   content: string;
+  content_html: string;
   author_id: number;
   author: Author;
   topic_id: number;
+  parent_id?: number;
+  likes: number;
+  liked_by_me: boolean;
   created_at: string;
   updated_at: string;
+  edited: boolean;
+  edited_at?: string;
+  depth?: number;
+  reply_count?: number;
+  replies?: Comment[];
+  replies_cursor?: string;
+  deleted?: boolean;
+  deleted_at?: string;
+}
+
+export interface CommentTree {
+  comments: Comment[];
+  next_cursor?: string;
+}
+
+export interface CommentLike {
+  user_id: number;
+  username: string;
+  avatar: string;
+  created_at: string;
+}
+
+export interface CommentLikePage {
+  likes: CommentLike[];
+  next_cursor?: string;
+}
+
+export interface CommentLikeResult {
+  likes: number;
+  liked_by_me: boolean;
+}
+
+export interface CommentTreeParams {
+  depth?: number;
+  limit?: number;
+  cursor?: string;
 }
 
 export interface CreateTopicDto {
   title: string;
   content: string;
   category_id: number;
-  tags?: number[];
+  tag_names?: string[];
+  publish_at?: string;
 }
 
 export interface CreateCommentDto {
   content: string;
   topic_id: number;
+  parent_id?: number;
 } 
\ No newline at end of file
@@ -5,4 +5,11 @@ export interface User {
   role: string;
   avatar?: string;
   isAdmin?: boolean;
-} 
\ No newline at end of file
+} 
+// Author embedded in topics, comments and chat messages. avatar is the
+// uploaded picture or a generated one, relative to the API host
+export interface UserSummary {
+  id: number;
+  username: string;
+  avatar: string;
+}